@@ -0,0 +1,64 @@
+// Package containers finds the running containers of compose services from their
+// labels, for the commands that run in them like fleet php and fleet node. Container
+// names depend on the compose version and the project name, labels don't.
+package containers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// defaultComposeProject is the compose project Fleet runs every project under
+const defaultComposeProject = "fleet"
+
+// getComposeProject returns the compose project of the services. COMPOSE_PROJECT_NAME
+// overrides it, like it does for docker compose.
+func getComposeProject() string {
+	if project := os.Getenv("COMPOSE_PROJECT_NAME"); project != "" {
+		return project
+	}
+	return defaultComposeProject
+}
+
+// listServiceContainers returns the container number and name of the running containers
+// of a compose service, one per line
+var listServiceContainers = func(project, service string) ([]byte, error) {
+	return exec.Command("docker", "ps",
+		"--filter", "label=com.docker.compose.project="+project,
+		"--filter", "label=com.docker.compose.service="+service,
+		"--format", `{{.Label "com.docker.compose.container-number"}} {{.Names}}`).Output()
+}
+
+// FindServiceContainer returns the running container of a compose service, the first
+// replica when it has several
+func FindServiceContainer(service string) (string, error) {
+	project := getComposeProject()
+	output, err := listServiceContainers(project, service)
+	if err != nil {
+		return "", fmt.Errorf("failed to list the containers of %s: %w", service, err)
+	}
+
+	container := ""
+	lowest := 0
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// Compose implementations without the container-number label only give the name
+		name, n := fields[len(fields)-1], 0
+		if len(fields) == 2 {
+			n, _ = strconv.Atoi(fields[0])
+		}
+		if container == "" || n < lowest {
+			container, lowest = name, n
+		}
+	}
+	if container == "" {
+		return "", fmt.Errorf("no running container of %s in compose project %s, start it with 'fleet up'", service, project)
+	}
+	return container, nil
+}
@@ -0,0 +1,54 @@
+package containers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ContainersTestSuite struct {
+	suite.Suite
+	output  string
+	project string
+}
+
+func (suite *ContainersTestSuite) SetupTest() {
+	suite.T().Setenv("COMPOSE_PROJECT_NAME", "")
+	original := listServiceContainers
+	listServiceContainers = func(project, service string) ([]byte, error) {
+		suite.project = project
+		return []byte(suite.output), nil
+	}
+	suite.T().Cleanup(func() { listServiceContainers = original })
+}
+
+func (suite *ContainersTestSuite) TestFindServiceContainer() {
+	suite.output = "3 fleet-api-3\n1 fleet-api-1\n2 fleet-api-2\n"
+	container, err := FindServiceContainer("api")
+	suite.Require().NoError(err)
+	suite.Equal("fleet-api-1", container, "The first replica")
+	suite.Equal("fleet", suite.project)
+
+	suite.output = " fleet_api_1\n"
+	container, err = FindServiceContainer("api")
+	suite.Require().NoError(err)
+	suite.Equal("fleet_api_1", container, "Without the container-number label")
+
+	suite.T().Setenv("COMPOSE_PROJECT_NAME", "shop")
+	suite.output = ""
+	_, err = FindServiceContainer("api")
+	suite.ErrorContains(err, "no running container of api in compose project shop")
+}
+
+func (suite *ContainersTestSuite) TestDockerFails() {
+	listServiceContainers = func(project, service string) ([]byte, error) {
+		return nil, errors.New("docker is not running")
+	}
+	_, err := FindServiceContainer("api")
+	suite.ErrorContains(err, "failed to list the containers of api")
+}
+
+func TestContainersSuite(t *testing.T) {
+	suite.Run(t, new(ContainersTestSuite))
+}
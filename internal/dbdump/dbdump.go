@@ -0,0 +1,77 @@
+// Package dbdump runs the commands dumping and restoring a database, with their output
+// or input in a file. Files ending with .gz are gzipped, and a dump to - goes to the
+// standard output. It is shared by fleet db and the db: commands of fleet php.
+package dbdump
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Dump runs a command writing a database to its output, into a file. A failed dump leaves
+// no file behind, it would restore a broken database.
+func Dump(cmd *exec.Cmd, file string) (err error) {
+	var out io.Writer = os.Stdout
+	if file != "-" {
+		var f *os.File
+		if f, err = os.Create(file); err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(file)
+			}
+		}()
+		out = f
+		if strings.HasSuffix(file, ".gz") {
+			gz := gzip.NewWriter(f)
+			defer func() {
+				if closeErr := gz.Close(); err == nil {
+					err = closeErr
+				}
+			}()
+			out = gz
+		}
+	}
+
+	cmd.Stdout = out
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	return cmd.Run()
+}
+
+// Restore runs a command loading a dump from its input, with the content of a file
+func Restore(cmd *exec.Cmd, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var in io.Reader = f
+	if strings.HasSuffix(file, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		defer gz.Close()
+		in = gz
+	}
+
+	cmd.Stdin = in
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	return cmd.Run()
+}
@@ -0,0 +1,67 @@
+package dbdump
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DBDumpTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func (suite *DBDumpTestSuite) SetupTest() {
+	suite.dir = suite.T().TempDir()
+}
+
+func (suite *DBDumpTestSuite) TestDumpGzip() {
+	file := filepath.Join(suite.dir, "shop.sql.gz")
+	suite.Require().NoError(Dump(exec.Command("echo", "CREATE TABLE orders;"), file))
+
+	f, err := os.Open(file)
+	suite.Require().NoError(err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	suite.Require().NoError(err)
+	content, err := io.ReadAll(gz)
+	suite.Require().NoError(err)
+	suite.Equal("CREATE TABLE orders;\n", string(content))
+}
+
+func (suite *DBDumpTestSuite) TestFailedDumpLeavesNoFile() {
+	file := filepath.Join(suite.dir, "shop.sql")
+	cmd := exec.Command("sh", "-c", "echo partial; exit 1")
+	cmd.Stderr = io.Discard
+	suite.Error(Dump(cmd, file))
+	suite.NoFileExists(file)
+}
+
+func (suite *DBDumpTestSuite) TestRestoreGzip() {
+	file := filepath.Join(suite.dir, "shop.sql.gz")
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte("CREATE TABLE orders;\n"))
+	suite.Require().NoError(gz.Close())
+	suite.Require().NoError(os.WriteFile(file, compressed.Bytes(), 0644))
+
+	var out bytes.Buffer
+	cmd := exec.Command("cat")
+	cmd.Stdout = &out
+	suite.Require().NoError(Restore(cmd, file))
+	suite.Equal("CREATE TABLE orders;\n", out.String())
+}
+
+func (suite *DBDumpTestSuite) TestRestoreMissingFile() {
+	suite.Error(Restore(exec.Command("cat"), filepath.Join(suite.dir, "missing.sql")))
+}
+
+func TestDBDumpSuite(t *testing.T) {
+	suite.Run(t, new(DBDumpTestSuite))
+}
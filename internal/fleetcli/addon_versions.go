@@ -0,0 +1,139 @@
+package fleetcli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// addonKind is an attached service setting whose versions come from config/versions.json
+type addonKind struct {
+	key         string
+	sets        map[string]VersionSet
+	value       func(svc *Service) string
+	parse       func(value string) (string, string)
+	image       func(addonType, version string) string
+	serviceName func(addonType, version string) string
+}
+
+// UnknownAddonVersion is a version of an attached service Fleet has no image for
+type UnknownAddonVersion struct {
+	Service        string
+	Key            string
+	Type           string
+	Version        string
+	ComposeService string
+	Supported      []string
+	DefaultImage   string // What runs instead by default
+	Image          string // The literal tag, used with --allow-unknown-version
+}
+
+// getAddonKinds returns the attached service settings that are checked. AI and queue
+// versions are rejected when the config loads, so they never fall back.
+func getAddonKinds() []addonKind {
+	return []addonKind{
+		{"database", versionData.Database, func(svc *Service) string { return svc.Database }, parseDatabaseType, getDatabaseImage, getSharedDatabaseServiceName},
+		{"cache", versionData.Cache, func(svc *Service) string { return svc.Cache }, parseCacheType, getCacheImage, getSharedCacheServiceName},
+		{"search", versionData.Search, func(svc *Service) string { return svc.Search }, parseSearchType, getSearchImage, getSharedSearchServiceName},
+		{"email", versionData.Email, func(svc *Service) string { return svc.Email }, parseEmailType, getEmailImage,
+			func(emailType, _ string) string { return getEmailServiceName(emailType) }},
+		{"compat", versionData.Compat, func(svc *Service) string { return svc.Compat }, parseCompatType, getCompatImage, getSharedCompatServiceName},
+	}
+}
+
+// getLiteralAddonImage returns the image of the default version with the requested tag
+func getLiteralAddonImage(defaultImage, version string) string {
+	repository := defaultImage
+	if index := strings.LastIndex(defaultImage, ":"); index > strings.LastIndex(defaultImage, "/") {
+		repository = defaultImage[:index]
+	}
+	return repository + ":" + version
+}
+
+// getUnknownAddonVersions returns the attached services asking for a version Fleet
+// doesn't know. Without --allow-unknown-version they run the default version.
+func getUnknownAddonVersions(config *Config) []UnknownAddonVersion {
+	var unknown []UnknownAddonVersion
+	for _, svc := range config.Services {
+		for _, kind := range getAddonKinds() {
+			value := kind.value(&svc)
+			// Without a version the default is what was asked for
+			if !strings.Contains(value, ":") {
+				continue
+			}
+			addonType, version := kind.parse(value)
+			set, ok := kind.sets[addonType]
+			if !ok || version == "" {
+				continue
+			}
+			defaultImage := set.Images[set.Default]
+			if _, known := set.Images[version]; known || kind.image(addonType, version) != defaultImage {
+				continue
+			}
+			unknown = append(unknown, UnknownAddonVersion{
+				Service:        svc.Name,
+				Key:            kind.key,
+				Type:           addonType,
+				Version:        version,
+				ComposeService: kind.serviceName(addonType, version),
+				Supported:      set.getVersions(),
+				DefaultImage:   defaultImage,
+				Image:          getLiteralAddonImage(defaultImage, version),
+			})
+		}
+	}
+	return unknown
+}
+
+// applyUnknownAddonVersions runs the requested tag of attached services Fleet doesn't
+// know the version of, when the config allows it
+func applyUnknownAddonVersions(compose *DockerCompose, config *Config) {
+	if !config.AllowUnknownVersions {
+		return
+	}
+	for _, addon := range getUnknownAddonVersions(config) {
+		if service, exists := compose.Services[addon.ComposeService]; exists {
+			service.Image = addon.Image
+			compose.Services[addon.ComposeService] = service
+		}
+	}
+}
+
+// warnUnknownAddonVersions prints the attached services whose version isn't known, and
+// which image they run instead
+func warnUnknownAddonVersions(config *Config) {
+	for _, addon := range getUnknownAddonVersions(config) {
+		warnf("⚠️  Warning: service %s asks for %s %s, which Fleet doesn't know (supported: %s)\n",
+			addon.Service, addon.Type, addon.Version, strings.Join(addon.Supported, ", "))
+		if config.AllowUnknownVersions {
+			warnf("   Running %s as asked, since unknown versions are allowed\n", addon.Image)
+		} else {
+			warnf("   Running %s instead. Run 'fleet versions update', or pass --allow-unknown-version to run %s\n", addon.DefaultImage, addon.Image)
+		}
+	}
+}
+
+// checkAddonVersions is the doctor check of the versions of attached services
+func checkAddonVersions(config *Config) DoctorCheck {
+	check := DoctorCheck{Name: "Service versions", Status: doctorOK, Detail: "every attached service runs the version it asks for"}
+	unknown := getUnknownAddonVersions(config)
+	if len(unknown) == 0 {
+		return check
+	}
+
+	var problems []string
+	for _, addon := range unknown {
+		runs := addon.DefaultImage
+		if config.AllowUnknownVersions {
+			runs = addon.Image
+		}
+		problems = append(problems, fmt.Sprintf("%s %s = %q is unknown, runs %s", addon.Service, addon.Key, addon.Type+":"+addon.Version, runs))
+	}
+	check.Status = doctorWarn
+	check.Detail = strings.Join(problems, "; ")
+	if config.AllowUnknownVersions {
+		check.Fix = "Check that the images exist, or run 'fleet versions update' to get the versions Fleet supports"
+	} else {
+		check.Fix = "Run 'fleet versions update', pick a supported version, or pass --allow-unknown-version to 'fleet up'"
+	}
+	return check
+}
@@ -0,0 +1,90 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AddonVersionsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *AddonVersionsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *AddonVersionsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *AddonVersionsTestSuite) TestGetLiteralAddonImage() {
+	suite.Equal("redis:8.0", getLiteralAddonImage("redis:7.2-alpine", "8.0"))
+	suite.Equal("ghcr.io/acme/cache:8.0", getLiteralAddonImage("ghcr.io/acme/cache:1", "8.0"))
+	suite.Equal("localhost:5000/redis:8.0", getLiteralAddonImage("localhost:5000/redis", "8.0"))
+}
+
+func (suite *AddonVersionsTestSuite) TestGetUnknownAddonVersions() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Cache: "redis:8.0", Database: "postgres:16", Search: "meilisearch"},
+		{Name: "shop", Image: "node:20", Port: 3001, Database: "mysql:9.1", Compat: "minio:2024"},
+	}}
+
+	unknown := getUnknownAddonVersions(config)
+	suite.Require().Len(unknown, 2)
+	suite.Equal("api", unknown[0].Service)
+	suite.Equal("cache", unknown[0].Key)
+	suite.Equal("redis-80", unknown[0].ComposeService)
+	suite.Equal(versionData.Cache["redis"].Images["7.2"], unknown[0].DefaultImage)
+	suite.Equal("redis:8.0", unknown[0].Image)
+	suite.Contains(unknown[0].Supported, "7.2")
+	suite.Equal("mysql:9.1", unknown[1].Image)
+}
+
+func (suite *AddonVersionsTestSuite) TestFallsBackByDefault() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Cache: "redis:8.0"},
+	}}
+
+	suite.Equal(versionData.Cache["redis"].Images["7.2"], generateDockerCompose(config).Services["redis-80"].Image)
+
+	check := checkAddonVersions(config)
+	suite.Equal(doctorWarn, check.Status)
+	suite.Contains(check.Detail, `api cache = "redis:8.0" is unknown, runs `+versionData.Cache["redis"].Images["7.2"])
+	suite.Contains(check.Fix, "--allow-unknown-version")
+}
+
+func (suite *AddonVersionsTestSuite) TestAllowUnknownVersions() {
+	config := &Config{Project: "test", AllowUnknownVersions: true, Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Cache: "redis:8.0", Database: "postgres:16"},
+	}}
+
+	compose := generateDockerCompose(config)
+	suite.Equal("redis:8.0", compose.Services["redis-80"].Image)
+	suite.Equal(versionData.Database["postgres"].Images["16"], compose.Services["postgres-16"].Image, "Known versions are unchanged")
+
+	check := checkAddonVersions(config)
+	suite.Equal(doctorWarn, check.Status)
+	suite.Contains(check.Detail, "runs redis:8.0")
+}
+
+func (suite *AddonVersionsTestSuite) TestKnownVersions() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Cache: "redis", Database: "postgres:latest", Email: "mailpit:1.20"},
+	}}
+
+	suite.Empty(getUnknownAddonVersions(config))
+	suite.Equal(doctorOK, checkAddonVersions(config).Status)
+}
+
+func TestAddonVersionsSuite(t *testing.T) {
+	suite.Run(t, new(AddonVersionsTestSuite))
+}
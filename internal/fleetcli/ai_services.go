@@ -0,0 +1,185 @@
+package fleetcli
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Supported local LLM service versions, from config/versions.json
+var supportedAIVersions = imageMaps(versionData.AI)
+
+const (
+	// aiServiceName is the compose service of the LLM server. Models are large, so the
+	// project runs a single server whatever the number of services using it.
+	aiServiceName = "ollama"
+	// aiModelsServiceName is the one-off container pulling the configured models
+	aiModelsServiceName = "ollama-models"
+	// ollamaPort is where Ollama serves its API
+	ollamaPort = 11434
+)
+
+// aiModelPattern matches an Ollama model like llama3.2, nomic-embed-text or qwen2.5:7b
+var aiModelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]*(:[A-Za-z0-9._-]+)?$`)
+
+// parseAIType parses the LLM server type and version from a string like "ollama:0.3"
+func parseAIType(aiString string) (aiType string, version string) {
+	if aiString == "" {
+		return "", ""
+	}
+
+	aiType, version, _ = strings.Cut(strings.ToLower(aiString), ":")
+	if version == "" {
+		if set, ok := versionData.AI[aiType]; ok {
+			version = set.Default
+		}
+	}
+	return aiType, version
+}
+
+// getAIImage returns the Docker image of an LLM server, or "" for unknown types
+func getAIImage(aiType, version string) string {
+	versions, ok := supportedAIVersions[aiType]
+	if !ok {
+		return ""
+	}
+	if image, ok := versions[version]; ok {
+		return image
+	}
+	return versions["default"]
+}
+
+// validateAIService checks the ai settings of a service
+func validateAIService(svc *Service) error {
+	if svc.AI == "" {
+		if len(svc.AIModels) > 0 || svc.AIGPU {
+			return fmt.Errorf("service %s: 'ai_models' and 'ai_gpu' require 'ai'", svc.Name)
+		}
+		return nil
+	}
+
+	aiType, version := parseAIType(svc.AI)
+	set, ok := versionData.AI[aiType]
+	if !ok {
+		return fmt.Errorf("service %s: unsupported ai service %q (supported: ollama)", svc.Name, aiType)
+	}
+	if _, ok := set.Images[version]; !ok {
+		return fmt.Errorf("service %s: unsupported %s version %s (supported: %s)", svc.Name, aiType, version, strings.Join(set.getVersions(), ", "))
+	}
+	for _, model := range svc.AIModels {
+		if !aiModelPattern.MatchString(model) {
+			return fmt.Errorf("service %s: invalid ai model %q, use a name like llama3.2 or qwen2.5:7b", svc.Name, model)
+		}
+	}
+	return nil
+}
+
+// getAIModels returns the models every service of the project needs, sorted and once each
+func getAIModels(config *Config) []string {
+	var models []string
+	for _, svc := range config.Services {
+		if svc.AI == "" {
+			continue
+		}
+		for _, model := range svc.AIModels {
+			if !containsString(models, model) {
+				models = append(models, model)
+			}
+		}
+	}
+	sort.Strings(models)
+	return models
+}
+
+// needsAIGPU reports whether a service of the project asks for GPU acceleration
+func needsAIGPU(config *Config) bool {
+	for _, svc := range config.Services {
+		if svc.AI != "" && svc.AIGPU {
+			return true
+		}
+	}
+	return false
+}
+
+// getGPUDeploy reserves every Nvidia GPU of the host, through the Nvidia container toolkit
+func getGPUDeploy() *DockerDeploy {
+	return &DockerDeploy{Resources: &DockerResources{Reservations: DockerReservations{
+		Devices: []DockerDevice{{Driver: "nvidia", Count: "all", Capabilities: []string{"gpu"}}},
+	}}}
+}
+
+// getAIModelsScript returns the command pulling the models the server doesn't have yet,
+// so only the first start downloads them
+func getAIModelsScript(models []string) string {
+	commands := make([]string, 0, len(models))
+	for _, model := range models {
+		commands = append(commands, fmt.Sprintf("(ollama show %s >/dev/null 2>&1 || ollama pull %s)", model, model))
+	}
+	return strings.Join(commands, " && ")
+}
+
+// addAIService adds the LLM server, and the container pulling its models, the first time
+// a service needs it. Services using it get its URL.
+func addAIService(compose *DockerCompose, svc *Service, config *Config) {
+	aiType, version := parseAIType(svc.AI)
+	image := getAIImage(aiType, version)
+	if image == "" {
+		return
+	}
+
+	// A service with only ai has no container of its own, it just asks for the server
+	if service, exists := compose.Services[svc.Name]; exists && service.Image == "" && service.Build == "" {
+		delete(compose.Services, svc.Name)
+	}
+
+	if _, exists := compose.Services[aiServiceName]; !exists {
+		service := DockerService{
+			Image:    image,
+			Networks: []string{"fleet-network"},
+			Restart:  "unless-stopped",
+			// Models survive down and up, a single one is several GB
+			Volumes: []string{fmt.Sprintf("%s-data:/root/.ollama", aiServiceName)},
+			HealthCheck: &HealthCheckYAML{
+				Test:     []string{"CMD", "ollama", "list"},
+				Interval: "10s",
+				Timeout:  "5s",
+				Retries:  5,
+			},
+		}
+		if needsAIGPU(config) {
+			service.Deploy = getGPUDeploy()
+		}
+		compose.Services[aiServiceName] = service
+
+		// Models are pulled next to the apps starting, a download can take minutes
+		if models := getAIModels(config); len(models) > 0 {
+			compose.Services[aiModelsServiceName] = DockerService{
+				Image:               image,
+				Networks:            []string{"fleet-network"},
+				Entrypoint:          []string{"/bin/sh", "-c", getAIModelsScript(models)},
+				Environment:         map[string]string{"OLLAMA_HOST": fmt.Sprintf("http://%s:%d", aiServiceName, ollamaPort)},
+				DependsOn:           []string{aiServiceName},
+				DependsOnConditions: map[string]string{aiServiceName: dependsOnHealthy},
+			}
+		}
+	}
+
+	url := fmt.Sprintf("http://%s:%d", aiServiceName, ollamaPort)
+	vars := map[string]string{
+		"OLLAMA_BASE_URL": url,
+		"OLLAMA_HOST":     url,
+	}
+	if len(svc.AIModels) > 0 {
+		vars["OLLAMA_MODEL"] = svc.AIModels[0]
+	}
+
+	// PHP code runs in the FPM container, so it needs the URL as well
+	for _, name := range []string{svc.Name, getAppServiceName(svc)} {
+		if service, exists := compose.Services[name]; exists && !containsString(service.DependsOn, aiServiceName) {
+			service.DependsOn = append(service.DependsOn, aiServiceName)
+			compose.Services[name] = service
+		}
+		mergeServiceEnvironment(compose, name, vars)
+	}
+}
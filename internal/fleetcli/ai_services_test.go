@@ -0,0 +1,119 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+)
+
+type AIServicesTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *AIServicesTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *AIServicesTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *AIServicesTestSuite) TestParseAIType() {
+	aiType, version := parseAIType("ollama:0.3")
+	suite.Equal("ollama", aiType)
+	suite.Equal("0.3", version)
+	suite.Equal("ollama/ollama:0.3.14", getAIImage(aiType, version))
+
+	aiType, version = parseAIType("Ollama")
+	suite.Equal("ollama", aiType)
+	suite.Equal(versionData.AI["ollama"].Default, version)
+
+	suite.Equal("", getAIImage("llamafile", "1"))
+}
+
+func (suite *AIServicesTestSuite) TestValidateAIService() {
+	testCases := []struct {
+		name    string
+		service Service
+		wantErr string
+	}{
+		{"valid", Service{Name: "api", AI: "ollama:0.3", AIModels: []string{"llama3.2", "qwen2.5:7b", "nomic-embed-text"}, AIGPU: true}, ""},
+		{"default version", Service{Name: "api", AI: "ollama"}, ""},
+		{"unknown type", Service{Name: "api", AI: "llamafile"}, "unsupported ai service"},
+		{"unknown version", Service{Name: "api", AI: "ollama:9.9"}, "unsupported ollama version 9.9"},
+		{"invalid model", Service{Name: "api", AI: "ollama", AIModels: []string{"llama3.2; rm -rf /"}}, "invalid ai model"},
+		{"models without ai", Service{Name: "api", AIModels: []string{"llama3.2"}}, "require 'ai'"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			err := validateAIService(&tc.service)
+			if tc.wantErr == "" {
+				suite.NoError(err)
+			} else {
+				suite.ErrorContains(err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *AIServicesTestSuite) TestGenerateDockerCompose() {
+	suite.Require().NoError(os.MkdirAll("shop", 0755))
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, AI: "ollama:0.3", AIModels: []string{"llama3.2"}},
+		{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "shop", Domain: "shop.test", AI: "ollama:0.3", AIModels: []string{"nomic-embed-text", "llama3.2"}, AIGPU: true},
+	}}
+
+	compose := generateDockerCompose(config)
+
+	server := compose.Services["ollama"]
+	suite.Equal("ollama/ollama:0.3.14", server.Image)
+	suite.Contains(server.Volumes, "ollama-data:/root/.ollama")
+	suite.Contains(compose.Volumes, "ollama-data")
+	suite.Require().NotNil(server.Deploy, "A service asked for the GPU")
+	suite.Equal("nvidia", server.Deploy.Resources.Reservations.Devices[0].Driver)
+
+	models := compose.Services["ollama-models"]
+	suite.Equal([]string{"/bin/sh", "-c", "(ollama show llama3.2 >/dev/null 2>&1 || ollama pull llama3.2) && " +
+		"(ollama show nomic-embed-text >/dev/null 2>&1 || ollama pull nomic-embed-text)"}, models.Entrypoint)
+	suite.Equal(dependsOnHealthy, models.DependsOnConditions["ollama"])
+
+	for _, name := range []string{"api", "shop-php"} {
+		suite.Equal("http://ollama:11434", compose.Services[name].Environment["OLLAMA_BASE_URL"], name)
+		suite.Contains(compose.Services[name].DependsOn, "ollama", name)
+	}
+	suite.Equal("llama3.2", compose.Services["api"].Environment["OLLAMA_MODEL"])
+	suite.Equal("nomic-embed-text", compose.Services["shop-php"].Environment["OLLAMA_MODEL"])
+
+	data, err := yaml.Marshal(compose)
+	suite.Require().NoError(err)
+	suite.Contains(string(data), "condition: service_healthy")
+	suite.Contains(string(data), "count: all")
+}
+
+func (suite *AIServicesTestSuite) TestStandaloneAIService() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "llm", AI: "ollama"},
+		{Name: "api", Image: "node:20", AI: "ollama"},
+	}}
+
+	compose := generateDockerCompose(config)
+
+	suite.NotContains(compose.Services, "llm", "The server is the container of a service with only ai")
+	suite.Contains(compose.Services, "ollama")
+	suite.NotContains(compose.Services, "ollama-models", "No models to pull")
+	suite.Nil(compose.Services["ollama"].Deploy)
+}
+
+func TestAIServicesSuite(t *testing.T) {
+	suite.Run(t, new(AIServicesTestSuite))
+}
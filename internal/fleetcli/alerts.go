@@ -0,0 +1,396 @@
+package fleetcli
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// defaultStatsInterval is how often fleet stats --watch checks the alerts
+const defaultStatsInterval = 10 * time.Second
+
+// hasAlerts reports whether any alert is configured
+func hasAlerts(t AlertThresholds) bool {
+	return t.Memory != "" || t.CPU != "" || t.Restarts > 0
+}
+
+// sizePattern matches a size like 512MB, 1.5g or 200MiB
+var sizePattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z]*)$`)
+
+// sizeUnits are the units of sizes in alerts and docker stats. Single letters are binary,
+// like the memory limits of Docker.
+var sizeUnits = map[string]float64{
+	"": 1, "b": 1,
+	"kb": 1e3, "mb": 1e6, "gb": 1e9, "tb": 1e12,
+	"k": 1 << 10, "m": 1 << 20, "g": 1 << 30, "t": 1 << 40,
+	"kib": 1 << 10, "mib": 1 << 20, "gib": 1 << 30, "tib": 1 << 40,
+}
+
+// parseSize parses a size like 512MB or 1.5GiB into bytes
+func parseSize(value string) (int64, error) {
+	match := sizePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	unit, ok := sizeUnits[strings.ToLower(match[2])]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	number, _ := strconv.ParseFloat(match[1], 64)
+	return int64(number * unit), nil
+}
+
+// parsePercent parses a percentage like 80% or 12.5%
+func parsePercent(value string) (float64, error) {
+	number, found := strings.CutSuffix(strings.TrimSpace(value), "%")
+	if !found {
+		return 0, fmt.Errorf("invalid percentage %q", value)
+	}
+	percent, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %q", value)
+	}
+	return percent, nil
+}
+
+// validateAlerts checks the alerts of a service
+func validateAlerts(svc *Service) error {
+	alerts := svc.Alerts
+	if alerts.Memory != "" {
+		if strings.HasSuffix(alerts.Memory, "%") {
+			if percent, err := parsePercent(alerts.Memory); err != nil || percent <= 0 || percent > 100 {
+				return fmt.Errorf("service %s: invalid memory alert %q, use a percentage between 0%% and 100%%", svc.Name, alerts.Memory)
+			}
+		} else if size, err := parseSize(alerts.Memory); err != nil || size <= 0 {
+			return fmt.Errorf("service %s: invalid memory alert %q, use a percentage like \"80%%\" or a size like \"512MB\"", svc.Name, alerts.Memory)
+		}
+	}
+	if alerts.CPU != "" {
+		if percent, err := parsePercent(alerts.CPU); err != nil || percent <= 0 {
+			return fmt.Errorf("service %s: invalid cpu alert %q, use a percentage of one core like \"90%%\"", svc.Name, alerts.CPU)
+		}
+	}
+	if alerts.Restarts < 0 {
+		return fmt.Errorf("service %s: restarts alert must be positive", svc.Name)
+	}
+	return nil
+}
+
+// getAlertThresholds returns the alerts of each compose service. The PHP-FPM container of
+// a service runs its code, so it gets the alerts as well.
+func getAlertThresholds(config *Config) map[string]AlertThresholds {
+	thresholds := make(map[string]AlertThresholds)
+	for _, svc := range config.Services {
+		if !hasAlerts(svc.Alerts) {
+			continue
+		}
+		thresholds[svc.Name] = svc.Alerts
+		thresholds[getAppServiceName(&svc)] = svc.Alerts
+	}
+	return thresholds
+}
+
+// ContainerStats is the resource usage docker stats reports for a container
+type ContainerStats struct {
+	Name     string `json:"Name"`
+	CPUPerc  string `json:"CPUPerc"`
+	MemUsage string `json:"MemUsage"`
+	MemPerc  string `json:"MemPerc"`
+}
+
+// memoryUsage returns the memory the container uses, from a MemUsage like "1.2GiB / 7.6GiB"
+func (s ContainerStats) memoryUsage() (int64, error) {
+	usage, _, _ := strings.Cut(s.MemUsage, "/")
+	return parseSize(usage)
+}
+
+// parseContainerStats parses the JSON lines of docker stats
+func parseContainerStats(output []byte) (map[string]ContainerStats, error) {
+	stats := make(map[string]ContainerStats)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry ContainerStats
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse docker stats output: %w", err)
+		}
+		stats[entry.Name] = entry
+	}
+	return stats, nil
+}
+
+// collectContainerStats returns the current resource usage of running containers
+var collectContainerStats = func(containers []string) (map[string]ContainerStats, error) {
+	if len(containers) == 0 {
+		return map[string]ContainerStats{}, nil
+	}
+	args := append([]string{"stats", "--no-stream", "--format", "{{json .}}"}, containers...)
+	output, err := newCommand("docker", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container stats: %w", err)
+	}
+	return parseContainerStats(output)
+}
+
+// StatsSnapshot is the state and resource usage of the containers of a project
+type StatsSnapshot struct {
+	Entries  []ComposePSEntry
+	Stats    map[string]ContainerStats
+	Restarts map[string]int
+}
+
+// collectStatsSnapshot queries Docker for the containers of the project
+func collectStatsSnapshot(composeFiles ComposeFiles) (*StatsSnapshot, error) {
+	entries, err := getComposeServiceStates(composeFiles)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	var all, running []string
+	for _, entry := range entries {
+		all = append(all, entry.Name)
+		if entry.State == "running" {
+			running = append(running, entry.Name)
+		}
+	}
+
+	stats, err := collectContainerStats(running)
+	if err != nil {
+		return nil, err
+	}
+	restarts, err := inspectRestartCounts(all)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsSnapshot{Entries: entries, Stats: stats, Restarts: restarts}, nil
+}
+
+// Alert is a threshold a container reached
+type Alert struct {
+	Service   string
+	Container string
+	// Kind is memory, cpu or restarts
+	Kind      string
+	Value     string
+	Threshold string
+}
+
+// key identifies the alert across checks, so it is reported once while it lasts
+func (a Alert) key() string {
+	return a.Container + "/" + a.Kind
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("%s: %s %s (alert at %s)", a.Container, a.Kind, a.Value, a.Threshold)
+}
+
+// evaluateAlerts returns the alerts the containers of a snapshot reached
+func evaluateAlerts(thresholds map[string]AlertThresholds, snapshot *StatsSnapshot) []Alert {
+	var alerts []Alert
+	for _, entry := range snapshot.Entries {
+		threshold, ok := thresholds[entry.Service]
+		if !ok {
+			continue
+		}
+		alert := func(kind, value, limit string) {
+			alerts = append(alerts, Alert{Service: entry.Service, Container: entry.Name, Kind: kind, Value: value, Threshold: limit})
+		}
+
+		if stats, ok := snapshot.Stats[entry.Name]; ok {
+			if threshold.Memory != "" {
+				if strings.HasSuffix(threshold.Memory, "%") {
+					limit, _ := parsePercent(threshold.Memory)
+					if percent, err := parsePercent(stats.MemPerc); err == nil && percent >= limit {
+						alert("memory", stats.MemPerc, threshold.Memory)
+					}
+				} else {
+					limit, _ := parseSize(threshold.Memory)
+					if usage, err := stats.memoryUsage(); err == nil && usage >= limit {
+						alert("memory", formatBytes(usage), threshold.Memory)
+					}
+				}
+			}
+			if threshold.CPU != "" {
+				limit, _ := parsePercent(threshold.CPU)
+				if percent, err := parsePercent(stats.CPUPerc); err == nil && percent >= limit {
+					alert("cpu", stats.CPUPerc, threshold.CPU)
+				}
+			}
+		}
+
+		if threshold.Restarts > 0 && snapshot.Restarts[entry.Name] >= threshold.Restarts {
+			alert("restarts", strconv.Itoa(snapshot.Restarts[entry.Name]), strconv.Itoa(threshold.Restarts))
+		}
+	}
+	return alerts
+}
+
+// updateAlerts compares the alerts of a check with the ones still active from the previous
+// checks. It returns the alerts that just fired, the ones that cleared and the active ones.
+func updateAlerts(active map[string]Alert, alerts []Alert) (fired []Alert, cleared []Alert, next map[string]Alert) {
+	next = make(map[string]Alert, len(alerts))
+	for _, alert := range alerts {
+		if _, exists := active[alert.key()]; !exists {
+			fired = append(fired, alert)
+		}
+		next[alert.key()] = alert
+	}
+
+	keys := make([]string, 0, len(active))
+	for key := range active {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, exists := next[key]; !exists {
+			cleared = append(cleared, active[key])
+		}
+	}
+	return fired, cleared, next
+}
+
+// appleScriptQuote quotes a string for AppleScript
+func appleScriptQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// sendDesktopNotification shows a notification on the desktop of the host
+var sendDesktopNotification = func(title, message string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptQuote(message), appleScriptQuote(title))
+		return newCommand("osascript", "-e", script).Run()
+	case "linux":
+		return newCommand("notify-send", title, message).Run()
+	}
+	return fmt.Errorf("desktop notifications aren't supported on %s", runtime.GOOS)
+}
+
+// printStats prints the resource usage of each container
+func printStats(w io.Writer, snapshot *StatsSnapshot) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tCONTAINER\tCPU\tMEMORY\tMEM %\tRESTARTS")
+	for _, entry := range snapshot.Entries {
+		stats, ok := snapshot.Stats[entry.Name]
+		if !ok {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t-\t-\t%d\n", entry.Service, entry.Name, entry.State, snapshot.Restarts[entry.Name])
+			continue
+		}
+		usage, _, _ := strings.Cut(stats.MemUsage, "/")
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n", entry.Service, entry.Name, stats.CPUPerc, strings.TrimSpace(usage), stats.MemPerc, snapshot.Restarts[entry.Name])
+	}
+	tw.Flush()
+}
+
+// watchAlerts checks the alerts until Fleet is interrupted. Each alert is reported when it
+// fires and when it clears, not on every check.
+func watchAlerts(config *Config, thresholds map[string]AlertThresholds, interval time.Duration, notify bool) {
+	infof("👀 Watching the alerts of %s every %s (Ctrl-C to stop)\n", config.Project, interval)
+
+	composeFiles := getComposeFiles(config)
+	active := map[string]Alert{}
+	for {
+		snapshot, err := collectStatsSnapshot(composeFiles)
+		if err != nil {
+			warnf("⚠️  Warning: %v\n", err)
+		} else {
+			var fired, cleared []Alert
+			fired, cleared, active = updateAlerts(active, evaluateAlerts(thresholds, snapshot))
+			for _, alert := range fired {
+				warnf("%s %s %s\n", time.Now().Format("15:04:05"), emojiOr("🚨", "ALERT"), alert)
+				if notify {
+					if err := sendDesktopNotification("Fleet: "+config.Project, alert.String()); err != nil {
+						warnf("⚠️  Warning: failed to send a notification: %v\n", err)
+					}
+				}
+			}
+			for _, alert := range cleared {
+				outputf("%s %s %s: %s back under %s\n", time.Now().Format("15:04:05"), emojiOr("✅", "OK"), alert.Container, alert.Kind, alert.Threshold)
+			}
+		}
+
+		select {
+		case <-rootContext.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func printStatsUsage() {
+	fmt.Println("Fleet stats - Resource usage of the containers and their alerts")
+	fmt.Println("\nUsage: fleet stats [options]")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --watch     Check the alerts of the services until interrupted")
+	fmt.Printf("  --interval  Time between checks (for --watch, default: %s)\n", defaultStatsInterval)
+	fmt.Println("  --notify    Send a desktop notification when an alert fires (for --watch)")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("\nAlerts are set per service in fleet.toml:")
+	fmt.Println("  alerts = { memory = \"80%\", cpu = \"90%\", restarts = 3 }")
+}
+
+func handleStats() {
+	if len(os.Args) > 2 && os.Args[2] == "help" {
+		printStatsUsage()
+		return
+	}
+
+	fs := newCommandFlagSet("stats", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	watch := fs.Bool("watch", false, "Check the alerts until interrupted")
+	interval := fs.Duration("interval", defaultStatsInterval, "Time between checks")
+	notify := fs.Bool("notify", false, "Send a desktop notification when an alert fires")
+	fs.Usage = printStatsUsage
+
+	fs.Parse(os.Args[2:])
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+	if *interval <= 0 {
+		log.Fatalf("❌ --interval must be positive")
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	thresholds := getAlertThresholds(config)
+
+	if *watch {
+		if len(thresholds) == 0 {
+			log.Fatalf("❌ No service has alerts, add alerts = { memory = \"80%%\" } to a service")
+		}
+		watchAlerts(config, thresholds, *interval, *notify)
+		return
+	}
+
+	snapshot, err := collectStatsSnapshot(getComposeFiles(config))
+	if err != nil {
+		log.Fatalf("❌ Error reading container stats: %v", err)
+	}
+	if len(snapshot.Entries) == 0 {
+		outputln("No containers are running, start them with 'fleet up'")
+		return
+	}
+	printStats(os.Stdout, snapshot)
+	for _, alert := range evaluateAlerts(thresholds, snapshot) {
+		warnf("%s %s\n", emojiOr("🚨", "ALERT"), alert)
+	}
+}
@@ -0,0 +1,137 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AlertsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *AlertsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *AlertsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *AlertsTestSuite) TestValidateAlerts() {
+	testCases := []struct {
+		name    string
+		alerts  AlertThresholds
+		wantErr string
+	}{
+		{"none", AlertThresholds{}, ""},
+		{"percentages", AlertThresholds{Memory: "80%", CPU: "90%", Restarts: 3}, ""},
+		{"memory size", AlertThresholds{Memory: "512MB"}, ""},
+		{"several cores", AlertThresholds{CPU: "250%"}, ""},
+		{"memory over 100%", AlertThresholds{Memory: "120%"}, "between 0% and 100%"},
+		{"memory without unit", AlertThresholds{Memory: "lots"}, "invalid memory alert"},
+		{"cpu without percent", AlertThresholds{CPU: "0.9"}, "invalid cpu alert"},
+		{"negative restarts", AlertThresholds{Restarts: -1}, "must be positive"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			err := validateAlerts(&Service{Name: "api", Alerts: tc.alerts})
+			if tc.wantErr == "" {
+				suite.NoError(err)
+			} else {
+				suite.ErrorContains(err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *AlertsTestSuite) TestParseSize() {
+	testCases := map[string]int64{
+		"512MB":  512_000_000,
+		"512m":   512 << 20,
+		"1.5GiB": 3 << 29,
+		"200kB":  200_000,
+		"42":     42,
+	}
+	for value, expected := range testCases {
+		size, err := parseSize(value)
+		suite.Require().NoError(err, value)
+		suite.Equal(expected, size, value)
+	}
+
+	_, err := parseSize("12 parsecs")
+	suite.Error(err)
+}
+
+func (suite *AlertsTestSuite) TestEvaluateAlerts() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Alerts: AlertThresholds{Memory: "80%", CPU: "90%", Restarts: 3}},
+		{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Alerts: AlertThresholds{Memory: "256MB"}},
+		{Name: "db", Image: "postgres:16"},
+	}}
+	thresholds := getAlertThresholds(config)
+	suite.Contains(thresholds, "shop-php", "The FPM container runs the code")
+	suite.NotContains(thresholds, "db")
+
+	output := []byte(`{"Name":"test-api-1","CPUPerc":"95.10%","MemUsage":"900MiB / 1GiB","MemPerc":"87.89%"}
+{"Name":"test-api-2","CPUPerc":"3.00%","MemUsage":"100MiB / 1GiB","MemPerc":"9.77%"}
+{"Name":"test-shop-php-1","CPUPerc":"1.00%","MemUsage":"300MiB / 7.6GiB","MemPerc":"3.85%"}
+{"Name":"test-db-1","CPUPerc":"99.00%","MemUsage":"7GiB / 7.6GiB","MemPerc":"92.00%"}
+`)
+	stats, err := parseContainerStats(output)
+	suite.Require().NoError(err)
+
+	snapshot := &StatsSnapshot{
+		Entries: []ComposePSEntry{
+			{Name: "test-api-1", Service: "api", State: "running"},
+			{Name: "test-api-2", Service: "api", State: "running"},
+			{Name: "test-db-1", Service: "db", State: "running"},
+			{Name: "test-shop-php-1", Service: "shop-php", State: "running"},
+		},
+		Stats:    stats,
+		Restarts: map[string]int{"test-api-2": 3, "test-db-1": 12},
+	}
+
+	var alerts []string
+	for _, alert := range evaluateAlerts(thresholds, snapshot) {
+		alerts = append(alerts, alert.String())
+	}
+	suite.Equal([]string{
+		"test-api-1: memory 87.89% (alert at 80%)",
+		"test-api-1: cpu 95.10% (alert at 90%)",
+		"test-api-2: restarts 3 (alert at 3)",
+		"test-shop-php-1: memory 314.6MB (alert at 256MB)",
+	}, alerts)
+}
+
+func (suite *AlertsTestSuite) TestUpdateAlerts() {
+	memory := Alert{Service: "api", Container: "test-api-1", Kind: "memory", Value: "85%", Threshold: "80%"}
+	cpu := Alert{Service: "api", Container: "test-api-1", Kind: "cpu", Value: "95%", Threshold: "90%"}
+
+	fired, cleared, active := updateAlerts(map[string]Alert{}, []Alert{memory})
+	suite.Equal([]Alert{memory}, fired)
+	suite.Empty(cleared)
+
+	// An alert that lasts is reported once
+	fired, cleared, active = updateAlerts(active, []Alert{memory, cpu})
+	suite.Equal([]Alert{cpu}, fired)
+	suite.Empty(cleared)
+
+	fired, cleared, active = updateAlerts(active, []Alert{cpu})
+	suite.Empty(fired)
+	suite.Equal([]Alert{memory}, cleared)
+	suite.Len(active, 1)
+}
+
+func TestAlertsSuite(t *testing.T) {
+	suite.Run(t, new(AlertsTestSuite))
+}
@@ -0,0 +1,227 @@
+package fleetcli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fleet/fleet/internal/ui"
+)
+
+// Changes writing an artifact makes on disk, shown by fleet up --dry-run
+const (
+	artifactCreate    = "create"
+	artifactUpdate    = "update"
+	artifactUnchanged = "unchanged"
+)
+
+// Artifact is a file generated next to the compose files, like an nginx config, a
+// script mounted into a container or an env file
+type Artifact struct {
+	Path    string
+	Content []byte
+	Mode    os.FileMode
+}
+
+// ArtifactPlan holds the files and directories a generated stack needs. Generating the
+// compose config only records them, writeArtifacts writes them, so generation never
+// touches the disk and can run for dry runs, diffs and tests in parallel.
+type ArtifactPlan struct {
+	// dir is where the files of the stack are generated, .fleet or the directory of the
+	// artifact set of its config
+	dir          string
+	files        map[string]*Artifact
+	dirs         map[string]os.FileMode
+	certificates []SSLCertificate
+}
+
+// ArtifactChange is what writing a planned file would do
+type ArtifactChange struct {
+	Path   string
+	Change string
+}
+
+// newArtifactPlan returns an empty plan
+func newArtifactPlan() *ArtifactPlan {
+	return &ArtifactPlan{dir: defaultComposeOutputDir, files: make(map[string]*Artifact), dirs: make(map[string]os.FileMode)}
+}
+
+// path returns the path of a file generated for the stack, in the directory of the plan
+func (plan *ArtifactPlan) path(name string) string {
+	return filepath.Join(plan.dir, name)
+}
+
+// mountPath returns the source of a bind mount of a file generated for the stack. Paths
+// are relative to the compose project directory, .fleet, wherever the compose files are.
+func (plan *ArtifactPlan) mountPath(name string) string {
+	rel, err := filepath.Rel(defaultComposeOutputDir, plan.path(name))
+	if err != nil {
+		return plan.path(name)
+	}
+	return "./" + filepath.ToSlash(rel)
+}
+
+// getArtifactPlan returns the plan of the files a compose config needs, creating it on
+// first use
+func getArtifactPlan(compose *DockerCompose) *ArtifactPlan {
+	if compose.Artifacts == nil {
+		compose.Artifacts = newArtifactPlan()
+	}
+	return compose.Artifacts
+}
+
+// addFile plans a file, replacing the content planned for the same path
+func (plan *ArtifactPlan) addFile(path string, content []byte, mode os.FileMode) {
+	path = filepath.Clean(path)
+	plan.files[path] = &Artifact{Path: path, Content: content, Mode: mode}
+}
+
+// addDir plans a directory that has to exist, like the one backups are written to
+func (plan *ArtifactPlan) addDir(path string, mode os.FileMode) {
+	plan.dirs[filepath.Clean(path)] = mode
+}
+
+// addCertificate plans a self-signed certificate. Its key is only generated when the
+// plan is written.
+func (plan *ArtifactPlan) addCertificate(cert SSLCertificate) {
+	plan.certificates = append(plan.certificates, cert)
+}
+
+// file returns a planned file, so generation can change a file it planned earlier
+func (plan *ArtifactPlan) file(path string) (*Artifact, bool) {
+	artifact, ok := plan.files[filepath.Clean(path)]
+	return artifact, ok
+}
+
+// rebase returns the part of a plan under a directory, moved to another directory. fleet
+// bundle writes the files generated for .fleet into the bundle this way.
+func (plan *ArtifactPlan) rebase(from, to string) *ArtifactPlan {
+	move := func(path string) (string, bool) {
+		rel, err := filepath.Rel(from, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", false
+		}
+		return filepath.Join(to, rel), true
+	}
+
+	rebased := newArtifactPlan()
+	for path, mode := range plan.dirs {
+		if dir, ok := move(path); ok {
+			rebased.addDir(dir, mode)
+		}
+	}
+	for _, artifact := range plan.files {
+		if path, ok := move(artifact.Path); ok {
+			rebased.addFile(path, artifact.Content, artifact.Mode)
+		}
+	}
+	for _, cert := range plan.certificates {
+		certPath, certOK := move(cert.CertPath)
+		keyPath, keyOK := move(cert.KeyPath)
+		if certOK && keyOK {
+			cert.CertPath, cert.KeyPath = certPath, keyPath
+			rebased.addCertificate(cert)
+		}
+	}
+	return rebased
+}
+
+// Files returns the planned files sorted by path
+func (plan *ArtifactPlan) Files() []*Artifact {
+	files := make([]*Artifact, 0, len(plan.files))
+	for _, path := range sortedKeys(plan.files) {
+		files = append(files, plan.files[path])
+	}
+	return files
+}
+
+// getChanges returns what writing the plan would change on disk, by path
+func (plan *ArtifactPlan) getChanges() []ArtifactChange {
+	var changes []ArtifactChange
+	for _, artifact := range plan.Files() {
+		changes = append(changes, ArtifactChange{Path: artifact.Path, Change: getFileChange(artifact.Path, artifact.Content)})
+	}
+	for _, cert := range plan.certificates {
+		for _, path := range []string{cert.CertPath, cert.KeyPath} {
+			change := artifactCreate
+			if _, err := os.Stat(path); err == nil {
+				change = artifactUpdate
+			}
+			changes = append(changes, ArtifactChange{Path: filepath.Clean(path), Change: change})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// getFileChange compares the content of a file with what would be written to it
+func getFileChange(path string, content []byte) string {
+	current, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return artifactCreate
+	}
+	if err != nil || !bytes.Equal(current, content) {
+		return artifactUpdate
+	}
+	return artifactUnchanged
+}
+
+// writeArtifacts writes the files and directories of a plan, and generates the planned
+// certificates
+func writeArtifacts(plan *ArtifactPlan) error {
+	if plan == nil {
+		return nil
+	}
+
+	for _, dir := range sortedKeys(plan.dirs) {
+		if err := os.MkdirAll(dir, plan.dirs[dir]); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		// MkdirAll applies the umask, and keeps the mode of an existing directory
+		if err := os.Chmod(dir, plan.dirs[dir]); err != nil {
+			return fmt.Errorf("failed to set permissions on %s: %w", dir, err)
+		}
+	}
+
+	for _, artifact := range plan.Files() {
+		if err := os.MkdirAll(filepath.Dir(artifact.Path), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(artifact.Path), err)
+		}
+		if err := os.WriteFile(artifact.Path, artifact.Content, artifact.Mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", artifact.Path, err)
+		}
+		// WriteFile keeps the permissions of an existing file
+		if err := os.Chmod(artifact.Path, artifact.Mode); err != nil {
+			return fmt.Errorf("failed to set permissions on %s: %w", artifact.Path, err)
+		}
+	}
+
+	if len(plan.certificates) == 0 {
+		return nil
+	}
+	return newProgress().Run(fmt.Sprintf("Generating %d SSL certificates", len(plan.certificates)), func(step *ui.Step) error {
+		for _, cert := range plan.certificates {
+			if err := os.MkdirAll(filepath.Dir(cert.CertPath), 0755); err != nil {
+				return fmt.Errorf("failed to create SSL directory: %w", err)
+			}
+			if err := generateSelfSignedCertificate(cert); err != nil {
+				return fmt.Errorf("failed to generate certificate for %s: %w", cert.Domain, err)
+			}
+			step.Log("   %s\n", cert.Domain)
+		}
+		return nil
+	})
+}
+
+// printArtifactChanges prints what writing the generated files would change
+func printArtifactChanges(out io.Writer, changes []ArtifactChange) {
+	icons := map[string]string{artifactCreate: "+", artifactUpdate: "~", artifactUnchanged: "="}
+	for _, change := range changes {
+		fmt.Fprintf(out, "  %s %-9s %s\n", icons[change.Change], change.Change, change.Path)
+	}
+}
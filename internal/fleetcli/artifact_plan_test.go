@@ -0,0 +1,98 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ArtifactPlanTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *ArtifactPlanTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *ArtifactPlanTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+// newConfig returns a project whose services need generated files
+func (suite *ArtifactPlanTestSuite) newConfig() *Config {
+	suite.Require().NoError(os.MkdirAll("shop", 0755))
+	return &Config{Project: "test", Secrets: secretsEnvFile, Services: []Service{
+		{Name: "web", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "shop", Domain: "shop.test", SSL: true,
+			Database: "postgres:16", DatabaseExtensions: []string{"vector"}, BackupSchedule: "0 3 * * *"},
+	}}
+}
+
+func (suite *ArtifactPlanTestSuite) TestGenerationWritesNothing() {
+	compose := generateDockerCompose(suite.newConfig())
+	suite.NoDirExists(".fleet", "Generation only plans files")
+
+	plan := compose.Artifacts
+	suite.Require().NotNil(plan)
+	for _, path := range []string{".fleet/nginx.conf", ".fleet/web-nginx.conf", ".fleet/web-backup.sh", ".fleet/env/postgres-16.env", ".fleet/ssl/ssl-params.conf"} {
+		_, planned := plan.file(path)
+		suite.True(planned, path)
+	}
+	suite.Len(plan.certificates, 2, "The default certificate and one for the domain")
+
+	suite.Require().NoError(writeArtifacts(plan))
+	suite.FileExists(filepath.Join(".fleet", "ssl", "shop_test.crt"))
+	info, err := os.Stat(filepath.Join(".fleet", "web-backup.sh"))
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0755), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(".fleet", "env", "postgres-16.env"))
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0600), info.Mode().Perm(), "Env files stay private")
+
+	suite.Empty(generateDockerCompose(suite.newConfig()).Artifacts.certificates, "Valid certificates are kept")
+}
+
+func (suite *ArtifactPlanTestSuite) TestGetChanges() {
+	plan := newArtifactPlan()
+	plan.addFile(".fleet/a.conf", []byte("a\n"), 0644)
+	plan.addFile(".fleet/b.conf", []byte("b\n"), 0644)
+	plan.addFile(".fleet/c.conf", []byte("c\n"), 0644)
+	suite.Require().NoError(os.MkdirAll(".fleet", 0755))
+	suite.Require().NoError(os.WriteFile(".fleet/b.conf", []byte("b\n"), 0644))
+	suite.Require().NoError(os.WriteFile(".fleet/c.conf", []byte("old\n"), 0644))
+
+	suite.Equal([]ArtifactChange{
+		{Path: filepath.Join(".fleet", "a.conf"), Change: artifactCreate},
+		{Path: filepath.Join(".fleet", "b.conf"), Change: artifactUnchanged},
+		{Path: filepath.Join(".fleet", "c.conf"), Change: artifactUpdate},
+	}, plan.getChanges())
+}
+
+func (suite *ArtifactPlanTestSuite) TestComposeChanges() {
+	config := &Config{Project: "test", Services: []Service{{Name: "api", Image: "node:20", Port: 3000}}}
+	compose := generateDockerCompose(config)
+
+	changes, err := getComposeChanges(config, compose)
+	suite.Require().NoError(err)
+	suite.Contains(changes, ArtifactChange{Path: filepath.Join(".fleet", composeFileName), Change: artifactCreate})
+
+	_, err = writeComposeFiles(config, compose)
+	suite.Require().NoError(err)
+	changes, err = getComposeChanges(config, generateDockerCompose(config))
+	suite.Require().NoError(err)
+	for _, change := range changes {
+		suite.Equal(artifactUnchanged, change.Change, change.Path)
+	}
+}
+
+func TestArtifactPlanSuite(t *testing.T) {
+	suite.Run(t, new(ArtifactPlanTestSuite))
+}
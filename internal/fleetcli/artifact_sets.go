@@ -0,0 +1,177 @@
+package fleetcli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// runningConfigFile records which config the running stack was started from
+const runningConfigFile = ".fleet/running.json"
+
+// artifactSetPattern matches characters that can't be in the directory of an artifact set
+var artifactSetPattern = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// reservedArtifactSets are directories of .fleet that aren't artifact sets
+var reservedArtifactSets = []string{"backups", "bin", "build-output", "env", "logs", "native", "php", "profiles", "ssl"}
+
+// RunningConfig is the content of .fleet/running.json
+type RunningConfig struct {
+	Config     string    `json:"config"`
+	ComposeDir string    `json:"compose_dir"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// isDefaultConfigFile reports whether a config file is the fleet config of its project,
+// like fleet.toml, whose files are generated in .fleet itself
+func isDefaultConfigFile(filename string) bool {
+	base := filepath.Base(filename)
+	return strings.TrimSuffix(base, filepath.Ext(base)) == "fleet"
+}
+
+// getArtifactSetName returns the directory of .fleet the files generated for a config
+// file are written to, like staging for fleet.staging.toml, or "" for the default config.
+// Configs outside the project directory get a hash of their path, so two of them with
+// the same name don't share files.
+func getArtifactSetName(filename string) string {
+	if isDefaultConfigFile(filename) {
+		return ""
+	}
+
+	base := strings.ToLower(filepath.Base(filename))
+	name := strings.TrimPrefix(strings.TrimSuffix(base, filepath.Ext(base)), "fleet.")
+	name = strings.Trim(artifactSetPattern.ReplaceAllString(name, "-"), "-.")
+	if name == "" {
+		name = "config"
+	} else if containsString(reservedArtifactSets, name) {
+		name = "config-" + name
+	}
+
+	if filepath.Dir(filepath.Clean(filename)) != "." {
+		path, err := filepath.Abs(filename)
+		if err != nil {
+			path = filename
+		}
+		sum := sha256.Sum256([]byte(path))
+		name = fmt.Sprintf("%s-%s", name, hex.EncodeToString(sum[:])[:8])
+	}
+	return name
+}
+
+// getArtifactDir returns the directory the files generated for the stack of a config are
+// written to: .fleet for the default config, the directory of its artifact set otherwise.
+// Backups, logs and profiles stay in .fleet, they are shared by every config.
+func getArtifactDir(config *Config) string {
+	if config.ArtifactSet != "" {
+		return filepath.Join(defaultComposeOutputDir, config.ArtifactSet)
+	}
+	return defaultComposeOutputDir
+}
+
+// loadRunningConfig reads which config the running stack was started from. Without a
+// stack started by fleet up it returns nil.
+func loadRunningConfig() (*RunningConfig, error) {
+	data, err := os.ReadFile(runningConfigFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var running RunningConfig
+	if err := json.Unmarshal(data, &running); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", runningConfigFile, err)
+	}
+	return &running, nil
+}
+
+// recordRunningConfig remembers the config fleet up starts the stack from. It is recorded
+// before compose runs, since compose keeps running in the foreground: the returned
+// function puts back the previous record when the stack fails to start.
+func recordRunningConfig(filename string, config *Config) (func(), error) {
+	noop := func() {}
+	data, err := json.MarshalIndent(RunningConfig{
+		Config:     filepath.ToSlash(filepath.Clean(filename)),
+		ComposeDir: filepath.ToSlash(getComposeOutputDir(config)),
+		StartedAt:  time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return noop, err
+	}
+	if err := os.MkdirAll(filepath.Dir(runningConfigFile), 0755); err != nil {
+		return noop, err
+	}
+	previous, readErr := os.ReadFile(runningConfigFile)
+	if err := os.WriteFile(runningConfigFile, append(data, '\n'), 0644); err != nil {
+		return noop, fmt.Errorf("failed to write %s: %w", runningConfigFile, err)
+	}
+	return func() {
+		if readErr != nil {
+			os.Remove(runningConfigFile)
+		} else {
+			os.WriteFile(runningConfigFile, previous, 0644)
+		}
+	}, nil
+}
+
+// clearRunningConfig forgets the running stack once fleet down stopped it
+func clearRunningConfig(filename string) {
+	running, err := loadRunningConfig()
+	if err != nil || running == nil || !sameConfigFile(running.Config, filename) {
+		return
+	}
+	os.Remove(runningConfigFile)
+}
+
+// sameConfigFile reports whether two config file names are the same file
+func sameConfigFile(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// isConfigFlagSet reports whether -f or --file was given on the command line
+func isConfigFlagSet(fs *flag.FlagSet) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "f" || f.Name == "file" {
+			set = true
+		}
+	})
+	return set
+}
+
+// resolveRunningConfigFile returns the config of the running stack when no config file
+// was given, so fleet down and fleet status act on what fleet up started
+func resolveRunningConfigFile(fs *flag.FlagSet, filename string) string {
+	if isConfigFlagSet(fs) {
+		return filename
+	}
+	running, err := loadRunningConfig()
+	if err != nil || running == nil || sameConfigFile(running.Config, filename) {
+		return filename
+	}
+	if _, err := os.Stat(running.Config); err != nil {
+		return filename
+	}
+	infof("ℹ️  Using %s, the config the running stack was started from\n", running.Config)
+	return running.Config
+}
+
+// warnOtherRunningConfig warns when fleet up starts a config while the stack of another
+// one is running. All configs of a project share the compose project, so the services
+// of the running one are replaced.
+func warnOtherRunningConfig(filename string) {
+	running, err := loadRunningConfig()
+	if err != nil || running == nil || sameConfigFile(running.Config, filename) {
+		return
+	}
+	warnf("⚠️  Warning: the stack of %s is running, services with the same name are replaced\n", running.Config)
+	warnf("   Run 'fleet down -f %s' first to stop it\n", running.Config)
+}
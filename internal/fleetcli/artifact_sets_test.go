@@ -0,0 +1,140 @@
+package fleetcli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ArtifactSetsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *ArtifactSetsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *ArtifactSetsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+// writeConfig writes a config with one service and loads it
+func (suite *ArtifactSetsTestSuite) writeConfig(filename, image string) *Config {
+	content := "project = \"shop\"\n\n[[services]]\nname = \"web\"\nimage = \"" + image + "\"\n"
+	suite.Require().NoError(os.WriteFile(filename, []byte(content), 0644))
+	config, err := loadConfig(filename)
+	suite.Require().NoError(err)
+	return config
+}
+
+func (suite *ArtifactSetsTestSuite) TestGetArtifactSetName() {
+	testCases := map[string]string{
+		"fleet.toml":         "",
+		"fleet.yaml":         "",
+		"apps/fleet.toml":    "",
+		"fleet.staging.toml": "staging",
+		"CI Config.yml":      "ci-config",
+		"fleet.ssl.toml":     "config-ssl",
+		".toml":              "config",
+	}
+	for filename, expected := range testCases {
+		suite.Equal(expected, getArtifactSetName(filename), filename)
+	}
+
+	other := getArtifactSetName("../other/fleet.staging.toml")
+	suite.Regexp(`^staging-[0-9a-f]{8}$`, other, "Configs outside the project get a hash")
+	suite.NotEqual(other, getArtifactSetName("../third/fleet.staging.toml"))
+}
+
+func (suite *ArtifactSetsTestSuite) TestComposeFilesPerConfig() {
+	defaultConfig := suite.writeConfig("fleet.toml", "nginx:alpine")
+	stagingConfig := suite.writeConfig("fleet.staging.toml", "nginx:1.27")
+	suite.Equal(".fleet", getComposeOutputDir(defaultConfig))
+	suite.Equal(filepath.Join(".fleet", "staging"), getComposeOutputDir(stagingConfig))
+
+	_, err := writeComposeFiles(defaultConfig, generateDockerCompose(defaultConfig))
+	suite.Require().NoError(err)
+	files, err := writeComposeFiles(stagingConfig, generateDockerCompose(stagingConfig))
+	suite.Require().NoError(err)
+	suite.Equal([]string{filepath.Join(".fleet", "staging", composeFileName)}, files.Files)
+	suite.Contains(composeArgs(files, "ps"), "--project-directory", "Paths stay relative to .fleet")
+
+	compose, err := readDockerCompose(filepath.Join(".fleet", composeFileName))
+	suite.Require().NoError(err)
+	suite.Equal("nginx:alpine", compose.Services["web"].Image, "The default config keeps its files")
+
+	stagingConfig.ComposeOutputDir = "compose"
+	suite.Equal("compose", getComposeOutputDir(stagingConfig), "compose_output_dir wins")
+}
+
+func (suite *ArtifactSetsTestSuite) TestGeneratedFilesPerConfig() {
+	config := &Config{Project: "shop", ArtifactSet: "staging", Secrets: secretsEnvFile, Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, SSL: true, Database: "postgres:16", DatabaseExtensions: []string{"postgis"}},
+		{Name: "worker", Image: "node:20", Command: "node worker.js", WaitFor: []string{"api:port:3000"}},
+	}}
+	compose := generateDockerCompose(config)
+	plan := compose.Artifacts
+	dir := filepath.Join(".fleet", "staging")
+	suite.Equal(dir, getArtifactDir(config))
+
+	for _, name := range []string{"nginx.conf", "wait-for.sh", "postgres-16-init.sql", filepath.Join("env", "api.env")} {
+		_, planned := plan.file(filepath.Join(dir, name))
+		suite.True(planned, "%s is generated in the directory of the config", name)
+		_, shared := plan.file(filepath.Join(".fleet", name))
+		suite.False(shared, "%s isn't shared with the default config", name)
+	}
+	suite.Contains(compose.Services["worker"].Volumes, "./staging/wait-for.sh:"+waitForScriptPath+":ro")
+	suite.Contains(compose.Services["postgres-16"].Volumes, "./staging/postgres-16-init.sql:/docker-entrypoint-initdb.d/init.sql:ro")
+	suite.Contains(compose.Services["api"].EnvFile, "./staging/env/api.env")
+	suite.Equal(filepath.Join(dir, "ssl"), getSSLDir(config))
+
+	suite.Equal("config-logs", getArtifactSetName("fleet.logs.toml"))
+	suite.Equal("config-native", getArtifactSetName("fleet.native.toml"))
+}
+
+func (suite *ArtifactSetsTestSuite) TestRunningConfig() {
+	config := suite.writeConfig("fleet.staging.toml", "nginx:alpine")
+	suite.writeConfig("fleet.toml", "nginx:alpine")
+
+	forget, err := recordRunningConfig("fleet.toml", config)
+	suite.Require().NoError(err)
+	forget()
+	suite.NoFileExists(runningConfigFile, "A stack that failed to start isn't running")
+
+	_, err = recordRunningConfig("fleet.staging.toml", config)
+	suite.Require().NoError(err)
+	forget, err = recordRunningConfig("fleet.toml", config)
+	suite.Require().NoError(err)
+	forget()
+	running, err := loadRunningConfig()
+	suite.Require().NoError(err)
+	suite.Equal("fleet.staging.toml", running.Config, "The previous stack is still the running one")
+	suite.Equal(".fleet/staging", running.ComposeDir)
+
+	fs := flag.NewFlagSet("down", flag.ContinueOnError)
+	fs.String("f", "fleet.toml", "Config file")
+	suite.Require().NoError(fs.Parse(nil))
+	suite.Equal("fleet.staging.toml", resolveRunningConfigFile(fs, "fleet.toml"), "Without -f the running config is used")
+
+	suite.Require().NoError(fs.Parse([]string{"-f", "fleet.toml"}))
+	suite.Equal("fleet.toml", resolveRunningConfigFile(fs, "fleet.toml"), "-f wins")
+
+	clearRunningConfig("fleet.toml")
+	suite.FileExists(runningConfigFile, "Stopping another config keeps the record")
+	clearRunningConfig("fleet.staging.toml")
+	suite.NoFileExists(runningConfigFile)
+}
+
+func TestArtifactSetsSuite(t *testing.T) {
+	suite.Run(t, new(ArtifactSetsTestSuite))
+}
@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"embed"
@@ -11,7 +11,14 @@ var scriptsFS embed.FS
 
 //go:embed templates/compose/docker-compose.dnsmasq.yml
 //go:embed templates/dockerfiles/Dockerfile.dnsmasq templates/dockerfiles/Dockerfile.nginx templates/nginx/nginx.conf.tmpl
+//go:embed templates/nginx/php/*.conf.tmpl
 var templatesFS embed.FS
 
 //go:embed config/services/dnsmasq.conf config/services/hosts.test
-var configFS embed.FS
\ No newline at end of file
+var configFS embed.FS
+
+//go:embed config/versions.json
+var embeddedVersionData []byte
+
+//go:embed templates/services/*.toml
+var serviceTemplatesFS embed.FS
@@ -0,0 +1,350 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Severities of audit findings, fleet audit fails on high ones
+const (
+	auditHigh   = "high"
+	auditMedium = "medium"
+	auditLow    = "low"
+)
+
+// auditSeverityOrder sorts findings with the most severe first
+var auditSeverityOrder = map[string]int{auditHigh: 0, auditMedium: 1, auditLow: 2}
+
+// auditDataPorts are the ports databases, caches and search engines listen on
+var auditDataPorts = map[string]string{
+	"5432":  "PostgreSQL",
+	"3306":  "MySQL",
+	"27017": "MongoDB",
+	"6379":  "Redis",
+	"11211": "Memcached",
+	"9200":  "Elasticsearch",
+	"7700":  "Meilisearch",
+	"8108":  "Typesense",
+}
+
+// auditDataImages are the images of databases, caches and search engines, whatever
+// port they are published on
+var auditDataImages = []string{"postgres", "mysql", "mariadb", "mongo", "redis", "memcached", "elasticsearch", "opensearch", "getmeili/meilisearch", "typesense/typesense"}
+
+// auditWeakPasswords are defaults and guessable values of credentials
+var auditWeakPasswords = []string{"password", "rootpassword", "secret", "root", "admin", "changeme", "minioadmin", "123456"}
+
+// auditAuthPattern matches variables of services that log users in
+var auditAuthPattern = regexp.MustCompile(`(?i)(AUTH|SESSION|JWT|OAUTH|COOKIE|LOGIN)`)
+
+// auditAuthFrameworks are the frameworks that come with logins and sessions
+var auditAuthFrameworks = []string{"laravel", "symfony", "drupal", "wordpress"}
+
+// AuditFinding is a security issue found by fleet audit
+type AuditFinding struct {
+	Severity string
+	Service  string
+	Message  string
+	Fix      string
+}
+
+// inspectImageUser returns the user a local image runs as, false when the image isn't
+// available locally
+var inspectImageUser = func(image string) (string, bool) {
+	output, err := newCommand("docker", "image", "inspect", "--format", "{{.Config.User}}", image).Output()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(output)), true
+}
+
+// parsePublishedPort returns the host address and container port of a port mapping,
+// like 127.0.0.1:5432:5432/tcp. The address is empty when the port listens on every
+// interface.
+func parsePublishedPort(mapping string) (address, containerPort string) {
+	parts := strings.Split(strings.Split(mapping, "/")[0], ":")
+	containerPort = parts[len(parts)-1]
+	if len(parts) == 3 {
+		address = strings.Trim(parts[0], "[]")
+	}
+	return address, containerPort
+}
+
+// isLocalAddress checks if a published port only listens on the loopback interface
+func isLocalAddress(address string) bool {
+	return address == "localhost" || address == "::1" || strings.HasPrefix(address, "127.")
+}
+
+// isDataImage checks if an image runs a database, cache or search engine
+func isDataImage(image string) bool {
+	repository := getImageRepository(image)
+	for _, name := range auditDataImages {
+		if repository == name || strings.HasSuffix(repository, "/"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditPublicPorts flags databases, caches and search engines published on every
+// interface, where anyone on the network can reach them
+func auditPublicPorts(compose *DockerCompose) []AuditFinding {
+	var findings []AuditFinding
+	for _, name := range sortedKeys(compose.Services) {
+		service := compose.Services[name]
+		for _, mapping := range service.Ports {
+			address, port := parsePublishedPort(mapping)
+			kind, isDataPort := auditDataPorts[port]
+			if isLocalAddress(address) || (!isDataPort && !isDataImage(service.Image)) {
+				continue
+			}
+			if kind == "" {
+				kind = "Data"
+			}
+			findings = append(findings, AuditFinding{
+				Severity: auditHigh,
+				Service:  name,
+				Message:  fmt.Sprintf("%s port %s is published on every network interface (%s)", kind, port, mapping),
+				Fix:      fmt.Sprintf("Publish it on 127.0.0.1 only (\"127.0.0.1:%s\"), or drop the port and connect from containers by service name", strings.TrimPrefix(mapping, address+":")),
+			})
+		}
+	}
+	return findings
+}
+
+// auditDefaultPasswords flags credentials left at their default or a guessable value
+func auditDefaultPasswords(config *Config) []AuditFinding {
+	var findings []AuditFinding
+	weak := func(value string) bool {
+		return containsString(auditWeakPasswords, strings.ToLower(value))
+	}
+
+	for _, svc := range config.Services {
+		if svc.Database != "" {
+			dbType, _ := parseDatabaseType(svc.Database)
+			if weak(getEnvOrDefault(svc.DatabasePassword, "password")) {
+				findings = append(findings, AuditFinding{
+					Severity: auditMedium,
+					Service:  svc.Name,
+					Message:  fmt.Sprintf("The %s password is a default or guessable value", dbType),
+					Fix:      "Set database_password to a generated value",
+				})
+			}
+			if (dbType == "mysql" || dbType == "mariadb") && weak(getEnvOrDefault(svc.DatabaseRootPassword, "rootpassword")) {
+				findings = append(findings, AuditFinding{
+					Severity: auditMedium,
+					Service:  svc.Name,
+					Message:  fmt.Sprintf("The %s root password is a default or guessable value", dbType),
+					Fix:      "Set database_root_password to a generated value",
+				})
+			}
+		}
+
+		if compatType, _ := parseCompatType(svc.Compat); compatType == "minio" && weak(getEnvOrDefault(svc.CompatSecretKey, "minioadmin")) {
+			findings = append(findings, AuditFinding{
+				Severity: auditMedium,
+				Service:  svc.Name,
+				Message:  "The MinIO secret key is a default or guessable value",
+				Fix:      "Set compat_access_key and compat_secret_key",
+			})
+		}
+
+		for _, key := range sortedKeys(svc.Environment) {
+			if isSecretEnvVar(key, svc.Environment[key]) && weak(svc.Environment[key]) {
+				findings = append(findings, AuditFinding{
+					Severity: auditMedium,
+					Service:  svc.Name,
+					Message:  fmt.Sprintf("%s is set to a guessable value", key),
+					Fix:      fmt.Sprintf("Generate a value for %s, e.g. with 'openssl rand -hex 32'", key),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// handlesAuth checks if a service logs users in, from its framework or variables
+func handlesAuth(svc *Service) bool {
+	if containsString(auditAuthFrameworks, svc.Framework) {
+		return true
+	}
+	for key := range svc.Environment {
+		if auditAuthPattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditMissingSSL flags domains of services with logins served over plain HTTP
+func auditMissingSSL(config *Config) []AuditFinding {
+	var findings []AuditFinding
+	for _, svc := range config.Services {
+		domain := getDomainForService(&svc)
+		if domain == "" || svc.SSL || !handlesAuth(&svc) {
+			continue
+		}
+		findings = append(findings, AuditFinding{
+			Severity: auditMedium,
+			Service:  svc.Name,
+			Message:  fmt.Sprintf("%s handles logins or sessions over plain HTTP", domain),
+			Fix:      "Set ssl = true, so cookies and tokens aren't sent in clear text and Secure cookies work like in production",
+		})
+	}
+	return findings
+}
+
+// auditKeyFiles flags private keys of the proxy certificates other users can read
+func auditKeyFiles(sslDir string) []AuditFinding {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	keys, _ := filepath.Glob(filepath.Join(sslDir, "*.key"))
+	sort.Strings(keys)
+
+	var findings []AuditFinding
+	for _, key := range keys {
+		info, err := os.Stat(key)
+		if err != nil || info.Mode().Perm()&0004 == 0 {
+			continue
+		}
+		findings = append(findings, AuditFinding{
+			Severity: auditHigh,
+			Service:  "nginx-proxy",
+			Message:  fmt.Sprintf("%s is readable by every user (%s)", key, info.Mode().Perm()),
+			Fix:      fmt.Sprintf("chmod 600 %s", key),
+		})
+	}
+	return findings
+}
+
+// auditRootMounts flags containers that run as root and can write to host paths, since
+// files they create are owned by root and a compromised container can change them
+func auditRootMounts(compose *DockerCompose) []AuditFinding {
+	var findings []AuditFinding
+	for _, name := range sortedKeys(compose.Services) {
+		service := compose.Services[name]
+
+		var writable []string
+		for _, volume := range service.Volumes {
+			source, rest := splitVolume(volume)
+			if source == "" || !isHostPathSource(source) {
+				continue
+			}
+			if strings.HasSuffix(source, "docker.sock") {
+				findings = append(findings, AuditFinding{
+					Severity: auditMedium,
+					Service:  name,
+					Message:  "Mounts the Docker socket, which gives it root access to the host",
+					Fix:      "Only mount the socket into containers you trust, and keep their images pinned with 'fleet lock'",
+				})
+				continue
+			}
+			if !containsString(strings.Split(rest, ":")[1:], "ro") {
+				writable = append(writable, source)
+			}
+		}
+		if len(writable) == 0 {
+			continue
+		}
+
+		if service.Image != "" {
+			if user, found := inspectImageUser(service.Image); found && user != "" && user != "root" && user != "0" && !strings.HasPrefix(user, "0:") {
+				continue
+			}
+		}
+		findings = append(findings, AuditFinding{
+			Severity: auditLow,
+			Service:  name,
+			Message:  fmt.Sprintf("Runs as root with writable bind mounts of %s", strings.Join(writable, ", ")),
+			Fix:      fmt.Sprintf("Add :ro to mounts it only reads, or run it as your user with user: \"1000:1000\" in %s", composeOverrideFileName),
+		})
+	}
+	return findings
+}
+
+// auditStack reviews the config and generated stack of a project
+func auditStack(config *Config, compose *DockerCompose) []AuditFinding {
+	var findings []AuditFinding
+	findings = append(findings, auditPublicPorts(compose)...)
+	findings = append(findings, auditDefaultPasswords(config)...)
+	findings = append(findings, auditMissingSSL(config)...)
+	findings = append(findings, auditKeyFiles(getSSLDir(config))...)
+	findings = append(findings, auditRootMounts(compose)...)
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return auditSeverityOrder[findings[i].Severity] < auditSeverityOrder[findings[j].Severity]
+	})
+	return findings
+}
+
+// printAuditFindings prints the findings with how to fix each one
+func printAuditFindings(out io.Writer, findings []AuditFinding) {
+	icons := map[string]string{auditHigh: "🔴", auditMedium: "🟠", auditLow: "🟡"}
+	for _, finding := range findings {
+		fmt.Fprintf(out, "%s %-6s %s: %s\n", emojiOr(icons[finding.Severity], "-"), finding.Severity, finding.Service, finding.Message)
+		fmt.Fprintf(out, "   Fix: %s\n", finding.Fix)
+	}
+}
+
+func printAuditUsage() {
+	fmt.Println("Fleet audit - Review the security of the stack")
+	fmt.Println("\nUsage: fleet audit [options]")
+	fmt.Println("\nChecks for databases published on every interface, default passwords, logins")
+	fmt.Println("served without SSL, readable certificate keys and root containers writing to")
+	fmt.Println("host paths. Exits with an error when a high severity issue is found.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --strict    Also fail on medium and low severity issues")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+}
+
+func handleAudit() {
+	if len(os.Args) > 2 && os.Args[2] == "help" {
+		printAuditUsage()
+		return
+	}
+
+	fs := newCommandFlagSet("audit", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	strict := fs.Bool("strict", false, "Fail on any issue")
+	fs.Usage = printAuditUsage
+
+	fs.Parse(os.Args[2:])
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+
+	infof("🔒 Auditing %s...\n\n", config.Project)
+	findings := auditStack(config, generateDockerCompose(config))
+	if len(findings) == 0 {
+		infoln("✅ No issues found")
+		return
+	}
+	printAuditFindings(os.Stdout, findings)
+
+	high := 0
+	for _, finding := range findings {
+		if finding.Severity == auditHigh {
+			high++
+		}
+	}
+	outputf("\n%d issues, %d of high severity\n", len(findings), high)
+	if high > 0 || *strict {
+		os.Exit(1)
+	}
+}
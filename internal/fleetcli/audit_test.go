@@ -0,0 +1,155 @@
+package fleetcli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *AuditTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *AuditTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *AuditTestSuite) TestParsePublishedPort() {
+	testCases := map[string][2]string{
+		"5432:5432":           {"", "5432"},
+		"127.0.0.1:6379:6379": {"127.0.0.1", "6379"},
+		"0.0.0.0:3306:3306":   {"0.0.0.0", "3306"},
+		"8080:80/tcp":         {"", "80"},
+		"27017":               {"", "27017"},
+	}
+	for mapping, expected := range testCases {
+		address, port := parsePublishedPort(mapping)
+		suite.Equal(expected, [2]string{address, port}, mapping)
+	}
+}
+
+func (suite *AuditTestSuite) TestAuditPublicPorts() {
+	compose := &DockerCompose{Services: map[string]DockerService{
+		"db":    {Image: "postgres:16", Ports: []string{"5432:5432"}},
+		"cache": {Image: "redis:7", Ports: []string{"127.0.0.1:6379:6379"}},
+		"store": {Image: "bitnami/redis:7", Ports: []string{"0.0.0.0:16379:6379"}},
+		"web":   {Image: "node:20", Ports: []string{"3000:3000"}},
+	}}
+
+	findings := auditPublicPorts(compose)
+	suite.Require().Len(findings, 2)
+	suite.Equal("db", findings[0].Service)
+	suite.Contains(findings[0].Message, "PostgreSQL port 5432")
+	suite.Contains(findings[0].Fix, `"127.0.0.1:5432:5432"`)
+	suite.Equal("store", findings[1].Service)
+	suite.Contains(findings[1].Fix, `"127.0.0.1:16379:6379"`)
+}
+
+func (suite *AuditTestSuite) TestAuditDefaultPasswords() {
+	config := &Config{Services: []Service{
+		{Name: "api", Database: "mysql:8.0", DatabasePassword: "Xk2v9q"},
+		{Name: "shop", Database: "postgres:16", Compat: "minio", CompatSecretKey: "Tz8w4p"},
+		{Name: "web", Environment: map[string]string{"JWT_SECRET": "secret", "APP_NAME": "admin"}},
+	}}
+
+	var messages []string
+	for _, finding := range auditDefaultPasswords(config) {
+		messages = append(messages, finding.Service+": "+finding.Message)
+	}
+	suite.Equal([]string{
+		"api: The mysql root password is a default or guessable value",
+		"shop: The postgres password is a default or guessable value",
+		"web: JWT_SECRET is set to a guessable value",
+	}, messages)
+}
+
+func (suite *AuditTestSuite) TestAuditMissingSSL() {
+	config := &Config{Services: []Service{
+		{Name: "shop", Runtime: "php:8.3", Framework: "laravel", Domain: "shop.test"},
+		{Name: "secure", Runtime: "php:8.3", Framework: "laravel", Domain: "secure.test", SSL: true},
+		{Name: "api", Image: "node:20", Port: 3000, Environment: map[string]string{"SESSION_DRIVER": "redis"}},
+		{Name: "docs", Image: "nginx:alpine", Domain: "docs.test"},
+	}}
+
+	findings := auditMissingSSL(config)
+	suite.Require().Len(findings, 2)
+	suite.Contains(findings[0].Message, "shop.test")
+	suite.Contains(findings[1].Message, "api.test")
+}
+
+func (suite *AuditTestSuite) TestAuditKeyFiles() {
+	sslDir := filepath.Join(".fleet", "ssl")
+	suite.Require().NoError(os.MkdirAll(sslDir, 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(sslDir, "shop.test.key"), []byte("key"), 0600))
+	suite.Require().NoError(os.WriteFile(filepath.Join(sslDir, "api.test.key"), []byte("key"), 0644))
+	suite.Require().NoError(os.Chmod(filepath.Join(sslDir, "api.test.key"), 0644))
+	suite.Require().NoError(os.WriteFile(filepath.Join(sslDir, "api.test.crt"), []byte("cert"), 0644))
+
+	findings := auditKeyFiles(sslDir)
+	suite.Require().Len(findings, 1)
+	suite.Contains(findings[0].Message, "api.test.key")
+	suite.Equal(auditHigh, findings[0].Severity)
+}
+
+func (suite *AuditTestSuite) TestAuditRootMounts() {
+	originalInspect := inspectImageUser
+	defer func() { inspectImageUser = originalInspect }()
+	inspectImageUser = func(image string) (string, bool) {
+		if image == "node:20" {
+			return "node", true
+		}
+		return "", true
+	}
+
+	compose := &DockerCompose{Services: map[string]DockerService{
+		"api":         {Image: "node:20", Volumes: []string{"../api:/app"}},
+		"shop-php":    {Image: "php:8.3-fpm", Volumes: []string{"../shop:/var/www/html", "/app/vendor", "vendor-cache:/cache"}},
+		"nginx-proxy": {Image: "nginx:alpine", Volumes: []string{"/home/dev/.fleet/nginx.conf:/etc/nginx/nginx.conf:ro"}},
+		"maintenance": {Image: "docker:cli", Volumes: []string{"/var/run/docker.sock:/var/run/docker.sock"}},
+	}}
+
+	findings := auditRootMounts(compose)
+	suite.Require().Len(findings, 2)
+	suite.Equal("maintenance", findings[0].Service)
+	suite.Contains(findings[0].Message, "Docker socket")
+	suite.Equal("shop-php", findings[1].Service)
+	suite.Equal("Runs as root with writable bind mounts of ../shop", findings[1].Message)
+}
+
+func (suite *AuditTestSuite) TestAuditStack() {
+	originalInspect := inspectImageUser
+	defer func() { inspectImageUser = originalInspect }()
+	inspectImageUser = func(image string) (string, bool) { return "", false }
+
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "db", Image: "postgres:16", Ports: []string{"5432:5432"}},
+		{Name: "api", Image: "node:20", Port: 3000, Database: "postgres:16", DatabasePassword: "Xk2v9q"},
+	}}
+
+	findings := auditStack(config, generateDockerCompose(config))
+	suite.Require().NotEmpty(findings)
+	suite.Equal(auditHigh, findings[0].Severity, "Most severe first")
+
+	var out bytes.Buffer
+	printAuditFindings(&out, findings)
+	suite.Contains(out.String(), "high   db: PostgreSQL port 5432 is published on every network interface")
+	suite.Contains(out.String(), "   Fix: ")
+}
+
+func TestAuditSuite(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
@@ -0,0 +1,142 @@
+package fleetcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// BinaryDeployer manages deployment of helper binaries to .fleet/bin
+type BinaryDeployer struct {
+	projectPath string
+	fleetBinDir string
+}
+
+// NewBinaryDeployer creates a new binary deployer
+func NewBinaryDeployer() *BinaryDeployer {
+	return &BinaryDeployer{
+		projectPath: ".",
+		fleetBinDir: filepath.Join(".fleet", "bin"),
+	}
+}
+
+// phpBinaryName returns the file name of fleet-php in .fleet/bin
+func (bd *BinaryDeployer) phpBinaryName() string {
+	if runtime.GOOS == "windows" {
+		return "fleet-php.cmd"
+	}
+	return "fleet-php"
+}
+
+// DeployPHPBinary deploys fleet-php to .fleet/bin. It is a script running 'fleet php' with
+// the fleet binary that started the project, so the two never run different versions.
+func (bd *BinaryDeployer) DeployPHPBinary() error {
+	// Create .fleet/bin directory
+	if err := os.MkdirAll(bd.fleetBinDir, 0755); err != nil {
+		return fmt.Errorf("failed to create .fleet/bin directory: %v", err)
+	}
+	
+	fleetPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the fleet binary: %v", err)
+	}
+	
+	// Rewritten on every start, so it follows the fleet binary when it moves or updates
+	script := fmt.Sprintf("#!/bin/sh\nexec %s php \"$@\"\n", shellQuote(fleetPath))
+	if runtime.GOOS == "windows" {
+		script = fmt.Sprintf("@\"%s\" php %%*\r\n", fleetPath)
+	}
+	
+	targetPath := filepath.Join(bd.fleetBinDir, bd.phpBinaryName())
+	if err := os.WriteFile(targetPath, []byte(script), 0755); err != nil {
+		return fmt.Errorf("failed to write fleet-php: %v", err)
+	}
+	
+	// Make executable on Unix systems, WriteFile keeps the mode of an existing file
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(targetPath, 0755); err != nil {
+			return fmt.Errorf("failed to make fleet-php executable: %v", err)
+		}
+	}
+	
+	return nil
+}
+
+// IsPHPBinaryDeployed checks if fleet-php is already deployed
+func (bd *BinaryDeployer) IsPHPBinaryDeployed() bool {
+	binaryName := bd.phpBinaryName()
+	binaryPath := filepath.Join(bd.fleetBinDir, binaryName)
+	_, err := os.Stat(binaryPath)
+	return err == nil
+}
+
+// RemovePHPBinary removes the fleet-php binary
+func (bd *BinaryDeployer) RemovePHPBinary() error {
+	binaryName := bd.phpBinaryName()
+	binaryPath := filepath.Join(bd.fleetBinDir, binaryName)
+	
+	// Remove the binary if it exists
+	if err := os.Remove(binaryPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove fleet-php: %v", err)
+	}
+	
+	// Try to remove bin directory if empty
+	os.Remove(bd.fleetBinDir)
+	
+	return nil
+}
+
+// GetPHPBinaryPath returns the full path to fleet-php
+func (bd *BinaryDeployer) GetPHPBinaryPath() string {
+	binaryName := bd.phpBinaryName()
+	absPath, _ := filepath.Abs(filepath.Join(bd.fleetBinDir, binaryName))
+	return absPath
+}
+
+// PrintUsageInstructions prints instructions for using fleet-php
+func (bd *BinaryDeployer) PrintUsageInstructions() {
+	binaryPath := bd.GetPHPBinaryPath()
+	
+	infoln("\n💡 PHP CLI tools available:")
+	infof("   fleet-php has been deployed to: %s\n", binaryPath)
+	infoln("\n   Available commands:")
+	infoln("   • fleet-php composer [args...]  - Run Composer commands")
+	infoln("   • fleet-php php [args...]       - Run PHP scripts")
+	infoln("   • fleet php <command> [args...] - The same commands, without fleet-php in PATH")
+	
+	// Add PATH instruction if not in PATH
+	if !bd.isInPath() {
+		infoln("\n   To use fleet-php from anywhere in this project:")
+		if runtime.GOOS == "windows" {
+			infof("   set \"PATH=%%PATH%%;%s\"\n", filepath.Dir(binaryPath))
+		} else {
+			infof("   export PATH=\"$PATH\":%s\n", shellQuote(filepath.Dir(binaryPath)))
+		}
+	}
+}
+
+// isInPath checks if .fleet/bin is in PATH
+func (bd *BinaryDeployer) isInPath() bool {
+	pathEnv := os.Getenv("PATH")
+	binDir, _ := filepath.Abs(bd.fleetBinDir)
+	
+	paths := filepath.SplitList(pathEnv)
+	for _, p := range paths {
+		absP, _ := filepath.Abs(p)
+		if absP == binDir {
+			return true
+		}
+	}
+	
+	return false
+}
+
+// CleanupBinaries removes all deployed binaries (called on fleet down --volumes)
+func (bd *BinaryDeployer) CleanupBinaries() error {
+	// Remove entire .fleet/bin directory
+	if err := os.RemoveAll(bd.fleetBinDir); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to cleanup binaries: %v", err)
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,321 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultBundleDir is where fleet bundle writes the bundle unless -o says otherwise
+const defaultBundleDir = "fleet-bundle"
+
+// Bundler copies the files a compose file mounts into a bundle directory and rewrites
+// their paths relative to it. Files already in the bundle, like the generated ones, are
+// kept.
+type Bundler struct {
+	// ComposeDir is the directory the paths of the compose file are relative to
+	ComposeDir string
+	ProjectDir string
+	BundleDir  string
+	// Skipped are the mounts left out of the bundle because they are outside the project
+	Skipped []string
+	// Dropped are the services left out of the bundle because they need the host
+	Dropped []string
+}
+
+// rewritePath returns the path of a host path of the compose file in the bundle.
+// Generated files are copied into the bundle, project files are referenced relative to
+// it, and host paths outside the project are left out.
+func (b *Bundler) rewritePath(source string) (string, bool, error) {
+	path := strings.ReplaceAll(source, "$$", "$")
+	if strings.HasPrefix(path, "~") || windowsDrivePattern.MatchString(path) {
+		return "", false, nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(b.ComposeDir, path)
+	}
+
+	if rel, err := filepath.Rel(b.ComposeDir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		if err := copyBundlePath(path, filepath.Join(b.BundleDir, rel)); err != nil {
+			return "", false, err
+		}
+		return escapeComposeInterpolation("./" + filepath.ToSlash(rel)), true, nil
+	}
+
+	rel, err := filepath.Rel(b.ProjectDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false, nil
+	}
+	rel, err = filepath.Rel(b.BundleDir, path)
+	if err != nil {
+		return "", false, nil
+	}
+	if !strings.HasPrefix(rel, "..") {
+		rel = "./" + rel
+	}
+	return escapeComposeInterpolation(filepath.ToSlash(rel)), true, nil
+}
+
+// Bundle rewrites the host paths of the volumes, env files and build contexts of a
+// compose file for the bundle
+func (b *Bundler) Bundle(compose *DockerCompose) error {
+	for _, name := range sortedKeys(compose.Services) {
+		service := compose.Services[name]
+
+		var volumes []string
+		for _, volume := range service.Volumes {
+			source, rest := splitVolume(volume)
+			if source == "" || !isHostPathSource(source) {
+				volumes = append(volumes, volume)
+				continue
+			}
+			path, kept, err := b.rewritePath(source)
+			if err != nil {
+				return err
+			}
+			if !kept {
+				b.Skipped = append(b.Skipped, fmt.Sprintf("%s: %s", name, volume))
+				continue
+			}
+			volumes = append(volumes, path+":"+rest)
+		}
+		service.Volumes = volumes
+
+		for i, envFile := range service.EnvFile {
+			path, kept, err := b.rewritePath(envFile)
+			if err != nil {
+				return err
+			}
+			if !kept {
+				return fmt.Errorf("env file %s of %s is outside the project", envFile, name)
+			}
+			service.EnvFile[i] = path
+		}
+
+		if service.Build != "" && !strings.Contains(service.Build, "://") && !strings.HasPrefix(service.Build, "git@") {
+			path, kept, err := b.rewritePath(service.Build)
+			if err != nil {
+				return err
+			}
+			if !kept {
+				return fmt.Errorf("build context %s of %s is outside the project", service.Build, name)
+			}
+			service.Build = path
+		}
+
+		compose.Services[name] = service
+	}
+
+	// The project directory only tells Fleet which project a volume belongs to
+	for name, volume := range compose.Volumes {
+		if volume.Labels != nil {
+			delete(volume.Labels, fleetProjectDirLabel)
+			compose.Volumes[name] = volume
+		}
+	}
+	return nil
+}
+
+// copyBundlePath copies a file or directory of .fleet into the bundle, keeping
+// permissions, so env files stay private and scripts executable. Files the bundle
+// already has are left alone.
+func copyBundlePath(source, target string) error {
+	info, err := os.Stat(source)
+	if os.IsNotExist(err) {
+		// Nothing to copy, compose creates missing mount sources when it starts
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	if info.IsDir() {
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		if err := os.MkdirAll(target, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to create %s: %w", target, err)
+		}
+		for _, entry := range entries {
+			if err := copyBundlePath(filepath.Join(source, entry.Name()), filepath.Join(target, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, err := os.Stat(target); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+	}
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	return out.Close()
+}
+
+// prepareBundleDir empties the directory of a previous bundle, and refuses to touch a
+// directory that isn't one
+func prepareBundleDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return os.MkdirAll(dir, 0755)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	composeFile := filepath.Join(dir, composeFileName)
+	if _, err := os.Stat(composeFile); len(entries) > 0 && (err != nil || !isGeneratedComposeFile(composeFile)) {
+		return fmt.Errorf("%s is not empty and isn't a Fleet bundle, choose another directory with -o", dir)
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// createBundle writes a compose file that runs the project without Fleet, and the files
+// it mounts, to a directory. The generated files are written into the bundle only, the
+// .fleet directory of the project is left as it is. It returns the bundler, with the
+// mounts and services left out.
+func createBundle(config *Config, dir string) (*Bundler, error) {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	bundleDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle directory %s: %w", dir, err)
+	}
+	composeDir, err := filepath.Abs(getComposeOutputDir(config))
+	if err != nil {
+		return nil, fmt.Errorf("invalid compose output directory: %w", err)
+	}
+	if bundleDir == projectDir || bundleDir == composeDir {
+		return nil, fmt.Errorf("the bundle needs its own directory, choose one with -o")
+	}
+
+	// Credentials go to env files next to the compose file, not into it
+	bundleConfig := *config
+	bundleConfig.Secrets = secretsEnvFile
+	compose := generateDockerCompose(&bundleConfig)
+
+	bundler := &Bundler{ProjectDir: projectDir, BundleDir: bundleDir}
+	// The scheduler runs tasks through the Docker socket of the host, the bundle can't
+	// mount it
+	if _, exists := compose.Services[maintenanceServiceName]; exists {
+		delete(compose.Services, maintenanceServiceName)
+		plan := getArtifactPlan(compose)
+		delete(plan.files, plan.path(maintenanceScriptFile))
+		bundler.Dropped = append(bundler.Dropped, maintenanceServiceName)
+	}
+
+	if err := prepareBundleDir(bundleDir); err != nil {
+		return nil, err
+	}
+	// Generated paths are relative to .fleet wherever the compose files are written
+	if err := writeArtifacts(getArtifactPlan(compose).rebase(defaultComposeOutputDir, bundleDir)); err != nil {
+		return nil, err
+	}
+	if bundler.ComposeDir, err = filepath.Abs(defaultComposeOutputDir); err != nil {
+		return nil, fmt.Errorf("invalid compose output directory: %w", err)
+	}
+	if err := bundler.Bundle(compose); err != nil {
+		return nil, err
+	}
+
+	// Compose names the project after the directory of the file, keep the name Fleet uses
+	compose.Name = composeProjectName
+	if err := writeDockerCompose(compose, filepath.Join(bundleDir, composeFileName)); err != nil {
+		return nil, err
+	}
+	sort.Strings(bundler.Skipped)
+	return bundler, nil
+}
+
+func printBundleUsage() {
+	fmt.Println("Fleet bundle - Write a compose setup that runs without Fleet")
+	fmt.Println("\nUsage: fleet bundle [options]")
+	fmt.Println("\nThe bundle holds a docker-compose.yml, the env files with the credentials of the")
+	fmt.Println("services and the generated nginx configs, certificates and scripts. Paths are")
+	fmt.Println("relative, start it with 'docker compose up' in the bundle directory.")
+	fmt.Println("\nOptions:")
+	fmt.Printf("  -o, --output  Bundle directory (default: %s)\n", defaultBundleDir)
+	fmt.Println("  -f, --file    Specify config file (default: fleet.toml)")
+	fmt.Println("  --wait        Wait for another fleet command in the project, e.g. --wait 1m")
+	fmt.Println("  --force       Take over the project from a stuck fleet command")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet bundle                 # Write the bundle to fleet-bundle/")
+	fmt.Println("  fleet bundle -o ci/stack     # Write it where CI expects it")
+}
+
+func handleBundle() {
+	if len(os.Args) > 2 && os.Args[2] == "help" {
+		printBundleUsage()
+		return
+	}
+
+	fs := newCommandFlagSet("bundle", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	output := fs.String("o", defaultBundleDir, "Bundle directory")
+	outputLong := fs.String("output", defaultBundleDir, "Bundle directory")
+	lockOptions := addProjectLockFlags(fs)
+	fs.Usage = printBundleUsage
+
+	fs.Parse(os.Args[2:])
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+	if *outputLong != defaultBundleDir {
+		*output = *outputLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+
+	// Existing files of .fleet are copied into the bundle, keep fleet up from rewriting them
+	release := lockProject("bundle", lockOptions)
+	defer release()
+
+	bundler, err := createBundle(config, *output)
+	if err != nil {
+		release()
+		log.Fatalf("❌ Error writing the bundle: %v", err)
+	}
+
+	infof("📦 Bundled %s into %s\n", config.Project, *output)
+	if len(bundler.Skipped) > 0 {
+		warnln("⚠️  These mounts are outside the project and were left out:")
+		for _, mount := range bundler.Skipped {
+			warnf("   %s\n", mount)
+		}
+	}
+	for _, service := range bundler.Dropped {
+		warnf("⚠️  %s needs the Docker socket of the host and was left out, run its tasks with fleet maintain run\n", service)
+	}
+	infof("💡 Start it with 'docker compose up -d' in %s. Its env/ folder holds credentials, keep it out of public repositories\n", *output)
+}
@@ -0,0 +1,116 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BundleTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *BundleTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *BundleTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *BundleTestSuite) TestBundler() {
+	project := suite.helper.TempDir()
+	composeDir := filepath.Join(project, ".fleet")
+	suite.Require().NoError(os.MkdirAll(filepath.Join(composeDir, "env"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(composeDir, "nginx.conf"), []byte("events {}\n"), 0644))
+	suite.Require().NoError(os.WriteFile(filepath.Join(composeDir, "env", "api.env"), []byte("DB_PASSWORD=secret\n"), 0600))
+
+	compose := &DockerCompose{Services: map[string]DockerService{
+		"nginx-proxy": {Volumes: []string{formatBindMount(filepath.Join(composeDir, "nginx.conf"), "/etc/nginx/nginx.conf:ro")}},
+		"api": {
+			Build:   "../api",
+			Volumes: []string{"../api:/app", "api-data:/data", "/app/node_modules", "/home/dev/.gitconfig:/root/.gitconfig:ro"},
+			EnvFile: []string{"./env/api.env"},
+		},
+	}}
+
+	bundler := &Bundler{ComposeDir: composeDir, ProjectDir: project, BundleDir: filepath.Join(project, "fleet-bundle")}
+	suite.Require().NoError(bundler.Bundle(compose))
+
+	suite.Equal([]string{"./nginx.conf:/etc/nginx/nginx.conf:ro"}, compose.Services["nginx-proxy"].Volumes)
+	suite.Equal([]string{"../api:/app", "api-data:/data", "/app/node_modules"}, compose.Services["api"].Volumes)
+	suite.Equal("../api", compose.Services["api"].Build)
+	suite.Equal([]string{"./env/api.env"}, compose.Services["api"].EnvFile)
+	suite.Equal([]string{"api: /home/dev/.gitconfig:/root/.gitconfig:ro"}, bundler.Skipped)
+
+	suite.FileExists(filepath.Join(project, "fleet-bundle", "nginx.conf"))
+	info, err := os.Stat(filepath.Join(project, "fleet-bundle", "env", "api.env"))
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0600), info.Mode().Perm(), "Credentials stay private")
+}
+
+func (suite *BundleTestSuite) TestCreateBundle() {
+	suite.Require().NoError(os.MkdirAll("api", 0755))
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Folder: "api", Database: "postgres:16"},
+	}}
+
+	bundler, err := createBundle(config, "fleet-bundle")
+	suite.Require().NoError(err)
+	suite.Empty(bundler.Skipped)
+	suite.Empty(bundler.Dropped)
+	suite.Empty(config.Secrets, "The config of the project is left as it is")
+	suite.NoFileExists(filepath.Join(".fleet", "nginx.conf"), "The generated files only go to the bundle")
+
+	compose, err := readDockerCompose(filepath.Join("fleet-bundle", composeFileName))
+	suite.Require().NoError(err)
+	suite.Equal(composeProjectName, compose.Name)
+	suite.Contains(compose.Services["api"].Volumes, "../api:/app")
+	suite.Contains(compose.Services["nginx-proxy"].Volumes, "./nginx.conf:/etc/nginx/nginx.conf:ro")
+	suite.NotEmpty(compose.Services["api"].EnvFile, "Credentials are moved to env files")
+	suite.FileExists(filepath.Join("fleet-bundle", "nginx.conf"))
+
+	data, err := os.ReadFile(filepath.Join("fleet-bundle", composeFileName))
+	suite.Require().NoError(err)
+	suite.NotContains(string(data), suite.helper.TempDir(), "No absolute host paths")
+
+	// A second run replaces the bundle, other directories are left alone
+	_, err = createBundle(config, "fleet-bundle")
+	suite.NoError(err)
+	suite.Require().NoError(os.MkdirAll("ci", 0755))
+	_, err = createBundle(config, "ci")
+	suite.NoError(err, "An empty directory is fine")
+	suite.Require().NoError(os.Remove(filepath.Join("ci", composeFileName)))
+	_, err = createBundle(config, "ci")
+	suite.ErrorContains(err, "isn't a Fleet bundle")
+}
+
+func (suite *BundleTestSuite) TestCreateBundleDropsMaintenanceScheduler() {
+	config := &Config{
+		Project:     "test",
+		Services:    []Service{{Name: "api", Image: "node:20", Port: 3000, Database: "postgres:16"}},
+		Maintenance: map[string]MaintenanceTask{"vacuum": {Service: "api", Preset: presetPostgresVacuum, Schedule: "0 3 * * 0"}},
+	}
+
+	bundler, err := createBundle(config, "fleet-bundle")
+	suite.Require().NoError(err)
+	suite.Equal([]string{maintenanceServiceName}, bundler.Dropped)
+
+	compose, err := readDockerCompose(filepath.Join("fleet-bundle", composeFileName))
+	suite.Require().NoError(err)
+	suite.NotContains(compose.Services, maintenanceServiceName)
+	suite.NoFileExists(filepath.Join("fleet-bundle", maintenanceScriptFile))
+}
+
+func TestBundleSuite(t *testing.T) {
+	suite.Run(t, new(BundleTestSuite))
+}
@@ -0,0 +1,330 @@
+package fleetcli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Commands run in cache containers. The memcached image has no client, but busybox nc.
+const (
+	redisFlushCommand     = "redis-cli FLUSHDB"
+	redisStatsCommand     = "redis-cli INFO"
+	memcachedFlushCommand = `printf 'flush_all\r\nquit\r\n' | nc localhost 11211`
+	memcachedStatsCommand = `printf 'stats\r\nquit\r\n' | nc localhost 11211`
+)
+
+// getCachePassword returns the Redis password of a service. Services without an image
+// may still set it with password, like the cache service itself does.
+func getCachePassword(svc *Service) string {
+	if svc.CachePassword != "" {
+		return svc.CachePassword
+	}
+	if svc.Password != "" && svc.Image == "" && strings.HasPrefix(svc.Cache, "redis") {
+		return svc.Password
+	}
+	return ""
+}
+
+// getCacheExec returns how to run a command in the cache container of a service, or nil
+// when the service has no cache
+func getCacheExec(svc *Service, redisCommand, memcachedCommand string) *MaintenanceExec {
+	cacheType, version := parseCacheType(svc.Cache)
+	target := getSharedCacheServiceName(cacheType, version)
+	switch cacheType {
+	case "redis":
+		exec := &MaintenanceExec{Target: target, Command: redisCommand}
+		if password := getCachePassword(svc); password != "" {
+			exec.Env = map[string]string{"REDISCLI_AUTH": password}
+		}
+		return exec
+	case "memcached":
+		return &MaintenanceExec{Target: target, Command: memcachedCommand}
+	}
+	return nil
+}
+
+// getFrameworkCacheExec returns the command clearing the framework cache of a PHP
+// service, or nil when its framework has none Fleet knows
+func getFrameworkCacheExec(svc *Service) *MaintenanceExec {
+	if !strings.HasPrefix(svc.Runtime, "php") {
+		return nil
+	}
+	framework := svc.Framework
+	if framework == "" {
+		framework = detectPHPFramework(svc.Folder)
+	}
+	switch strings.ToLower(framework) {
+	case "laravel", "lumen":
+		return &MaintenanceExec{Target: getAppServiceName(svc), Command: "php artisan cache:clear"}
+	case "symfony":
+		return &MaintenanceExec{Target: getAppServiceName(svc), Command: "php bin/console cache:clear"}
+	}
+	return nil
+}
+
+// getCacheFlushExecs returns the commands flushing the caches of the selected services,
+// or of every service. Shared cache containers are flushed once.
+func getCacheFlushExecs(config *Config, services []string) []*MaintenanceExec {
+	var execs []*MaintenanceExec
+	flushed := make(map[string]bool)
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if len(services) > 0 && !containsString(services, svc.Name) {
+			continue
+		}
+		if exec := getCacheExec(svc, redisFlushCommand, memcachedFlushCommand); exec != nil && !flushed[exec.Target] {
+			flushed[exec.Target] = true
+			execs = append(execs, exec)
+		}
+		if exec := getFrameworkCacheExec(svc); exec != nil {
+			execs = append(execs, exec)
+		}
+	}
+	return execs
+}
+
+// getCacheUsers maps each cache container to the services using it, in config order
+func getCacheUsers(config *Config) ([]string, map[string][]string) {
+	var targets []string
+	users := make(map[string][]string)
+	for i := range config.Services {
+		svc := &config.Services[i]
+		exec := getCacheExec(svc, redisStatsCommand, memcachedStatsCommand)
+		if exec == nil {
+			continue
+		}
+		if _, exists := users[exec.Target]; !exists {
+			targets = append(targets, exec.Target)
+		}
+		users[exec.Target] = append(users[exec.Target], svc.Name)
+	}
+	return targets, users
+}
+
+// CacheStats is the memory usage and hit rate of a cache
+type CacheStats struct {
+	UsedMemory int64
+	MaxMemory  int64
+	Keys       int64
+	Hits       int64
+	Misses     int64
+}
+
+// formatHitRate returns the share of lookups that hit, or "-" before the first lookup
+func (s *CacheStats) formatHitRate() string {
+	if s.Hits+s.Misses == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(s.Hits)*100/float64(s.Hits+s.Misses))
+}
+
+// formatMemory returns the memory used, and the limit when there is one
+func (s *CacheStats) formatMemory() string {
+	if s.MaxMemory == 0 {
+		return formatBytes(s.UsedMemory)
+	}
+	return fmt.Sprintf("%s / %s", formatBytes(s.UsedMemory), formatBytes(s.MaxMemory))
+}
+
+// parseStatsLines calls fn with the key and value of each "key<sep>value" line
+func parseStatsLines(output, prefix, separator string, fn func(key, value string)) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), prefix))
+		if key, value, found := strings.Cut(line, separator); found {
+			fn(strings.TrimSpace(key), strings.TrimSpace(value))
+		}
+	}
+}
+
+// parseRedisInfo reads the output of redis-cli INFO. Keys are summed over databases,
+// from lines like "db0:keys=12,expires=0,avg_ttl=0".
+func parseRedisInfo(output string) CacheStats {
+	var stats CacheStats
+	parseStatsLines(output, "", ":", func(key, value string) {
+		number, _ := strconv.ParseInt(value, 10, 64)
+		switch {
+		case key == "used_memory":
+			stats.UsedMemory = number
+		case key == "maxmemory":
+			stats.MaxMemory = number
+		case key == "keyspace_hits":
+			stats.Hits = number
+		case key == "keyspace_misses":
+			stats.Misses = number
+		case strings.HasPrefix(key, "db"):
+			for _, field := range strings.Split(value, ",") {
+				if keys, found := strings.CutPrefix(field, "keys="); found {
+					count, _ := strconv.ParseInt(keys, 10, 64)
+					stats.Keys += count
+				}
+			}
+		}
+	})
+	return stats
+}
+
+// parseMemcachedStats reads the output of the memcached stats command, lines like
+// "STAT get_hits 42"
+func parseMemcachedStats(output string) CacheStats {
+	var stats CacheStats
+	parseStatsLines(output, "STAT ", " ", func(key, value string) {
+		number, _ := strconv.ParseInt(value, 10, 64)
+		switch key {
+		case "bytes":
+			stats.UsedMemory = number
+		case "limit_maxbytes":
+			stats.MaxMemory = number
+		case "curr_items":
+			stats.Keys = number
+		case "get_hits":
+			stats.Hits = number
+		case "get_misses":
+			stats.Misses = number
+		}
+	})
+	return stats
+}
+
+// execInService runs a command in a compose service and returns its output
+var execInService = func(composeFiles ComposeFiles, exec *MaintenanceExec) (string, error) {
+	cmd, err := dockerCommand(append(composeArgs(composeFiles, "exec", "-T", exec.Target), getMaintenanceExecArgs(exec)...)...)
+	if err != nil {
+		return "", err
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// collectCacheStats returns the stats of a cache container
+func collectCacheStats(composeFiles ComposeFiles, config *Config, target string) (CacheStats, error) {
+	for i := range config.Services {
+		svc := &config.Services[i]
+		exec := getCacheExec(svc, redisStatsCommand, memcachedStatsCommand)
+		if exec == nil || exec.Target != target {
+			continue
+		}
+		output, err := execInService(composeFiles, exec)
+		if err != nil {
+			return CacheStats{}, err
+		}
+		if exec.Command == redisStatsCommand {
+			return parseRedisInfo(output), nil
+		}
+		return parseMemcachedStats(output), nil
+	}
+	return CacheStats{}, fmt.Errorf("no service uses %s", target)
+}
+
+func handleCache() {
+	if len(os.Args) < 3 {
+		printCacheUsage()
+		os.Exit(0)
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "flush", "clear":
+		handleCacheFlush(os.Args[3:])
+	case "stats":
+		handleCacheStats(os.Args[3:])
+	case "help":
+		printCacheUsage()
+	default:
+		fmt.Printf("Unknown cache command: %s\n\n", subcommand)
+		printCacheUsage()
+		os.Exit(1)
+	}
+}
+
+func printCacheUsage() {
+	fmt.Println("Fleet cache - Flush and inspect the caches of a project")
+	fmt.Println("\nUsage: fleet cache <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  flush [service...]  Flush Redis or Memcached and the Laravel or Symfony cache of services")
+	fmt.Println("  stats               Show the memory usage, keys and hit rate of each cache")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("\nServices with the same cache version share its container, flushing one flushes it for all.")
+}
+
+// loadCacheConfig parses the common flags of cache commands and loads the config
+func loadCacheConfig(name string, args []string) (*Config, []string) {
+	fs := newCommandFlagSet(name, flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+
+	rest := parseFlagsAndArgs(fs, args)
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	for _, name := range rest {
+		if findService(config, name) == nil {
+			log.Fatalf("❌ Unknown service: %s", name)
+		}
+	}
+	return config, rest
+}
+
+func handleCacheFlush(args []string) {
+	config, services := loadCacheConfig("cache flush", args)
+
+	execs := getCacheFlushExecs(config, services)
+	if len(execs) == 0 {
+		outputln("No caches to flush: set cache on a service, or use Laravel or Symfony")
+		return
+	}
+
+	composeFiles := getComposeFiles(config)
+	failed := false
+	for _, exec := range execs {
+		infof("🧹 Flushing %s: %s\n", exec.Target, exec.Command)
+		if _, err := execInService(composeFiles, exec); err != nil {
+			warnf("⚠️  Failed to flush %s: %v\n", exec.Target, err)
+			failed = true
+		}
+	}
+	if failed {
+		log.Fatalf("❌ Some caches weren't flushed, is the project running?")
+	}
+	infoln("✅ Caches flushed")
+}
+
+func handleCacheStats(args []string) {
+	config, _ := loadCacheConfig("cache stats", args)
+
+	targets, users := getCacheUsers(config)
+	if len(targets) == 0 {
+		outputln("No service has a cache")
+		return
+	}
+
+	composeFiles := getComposeFiles(config)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CACHE\tUSED BY\tMEMORY\tKEYS\tHIT RATE")
+	for _, target := range targets {
+		stats, err := collectCacheStats(composeFiles, config, target)
+		if err != nil {
+			fmt.Fprintf(w, "%s\t%s\tnot running\t-\t-\n", target, strings.Join(users[target], ", "))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", target, strings.Join(users[target], ", "), stats.formatMemory(), stats.Keys, stats.formatHitRate())
+	}
+	w.Flush()
+}
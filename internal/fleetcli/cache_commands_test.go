@@ -0,0 +1,133 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheCommandsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *CacheCommandsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *CacheCommandsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *CacheCommandsTestSuite) TestGetCacheFlushExecs() {
+	config := &Config{Services: []Service{
+		{Name: "api", Image: "nginx:alpine", Runtime: "php:8.3", Framework: "laravel", Cache: "redis:7.2", CachePassword: "secret"},
+		{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Framework: "symfony", Cache: "redis:7.2"},
+		{Name: "sessions", Cache: "memcached"},
+		{Name: "redis", Cache: "redis:6", Password: "legacy"},
+		{Name: "worker", Image: "node:20"},
+	}}
+
+	testCases := []struct {
+		name     string
+		services []string
+		expected []*MaintenanceExec
+	}{
+		{
+			name:     "laravel with password",
+			services: []string{"api"},
+			expected: []*MaintenanceExec{
+				{Target: "redis-72", Env: map[string]string{"REDISCLI_AUTH": "secret"}, Command: "redis-cli FLUSHDB"},
+				{Target: "api-php", Command: "php artisan cache:clear"},
+			},
+		},
+		{
+			name:     "symfony",
+			services: []string{"shop"},
+			expected: []*MaintenanceExec{
+				{Target: "redis-72", Command: "redis-cli FLUSHDB"},
+				{Target: "shop-php", Command: "php bin/console cache:clear"},
+			},
+		},
+		{
+			name:     "memcached",
+			services: []string{"sessions"},
+			expected: []*MaintenanceExec{{Target: "memcached-16", Command: memcachedFlushCommand}},
+		},
+		{
+			name:     "password of a cache service",
+			services: []string{"redis"},
+			expected: []*MaintenanceExec{{Target: "redis-6", Env: map[string]string{"REDISCLI_AUTH": "legacy"}, Command: "redis-cli FLUSHDB"}},
+		},
+		{
+			name:     "no cache",
+			services: []string{"worker"},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.Equal(tc.expected, getCacheFlushExecs(config, tc.services))
+		})
+	}
+
+	// The shared redis-72 is flushed once for both services
+	execs := getCacheFlushExecs(config, nil)
+	suite.Len(execs, 5)
+}
+
+func (suite *CacheCommandsTestSuite) TestParseRedisInfo() {
+	output := "# Memory\r\nused_memory:1048576\r\nused_memory_human:1.00M\r\nmaxmemory:0\r\n" +
+		"# Stats\r\nkeyspace_hits:30\r\nkeyspace_misses:10\r\n" +
+		"# Keyspace\r\ndb0:keys=12,expires=0,avg_ttl=0\r\ndb1:keys=3,expires=1,avg_ttl=100\r\n"
+
+	stats := parseRedisInfo(output)
+	suite.Equal(CacheStats{UsedMemory: 1048576, Keys: 15, Hits: 30, Misses: 10}, stats)
+	suite.Equal("75.0%", stats.formatHitRate())
+}
+
+func (suite *CacheCommandsTestSuite) TestParseMemcachedStats() {
+	output := "STAT pid 1\r\nSTAT bytes 2048\r\nSTAT curr_items 4\r\nSTAT get_hits 0\r\n" +
+		"STAT get_misses 0\r\nSTAT limit_maxbytes 67108864\r\nEND\r\n"
+
+	stats := parseMemcachedStats(output)
+	suite.Equal(CacheStats{UsedMemory: 2048, MaxMemory: 67108864, Keys: 4}, stats)
+	suite.Equal("-", stats.formatHitRate())
+}
+
+func (suite *CacheCommandsTestSuite) TestCollectCacheStats() {
+	originalExec := execInService
+	defer func() { execInService = originalExec }()
+
+	var executed *MaintenanceExec
+	execInService = func(composeFiles ComposeFiles, exec *MaintenanceExec) (string, error) {
+		executed = exec
+		return "used_memory:100\r\nkeyspace_hits:1\r\n", nil
+	}
+
+	config := &Config{Services: []Service{
+		{Name: "api", Cache: "redis:7.2", CachePassword: "secret"},
+		{Name: "shop", Cache: "redis:7.2"},
+	}}
+	targets, users := getCacheUsers(config)
+	suite.Equal([]string{"redis-72"}, targets)
+	suite.Equal([]string{"api", "shop"}, users["redis-72"])
+
+	stats, err := collectCacheStats(ComposeFiles{}, config, "redis-72")
+	suite.Require().NoError(err)
+	suite.Equal(int64(100), stats.UsedMemory)
+	suite.Equal("secret", executed.Env["REDISCLI_AUTH"])
+	suite.Equal(redisStatsCommand, executed.Command)
+}
+
+func TestCacheCommandsSuite(t *testing.T) {
+	suite.Run(t, new(CacheCommandsTestSuite))
+}
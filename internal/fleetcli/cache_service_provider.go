@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -13,14 +13,8 @@ type CacheServiceProvider struct {
 // NewCacheServiceProvider creates a new cache service provider
 func NewCacheServiceProvider() *CacheServiceProvider {
 	return &CacheServiceProvider{
-		defaultVersions: map[string]string{
-			"redis":     "7.2",
-			"memcached": "1.6",
-		},
-		supportedVersions: map[string][]string{
-			"redis":     {"6.0", "6.2", "7.0", "7.2", "7.4"},
-			"memcached": {"1.6", "1.6.23"},
-		},
+		defaultVersions:   getDefaultVersions(versionData.Cache),
+		supportedVersions: getVersionLists(versionData.Cache),
 	}
 }
 
@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -13,26 +13,8 @@ type CacheConfig struct {
 	MaxMemory string // For memory limits
 }
 
-// Supported cache service versions
-var supportedCacheVersions = map[string]map[string]string{
-	"redis": {
-		"6.0":     "redis:6.0-alpine",
-		"6.2":     "redis:6.2-alpine",
-		"7.0":     "redis:7.0-alpine",
-		"7.2":     "redis:7.2-alpine",
-		"7.4":     "redis:7.4-alpine",
-		"latest":  "redis:alpine",
-		"default": "redis:7.2-alpine",
-	},
-	"memcached": {
-		"1.6":     "memcached:1.6-alpine",
-		"1.6.21":  "memcached:1.6.21-alpine",
-		"1.6.22":  "memcached:1.6.22-alpine",
-		"1.6.23":  "memcached:1.6.23-alpine",
-		"latest":  "memcached:alpine",
-		"default": "memcached:1.6-alpine",
-	},
-}
+// Supported cache service versions, from config/versions.json
+var supportedCacheVersions = imageMaps(versionData.Cache)
 
 // parseCacheType parses cache type and version from a string like "redis:7.2"
 func parseCacheType(cacheString string) (cacheType string, version string) {
@@ -0,0 +1,179 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// cloudEnvVar turns on cloud mode outside of the cloud IDEs Fleet detects
+const cloudEnvVar = "FLEET_CLOUD"
+
+// Host ports handed to web services that don't set a port of their own
+const (
+	cloudPortBase  = 8000
+	cloudPortRange = 1000
+)
+
+// devcontainerPortsFile holds the port attributes to merge into devcontainer.json
+const devcontainerPortsFile = ".fleet/devcontainer-ports.json"
+
+// CloudPort is a service port published for the cloud IDE to forward
+type CloudPort struct {
+	Service       string
+	HostPort      int
+	ContainerPort int
+}
+
+// isCloud reports whether Fleet runs in a cloud IDE, where the hosts file and .test
+// domains don't reach the browser and ports are forwarded instead
+func isCloud(flagValue bool) bool {
+	return flagValue || isEnvEnabled(cloudEnvVar) || isEnvEnabled("CODESPACES") || os.Getenv("GITPOD_WORKSPACE_ID") != ""
+}
+
+// getCloudPortFromName returns the preferred port of a service without a port of its
+// own. It only depends on the name, so adding services doesn't move existing ones.
+func getCloudPortFromName(name string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return cloudPortBase + int(h.Sum32()%cloudPortRange)
+}
+
+// canForward checks if a service can be reached without the proxy. PHP-FPM speaks
+// FastCGI, not HTTP, so it needs the proxy in front of it.
+func canForward(svc *Service) bool {
+	isFPM := strings.HasPrefix(svc.Runtime, "php") && svc.Image == "" && svc.Build == ""
+	return !isFPM && getProxyTargetPort(svc) > 0
+}
+
+// getCloudPorts returns the published port of every service that would be behind the proxy.
+// Services keep their own port, the others get one derived from their name.
+func getCloudPorts(config *Config) []CloudPort {
+	var ports []CloudPort
+	var derived []*Service
+	used := make(map[int]bool)
+
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if getDomainForService(svc) == "" || !canForward(svc) {
+			continue
+		}
+		if port := getServicePort(svc); port > 0 {
+			ports = append(ports, CloudPort{Service: svc.Name, HostPort: port, ContainerPort: getProxyTargetPort(svc)})
+			used[port] = true
+			continue
+		}
+		derived = append(derived, svc)
+	}
+
+	// Resolve collisions in name order, so the result doesn't depend on the config order
+	sort.Slice(derived, func(i, j int) bool { return derived[i].Name < derived[j].Name })
+	for _, svc := range derived {
+		port := getCloudPortFromName(svc.Name)
+		for used[port] {
+			port = cloudPortBase + (port-cloudPortBase+1)%cloudPortRange
+		}
+		used[port] = true
+		ports = append(ports, CloudPort{Service: svc.Name, HostPort: port, ContainerPort: getProxyTargetPort(svc)})
+	}
+
+	sort.Slice(ports, func(i, j int) bool { return ports[i].HostPort < ports[j].HostPort })
+	return ports
+}
+
+// applyCloudPorts publishes the services that would be behind the proxy on their cloud ports
+func applyCloudPorts(compose *DockerCompose, config *Config) {
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if getDomainForService(svc) != "" && !canForward(svc) {
+			warnf("⚠️  Warning: service %s can't be reached without the proxy in cloud mode. PHP services need image = \"nginx:alpine\"\n", svc.Name)
+		}
+	}
+
+	for _, port := range getCloudPorts(config) {
+		service, exists := compose.Services[port.Service]
+		if !exists {
+			continue
+		}
+		service.Ports = append(service.Ports, fmt.Sprintf("%d:%d", port.HostPort, port.ContainerPort))
+		// A forwarded port can only be bound by one replica
+		if service.Deploy != nil && service.Deploy.Replicas > 1 {
+			warnf("⚠️  Warning: service %s runs a single replica in cloud mode\n", port.Service)
+			service.Deploy = nil
+		}
+		compose.Services[port.Service] = service
+	}
+}
+
+// getForwardedURL returns the URL the cloud IDE forwards a port to
+func getForwardedURL(port int) string {
+	// GitHub Codespaces: https://<codespace>-<port>.app.github.dev
+	if name := os.Getenv("CODESPACE_NAME"); name != "" {
+		domain := getEnvOrDefault(os.Getenv("GITHUB_CODESPACES_PORT_FORWARDING_DOMAIN"), "app.github.dev")
+		return fmt.Sprintf("https://%s-%d.%s", name, port, domain)
+	}
+	// Gitpod: https://<port>-<workspace host>
+	if workspaceURL := os.Getenv("GITPOD_WORKSPACE_URL"); workspaceURL != "" {
+		return fmt.Sprintf("https://%d-%s", port, strings.TrimPrefix(workspaceURL, "https://"))
+	}
+	return fmt.Sprintf("http://localhost:%d", port)
+}
+
+// printCloudURLs prints where the forwarded services can be opened
+func printCloudURLs(config *Config) {
+	ports := getCloudPorts(config)
+	if len(ports) == 0 {
+		return
+	}
+
+	infoln("☁️  Cloud mode: no proxy or .test domains, services are reached through forwarded ports")
+	for _, port := range ports {
+		outputf("   %-20s %s\n", port.Service, getForwardedURL(port.HostPort))
+	}
+}
+
+// generateDevcontainerPorts returns the forwardPorts and portsAttributes of devcontainer.json,
+// so the cloud IDE labels forwarded ports with their service names
+func generateDevcontainerPorts(config *Config) ([]byte, error) {
+	type portAttributes struct {
+		Label         string `json:"label"`
+		OnAutoForward string `json:"onAutoForward"`
+		Protocol      string `json:"protocol,omitempty"`
+	}
+
+	forwardPorts := []int{}
+	attributes := make(map[string]portAttributes)
+	for _, port := range getCloudPorts(config) {
+		forwardPorts = append(forwardPorts, port.HostPort)
+		attributes[strconv.Itoa(port.HostPort)] = portAttributes{
+			Label:         port.Service,
+			OnAutoForward: "notify",
+			Protocol:      "http",
+		}
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"forwardPorts":    forwardPorts,
+		"portsAttributes": attributes,
+	}, "", "  ")
+}
+
+// writeDevcontainerPorts writes the port attributes next to the compose file
+func writeDevcontainerPorts(config *Config) error {
+	data, err := generateDevcontainerPorts(config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(devcontainerPortsFile), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(devcontainerPortsFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", devcontainerPortsFile, err)
+	}
+	return nil
+}
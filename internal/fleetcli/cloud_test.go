@@ -0,0 +1,149 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CloudTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *CloudTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+
+	for _, name := range []string{cloudEnvVar, "CODESPACES", "CODESPACE_NAME", "GITHUB_CODESPACES_PORT_FORWARDING_DOMAIN", "GITPOD_WORKSPACE_ID", "GITPOD_WORKSPACE_URL"} {
+		suite.T().Setenv(name, "")
+	}
+}
+
+func (suite *CloudTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *CloudTestSuite) TestIsCloud() {
+	suite.False(isCloud(false))
+	suite.True(isCloud(true))
+
+	suite.T().Setenv("CODESPACES", "true")
+	suite.True(isCloud(false), "Codespaces is detected")
+
+	suite.T().Setenv("CODESPACES", "")
+	suite.T().Setenv("GITPOD_WORKSPACE_ID", "fleet-abc123")
+	suite.True(isCloud(false), "Gitpod is detected")
+}
+
+func (suite *CloudTestSuite) TestGetCloudPorts() {
+	config := &Config{
+		Project: "test",
+		Services: []Service{
+			{Name: "api", Image: "node:20", Port: 3000},
+			{Name: "web", Image: "nginx:alpine", Domain: "shop.test"},
+			{Name: "app", Runtime: "php:8.3", Domain: "app.test"},
+			{Name: "database", Image: "postgres"},
+		},
+	}
+
+	ports := getCloudPorts(config)
+	suite.Equal([]CloudPort{
+		{Service: "api", HostPort: 3000, ContainerPort: 3000},
+		{Service: "web", HostPort: getCloudPortFromName("web"), ContainerPort: 80},
+	}, ports, "PHP-FPM and services without a domain aren't forwarded")
+
+	// Ports don't depend on the order or number of services
+	config.Services = []Service{
+		{Name: "admin", Image: "nginx:alpine", Domain: "admin.test"},
+		{Name: "web", Image: "nginx:alpine", Domain: "shop.test"},
+	}
+	for _, port := range getCloudPorts(config) {
+		if port.Service == "web" {
+			suite.Equal(getCloudPortFromName("web"), port.HostPort)
+		}
+	}
+}
+
+func (suite *CloudTestSuite) TestGetCloudPortsResolvesCollisions() {
+	port := getCloudPortFromName("web")
+	config := &Config{
+		Project: "test",
+		Services: []Service{
+			{Name: "web", Image: "nginx:alpine", Domain: "shop.test"},
+			{Name: "api", Image: "node:20", Port: port},
+		},
+	}
+
+	ports := getCloudPorts(config)
+	suite.Len(ports, 2)
+	suite.NotEqual(ports[0].HostPort, ports[1].HostPort)
+	for _, p := range ports {
+		if p.Service == "api" {
+			suite.Equal(port, p.HostPort, "Explicit ports win")
+		}
+	}
+}
+
+func (suite *CloudTestSuite) TestGetForwardedURL() {
+	suite.Equal("http://localhost:3000", getForwardedURL(3000))
+
+	suite.T().Setenv("GITPOD_WORKSPACE_URL", "https://fleet-abc123.ws-eu114.gitpod.io")
+	suite.Equal("https://3000-fleet-abc123.ws-eu114.gitpod.io", getForwardedURL(3000))
+
+	suite.T().Setenv("CODESPACE_NAME", "fleet-xyz")
+	suite.Equal("https://fleet-xyz-3000.app.github.dev", getForwardedURL(3000))
+
+	suite.T().Setenv("GITHUB_CODESPACES_PORT_FORWARDING_DOMAIN", "preview.app.github.dev")
+	suite.Equal("https://fleet-xyz-3000.preview.app.github.dev", getForwardedURL(3000))
+}
+
+func (suite *CloudTestSuite) TestGenerateDockerComposeInCloudMode() {
+	config := &Config{
+		Project: "test",
+		Cloud:   true,
+		Services: []Service{
+			{Name: "api", Image: "node:20", Port: 3000},
+			{Name: "web", Image: "nginx:alpine", Domain: "shop.test"},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+	suite.NotContains(compose.Services, "nginx-proxy", "Cloud mode doesn't use the proxy")
+	suite.Equal([]string{"3000:3000"}, compose.Services["api"].Ports)
+	suite.Equal([]string{fmt.Sprintf("%d:80", getCloudPortFromName("web"))}, compose.Services["web"].Ports)
+}
+
+func (suite *CloudTestSuite) TestWriteDevcontainerPorts() {
+	config := &Config{
+		Project:  "test",
+		Services: []Service{{Name: "api", Image: "node:20", Port: 3000}},
+	}
+
+	suite.Require().NoError(writeDevcontainerPorts(config))
+
+	data, err := os.ReadFile(devcontainerPortsFile)
+	suite.Require().NoError(err)
+
+	var devcontainer struct {
+		ForwardPorts    []int `json:"forwardPorts"`
+		PortsAttributes map[string]struct {
+			Label string `json:"label"`
+		} `json:"portsAttributes"`
+	}
+	suite.Require().NoError(json.Unmarshal(data, &devcontainer))
+	suite.Equal([]int{3000}, devcontainer.ForwardPorts)
+	suite.Equal("api", devcontainer.PortsAttributes["3000"].Label)
+}
+
+func TestCloudSuite(t *testing.T) {
+	suite.Run(t, new(CloudTestSuite))
+}
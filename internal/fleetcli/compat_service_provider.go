@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -13,12 +13,8 @@ type CompatServiceProvider struct {
 // NewCompatServiceProvider creates a new compatibility service provider
 func NewCompatServiceProvider() *CompatServiceProvider {
 	return &CompatServiceProvider{
-		defaultVersions: map[string]string{
-			"minio": "2024",
-		},
-		supportedVersions: map[string][]string{
-			"minio": {"2023", "2024", "latest"},
-		},
+		defaultVersions:   getDefaultVersions(versionData.Compat),
+		supportedVersions: getVersionLists(versionData.Compat),
 	}
 }
 
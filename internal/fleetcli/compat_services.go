@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -15,14 +15,29 @@ type CompatConfig struct {
 	Region      string // For AWS/S3 region emulation
 }
 
-// Supported compatibility service versions
-var supportedCompatVersions = map[string]map[string]string{
-	"minio": {
-		"2023":    "minio/minio:RELEASE.2023-12-20T01-00-02Z",
-		"2024":    "minio/minio:RELEASE.2024-01-16T16-07-38Z",
-		"latest":  "minio/minio:latest",
-		"default": "minio/minio:RELEASE.2024-01-16T16-07-38Z",
-	},
+// Supported compatibility service versions, from config/versions.json
+var supportedCompatVersions = imageMaps(versionData.Compat)
+
+const (
+	minIOAPIPort     = 9000
+	minIOConsolePort = 9001
+	// minIODomain serves the S3 API of the first MinIO of the project, and
+	// minIOConsoleDomain its console. Other versions get domains named after their service.
+	minIODomain        = "minio.test"
+	minIOConsoleDomain = "minio-console.test"
+)
+
+// MinIORoute is a MinIO service routed through the proxy
+type MinIORoute struct {
+	Service         string   // Compose service, e.g. minio-2024
+	Domain          string   // Domain of the S3 API, e.g. minio.test
+	ConsoleDomain   string   // Domain of the console, e.g. minio-console.test
+	Aliases         []string // .localhost names of the S3 API in unprivileged mode
+	ConsoleAliases  []string // .localhost names of the console in unprivileged mode
+	Upstream        string   // URL of the S3 API
+	ConsoleUpstream string   // URL of the console
+	AccessKey       string
+	SecretKey       string
 }
 
 // parseCompatType parses compatibility service type and version from a string like "minio:2024"
@@ -190,14 +205,7 @@ func configureMinIOService(service *DockerService, svc *Service, compatServiceNa
 	service.Volumes = append(service.Volumes, fmt.Sprintf("%s-data:/data", compatServiceName))
 	
 	// Set access and secret keys
-	accessKey := svc.CompatAccessKey
-	if accessKey == "" {
-		accessKey = "minioadmin"
-	}
-	secretKey := svc.CompatSecretKey
-	if secretKey == "" {
-		secretKey = "minioadmin"
-	}
+	accessKey, secretKey := getMinIOCredentials(svc)
 	
 	service.Environment["MINIO_ROOT_USER"] = accessKey
 	service.Environment["MINIO_ROOT_PASSWORD"] = secretKey
@@ -211,10 +219,10 @@ func configureMinIOService(service *DockerService, svc *Service, compatServiceNa
 	service.Environment["MINIO_BROWSER"] = "on"
 	
 	// Command to start MinIO server
-	service.Command = "server /data --console-address :9001"
+	service.Command = fmt.Sprintf("server /data --console-address :%d", minIOConsolePort)
 	
-	// Expose both API and Console ports internally (not to host)
-	// API port: 9000, Console port: 9001
+	// Expose both API and Console ports internally (not to host), the proxy
+	// routes minio.test and minio-console.test to them
 	
 	// Health check
 	service.HealthCheck = &HealthCheckYAML{
@@ -244,14 +252,7 @@ func addCompatEnvVars(service *DockerService, compatType, compatServiceName stri
 		service.Environment["MINIO_CONSOLE_URL"] = fmt.Sprintf("http://%s:9001", compatServiceName)
 		
 		// Access credentials
-		accessKey := svc.CompatAccessKey
-		if accessKey == "" {
-			accessKey = "minioadmin"
-		}
-		secretKey := svc.CompatSecretKey
-		if secretKey == "" {
-			secretKey = "minioadmin"
-		}
+		accessKey, secretKey := getMinIOCredentials(svc)
 		
 		service.Environment["AWS_ACCESS_KEY_ID"] = accessKey
 		service.Environment["AWS_SECRET_ACCESS_KEY"] = secretKey
@@ -270,4 +271,74 @@ func addCompatEnvVars(service *DockerService, compatType, compatServiceName stri
 		service.Environment["S3_USE_PATH_STYLE"] = "true"
 		service.Environment["AWS_S3_FORCE_PATH_STYLE"] = "true"
 	}
-}
\ No newline at end of file
+}
+
+// getMinIOCredentials returns the root user of the MinIO of a service
+func getMinIOCredentials(svc *Service) (accessKey, secretKey string) {
+	accessKey = svc.CompatAccessKey
+	if accessKey == "" {
+		accessKey = "minioadmin"
+	}
+	secretKey = svc.CompatSecretKey
+	if secretKey == "" {
+		secretKey = "minioadmin"
+	}
+	return accessKey, secretKey
+}
+
+// getMinIORoutes returns the MinIO services of the project with their proxy domains. The
+// first one is minio.test, the next ones are named after their service like
+// minio-2023.test. Credentials are those of the service that created the container.
+func getMinIORoutes(config *Config) []MinIORoute {
+	if !shouldAddNginxProxy(config) {
+		return nil
+	}
+
+	var routes []MinIORoute
+	seen := make(map[string]bool)
+	for i := range config.Services {
+		svc := &config.Services[i]
+		compatType, version := parseCompatType(svc.Compat)
+		if compatType != "minio" || getCompatImage(compatType, version) == "" {
+			continue
+		}
+		serviceName := getSharedCompatServiceName(compatType, version)
+		if seen[serviceName] {
+			continue
+		}
+		seen[serviceName] = true
+
+		route := MinIORoute{
+			Service:         serviceName,
+			Domain:          minIODomain,
+			ConsoleDomain:   minIOConsoleDomain,
+			Upstream:        fmt.Sprintf("http://%s:%d", serviceName, minIOAPIPort),
+			ConsoleUpstream: fmt.Sprintf("http://%s:%d", serviceName, minIOConsolePort),
+		}
+		if len(routes) > 0 {
+			route.Domain = serviceName + ".test"
+			route.ConsoleDomain = serviceName + "-console.test"
+		}
+		if config.Unprivileged {
+			route.Aliases = []string{strings.TrimSuffix(route.Domain, ".test") + ".localhost"}
+			route.ConsoleAliases = []string{strings.TrimSuffix(route.ConsoleDomain, ".test") + ".localhost"}
+		}
+		route.AccessKey, route.SecretKey = getMinIOCredentials(svc)
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// printMinIORoutes prints where the MinIO services of the project answer, with their
+// credentials
+func printMinIORoutes(config *Config) {
+	for _, route := range getMinIORoutes(config) {
+		api, console := "http://"+route.Domain, "http://"+route.ConsoleDomain
+		if config.Unprivileged {
+			api = fmt.Sprintf("http://%s:%d", route.Aliases[0], unprivilegedHTTPPort)
+			console = fmt.Sprintf("http://%s:%d", route.ConsoleAliases[0], unprivilegedHTTPPort)
+		}
+		infof("🪣 MinIO (%s): S3 API %s, console %s\n", route.Service, api, console)
+		infof("   Access key: %s, secret key: %s\n", route.AccessKey, route.SecretKey)
+	}
+}
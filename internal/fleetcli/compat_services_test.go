@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"strings"
@@ -409,6 +409,55 @@ func (suite *CompatServicesTestSuite) TestMinIOCommandParsing() {
 	suite.False(strings.Contains(service.Command, "  "))
 }
 
+func (suite *CompatServicesTestSuite) TestGetMinIORoutes() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Compat: "minio", CompatAccessKey: "fleet", CompatSecretKey: "s3cret"},
+		{Name: "worker", Image: "node:20", Compat: "minio:2024"},
+		{Name: "legacy", Image: "node:20", Compat: "minio:2023"},
+	}}
+
+	routes := getMinIORoutes(config)
+	suite.Equal([]MinIORoute{
+		{Service: "minio-2024", Domain: "minio.test", ConsoleDomain: "minio-console.test", Upstream: "http://minio-2024:9000",
+			ConsoleUpstream: "http://minio-2024:9001", AccessKey: "fleet", SecretKey: "s3cret"},
+		{Service: "minio-2023", Domain: "minio-2023.test", ConsoleDomain: "minio-2023-console.test", Upstream: "http://minio-2023:9000",
+			ConsoleUpstream: "http://minio-2023:9001", AccessKey: "minioadmin", SecretKey: "minioadmin"},
+	}, routes)
+
+	mappings := getDomainMappings(config)
+	suite.Contains(mappings, "minio.test")
+	suite.Contains(mappings, "minio-2023-console.test")
+
+	compose := generateDockerCompose(config)
+	suite.Contains(compose.Services["nginx-proxy"].DependsOn, "minio-2023")
+
+	config.Unprivileged = true
+	routes = getMinIORoutes(config)
+	suite.Equal([]string{"minio.localhost"}, routes[0].Aliases)
+	suite.Equal([]string{"minio-console.localhost"}, routes[0].ConsoleAliases)
+
+	config.Cloud = true
+	suite.Empty(getMinIORoutes(config), "Cloud IDEs forward ports instead")
+}
+
+func (suite *CompatServicesTestSuite) TestMinIONginxConfig() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "worker", Image: "python:3.12", Compat: "minio"},
+	}}
+	suite.True(shouldAddNginxProxy(config), "MinIO is routed without a web service")
+
+	nginxConf, err := generateNginxConfig(config)
+	suite.Require().NoError(err)
+	suite.Contains(nginxConf, "server_name minio.test;")
+	suite.Contains(nginxConf, "proxy_pass http://minio-2024:9000;")
+	suite.Contains(nginxConf, "client_max_body_size 0;")
+	suite.Contains(nginxConf, "server_name minio-console.test;")
+	suite.Contains(nginxConf, "proxy_pass http://minio-2024:9001;")
+
+	console := nginxConf[strings.Index(nginxConf, "server_name minio-console.test;"):]
+	suite.Contains(console, `proxy_set_header Connection "upgrade";`, "The console uses websockets")
+}
+
 func TestCompatServicesSuite(t *testing.T) {
 	suite.Run(t, new(CompatServicesTestSuite))
 }
\ No newline at end of file
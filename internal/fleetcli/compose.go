@@ -0,0 +1,804 @@
+package fleetcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// composeProjectName is the compose project every Fleet project runs under,
+// since compose names projects after the directory of the compose file (.fleet)
+const composeProjectName = "fleet"
+
+// Labels Fleet attaches to generated containers and volumes
+const (
+	fleetProjectLabel    = "com.fleet.project"
+	fleetProjectDirLabel = "com.fleet.project-dir"
+	fleetServiceLabel    = "com.fleet.service"
+	fleetPortLabel       = "com.fleet.port"
+)
+
+type DockerCompose struct {
+	Version  string                    `yaml:"version"`
+	Name     string                    `yaml:"name,omitempty"` // Project name, only set in bundles, see bundle.go
+	Services map[string]DockerService  `yaml:"services"`
+	Networks map[string]DockerNetwork  `yaml:"networks,omitempty"`
+	Volumes  map[string]DockerVolume   `yaml:"volumes,omitempty"`
+	// Artifacts are the files the services mount, written with the compose files, see artifact_plan.go
+	Artifacts *ArtifactPlan `yaml:"-"`
+}
+
+type DockerService struct {
+	Image           string            `yaml:"image,omitempty"`
+	Build           string            `yaml:"build,omitempty"`
+	Ports           []string          `yaml:"ports,omitempty"`
+	Volumes         []string          `yaml:"volumes,omitempty"`
+	Environment     map[string]string `yaml:"environment,omitempty"`
+	EnvFile         []string          `yaml:"env_file,omitempty"`
+	Networks        []string          `yaml:"networks,omitempty"`
+	Restart         string            `yaml:"restart,omitempty"`
+	DependsOn       []string          `yaml:"depends_on,omitempty"`
+	Command         string            `yaml:"command,omitempty"`
+	HealthCheck     *HealthCheckYAML  `yaml:"healthcheck,omitempty"`
+	WorkingDir      string            `yaml:"working_dir,omitempty"`
+	Labels          map[string]string `yaml:"labels,omitempty"`
+	Hostname        string            `yaml:"hostname,omitempty"`
+	ExtraHosts      []string          `yaml:"extra_hosts,omitempty"`
+	Runtime         string            `yaml:"runtime,omitempty"`
+	Deploy          *DockerDeploy     `yaml:"deploy,omitempty"`
+	Entrypoint      []string          `yaml:"entrypoint,omitempty"`
+	Develop         *DockerDevelop    `yaml:"develop,omitempty"`
+	StopSignal      string            `yaml:"stop_signal,omitempty"`
+	StopGracePeriod string            `yaml:"stop_grace_period,omitempty"`
+
+	// DependsOnConditions sets the condition of entries in DependsOn, see MarshalYAML
+	DependsOnConditions map[string]string `yaml:"-"`
+}
+
+// depends_on conditions of the compose specification
+const (
+	dependsOnStarted   = "service_started"
+	dependsOnHealthy   = "service_healthy"
+	dependsOnCompleted = "service_completed_successfully"
+)
+
+// dockerServiceFields is DockerService without its YAML methods
+type dockerServiceFields DockerService
+
+// dependsOnEntry is an entry of the long form of depends_on
+type dependsOnEntry struct {
+	Condition string `yaml:"condition"`
+}
+
+// MarshalYAML writes depends_on in its long form when a dependency has a condition
+func (s DockerService) MarshalYAML() (interface{}, error) {
+	if len(s.DependsOnConditions) == 0 || len(s.DependsOn) == 0 {
+		return dockerServiceFields(s), nil
+	}
+
+	entries := make(map[string]dependsOnEntry, len(s.DependsOn))
+	for _, dep := range s.DependsOn {
+		condition := s.DependsOnConditions[dep]
+		if condition == "" {
+			condition = dependsOnStarted
+		}
+		entries[dep] = dependsOnEntry{Condition: condition}
+	}
+
+	fields := dockerServiceFields(s)
+	fields.DependsOn = nil
+	var node, dependsOn yaml.Node
+	if err := node.Encode(fields); err != nil {
+		return nil, err
+	}
+	if err := dependsOn.Encode(entries); err != nil {
+		return nil, err
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "depends_on"}, &dependsOn)
+	return &node, nil
+}
+
+// UnmarshalYAML reads depends_on in its short or long form
+func (s *DockerService) UnmarshalYAML(value *yaml.Node) error {
+	var dependsOn *yaml.Node
+	if value.Kind == yaml.MappingNode {
+		fields := *value
+		fields.Content = nil
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			if value.Content[i].Value == "depends_on" && value.Content[i+1].Kind == yaml.MappingNode {
+				dependsOn = value.Content[i+1]
+				continue
+			}
+			fields.Content = append(fields.Content, value.Content[i], value.Content[i+1])
+		}
+		value = &fields
+	}
+
+	var fields dockerServiceFields
+	if err := value.Decode(&fields); err != nil {
+		return err
+	}
+	*s = DockerService(fields)
+	if dependsOn == nil {
+		return nil
+	}
+
+	var entries map[string]dependsOnEntry
+	if err := dependsOn.Decode(&entries); err != nil {
+		return err
+	}
+	for dep, entry := range entries {
+		s.DependsOn = append(s.DependsOn, dep)
+		if entry.Condition != "" && entry.Condition != dependsOnStarted {
+			if s.DependsOnConditions == nil {
+				s.DependsOnConditions = make(map[string]string)
+			}
+			s.DependsOnConditions[dep] = entry.Condition
+		}
+	}
+	sort.Strings(s.DependsOn)
+	return nil
+}
+
+type HealthCheckYAML struct {
+	Test     []string `yaml:"test,omitempty"`
+	Interval string   `yaml:"interval,omitempty"`
+	Timeout  string   `yaml:"timeout,omitempty"`
+	Retries  int      `yaml:"retries,omitempty"`
+}
+
+// DockerDeploy is the deploy section of a compose service
+type DockerDeploy struct {
+	Replicas  int              `yaml:"replicas,omitempty"`
+	Resources *DockerResources `yaml:"resources,omitempty"`
+}
+
+// DockerResources reserves devices like GPUs for a compose service
+type DockerResources struct {
+	Reservations DockerReservations `yaml:"reservations"`
+}
+
+type DockerReservations struct {
+	Devices []DockerDevice `yaml:"devices,omitempty"`
+}
+
+type DockerDevice struct {
+	Driver       string   `yaml:"driver,omitempty"`
+	Count        string   `yaml:"count,omitempty"`
+	Capabilities []string `yaml:"capabilities"`
+}
+
+type DockerNetwork struct {
+	Driver   string                 `yaml:"driver,omitempty"`
+	Name     string                 `yaml:"name,omitempty"`
+	External bool                   `yaml:"external,omitempty"`
+	IPAM     *DockerNetworkIPAM     `yaml:"ipam,omitempty"`
+}
+
+type DockerNetworkIPAM struct {
+	Config []DockerNetworkIPAMConfig `yaml:"config,omitempty"`
+}
+
+type DockerNetworkIPAMConfig struct {
+	Subnet string `yaml:"subnet,omitempty"`
+}
+
+type DockerVolume struct {
+	Driver string            `yaml:"driver"`
+	Name   string            `yaml:"name,omitempty"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// buildServiceConfig creates the basic service configuration
+func buildServiceConfig(svc *Service) DockerService {
+	service := DockerService{
+		Networks: []string{"fleet-network"},
+		Restart:  "unless-stopped",
+	}
+
+	// Handle image or build
+	if svc.Image != "" {
+		service.Image = svc.Image
+	} else if svc.Build != "" {
+		service.Build = svc.Build
+	} else if isMockService(svc) {
+		// OpenAPI mock server
+		configureMockService(&service, svc)
+	} else if strings.HasPrefix(svc.Runtime, "php") {
+		// For PHP runtime, create PHP-FPM container
+		_, phpVersion := parsePHPRuntime(svc.Runtime)
+		service.Image = fmt.Sprintf("php:%s-fpm-alpine", phpVersion)
+	} else if strings.HasPrefix(svc.Runtime, "node") {
+		// For standalone Node.js runtime (no image specified), don't set anything here
+		// The complete service will be created by addNodeService
+		if svc.Image == "" {
+			// Return minimal service that will be replaced by addNodeService
+			return service
+		}
+		// For Node.js with nginx (build mode), keep the nginx image
+		service.Image = svc.Image
+	} else if strings.HasPrefix(svc.Runtime, "ruby") {
+		// The rest of the Ruby container is set up by addRubyService
+		_, rubyVersion := parseRubyRuntime(svc.Runtime)
+		service.Image = getRubyImage(rubyVersion)
+	}
+
+	// Handle command
+	if svc.Command != "" {
+		service.Command = svc.Command
+	}
+
+	return service
+}
+
+// configurePorts sets up port exposure for the service
+func configurePorts(service *DockerService, svc *Service) {
+	// Only expose ports if service has no domain
+	// Services with domains are accessed through nginx proxy
+	hasDomain := getDomainForService(svc) != ""
+	if !hasDomain {
+		if svc.Port > 0 {
+			service.Ports = []string{fmt.Sprintf("%d:%d", svc.Port, svc.Port)}
+		} else if len(svc.Ports) > 0 {
+			service.Ports = svc.Ports
+		}
+	}
+}
+
+// configureVolumes handles volume mounting logic
+func configureVolumes(service *DockerService, svc *Service, volumesNeeded map[string]bool, plan *ArtifactPlan) {
+	if svc.Folder != "" {
+		// Check nginx first (for both PHP and Node.js build mode)
+		if strings.Contains(strings.ToLower(svc.Image), "nginx") {
+			if strings.HasPrefix(svc.Runtime, "php") {
+				// nginx with PHP runtime
+				service.Volumes = append(service.Volumes, formatBindMount("../"+svc.Folder, "/var/www/html"))
+				
+				// Auto-detect framework if not specified
+				framework := svc.Framework
+				if framework == "" {
+					framework = detectPHPFramework(svc.hostPath(svc.Folder))
+				}
+				
+				// Parse PHP version from runtime
+				_, phpVersion := parsePHPRuntime(svc.Runtime)
+				
+				// Generate and mount PHP nginx config with version
+				configPath := planNginxPHPConfigWithVersion(plan, svc, framework, phpVersion)
+				var err error
+				if hasAssetsBuild(svc) && svc.AssetsDev {
+					err = addAssetsProxyToNginxConfig(plan, configPath, getAssetsServiceName(svc.Name))
+				}
+				if err == nil {
+					absPath, _ := filepath.Abs(svc.hostPath(configPath))
+					service.Volumes = append(service.Volumes, formatBindMount(absPath, "/etc/nginx/conf.d/default.conf:ro"))
+				}
+			} else if strings.HasPrefix(svc.Runtime, "node") && svc.BuildCommand != "" {
+				// nginx with Node.js runtime (build mode) - serve the build output
+				framework := detectNodeFramework(svc.hostPath(svc.Folder))
+				buildDir := "build" // Default for React, Create React App
+				if framework == "vue" || framework == "nuxt" {
+					buildDir = "dist"
+				}
+				service.Volumes = append(service.Volumes, formatBindMount(fmt.Sprintf("../%s/%s", svc.Folder, buildDir), "/usr/share/nginx/html"))
+			} else {
+				// Regular nginx service
+				service.Volumes = append(service.Volumes, formatBindMount("../"+svc.Folder, "/usr/share/nginx/html"))
+			}
+		} else if strings.HasPrefix(svc.Runtime, "php") {
+			// Standalone PHP-FPM containers, mount to /var/www/html
+			service.Volumes = append(service.Volumes, formatBindMount("../"+svc.Folder, "/var/www/html"))
+		} else if strings.HasPrefix(svc.Runtime, "node") {
+			// Standalone Node.js containers, mount to /app
+			service.Volumes = append(service.Volumes, formatBindMount("../"+svc.Folder, "/app"))
+			// Add node_modules volume for better performance (only for service mode)
+			if !isNodeBuildMode(svc) {
+				volumeName := fmt.Sprintf("%s_node_modules", strings.ReplaceAll(svc.Name, "-", "_"))
+				service.Volumes = append(service.Volumes, fmt.Sprintf("%s:/app/node_modules", volumeName))
+				volumesNeeded[volumeName] = true
+			}
+		} else if isRubyService(svc) {
+			// Ruby containers, mount to /app
+			service.Volumes = append(service.Volumes, formatBindMount("../"+svc.Folder, "/app"))
+			// Keep the installed gems in a volume, so bundle install only runs once
+			volumeName := getRubyBundleVolume(svc)
+			service.Volumes = append(service.Volumes, fmt.Sprintf("%s:%s", volumeName, rubyBundlePath))
+			volumesNeeded[volumeName] = true
+		} else {
+			// For other images, map to /app
+			service.Volumes = append(service.Volumes, formatBindMount("../"+svc.Folder, "/app"))
+		}
+	}
+
+	// Handle named volumes
+	for _, vol := range svc.Volumes {
+		service.Volumes = append(service.Volumes, normalizeVolume(vol))
+		// If it's a named volume (not a bind mount), track it
+		if !strings.Contains(vol, "/") && !strings.Contains(vol, ".") {
+			volName := strings.Split(vol, ":")[0]
+			volumesNeeded[volName] = true
+		}
+	}
+}
+
+// configureEnvironment sets up environment variables including database credentials
+func configureEnvironment(service *DockerService, svc *Service, config *Config) {
+	if len(svc.Environment) > 0 {
+		// Copied so the variables added for attached services don't end up in the config
+		service.Environment = make(map[string]string, len(svc.Environment))
+		for k, v := range svc.Environment {
+			service.Environment[k] = v
+		}
+	}
+
+	// Handle special password field for databases
+	if svc.Password != "" {
+		if service.Environment == nil {
+			service.Environment = make(map[string]string)
+		}
+		
+		configureDatabaseCredentials(service, svc.Image, svc.Password, config.Project)
+	}
+}
+
+// configureDatabaseCredentials sets database-specific environment variables
+func configureDatabaseCredentials(service *DockerService, image, password, project string) {
+	// Auto-detect database type and set appropriate password env var
+	if strings.Contains(image, "postgres") {
+		service.Environment["POSTGRES_PASSWORD"] = password
+		service.Environment["POSTGRES_DB"] = project
+	} else if strings.Contains(image, "mysql") || strings.Contains(image, "mariadb") {
+		service.Environment["MYSQL_ROOT_PASSWORD"] = password
+		service.Environment["MYSQL_DATABASE"] = project
+	} else if strings.Contains(image, "mongo") {
+		service.Environment["MONGO_INITDB_ROOT_USERNAME"] = "root"
+		service.Environment["MONGO_INITDB_ROOT_PASSWORD"] = password
+	} else if strings.Contains(image, "redis") {
+		service.Command = fmt.Sprintf("redis-server --requirepass %s", password)
+	}
+}
+
+// configureHealthCheck sets up health check configuration
+func configureHealthCheck(service *DockerService, svc *Service) {
+	if svc.HealthCheck.Test != "" {
+		service.HealthCheck = &HealthCheckYAML{
+			Test:     strings.Split(svc.HealthCheck.Test, " "),
+			Interval: svc.HealthCheck.Interval,
+			Timeout:  svc.HealthCheck.Timeout,
+			Retries:  svc.HealthCheck.Retries,
+		}
+		
+		// Set defaults if not specified
+		if service.HealthCheck.Interval == "" {
+			service.HealthCheck.Interval = "30s"
+		}
+		if service.HealthCheck.Timeout == "" {
+			service.HealthCheck.Timeout = "10s"
+		}
+		if service.HealthCheck.Retries == 0 {
+			service.HealthCheck.Retries = 3
+		}
+	}
+}
+
+// addServiceDependencies manages service dependencies
+func addServiceDependencies(service *DockerService, svc *Service) {
+	// Handle explicit dependencies
+	if len(svc.Needs) > 0 {
+		service.DependsOn = svc.Needs
+	}
+	
+	// Add PHP-FPM dependency for nginx with PHP runtime
+	if strings.Contains(strings.ToLower(svc.Image), "nginx") && strings.HasPrefix(svc.Runtime, "php") {
+		phpServiceName := fmt.Sprintf("%s-php", svc.Name)
+		found := false
+		for _, dep := range service.DependsOn {
+			if dep == phpServiceName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			service.DependsOn = append(service.DependsOn, phpServiceName)
+		}
+	}
+}
+
+// addSupportServices adds additional services based on configuration
+func addSupportServices(compose *DockerCompose, svc *Service, config *Config) {
+	// Add PHP-FPM service if this nginx service has PHP runtime
+	if strings.Contains(strings.ToLower(svc.Image), "nginx") && strings.HasPrefix(svc.Runtime, "php") {
+		addPHPFPMService(compose, svc, config)
+	}
+
+	// Add the assets build container of PHP services
+	if hasAssetsBuild(svc) {
+		addAssetsService(compose, svc)
+	}
+	
+	// Add Node.js service if specified
+	if strings.HasPrefix(svc.Runtime, "node") {
+		// For standalone Node.js services (no image specified) or build mode with nginx
+		if svc.Image == "" {
+			// For standalone Node.js, we need to replace the basic service with a fully configured one
+			addNodeService(compose, svc, config)
+		} else if strings.Contains(strings.ToLower(svc.Image), "nginx") && svc.BuildCommand != "" {
+			// For nginx with Node.js build, add a separate build container
+			addNodeService(compose, svc, config)
+		}
+	}
+	
+	// Complete Ruby containers with their bundle and puma command
+	if isRubyService(svc) {
+		addRubyService(compose, svc)
+	}
+
+	// Variables the attached services below add are subject to env_style and env_map
+	existingEnv := getEnvKeys(compose, svc.Name)
+
+	// Add database service if specified
+	if svc.Database != "" {
+		addDatabaseService(compose, svc, config)
+
+		// Scheduled backups of the service's database
+		addDatabaseBackupService(compose, svc)
+	}
+	
+	// Add cache service if specified
+	if svc.Cache != "" {
+		addCacheService(compose, svc, config)
+	}
+	
+	// Add search service if specified
+	if svc.Search != "" {
+		addSearchService(compose, svc, config)
+	}
+	
+	// Add compatibility service if specified
+	if svc.Compat != "" {
+		addCompatService(compose, svc, config)
+	}
+	
+	// Add email service if specified
+	if svc.Email != "" {
+		addEmailService(compose, svc, config)
+	}
+
+	// Add local LLM service if specified
+	if svc.AI != "" {
+		addAIService(compose, svc, config)
+	}
+
+	// Add message queue if specified
+	if svc.Queue != "" {
+		addQueueService(compose, svc, config)
+	}
+	
+	// Add Laravel Reverb service if specified (for Laravel/Lumen apps)
+	if svc.Reverb && (svc.Framework == "laravel" || svc.Framework == "lumen") {
+		addReverbService(compose, svc, config)
+	}
+
+	// Write the variables of attached services to the config files of the framework
+	planFrameworkConfig(compose, svc, config, existingEnv)
+
+	// Rename or drop the variables of attached services for the app's conventions
+	applyEnvStyle(compose, svc, existingEnv)
+}
+
+// finalizeVolumes creates volume definitions for all tracked volumes
+func finalizeVolumes(compose *DockerCompose, volumesNeeded map[string]bool) {
+	// Create volume definitions for explicitly tracked volumes
+	for volName := range volumesNeeded {
+		compose.Volumes[volName] = DockerVolume{
+			Driver: "local",
+		}
+	}
+	
+	// Add database volumes
+	for _, service := range compose.Services {
+		for _, volume := range service.Volumes {
+			// Check if it's a named volume (contains : but doesn't start with . or /)
+			if strings.Contains(volume, ":") {
+				parts := strings.Split(volume, ":")
+				volName := parts[0]
+				if !strings.HasPrefix(volName, ".") && !strings.HasPrefix(volName, "/") && strings.HasSuffix(volName, "-data") {
+					compose.Volumes[volName] = DockerVolume{
+						Driver: "local",
+					}
+				}
+			}
+		}
+	}
+
+	// If no volumes needed, remove the volumes section
+	if len(compose.Volumes) == 0 {
+		compose.Volumes = nil
+	}
+}
+
+// newDockerCompose returns an empty compose file with the network every Fleet service joins
+func newDockerCompose() *DockerCompose {
+	return &DockerCompose{
+		Version:  "3.8",
+		Services: make(map[string]DockerService),
+		Networks: map[string]DockerNetwork{
+			"fleet-network": {
+				Driver: "bridge",
+				IPAM: &DockerNetworkIPAM{
+					Config: []DockerNetworkIPAMConfig{
+						{Subnet: "172.28.0.0/16"},
+					},
+				},
+			},
+		},
+		Volumes: make(map[string]DockerVolume),
+	}
+}
+
+// Generate returns the compose config of a Fleet config and the plan of the files its
+// services mount, like nginx configs and scripts. The folders of services are read in
+// projectDir, and the paths of planned files are relative to it. Nothing is written, the
+// fleet command writes the plan next to the compose files.
+func Generate(config *Config, projectDir string) (*DockerCompose, *ArtifactPlan) {
+	if abs, err := filepath.Abs(projectDir); err == nil {
+		projectDir = abs
+	}
+	generated := *config
+	generated.projectDir = projectDir
+	generated.Services = make([]Service, len(config.Services))
+	for i, svc := range config.Services {
+		svc.projectDir = projectDir
+		generated.Services[i] = svc
+	}
+
+	compose := generateDockerCompose(&generated)
+	return compose, getArtifactPlan(compose)
+}
+
+// getProjectDir returns the absolute directory of a project: the one given to Generate,
+// or the working directory of the fleet command
+func getProjectDir(config *Config) (string, error) {
+	if config.projectDir != "" {
+		return config.projectDir, nil
+	}
+	return os.Getwd()
+}
+
+// hostPath returns where a path relative to the project is read from
+func (config *Config) hostPath(path string) string {
+	return joinProjectDir(config.projectDir, path)
+}
+
+// hostPath returns where a path relative to the project of a service is read from
+func (svc *Service) hostPath(path string) string {
+	return joinProjectDir(svc.projectDir, path)
+}
+
+// joinProjectDir joins a relative path to a project directory. Without one, paths stay
+// relative to the working directory.
+func joinProjectDir(projectDir, path string) string {
+	if projectDir == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(projectDir, path)
+}
+
+func generateDockerCompose(config *Config) *DockerCompose {
+	compose := newDockerCompose()
+	plan := getArtifactPlan(compose)
+
+	// Ensure the directory of the generated configs exists, in .fleet or its artifact set
+	plan.dir = getArtifactDir(config)
+	plan.addDir(plan.dir, 0755)
+	
+	// Create profiles directory if any service has profiling enabled
+	for _, svc := range config.Services {
+		if svc.Profile {
+			profileDir := svc.ProfileOutput
+			if profileDir == "" {
+				profileDir = ".fleet/profiles"
+			}
+			plan.addDir(profileDir, 0755)
+			break
+		}
+	}
+
+	// Track which volumes need to be created
+	volumesNeeded := make(map[string]bool)
+
+	for _, svc := range config.Services {
+		// Build basic service configuration
+		service := buildServiceConfig(&svc)
+		
+		// Configure individual aspects of the service
+		configurePorts(&service, &svc)
+		configureVolumes(&service, &svc, volumesNeeded, plan)
+		configureEnvironment(&service, &svc, config)
+		configureHealthCheck(&service, &svc)
+		addServiceDependencies(&service, &svc)
+		
+		// Add the configured service to compose
+		compose.Services[svc.Name] = service
+		
+		// Add any supporting services
+		addSupportServices(compose, &svc, config)
+
+		// Share the host SSH agent and git credentials for private dependencies
+		configureCredentialForwarding(compose, &svc)
+
+		// Apply the hostname and extra hosts the app expects
+		configureHostnames(compose, &svc)
+
+		// Sandbox the service with another OCI runtime, like gVisor
+		configureRuntimeClass(compose, &svc)
+
+		// Tune PHP and the FPM pool, and let nginx accept the uploads PHP does
+		configurePHPFPM(compose, &svc)
+
+		// Run several instances behind the proxy
+		configureReplicas(compose, &svc)
+
+		// Sync or rebuild the service when its files change, with fleet dev
+		configureWatch(compose, &svc)
+
+		// Run init containers to completion before the service starts
+		addInitContainers(compose, &svc)
+
+		// Run the Horizon workers of Laravel apps next to the app, once its init containers completed
+		addHorizonService(compose, &svc)
+
+		// Run the workers of the service, with its image, code and environment
+		addWorkers(compose, &svc)
+
+		// Label the container so other projects can reference it
+		labelProjectService(compose, &svc, config.Project)
+	}
+
+	// Run the requested tag of attached services with unknown versions, when allowed
+	applyUnknownAddonVersions(compose, config)
+
+	// Cloud IDEs reach services through forwarded ports instead of the proxy
+	if config.Cloud {
+		applyCloudPorts(compose, config)
+	}
+
+	// Point services at the mock servers they need
+	addMockEnvVars(compose, config)
+
+	// Resolve services running in other Fleet projects
+	addExternalServices(compose, config)
+
+	// Run native services on the host instead of in containers
+	addNativeServices(compose, config)
+
+	// Hold commands back until their dependencies accept connections
+	applyWaitFor(compose, config)
+
+	// Run scheduled maintenance tasks
+	addMaintenanceScheduler(compose, config)
+
+	// Keep dependency folders out of the bind mounts of service folders
+	applyMountExcludes(compose, config)
+
+	// Finalize volume definitions
+	finalizeVolumes(compose, volumesNeeded)
+
+	// Scope named volumes to the project so projects never share data
+	projectDir, _ := getProjectDir(config)
+	scopeVolumesToProject(compose, config.Project, projectDir)
+
+	// Record the requests of debug_proxy services between nginx and the service
+	addDebugProxy(compose, config)
+
+	// Add nginx or Traefik if needed
+	addProxyToCompose(compose, config)
+	
+	// Plan PostgreSQL initialization scripts if needed
+	planPostgresInitScripts(compose)
+
+	// Run the image digests recorded by fleet lock
+	applyImageLock(compose, config)
+
+	// Keep credentials out of the compose file
+	if config.Secrets == secretsEnvFile {
+		moveSecretsToEnvFiles(compose)
+	}
+
+	return compose
+}
+
+// planPostgresInitScripts moves the init scripts of PostgreSQL services from their labels
+// to the generated files, and mounts them
+func planPostgresInitScripts(compose *DockerCompose) {
+	plan := getArtifactPlan(compose)
+	// Check all services for PostgreSQL init scripts in labels
+	for name, service := range compose.Services {
+		if service.Labels != nil {
+			if script, ok := service.Labels["fleet.postgres.init.script"]; ok {
+				if file, ok := service.Labels["fleet.postgres.init.path"]; ok {
+					// Write the init script with the generated files
+					plan.addFile(plan.path(file), []byte(script), 0644)
+					service.Volumes = append(service.Volumes, formatBindMount(plan.mountPath(file), "/docker-entrypoint-initdb.d/init.sql:ro"))
+					// Remove the labels after planning (they're not needed in docker-compose.yml)
+					delete(service.Labels, "fleet.postgres.init.script")
+					delete(service.Labels, "fleet.postgres.init.path")
+					// If labels map is empty, set it to nil
+					if len(service.Labels) == 0 {
+						service.Labels = nil
+					}
+					compose.Services[name] = service
+				}
+			}
+		}
+	}
+}
+
+// marshalDockerCompose returns the content of a compose file, with its header
+func marshalDockerCompose(compose *DockerCompose) ([]byte, error) {
+	data, err := yaml.Marshal(compose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal docker-compose: %w", err)
+	}
+
+	// Add header comment
+	return append([]byte(composeFileHeader), data...), nil
+}
+
+func writeDockerCompose(compose *DockerCompose, filename string) error {
+	data, err := marshalDockerCompose(compose)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write docker-compose.yml: %w", err)
+	}
+
+	return nil
+}
+
+// getServiceEnvPrefix returns the environment variable prefix for a service (mock-api -> MOCK_API)
+func getServiceEnvPrefix(serviceName string) string {
+	return strings.ToUpper(strings.ReplaceAll(serviceName, "-", "_"))
+}
+
+// mergeServiceEnvironment adds variables to a compose service without overriding
+// explicitly configured ones. The map is copied so the service's config isn't modified.
+func mergeServiceEnvironment(compose *DockerCompose, name string, vars map[string]string) {
+	service, exists := compose.Services[name]
+	if !exists || len(vars) == 0 {
+		return
+	}
+
+	env := make(map[string]string, len(service.Environment)+len(vars))
+	for k, v := range service.Environment {
+		env[k] = v
+	}
+	for k, v := range vars {
+		if _, set := env[k]; !set {
+			env[k] = v
+		}
+	}
+	service.Environment = env
+	compose.Services[name] = service
+}
+
+// readDockerCompose loads a previously generated docker-compose file
+func readDockerCompose(filename string) (*DockerCompose, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	var compose DockerCompose
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	return &compose, nil
+}
\ No newline at end of file
@@ -0,0 +1,134 @@
+package fleetcli
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// errComposeV2Required is returned for compose arguments docker-compose v1 doesn't understand
+var errComposeV2Required = errors.New("needs Docker Compose v2")
+
+// composeV2OnlyFlags are the compose flags Fleet uses that docker-compose v1 lacks
+var composeV2OnlyFlags = []string{"--format", "--wait", "--dry-run", "--watch"}
+
+// composeGlobalFlagsWithValue are the flags before the compose subcommand that take a value
+var composeGlobalFlagsWithValue = []string{"-f", "--file", "-p", "--project-name", "--profile", "--env-file", "--project-directory"}
+
+// ComposeCLI is the Docker Compose implementation Fleet runs compose commands with
+type ComposeCLI struct {
+	// Plugin is true for docker compose, false for the standalone docker-compose binary
+	Plugin  bool
+	Version string
+}
+
+// isLegacy reports whether the implementation is the Python based docker-compose v1
+func (c *ComposeCLI) isLegacy() bool {
+	return compareVersions(c.Version, "2.0.0") < 0
+}
+
+func (c *ComposeCLI) String() string {
+	if c.Plugin {
+		return fmt.Sprintf("docker compose %s (plugin)", c.Version)
+	}
+	return fmt.Sprintf("docker-compose %s (standalone)", c.Version)
+}
+
+// queryComposeVersion runs version --short with a compose command and returns the version
+func queryComposeVersion(name string, args ...string) (string, error) {
+	output, err := newCommand(name, append(args, "version", "--short")...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// detectComposeCLI finds the compose implementation to use. The plugin is preferred,
+// the standalone docker-compose binary (v1 or v2) is the fallback.
+func detectComposeCLI(query func(name string, args ...string) (string, error)) (*ComposeCLI, error) {
+	if version, err := query("docker", "compose"); err == nil {
+		return &ComposeCLI{Plugin: true, Version: version}, nil
+	}
+	if version, err := query("docker-compose"); err == nil {
+		return &ComposeCLI{Version: version}, nil
+	}
+	return nil, fmt.Errorf("docker compose is not installed. Please install the compose plugin: https://docs.docker.com/compose/install/")
+}
+
+var (
+	composeCLIOnce sync.Once
+	composeCLI     *ComposeCLI
+	composeCLIErr  error
+)
+
+// getComposeCLI returns the compose implementation, detected once per run
+var getComposeCLI = func() (*ComposeCLI, error) {
+	composeCLIOnce.Do(func() {
+		composeCLI, composeCLIErr = detectComposeCLI(queryComposeVersion)
+	})
+	return composeCLI, composeCLIErr
+}
+
+// composeSubcommand returns the compose subcommand of the arguments, like up or ps
+func composeSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		if containsString(composeGlobalFlagsWithValue, args[i]) {
+			i++
+			continue
+		}
+		if !strings.HasPrefix(args[i], "-") {
+			return args[i]
+		}
+	}
+	return ""
+}
+
+// adaptComposeArgs rewrites compose arguments, without the leading compose, for an
+// implementation. The plugin and docker-compose v2 take the same arguments. v1 lists
+// stopped containers without --all and fails on flags it doesn't know.
+func adaptComposeArgs(cli *ComposeCLI, args []string) ([]string, error) {
+	subcommand := composeSubcommand(args)
+	// The command run in the container is passed through as is
+	if !cli.isLegacy() || subcommand == "exec" || subcommand == "run" {
+		return args, nil
+	}
+	if subcommand == "watch" {
+		return nil, fmt.Errorf("compose watch %w (found docker-compose %s)", errComposeV2Required, cli.Version)
+	}
+
+	adapted := make([]string, 0, len(args))
+	for _, arg := range args {
+		flagName, _, _ := strings.Cut(arg, "=")
+		if containsString(composeV2OnlyFlags, flagName) {
+			return nil, fmt.Errorf("compose %s %s %w (found docker-compose %s)", subcommand, flagName, errComposeV2Required, cli.Version)
+		}
+		if subcommand == "ps" && (arg == "--all" || arg == "-a") {
+			continue
+		}
+		adapted = append(adapted, arg)
+	}
+	return adapted, nil
+}
+
+// dockerCommand builds a docker command. Commands starting with compose run with the
+// detected compose implementation, adapted to what it supports.
+func dockerCommand(args ...string) (*exec.Cmd, error) {
+	if len(args) == 0 || args[0] != "compose" {
+		return newCommand("docker", args...), nil
+	}
+
+	cli, err := getComposeCLI()
+	if err != nil {
+		return nil, err
+	}
+	composeArgs, err := adaptComposeArgs(cli, args[1:])
+	if err != nil {
+		return nil, err
+	}
+	if cli.Plugin {
+		return newCommand("docker", append([]string{"compose"}, composeArgs...)...), nil
+	}
+	return newCommand("docker-compose", composeArgs...), nil
+}
@@ -0,0 +1,114 @@
+package fleetcli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ComposeCLITestSuite struct {
+	suite.Suite
+	helper             *TestHelper
+	originalComposeCLI func() (*ComposeCLI, error)
+}
+
+func (suite *ComposeCLITestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalComposeCLI = getComposeCLI
+}
+
+func (suite *ComposeCLITestSuite) TearDownTest() {
+	getComposeCLI = suite.originalComposeCLI
+	suite.helper.Cleanup()
+}
+
+// fakeComposeQuery answers version queries for the installed compose commands
+func fakeComposeQuery(installed map[string]string) func(name string, args ...string) (string, error) {
+	return func(name string, args ...string) (string, error) {
+		command := strings.Join(append([]string{name}, args...), " ")
+		if version, exists := installed[command]; exists {
+			return version, nil
+		}
+		return "", errors.New("executable file not found in $PATH")
+	}
+}
+
+func (suite *ComposeCLITestSuite) TestDetectComposeCLI() {
+	testCases := []struct {
+		name      string
+		installed map[string]string
+		expected  string
+	}{
+		{"plugin", map[string]string{"docker compose": "2.27.0"}, "docker compose 2.27.0 (plugin)"},
+		{"plugin is preferred", map[string]string{"docker compose": "2.27.0", "docker-compose": "1.29.2"}, "docker compose 2.27.0 (plugin)"},
+		{"standalone v2", map[string]string{"docker-compose": "2.24.6"}, "docker-compose 2.24.6 (standalone)"},
+		{"standalone v1", map[string]string{"docker-compose": "1.29.2"}, "docker-compose 1.29.2 (standalone)"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			cli, err := detectComposeCLI(fakeComposeQuery(tc.installed))
+			suite.Require().NoError(err)
+			suite.Equal(tc.expected, cli.String())
+		})
+	}
+
+	_, err := detectComposeCLI(fakeComposeQuery(nil))
+	suite.ErrorContains(err, "docker compose is not installed")
+}
+
+func (suite *ComposeCLITestSuite) TestAdaptComposeArgs() {
+	plugin := &ComposeCLI{Plugin: true, Version: "2.27.0"}
+	legacy := &ComposeCLI{Version: "1.29.2"}
+
+	testCases := []struct {
+		name     string
+		cli      *ComposeCLI
+		args     []string
+		expected []string
+		error    bool
+	}{
+		{"v2 is unchanged", plugin, []string{"-f", "dc.yml", "ps", "--all", "--format", "json"}, []string{"-f", "dc.yml", "ps", "--all", "--format", "json"}, false},
+		{"v1 ps lists stopped containers by default", legacy, []string{"-f", "dc.yml", "ps", "--all"}, []string{"-f", "dc.yml", "ps"}, false},
+		{"v1 up", legacy, []string{"-f", "dc.yml", "up", "-d"}, []string{"-f", "dc.yml", "up", "-d"}, false},
+		{"v1 without json output", legacy, []string{"-f", "dc.yml", "ps", "--format", "json"}, nil, true},
+		{"v1 without --wait", legacy, []string{"-f", "dc.yml", "up", "--wait"}, nil, true},
+		{"v1 without watch", legacy, []string{"-f", "dc.yml", "watch"}, nil, true},
+		{"exec commands pass through", legacy, []string{"-f", "dc.yml", "exec", "-T", "web", "ls", "--all", "--format=long"}, []string{"-f", "dc.yml", "exec", "-T", "web", "ls", "--all", "--format=long"}, false},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			args, err := adaptComposeArgs(tc.cli, tc.args)
+			if tc.error {
+				suite.ErrorIs(err, errComposeV2Required)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Equal(tc.expected, args)
+		})
+	}
+}
+
+func (suite *ComposeCLITestSuite) TestDockerCommand() {
+	getComposeCLI = func() (*ComposeCLI, error) { return &ComposeCLI{Version: "1.29.2"}, nil }
+
+	cmd, err := dockerCommand("compose", "-f", "dc.yml", "ps", "--all")
+	suite.Require().NoError(err)
+	suite.Equal([]string{"docker-compose", "-f", "dc.yml", "ps"}, cmd.Args)
+
+	cmd, err = dockerCommand("ps", "-q")
+	suite.Require().NoError(err)
+	suite.Equal([]string{"docker", "ps", "-q"}, cmd.Args, "Other docker commands don't use compose")
+
+	getComposeCLI = func() (*ComposeCLI, error) { return &ComposeCLI{Plugin: true, Version: "2.27.0"}, nil }
+	cmd, err = dockerCommand("compose", "-f", "dc.yml", "down")
+	suite.Require().NoError(err)
+	suite.Equal([]string{"docker", "compose", "-f", "dc.yml", "down"}, cmd.Args)
+}
+
+func TestComposeCLISuite(t *testing.T) {
+	suite.Run(t, new(ComposeCLITestSuite))
+}
@@ -0,0 +1,283 @@
+package fleetcli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultComposeOutputDir is where the compose configuration is written unless
+// compose_output_dir says otherwise. Paths in the generated files are relative to it.
+const defaultComposeOutputDir = ".fleet"
+
+const (
+	// composeFileName is the single generated compose file
+	composeFileName = "docker-compose.yml"
+	// composeOverrideFileName is a compose file Fleet never writes, passed last so it can
+	// override anything it generates
+	composeOverrideFileName = "docker-compose.override.yml"
+	// composeFileHeader starts every generated compose file
+	composeFileHeader = "# Generated by Fleet CLI - DO NOT EDIT\n# Edit fleet.toml instead and regenerate\n\n"
+)
+
+// Layers of a split compose configuration, see compose_layers
+const (
+	// composeLayerBase holds the services of fleet.toml, the network and the volumes
+	composeLayerBase = "base"
+	// composeLayerAddons holds what Fleet adds for them: runtimes, databases, caches,
+	// sidecars, init containers, mocks and the maintenance scheduler
+	composeLayerAddons = "addons"
+	// composeLayerProxy holds the nginx proxy routing the domains
+	composeLayerProxy = "proxy"
+)
+
+// composeLayers are the generated layers in the order compose merges them
+var composeLayers = []string{composeLayerBase, composeLayerAddons, composeLayerProxy}
+
+// ComposeFiles are the compose files of a project. docker compose merges them in order,
+// so later files override earlier ones.
+type ComposeFiles struct {
+	// ProjectDir is the directory relative paths in the files are resolved from
+	ProjectDir string
+	Files      []string
+}
+
+// args returns the compose options selecting the files
+func (files ComposeFiles) args() []string {
+	var args []string
+	for _, file := range files.Files {
+		args = append(args, "-f", file)
+	}
+	// Compose resolves paths from the directory of the first file, and generated paths
+	// are relative to .fleet wherever the files are written
+	if len(files.Files) > 0 && filepath.Clean(filepath.Dir(files.Files[0])) != filepath.Clean(files.ProjectDir) {
+		args = append(args, "--project-directory", files.ProjectDir)
+	}
+	return args
+}
+
+// String lists the files, for messages
+func (files ComposeFiles) String() string {
+	return strings.Join(files.Files, ", ")
+}
+
+// composeArgs returns a docker compose command line for the files
+func composeArgs(files ComposeFiles, args ...string) []string {
+	return append(append([]string{"compose"}, files.args()...), args...)
+}
+
+// getComposeOutputDir returns the directory the compose files of a project are written
+// to. Configs other than the default one get their own directory in .fleet, so running
+// fleet up with another config doesn't overwrite the files of the default one.
+func getComposeOutputDir(config *Config) string {
+	if config.ComposeOutputDir != "" {
+		return filepath.Clean(config.ComposeOutputDir)
+	}
+	if config.ArtifactSet != "" {
+		return filepath.Join(defaultComposeOutputDir, config.ArtifactSet)
+	}
+	return defaultComposeOutputDir
+}
+
+// getComposeLayerFileName returns the file name of a compose layer
+func getComposeLayerFileName(layer string) string {
+	return fmt.Sprintf("docker-compose.%s.yml", layer)
+}
+
+// validateComposeOutput checks the compose output settings of a config
+func validateComposeOutput(config *Config) error {
+	if config.ComposeOutputDir == "" {
+		return nil
+	}
+	if strings.TrimSpace(config.ComposeOutputDir) == "" {
+		return fmt.Errorf("compose_output_dir cannot be blank")
+	}
+	if filepath.IsAbs(config.ComposeOutputDir) {
+		return fmt.Errorf("compose_output_dir %q must be relative to the project", config.ComposeOutputDir)
+	}
+	return nil
+}
+
+// getComposeFiles returns the compose files of the project in the current directory
+func getComposeFiles(config *Config) ComposeFiles {
+	return findComposeFiles("", config)
+}
+
+// findComposeFiles returns the compose files of the project in root. Layers without
+// services aren't written, so only the layers on disk are used, and the override file
+// is added when it exists.
+func findComposeFiles(root string, config *Config) ComposeFiles {
+	dir := filepath.Join(root, getComposeOutputDir(config))
+	files := ComposeFiles{ProjectDir: filepath.Join(root, defaultComposeOutputDir)}
+
+	if config.ComposeLayers {
+		for _, layer := range composeLayers {
+			path := filepath.Join(dir, getComposeLayerFileName(layer))
+			// The base layer is always used, so compose reports it missing before fleet up
+			if _, err := os.Stat(path); err == nil || layer == composeLayerBase {
+				files.Files = append(files.Files, path)
+			}
+		}
+	} else {
+		files.Files = append(files.Files, filepath.Join(dir, composeFileName))
+	}
+
+	override := filepath.Join(dir, composeOverrideFileName)
+	if _, err := os.Stat(override); err == nil {
+		files.Files = append(files.Files, override)
+	}
+	return files
+}
+
+// getComposeLayer returns the layer a compose service is written to
+func getComposeLayer(config *Config, name string) string {
+	if name == "nginx-proxy" || name == traefikServiceName {
+		return composeLayerProxy
+	}
+	for _, svc := range config.Services {
+		if svc.Name == name {
+			return composeLayerBase
+		}
+	}
+	return composeLayerAddons
+}
+
+// splitComposeLayers splits a compose file into its layers. The network and volumes are
+// declared in the base layer, layers without services are left out.
+func splitComposeLayers(config *Config, compose *DockerCompose) map[string]*DockerCompose {
+	layers := map[string]*DockerCompose{
+		composeLayerBase: {
+			Version:  compose.Version,
+			Services: make(map[string]DockerService),
+			Networks: compose.Networks,
+			Volumes:  compose.Volumes,
+		},
+	}
+	for name, service := range compose.Services {
+		layer := getComposeLayer(config, name)
+		if layers[layer] == nil {
+			layers[layer] = &DockerCompose{Version: compose.Version, Services: make(map[string]DockerService)}
+		}
+		layers[layer].Services[name] = service
+	}
+	return layers
+}
+
+// isGeneratedComposeFile reports whether a file can be overwritten: it doesn't exist
+// or was written by Fleet
+func isGeneratedComposeFile(path string) bool {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true
+	}
+	return err == nil && bytes.HasPrefix(data, []byte(composeFileHeader))
+}
+
+// getComposeFileContents returns the compose configs of a project by file name, one file
+// or a file per layer
+func getComposeFileContents(config *Config, compose *DockerCompose) map[string]*DockerCompose {
+	if !config.ComposeLayers {
+		return map[string]*DockerCompose{composeFileName: compose}
+	}
+	files := make(map[string]*DockerCompose)
+	for layer, content := range splitComposeLayers(config, compose) {
+		files[getComposeLayerFileName(layer)] = content
+	}
+	return files
+}
+
+// getComposeChanges returns what writing the compose files and the files services mount
+// would change on disk, for fleet up --dry-run
+func getComposeChanges(config *Config, compose *DockerCompose) ([]ArtifactChange, error) {
+	var changes []ArtifactChange
+	if compose.Artifacts != nil {
+		changes = compose.Artifacts.getChanges()
+	}
+	files := getComposeFileContents(config, compose)
+	for _, name := range sortedKeys(files) {
+		data, err := marshalDockerCompose(files[name])
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(getComposeOutputDir(config), name)
+		changes = append(changes, ArtifactChange{Path: path, Change: getFileChange(path, data)})
+	}
+	return changes, nil
+}
+
+// writeComposeFiles writes the compose configuration of a project, as one file or split
+// into layers, and returns the files to pass to compose
+func writeComposeFiles(config *Config, compose *DockerCompose) (ComposeFiles, error) {
+	dir := getComposeOutputDir(config)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return ComposeFiles{}, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	// The files services mount are written first, compose reads them once it starts
+	if err := writeArtifacts(compose.Artifacts); err != nil {
+		return ComposeFiles{}, err
+	}
+
+	written := getComposeFileContents(config, compose)
+
+	// Files of the other mode, or of layers that are empty now, would be picked up again
+	names := []string{composeFileName}
+	for _, layer := range composeLayers {
+		names = append(names, getComposeLayerFileName(layer))
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if _, exists := written[name]; exists {
+			if !isGeneratedComposeFile(path) {
+				return ComposeFiles{}, fmt.Errorf("%s was not generated by Fleet, refusing to overwrite it", path)
+			}
+			continue
+		}
+		if _, err := os.Stat(path); err == nil && isGeneratedComposeFile(path) {
+			os.Remove(path)
+		}
+	}
+
+	for name, content := range written {
+		if err := writeDockerCompose(content, filepath.Join(dir, name)); err != nil {
+			return ComposeFiles{}, err
+		}
+	}
+	return getComposeFiles(config), nil
+}
+
+// readComposeFiles reads the services Fleet generated into compose files. The override
+// file is skipped: it usually changes services partially, and Fleet only needs to know
+// which services exist and how they depend on each other.
+func readComposeFiles(files ComposeFiles) (*DockerCompose, error) {
+	merged := &DockerCompose{
+		Services: make(map[string]DockerService),
+		Networks: make(map[string]DockerNetwork),
+		Volumes:  make(map[string]DockerVolume),
+	}
+	for _, file := range files.Files {
+		if filepath.Base(file) == composeOverrideFileName {
+			continue
+		}
+		compose, err := readDockerCompose(file)
+		if err != nil {
+			return nil, err
+		}
+		if merged.Version == "" {
+			merged.Version = compose.Version
+		}
+		for name, service := range compose.Services {
+			merged.Services[name] = service
+		}
+		for name, network := range compose.Networks {
+			merged.Networks[name] = network
+		}
+		for name, volume := range compose.Volumes {
+			merged.Volumes[name] = volume
+		}
+	}
+	return merged, nil
+}
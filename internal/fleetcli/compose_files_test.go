@@ -0,0 +1,117 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ComposeFilesTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *ComposeFilesTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *ComposeFilesTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+// layeredCompose returns an app with a sidecar, a shared database and the proxy
+func (suite *ComposeFilesTestSuite) layeredCompose() (*Config, *DockerCompose) {
+	config := &Config{Project: "shop", ComposeLayers: true, Services: []Service{{Name: "web"}}}
+	compose := newDockerCompose()
+	compose.Services["web"] = DockerService{Image: "nginx:alpine"}
+	compose.Services["web-php"] = DockerService{Image: "php:8.3-fpm"}
+	compose.Services["mysql-80"] = DockerService{Image: "mysql:8.0"}
+	compose.Services["nginx-proxy"] = DockerService{Image: "nginx:alpine"}
+	compose.Volumes["mysql-80-data"] = DockerVolume{Driver: "local"}
+	return config, compose
+}
+
+func (suite *ComposeFilesTestSuite) TestWriteSingleFile() {
+	config := &Config{Project: "shop", Services: []Service{{Name: "web"}}}
+	compose := newDockerCompose()
+	compose.Services["web"] = DockerService{Image: "nginx:alpine"}
+
+	files, err := writeComposeFiles(config, compose)
+	suite.Require().NoError(err)
+	suite.Equal([]string{"compose", "-f", filepath.Join(".fleet", "docker-compose.yml"), "up"}, composeArgs(files, "up"))
+}
+
+func (suite *ComposeFilesTestSuite) TestWriteLayers() {
+	config, compose := suite.layeredCompose()
+
+	files, err := writeComposeFiles(config, compose)
+	suite.Require().NoError(err)
+	suite.Equal([]string{
+		filepath.Join(".fleet", "docker-compose.base.yml"),
+		filepath.Join(".fleet", "docker-compose.addons.yml"),
+		filepath.Join(".fleet", "docker-compose.proxy.yml"),
+	}, files.Files)
+
+	base, err := readDockerCompose(files.Files[0])
+	suite.Require().NoError(err)
+	suite.Len(base.Services, 1)
+	suite.Contains(base.Networks, "fleet-network", "The network and volumes are declared once, in the base layer")
+	suite.Contains(base.Volumes, "mysql-80-data")
+
+	merged, err := readComposeFiles(files)
+	suite.Require().NoError(err)
+	suite.Len(merged.Services, 4)
+
+	// Without domains the proxy layer goes away
+	delete(compose.Services, "nginx-proxy")
+	files, err = writeComposeFiles(config, compose)
+	suite.Require().NoError(err)
+	suite.Len(files.Files, 2)
+	suite.NoFileExists(filepath.Join(".fleet", "docker-compose.proxy.yml"))
+}
+
+func (suite *ComposeFilesTestSuite) TestOutputDirAndOverride() {
+	config, compose := suite.layeredCompose()
+	config.ComposeOutputDir = "compose"
+	suite.Require().NoError(os.MkdirAll("compose", 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join("compose", "docker-compose.override.yml"), []byte("services:\n  web:\n    ports: [\"8080:80\"]\n"), 0644))
+
+	files, err := writeComposeFiles(config, compose)
+	suite.Require().NoError(err)
+
+	args := files.args()
+	suite.Equal(filepath.Join("compose", "docker-compose.override.yml"), files.Files[len(files.Files)-1], "The override file is merged last")
+	suite.Equal([]string{"--project-directory", ".fleet"}, args[len(args)-2:], "Generated paths stay relative to .fleet")
+
+	merged, err := readComposeFiles(files)
+	suite.Require().NoError(err)
+	suite.Empty(merged.Services["web"].Ports, "The override file isn't read back")
+}
+
+func (suite *ComposeFilesTestSuite) TestRefusesToOverwriteOtherFiles() {
+	config, compose := suite.layeredCompose()
+	config.ComposeLayers = false
+	config.ComposeOutputDir = "."
+	suite.Require().NoError(os.WriteFile("docker-compose.yml", []byte("services: {}\n"), 0644))
+
+	_, err := writeComposeFiles(config, compose)
+	suite.ErrorContains(err, "not generated by Fleet")
+}
+
+func (suite *ComposeFilesTestSuite) TestValidateComposeOutput() {
+	suite.NoError(validateComposeOutput(&Config{ComposeOutputDir: "build/compose"}))
+	suite.Error(validateComposeOutput(&Config{ComposeOutputDir: "  "}))
+	suite.Error(validateComposeOutput(&Config{ComposeOutputDir: "/tmp/compose"}))
+}
+
+func TestComposeFilesSuite(t *testing.T) {
+	suite.Run(t, new(ComposeFilesTestSuite))
+}
@@ -0,0 +1,218 @@
+package fleetcli
+
+// Config is a fleet.toml: a project and the services it runs
+type Config struct {
+	Project              string                     `toml:"project" yaml:"project" json:"project"`
+	Secrets              string                     `toml:"secrets,omitempty" yaml:"secrets,omitempty" json:"secrets,omitempty"`
+	ComposeOutputDir     string                     `toml:"compose_output_dir,omitempty" yaml:"compose_output_dir,omitempty" json:"compose_output_dir,omitempty"`
+	ComposeLayers        bool                       `toml:"compose_layers,omitempty" yaml:"compose_layers,omitempty" json:"compose_layers,omitempty"`
+	QueueDashboardAuth   string                     `toml:"queue_dashboard_auth,omitempty" yaml:"queue_dashboard_auth,omitempty" json:"queue_dashboard_auth,omitempty"`
+	Proxy                string                     `toml:"proxy,omitempty" yaml:"proxy,omitempty" json:"proxy,omitempty"`
+	HTTP3                bool                       `toml:"http3,omitempty" yaml:"http3,omitempty" json:"http3,omitempty"`
+	ModernTLSOnly        bool                       `toml:"modern_tls_only,omitempty" yaml:"modern_tls_only,omitempty" json:"modern_tls_only,omitempty"`
+	SSLTrusted           bool                       `toml:"ssl_trusted,omitempty" yaml:"ssl_trusted,omitempty" json:"ssl_trusted,omitempty"`
+	AllowUnknownVersions bool                       `toml:"allow_unknown_versions,omitempty" yaml:"allow_unknown_versions,omitempty" json:"allow_unknown_versions,omitempty"`
+	Services             []Service                  `toml:"services" yaml:"services" json:"services"`
+	Maintenance          map[string]MaintenanceTask `toml:"maintenance,omitempty" yaml:"maintenance,omitempty" json:"maintenance,omitempty"`
+
+	// Unprivileged is set by --no-privileged, it is never read from the config file
+	Unprivileged bool `toml:"-" yaml:"-" json:"-"`
+	// Cloud is set by --cloud or in a cloud IDE, it is never read from the config file
+	Cloud bool `toml:"-" yaml:"-" json:"-"`
+	// ArtifactSet is the directory of .fleet the compose files are generated in when
+	// the config isn't the default one. The fleet command sets it from the file name.
+	ArtifactSet string `toml:"-" yaml:"-" json:"-"`
+
+	// projectDir is the directory the folders of services and generated files are
+	// relative to, set by Generate. The fleet command leaves it empty and uses the
+	// working directory.
+	projectDir string
+}
+
+// Service is a [[services]] entry. Setting Database, Cache, Search, Email, Queue and the
+// other addons attaches a shared service to it.
+type Service struct {
+	Name                  string            `toml:"name" yaml:"name" json:"name"`
+	Image                 string            `toml:"image" yaml:"image" json:"image"`
+	Build                 string            `toml:"build,omitempty" yaml:"build,omitempty" json:"build,omitempty"`
+	Port                  int               `toml:"port,omitempty" yaml:"port,omitempty" json:"port,omitempty"`
+	Ports                 []string          `toml:"ports,omitempty" yaml:"ports,omitempty" json:"ports,omitempty"`
+	Domain                string            `toml:"domain,omitempty" yaml:"domain,omitempty" json:"domain,omitempty"`
+	Domains               []string          `toml:"domains,omitempty" yaml:"domains,omitempty" json:"domains,omitempty"`
+	Tenants               []string          `toml:"tenants,omitempty" yaml:"tenants,omitempty" json:"tenants,omitempty"`
+	Path                  string            `toml:"path,omitempty" yaml:"path,omitempty" json:"path,omitempty"`
+	StripPath             bool              `toml:"strip_path,omitempty" yaml:"strip_path,omitempty" json:"strip_path,omitempty"`
+	WebSocket             bool              `toml:"websocket,omitempty" yaml:"websocket,omitempty" json:"websocket,omitempty"`
+	WebSocketPaths        []string          `toml:"websocket_paths,omitempty" yaml:"websocket_paths,omitempty" json:"websocket_paths,omitempty"`
+	Runtime               string            `toml:"runtime,omitempty" yaml:"runtime,omitempty" json:"runtime,omitempty"`
+	Framework             string            `toml:"framework,omitempty" yaml:"framework,omitempty" json:"framework,omitempty"`
+	Folder                string            `toml:"folder,omitempty" yaml:"folder,omitempty" json:"folder,omitempty"`
+	Password              string            `toml:"password,omitempty" yaml:"password,omitempty" json:"password,omitempty"`
+	Database              string            `toml:"database,omitempty" yaml:"database,omitempty" json:"database,omitempty"`
+	DatabaseName          string            `toml:"database_name,omitempty" yaml:"database_name,omitempty" json:"database_name,omitempty"`
+	DatabaseUser          string            `toml:"database_user,omitempty" yaml:"database_user,omitempty" json:"database_user,omitempty"`
+	DatabasePassword      string            `toml:"database_password,omitempty" yaml:"database_password,omitempty" json:"database_password,omitempty"`
+	DatabaseRootPassword  string            `toml:"database_root_password,omitempty" yaml:"database_root_password,omitempty" json:"database_root_password,omitempty"`
+	Cache                 string            `toml:"cache,omitempty" yaml:"cache,omitempty" json:"cache,omitempty"`
+	CachePassword         string            `toml:"cache_password,omitempty" yaml:"cache_password,omitempty" json:"cache_password,omitempty"`
+	CacheMaxMemory        string            `toml:"cache_max_memory,omitempty" yaml:"cache_max_memory,omitempty" json:"cache_max_memory,omitempty"`
+	Search                string            `toml:"search,omitempty" yaml:"search,omitempty" json:"search,omitempty"`
+	SearchApiKey          string            `toml:"search_api_key,omitempty" yaml:"search_api_key,omitempty" json:"search_api_key,omitempty"`
+	SearchMasterKey       string            `toml:"search_master_key,omitempty" yaml:"search_master_key,omitempty" json:"search_master_key,omitempty"`
+	Compat                string            `toml:"compat,omitempty" yaml:"compat,omitempty" json:"compat,omitempty"`
+	CompatAccessKey       string            `toml:"compat_access_key,omitempty" yaml:"compat_access_key,omitempty" json:"compat_access_key,omitempty"`
+	CompatSecretKey       string            `toml:"compat_secret_key,omitempty" yaml:"compat_secret_key,omitempty" json:"compat_secret_key,omitempty"`
+	CompatRegion          string            `toml:"compat_region,omitempty" yaml:"compat_region,omitempty" json:"compat_region,omitempty"`
+	Email                 string            `toml:"email,omitempty" yaml:"email,omitempty" json:"email,omitempty"`
+	EmailUsername         string            `toml:"email_username,omitempty" yaml:"email_username,omitempty" json:"email_username,omitempty"`
+	EmailPassword         string            `toml:"email_password,omitempty" yaml:"email_password,omitempty" json:"email_password,omitempty"`
+	Reverb                bool              `toml:"reverb,omitempty" yaml:"reverb,omitempty" json:"reverb,omitempty"`
+	ReverbHost            string            `toml:"reverb_host,omitempty" yaml:"reverb_host,omitempty" json:"reverb_host,omitempty"`
+	ReverbPort            int               `toml:"reverb_port,omitempty" yaml:"reverb_port,omitempty" json:"reverb_port,omitempty"`
+	ReverbAppId           string            `toml:"reverb_app_id,omitempty" yaml:"reverb_app_id,omitempty" json:"reverb_app_id,omitempty"`
+	ReverbAppKey          string            `toml:"reverb_app_key,omitempty" yaml:"reverb_app_key,omitempty" json:"reverb_app_key,omitempty"`
+	ReverbAppSecret       string            `toml:"reverb_app_secret,omitempty" yaml:"reverb_app_secret,omitempty" json:"reverb_app_secret,omitempty"`
+	Horizon               bool              `toml:"horizon,omitempty" yaml:"horizon,omitempty" json:"horizon,omitempty"`
+	SSL                   bool              `toml:"ssl,omitempty" yaml:"ssl,omitempty" json:"ssl,omitempty"`
+	SSLPort               int               `toml:"ssl_port,omitempty" yaml:"ssl_port,omitempty" json:"ssl_port,omitempty"`
+	Debug                 bool              `toml:"debug,omitempty" yaml:"debug,omitempty" json:"debug,omitempty"`
+	DebugPort             int               `toml:"debug_port,omitempty" yaml:"debug_port,omitempty" json:"debug_port,omitempty"`
+	Profile               bool              `toml:"profile,omitempty" yaml:"profile,omitempty" json:"profile,omitempty"`
+	ProfileTrigger        string            `toml:"profile_trigger,omitempty" yaml:"profile_trigger,omitempty" json:"profile_trigger,omitempty"`
+	ProfileOutput         string            `toml:"profile_output,omitempty" yaml:"profile_output,omitempty" json:"profile_output,omitempty"`
+	PHPImageStrategy      string            `toml:"php_image_strategy,omitempty" yaml:"php_image_strategy,omitempty" json:"php_image_strategy,omitempty"`
+	PHPImage              string            `toml:"php_image,omitempty" yaml:"php_image,omitempty" json:"php_image,omitempty"`
+	BuildCommand          string            `toml:"build_command,omitempty" yaml:"build_command,omitempty" json:"build_command,omitempty"`
+	PackageManager        string            `toml:"package_manager,omitempty" yaml:"package_manager,omitempty" json:"package_manager,omitempty"`
+	NodeEnv               string            `toml:"node_env,omitempty" yaml:"node_env,omitempty" json:"node_env,omitempty"`
+	DatabaseExtensions    []string          `toml:"database_extensions,omitempty" yaml:"database_extensions,omitempty" json:"database_extensions,omitempty"`
+	DatabaseSnapshotImage string            `toml:"database_snapshot_image,omitempty" yaml:"database_snapshot_image,omitempty" json:"database_snapshot_image,omitempty"`
+	DatabaseSeed          string            `toml:"database_seed,omitempty" yaml:"database_seed,omitempty" json:"database_seed,omitempty"`
+	SlowQueryLog          bool              `toml:"slow_query_log,omitempty" yaml:"slow_query_log,omitempty" json:"slow_query_log,omitempty"`
+	SlowQueryThreshold    string            `toml:"slow_query_threshold,omitempty" yaml:"slow_query_threshold,omitempty" json:"slow_query_threshold,omitempty"`
+	Environment           map[string]string `toml:"env,omitempty" yaml:"env,omitempty" json:"env,omitempty"`
+	EnvStyle              string            `toml:"env_style,omitempty" yaml:"env_style,omitempty" json:"env_style,omitempty"`
+	EnvMap                map[string]string `toml:"env_map,omitempty" yaml:"env_map,omitempty" json:"env_map,omitempty"`
+	FrameworkConfig       bool              `toml:"framework_config,omitempty" yaml:"framework_config,omitempty" json:"framework_config,omitempty"`
+	Volumes               []string          `toml:"volumes,omitempty" yaml:"volumes,omitempty" json:"volumes,omitempty"`
+	MountExcludes         []string          `toml:"mount_excludes,omitempty" yaml:"mount_excludes,omitempty" json:"mount_excludes,omitempty"`
+	Needs                 []string          `toml:"needs,omitempty" yaml:"needs,omitempty" json:"needs,omitempty"`
+	WaitFor               []string          `toml:"wait_for,omitempty" yaml:"wait_for,omitempty" json:"wait_for,omitempty"`
+	Init                  []InitContainer   `toml:"init,omitempty" yaml:"init,omitempty" json:"init,omitempty"`
+	Workers               []Worker          `toml:"workers,omitempty" yaml:"workers,omitempty" json:"workers,omitempty"`
+	Cron                  []CronJob         `toml:"cron,omitempty" yaml:"cron,omitempty" json:"cron,omitempty"`
+	ForwardSSHAgent       bool              `toml:"forward_ssh_agent,omitempty" yaml:"forward_ssh_agent,omitempty" json:"forward_ssh_agent,omitempty"`
+	GitCredentials        bool              `toml:"git_credentials,omitempty" yaml:"git_credentials,omitempty" json:"git_credentials,omitempty"`
+	Hostname              string            `toml:"hostname,omitempty" yaml:"hostname,omitempty" json:"hostname,omitempty"`
+	ExtraHosts            []string          `toml:"extra_hosts,omitempty" yaml:"extra_hosts,omitempty" json:"extra_hosts,omitempty"`
+	RuntimeClass          string            `toml:"runtime_class,omitempty" yaml:"runtime_class,omitempty" json:"runtime_class,omitempty"`
+	Command               string            `toml:"command,omitempty" yaml:"command,omitempty" json:"command,omitempty"`
+	ReloadSignal          string            `toml:"reload_signal,omitempty" yaml:"reload_signal,omitempty" json:"reload_signal,omitempty"`
+	Mock                  string            `toml:"mock,omitempty" yaml:"mock,omitempty" json:"mock,omitempty"`
+	ExternalService       string            `toml:"external_service,omitempty" yaml:"external_service,omitempty" json:"external_service,omitempty"`
+	Native                bool              `toml:"native,omitempty" yaml:"native,omitempty" json:"native,omitempty"`
+	Prewarm               bool              `toml:"prewarm,omitempty" yaml:"prewarm,omitempty" json:"prewarm,omitempty"`
+	AssetsRuntime         string            `toml:"assets_runtime,omitempty" yaml:"assets_runtime,omitempty" json:"assets_runtime,omitempty"`
+	AssetsCommand         string            `toml:"assets_command,omitempty" yaml:"assets_command,omitempty" json:"assets_command,omitempty"`
+	AssetsDev             bool              `toml:"assets_dev,omitempty" yaml:"assets_dev,omitempty" json:"assets_dev,omitempty"`
+	BackupSchedule        string            `toml:"backup_schedule,omitempty" yaml:"backup_schedule,omitempty" json:"backup_schedule,omitempty"`
+	BackupRetention       int               `toml:"backup_retention,omitempty" yaml:"backup_retention,omitempty" json:"backup_retention,omitempty"`
+	HealthCheck           HealthCheck       `toml:"health,omitempty" yaml:"health,omitempty" json:"health,omitempty"`
+	PHPFPM                PHPFPMSettings    `toml:"php_fpm,omitempty" yaml:"php_fpm,omitempty" json:"php_fpm,omitempty"`
+	NginxTemplate         string            `toml:"nginx_template,omitempty" yaml:"nginx_template,omitempty" json:"nginx_template,omitempty"`
+	FastCGIParams         map[string]string `toml:"fastcgi_params,omitempty" yaml:"fastcgi_params,omitempty" json:"fastcgi_params,omitempty"`
+	Checks                []HTTPCheck       `toml:"checks,omitempty" yaml:"checks,omitempty" json:"checks,omitempty"`
+	Replicas              int               `toml:"replicas,omitempty" yaml:"replicas,omitempty" json:"replicas,omitempty"`
+	AI                    string            `toml:"ai,omitempty" yaml:"ai,omitempty" json:"ai,omitempty"`
+	AIModels              []string          `toml:"ai_models,omitempty" yaml:"ai_models,omitempty" json:"ai_models,omitempty"`
+	AIGPU                 bool              `toml:"ai_gpu,omitempty" yaml:"ai_gpu,omitempty" json:"ai_gpu,omitempty"`
+	Alerts                AlertThresholds   `toml:"alerts,omitempty" yaml:"alerts,omitempty" json:"alerts,omitempty"`
+	DebugProxy            bool              `toml:"debug_proxy,omitempty" yaml:"debug_proxy,omitempty" json:"debug_proxy,omitempty"`
+	Queue                 string            `toml:"queue,omitempty" yaml:"queue,omitempty" json:"queue,omitempty"`
+	QueueUI               bool              `toml:"queue_ui,omitempty" yaml:"queue_ui,omitempty" json:"queue_ui,omitempty"`
+	Watch                 []WatchRule       `toml:"watch,omitempty" yaml:"watch,omitempty" json:"watch,omitempty"`
+
+	// projectDir is the project directory of the config of the service, see Config
+	projectDir string
+}
+
+// HealthCheck is the health check of a service, from [services.health]
+type HealthCheck struct {
+	Test     string `toml:"test,omitempty" yaml:"test,omitempty" json:"test,omitempty"`
+	Interval string `toml:"interval,omitempty" yaml:"interval,omitempty" json:"interval,omitempty"`
+	Timeout  string `toml:"timeout,omitempty" yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Retries  int    `toml:"retries,omitempty" yaml:"retries,omitempty" json:"retries,omitempty"`
+}
+
+// InitContainer is a one-off container that runs to completion before its service starts
+type InitContainer struct {
+	Name    string `toml:"name,omitempty" yaml:"name,omitempty" json:"name,omitempty"`
+	Image   string `toml:"image,omitempty" yaml:"image,omitempty" json:"image,omitempty"`
+	Command string `toml:"command,omitempty" yaml:"command,omitempty" json:"command,omitempty"`
+}
+
+// Worker is a long running process of a service, like a queue worker, run in containers
+// of its own from [[services.workers]]
+type Worker struct {
+	Name     string `toml:"name,omitempty" yaml:"name,omitempty" json:"name,omitempty"`
+	Command  string `toml:"command" yaml:"command" json:"command"`
+	Replicas int    `toml:"replicas,omitempty" yaml:"replicas,omitempty" json:"replicas,omitempty"`
+}
+
+// CronJob is a command run in the container of a service on a schedule, from [[services.cron]]
+type CronJob struct {
+	Name     string `toml:"name,omitempty" yaml:"name,omitempty" json:"name,omitempty"`
+	Schedule string `toml:"schedule" yaml:"schedule" json:"schedule"`
+	Command  string `toml:"command" yaml:"command" json:"command"`
+}
+
+// MaintenanceTask is a chore run on demand with fleet maintain run, or on a schedule
+type MaintenanceTask struct {
+	Service  string `toml:"service" yaml:"service" json:"service"`
+	Preset   string `toml:"preset,omitempty" yaml:"preset,omitempty" json:"preset,omitempty"`
+	Command  string `toml:"command,omitempty" yaml:"command,omitempty" json:"command,omitempty"`
+	Schedule string `toml:"schedule,omitempty" yaml:"schedule,omitempty" json:"schedule,omitempty"`
+}
+
+// HTTPCheck is a request fleet verify sends to a service, from [[services.checks]]
+type HTTPCheck struct {
+	// URL is a path on the service domain, like /health, or a full URL
+	URL     string `toml:"url" yaml:"url" json:"url"`
+	Status  int    `toml:"status,omitempty" yaml:"status,omitempty" json:"status,omitempty"`
+	Body    string `toml:"body,omitempty" yaml:"body,omitempty" json:"body,omitempty"`
+	Timeout string `toml:"timeout,omitempty" yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// PHPFPMSettings tunes PHP and the FPM pool of a PHP service, from [services.php_fpm]
+type PHPFPMSettings struct {
+	PM                        string `toml:"pm,omitempty" yaml:"pm,omitempty" json:"pm,omitempty"`
+	MaxChildren               int    `toml:"max_children,omitempty" yaml:"max_children,omitempty" json:"max_children,omitempty"`
+	MemoryLimit               string `toml:"memory_limit,omitempty" yaml:"memory_limit,omitempty" json:"memory_limit,omitempty"`
+	UploadMaxFilesize         string `toml:"upload_max_filesize,omitempty" yaml:"upload_max_filesize,omitempty" json:"upload_max_filesize,omitempty"`
+	PostMaxSize               string `toml:"post_max_size,omitempty" yaml:"post_max_size,omitempty" json:"post_max_size,omitempty"`
+	Opcache                   *bool  `toml:"opcache,omitempty" yaml:"opcache,omitempty" json:"opcache,omitempty"`
+	OpcacheMemory             int    `toml:"opcache_memory,omitempty" yaml:"opcache_memory,omitempty" json:"opcache_memory,omitempty"`
+	OpcacheMaxFiles           int    `toml:"opcache_max_files,omitempty" yaml:"opcache_max_files,omitempty" json:"opcache_max_files,omitempty"`
+	OpcacheValidateTimestamps *bool  `toml:"opcache_validate_timestamps,omitempty" yaml:"opcache_validate_timestamps,omitempty" json:"opcache_validate_timestamps,omitempty"`
+}
+
+// AlertThresholds are the resource limits fleet stats warns about for a service
+type AlertThresholds struct {
+	// Memory is a share of the container memory limit like "80%", or a size like "512MB"
+	Memory string `toml:"memory,omitempty" yaml:"memory,omitempty" json:"memory,omitempty"`
+	// CPU is a share of one core, "150%" is one and a half cores
+	CPU string `toml:"cpu,omitempty" yaml:"cpu,omitempty" json:"cpu,omitempty"`
+	// Restarts is the number of times Docker restarted a container
+	Restarts int `toml:"restarts,omitempty" yaml:"restarts,omitempty" json:"restarts,omitempty"`
+}
+
+// WatchRule is what docker compose watch does when files of a service change
+type WatchRule struct {
+	// Path is the host path to watch, relative to the project. It defaults to the folder
+	// of the service.
+	Path   string `toml:"path,omitempty" yaml:"path,omitempty" json:"path,omitempty"`
+	Action string `toml:"action" yaml:"action" json:"action"`
+	// Target is the path in the container files are synced to
+	Target string   `toml:"target,omitempty" yaml:"target,omitempty" json:"target,omitempty"`
+	Ignore []string `toml:"ignore,omitempty" yaml:"ignore,omitempty" json:"ignore,omitempty"`
+}
@@ -0,0 +1,194 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Kinds of config changes
+const (
+	configAdded   = "added"
+	configRemoved = "removed"
+	configChanged = "changed"
+)
+
+// ANSI colors of the config diff
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+// ConfigChange is a difference between two configs: a service added or removed, or a
+// field of the project or of a service that changed
+type ConfigChange struct {
+	Kind    string
+	Service string // Empty for fields of the project
+	Field   string // Empty for added and removed services
+	Old     string
+	New     string
+}
+
+// formatConfigValue formats a config value on one line, like "node:20" or ["db", "cache"]
+func formatConfigValue(value reflect.Value) string {
+	data, err := json.Marshal(value.Interface())
+	if err != nil {
+		return fmt.Sprint(value.Interface())
+	}
+	return strings.ReplaceAll(string(data), `","`, `", "`)
+}
+
+// getConfigFieldName returns the name of a field in the config file
+func getConfigFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// diffConfigFields returns the fields of two structs that differ, skipping those in skip
+func diffConfigFields(service string, old, new reflect.Value, skip ...string) []ConfigChange {
+	var changes []ConfigChange
+	for i := 0; i < old.NumField(); i++ {
+		field := old.Type().Field(i)
+		name := getConfigFieldName(field)
+		if !field.IsExported() || name == "-" || containsString(skip, name) {
+			continue
+		}
+
+		oldValue, newValue := old.Field(i), new.Field(i)
+		// An empty list or table is the same as none
+		if (oldValue.IsZero() || (oldValue.Kind() == reflect.Map || oldValue.Kind() == reflect.Slice) && oldValue.Len() == 0) &&
+			(newValue.IsZero() || (newValue.Kind() == reflect.Map || newValue.Kind() == reflect.Slice) && newValue.Len() == 0) {
+			continue
+		}
+
+		change := ConfigChange{Kind: configChanged, Service: service, Field: name}
+		switch {
+		case reflect.DeepEqual(oldValue.Interface(), newValue.Interface()):
+			continue
+		case oldValue.IsZero():
+			change.Kind = configAdded
+			change.New = formatConfigValue(newValue)
+		case newValue.IsZero():
+			change.Kind = configRemoved
+			change.Old = formatConfigValue(oldValue)
+		default:
+			change.Old = formatConfigValue(oldValue)
+			change.New = formatConfigValue(newValue)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// diffConfigs returns what changes from one config to another. Services are matched by
+// name and listed in the order of the new config, followed by the removed ones.
+func diffConfigs(old, new *Config) []ConfigChange {
+	changes := diffConfigFields("", reflect.ValueOf(*old), reflect.ValueOf(*new), "services")
+
+	for i := range new.Services {
+		svc := &new.Services[i]
+		existing := findService(old, svc.Name)
+		if existing == nil {
+			changes = append(changes, ConfigChange{Kind: configAdded, Service: svc.Name})
+			changes = append(changes, diffConfigFields(svc.Name, reflect.ValueOf(Service{}), reflect.ValueOf(*svc), "name")...)
+			continue
+		}
+		changes = append(changes, diffConfigFields(svc.Name, reflect.ValueOf(*existing), reflect.ValueOf(*svc), "name")...)
+	}
+	for i := range old.Services {
+		if findService(new, old.Services[i].Name) == nil {
+			changes = append(changes, ConfigChange{Kind: configRemoved, Service: old.Services[i].Name})
+		}
+	}
+	return changes
+}
+
+// useColor reports whether output can be colored: on a terminal, unless NO_COLOR is set
+func useColor() bool {
+	return os.Getenv("NO_COLOR") == "" && isOutputTerminal()
+}
+
+// printConfigDiff prints config changes grouped by service, + for additions, - for
+// removals and ~ for changes
+func printConfigDiff(out io.Writer, changes []ConfigChange, color bool) {
+	colors := map[string]string{configAdded: colorGreen, configRemoved: colorRed, configChanged: colorYellow}
+	signs := map[string]string{configAdded: "+", configRemoved: "-", configChanged: "~"}
+	line := func(kind, indent, text string) {
+		if color {
+			fmt.Fprintf(out, "%s%s%s %s%s\n", colors[kind], indent, signs[kind], text, colorReset)
+		} else {
+			fmt.Fprintf(out, "%s%s %s\n", indent, signs[kind], text)
+		}
+	}
+
+	service := ""
+	for _, change := range changes {
+		indent := ""
+		if change.Service != "" {
+			indent = "    "
+			if change.Field == "" {
+				line(change.Kind, "", "service "+change.Service)
+				service = change.Service
+				continue
+			}
+			if change.Service != service {
+				line(configChanged, "", "service "+change.Service)
+				service = change.Service
+			}
+		}
+
+		switch change.Kind {
+		case configAdded:
+			line(change.Kind, indent, fmt.Sprintf("%s = %s", change.Field, change.New))
+		case configRemoved:
+			line(change.Kind, indent, fmt.Sprintf("%s = %s", change.Field, change.Old))
+		default:
+			line(change.Kind, indent, fmt.Sprintf("%s: %s → %s", change.Field, change.Old, change.New))
+		}
+	}
+}
+
+// confirmConfigOverwrite shows what saving a config changes in an existing file and asks
+// before overwriting it
+func confirmConfigOverwrite(filename string, config *Config) bool {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("❌ Error reading %s: %v\n", filename, err)
+		return false
+	}
+
+	var existing Config
+	if err := toml.Unmarshal(data, &existing); err != nil {
+		fmt.Printf("\n⚠️  %s can't be parsed (%v), all of it will be replaced\n", filename, err)
+	} else {
+		changes := diffConfigs(&existing, config)
+		if len(changes) == 0 {
+			fmt.Printf("\n✅ %s already has this configuration, nothing to save\n", filename)
+			return false
+		}
+		fmt.Printf("\n📝 Changes to %s:\n\n", filename)
+		printConfigDiff(os.Stdout, changes, useColor())
+		fmt.Println("\nComments and formatting of the file are not kept.")
+	}
+
+	overwrite := false
+	if err := survey.AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("Overwrite %s?", filename),
+		Default: false,
+	}, &overwrite); err != nil || !overwrite {
+		fmt.Printf("❌ Configuration not saved, %s is unchanged\n", filename)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package fleetcli
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigDiffTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *ConfigDiffTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *ConfigDiffTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *ConfigDiffTestSuite) TestDiffConfigs() {
+	old := &Config{Project: "shop", Services: []Service{
+		{Name: "api", Image: "node:18", Port: 3000, Environment: map[string]string{}},
+		{Name: "worker", Image: "node:18"},
+	}}
+	new := &Config{Project: "shop", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Database: "postgres:16"},
+		{Name: "cache", Image: "redis:7", Ports: []string{"6379:6379"}},
+	}}
+
+	suite.Equal([]ConfigChange{
+		{Kind: configChanged, Service: "api", Field: "image", Old: `"node:18"`, New: `"node:20"`},
+		{Kind: configAdded, Service: "api", Field: "database", New: `"postgres:16"`},
+		{Kind: configAdded, Service: "cache"},
+		{Kind: configAdded, Service: "cache", Field: "image", New: `"redis:7"`},
+		{Kind: configAdded, Service: "cache", Field: "ports", New: `["6379:6379"]`},
+		{Kind: configRemoved, Service: "worker"},
+	}, diffConfigs(old, new))
+
+	suite.Empty(diffConfigs(new, new), "No changes")
+}
+
+func (suite *ConfigDiffTestSuite) TestPrintConfigDiff() {
+	changes := []ConfigChange{
+		{Kind: configChanged, Field: "project", Old: `"shop"`, New: `"store"`},
+		{Kind: configChanged, Service: "api", Field: "image", Old: `"node:18"`, New: `"node:20"`},
+		{Kind: configRemoved, Service: "api", Field: "port", Old: "3000"},
+		{Kind: configAdded, Service: "cache"},
+		{Kind: configAdded, Service: "cache", Field: "image", New: `"redis:7"`},
+	}
+
+	var out bytes.Buffer
+	printConfigDiff(&out, changes, false)
+	suite.Equal(`~ project: "shop" → "store"
+~ service api
+    ~ image: "node:18" → "node:20"
+    - port = 3000
++ service cache
+    + image = "redis:7"
+`, out.String())
+
+	out.Reset()
+	printConfigDiff(&out, changes[3:4], true)
+	suite.Equal(colorGreen+"+ service cache"+colorReset+"\n", out.String())
+}
+
+func TestConfigDiffSuite(t *testing.T) {
+	suite.Run(t, new(ConfigDiffTestSuite))
+}
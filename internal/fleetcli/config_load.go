@@ -0,0 +1,190 @@
+package fleetcli
+
+import "strings"
+
+func loadConfig(filename string) (*Config, error) {
+	// URLs and git references are fetched to a local copy first
+	if isRemoteConfig(filename) {
+		localPath, err := resolveRemoteConfig(filename)
+		if err != nil {
+			return nil, err
+		}
+		filename = localPath
+	}
+
+	return Load(filename)
+}
+
+// Validate checks a config: the services it needs and the settings of each feature,
+// against the runtime and service versions Fleet supports
+func Validate(config *Config) error {
+	if err := validateStructure(config); err != nil {
+		return err
+	}
+
+	for i, svc := range config.Services {
+		if err := validateMockService(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateExternalService(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateNativeService(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validatePrewarm(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateAssetsBuild(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validatePHPFPM(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateBackupSchedule(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateWaitFor(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateHostnames(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateRuntimeClass(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateMountExcludes(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateFolderPath(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateHTTPChecks(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateReplicas(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateAIService(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateQueueService(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateHorizon(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateWatch(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateEnvStyle(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateFrameworkConfig(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateAlerts(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateDebugProxy(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateDatabaseSnapshot(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateDatabaseSeed(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateSlowQueryLog(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validatePHPNginxTemplate(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateWildcardDomain(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if err := validateWebSocket(&config.Services[i]); err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(svc.Runtime, "php") {
+			if err := validatePHPImageStrategy(&config.Services[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := validateDatabaseSnapshots(config); err != nil {
+		return err
+	}
+
+	if err := validateInitContainers(config); err != nil {
+		return err
+	}
+
+	if err := validateWorkers(config); err != nil {
+		return err
+	}
+
+	if err := validateMaintenance(config); err != nil {
+		return err
+	}
+
+	if err := validateCronJobs(config); err != nil {
+		return err
+	}
+
+	if err := validateSecrets(config); err != nil {
+		return err
+	}
+
+	if err := validateQueueDashboardAuth(config); err != nil {
+		return err
+	}
+
+	if err := validateComposeOutput(config); err != nil {
+		return err
+	}
+
+	if err := validateProxy(config); err != nil {
+		return err
+	}
+
+	if err := validateDomains(config); err != nil {
+		return err
+	}
+
+	if err := validatePathRouting(config); err != nil {
+		return err
+	}
+
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,215 @@
+package fleetcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// configWatchInterval is how often fleet up --watch checks the watched files for changes
+var configWatchInterval = time.Second
+
+// configWatchRestartTimeout is how long a rolling restart waits for each instance
+const configWatchRestartTimeout = 2 * time.Minute
+
+// getConfigWatchFiles returns the files fleet up --watch reacts to, with the services
+// mounting each one: the config file, which mounts nothing, and the files services mount
+// from the project, like nginx configs
+func getConfigWatchFiles(configFile string, config *Config) map[string][]string {
+	files := map[string][]string{filepath.Clean(configFile): nil}
+	for _, svc := range config.Services {
+		for _, volume := range svc.Volumes {
+			source, _ := splitVolume(volume)
+			// Folders are mounted live, only replaced files need a restart
+			if !isHostPathSource(source) || strings.HasPrefix(source, "~") {
+				continue
+			}
+			if info, err := os.Stat(source); err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			path := filepath.Clean(source)
+			if !containsString(files[path], svc.Name) {
+				files[path] = append(files[path], svc.Name)
+			}
+		}
+	}
+	return files
+}
+
+// getFileModTimes returns the modification time of each file, zero for missing ones
+func getFileModTimes(paths []string) map[string]time.Time {
+	modTimes := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		} else {
+			modTimes[path] = time.Time{}
+		}
+	}
+	return modTimes
+}
+
+// getChangedFiles returns the files whose modification time changed, sorted
+func getChangedFiles(before, after map[string]time.Time) []string {
+	var changed []string
+	for path, modTime := range after {
+		if !modTime.Equal(before[path]) {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// reloadStack regenerates the compose files from the changed config and applies them.
+// Compose only recreates the services whose configuration changed, the others keep running.
+func reloadStack(configFile string, previous *Config) (*Config, error) {
+	config, err := loadConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	config.Unprivileged = previous.Unprivileged
+	config.Cloud = previous.Cloud
+	// --allow-unknown-version holds until fleet up --watch stops
+	config.AllowUnknownVersions = config.AllowUnknownVersions || previous.AllowUnknownVersions
+	if err := checkRuntimeClasses(config); err != nil {
+		return nil, err
+	}
+	warnUnknownAddonVersions(config)
+
+	release, err := acquireProjectLock("up", &ProjectLockOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Every project runs in the same compose project, so --remove-orphans would remove the
+	// containers of the other projects. Only the services that went away are removed,
+	// while the compose files still describe them.
+	compose := generateDockerCompose(config)
+	if removed := getRemovedServices(previous, generateDockerCompose(previous), compose); len(removed) > 0 {
+		infof("🗑️  Removing %s\n", strings.Join(removed, ", "))
+		args := append([]string{"rm", "--stop", "--force"}, removed...)
+		if err := runDocker(composeArgs(getComposeFiles(previous), args...)); err != nil {
+			warnf("⚠️  Warning: failed to remove %s: %v\n", strings.Join(removed, ", "), err)
+		}
+	}
+
+	composeFiles, err := writeComposeFiles(config, compose)
+	if err != nil {
+		return nil, err
+	}
+	forgetRunningConfig, err := recordRunningConfig(configFile, config)
+	if err != nil {
+		warnf("⚠️  Warning: %v\n", err)
+	}
+
+	// Only ask for privileges when there are new domains
+	domainsChanged := strings.Join(getProjectDomains(config), ",") != strings.Join(getProjectDomains(previous), ",")
+	if domainsChanged && !config.Cloud && !config.Unprivileged && shouldAddNginxProxy(config) {
+		infoln("📝 Updating hosts file with service domains...")
+		if err := updateHostsFileWithDomains(config); err != nil {
+			warnf("⚠️  Warning: failed to update hosts file: %v\n", err)
+		}
+	}
+
+	if err := runDocker(composeArgs(composeFiles, "up", "-d")); err != nil {
+		forgetRunningConfig()
+		return nil, fmt.Errorf("failed to apply the config: %w", err)
+	}
+	return config, nil
+}
+
+// getRemovedServices returns the compose services of the services of a config that the
+// new compose config doesn't have anymore, like those of a removed [[services]] entry or
+// its assets build. Shared services, like databases, may be used by other projects and
+// keep running.
+func getRemovedServices(previous *Config, before, after *DockerCompose) []string {
+	var removed []string
+	for _, name := range sortedKeys(before.Services) {
+		if _, exists := after.Services[name]; exists {
+			continue
+		}
+		for _, svc := range previous.Services {
+			if name == svc.Name || strings.HasPrefix(name, svc.Name+"-") {
+				removed = append(removed, name)
+				break
+			}
+		}
+	}
+	return removed
+}
+
+// restartMountingServices restarts the services mounting a changed file. Services with
+// replicas are restarted one instance at a time.
+func restartMountingServices(config *Config, services []string) error {
+	composeFiles := getComposeFiles(config)
+	for _, name := range services {
+		infof("🔄 Restarting %s\n", name)
+		if svc := findService(config, name); svc != nil && svc.Replicas > 1 {
+			if err := rollingRestart(composeFiles, name, configWatchRestartTimeout); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := runDocker(composeArgs(composeFiles, "restart", name)); err != nil {
+			return fmt.Errorf("failed to restart %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// watchConfig applies changes of the config and of the files services mount until Fleet
+// is interrupted. A config that doesn't load keeps the running stack as it is.
+func watchConfig(configFile string, config *Config) {
+	infof("👀 Watching %s for changes, press Ctrl-C to stop\n", configFile)
+
+	files := getConfigWatchFiles(configFile, config)
+	modTimes := getFileModTimes(sortedKeys(files))
+	for {
+		select {
+		case <-rootContext.Done():
+			return
+		case <-time.After(configWatchInterval):
+		}
+
+		current := getFileModTimes(sortedKeys(files))
+		changed := getChangedFiles(modTimes, current)
+		if len(changed) == 0 {
+			continue
+		}
+		modTimes = current
+		now := time.Now().Format("15:04:05")
+
+		if containsString(changed, filepath.Clean(configFile)) {
+			infof("%s %s changed, applying it\n", now, configFile)
+			reloaded, err := reloadStack(configFile, config)
+			if err != nil {
+				warnf("⚠️  %v\n", err)
+				warnln("   The running stack is unchanged, fix the config and save it again")
+				continue
+			}
+			config = reloaded
+			files = getConfigWatchFiles(configFile, config)
+			modTimes = getFileModTimes(sortedKeys(files))
+			infof("%s Stack of %s updated\n", time.Now().Format("15:04:05"), config.Project)
+			continue
+		}
+
+		var services []string
+		for _, path := range changed {
+			infof("%s %s changed\n", now, path)
+			for _, name := range files[path] {
+				if !containsString(services, name) {
+					services = append(services, name)
+				}
+			}
+		}
+		if err := restartMountingServices(config, services); err != nil {
+			warnf("⚠️  Warning: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigWatchTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *ConfigWatchTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *ConfigWatchTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *ConfigWatchTestSuite) TestGetConfigWatchFiles() {
+	suite.Require().NoError(os.MkdirAll("nginx", 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join("nginx", "site.conf"), []byte("server {}\n"), 0644))
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "web", Image: "nginx:alpine", Volumes: []string{"./nginx/site.conf:/etc/nginx/conf.d/default.conf:ro", "./nginx:/srv"}},
+		{Name: "proxy", Image: "nginx:alpine", Volumes: []string{"./nginx/site.conf:/etc/nginx/nginx.conf", "data:/data"}},
+		{Name: "api", Image: "node:20", Volumes: []string{"./missing.conf:/app/missing.conf"}},
+	}}
+
+	suite.Equal(map[string][]string{
+		"fleet.toml":                        nil,
+		filepath.Join("nginx", "site.conf"): {"web", "proxy"},
+	}, getConfigWatchFiles("./fleet.toml", config), "Folders, named volumes and missing files are skipped")
+}
+
+func (suite *ConfigWatchTestSuite) TestGetChangedFiles() {
+	suite.Require().NoError(os.WriteFile("fleet.toml", []byte("project = \"test\"\n"), 0644))
+	suite.Require().NoError(os.WriteFile("site.conf", []byte("server {}\n"), 0644))
+	paths := []string{"fleet.toml", "site.conf", "gone.conf"}
+
+	before := getFileModTimes(paths)
+	suite.True(before["gone.conf"].IsZero())
+	suite.Empty(getChangedFiles(before, getFileModTimes(paths)))
+
+	later := time.Now().Add(time.Minute)
+	suite.Require().NoError(os.Chtimes("site.conf", later, later))
+	suite.Require().NoError(os.WriteFile("gone.conf", []byte("\n"), 0644))
+	suite.Equal([]string{"gone.conf", "site.conf"}, getChangedFiles(before, getFileModTimes(paths)))
+}
+
+func (suite *ConfigWatchTestSuite) TestGetRemovedServices() {
+	previous := &Config{Services: []Service{{Name: "web"}, {Name: "api"}}}
+	before := &DockerCompose{Services: map[string]DockerService{
+		"web": {}, "web-assets": {}, "api": {}, "postgres": {}, "nginx-proxy": {},
+	}}
+	after := &DockerCompose{Services: map[string]DockerService{"api": {}}}
+	suite.Equal([]string{"web", "web-assets"}, getRemovedServices(previous, before, after),
+		"Shared services may be used by other projects")
+	suite.Empty(getRemovedServices(previous, after, after))
+}
+
+func TestConfigWatchSuite(t *testing.T) {
+	suite.Run(t, new(ConfigWatchTestSuite))
+}
@@ -0,0 +1,107 @@
+package fleetcli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// commandGracePeriod is how long a command gets to exit after Fleet is interrupted
+// before it is killed
+const commandGracePeriod = 10 * time.Second
+
+// rootContext is cancelled when Fleet receives an interrupt or termination signal.
+// Every external command is started from it so docker never outlives Fleet.
+var rootContext, cancelRootContext = context.WithCancel(context.Background())
+
+var (
+	signalMutex       sync.Mutex
+	receivedSignal    os.Signal
+	interruptCleanups = map[int]func(){}
+	nextCleanupID     int
+)
+
+// handleSignals cancels the root context on the first signal and exits on the second
+func handleSignals() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		signalMutex.Lock()
+		receivedSignal = sig
+		signalMutex.Unlock()
+
+		cancelRootContext()
+		runInterruptCleanups()
+
+		// A second signal stops waiting for child processes
+		<-signals
+		os.Exit(130)
+	}()
+}
+
+// getReceivedSignal returns the signal that interrupted Fleet, if any
+func getReceivedSignal() os.Signal {
+	signalMutex.Lock()
+	defer signalMutex.Unlock()
+	return receivedSignal
+}
+
+// newCommand creates a command that is stopped when Fleet is interrupted
+func newCommand(name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(rootContext, name, args...)
+	cmd.Cancel = func() error {
+		// Ctrl-C already reached the child through the terminal. Sending it again
+		// makes docker compose force-kill containers instead of stopping them.
+		if getReceivedSignal() == os.Interrupt {
+			return nil
+		}
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = commandGracePeriod
+	return cmd
+}
+
+// onInterrupt registers cleanup to run if Fleet is interrupted.
+// The returned function unregisters it once the guarded work is done.
+func onInterrupt(cleanup func()) func() {
+	signalMutex.Lock()
+	defer signalMutex.Unlock()
+
+	id := nextCleanupID
+	nextCleanupID++
+	interruptCleanups[id] = cleanup
+
+	return func() {
+		signalMutex.Lock()
+		defer signalMutex.Unlock()
+		delete(interruptCleanups, id)
+	}
+}
+
+// runInterruptCleanups runs and clears all registered cleanups
+func runInterruptCleanups() {
+	signalMutex.Lock()
+	cleanups := interruptCleanups
+	interruptCleanups = map[int]func(){}
+	signalMutex.Unlock()
+
+	for _, cleanup := range cleanups {
+		cleanup()
+	}
+}
+
+// exitIfInterrupted exits quietly when a command failed because Fleet was interrupted
+func exitIfInterrupted() {
+	if rootContext.Err() == nil {
+		return
+	}
+	runInterruptCleanups()
+	warnln("\n🛑 Interrupted")
+	os.Exit(130)
+}
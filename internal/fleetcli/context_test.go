@@ -0,0 +1,75 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ContextTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+}
+
+func (suite *ContextTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+}
+
+func (suite *ContextTestSuite) TearDownTest() {
+	suite.helper.Cleanup()
+}
+
+func (suite *ContextTestSuite) TestNewCommandUsesRootContext() {
+	cmd := newCommand("docker", "compose", "ps")
+
+	suite.Equal([]string{"docker", "compose", "ps"}, cmd.Args)
+	suite.NotNil(cmd.Cancel)
+	suite.Equal(commandGracePeriod, cmd.WaitDelay)
+}
+
+func (suite *ContextTestSuite) TestInterruptCleanups() {
+	var ran []string
+	done := onInterrupt(func() { ran = append(ran, "hosts") })
+	onInterrupt(func() { ran = append(ran, "other") })
+
+	// Finished work no longer needs cleanup
+	done()
+	runInterruptCleanups()
+	suite.Equal([]string{"other"}, ran)
+
+	// Cleanups only run once
+	runInterruptCleanups()
+	suite.Equal([]string{"other"}, ran)
+}
+
+func (suite *ContextTestSuite) TestGuardHostsEditRestoresOriginal() {
+	hostsFile := filepath.Join(suite.helper.TempDir(), "hosts")
+	original := []byte("127.0.0.1 localhost\n")
+	suite.Require().NoError(os.WriteFile(hostsFile, []byte("127.0.0.1 local"), 0644))
+
+	guardHostsEdit(hostsFile, original)
+	runInterruptCleanups()
+
+	content, err := os.ReadFile(hostsFile)
+	suite.Require().NoError(err)
+	suite.Equal(original, content)
+}
+
+func (suite *ContextTestSuite) TestGuardHostsEditDoneLeavesFile() {
+	hostsFile := filepath.Join(suite.helper.TempDir(), "hosts")
+	suite.Require().NoError(os.WriteFile(hostsFile, []byte("updated"), 0644))
+
+	done := guardHostsEdit(hostsFile, []byte("original"))
+	done()
+	runInterruptCleanups()
+
+	content, err := os.ReadFile(hostsFile)
+	suite.Require().NoError(err)
+	suite.Equal("updated", string(content))
+}
+
+func TestContextSuite(t *testing.T) {
+	suite.Run(t, new(ContextTestSuite))
+}
@@ -0,0 +1,68 @@
+package fleetcli
+
+import (
+	"fmt"
+)
+
+// getCronTaskName returns the maintenance task name of the cron job at index
+func getCronTaskName(serviceName string, index int, job CronJob) string {
+	if job.Name != "" {
+		return fmt.Sprintf("%s-cron-%s", serviceName, job.Name)
+	}
+	return fmt.Sprintf("%s-cron-%d", serviceName, index+1)
+}
+
+// getMaintenanceTasks returns the maintenance tasks of a config with the cron jobs of its
+// services. Cron jobs are scheduled tasks running a command in the app container, so the
+// maintenance scheduler runs them, and fleet maintain runs and lists them.
+func getMaintenanceTasks(config *Config) map[string]MaintenanceTask {
+	tasks := make(map[string]MaintenanceTask, len(config.Maintenance))
+	for name, task := range config.Maintenance {
+		tasks[name] = task
+	}
+	for _, svc := range config.Services {
+		for i, job := range svc.Cron {
+			tasks[getCronTaskName(svc.Name, i, job)] = MaintenanceTask{
+				Service:  svc.Name,
+				Command:  job.Command,
+				Schedule: job.Schedule,
+			}
+		}
+	}
+	return tasks
+}
+
+// validateCronJobs checks the cron jobs of every service. They share the names of
+// maintenance tasks, so they must not collide.
+func validateCronJobs(config *Config) error {
+	names := make(map[string]string)
+	for name := range config.Maintenance {
+		names[name] = "maintenance task " + name
+	}
+
+	for _, svc := range config.Services {
+		if len(svc.Cron) > 0 && (svc.Native || svc.ExternalService != "") {
+			return fmt.Errorf("service %s: cron jobs run in the container of the service, and it has none", svc.Name)
+		}
+		for i, job := range svc.Cron {
+			name := getCronTaskName(svc.Name, i, job)
+			if job.Name != "" && !maintenanceTaskName.MatchString(job.Name) {
+				return fmt.Errorf("service %s: invalid cron job name %q, use lowercase letters, digits, - and _", svc.Name, job.Name)
+			}
+			if job.Command == "" {
+				return fmt.Errorf("service %s: cron job %s needs a 'command'", svc.Name, name)
+			}
+			if job.Schedule == "" {
+				return fmt.Errorf("service %s: cron job %s needs a 'schedule', like \"* * * * *\"", svc.Name, name)
+			}
+			if err := validateCronSchedule(job.Schedule); err != nil {
+				return fmt.Errorf("service %s: cron job %s: invalid schedule %q (%v)", svc.Name, name, job.Schedule, err)
+			}
+			if existing, exists := names[name]; exists {
+				return fmt.Errorf("service %s: cron job name %s is already used by %s", svc.Name, name, existing)
+			}
+			names[name] = "a cron job of " + svc.Name
+		}
+	}
+	return nil
+}
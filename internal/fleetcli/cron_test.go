@@ -0,0 +1,97 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CronTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+	config      *Config
+}
+
+func (suite *CronTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+
+	suite.config = &Config{Project: "shop", Services: []Service{
+		{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Framework: "laravel", Folder: "shop", Cron: []CronJob{
+			{Schedule: "* * * * *", Command: "php artisan schedule:run"},
+		}},
+		{Name: "api", Image: "node:20", Cron: []CronJob{
+			{Name: "reports", Schedule: "0 6 * * 1", Command: "node scripts/reports.js 'weekly'"},
+		}},
+	}}
+}
+
+func (suite *CronTestSuite) TearDownTest() {
+	os.Chdir(suite.originalDir)
+	suite.helper.Cleanup()
+}
+
+func (suite *CronTestSuite) TestGetMaintenanceTasks() {
+	suite.config.Maintenance = map[string]MaintenanceTask{"flush": {Service: "shop", Command: "php artisan cache:clear"}}
+
+	tasks := getMaintenanceTasks(suite.config)
+	suite.Len(tasks, 3)
+	suite.Equal(MaintenanceTask{Service: "shop", Command: "php artisan schedule:run", Schedule: "* * * * *"}, tasks["shop-cron-1"])
+	suite.Equal("api", tasks["api-cron-reports"].Service)
+	suite.Equal([]string{"api-cron-reports", "flush", "shop-cron-1"}, getMaintenanceTaskNames(suite.config))
+}
+
+func (suite *CronTestSuite) TestValidateCronJobs() {
+	suite.NoError(validateCronJobs(suite.config))
+
+	testCases := []struct {
+		name    string
+		job     CronJob
+		wantErr string
+	}{
+		{"no command", CronJob{Schedule: "* * * * *"}, "needs a 'command'"},
+		{"no schedule", CronJob{Command: "run"}, "needs a 'schedule'"},
+		{"invalid schedule", CronJob{Schedule: "every minute", Command: "run"}, "invalid schedule"},
+		{"invalid name", CronJob{Name: "Nightly Job", Schedule: "0 0 * * *", Command: "run"}, "invalid cron job name"},
+	}
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			err := validateCronJobs(&Config{Services: []Service{{Name: "api", Cron: []CronJob{tc.job}}}})
+			suite.Require().Error(err)
+			suite.Contains(err.Error(), tc.wantErr)
+		})
+	}
+
+	suite.config.Maintenance = map[string]MaintenanceTask{"shop-cron-1": {Service: "shop", Command: "true"}}
+	err := validateCronJobs(suite.config)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "already used by maintenance task shop-cron-1")
+
+	err = validateCronJobs(&Config{Services: []Service{{Name: "api", Native: true, Cron: []CronJob{{Schedule: "* * * * *", Command: "run"}}}}})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "it has none")
+}
+
+func (suite *CronTestSuite) TestScheduler() {
+	compose := generateDockerCompose(suite.config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
+
+	scheduler, exists := compose.Services[maintenanceServiceName]
+	suite.Require().True(exists, "Cron jobs run in the maintenance scheduler")
+	suite.Contains(scheduler.Command, "* * * * * sh /usr/local/bin/fleet-maintenance shop-cron-1")
+	suite.Contains(scheduler.Command, "0 6 * * 1 sh /usr/local/bin/fleet-maintenance api-cron-reports")
+
+	script, err := os.ReadFile(filepath.Join(".fleet", maintenanceScriptFile))
+	suite.Require().NoError(err)
+	// PHP apps behind nginx run their code in the FPM container
+	suite.Contains(string(script), "shop-cron-1) run 'shop-php' 'sh' '-c' 'php artisan schedule:run' ;;")
+	suite.Contains(string(script), `api-cron-reports) run 'api' 'sh' '-c' 'node scripts/reports.js '\''weekly'\''' ;;`)
+}
+
+func TestCronSuite(t *testing.T) {
+	suite.Run(t, new(CronTestSuite))
+}
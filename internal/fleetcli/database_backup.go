@@ -0,0 +1,147 @@
+package fleetcli
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// defaultBackupRetentionDays is how long backups are kept when backup_retention isn't set
+const defaultBackupRetentionDays = 7
+
+// cronScheduleField matches a single field of a cron expression
+var cronScheduleField = regexp.MustCompile(`^[0-9*/,-]+$`)
+
+// getBackupServiceName returns the name of the backup container of a service
+func getBackupServiceName(serviceName string) string {
+	return fmt.Sprintf("%s-backup", serviceName)
+}
+
+// getBackupRetentionDays returns the number of days backups of a service are kept
+func getBackupRetentionDays(svc *Service) int {
+	if svc.BackupRetention > 0 {
+		return svc.BackupRetention
+	}
+	return defaultBackupRetentionDays
+}
+
+// validateCronSchedule checks that a schedule is a 5 field cron expression busybox crond understands
+func validateCronSchedule(schedule string) error {
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return fmt.Errorf("expected 5 cron fields")
+	}
+	for _, field := range fields {
+		if !cronScheduleField.MatchString(field) {
+			return fmt.Errorf("invalid cron field %q", field)
+		}
+	}
+	return nil
+}
+
+// validateBackupSchedule checks the backup settings of a service
+func validateBackupSchedule(svc *Service) error {
+	if svc.BackupSchedule == "" {
+		return nil
+	}
+
+	dbType, _ := parseDatabaseType(svc.Database)
+	switch dbType {
+	case "postgres", "mysql", "mariadb":
+	case "":
+		return fmt.Errorf("service %s: 'backup_schedule' requires 'database'", svc.Name)
+	default:
+		return fmt.Errorf("service %s: backups are not supported for %s", svc.Name, dbType)
+	}
+
+	if err := validateCronSchedule(svc.BackupSchedule); err != nil {
+		return fmt.Errorf("service %s: invalid backup_schedule %q (%v)", svc.Name, svc.BackupSchedule, err)
+	}
+
+	if svc.BackupRetention < 0 {
+		return fmt.Errorf("service %s: backup_retention must be positive", svc.Name)
+	}
+	return nil
+}
+
+// generateBackupScript returns the script cron runs for every backup.
+// Connection details come from the container environment.
+func generateBackupScript(dbType, prefix string, retentionDays int) string {
+	dump := `pg_dump --no-owner --clean --if-exists`
+	if dbType == "mysql" || dbType == "mariadb" {
+		dump = `mysqldump -h "$DB_HOST" -u "$DB_USER" --single-transaction --routines --triggers "$DB_NAME"`
+	}
+
+	return fmt.Sprintf(`#!/bin/sh
+# Generated by Fleet CLI - DO NOT EDIT
+set -eo pipefail
+
+file="/backups/%[1]s-$(date +%%Y%%m%%d-%%H%%M%%S).sql.gz"
+%[2]s | gzip > "$file"
+echo "Backup written to $file"
+
+# Retention
+find /backups -name '%[1]s-*.sql.gz' -mtime +%[3]d -delete
+`, prefix, dump, retentionDays)
+}
+
+// planBackupScript plans the backup script of a service and the backups folder
+func planBackupScript(plan *ArtifactPlan, serviceName, script string) string {
+	plan.addDir(filepath.Join(".fleet", "backups"), 0755)
+
+	name := fmt.Sprintf("%s-backup.sh", serviceName)
+	plan.addFile(plan.path(name), []byte(script), 0755)
+	return plan.mountPath(name)
+}
+
+// addDatabaseBackupService adds a container that dumps the service's database on schedule
+// into .fleet/backups
+func addDatabaseBackupService(compose *DockerCompose, svc *Service) {
+	if svc.BackupSchedule == "" {
+		return
+	}
+
+	dbType, version := parseDatabaseType(svc.Database)
+	dbServiceName := getSharedDatabaseServiceName(dbType, version)
+	dbName := getEnvOrDefault(svc.DatabaseName, svc.Name)
+
+	backupService := DockerService{
+		Networks:    []string{"fleet-network"},
+		Restart:     "unless-stopped",
+		DependsOn:   []string{dbServiceName},
+		Environment: make(map[string]string),
+	}
+
+	// Install a client that matches the server and run the script from busybox crond
+	setup := ""
+	switch dbType {
+	case "postgres":
+		// The alpine server image ships pg_dump of the same version
+		backupService.Image = getDatabaseImage(dbType, version)
+		backupService.Environment["PGHOST"] = dbServiceName
+		backupService.Environment["PGUSER"] = getEnvOrDefault(svc.DatabaseUser, svc.Name)
+		backupService.Environment["PGPASSWORD"] = getEnvOrDefault(svc.DatabasePassword, "password")
+		backupService.Environment["PGDATABASE"] = dbName
+	case "mysql", "mariadb":
+		backupService.Image = "alpine:3.19"
+		setup = "apk add --no-cache mysql-client && "
+		backupService.Environment["DB_HOST"] = dbServiceName
+		backupService.Environment["DB_USER"] = getEnvOrDefault(svc.DatabaseUser, svc.Name)
+		backupService.Environment["MYSQL_PWD"] = getEnvOrDefault(svc.DatabasePassword, "password")
+		backupService.Environment["DB_NAME"] = dbName
+	}
+
+	script := generateBackupScript(dbType, dbName, getBackupRetentionDays(svc))
+	scriptPath := planBackupScript(getArtifactPlan(compose), svc.Name, script)
+
+	// Paths are relative to the compose file in .fleet, backups are shared by every config
+	backupService.Volumes = []string{
+		"./backups:/backups",
+		formatBindMount(scriptPath, "/usr/local/bin/fleet-backup:ro"),
+	}
+	backupService.Command = fmt.Sprintf(`sh -c "%secho '%s sh /usr/local/bin/fleet-backup' > /etc/crontabs/root && crond -f -l 8"`,
+		setup, strings.Join(strings.Fields(svc.BackupSchedule), " "))
+
+	compose.Services[getBackupServiceName(svc.Name)] = backupService
+}
@@ -0,0 +1,133 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DatabaseBackupTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *DatabaseBackupTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+
+	suite.originalDir, _ = os.Getwd()
+	suite.Require().NoError(os.Chdir(suite.helper.TempDir()))
+}
+
+func (suite *DatabaseBackupTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *DatabaseBackupTestSuite) TestValidateBackupSchedule() {
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr string
+	}{
+		{"postgres", Service{Name: "app", Database: "postgres:15", BackupSchedule: "0 3 * * *"}, ""},
+		{"mysql with step", Service{Name: "app", Database: "mysql:8.0", BackupSchedule: "*/30 * * * 1-5"}, ""},
+		{"disabled", Service{Name: "app", Database: "mongodb"}, ""},
+		{"no database", Service{Name: "app", Image: "nginx", BackupSchedule: "0 3 * * *"}, "requires 'database'"},
+		{"mongodb", Service{Name: "app", Database: "mongodb:7.0", BackupSchedule: "0 3 * * *"}, "not supported"},
+		{"too few fields", Service{Name: "app", Database: "postgres", BackupSchedule: "0 3 * *"}, "expected 5 cron fields"},
+		{"bad characters", Service{Name: "app", Database: "postgres", BackupSchedule: "0 3 * * $(rm)"}, "invalid backup_schedule"},
+		{"negative retention", Service{Name: "app", Database: "postgres", BackupSchedule: "0 3 * * *", BackupRetention: -1}, "backup_retention"},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			err := validateBackupSchedule(&tt.svc)
+			if tt.wantErr == "" {
+				suite.NoError(err)
+				return
+			}
+			suite.Error(err)
+			suite.Contains(err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func (suite *DatabaseBackupTestSuite) TestPostgresBackupService() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "app", Image: "php:8.3-fpm", Database: "postgres:15", DatabaseName: "shop", BackupSchedule: "0 3 * * *"},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
+
+	backup, exists := compose.Services["app-backup"]
+	suite.Require().True(exists)
+	suite.Equal("postgres:15-alpine", backup.Image)
+	suite.Equal([]string{"postgres-15"}, backup.DependsOn)
+	suite.Equal("postgres-15", backup.Environment["PGHOST"])
+	suite.Equal("shop", backup.Environment["PGDATABASE"])
+	suite.Contains(backup.Volumes, "./backups:/backups")
+	suite.Contains(backup.Command, "echo '0 3 * * * sh /usr/local/bin/fleet-backup' > /etc/crontabs/root")
+
+	script, err := os.ReadFile(filepath.Join(".fleet", "app-backup.sh"))
+	suite.Require().NoError(err)
+	suite.Contains(string(script), "pg_dump --no-owner")
+	suite.Contains(string(script), "-mtime +7 -delete")
+	suite.DirExists(filepath.Join(".fleet", "backups"))
+}
+
+func (suite *DatabaseBackupTestSuite) TestMySQLBackupService() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "app", Image: "php:8.3-fpm", Database: "mysql:8.0", BackupSchedule: "30 2 * * *", BackupRetention: 14},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
+
+	backup := compose.Services["app-backup"]
+	suite.Equal("alpine:3.19", backup.Image)
+	suite.Contains(backup.Command, "apk add --no-cache mysql-client")
+	suite.Equal("mysql-80", backup.Environment["DB_HOST"])
+	suite.Equal("password", backup.Environment["MYSQL_PWD"])
+
+	script, err := os.ReadFile(filepath.Join(".fleet", "app-backup.sh"))
+	suite.Require().NoError(err)
+	suite.Contains(string(script), `mysqldump -h "$DB_HOST"`)
+	suite.Contains(string(script), "-mtime +14 -delete")
+}
+
+func (suite *DatabaseBackupTestSuite) TestGenerateBackupScript() {
+	script := generateBackupScript("postgres", "shop", 7)
+
+	suite.Contains(script, `file="/backups/shop-$(date +%Y%m%d-%H%M%S).sql.gz"`)
+	suite.Contains(script, "find /backups -name 'shop-*.sql.gz' -mtime +7 -delete")
+}
+
+func (suite *DatabaseBackupTestSuite) TestNoBackupWithoutSchedule() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "app", Image: "php:8.3-fpm", Database: "postgres:15"},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	_, exists := compose.Services["app-backup"]
+	suite.False(exists)
+}
+
+func TestDatabaseBackupSuite(t *testing.T) {
+	suite.Run(t, new(DatabaseBackupTestSuite))
+}
@@ -0,0 +1,195 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// databaseNamePattern matches a database name every supported database accepts unquoted
+var databaseNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,62}$`)
+
+// getDatabaseCloneExec returns how to copy the database of a service into a new database
+// on the same container. The copy is dumped to a file first, so a failed dump never
+// leaves an empty database behind, and a failed restore drops it.
+func getDatabaseCloneExec(svc *Service, name string) (*MaintenanceExec, error) {
+	if !databaseNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid database name %q, use letters, digits and _", name)
+	}
+
+	dbType, version := parseDatabaseType(svc.Database)
+	target := getSharedDatabaseServiceName(dbType, version)
+	source := getEnvOrDefault(svc.DatabaseName, svc.Name)
+	if name == source {
+		return nil, fmt.Errorf("%s is the database of service %s, choose another name", name, svc.Name)
+	}
+	dump := fmt.Sprintf("/tmp/fleet-clone-%s", name)
+
+	switch dbType {
+	case "postgres":
+		return &MaintenanceExec{
+			Target: target,
+			Env: map[string]string{
+				"PGUSER":     getEnvOrDefault(svc.DatabaseUser, svc.Name),
+				"PGPASSWORD": getEnvOrDefault(svc.DatabasePassword, "password"),
+			},
+			Command: fmt.Sprintf("pg_dump --no-owner --no-privileges -d %s -f %s && createdb %s && "+
+				"{ psql -q -v ON_ERROR_STOP=1 -d %s -f %s || { dropdb %s; false; }; }; status=$?; rm -f %s; exit $status",
+				shellQuote(source), dump, name, name, dump, name, dump),
+		}, nil
+
+	case "mysql", "mariadb":
+		// The app user can only reach its own database, so root creates the copy
+		client, dumper := "mysql", "mysqldump"
+		if dbType == "mariadb" {
+			client, dumper = "mariadb", "mariadb-dump"
+		}
+		user := strings.ReplaceAll(getEnvOrDefault(svc.DatabaseUser, svc.Name), "'", "''")
+		create := fmt.Sprintf("CREATE DATABASE %s; GRANT ALL PRIVILEGES ON %s.* TO '%s'@'%%'", name, name, user)
+		return &MaintenanceExec{
+			Target: target,
+			Env:    map[string]string{"MYSQL_PWD": getEnvOrDefault(svc.DatabaseRootPassword, "rootpassword")},
+			Command: fmt.Sprintf("%s -uroot --single-transaction --routines --triggers --events %s > %s && %s -uroot -e %s && "+
+				"{ %s -uroot %s < %s || { %s -uroot -e 'DROP DATABASE %s'; false; }; }; status=$?; rm -f %s; exit $status",
+				dumper, shellQuote(source), dump, client, shellQuote(create), client, name, dump, client, name, dump),
+		}, nil
+
+	case "mongodb":
+		auth := fmt.Sprintf("--username %s --password %s --authenticationDatabase admin",
+			shellQuote(getEnvOrDefault(svc.DatabaseUser, "admin")), shellQuote(getEnvOrDefault(svc.DatabasePassword, "password")))
+		return &MaintenanceExec{
+			Target: target,
+			Command: fmt.Sprintf("mongodump --quiet %s --db %s --archive=%s && mongorestore --quiet %s --archive=%s --nsFrom %s --nsTo %s; status=$?; rm -f %s; exit $status",
+				auth, shellQuote(source), dump, auth, dump, shellQuote(source+".*"), shellQuote(name+".*"), dump),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("service %s has no database to clone", svc.Name)
+}
+
+// getDatabaseCloneEnv returns the connection variables of the copy, the ones the service
+// gets for its own database
+func getDatabaseCloneEnv(svc *Service, name string) map[string]string {
+	clone := *svc
+	clone.DatabaseName = name
+	dbType, version := parseDatabaseType(svc.Database)
+
+	var service DockerService
+	addDatabaseEnvVars(&service, dbType, getSharedDatabaseServiceName(dbType, version), &clone)
+	return service.Environment
+}
+
+// cloneDatabase copies the database of a service and returns the connection variables of
+// the copy
+func cloneDatabase(composeFiles ComposeFiles, svc *Service, name string) (map[string]string, error) {
+	exec, err := getDatabaseCloneExec(svc, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := execInService(composeFiles, exec); err != nil {
+		return nil, fmt.Errorf("failed to clone the database of %s into %s (is %s running?): %w", svc.Name, name, exec.Target, err)
+	}
+	return getDatabaseCloneEnv(svc, name), nil
+}
+
+func handleDB() {
+	if len(os.Args) < 3 {
+		printDBUsage()
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "clone":
+		handleDBClone(os.Args[3:])
+	case "shell", "dump", "restore", "list", "create", "drop":
+		handleDBTool(os.Args[2], os.Args[3:])
+	case "slowlog":
+		handleDBSlowlog(os.Args[3:])
+	case "help":
+		printDBUsage()
+	default:
+		fmt.Printf("Unknown db command: %s\n\n", os.Args[2])
+		printDBUsage()
+		os.Exit(1)
+	}
+}
+
+func printDBUsage() {
+	fmt.Println("Fleet db - Work with the databases of services")
+	fmt.Println("\nUsage: fleet db <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  list                    List the database containers and the database of each service")
+	fmt.Println("  shell <service> [args]  Open psql, mysql or mongosh in a service's database, or as the")
+	fmt.Println("                          superuser of a container like postgres-16")
+	fmt.Println("  dump <service> [file]   Dump a service's database, .gz to compress it, - for the output")
+	fmt.Println("  restore <service> <file>  Load a dump into a service's database")
+	fmt.Println("  create <service>        Create the database and user of a service on its shared container")
+	fmt.Println("  drop <service>          Drop the database of a service")
+	fmt.Println("  clone <service> <name>  Copy the schema and data of a service's database into a new database")
+	fmt.Println("  slowlog <service>       Show the last slow queries of a service, with slow_query_log = true")
+	fmt.Println("\nCredentials come from the compose files 'fleet up' generated.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("  --yes       Drop without asking")
+	fmt.Println("  -n 20       Number of slow queries to show (for 'slowlog')")
+	fmt.Println("  --summary   Group slow queries and show the slowest in total (for 'slowlog')")
+	fmt.Println("  --follow    Print slow queries as they are logged (for 'slowlog')")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet db shell api                 # Open psql in the database of api")
+	fmt.Println("  fleet db dump shop shop.sql.gz     # Dump the database of shop")
+	fmt.Println("  fleet db restore shop shop.sql.gz  # Load it back")
+	fmt.Println("  fleet db clone api api_experiment  # Experiment on a copy of the api database")
+	fmt.Println("  fleet db slowlog shop --summary    # Show the queries of shop that took the most time")
+}
+
+func handleDBClone(args []string) {
+	fs := newCommandFlagSet("db clone", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+
+	positional := parseFlagsAndArgs(fs, args)
+	if len(positional) != 2 {
+		log.Fatalf("❌ Usage: fleet db clone <service> <name>")
+	}
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+
+	svc := findService(config, positional[0])
+	if svc == nil {
+		log.Fatalf("❌ Service %s not found in %s", positional[0], *configFile)
+	}
+	if svc.Database == "" {
+		log.Fatalf("❌ Service %s has no database", svc.Name)
+	}
+
+	name := positional[1]
+	infof("📋 Cloning the database of %s into %s...\n", svc.Name, name)
+	env, err := cloneDatabase(getComposeFiles(config), svc, name)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	dbType, version := parseDatabaseType(svc.Database)
+	infof("✅ Database %s created on %s\n", name, getSharedDatabaseServiceName(dbType, version))
+	outputln("\nConnection variables of the copy:")
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		outputf("  %s=%s\n", key, env[key])
+	}
+	infof("\n💡 To point %s at the copy, set database_name = %q and run 'fleet up -d'\n", svc.Name, name)
+}
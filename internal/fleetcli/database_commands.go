@@ -0,0 +1,482 @@
+package fleetcli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fleet/fleet/internal/dbdump"
+)
+
+// DatabaseTarget is a database in one of the shared database containers of a project,
+// with the user that connects to it
+type DatabaseTarget struct {
+	Service   string // Service of fleet.toml using it, empty for the superuser of the container
+	Container string // Compose service of the database, e.g. postgres-16
+	Type      string // postgres, mysql, mariadb or mongodb
+	Database  string
+	User      string
+	Password  string
+}
+
+// getDatabaseContainerType returns the database type of a compose service like
+// postgres-16, or "" when it isn't a database Fleet added
+func getDatabaseContainerType(compose *DockerCompose, name string) string {
+	if _, exists := compose.Services[name]; !exists {
+		return ""
+	}
+	dbType, _, _ := strings.Cut(name, "-")
+	if _, ok := versionData.Database[dbType]; !ok {
+		return ""
+	}
+	return dbType
+}
+
+// getDatabaseAdmin returns the superuser of a database container, from its environment
+// in the compose file
+func getDatabaseAdmin(files ComposeFiles, compose *DockerCompose, container string) (*DatabaseTarget, error) {
+	dbType := getDatabaseContainerType(compose, container)
+	if dbType == "" {
+		return nil, fmt.Errorf("%s is not a database of the project", container)
+	}
+	env, err := readServiceEnvironment(files, compose, container)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := &DatabaseTarget{Container: container, Type: dbType}
+	switch dbType {
+	case "postgres":
+		admin.User, admin.Password, admin.Database = env["POSTGRES_USER"], env["POSTGRES_PASSWORD"], "postgres"
+	case "mysql":
+		admin.User, admin.Password = "root", env["MYSQL_ROOT_PASSWORD"]
+	case "mariadb":
+		admin.User, admin.Password = "root", env["MARIADB_ROOT_PASSWORD"]
+	case "mongodb":
+		admin.User, admin.Password, admin.Database = env["MONGO_INITDB_ROOT_USERNAME"], env["MONGO_INITDB_ROOT_PASSWORD"], "admin"
+	}
+	return admin, nil
+}
+
+// getDatabaseTargets returns the database of every service of a project that has one,
+// with the credentials its container gets in the compose file. Services sharing a
+// database container have one target each.
+func getDatabaseTargets(config *Config, files ComposeFiles, compose *DockerCompose) ([]DatabaseTarget, error) {
+	var targets []DatabaseTarget
+	for i := range config.Services {
+		svc := &config.Services[i]
+		dbType, version := parseDatabaseType(svc.Database)
+		container := getSharedDatabaseServiceName(dbType, version)
+		if dbType == "" || getDatabaseContainerType(compose, container) == "" {
+			continue
+		}
+		env, err := readServiceEnvironment(files, compose, svc.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		// The variables of env_style and env_map may have other names, the defaults
+		// are the values they were built from
+		var defaults DockerService
+		addDatabaseEnvVars(&defaults, dbType, container, svc)
+		keys := [3]string{"DB_DATABASE", "DB_USERNAME", "DB_PASSWORD"}
+		if dbType == "mongodb" {
+			keys = [3]string{"MONGO_DB", "MONGO_USER", "MONGO_PASSWORD"}
+		}
+		value := func(key string) string {
+			return getEnvOrDefault(env[key], defaults.Environment[key])
+		}
+		targets = append(targets, DatabaseTarget{
+			Service:   svc.Name,
+			Container: container,
+			Type:      dbType,
+			Database:  value(keys[0]),
+			User:      value(keys[1]),
+			Password:  value(keys[2]),
+		})
+	}
+	return targets, nil
+}
+
+// resolveDatabaseTarget returns the database a name refers to: the database of a service
+// of fleet.toml, or the superuser of a database container like postgres-16
+func resolveDatabaseTarget(config *Config, files ComposeFiles, compose *DockerCompose, name string) (*DatabaseTarget, error) {
+	targets, err := getDatabaseTargets(config, files, compose)
+	if err != nil {
+		return nil, err
+	}
+	for i := range targets {
+		if targets[i].Service == name {
+			return &targets[i], nil
+		}
+	}
+	if svc := findService(config, name); svc != nil {
+		return nil, fmt.Errorf("service %s has no database", name)
+	}
+	if getDatabaseContainerType(compose, name) != "" {
+		return getDatabaseAdmin(files, compose, name)
+	}
+
+	var names []string
+	for _, target := range targets {
+		names = append(names, target.Service)
+		if !containsString(names, target.Container) {
+			names = append(names, target.Container)
+		}
+	}
+	return nil, fmt.Errorf("unknown database %s, the project has: %s", name, strings.Join(names, ", "))
+}
+
+// getDatabaseClientEnv returns the variables that log the clients of a database in
+func getDatabaseClientEnv(target *DatabaseTarget) []string {
+	switch target.Type {
+	case "postgres":
+		return []string{"PGPASSWORD=" + target.Password}
+	case "mysql", "mariadb":
+		return []string{"MYSQL_PWD=" + target.Password}
+	}
+	return nil
+}
+
+// getMongoAuthArgs returns the options that log the MongoDB tools in
+func getMongoAuthArgs(target *DatabaseTarget) []string {
+	return []string{"--username", target.User, "--password", target.Password, "--authenticationDatabase", "admin"}
+}
+
+// getMySQLClient returns the client and dump tool of MySQL or MariaDB. Recent MariaDB
+// images don't have the mysql names anymore.
+func getMySQLClient(dbType string) (string, string) {
+	if dbType == "mariadb" {
+		return "mariadb", "mariadb-dump"
+	}
+	return "mysql", "mysqldump"
+}
+
+// getDatabaseShellCommand returns the interactive client of a database, with extra
+// arguments for it
+func getDatabaseShellCommand(target *DatabaseTarget, args []string) []string {
+	var command []string
+	switch target.Type {
+	case "postgres":
+		command = []string{"psql", "-U", target.User, "-d", target.Database}
+	case "mysql", "mariadb":
+		client, _ := getMySQLClient(target.Type)
+		command = []string{client, "-u", target.User}
+		if target.Database != "" {
+			command = append(command, target.Database)
+		}
+	case "mongodb":
+		command = append([]string{"mongosh", "--quiet"}, getMongoAuthArgs(target)...)
+		command = append(command, target.Database)
+	}
+	return append(command, args...)
+}
+
+// getDatabaseDumpCommand returns the command writing a database to its output, as SQL or
+// as a MongoDB archive
+func getDatabaseDumpCommand(target *DatabaseTarget) []string {
+	switch target.Type {
+	case "postgres":
+		return []string{"pg_dump", "-U", target.User, "--no-owner", "--clean", "--if-exists", target.Database}
+	case "mysql", "mariadb":
+		_, dumper := getMySQLClient(target.Type)
+		return []string{dumper, "-u", target.User, "--single-transaction", "--routines", "--triggers", "--no-tablespaces", target.Database}
+	case "mongodb":
+		return append(append([]string{"mongodump", "--quiet"}, getMongoAuthArgs(target)...), "--db", target.Database, "--archive")
+	}
+	return nil
+}
+
+// getDatabaseRestoreCommand returns the command loading a dump from its input into a
+// database, stopping at the first error
+func getDatabaseRestoreCommand(target *DatabaseTarget) []string {
+	switch target.Type {
+	case "postgres":
+		return []string{"psql", "-U", target.User, "-d", target.Database, "-q", "-v", "ON_ERROR_STOP=1"}
+	case "mongodb":
+		return append(append([]string{"mongorestore", "--quiet"}, getMongoAuthArgs(target)...), "--archive", "--drop")
+	}
+	return getDatabaseShellCommand(target, nil)
+}
+
+// getDatabaseCreateExec returns how the superuser of a container creates the database and
+// the user of a service, keeping them when they exist. Only the first service using a
+// shared container gets its database when the container starts.
+func getDatabaseCreateExec(admin, target *DatabaseTarget) (*MaintenanceExec, error) {
+	if !databaseNamePattern.MatchString(target.Database) || !databaseNamePattern.MatchString(target.User) {
+		return nil, fmt.Errorf("database %q or user %q of %s has characters fleet db create doesn't support", target.Database, target.User, target.Service)
+	}
+	password := strings.ReplaceAll(target.Password, "'", "''")
+
+	switch target.Type {
+	case "postgres":
+		role := fmt.Sprintf("DO $$ BEGIN IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = '%s') THEN CREATE ROLE %s LOGIN PASSWORD '%s'; END IF; END $$",
+			target.User, target.User, password)
+		return &MaintenanceExec{
+			Target: target.Container,
+			Env:    map[string]string{"PGUSER": admin.User, "PGPASSWORD": admin.Password},
+			Command: fmt.Sprintf("psql -q -v ON_ERROR_STOP=1 -d postgres -c %s && "+
+				"{ psql -tA -d postgres -c \"SELECT 1 FROM pg_database WHERE datname = '%s'\" | grep -q 1 || createdb -O %s %s; }",
+				shellQuote(role), target.Database, target.User, target.Database),
+		}, nil
+
+	case "mysql", "mariadb":
+		client, _ := getMySQLClient(target.Type)
+		sql := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s; CREATE USER IF NOT EXISTS '%s'@'%%' IDENTIFIED BY '%s'; GRANT ALL PRIVILEGES ON %s.* TO '%s'@'%%'",
+			target.Database, target.User, password, target.Database, target.User)
+		return &MaintenanceExec{
+			Target:  target.Container,
+			Env:     map[string]string{"MYSQL_PWD": admin.Password},
+			Command: fmt.Sprintf("%s -u%s -e %s", client, admin.User, shellQuote(sql)),
+		}, nil
+
+	case "mongodb":
+		return nil, fmt.Errorf("MongoDB creates the database of %s on its first write", target.Service)
+	}
+	return nil, fmt.Errorf("service %s has no database", target.Service)
+}
+
+// getDatabaseDropExec returns how the superuser of a container drops the database of a
+// service. Connections to it are closed first, the app is usually connected.
+func getDatabaseDropExec(admin, target *DatabaseTarget) (*MaintenanceExec, error) {
+	if !databaseNamePattern.MatchString(target.Database) {
+		return nil, fmt.Errorf("database %q of %s has characters fleet db drop doesn't support", target.Database, target.Service)
+	}
+	if target.Database == admin.Database {
+		return nil, fmt.Errorf("%s is the maintenance database of %s and can't be dropped", target.Database, target.Container)
+	}
+
+	switch target.Type {
+	case "postgres":
+		terminate := fmt.Sprintf("SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE datname = '%s' AND pid <> pg_backend_pid()", target.Database)
+		return &MaintenanceExec{
+			Target:  target.Container,
+			Env:     map[string]string{"PGUSER": admin.User, "PGPASSWORD": admin.Password},
+			Command: fmt.Sprintf("psql -q -d postgres -c %s > /dev/null && dropdb --if-exists %s", shellQuote(terminate), target.Database),
+		}, nil
+
+	case "mysql", "mariadb":
+		client, _ := getMySQLClient(target.Type)
+		return &MaintenanceExec{
+			Target:  target.Container,
+			Env:     map[string]string{"MYSQL_PWD": admin.Password},
+			Command: fmt.Sprintf("%s -u%s -e %s", client, admin.User, shellQuote("DROP DATABASE IF EXISTS "+target.Database)),
+		}, nil
+
+	case "mongodb":
+		command := append([]string{"mongosh", "--quiet"}, getMongoAuthArgs(admin)...)
+		for i := range command {
+			command[i] = shellQuote(command[i])
+		}
+		return &MaintenanceExec{
+			Target:  target.Container,
+			Command: fmt.Sprintf("%s %s --eval 'db.dropDatabase()'", strings.Join(command, " "), target.Database),
+		}, nil
+	}
+	return nil, fmt.Errorf("service %s has no database", target.Service)
+}
+
+// getDatabaseExecArgs returns the docker arguments running a database tool in the
+// container of a database
+func getDatabaseExecArgs(files ComposeFiles, target *DatabaseTarget, tty bool, command []string) []string {
+	args := composeArgs(files, "exec")
+	if !tty {
+		args = append(args, "-T")
+	}
+	for _, env := range getDatabaseClientEnv(target) {
+		args = append(args, "-e", env)
+	}
+	args = append(args, target.Container)
+	return append(args, command...)
+}
+
+// getDefaultDumpFile returns the file fleet db dump writes to when none is given
+func getDefaultDumpFile(target *DatabaseTarget, now time.Time) string {
+	extension := "sql"
+	if target.Type == "mongodb" {
+		extension = "archive"
+	}
+	return fmt.Sprintf("%s-%s.%s", target.Database, now.Format("20060102-150405"), extension)
+}
+
+// dumpDatabase writes a database to a file, gzipped when its name ends with .gz, or to
+// the output with -. A failed dump leaves no file behind.
+func dumpDatabase(files ComposeFiles, target *DatabaseTarget, file string) error {
+	cmd, err := dockerCommand(getDatabaseExecArgs(files, target, false, getDatabaseDumpCommand(target))...)
+	if err != nil {
+		return err
+	}
+	return dbdump.Dump(cmd, file)
+}
+
+// restoreDatabase loads a dump into a database, gunzipped when its name ends with .gz
+func restoreDatabase(files ComposeFiles, target *DatabaseTarget, file string) error {
+	cmd, err := dockerCommand(getDatabaseExecArgs(files, target, false, getDatabaseRestoreCommand(target))...)
+	if err != nil {
+		return err
+	}
+	return dbdump.Restore(cmd, file)
+}
+
+// printDatabaseTargets lists the database containers of a project and the database of
+// each service in them
+func printDatabaseTargets(targets []DatabaseTarget) {
+	if len(targets) == 0 {
+		outputln("No service has a database")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONTAINER\tTYPE\tSERVICE\tDATABASE\tUSER")
+	for _, target := range targets {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", target.Container, target.Type, target.Service, target.Database, target.User)
+	}
+	w.Flush()
+}
+
+// loadDatabaseCommand loads the config and the generated compose file of a fleet db
+// command
+func loadDatabaseCommand(configFile string) (*Config, ComposeFiles, *DockerCompose) {
+	config, err := loadConfig(configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	files := getComposeFiles(config)
+	compose, err := readComposeFiles(files)
+	if err != nil {
+		log.Fatalf("❌ Error reading the compose file, start the services with 'fleet up' first: %v", err)
+	}
+	return config, files, compose
+}
+
+// handleDBTool runs fleet db shell, dump, restore, list, create and drop
+func handleDBTool(command string, args []string) {
+	fs := newCommandFlagSet("db "+command, flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	yes := fs.Bool("yes", false, "Drop without asking")
+	fs.Usage = printDBUsage
+
+	var positional []string
+	if command == "shell" {
+		// Everything after the database goes to the client
+		fs.Parse(args)
+		positional = fs.Args()
+	} else {
+		positional = parseFlagsAndArgs(fs, args)
+	}
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, files, compose := loadDatabaseCommand(*configFile)
+	if command == "list" {
+		targets, err := getDatabaseTargets(config, files, compose)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		printDatabaseTargets(targets)
+		return
+	}
+
+	usage := map[string]string{
+		"shell":   "fleet db shell <service|container> [client args...]",
+		"dump":    "fleet db dump <service> [file]",
+		"restore": "fleet db restore <service> <file>",
+		"create":  "fleet db create <service>",
+		"drop":    "fleet db drop <service> [--yes]",
+	}
+	switch {
+	case len(positional) == 0,
+		command == "dump" && len(positional) > 2,
+		command == "restore" && len(positional) != 2,
+		(command == "create" || command == "drop") && len(positional) != 1:
+		log.Fatalf("❌ Usage: %s", usage[command])
+	}
+
+	target, err := resolveDatabaseTarget(config, files, compose, positional[0])
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if command != "shell" && target.Service == "" {
+		log.Fatalf("❌ fleet db %s works on the database of a service, not on %s", command, target.Container)
+	}
+
+	switch command {
+	case "shell":
+		tty := isInteractiveTerminal() && isOutputTerminal()
+		if err := runDocker(getDatabaseExecArgs(files, target, tty, getDatabaseShellCommand(target, positional[1:]))); err != nil {
+			// The client exits with the code of its last statement
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				os.Exit(exitErr.ExitCode())
+			}
+			log.Fatalf("❌ Error opening a shell in %s: %v", target.Container, err)
+		}
+
+	case "dump":
+		file := getDefaultDumpFile(target, time.Now())
+		if len(positional) > 1 {
+			file = positional[1]
+		}
+		if err := dumpDatabase(files, target, file); err != nil {
+			log.Fatalf("❌ Error dumping %s (is %s running?): %v", target.Database, target.Container, err)
+		}
+		if file != "-" {
+			infof("✅ Dumped %s to %s\n", target.Database, file)
+		}
+
+	case "restore":
+		infof("📥 Restoring %s into %s...\n", positional[1], target.Database)
+		if err := restoreDatabase(files, target, positional[1]); err != nil {
+			log.Fatalf("❌ Error restoring %s (is %s running?): %v", target.Database, target.Container, err)
+		}
+		infof("✅ Restored %s\n", target.Database)
+
+	case "create", "drop":
+		admin, err := getDatabaseAdmin(files, compose, target.Container)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		var task *MaintenanceExec
+		if command == "create" {
+			task, err = getDatabaseCreateExec(admin, target)
+		} else {
+			task, err = getDatabaseDropExec(admin, target)
+		}
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if command == "drop" && !*yes && !confirmDatabaseDrop(target) {
+			log.Fatalf("❌ %s was not dropped", target.Database)
+		}
+		if _, err := execInService(files, task); err != nil {
+			log.Fatalf("❌ Failed to %s %s (is %s running?): %v", command, target.Database, target.Container, err)
+		}
+		if command == "create" {
+			infof("✅ Database %s and user %s exist on %s\n", target.Database, target.User, target.Container)
+		} else {
+			infof("🗑️  Dropped %s on %s\n", target.Database, target.Container)
+		}
+	}
+}
+
+// confirmDatabaseDrop asks before dropping a database. Without a terminal to ask on,
+// --yes is needed.
+func confirmDatabaseDrop(target *DatabaseTarget) bool {
+	if !isInteractiveTerminal() {
+		log.Fatalf("❌ Pass --yes to drop %s without a terminal", target.Database)
+	}
+	drop := false
+	err := survey.AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("Drop database %s of %s on %s? Its data is lost", target.Database, target.Service, target.Container),
+		Default: false,
+	}, &drop)
+	return err == nil && drop
+}
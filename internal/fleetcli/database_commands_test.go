@@ -0,0 +1,138 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DatabaseCommandsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *DatabaseCommandsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *DatabaseCommandsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+// writeCompose generates and writes the compose files of a config, like fleet up
+func (suite *DatabaseCommandsTestSuite) writeCompose(config *Config) (ComposeFiles, *DockerCompose) {
+	files, err := writeComposeFiles(config, generateDockerCompose(config))
+	suite.Require().NoError(err)
+	compose, err := readComposeFiles(files)
+	suite.Require().NoError(err)
+	return files, compose
+}
+
+func (suite *DatabaseCommandsTestSuite) TestGetDatabaseTargets() {
+	config := &Config{Project: "test", Secrets: secretsEnvFile, Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Database: "postgres:16", DatabasePassword: "s3cret"},
+		{Name: "worker", Image: "node:20", Database: "postgres:16", DatabaseName: "jobs"},
+		{Name: "shop", Image: "node:20", Port: 3001, Database: "mariadb:11", EnvStyle: "rails"},
+		{Name: "web", Image: "nginx:alpine", Port: 80},
+	}}
+	files, compose := suite.writeCompose(config)
+
+	targets, err := getDatabaseTargets(config, files, compose)
+	suite.Require().NoError(err)
+	suite.Equal([]DatabaseTarget{
+		{Service: "api", Container: "postgres-16", Type: "postgres", Database: "api", User: "api", Password: "s3cret"},
+		{Service: "worker", Container: "postgres-16", Type: "postgres", Database: "jobs", User: "worker", Password: "password"},
+		{Service: "shop", Container: "mariadb-11", Type: "mariadb", Database: "shop", User: "shop", Password: "password"},
+	}, targets)
+
+	admin, err := getDatabaseAdmin(files, compose, "mariadb-11")
+	suite.Require().NoError(err)
+	suite.Equal(&DatabaseTarget{Container: "mariadb-11", Type: "mariadb", User: "root", Password: "rootpassword"}, admin)
+}
+
+func (suite *DatabaseCommandsTestSuite) TestResolveDatabaseTarget() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Database: "postgres:16"},
+		{Name: "web", Image: "nginx:alpine", Port: 80},
+	}}
+	files, compose := suite.writeCompose(config)
+
+	target, err := resolveDatabaseTarget(config, files, compose, "api")
+	suite.Require().NoError(err)
+	suite.Equal("api", target.Database)
+
+	target, err = resolveDatabaseTarget(config, files, compose, "postgres-16")
+	suite.Require().NoError(err)
+	suite.Equal(&DatabaseTarget{Container: "postgres-16", Type: "postgres", Database: "postgres", User: "api", Password: "password"}, target)
+
+	_, err = resolveDatabaseTarget(config, files, compose, "web")
+	suite.ErrorContains(err, "service web has no database")
+	_, err = resolveDatabaseTarget(config, files, compose, "nginx-proxy")
+	suite.ErrorContains(err, "the project has: api, postgres-16")
+}
+
+func (suite *DatabaseCommandsTestSuite) TestClientCommands() {
+	files := ComposeFiles{ProjectDir: ".fleet", Files: []string{".fleet/docker-compose.yml"}}
+	postgres := &DatabaseTarget{Service: "api", Container: "postgres-16", Type: "postgres", Database: "api", User: "api", Password: "secret"}
+	mariadb := &DatabaseTarget{Service: "shop", Container: "mariadb-11", Type: "mariadb", Database: "shop", User: "shop", Password: "secret"}
+	mongo := &DatabaseTarget{Service: "events", Container: "mongodb-7", Type: "mongodb", Database: "events", User: "admin", Password: "secret"}
+
+	suite.Equal(composeArgs(files, "exec", "-e", "PGPASSWORD=secret", "postgres-16", "psql", "-U", "api", "-d", "api", "-c", "\\dt"),
+		getDatabaseExecArgs(files, postgres, true, getDatabaseShellCommand(postgres, []string{"-c", "\\dt"})))
+	suite.Equal([]string{"mariadb", "-u", "shop", "shop"}, getDatabaseShellCommand(mariadb, nil))
+	suite.Equal([]string{"mongosh", "--quiet", "--username", "admin", "--password", "secret", "--authenticationDatabase", "admin", "events"},
+		getDatabaseShellCommand(mongo, nil))
+
+	suite.Equal([]string{"pg_dump", "-U", "api", "--no-owner", "--clean", "--if-exists", "api"}, getDatabaseDumpCommand(postgres))
+	suite.Equal("mariadb-dump", getDatabaseDumpCommand(mariadb)[0])
+	suite.Contains(getDatabaseDumpCommand(mongo), "--archive")
+	suite.Contains(getDatabaseRestoreCommand(postgres), "ON_ERROR_STOP=1")
+	suite.Equal([]string{"mariadb", "-u", "shop", "shop"}, getDatabaseRestoreCommand(mariadb))
+	suite.Equal(composeArgs(files, "exec", "-T", "-e", "MYSQL_PWD=secret", "mariadb-11", "mariadb-dump"),
+		getDatabaseExecArgs(files, mariadb, false, []string{"mariadb-dump"}))
+}
+
+func (suite *DatabaseCommandsTestSuite) TestCreateAndDropExec() {
+	postgresAdmin := &DatabaseTarget{Container: "postgres-16", Type: "postgres", Database: "postgres", User: "api", Password: "password"}
+	worker := &DatabaseTarget{Service: "worker", Container: "postgres-16", Type: "postgres", Database: "jobs", User: "worker", Password: "it's"}
+
+	create, err := getDatabaseCreateExec(postgresAdmin, worker)
+	suite.Require().NoError(err)
+	suite.Equal("postgres-16", create.Target)
+	suite.Equal(map[string]string{"PGUSER": "api", "PGPASSWORD": "password"}, create.Env)
+	suite.Contains(create.Command, "CREATE ROLE worker LOGIN PASSWORD '\\''it'\\'''\\''s'\\''")
+	suite.Contains(create.Command, "createdb -O worker jobs")
+
+	drop, err := getDatabaseDropExec(postgresAdmin, worker)
+	suite.Require().NoError(err)
+	suite.Contains(drop.Command, "pg_terminate_backend")
+	suite.Contains(drop.Command, "dropdb --if-exists jobs")
+
+	mysqlAdmin := &DatabaseTarget{Container: "mysql-80", Type: "mysql", User: "root", Password: "rootpassword"}
+	shop := &DatabaseTarget{Service: "shop", Container: "mysql-80", Type: "mysql", Database: "shop", User: "shop", Password: "password"}
+	create, err = getDatabaseCreateExec(mysqlAdmin, shop)
+	suite.Require().NoError(err)
+	suite.Equal(map[string]string{"MYSQL_PWD": "rootpassword"}, create.Env)
+	suite.Contains(create.Command, "CREATE DATABASE IF NOT EXISTS shop; CREATE USER IF NOT EXISTS '\\''shop'\\''@'\\''%'\\''")
+	drop, err = getDatabaseDropExec(mysqlAdmin, shop)
+	suite.Require().NoError(err)
+	suite.Equal("mysql -uroot -e 'DROP DATABASE IF EXISTS shop'", drop.Command)
+
+	_, err = getDatabaseDropExec(postgresAdmin, &DatabaseTarget{Service: "api", Type: "postgres", Database: "postgres"})
+	suite.ErrorContains(err, "maintenance database")
+	_, err = getDatabaseCreateExec(postgresAdmin, &DatabaseTarget{Service: "api", Type: "postgres", Database: "api; DROP", User: "api"})
+	suite.ErrorContains(err, "doesn't support")
+	_, err = getDatabaseCreateExec(nil, &DatabaseTarget{Service: "events", Type: "mongodb", Database: "events", User: "admin"})
+	suite.ErrorContains(err, "first write")
+}
+
+func TestDatabaseCommandsSuite(t *testing.T) {
+	suite.Run(t, new(DatabaseCommandsTestSuite))
+}
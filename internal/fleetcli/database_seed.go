@@ -0,0 +1,141 @@
+package fleetcli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// databaseSeedDir is where database containers find the seed files of services
+const databaseSeedDir = "/fleet-seed"
+
+// validateDatabaseSeed checks the database_seed of a service. The names end up in the
+// generated init script, so they must be plain identifiers.
+func validateDatabaseSeed(svc *Service) error {
+	if svc.DatabaseSeed == "" {
+		return nil
+	}
+	if svc.Database == "" {
+		return fmt.Errorf("service %s: 'database_seed' requires 'database'", svc.Name)
+	}
+	if svc.DatabaseSnapshotImage != "" {
+		return fmt.Errorf("service %s: 'database_seed' cannot be combined with 'database_snapshot_image', the snapshot already has the data", svc.Name)
+	}
+	if err := validateHostPath("database_seed", svc.DatabaseSeed); err != nil {
+		return fmt.Errorf("service %s: %w", svc.Name, err)
+	}
+
+	dbType, _ := parseDatabaseType(svc.Database)
+	name := getEnvOrDefault(svc.DatabaseName, svc.Name)
+	user := getEnvOrDefault(svc.DatabaseUser, svc.Name)
+	if dbType == "mongodb" {
+		user = getEnvOrDefault(svc.DatabaseUser, "admin")
+	}
+	if !databaseNamePattern.MatchString(name) || !databaseNamePattern.MatchString(user) {
+		return fmt.Errorf("service %s: database_seed needs a database name and user of letters, digits and underscores (got %s and %s)", svc.Name, name, user)
+	}
+	return nil
+}
+
+// getDatabaseSeedMount returns the bind mount of the seed of a service, a file or a
+// folder, and where it is in the database container
+func getDatabaseSeedMount(svc *Service) (volume string, location string) {
+	location = fmt.Sprintf("%s/%s/%s", databaseSeedDir, svc.Name, filepath.Base(svc.DatabaseSeed))
+	hostPath := svc.DatabaseSeed
+	if !filepath.IsAbs(hostPath) {
+		// Compose file lives in .fleet, so project-relative paths need a ../ prefix
+		hostPath = filepath.Join("..", hostPath)
+	}
+	return formatBindMount(hostPath, location+":ro"), location
+}
+
+// quoteSQLString quotes a value as an SQL string literal
+func quoteSQLString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// generateDatabaseSeedScript returns the init script loading the seed of a service into
+// its database. The entrypoint of the database image sources it when the data volume
+// is created. On a database container shared with other services, the database and
+// user of the service don't exist yet, so the script creates them first.
+func generateDatabaseSeedScript(svc *Service, dbType string) string {
+	name := getEnvOrDefault(svc.DatabaseName, svc.Name)
+	user := getEnvOrDefault(svc.DatabaseUser, svc.Name)
+	password := getEnvOrDefault(svc.DatabasePassword, "password")
+
+	var script strings.Builder
+	fmt.Fprintf(&script, "# Fleet: seed the %s database with the database_seed of %s\n\n", name, svc.Name)
+
+	var load map[string]string
+	switch dbType {
+	case "postgres":
+		psql := `psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --no-password --dbname postgres`
+		fmt.Fprintf(&script, "%s -tAc %s | grep -q 1 || %s -c %s\n", psql,
+			shellQuote(fmt.Sprintf("SELECT 1 FROM pg_roles WHERE rolname = '%s'", user)), psql,
+			shellQuote(fmt.Sprintf("CREATE ROLE %s LOGIN PASSWORD %s", user, quoteSQLString(password))))
+		fmt.Fprintf(&script, "%s -tAc %s | grep -q 1 || %s -c %s\n\n", psql,
+			shellQuote(fmt.Sprintf("SELECT 1 FROM pg_database WHERE datname = '%s'", name)), psql,
+			shellQuote(fmt.Sprintf("CREATE DATABASE %s OWNER %s", name, user)))
+		// Objects of the seed belong to the service's user, like those of its migrations
+		client := fmt.Sprintf("psql -v ON_ERROR_STOP=1 --username %s --no-password --dbname %s", user, name)
+		load = map[string]string{
+			"*.sql":    client + ` -f "$f"`,
+			"*.sql.gz": `gunzip -c "$f" | ` + client,
+		}
+	case "mysql", "mariadb":
+		client, _ := getMySQLClient(dbType)
+		root := `MYSQL_PWD="$MYSQL_ROOT_PASSWORD" ` + client + " -uroot"
+		if dbType == "mariadb" {
+			root = `MYSQL_PWD="$MARIADB_ROOT_PASSWORD" ` + client + " -uroot"
+		}
+		fmt.Fprintf(&script, "%s -e %s\n\n", root, shellQuote(fmt.Sprintf(
+			"CREATE DATABASE IF NOT EXISTS %s; CREATE USER IF NOT EXISTS '%s'@'%%' IDENTIFIED BY %s; GRANT ALL PRIVILEGES ON %s.* TO '%s'@'%%'",
+			name, user, quoteSQLString(password), name, user)))
+		load = map[string]string{
+			"*.sql":    root + ` ` + name + ` < "$f"`,
+			"*.sql.gz": `gunzip -c "$f" | ` + root + " " + name,
+		}
+	case "mongodb":
+		client := `$(command -v mongosh || command -v mongo) --quiet --username "$MONGO_INITDB_ROOT_USERNAME" --password "$MONGO_INITDB_ROOT_PASSWORD" --authenticationDatabase admin ` + name
+		load = map[string]string{
+			"*.js": client + ` "$f"`,
+		}
+	}
+
+	patterns := sortedKeys(load)
+	fmt.Fprintf(&script, "find %s -mindepth 1 -maxdepth 2 -type f | sort | while IFS= read -r f; do\n", shellQuote(databaseSeedDir+"/"+svc.Name))
+	script.WriteString("\tcase \"$f\" in\n")
+	for _, pattern := range patterns {
+		fmt.Fprintf(&script, "\t\t%s) echo \"fleet: seeding %s from $f\"; %s ;;\n", pattern, name, load[pattern])
+	}
+	script.WriteString("\t\t*.sh) echo \"fleet: running $f\"; . \"$f\" ;;\n")
+	script.WriteString("\t\t*) echo \"fleet: ignoring $f\" ;;\n")
+	script.WriteString("\tesac\n")
+	script.WriteString("done\n")
+	return script.String()
+}
+
+// addDatabaseSeed mounts the seed of a service into its database container, with the
+// init script loading it. Database images only run init scripts on an empty data
+// volume, so seeds load once, when the environment is created.
+func addDatabaseSeed(compose *DockerCompose, svc *Service, dbType, dbServiceName string) {
+	if svc.DatabaseSeed == "" || svc.DatabaseSnapshotImage != "" {
+		return
+	}
+	service, exists := compose.Services[dbServiceName]
+	if !exists {
+		return
+	}
+
+	volume, _ := getDatabaseSeedMount(svc)
+	plan := getArtifactPlan(compose)
+	scriptName := fmt.Sprintf("%s-seed-%s.sh", dbServiceName, svc.Name)
+	plan.addFile(plan.path(scriptName), []byte(generateDatabaseSeedScript(svc, dbType)), 0644)
+
+	// Init scripts run in name order, after init.sql with the PostgreSQL extensions
+	service.Volumes = append(service.Volumes,
+		volume,
+		formatBindMount(plan.mountPath(scriptName), fmt.Sprintf("/docker-entrypoint-initdb.d/seed-%s.sh:ro", svc.Name)),
+	)
+	compose.Services[dbServiceName] = service
+}
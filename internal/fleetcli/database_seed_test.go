@@ -0,0 +1,91 @@
+package fleetcli
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DatabaseSeedTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *DatabaseSeedTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *DatabaseSeedTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+// assertValidShell checks that sh can parse a generated script
+func (suite *DatabaseSeedTestSuite) assertValidShell(script string) {
+	cmd := exec.Command("sh", "-n")
+	cmd.Stdin = strings.NewReader(script)
+	output, err := cmd.CombinedOutput()
+	suite.NoError(err, string(output))
+}
+
+func (suite *DatabaseSeedTestSuite) TestValidateDatabaseSeed() {
+	suite.NoError(validateDatabaseSeed(&Service{Name: "api", Database: "postgres:16", DatabaseSeed: "./db/seed.sql"}))
+	suite.NoError(validateDatabaseSeed(&Service{Name: "api", Image: "node:20"}))
+
+	suite.ErrorContains(validateDatabaseSeed(&Service{Name: "api", DatabaseSeed: "./db"}), "requires 'database'")
+	suite.ErrorContains(validateDatabaseSeed(&Service{Name: "api", Database: "postgres:16", DatabaseSeed: "./db",
+		DatabaseSnapshotImage: "registry/api-db:latest"}), "cannot be combined with 'database_snapshot_image'")
+	suite.ErrorContains(validateDatabaseSeed(&Service{Name: "my-api", Database: "postgres:16", DatabaseSeed: "./db"}),
+		"letters, digits and underscores (got my-api")
+}
+
+func (suite *DatabaseSeedTestSuite) TestSeedsSharedPostgres() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Database: "postgres:16", DatabaseSeed: "./db/seed.sql", DatabaseExtensions: []string{"pgvector"}},
+		{Name: "worker", Image: "node:20", Database: "postgres:16", DatabaseName: "jobs", DatabaseSeed: "db/jobs"},
+	}}
+	compose := generateDockerCompose(config)
+
+	postgres := compose.Services["postgres-16"]
+	suite.Contains(postgres.Volumes, "../db/seed.sql:/fleet-seed/api/seed.sql:ro")
+	suite.Contains(postgres.Volumes, "./postgres-16-seed-api.sh:/docker-entrypoint-initdb.d/seed-api.sh:ro")
+	suite.Contains(postgres.Volumes, "../db/jobs:/fleet-seed/worker/jobs:ro")
+	suite.Contains(postgres.Volumes, "./postgres-16-seed-worker.sh:/docker-entrypoint-initdb.d/seed-worker.sh:ro")
+
+	artifact, planned := compose.Artifacts.file(".fleet/postgres-16-seed-worker.sh")
+	suite.Require().True(planned)
+	script := string(artifact.Content)
+	suite.Contains(script, "CREATE DATABASE jobs OWNER worker", "The database of a service sharing the container is created")
+	suite.Contains(script, "find '/fleet-seed/worker' -mindepth 1 -maxdepth 2 -type f | sort")
+	suite.Contains(script, `*.sql) echo "fleet: seeding jobs from $f"; psql -v ON_ERROR_STOP=1 --username worker --no-password --dbname jobs -f "$f" ;;`)
+	suite.Contains(script, `*.sql.gz)`)
+	suite.assertValidShell(script)
+}
+
+func (suite *DatabaseSeedTestSuite) TestSeedScripts() {
+	mysql := generateDatabaseSeedScript(&Service{Name: "shop", Database: "mysql:8.0", DatabasePassword: "it's"}, "mysql")
+	suite.Contains(mysql, `MYSQL_PWD="$MYSQL_ROOT_PASSWORD" mysql -uroot -e 'CREATE DATABASE IF NOT EXISTS shop;`)
+	suite.Contains(mysql, `IDENTIFIED BY '\''it'\'''\''s'\''`)
+	suite.Contains(mysql, `mysql -uroot shop < "$f"`)
+	suite.assertValidShell(mysql)
+
+	mariadb := generateDatabaseSeedScript(&Service{Name: "shop", Database: "mariadb:11"}, "mariadb")
+	suite.Contains(mariadb, `MYSQL_PWD="$MARIADB_ROOT_PASSWORD" mariadb -uroot`)
+
+	mongo := generateDatabaseSeedScript(&Service{Name: "events", Database: "mongodb:7"}, "mongodb")
+	suite.Contains(mongo, `*.js) echo "fleet: seeding events from $f"; $(command -v mongosh || command -v mongo) --quiet`)
+	suite.NotContains(mongo, "*.sql)")
+	suite.assertValidShell(mongo)
+}
+
+func TestDatabaseSeedSuite(t *testing.T) {
+	suite.Run(t, new(DatabaseSeedTestSuite))
+}
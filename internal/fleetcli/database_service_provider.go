@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -13,18 +13,8 @@ type DatabaseServiceProvider struct {
 // NewDatabaseServiceProvider creates a new database service provider
 func NewDatabaseServiceProvider() *DatabaseServiceProvider {
 	return &DatabaseServiceProvider{
-		defaultVersions: map[string]string{
-			"mysql":    "8.0",
-			"postgres": "15",
-			"mongodb":  "7.0",
-			"mariadb":  "11.1",
-		},
-		supportedVersions: map[string][]string{
-			"mysql":    {"5.7", "8.0", "8.1", "8.2", "8.3"},
-			"postgres": {"13", "14", "15", "16"},
-			"mongodb":  {"5.0", "6.0", "7.0"},
-			"mariadb":  {"10.6", "10.11", "11.0", "11.1", "11.2"},
-		},
+		defaultVersions:   getDefaultVersions(versionData.Database),
+		supportedVersions: getVersionLists(versionData.Database),
 	}
 }
 
@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -16,44 +16,8 @@ type DatabaseConfig struct {
 	Port     int
 }
 
-// Supported database versions
-var supportedDatabaseVersions = map[string]map[string]string{
-	"mysql": {
-		"5.7":     "mysql:5.7",
-		"8.0":     "mysql:8.0",
-		"8.1":     "mysql:8.1",
-		"8.2":     "mysql:8.2",
-		"8.3":     "mysql:8.3",
-		"latest":  "mysql:latest",
-		"default": "mysql:8.0",
-	},
-	"postgres": {
-		"12":      "postgres:12-alpine",
-		"13":      "postgres:13-alpine",
-		"14":      "postgres:14-alpine",
-		"15":      "postgres:15-alpine",
-		"16":      "postgres:16-alpine",
-		"latest":  "postgres:alpine",
-		"default": "postgres:15-alpine",
-	},
-	"mongodb": {
-		"4.4":     "mongo:4.4",
-		"5.0":     "mongo:5.0",
-		"6.0":     "mongo:6.0",
-		"7.0":     "mongo:7.0",
-		"latest":  "mongo:latest",
-		"default": "mongo:6.0",
-	},
-	"mariadb": {
-		"10.6":    "mariadb:10.6",
-		"10.11":   "mariadb:10.11",
-		"11.0":    "mariadb:11.0",
-		"11.1":    "mariadb:11.1",
-		"11.2":    "mariadb:11.2",
-		"latest":  "mariadb:latest",
-		"default": "mariadb:10.11",
-	},
-}
+// Supported database versions, from config/versions.json
+var supportedDatabaseVersions = imageMaps(versionData.Database)
 
 // parseDatabaseType parses database type and version from a string like "mysql:8.0"
 func parseDatabaseType(dbString string) (dbType string, version string) {
@@ -130,6 +94,8 @@ func addDatabaseService(compose *DockerCompose, svc *Service, config *Config) {
 				compose.Services[svc.Name] = appService
 			}
 		}
+		addDatabaseSeed(compose, svc, dbType, dbServiceName)
+		addDatabaseSlowQueryLog(compose, config, dbType, dbServiceName)
 		return
 	}
 	
@@ -159,8 +125,17 @@ func addDatabaseService(compose *DockerCompose, svc *Service, config *Config) {
 		configureMariaDBService(&dbService, svc, dbServiceName)
 	}
 	
+	// Start from a prebuilt image with seeded data
+	applyDatabaseSnapshot(&dbService, svc, dbServiceName)
+	
 	// Add the service to compose
 	compose.Services[dbServiceName] = dbService
+
+	// Load the schema and fixtures of the service into a new database
+	addDatabaseSeed(compose, svc, dbType, dbServiceName)
+
+	// Log the slow queries of the services using it
+	addDatabaseSlowQueryLog(compose, config, dbType, dbServiceName)
 	
 	// Update app service to depend on database
 	if appService, ok := compose.Services[svc.Name]; ok {
@@ -380,16 +355,12 @@ func configurePostgresExtensions(service *DockerService, svc *Service, dbService
 	// Create initialization script for enabling extensions
 	initScript := generatePostgresInitScript(svc.DatabaseExtensions)
 	if initScript != "" {
-		// Mount initialization script
-		initScriptPath := fmt.Sprintf(".fleet/%s-init.sql", dbServiceName)
-		service.Volumes = append(service.Volumes, fmt.Sprintf("%s:/docker-entrypoint-initdb.d/init.sql:ro", initScriptPath))
-		
-		// Store the init script content to be written later
+		// Store the init script content to be planned and mounted with the generated files
 		if service.Labels == nil {
 			service.Labels = make(map[string]string)
 		}
 		service.Labels["fleet.postgres.init.script"] = initScript
-		service.Labels["fleet.postgres.init.path"] = initScriptPath
+		service.Labels["fleet.postgres.init.path"] = fmt.Sprintf("%s-init.sql", dbServiceName)
 	}
 }
 
@@ -0,0 +1,102 @@
+package fleetcli
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// getSnapshotVolumeName returns the data volume of a database started from a snapshot image.
+// The name depends on the image, so pointing at a different snapshot starts from fresh data
+// instead of reusing a volume seeded from the old one.
+func getSnapshotVolumeName(dbServiceName, image string) string {
+	sum := sha256.Sum256([]byte(image))
+	return fmt.Sprintf("%s-snapshot-%x-data", dbServiceName, sum[:4])
+}
+
+// validateDatabaseSnapshot checks the database_snapshot_image of a service
+func validateDatabaseSnapshot(svc *Service) error {
+	if svc.DatabaseSnapshotImage == "" {
+		return nil
+	}
+	if svc.Database == "" {
+		return fmt.Errorf("service %s: 'database_snapshot_image' requires 'database'", svc.Name)
+	}
+	if strings.ContainsAny(svc.DatabaseSnapshotImage, " \t") {
+		return fmt.Errorf("service %s: invalid database_snapshot_image %q", svc.Name, svc.DatabaseSnapshotImage)
+	}
+	return nil
+}
+
+// validateDatabaseSnapshots checks that services sharing a database agree on its snapshot image
+func validateDatabaseSnapshots(config *Config) error {
+	images := make(map[string]string)
+	owners := make(map[string]string)
+	for _, svc := range config.Services {
+		dbType, version := parseDatabaseType(svc.Database)
+		if dbType == "" {
+			continue
+		}
+
+		dbServiceName := getSharedDatabaseServiceName(dbType, version)
+		if owner, exists := owners[dbServiceName]; exists {
+			if images[dbServiceName] != svc.DatabaseSnapshotImage {
+				return fmt.Errorf("services %s and %s share %s but use different database_snapshot_image values", owner, svc.Name, dbServiceName)
+			}
+			continue
+		}
+		owners[dbServiceName] = svc.Name
+		images[dbServiceName] = svc.DatabaseSnapshotImage
+	}
+	return nil
+}
+
+// applyDatabaseSnapshot starts a database service from a prebuilt image with seeded data.
+// Docker copies the image's data directory into the volume when the volume is created,
+// so later changes persist across restarts until `fleet down -v` removes the volume.
+func applyDatabaseSnapshot(service *DockerService, svc *Service, dbServiceName string) {
+	if svc.DatabaseSnapshotImage == "" {
+		return
+	}
+
+	service.Image = svc.DatabaseSnapshotImage
+
+	dataVolume := fmt.Sprintf("%s-data:", dbServiceName)
+	for i, volume := range service.Volumes {
+		if strings.HasPrefix(volume, dataVolume) {
+			service.Volumes[i] = getSnapshotVolumeName(dbServiceName, svc.DatabaseSnapshotImage) + ":" + strings.TrimPrefix(volume, dataVolume)
+		}
+	}
+}
+
+// getDatabaseSnapshots returns the snapshot image of every database started from one
+func getDatabaseSnapshots(config *Config) map[string]string {
+	snapshots := make(map[string]string)
+	for _, svc := range config.Services {
+		if svc.DatabaseSnapshotImage == "" {
+			continue
+		}
+		dbType, version := parseDatabaseType(svc.Database)
+		snapshots[getSharedDatabaseServiceName(dbType, version)] = svc.DatabaseSnapshotImage
+	}
+	return snapshots
+}
+
+// printDatabaseSnapshots explains where snapshot databases get their data from
+func printDatabaseSnapshots(config *Config, removingVolumes bool) {
+	snapshots := getDatabaseSnapshots(config)
+	names := make([]string, 0, len(snapshots))
+	for name := range snapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if removingVolumes {
+			infof("   %s will be restored from %s on the next up\n", name, snapshots[name])
+		} else {
+			infof("🌱 %s is seeded from %s (fleet down -v resets it)\n", name, snapshots[name])
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package fleetcli
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DatabaseSnapshotTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *DatabaseSnapshotTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+
+	suite.originalDir, _ = os.Getwd()
+	suite.Require().NoError(os.Chdir(suite.helper.TempDir()))
+}
+
+func (suite *DatabaseSnapshotTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *DatabaseSnapshotTestSuite) TestValidateDatabaseSnapshot() {
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr string
+	}{
+		{"snapshot", Service{Name: "app", Database: "mysql:8.0", DatabaseSnapshotImage: "ghcr.io/org/db-seeded:latest"}, ""},
+		{"disabled", Service{Name: "app", Database: "mysql:8.0"}, ""},
+		{"no database", Service{Name: "app", Image: "nginx", DatabaseSnapshotImage: "ghcr.io/org/db-seeded"}, "requires 'database'"},
+		{"whitespace", Service{Name: "app", Database: "postgres", DatabaseSnapshotImage: "org/db seeded"}, "invalid database_snapshot_image"},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			err := validateDatabaseSnapshot(&tt.svc)
+			if tt.wantErr == "" {
+				suite.NoError(err)
+				return
+			}
+			suite.Error(err)
+			suite.Contains(err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func (suite *DatabaseSnapshotTestSuite) TestSharedDatabaseMustAgree() {
+	config := &Config{
+		Services: []Service{
+			{Name: "api", Image: "php:8.3-fpm", Database: "mysql:8.0", DatabaseSnapshotImage: "org/db-seeded:1"},
+			{Name: "admin", Image: "php:8.3-fpm", Database: "mysql:8.0", DatabaseSnapshotImage: "org/db-seeded:1"},
+		},
+	}
+	suite.NoError(validateDatabaseSnapshots(config))
+
+	config.Services[1].DatabaseSnapshotImage = ""
+	err := validateDatabaseSnapshots(config)
+	suite.Error(err)
+	suite.Contains(err.Error(), "mysql-80")
+}
+
+func (suite *DatabaseSnapshotTestSuite) TestSnapshotReplacesImageAndVolume() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "api", Image: "php:8.3-fpm", Database: "postgres:15", DatabaseSnapshotImage: "ghcr.io/org/db-seeded:latest"},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	db := compose.Services["postgres-15"]
+	volumeName := getSnapshotVolumeName("postgres-15", "ghcr.io/org/db-seeded:latest")
+	suite.Equal("ghcr.io/org/db-seeded:latest", db.Image)
+	suite.Contains(db.Volumes, volumeName+":/var/lib/postgresql/data")
+	suite.Contains(compose.Volumes, volumeName)
+	suite.NotContains(compose.Volumes, "postgres-15-data")
+
+	// The app still gets its connection details
+	suite.Equal("postgres-15", compose.Services["api"].Environment["DB_HOST"])
+}
+
+func (suite *DatabaseSnapshotTestSuite) TestSnapshotVolumeDependsOnImage() {
+	first := getSnapshotVolumeName("mysql-80", "org/db-seeded:1")
+	second := getSnapshotVolumeName("mysql-80", "org/db-seeded:2")
+
+	suite.NotEqual(first, second)
+	suite.Equal(first, getSnapshotVolumeName("mysql-80", "org/db-seeded:1"))
+	suite.True(strings.HasPrefix(first, "mysql-80-snapshot-"))
+	suite.True(strings.HasSuffix(first, "-data"))
+}
+
+func (suite *DatabaseSnapshotTestSuite) TestWithoutSnapshot() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "api", Image: "php:8.3-fpm", Database: "mysql:8.0"},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	suite.Equal("mysql:8.0", compose.Services["mysql-80"].Image)
+	suite.Contains(compose.Services["mysql-80"].Volumes, "mysql-80-data:/var/lib/mysql")
+	suite.Empty(getDatabaseSnapshots(config))
+}
+
+func TestDatabaseSnapshotSuite(t *testing.T) {
+	suite.Run(t, new(DatabaseSnapshotTestSuite))
+}
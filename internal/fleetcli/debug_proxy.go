@@ -0,0 +1,81 @@
+package fleetcli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The debug proxy is a mitmweb container between nginx and the services with
+// debug_proxy = true. It records every request they get, and its UI can inspect and
+// replay them.
+const (
+	debugProxyServiceName = "debug-proxy"
+	debugProxyImage       = "mitmproxy/mitmproxy:10.4.2"
+	debugProxyDomain      = "proxy.test"
+	debugProxyUIPort      = 8081
+	// debugProxyFirstPort is the port mitmweb listens on for the first proxied service,
+	// the next ones get the following ports
+	debugProxyFirstPort = 8090
+)
+
+// validateDebugProxy checks that the requests of a service go through the proxy
+func validateDebugProxy(svc *Service) error {
+	if !svc.DebugProxy {
+		return nil
+	}
+	if strings.HasPrefix(svc.Runtime, "php") {
+		return fmt.Errorf("service %s: 'debug_proxy' is not supported for PHP services, nginx talks FastCGI to them", svc.Name)
+	}
+	if getDomainForService(svc) == "" {
+		return fmt.Errorf("service %s: 'debug_proxy' requires a 'domain' or 'port'", svc.Name)
+	}
+	return nil
+}
+
+// getDebugProxyPorts returns the port mitmweb listens on for each proxied service
+func getDebugProxyPorts(config *Config) map[string]int {
+	ports := make(map[string]int)
+	if !shouldAddNginxProxy(config) {
+		return ports
+	}
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if svc.DebugProxy && getDomainForService(svc) != "" && !strings.HasPrefix(svc.Runtime, "php") {
+			ports[svc.Name] = debugProxyFirstPort + len(ports)
+		}
+	}
+	return ports
+}
+
+// hasDebugProxy checks if any service is served through the debug proxy
+func hasDebugProxy(config *Config) bool {
+	return len(getDebugProxyPorts(config)) > 0
+}
+
+// addDebugProxy adds the mitmweb container with one reverse proxy per service
+func addDebugProxy(compose *DockerCompose, config *Config) {
+	ports := getDebugProxyPorts(config)
+	if len(ports) == 0 {
+		return
+	}
+
+	command := []string{"mitmweb", "--web-host", "0.0.0.0", "--web-port", fmt.Sprint(debugProxyUIPort),
+		"--no-web-open-browser", "--set", "keep_host_header=true"}
+	var dependsOn []string
+	for _, svc := range config.Services {
+		port, exists := ports[svc.Name]
+		if !exists {
+			continue
+		}
+		command = append(command, "--mode", fmt.Sprintf("reverse:http://%s:%d@%d", svc.Name, getProxyTargetPort(&svc), port))
+		dependsOn = append(dependsOn, svc.Name)
+	}
+
+	compose.Services[debugProxyServiceName] = DockerService{
+		Image:      debugProxyImage,
+		Entrypoint: command,
+		Networks:   []string{"fleet-network"},
+		Restart:    "unless-stopped",
+		DependsOn:  dependsOn,
+	}
+}
@@ -0,0 +1,563 @@
+package fleetcli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fleet/fleet/internal/ui"
+)
+
+func handleDNS() {
+	if len(os.Args) < 3 {
+		printDNSUsage()
+		os.Exit(0)
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "setup":
+		handleDNSSetup()
+	case "start":
+		handleDNSStart()
+	case "stop":
+		handleDNSStop()
+	case "restart":
+		handleDNSRestart()
+	case "status":
+		handleDNSStatus()
+	case "test":
+		handleDNSTest()
+	case "logs":
+		handleDNSLogs()
+	case "remove":
+		handleDNSRemove()
+	case "help":
+		printDNSUsage()
+	default:
+		fmt.Printf("Unknown DNS command: %s\n\n", subcommand)
+		printDNSUsage()
+		os.Exit(1)
+	}
+}
+
+func printDNSUsage() {
+	fmt.Println("Fleet DNS - Local DNS service for .test domains")
+	fmt.Println("\nUsage: fleet dns <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  setup       Configure system hosts file for DNS")
+	fmt.Println("  start       Start the dnsmasq container (--port N, --hosts-only)")
+	fmt.Println("  stop        Stop the dnsmasq container")
+	fmt.Println("  restart     Restart the dnsmasq container")
+	fmt.Println("  status      Show DNS service status and queries by domain (--watch for a live view)")
+	fmt.Println("  test        Test DNS resolution")
+	fmt.Println("  logs        Show dnsmasq logs")
+	fmt.Println("  remove      Remove DNS configuration from hosts file")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet dns setup     # Configure hosts file")
+	fmt.Println("  fleet dns start     # Start DNS service")
+	fmt.Println("  fleet dns test      # Test DNS resolution")
+	fmt.Println("  fleet dns status --watch  # Show queries as they arrive")
+}
+
+func handleDNSSetup() {
+	infoln("🌐 Setting up Fleet DNS for .test domain...")
+
+	scriptPath := getScriptPath()
+	if scriptPath == "" {
+		log.Fatal("❌ Setup script not found")
+	}
+
+	var op PrivilegedOperation
+	if runtime.GOOS == "windows" {
+		// Use PowerShell on Windows
+		op = PrivilegedOperation{
+			Description: "DNS setup",
+			Command:     "powershell",
+			Args:        []string{"-ExecutionPolicy", "Bypass", "-File", scriptPath, "setup"},
+		}
+	} else {
+		// Use bash script on Unix-like systems
+		op = PrivilegedOperation{
+			Description: "DNS setup",
+			Command:     scriptPath,
+			Args:        []string{},
+		}
+	}
+
+	if err := RunWithPrivileges(op); err != nil {
+		log.Fatalf("❌ DNS setup failed: %v", err)
+	}
+
+	infoln("✅ DNS setup complete")
+}
+
+func handleDNSStart() {
+	fs := newCommandFlagSet("dns start", flag.ExitOnError)
+	port := fs.Int("port", 0, "Run dnsmasq on this port and forward .test queries to it")
+	hostsOnly := fs.Bool("hosts-only", false, "Don't run dnsmasq, rely on the hosts file")
+	
+	fs.Parse(os.Args[3:])
+
+	composeFile := filepath.Join("internal", "fleetcli", "templates", "compose", "docker-compose.dnsmasq.yml")
+	
+	// Check if compose file exists
+	if _, err := os.Stat(composeFile); os.IsNotExist(err) {
+		log.Fatal("❌ Docker compose file not found: ", composeFile)
+	}
+
+	candidates := []DNSCandidate{{Strategy: dnsStrategyHosts}}
+	if !*hostsOnly {
+		candidates = getDNSCandidates(*port, runtime.GOOS, isResolvedRunning())
+	}
+
+	for _, candidate := range candidates {
+		state := &DNSState{Strategy: candidate.Strategy, Port: candidate.Port}
+
+		if candidate.Strategy == dnsStrategyHosts {
+			if !*hostsOnly {
+				warnln("⚠️  Port 53 is in use and .test can't be forwarded to another port on this system")
+			}
+			infoln("📝 Using hosts file mode: 'fleet up' adds project domains to the hosts file")
+			if err := saveDNSState(state); err != nil {
+				warnf("⚠️  Warning: %v\n", err)
+			}
+			return
+		}
+
+		if candidate.Strategy == dnsStrategyAltPort {
+			warnf("⚠️  Port 53 is in use, running dnsmasq on port %d instead\n", candidate.Port)
+		}
+
+		// The compose template publishes ${FLEET_DNS_PORT:-53}
+		os.Setenv("FLEET_DNS_PORT", strconv.Itoa(candidate.Port))
+		args := []string{"compose", "-f", composeFile, "up", "-d"}
+		err := newProgress().Run("Starting dnsmasq container", func(step *ui.Step) error {
+			return runDockerWithOutput(args, step.Writer(), step.Writer())
+		})
+		if err != nil {
+			if candidate.Strategy == dnsStrategyPort53 {
+				// Check if port 53 is in use
+				checkPort53()
+			}
+			warnf("⚠️  Failed to start dnsmasq on port %d: %v\n", candidate.Port, err)
+			continue
+		}
+
+		if candidate.Strategy == dnsStrategyAltPort {
+			path, content, _ := getForwardRule(runtime.GOOS, candidate.Port, isResolvedRunning())
+			infof("🔀 Forwarding .test queries to port %d with %s\n", candidate.Port, path)
+			if err := installForwardRule(path, content); err != nil {
+				warnf("⚠️  Failed to install forward rule: %v\n", err)
+				runDocker([]string{"compose", "-f", composeFile, "down"})
+				continue
+			}
+			state.ForwardRule = path
+		}
+
+		state.ContainerIP = getDNSContainerIP()
+		if err := saveDNSState(state); err != nil {
+			warnf("⚠️  Warning: %v\n", err)
+		}
+
+		infoln("✅ Dnsmasq started")
+		infoln("\nTest DNS resolution with:")
+		infoln("  fleet dns test")
+		return
+	}
+}
+
+func handleDNSStop() {
+	infoln("🛑 Stopping dnsmasq container...")
+
+	composeFile := filepath.Join("internal", "fleetcli", "templates", "compose", "docker-compose.dnsmasq.yml")
+	
+	args := []string{"compose", "-f", composeFile, "down"}
+	
+	if err := runDocker(args); err != nil {
+		log.Fatalf("❌ Error stopping DNS service: %v", err)
+	}
+
+	// .test queries would be forwarded to a port nothing listens on
+	if state, err := loadDNSState(); err == nil && state.ForwardRule != "" {
+		if err := removeForwardRule(state.ForwardRule); err != nil {
+			warnf("⚠️  Warning: failed to remove %s: %v\n", state.ForwardRule, err)
+		}
+	}
+	clearDNSState()
+
+	infoln("✅ Dnsmasq stopped")
+}
+
+func handleDNSRestart() {
+	infoln("🔄 Restarting dnsmasq container...")
+
+	composeFile := filepath.Join("internal", "fleetcli", "templates", "compose", "docker-compose.dnsmasq.yml")
+	
+	args := []string{"compose", "-f", composeFile, "restart"}
+	
+	if err := runDocker(args); err != nil {
+		log.Fatalf("❌ Error restarting DNS service: %v", err)
+	}
+
+	infoln("✅ Dnsmasq restarted")
+}
+
+func handleDNSStatus() {
+	fs := newCommandFlagSet("dns status", flag.ExitOnError)
+	watch := fs.Bool("watch", false, "Show queries live until interrupted")
+	tail := fs.Int("tail", dnsStatusTail, "Number of log lines to analyze")
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+
+	fs.Parse(os.Args[3:])
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	infoln("📊 DNS Service Status")
+	infoln("====================")
+
+	state, err := loadDNSState()
+	if err != nil {
+		warnf("⚠️  Warning: %v\n", err)
+		state = &DNSState{Strategy: dnsStrategyPort53, Port: 53}
+	}
+	outputf("Strategy: %s\n", describeDNSStrategy(state))
+	if state.ContainerIP != "" {
+		outputf("Container IP: %s\n", state.ContainerIP)
+	}
+	outputln()
+
+	if state.Strategy == dnsStrategyHosts {
+		infoln("ℹ️  dnsmasq isn't used, domains resolve through the hosts file")
+		infoln("   Run 'fleet hosts list' to check them")
+		return
+	}
+
+	// Check if container is running
+	args := []string{"ps", "--filter", "name=dnsmasq", "--format", "table {{.Names}}\t{{.Status}}\t{{.Ports}}"}
+	
+	cmd := newCommand("docker", args...)
+	output, err := cmd.CombinedOutput()
+	
+	if err != nil {
+		outputln("❌ DNS service is not running")
+		infoln("   Run 'fleet dns start' to start the service")
+		return
+	}
+
+	outputStr := string(output)
+	if !strings.Contains(outputStr, "dnsmasq") {
+		outputln("❌ DNS service is not running")
+		infoln("   Run 'fleet dns start' to start the service")
+		return
+	}
+
+	outputln("✅ DNS service is running")
+	outputln()
+	outputln(outputStr)
+
+	projectDomains := loadProjectDomains(*configFile)
+	if *watch {
+		watchDNSStatus(projectDomains)
+		return
+	}
+
+	// Show recent queries
+	logsArgs := []string{"logs", "dnsmasq", "--tail", strconv.Itoa(*tail)}
+	logsCmd := newCommand("docker", logsArgs...)
+	logsOutput, _ := logsCmd.CombinedOutput()
+	
+	stats := newDNSQueryStats()
+	var recent []string
+	scanner := bufio.NewScanner(strings.NewReader(string(logsOutput)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if entry, ok := parseDNSLogLine(line); ok {
+			stats.add(entry)
+			if entry.isQuery() {
+				recent = append(recent, line)
+			}
+		}
+	}
+	if len(recent) > 5 {
+		recent = recent[len(recent)-5:]
+	}
+
+	outputln("\nRecent DNS queries (last 5):")
+	for _, line := range recent {
+		outputf("  %s\n", line)
+	}
+	if len(recent) == 0 {
+		outputln("  No recent queries")
+		return
+	}
+
+	outputf("\nQueries by domain (last %d log lines):\n", *tail)
+	printDNSQueryStats(os.Stdout, stats, projectDomains)
+}
+
+// watchDNSStatus prints the queries dnsmasq answers until Fleet is interrupted, then the
+// hit count of every domain
+func watchDNSStatus(projectDomains []string) {
+	infoln("👀 Watching DNS queries (Ctrl-C to stop)")
+	outputf("%-8s  %-5s %-40s %-16s %s\n", "TIME", "TYPE", "DOMAIN", "CLIENT", "ANSWER")
+
+	// dnsmasq logs to stderr, docker logs keeps the streams apart
+	reader, writer := io.Pipe()
+	cmd := newCommand("docker", "logs", "--follow", "--tail", "0", "dnsmasq")
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	if err := cmd.Start(); err != nil {
+		log.Fatalf("❌ Error watching dnsmasq logs: %v", err)
+	}
+	go func() {
+		cmd.Wait()
+		writer.Close()
+	}()
+
+	stats := watchDNSQueries(reader, os.Stdout, projectDomains, time.Now)
+
+	outputln("\nQueries by domain:")
+	printDNSQueryStats(os.Stdout, stats, projectDomains)
+}
+
+func handleDNSTest() {
+	infoln("🧪 Testing DNS configuration...")
+	infoln("================================")
+
+	state, err := loadDNSState()
+	if err != nil {
+		state = &DNSState{Strategy: dnsStrategyPort53, Port: 53}
+	}
+	if state.Strategy == dnsStrategyHosts {
+		infoln("ℹ️  DNS runs in hosts file mode, run 'fleet hosts list' to check domains")
+		return
+	}
+
+	// Check if container is running
+	args := []string{"ps", "-q", "--filter", "name=dnsmasq"}
+	cmd := newCommand("docker", args...)
+	output, err := cmd.CombinedOutput()
+	
+	if err != nil || len(output) == 0 {
+		infoln("❌ DNS service is not running")
+		infoln("   Run 'fleet dns start' to start the service")
+		return
+	}
+
+	infoln("✅ DNS service is running")
+	infoln()
+
+	// Test domains
+	testDomains := []string{"test.test", "app.test", "api.test", "dnsmasq.test"}
+	
+	outputln("Testing .test domain resolution:")
+	outputln("---------------------------------")
+	
+	allPassed := true
+	for _, domain := range testDomains {
+		outputf("%-20s ", domain)
+		
+		// Try nslookup first
+		result := testDNSResolutionOnPort(domain, state.Port)
+		if result {
+			outputln("✅ Resolved")
+		} else {
+			outputln("❌ Failed")
+			allPassed = false
+		}
+	}
+
+	outputln()
+	if allPassed {
+		outputln("✅ All DNS tests passed!")
+	} else {
+		outputln("⚠️  Some DNS tests failed")
+		infoln("\nTroubleshooting:")
+		infoln("1. Ensure the DNS service is running: fleet dns start")
+		infoln("2. Check if port 53 is available")
+		infoln("3. Verify hosts file configuration: fleet dns setup")
+	}
+}
+
+func handleDNSLogs() {
+	fs := newCommandFlagSet("dns logs", flag.ExitOnError)
+	follow := fs.Bool("f", false, "Follow logs")
+	followLong := fs.Bool("follow", false, "Follow logs")
+	tail := fs.String("tail", "50", "Number of lines to show")
+	
+	// Parse remaining args after "dns logs"
+	fs.Parse(os.Args[3:])
+	
+	if *followLong {
+		*follow = true
+	}
+
+	infoln("📋 Dnsmasq logs:")
+	infoln("================")
+
+	args := []string{"logs", "dnsmasq", "--tail", *tail}
+	
+	if *follow {
+		args = append(args, "-f")
+	}
+
+	cmd := newCommand("docker", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	
+	if err := cmd.Run(); err != nil {
+		log.Fatalf("❌ Error viewing logs: %v", err)
+	}
+}
+
+func handleDNSRemove() {
+	infoln("🗑️  Removing Fleet DNS configuration...")
+
+	scriptPath := getScriptPath()
+	if scriptPath == "" {
+		log.Fatal("❌ Setup script not found")
+	}
+
+	var op PrivilegedOperation
+	if runtime.GOOS == "windows" {
+		// Use PowerShell on Windows
+		op = PrivilegedOperation{
+			Description: "DNS removal",
+			Command:     "powershell",
+			Args:        []string{"-ExecutionPolicy", "Bypass", "-File", scriptPath, "remove"},
+		}
+	} else {
+		// Use bash script on Unix-like systems
+		op = PrivilegedOperation{
+			Description: "DNS removal",
+			Command:     scriptPath,
+			Args:        []string{"remove"},
+		}
+	}
+
+	if err := RunWithPrivileges(op); err != nil {
+		log.Fatalf("❌ DNS removal failed: %v", err)
+	}
+
+	infoln("✅ DNS configuration removed")
+}
+
+// Helper functions
+
+func getScriptPath() string {
+	// Determine the script name based on OS
+	var scriptName string
+	if runtime.GOOS == "windows" {
+		scriptName = "setup-dns.ps1"
+	} else {
+		scriptName = "setup-dns.sh"
+	}
+
+	// Try relative path first (from CLI directory)
+	scriptPath := filepath.Join("..", "scripts", scriptName)
+	if _, err := os.Stat(scriptPath); err == nil {
+		return scriptPath
+	}
+
+	// Try from project root
+	scriptPath = filepath.Join("scripts", scriptName)
+	if _, err := os.Stat(scriptPath); err == nil {
+		return scriptPath
+	}
+
+	// Try from the root of a Fleet checkout
+	scriptPath = filepath.Join("internal", "fleetcli", "scripts", scriptName)
+	if _, err := os.Stat(scriptPath); err == nil {
+		return scriptPath
+	}
+
+	// Try absolute path based on executable location
+	exePath, err := os.Executable()
+	if err == nil {
+		exeDir := filepath.Dir(exePath)
+		scriptPath = filepath.Join(exeDir, "..", "internal", "fleetcli", "scripts", scriptName)
+		if _, err := os.Stat(scriptPath); err == nil {
+			return scriptPath
+		}
+	}
+
+	return ""
+}
+
+func testDNSResolution(domain string) bool {
+	return testDNSResolutionOnPort(domain, 53)
+}
+
+// testDNSResolutionOnPort queries the local DNS service on the given port
+func testDNSResolutionOnPort(domain string, port int) bool {
+	// Try nslookup
+	cmd := newCommand("nslookup", fmt.Sprintf("-port=%d", port), domain, "127.0.0.1")
+	cmd.Env = append(os.Environ(), "LANG=C") // Ensure consistent output
+	output, err := cmd.CombinedOutput()
+	
+	if err == nil && strings.Contains(string(output), "127.0.0.1") {
+		return true
+	}
+
+	// Fallback to dig if nslookup is not available
+	cmd = newCommand("dig", "-p", strconv.Itoa(port), "@127.0.0.1", domain, "+short")
+	output, err = cmd.CombinedOutput()
+	
+	if err == nil && strings.TrimSpace(string(output)) != "" {
+		return true
+	}
+
+	// Fallback to host command
+	cmd = newCommand("host", "-p", strconv.Itoa(port), domain, "127.0.0.1")
+	output, err = cmd.CombinedOutput()
+	
+	if err == nil && strings.Contains(string(output), "has address") {
+		return true
+	}
+
+	return false
+}
+
+func checkPort53() {
+	// Check if port 53 is in use
+	var cmd *exec.Cmd
+	
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = newCommand("lsof", "-i", ":53")
+	case "linux":
+		cmd = newCommand("ss", "-lnu", "sport", "=", ":53")
+	case "windows":
+		cmd = newCommand("netstat", "-an")
+	default:
+		return
+	}
+
+	output, _ := cmd.CombinedOutput()
+	outputStr := string(output)
+
+	if strings.Contains(outputStr, ":53") || strings.Contains(outputStr, "53 ") {
+		warnln("\n⚠️  Port 53 may already be in use")
+		warnln("   Another DNS service might be running")
+		
+		if runtime.GOOS == "darwin" {
+			warnln("   On macOS, try: sudo dscacheutil -flushcache")
+		} else if runtime.GOOS == "linux" {
+			warnln("   Check for systemd-resolved or dnsmasq")
+		}
+	}
+}
\ No newline at end of file
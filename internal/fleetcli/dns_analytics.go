@@ -0,0 +1,195 @@
+package fleetcli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// dnsStatusTail is how many dnsmasq log lines fleet dns status analyzes by default
+const dnsStatusTail = 500
+
+var (
+	// dnsQueryPattern matches a query logged by dnsmasq with log-queries:
+	// "dnsmasq[1]: query[A] shop.test from 172.30.0.1"
+	dnsQueryPattern = regexp.MustCompile(`query\[(\w+)\] (\S+) from (\S+)`)
+	// dnsAnswerPattern matches the answer to a query, from the config, the cache, a hosts
+	// file or upstream: "dnsmasq[1]: config shop.test is 127.0.0.1"
+	dnsAnswerPattern = regexp.MustCompile(`: (?:reply|config|cached|/\S+) (\S+) is (\S+)`)
+)
+
+// dnsNXDomain is how dnsmasq logs a domain that doesn't exist
+const dnsNXDomain = "NXDOMAIN"
+
+// DNSLogEntry is a query or an answer logged by dnsmasq
+type DNSLogEntry struct {
+	Domain string
+	// Type and Client are set for queries
+	Type   string
+	Client string
+	// Answer is set for answers: an address, NXDOMAIN or NODATA
+	Answer string
+}
+
+// isQuery reports whether the entry is a query rather than an answer
+func (e DNSLogEntry) isQuery() bool {
+	return e.Type != ""
+}
+
+// parseDNSLogLine parses a dnsmasq log line. Lines that aren't queries or answers, like
+// forwarding and startup messages, return false.
+func parseDNSLogLine(line string) (DNSLogEntry, bool) {
+	if match := dnsQueryPattern.FindStringSubmatch(line); match != nil {
+		return DNSLogEntry{Type: match[1], Domain: strings.ToLower(match[2]), Client: match[3]}, true
+	}
+	if match := dnsAnswerPattern.FindStringSubmatch(line); match != nil {
+		return DNSLogEntry{Domain: strings.ToLower(match[1]), Answer: match[2]}, true
+	}
+	return DNSLogEntry{}, false
+}
+
+// DNSQueryStats counts the queries dnsmasq answered
+type DNSQueryStats struct {
+	Queries  int
+	Hits     map[string]int
+	NXDomain map[string]int
+}
+
+// newDNSQueryStats returns empty query statistics
+func newDNSQueryStats() *DNSQueryStats {
+	return &DNSQueryStats{Hits: make(map[string]int), NXDomain: make(map[string]int)}
+}
+
+// add counts a log entry
+func (s *DNSQueryStats) add(entry DNSLogEntry) {
+	if entry.isQuery() {
+		s.Queries++
+		s.Hits[entry.Domain]++
+		return
+	}
+	if entry.Answer == dnsNXDomain {
+		s.NXDomain[entry.Domain]++
+	}
+}
+
+// loadProjectDomains returns the domains of the project in the current directory, or nil
+// outside a project
+func loadProjectDomains(configFile string) []string {
+	if _, err := os.Stat(configFile); err != nil {
+		return nil
+	}
+	config, err := loadConfig(configFile)
+	if err != nil {
+		return nil
+	}
+	return getProjectDomains(config)
+}
+
+// isProjectDomain reports whether a domain belongs to the project. Outside a project,
+// every .test domain is one Fleet should resolve.
+func isProjectDomain(domain string, projectDomains []string) bool {
+	if len(projectDomains) == 0 {
+		return strings.HasSuffix(domain, ".test")
+	}
+	for _, projectDomain := range projectDomains {
+		if domain == projectDomain || strings.HasSuffix(domain, "."+projectDomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// getFailedProjectDomains returns the project domains that got NXDOMAIN, sorted
+func getFailedProjectDomains(stats *DNSQueryStats, projectDomains []string) []string {
+	var failed []string
+	for domain := range stats.NXDomain {
+		if isProjectDomain(domain, projectDomains) {
+			failed = append(failed, domain)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// printDNSQueryStats prints the hit count of every queried domain and warns about project
+// domains dnsmasq couldn't resolve
+func printDNSQueryStats(out io.Writer, stats *DNSQueryStats, projectDomains []string) {
+	if stats.Queries == 0 {
+		fmt.Fprintln(out, "  No recent queries")
+		return
+	}
+
+	domains := make([]string, 0, len(stats.Hits))
+	for domain := range stats.Hits {
+		domains = append(domains, domain)
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		if stats.Hits[domains[i]] != stats.Hits[domains[j]] {
+			return stats.Hits[domains[i]] > stats.Hits[domains[j]]
+		}
+		return domains[i] < domains[j]
+	})
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  DOMAIN\tHITS\tNXDOMAIN")
+	for _, domain := range domains {
+		nxdomain := ""
+		if count := stats.NXDomain[domain]; count > 0 {
+			nxdomain = fmt.Sprintf("%d", count)
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%s\n", domain, stats.Hits[domain], nxdomain)
+	}
+	w.Flush()
+
+	printFailedProjectDomains(out, getFailedProjectDomains(stats, projectDomains))
+}
+
+// printFailedProjectDomains warns about project domains that got NXDOMAIN
+func printFailedProjectDomains(out io.Writer, failed []string) {
+	if len(failed) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n%s Project domains that didn't resolve: %s\n", emojiOr("⚠️ ", "!!"), strings.Join(failed, ", "))
+	fmt.Fprintln(out, "   dnsmasq or the hosts file isn't set up for them. Run 'fleet dns setup'")
+}
+
+// watchDNSQueries prints queries as dnsmasq answers them and returns the statistics
+// when the log ends
+func watchDNSQueries(logs io.Reader, out io.Writer, projectDomains []string, now func() time.Time) *DNSQueryStats {
+	stats := newDNSQueryStats()
+	pending := make(map[string]DNSLogEntry)
+	warned := make(map[string]bool)
+
+	scanner := bufio.NewScanner(logs)
+	for scanner.Scan() {
+		entry, ok := parseDNSLogLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		stats.add(entry)
+
+		if entry.isQuery() {
+			pending[entry.Domain] = entry
+			continue
+		}
+		// Print a query with its first answer, upstream replies can have several
+		query, exists := pending[entry.Domain]
+		if !exists {
+			continue
+		}
+		delete(pending, entry.Domain)
+		fmt.Fprintf(out, "%s  %-5s %-40s %-16s %s\n", now().Format("15:04:05"), query.Type, query.Domain, query.Client, entry.Answer)
+
+		if entry.Answer == dnsNXDomain && isProjectDomain(entry.Domain, projectDomains) && !warned[entry.Domain] {
+			warned[entry.Domain] = true
+			warnf("%s %s is a project domain but didn't resolve, run 'fleet dns setup'\n", emojiOr("⚠️ ", "!!"), entry.Domain)
+		}
+	}
+	return stats
+}
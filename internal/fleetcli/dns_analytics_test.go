@@ -0,0 +1,100 @@
+package fleetcli
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DNSAnalyticsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *DNSAnalyticsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *DNSAnalyticsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+// sampleDNSLog is dnsmasq output with log-queries, as docker logs prints it
+const sampleDNSLog = `dnsmasq[1]: started, version 2.90 cachesize 1000
+dnsmasq[1]: query[A] shop.test from 172.30.0.1
+dnsmasq[1]: config shop.test is 127.0.0.1
+dnsmasq[1]: query[AAAA] shop.test from 172.30.0.1
+dnsmasq[1]: config shop.test is NODATA-IPv6
+dnsmasq[1]: query[A] github.com from 172.30.0.1
+dnsmasq[1]: forwarded github.com to 8.8.8.8
+dnsmasq[1]: reply github.com is 140.82.121.4
+dnsmasq[1]: reply github.com is 140.82.121.3
+dnsmasq[1]: query[A] API.Shop.Local from 172.30.0.1
+dnsmasq[1]: forwarded api.shop.local to 8.8.8.8
+dnsmasq[1]: reply api.shop.local is NXDOMAIN
+dnsmasq[1]: query[A] typo.example from 172.30.0.1
+dnsmasq[1]: reply typo.example is NXDOMAIN
+`
+
+func (suite *DNSAnalyticsTestSuite) TestParseDNSLogLine() {
+	entry, ok := parseDNSLogLine("Jan  2 10:00:00 dnsmasq[1]: query[AAAA] Shop.test from 172.30.0.1")
+	suite.True(ok)
+	suite.Equal(DNSLogEntry{Type: "AAAA", Domain: "shop.test", Client: "172.30.0.1"}, entry)
+
+	entry, ok = parseDNSLogLine("dnsmasq[1]: /etc/hosts.test api.test is 127.0.0.1")
+	suite.True(ok)
+	suite.Equal(DNSLogEntry{Domain: "api.test", Answer: "127.0.0.1"}, entry)
+
+	_, ok = parseDNSLogLine("dnsmasq[1]: forwarded github.com to 8.8.8.8")
+	suite.False(ok)
+}
+
+func (suite *DNSAnalyticsTestSuite) TestWatchDNSQueries() {
+	var out strings.Builder
+	now := func() time.Time { return time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC) }
+	stats := watchDNSQueries(strings.NewReader(sampleDNSLog), &out, []string{"shop.test", "shop.local"}, now)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	suite.Len(lines, 5, "Each query is printed once, with its first answer")
+	suite.Contains(lines[2], "github.com")
+	suite.Contains(lines[2], "140.82.121.4")
+
+	suite.Equal(5, stats.Queries)
+	suite.Equal(2, stats.Hits["shop.test"])
+	suite.Equal([]string{"api.shop.local"}, getFailedProjectDomains(stats, []string{"shop.test", "shop.local"}),
+		"Subdomains of project domains count, other domains don't")
+}
+
+func (suite *DNSAnalyticsTestSuite) TestPrintDNSQueryStats() {
+	stats := newDNSQueryStats()
+	for _, line := range strings.Split(sampleDNSLog, "\n") {
+		if entry, ok := parseDNSLogLine(line); ok {
+			stats.add(entry)
+		}
+	}
+
+	var out strings.Builder
+	printDNSQueryStats(&out, stats, nil)
+	output := out.String()
+	suite.Regexp(`shop\.test\s+2\s*\n`, output, "The busiest domain comes first")
+	suite.Less(strings.Index(output, "shop.test"), strings.Index(output, "github.com"))
+	suite.NotContains(output, "didn't resolve", "Outside a project only .test domains are checked")
+
+	out.Reset()
+	printDNSQueryStats(&out, stats, []string{"shop.local"})
+	suite.Contains(out.String(), "Project domains that didn't resolve: api.shop.local")
+	suite.Contains(out.String(), "fleet dns setup")
+}
+
+func TestDNSAnalyticsSuite(t *testing.T) {
+	suite.Run(t, new(DNSAnalyticsTestSuite))
+}
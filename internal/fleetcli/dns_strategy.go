@@ -0,0 +1,204 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// DNS strategies, from most to least integrated
+const (
+	// dnsStrategyPort53 runs dnsmasq on the standard DNS port
+	dnsStrategyPort53 = "port53"
+	// dnsStrategyAltPort runs dnsmasq on another port and forwards .test to it
+	dnsStrategyAltPort = "alt-port"
+	// dnsStrategyHosts relies on the hosts file entries written by fleet up
+	dnsStrategyHosts = "hosts"
+)
+
+// defaultDNSAltPort is used for dnsmasq when port 53 is taken, e.g. by systemd-resolved
+const defaultDNSAltPort = 5300
+
+// dnsContainerName is the container_name of the dnsmasq service
+const dnsContainerName = "dnsmasq"
+
+// DNSState records how the DNS service was started, so status and stop don't have to guess
+type DNSState struct {
+	Strategy    string `json:"strategy"`
+	Port        int    `json:"port,omitempty"`
+	ContainerIP string `json:"container_ip,omitempty"`
+	ForwardRule string `json:"forward_rule,omitempty"`
+}
+
+// DNSCandidate is a strategy to try when starting the DNS service
+type DNSCandidate struct {
+	Strategy string
+	Port     int
+}
+
+// getDNSStatePath returns where the DNS state is cached
+var getDNSStatePath = func() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".fleet", "dns.json")
+}
+
+// isPortInUse checks if a local UDP port is already bound. A permission error means
+// the port is free but privileged, which is fine since Docker binds it.
+var isPortInUse = func(port int) bool {
+	conn, err := net.ListenPacket("udp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err == nil {
+		conn.Close()
+		return false
+	}
+	return errors.Is(err, syscall.EADDRINUSE)
+}
+
+// isResolvedRunning checks if systemd-resolved manages DNS on this machine
+var isResolvedRunning = func() bool {
+	_, err := os.Stat("/run/systemd/resolve")
+	return err == nil
+}
+
+// loadDNSState reads the cached DNS state. Without one, DNS is assumed on port 53.
+func loadDNSState() (*DNSState, error) {
+	data, err := os.ReadFile(getDNSStatePath())
+	if os.IsNotExist(err) {
+		return &DNSState{Strategy: dnsStrategyPort53, Port: 53}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DNS state: %w", err)
+	}
+
+	var state DNSState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse DNS state: %w", err)
+	}
+	return &state, nil
+}
+
+// saveDNSState caches the DNS state
+func saveDNSState(state *DNSState) error {
+	path := getDNSStatePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// clearDNSState removes the cached DNS state
+func clearDNSState() {
+	os.Remove(getDNSStatePath())
+}
+
+// getForwardRule returns the file that makes the OS resolver send .test queries to
+// dnsmasq on a non-standard port. ok is false when the OS has no such mechanism.
+func getForwardRule(goos string, port int, resolvedRunning bool) (path, content string, ok bool) {
+	switch goos {
+	case "darwin":
+		return "/etc/resolver/test", fmt.Sprintf("# Added by Fleet CLI\nnameserver 127.0.0.1\nport %d\n", port), true
+	case "linux":
+		if resolvedRunning {
+			return "/etc/systemd/resolved.conf.d/fleet-dns.conf", fmt.Sprintf("# Added by Fleet CLI\n[Resolve]\nDNS=127.0.0.1:%d\nDomains=~test\n", port), true
+		}
+	}
+	return "", "", false
+}
+
+// getDNSCandidates returns the strategies to try, in order. Hosts file mode always works,
+// so it is the last resort.
+func getDNSCandidates(requestedPort int, goos string, resolvedRunning bool) []DNSCandidate {
+	var candidates []DNSCandidate
+	if (requestedPort == 0 || requestedPort == 53) && !isPortInUse(53) {
+		candidates = append(candidates, DNSCandidate{Strategy: dnsStrategyPort53, Port: 53})
+	}
+
+	if requestedPort != 53 {
+		altPort := requestedPort
+		if altPort == 0 {
+			altPort = defaultDNSAltPort
+		}
+		if _, _, ok := getForwardRule(goos, altPort, resolvedRunning); ok && !isPortInUse(altPort) {
+			candidates = append(candidates, DNSCandidate{Strategy: dnsStrategyAltPort, Port: altPort})
+		}
+	}
+
+	return append(candidates, DNSCandidate{Strategy: dnsStrategyHosts})
+}
+
+// describeDNSStrategy returns a one line description of how .test domains resolve
+func describeDNSStrategy(state *DNSState) string {
+	switch state.Strategy {
+	case dnsStrategyAltPort:
+		return fmt.Sprintf("dnsmasq on port %d, forwarded by %s", state.Port, state.ForwardRule)
+	case dnsStrategyHosts:
+		return "hosts file (dnsmasq couldn't use port 53 or forward to another port)"
+	default:
+		return "dnsmasq on port 53"
+	}
+}
+
+// installForwardRule writes a forward rule and reloads the resolver
+func installForwardRule(path, content string) error {
+	if err := RunWithPrivileges(PrivilegedOperation{
+		Description: fmt.Sprintf("Creating %s", filepath.Dir(path)),
+		Command:     "mkdir",
+		Args:        []string{"-p", filepath.Dir(path)},
+	}); err != nil {
+		return err
+	}
+	if err := WriteFileWithPrivileges(path, []byte(content), 0644); err != nil {
+		return err
+	}
+	return reloadResolver()
+}
+
+// removeForwardRule removes a forward rule and reloads the resolver
+func removeForwardRule(path string) error {
+	if err := RunWithPrivileges(PrivilegedOperation{
+		Description: fmt.Sprintf("Removing %s", path),
+		Command:     "rm",
+		Args:        []string{"-f", path},
+	}); err != nil {
+		return err
+	}
+	return reloadResolver()
+}
+
+// reloadResolver makes the OS resolver pick up forward rule changes
+func reloadResolver() error {
+	if runtime.GOOS != "linux" {
+		// macOS watches /etc/resolver
+		return nil
+	}
+	return RunWithPrivileges(PrivilegedOperation{
+		Description: "Restarting systemd-resolved",
+		Command:     "systemctl",
+		Args:        []string{"restart", "systemd-resolved"},
+	})
+}
+
+// getDNSContainerIP returns the address of the dnsmasq container on the Fleet network
+func getDNSContainerIP() string {
+	output, err := newCommand("docker", "inspect", "-f", "{{range .NetworkSettings.Networks}}{{.IPAddress}} {{end}}", dnsContainerName).Output()
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,137 @@
+package fleetcli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DNSStrategyTestSuite struct {
+	suite.Suite
+	helper          *TestHelper
+	originalStateFn func() string
+	originalPortFn  func(int) bool
+	portsInUse      map[int]bool
+}
+
+func (suite *DNSStrategyTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+
+	suite.originalStateFn = getDNSStatePath
+	statePath := filepath.Join(suite.helper.TempDir(), ".fleet", "dns.json")
+	getDNSStatePath = func() string { return statePath }
+
+	suite.originalPortFn = isPortInUse
+	suite.portsInUse = map[int]bool{}
+	isPortInUse = func(port int) bool { return suite.portsInUse[port] }
+}
+
+func (suite *DNSStrategyTestSuite) TearDownTest() {
+	getDNSStatePath = suite.originalStateFn
+	isPortInUse = suite.originalPortFn
+	suite.helper.Cleanup()
+}
+
+func (suite *DNSStrategyTestSuite) TestGetForwardRule() {
+	path, content, ok := getForwardRule("darwin", 5300, false)
+	suite.True(ok)
+	suite.Equal("/etc/resolver/test", path)
+	suite.Contains(content, "port 5300")
+
+	path, content, ok = getForwardRule("linux", 5300, true)
+	suite.True(ok)
+	suite.Equal("/etc/systemd/resolved.conf.d/fleet-dns.conf", path)
+	suite.Contains(content, "DNS=127.0.0.1:5300")
+	suite.Contains(content, "Domains=~test")
+
+	_, _, ok = getForwardRule("linux", 5300, false)
+	suite.False(ok)
+
+	_, _, ok = getForwardRule("windows", 5300, false)
+	suite.False(ok)
+}
+
+func (suite *DNSStrategyTestSuite) TestCandidates() {
+	tests := []struct {
+		name      string
+		requested int
+		goos      string
+		resolved  bool
+		inUse     []int
+		expected  []DNSCandidate
+	}{
+		{
+			name:     "port 53 free",
+			goos:     "linux",
+			resolved: true,
+			expected: []DNSCandidate{{dnsStrategyPort53, 53}, {dnsStrategyAltPort, defaultDNSAltPort}, {Strategy: dnsStrategyHosts}},
+		},
+		{
+			name:     "port 53 taken by systemd-resolved",
+			goos:     "linux",
+			resolved: true,
+			inUse:    []int{53},
+			expected: []DNSCandidate{{dnsStrategyAltPort, defaultDNSAltPort}, {Strategy: dnsStrategyHosts}},
+		},
+		{
+			name:     "port 53 taken without a forward mechanism",
+			goos:     "windows",
+			inUse:    []int{53},
+			expected: []DNSCandidate{{Strategy: dnsStrategyHosts}},
+		},
+		{
+			name:      "requested port",
+			requested: 5454,
+			goos:      "darwin",
+			inUse:     []int{53},
+			expected:  []DNSCandidate{{dnsStrategyAltPort, 5454}, {Strategy: dnsStrategyHosts}},
+		},
+		{
+			name:      "requested port taken",
+			requested: 5454,
+			goos:      "darwin",
+			inUse:     []int{53, 5454},
+			expected:  []DNSCandidate{{Strategy: dnsStrategyHosts}},
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.portsInUse = map[int]bool{}
+			for _, port := range tt.inUse {
+				suite.portsInUse[port] = true
+			}
+			suite.Equal(tt.expected, getDNSCandidates(tt.requested, tt.goos, tt.resolved))
+		})
+	}
+}
+
+func (suite *DNSStrategyTestSuite) TestStateRoundTrip() {
+	state, err := loadDNSState()
+	suite.Require().NoError(err)
+	suite.Equal(dnsStrategyPort53, state.Strategy)
+	suite.Equal(53, state.Port)
+
+	saved := &DNSState{Strategy: dnsStrategyAltPort, Port: 5300, ContainerIP: "172.30.0.2", ForwardRule: "/etc/resolver/test"}
+	suite.Require().NoError(saveDNSState(saved))
+
+	state, err = loadDNSState()
+	suite.Require().NoError(err)
+	suite.Equal(saved, state)
+
+	clearDNSState()
+	state, _ = loadDNSState()
+	suite.Equal(dnsStrategyPort53, state.Strategy)
+}
+
+func (suite *DNSStrategyTestSuite) TestDescribeDNSStrategy() {
+	suite.Equal("dnsmasq on port 53", describeDNSStrategy(&DNSState{Strategy: dnsStrategyPort53, Port: 53}))
+	suite.Equal("dnsmasq on port 5300, forwarded by /etc/resolver/test",
+		describeDNSStrategy(&DNSState{Strategy: dnsStrategyAltPort, Port: 5300, ForwardRule: "/etc/resolver/test"}))
+	suite.Contains(describeDNSStrategy(&DNSState{Strategy: dnsStrategyHosts}), "hosts file")
+}
+
+func TestDNSStrategySuite(t *testing.T) {
+	suite.Run(t, new(DNSStrategyTestSuite))
+}
@@ -0,0 +1,195 @@
+//go:build integration
+
+package fleetcli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// dockerIntegrationTimeout is how long the stack may take to become healthy, including
+// image pulls and builds. FLEET_INTEGRATION_TIMEOUT overrides it.
+const dockerIntegrationTimeout = 5 * time.Minute
+
+// DockerIntegrationTestSuite runs a generated compose file against a real Docker daemon.
+// It brings up a Laravel + MySQL + Redis project once and checks it from the inside, so
+// no host ports are published and nothing outside the test project is touched.
+//
+// Run with: go test -v -tags integration -run TestDockerIntegrationSuite -timeout 20m ./internal/fleetcli
+type DockerIntegrationTestSuite struct {
+	suite.Suite
+	helper       *TestHelper
+	originalDir  string
+	project      string
+	composeFiles ComposeFiles
+	compose      *DockerCompose
+}
+
+// sampleLaravelApp is the smallest app the Laravel detection and nginx config accept
+var sampleLaravelApp = map[string]string{
+	"app/artisan":          "#!/usr/bin/env php\n<?php\n",
+	"app/composer.json":    `{"require": {"php": "^8.2", "laravel/framework": "^11.0"}}`,
+	"app/public/index.php": "<?php\necho 'fleet-integration-ok';\n",
+}
+
+func (suite *DockerIntegrationTestSuite) SetupSuite() {
+	if output, err := newCommand("docker", "info", "--format", "{{.ServerVersion}}").CombinedOutput(); err != nil {
+		suite.T().Skipf("Docker is not available: %v\n%s", err, output)
+	}
+
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	suite.Require().NoError(os.Chdir(suite.helper.TempDir()))
+
+	for path, content := range sampleLaravelApp {
+		suite.helper.CreateFile(path, content)
+	}
+
+	// A project of its own keeps containers and volumes apart from real Fleet projects
+	suite.project = fmt.Sprintf("fleet-it-%d", time.Now().Unix())
+	suite.T().Setenv("COMPOSE_PROJECT_NAME", suite.project)
+
+	config := &Config{
+		Project: suite.project,
+		Services: []Service{
+			{
+				Name:      "shop",
+				Image:     "nginx:alpine",
+				Runtime:   "php:8.3",
+				Framework: "laravel",
+				Folder:    "./app",
+				Domain:    "shop.test",
+				Database:  "mysql:8.0",
+				Cache:     "redis:7.2",
+			},
+		},
+	}
+	suite.Require().NoError(Validate(config))
+
+	suite.Require().NoError(os.MkdirAll(".fleet", 0755))
+	suite.compose = generateDockerCompose(config)
+	for name, service := range suite.compose.Services {
+		// Checks run inside the containers, so the ports of a running Fleet project don't matter
+		service.Ports = nil
+		suite.compose.Services[name] = service
+	}
+	composeFiles, err := writeComposeFiles(config, suite.compose)
+	suite.Require().NoError(err)
+	suite.composeFiles = composeFiles
+
+	suite.Require().NoError(suite.run("up", "-d", "--build"))
+
+	timeout := dockerIntegrationTimeout
+	if value := os.Getenv("FLEET_INTEGRATION_TIMEOUT"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		suite.Require().NoError(err)
+		timeout = parsed
+	}
+	for name := range suite.compose.Services {
+		if err := waitForServiceReady(suite.composeFiles, name, timeout); err != nil {
+			suite.dumpLogs()
+			suite.Require().NoError(err)
+		}
+	}
+}
+
+func (suite *DockerIntegrationTestSuite) TearDownSuite() {
+	if len(suite.composeFiles.Files) > 0 {
+		if err := suite.run("down", "--volumes", "--remove-orphans"); err != nil {
+			suite.T().Logf("Failed to remove the test project: %v", err)
+		}
+	}
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	if suite.helper != nil {
+		suite.helper.Cleanup()
+	}
+}
+
+// run runs a compose command against the generated files
+func (suite *DockerIntegrationTestSuite) run(args ...string) error {
+	_, err := suite.output(args...)
+	return err
+}
+
+// output runs a compose command against the generated files and returns its output
+func (suite *DockerIntegrationTestSuite) output(args ...string) (string, error) {
+	cmd, err := dockerCommand(composeArgs(suite.composeFiles, args...)...)
+	if err != nil {
+		return "", err
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("compose %s: %w\n%s", strings.Join(args, " "), err, output)
+	}
+	return string(output), nil
+}
+
+// exec runs a shell command in a service and returns its output
+func (suite *DockerIntegrationTestSuite) exec(service, command string) (string, error) {
+	output, err := suite.output("exec", "-T", service, "sh", "-c", command)
+	return strings.TrimSpace(output), err
+}
+
+// serviceWithImage returns the compose service running an image, like the shared MySQL
+func (suite *DockerIntegrationTestSuite) serviceWithImage(prefix string) string {
+	for name, service := range suite.compose.Services {
+		if strings.HasPrefix(service.Image, prefix) {
+			return name
+		}
+	}
+	suite.FailNow("no service runs " + prefix)
+	return ""
+}
+
+// dumpLogs logs the recent output of every container, to see why the stack failed
+func (suite *DockerIntegrationTestSuite) dumpLogs() {
+	output, _ := suite.output("logs", "--tail", "50")
+	suite.T().Log(output)
+}
+
+func (suite *DockerIntegrationTestSuite) TestHealthChecksPass() {
+	entries, err := getComposeServiceStates(suite.composeFiles)
+	suite.Require().NoError(err)
+	suite.Len(entries, len(suite.compose.Services))
+
+	for _, entry := range entries {
+		suite.True(isServiceReady(entry), "%s is %s (%s)", entry.Service, entry.State, entry.Health)
+		if suite.compose.Services[entry.Service].HealthCheck != nil {
+			suite.Equal("healthy", entry.Health, entry.Service)
+		}
+	}
+}
+
+func (suite *DockerIntegrationTestSuite) TestDomainRoutesThroughProxy() {
+	body, err := suite.exec("nginx-proxy", "wget -qO- --header 'Host: shop.test' http://127.0.0.1/")
+	suite.Require().NoError(err, body)
+	suite.Equal("fleet-integration-ok", body, "nginx-proxy -> shop -> shop-php serves public/index.php")
+}
+
+func (suite *DockerIntegrationTestSuite) TestServiceEnvironmentIsInjected() {
+	mysql := suite.serviceWithImage("mysql")
+	redis := suite.serviceWithImage("redis")
+
+	for variable, expected := range map[string]string{"DB_HOST": mysql, "REDIS_HOST": redis} {
+		value, err := suite.exec("shop", "printenv "+variable)
+		suite.Require().NoError(err, value)
+		suite.Equal(expected, value, variable)
+	}
+
+	// The injected hosts and ports reach the shared services
+	for _, check := range []string{`nc -z "$DB_HOST" "$DB_PORT"`, `nc -z "$REDIS_HOST" "$REDIS_PORT"`} {
+		output, err := suite.exec("shop", check)
+		suite.NoError(err, output)
+	}
+}
+
+func TestDockerIntegrationSuite(t *testing.T) {
+	suite.Run(t, new(DockerIntegrationTestSuite))
+}
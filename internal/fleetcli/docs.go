@@ -0,0 +1,318 @@
+package fleetcli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// mermaidIDPattern matches the characters Mermaid doesn't accept in node ids
+var mermaidIDPattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// escapeMarkdownCell escapes a value for a Markdown table cell
+func escapeMarkdownCell(value string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(value)
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getServiceURLs returns the URLs the proxy serves each service on
+func getServiceURLs(config *Config, configFile string) map[string][]string {
+	urls := make(map[string][]string)
+	for _, route := range getRoutes(config, configFile) {
+		scheme := "http"
+		if route.SSL != "-" {
+			scheme = "https"
+		}
+		for _, domain := range route.Domains {
+			urls[route.Service] = append(urls[route.Service], fmt.Sprintf("%s://%s%s", scheme, domain, route.Path))
+		}
+	}
+	return urls
+}
+
+// getServiceRuntime describes what a service runs, like php:8.3 on nginx:alpine
+func getServiceRuntime(svc *Service) string {
+	switch {
+	case svc.Runtime != "" && svc.Image != "":
+		return fmt.Sprintf("%s on %s", svc.Runtime, svc.Image)
+	case svc.Runtime != "":
+		return svc.Runtime
+	case svc.Image != "":
+		return svc.Image
+	case svc.Mock != "":
+		return "mock of " + svc.Mock
+	case svc.ExternalService != "":
+		return "external " + svc.ExternalService
+	}
+	return "-"
+}
+
+// getServiceDependencies returns the containers the containers of a service depend on,
+// without the service's own sidecar
+func getServiceDependencies(compose *DockerCompose, svc *Service) []string {
+	own := []string{svc.Name, getAppServiceName(svc)}
+	var dependencies []string
+	for _, name := range own {
+		for _, dependency := range compose.Services[name].DependsOn {
+			if !containsString(own, dependency) && !containsString(dependencies, dependency) {
+				dependencies = append(dependencies, dependency)
+			}
+		}
+	}
+	sort.Strings(dependencies)
+	return dependencies
+}
+
+// writeDocsServices writes the table of the services of the config
+func writeDocsServices(b *bytes.Buffer, config *Config, compose *DockerCompose, urls map[string][]string) {
+	b.WriteString("## Services\n\n")
+	b.WriteString("| Service | Runs | URL | Ports | Depends on |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for i := range config.Services {
+		svc := &config.Services[i]
+		url, ports, dependsOn := "-", "-", "-"
+		if len(urls[svc.Name]) > 0 {
+			url = strings.Join(urls[svc.Name], ", ")
+		}
+		if len(svc.Ports) > 0 {
+			ports = strings.Join(svc.Ports, ", ")
+		}
+		if deps := getServiceDependencies(compose, svc); len(deps) > 0 {
+			dependsOn = strings.Join(deps, ", ")
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s | %s |\n", svc.Name, escapeMarkdownCell(getServiceRuntime(svc)),
+			escapeMarkdownCell(url), escapeMarkdownCell(ports), dependsOn)
+	}
+
+	// Databases, caches, sidecars and the proxy
+	var support []string
+	for _, name := range sortedKeys(compose.Services) {
+		if findService(config, name) == nil {
+			support = append(support, name)
+		}
+	}
+	if len(support) == 0 {
+		return
+	}
+	b.WriteString("\nFleet adds these containers for them:\n\n")
+	b.WriteString("| Container | Image |\n")
+	b.WriteString("| --- | --- |\n")
+	for _, name := range support {
+		image := compose.Services[name].Image
+		if image == "" {
+			image = "built locally"
+		}
+		fmt.Fprintf(b, "| %s | %s |\n", name, escapeMarkdownCell(image))
+	}
+}
+
+// writeDocsEnvironment writes the variables each container of a service gets. Credentials
+// are left out, the docs point to the file that has them.
+func writeDocsEnvironment(b *bytes.Buffer, config *Config, compose *DockerCompose) {
+	var names []string
+	for i := range config.Services {
+		for _, name := range []string{config.Services[i].Name, getAppServiceName(&config.Services[i])} {
+			service, exists := compose.Services[name]
+			if exists && !containsString(names, name) && (len(service.Environment) > 0 || len(service.EnvFile) > 0) {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	b.WriteString("\n## Environment\n\n")
+	b.WriteString("Variables Fleet injects into each container. Credentials are not copied here, ")
+	b.WriteString("`fleet up` writes them to `.fleet/docker-compose.yml` or, with `secrets = \"env_file\"`, to `.fleet/env/`.\n")
+	for _, name := range names {
+		service := compose.Services[name]
+		fmt.Fprintf(b, "\n### %s\n\n", name)
+		if len(service.Environment) > 0 {
+			b.WriteString("| Variable | Value |\n")
+			b.WriteString("| --- | --- |\n")
+			for _, key := range sortedKeys(service.Environment) {
+				value := fmt.Sprintf("`%s`", escapeMarkdownCell(service.Environment[key]))
+				if isSecretEnvVar(key, service.Environment[key]) {
+					value = "_secret_"
+				}
+				fmt.Fprintf(b, "| %s | %s |\n", key, value)
+			}
+		}
+		for _, envFile := range service.EnvFile {
+			fmt.Fprintf(b, "\nSecrets are in `.fleet/%s`.\n", strings.TrimPrefix(envFile, "./"))
+		}
+	}
+}
+
+// writeDocsDependencies writes the dependency graph of the containers as a Mermaid chart
+func writeDocsDependencies(b *bytes.Buffer, compose *DockerCompose) {
+	var edges []string
+	for _, name := range sortedKeys(compose.Services) {
+		var dependsOn []string
+		for _, dependency := range compose.Services[name].DependsOn {
+			if !containsString(dependsOn, dependency) {
+				dependsOn = append(dependsOn, dependency)
+			}
+		}
+		sort.Strings(dependsOn)
+		for _, dependency := range dependsOn {
+			edges = append(edges, fmt.Sprintf("    %s[\"%s\"] --> %s[\"%s\"]",
+				mermaidIDPattern.ReplaceAllString(name, "_"), name, mermaidIDPattern.ReplaceAllString(dependency, "_"), dependency))
+		}
+	}
+	if len(edges) == 0 {
+		return
+	}
+
+	b.WriteString("\n## Dependencies\n\n")
+	b.WriteString("Each container starts after the ones it points to.\n\n")
+	b.WriteString("```mermaid\ngraph LR\n")
+	b.WriteString(strings.Join(edges, "\n"))
+	b.WriteString("\n```\n")
+}
+
+// writeDocsCommands writes the fleet commands that apply to the stack
+func writeDocsCommands(b *bytes.Buffer, config *Config, configFile string) {
+	file := ""
+	if configFile != "fleet.toml" {
+		file = " --file " + configFile
+	}
+
+	commands := [][2]string{
+		{"fleet up -d" + file, "Start the stack in the background"},
+		{"fleet status" + file, "Show the containers and their health"},
+	}
+	if len(config.Services) > 0 {
+		commands = append(commands, [2]string{fmt.Sprintf("fleet logs%s -f %s", file, config.Services[0].Name), "Follow the logs of a service"})
+	}
+
+	var hasPHP, hasNode, hasCache bool
+	var database *Service
+	for i := range config.Services {
+		svc := &config.Services[i]
+		hasPHP = hasPHP || strings.HasPrefix(svc.Runtime, "php")
+		hasNode = hasNode || strings.HasPrefix(svc.Runtime, "node")
+		hasCache = hasCache || svc.Cache != ""
+		if database == nil && svc.Database != "" {
+			database = svc
+		}
+	}
+	if hasPHP {
+		commands = append(commands, [2]string{"fleet php composer install", "Run Composer in the PHP service of the current folder"})
+	}
+	if hasNode {
+		commands = append(commands, [2]string{"fleet node npm install", "Run npm in the Node.js service of the current folder"})
+	}
+	if database != nil {
+		commands = append(commands, [2]string{fmt.Sprintf("fleet db clone%s %s %s_copy", file, database.Name, mermaidIDPattern.ReplaceAllString(database.Name, "_")), "Copy a database to experiment on"})
+	}
+	if hasCache {
+		commands = append(commands, [2]string{"fleet cache flush" + file, "Flush the caches"})
+	}
+	commands = append(commands,
+		[2]string{"fleet route" + file, "Show which container each domain reaches"},
+		[2]string{"fleet down" + file, "Stop the stack"},
+	)
+
+	width := 0
+	for _, command := range commands {
+		width = max(width, len(command[0]))
+	}
+	b.WriteString("\n## Common Commands\n\n```bash\n")
+	for _, command := range commands {
+		fmt.Fprintf(b, "%-*s  # %s\n", width, command[0], command[1])
+	}
+	b.WriteString("```\n")
+}
+
+// generateStackDocs returns a Markdown document describing the stack of a config
+func generateStackDocs(config *Config, compose *DockerCompose, configFile string) string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", config.Project)
+	fmt.Fprintf(&b, "<!-- Generated by 'fleet docs' from %s, run it again after changing the config -->\n\n", configFile)
+
+	writeDocsServices(&b, config, compose, getServiceURLs(config, configFile))
+	writeDocsEnvironment(&b, config, compose)
+	writeDocsDependencies(&b, compose)
+	writeDocsCommands(&b, config, configFile)
+	return b.String()
+}
+
+func printDocsUsage() {
+	fmt.Println("Fleet docs - Describe the stack in Markdown")
+	fmt.Println("\nUsage: fleet docs [options]")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -o, --output  Write the document to a file instead of stdout")
+	fmt.Println("  --check       Fail if the output file doesn't match the config, e.g. in CI")
+	fmt.Println("  -f, --file    Specify config file (default: fleet.toml)")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet docs -o STACK.md          # Write the onboarding docs of the project")
+	fmt.Println("  fleet docs -o STACK.md --check  # Check they are up to date")
+}
+
+func handleDocs() {
+	if len(os.Args) > 2 && os.Args[2] == "help" {
+		printDocsUsage()
+		return
+	}
+
+	fs := newCommandFlagSet("docs", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	output := fs.String("o", "", "Output file")
+	outputLong := fs.String("output", "", "Output file")
+	check := fs.Bool("check", false, "Fail if the output file is out of date")
+	fs.Usage = printDocsUsage
+
+	fs.Parse(os.Args[2:])
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+	if *outputLong != "" {
+		*output = *outputLong
+	}
+	if *check && *output == "" {
+		log.Fatalf("❌ --check needs the file to compare with, use -o <file>")
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	docs := generateStackDocs(config, generateDockerCompose(config), *configFile)
+
+	if *output == "" {
+		fmt.Print(docs)
+		return
+	}
+
+	if *check {
+		current, err := os.ReadFile(*output)
+		if err != nil || string(current) != docs {
+			log.Fatalf("❌ %s is out of date, run 'fleet docs -o %s'", *output, *output)
+		}
+		infof("✅ %s is up to date\n", *output)
+		return
+	}
+
+	if err := os.WriteFile(*output, []byte(docs), 0644); err != nil {
+		log.Fatalf("❌ Error writing %s: %v", *output, err)
+	}
+	infof("📝 Wrote the docs of %s to %s\n", config.Project, *output)
+}
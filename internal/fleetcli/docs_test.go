@@ -0,0 +1,73 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DocsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *DocsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *DocsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *DocsTestSuite) TestGenerateStackDocs() {
+	suite.Require().NoError(os.MkdirAll("shop", 0755))
+	config := &Config{Project: "demo", Services: []Service{
+		{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "shop", Domain: "shop.test", Database: "mysql:8.0", Cache: "redis:7"},
+		{Name: "api", Image: "node:20", Port: 3000, Domain: "api.test", Needs: []string{"shop"}, Environment: map[string]string{"STRIPE_KEY": "sk_test_123", "LOG_LEVEL": "debug"}},
+	}}
+
+	docs := generateStackDocs(config, generateDockerCompose(config), "fleet.toml")
+
+	suite.Contains(docs, "# demo\n")
+	suite.Contains(docs, "| shop | php:8.3 on nginx:alpine | http://shop.test | - | mysql-80, redis-7 |")
+	suite.Contains(docs, "| api | node:20 | http://api.test | - | shop |")
+	suite.Contains(docs, "| mysql-80 | mysql:8.0 |")
+
+	suite.Contains(docs, "| DB_HOST | `mysql-80` |")
+	suite.Contains(docs, "| LOG_LEVEL | `debug` |")
+	suite.Contains(docs, "| STRIPE_KEY | _secret_ |")
+	suite.Contains(docs, "| DATABASE_URL | _secret_ |", "URLs with a password are credentials")
+	suite.NotContains(docs, "sk_test_123")
+
+	suite.Contains(docs, "```mermaid\ngraph LR\n")
+	suite.Contains(docs, `    api["api"] --> shop["shop"]`)
+	suite.Contains(docs, `    shop["shop"] --> mysql_80["mysql-80"]`)
+
+	suite.Contains(docs, "fleet php composer install")
+	suite.Contains(docs, "fleet db clone shop shop_copy")
+	suite.NotContains(docs, "fleet node", "No Node.js runtime")
+
+	suite.Equal(docs, generateStackDocs(config, generateDockerCompose(config), "fleet.toml"), "The output is stable, so --check works")
+}
+
+func (suite *DocsTestSuite) TestCustomConfigFile() {
+	config := &Config{Project: "demo", Services: []Service{{Name: "web", Image: "nginx:alpine"}}}
+
+	docs := generateStackDocs(config, generateDockerCompose(config), "stack.yml")
+
+	suite.Contains(docs, "from stack.yml")
+	suite.Contains(docs, "fleet up -d --file stack.yml")
+	suite.Contains(docs, "fleet logs --file stack.yml -f web")
+	suite.NotContains(docs, "## Dependencies")
+}
+
+func TestDocsSuite(t *testing.T) {
+	suite.Run(t, new(DocsTestSuite))
+}
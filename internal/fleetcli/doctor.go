@@ -0,0 +1,427 @@
+package fleetcli
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Statuses of doctor checks, fleet doctor fails on any doctorFail
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// sslRenewWindow is how long before expiry certificates are renewed by fleet up
+const sslRenewWindow = 30 * 24 * time.Hour
+
+// DoctorCheck is the result of one check of fleet doctor
+type DoctorCheck struct {
+	Name   string
+	Status string
+	Detail string
+	Fix    string
+}
+
+// isTCPPortInUse checks if a local TCP port is already bound on an address, "" being
+// every interface. A permission error means the port is free but privileged, which is
+// fine since Docker binds it.
+var isTCPPortInUse = func(address string, port int) bool {
+	listener, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(port)))
+	if err == nil {
+		listener.Close()
+		return false
+	}
+	return errors.Is(err, syscall.EADDRINUSE)
+}
+
+// isDNSContainerRunning checks if the dnsmasq container of fleet dns is running
+var isDNSContainerRunning = func() bool {
+	output, err := newCommand("docker", "ps", "-q", "--filter", "name="+dnsContainerName).CombinedOutput()
+	return err == nil && len(strings.TrimSpace(string(output))) > 0
+}
+
+// parseHostPort returns the host address and port of a port mapping, like
+// 127.0.0.1:8080:80. It returns false for ports Docker picks itself and ranges.
+func parseHostPort(mapping string) (address string, port int, ok bool) {
+	mapping = strings.Split(mapping, "/")[0]
+	// IPv6 addresses are bracketed, like [::1]:6379:6379
+	if strings.HasPrefix(mapping, "[") {
+		end := strings.Index(mapping, "]:")
+		if end < 0 {
+			return "", 0, false
+		}
+		address, mapping = mapping[1:end], mapping[end+2:]
+	}
+
+	parts := strings.Split(mapping, ":")
+	if len(parts) < 2 {
+		return "", 0, false
+	}
+	port, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil {
+		return "", 0, false
+	}
+	if len(parts) == 3 {
+		address = parts[0]
+	}
+	return address, port, true
+}
+
+// checkPortConflicts finds host ports of the stack that another process already binds.
+// Services in running are skipped, the ports they bind are their own.
+func checkPortConflicts(compose *DockerCompose, running map[string]bool) DoctorCheck {
+	check := DoctorCheck{Name: "Ports"}
+	var conflicts []string
+	published := 0
+	for _, name := range sortedKeys(compose.Services) {
+		if running[name] {
+			continue
+		}
+		for _, mapping := range compose.Services[name].Ports {
+			// Only TCP ports are checked, like the QUIC port of http3
+			if strings.HasSuffix(mapping, "/udp") {
+				continue
+			}
+			address, port, ok := parseHostPort(mapping)
+			if !ok {
+				continue
+			}
+			published++
+			if isTCPPortInUse(address, port) {
+				conflicts = append(conflicts, fmt.Sprintf("%d (%s)", port, name))
+			}
+		}
+	}
+
+	if len(conflicts) == 0 {
+		check.Status = doctorOK
+		check.Detail = fmt.Sprintf("%d published ports are free", published)
+		return check
+	}
+	check.Status = doctorFail
+	check.Detail = "in use by another process: " + strings.Join(conflicts, ", ")
+	check.Fix = "Stop the process using the port, or change the port of the service in the config"
+	return check
+}
+
+// checkHostsEntries checks that the domains of the project resolve through the hosts
+// file. Missing entries only matter when the DNS service doesn't resolve them.
+func checkHostsEntries(config *Config, content string, dnsRunning bool) DoctorCheck {
+	check := DoctorCheck{Name: "Hosts file"}
+	domains := getProjectDomains(config)
+
+	if conflicts := findHostsConflicts(content, domains); len(conflicts) > 0 {
+		var lines []string
+		for _, conflict := range conflicts {
+			lines = append(lines, fmt.Sprintf("%s -> %s (line %d)", conflict.Domain, conflict.IP, conflict.LineNumber))
+		}
+		check.Status = doctorFail
+		check.Detail = "entries override Fleet domains: " + strings.Join(lines, ", ")
+		check.Fix = fmt.Sprintf("Remove these entries from %s", getHostsFilePath())
+		return check
+	}
+
+	mapped := make(map[string]bool)
+	for _, entry := range parseHostsEntries(content) {
+		if !entry.InFleetSection {
+			continue
+		}
+		for _, hostname := range entry.Hostnames {
+			mapped[hostname] = true
+		}
+	}
+	var missing []string
+	for _, domain := range domains {
+		if !mapped[domain] {
+			missing = append(missing, domain)
+		}
+	}
+
+	switch {
+	case len(missing) == 0:
+		check.Status = doctorOK
+		check.Detail = fmt.Sprintf("%d domains mapped", len(domains))
+	case dnsRunning:
+		check.Status = doctorOK
+		check.Detail = fmt.Sprintf("%d domains resolved by the DNS service", len(missing))
+	default:
+		check.Status = doctorWarn
+		check.Detail = "not mapped: " + strings.Join(missing, ", ")
+		check.Fix = "Run 'fleet hosts add', or 'fleet dns start' to resolve .test domains"
+	}
+	return check
+}
+
+// checkDNSContainer checks that the dnsmasq container runs, unless DNS was set up in
+// hosts file mode
+func checkDNSContainer() DoctorCheck {
+	check := DoctorCheck{Name: "DNS"}
+	state, err := loadDNSState()
+	if err != nil {
+		check.Status = doctorWarn
+		check.Detail = err.Error()
+		check.Fix = fmt.Sprintf("Delete %s and run 'fleet dns start'", getDNSStatePath())
+		return check
+	}
+	if state.Strategy == dnsStrategyHosts {
+		check.Status = doctorOK
+		check.Detail = "hosts file mode, no container needed"
+		return check
+	}
+	if !isDNSContainerRunning() {
+		check.Status = doctorWarn
+		check.Detail = "the dnsmasq container isn't running"
+		check.Fix = "Run 'fleet dns start'"
+		return check
+	}
+	check.Status = doctorOK
+	check.Detail = "dnsmasq is running (" + describeDNSStrategy(state) + ")"
+	return check
+}
+
+// checkSSLCertificates checks the expiry of the certificates in sslDir
+func checkSSLCertificates(sslDir string) DoctorCheck {
+	check := DoctorCheck{Name: "SSL certificates", Status: doctorOK}
+	certs, _ := filepath.Glob(filepath.Join(sslDir, "*.crt"))
+	sort.Strings(certs)
+
+	var expired, expiring, invalid []string
+	for _, path := range certs {
+		name := filepath.Base(path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			invalid = append(invalid, name)
+			continue
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			invalid = append(invalid, name)
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			invalid = append(invalid, name)
+			continue
+		}
+		switch remaining := time.Until(cert.NotAfter); {
+		case remaining <= 0:
+			expired = append(expired, fmt.Sprintf("%s (%s)", name, cert.NotAfter.Format("2006-01-02")))
+		case remaining < sslRenewWindow:
+			expiring = append(expiring, fmt.Sprintf("%s (%s)", name, cert.NotAfter.Format("2006-01-02")))
+		}
+	}
+
+	var problems []string
+	if len(expired) > 0 {
+		problems = append(problems, "expired: "+strings.Join(expired, ", "))
+	}
+	if len(invalid) > 0 {
+		problems = append(problems, "unreadable: "+strings.Join(invalid, ", "))
+	}
+	if len(expiring) > 0 {
+		problems = append(problems, "expiring: "+strings.Join(expiring, ", "))
+	}
+
+	switch {
+	case len(certs) == 0:
+		check.Detail = "no certificates generated"
+	case len(problems) == 0:
+		check.Detail = fmt.Sprintf("%d certificates valid", len(certs))
+	default:
+		check.Status = doctorWarn
+		if len(expired) > 0 || len(invalid) > 0 {
+			check.Status = doctorFail
+		}
+		check.Detail = strings.Join(problems, "; ")
+		check.Fix = "Run 'fleet up' to generate new certificates"
+	}
+	return check
+}
+
+// checkStaleArtifacts finds generated files in .fleet that don't match the configs of
+// the project: compose files older than their config, a running stack whose config is
+// gone and the artifact sets of configs that were removed
+func checkStaleArtifacts(configFile string, config *Config) DoctorCheck {
+	check := DoctorCheck{Name: "Generated files", Status: doctorOK}
+	var problems, fixes []string
+
+	composeFile := filepath.Join(getComposeOutputDir(config), composeFileName)
+	if config.ComposeLayers {
+		composeFile = filepath.Join(getComposeOutputDir(config), getComposeLayerFileName(composeLayerBase))
+	}
+	if composeInfo, err := os.Stat(composeFile); err == nil {
+		if configInfo, err := os.Stat(configFile); err == nil && configInfo.ModTime().After(composeInfo.ModTime()) {
+			problems = append(problems, fmt.Sprintf("%s changed since %s was generated", configFile, composeFile))
+			fixes = append(fixes, "Run 'fleet up -d' to apply the config")
+		}
+	}
+
+	if running, err := loadRunningConfig(); err == nil && running != nil {
+		if _, err := os.Stat(running.Config); err != nil {
+			problems = append(problems, fmt.Sprintf("%s records a stack of %s, which no longer exists", runningConfigFile, running.Config))
+			fixes = append(fixes, fmt.Sprintf("Run 'docker compose -p %s down' and delete %s", composeProjectName, runningConfigFile))
+		}
+	}
+
+	sets := make(map[string]bool)
+	for _, ext := range []string{".toml", ".yaml", ".yml", ".json"} {
+		files, _ := filepath.Glob("*" + ext)
+		for _, file := range files {
+			sets[getArtifactSetName(file)] = true
+		}
+	}
+	dirs, _ := filepath.Glob(filepath.Join(defaultComposeOutputDir, "*", composeFileName))
+	for _, path := range dirs {
+		dir := filepath.Dir(path)
+		if sets[filepath.Base(dir)] || filepath.Clean(dir) == filepath.Clean(getComposeOutputDir(config)) {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%s belongs to no config of the project", dir))
+		fixes = append(fixes, fmt.Sprintf("Delete %s", dir))
+	}
+
+	if len(problems) == 0 {
+		check.Detail = "up to date"
+		return check
+	}
+	check.Status = doctorWarn
+	check.Detail = strings.Join(problems, "; ")
+	check.Fix = strings.Join(fixes, ", then ")
+	return check
+}
+
+// checkProject runs the checks of the project in the current directory
+func checkProject(configFile string, config *Config) []DoctorCheck {
+	compose := generateDockerCompose(config)
+
+	// The ports of running services are bound by the stack itself
+	running := make(map[string]bool)
+	if states, err := getComposeServiceStates(getComposeFiles(config)); err == nil {
+		for _, state := range states {
+			if state.State == "running" {
+				running[state.Service] = true
+			}
+		}
+	}
+
+	checks := []DoctorCheck{checkPortConflicts(compose, running)}
+
+	dnsCheck := checkDNSContainer()
+	if len(getProjectDomains(config)) > 0 && shouldAddNginxProxy(config) && !isUnprivileged(config.Unprivileged) {
+		if content, err := os.ReadFile(getHostsFilePath()); err != nil {
+			checks = append(checks, DoctorCheck{Name: "Hosts file", Status: doctorWarn, Detail: err.Error()})
+		} else {
+			checks = append(checks, checkHostsEntries(config, string(content), dnsCheck.Status == doctorOK))
+		}
+		checks = append(checks, dnsCheck)
+	}
+
+	checks = append(checks, checkStaleArtifacts(configFile, config))
+	checks = append(checks, checkAddonVersions(config))
+	if hasSSLServices(config) {
+		checks = append(checks, checkSSLCertificates(getSSLDir(config)))
+	}
+	return checks
+}
+
+// printDoctorCheck prints a check with how to fix it
+func printDoctorCheck(check DoctorCheck) {
+	icons := map[string]string{doctorOK: emojiOr("✅", "ok"), doctorWarn: emojiOr("⚠️ ", "!!"), doctorFail: emojiOr("❌", "--")}
+	outputf("%s %s: %s\n", icons[check.Status], check.Name, check.Detail)
+	if check.Fix != "" {
+		infof("   Fix: %s\n", check.Fix)
+	}
+}
+
+func printDoctorUsage() {
+	fmt.Println("Fleet doctor - Diagnose the setup of Fleet and the project")
+	fmt.Println("\nUsage: fleet doctor [options]")
+	fmt.Println("\nChecks the Docker daemon and the compose implementation. In a project it also")
+	fmt.Println("checks the runtimes of services, ports taken by other processes, hosts file")
+	fmt.Println("entries, the DNS container, generated files that are out of date, versions of")
+	fmt.Println("attached services Fleet doesn't know and expiring SSL certificates, and prints")
+	fmt.Println("how to fix each problem.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+}
+
+func handleDoctor() {
+	if len(os.Args) > 2 && os.Args[2] == "help" {
+		printDoctorUsage()
+		return
+	}
+
+	fs := newCommandFlagSet("doctor", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	fs.Usage = printDoctorUsage
+
+	fs.Parse(os.Args[2:])
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	outputf("🩺 Checking the local Docker installation\n\n")
+
+	failed := 0
+	engine, err := queryDockerEngineVersion()
+	if err != nil {
+		printDoctorCheck(DoctorCheck{Name: "Docker Engine", Status: doctorFail, Detail: err.Error(),
+			Fix: "Start Docker Desktop or the docker service, and check that your user can reach the daemon"})
+		failed++
+	} else {
+		printDoctorCheck(DoctorCheck{Name: "Docker Engine", Status: doctorOK, Detail: engine})
+	}
+
+	cli, err := getComposeCLI()
+	switch {
+	case err != nil:
+		printDoctorCheck(DoctorCheck{Name: "Docker Compose", Status: doctorFail, Detail: err.Error(),
+			Fix: "Install the compose plugin: https://docs.docker.com/compose/install/"})
+		failed++
+	case cli.isLegacy():
+		printDoctorCheck(DoctorCheck{Name: "Docker Compose", Status: doctorWarn,
+			Detail: fmt.Sprintf("%s, v1 is no longer maintained and some commands need v2", cli),
+			Fix:    "Install the compose plugin: https://docs.docker.com/compose/install/"})
+	default:
+		printDoctorCheck(DoctorCheck{Name: "Docker Compose", Status: doctorOK, Detail: cli.String()})
+	}
+
+	// Outside a project there is nothing more to check
+	if _, err := os.Stat(*configFile); err == nil || isRemoteConfig(*configFile) {
+		config, err := loadConfig(*configFile)
+		if err != nil {
+			log.Fatalf("❌ Error loading config: %v", err)
+		}
+		if !printRuntimeClassChecks(config) {
+			failed++
+		}
+
+		outputf("\n🩺 Checking %s\n\n", config.Project)
+		for _, check := range checkProject(*configFile, config) {
+			printDoctorCheck(check)
+			if check.Status == doctorFail {
+				failed++
+			}
+		}
+	}
+
+	if failed > 0 {
+		log.Fatalf("❌ %d problems found, Fleet projects may not start", failed)
+	}
+	outputf("\n%s No problems found\n", emojiOr("✅", "ok"))
+}
@@ -0,0 +1,185 @@
+package fleetcli
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DoctorTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *DoctorTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *DoctorTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+// writeCertificate writes a self-signed certificate expiring at notAfter
+func (suite *DoctorTestSuite) writeCertificate(path string, notAfter time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "web.test"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	suite.Require().NoError(err)
+	suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+	suite.Require().NoError(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+}
+
+func (suite *DoctorTestSuite) TestParseHostPort() {
+	testCases := []struct {
+		mapping string
+		address string
+		port    int
+		ok      bool
+	}{
+		{"8080:80", "", 8080, true},
+		{"127.0.0.1:5432:5432/tcp", "127.0.0.1", 5432, true},
+		{"[::1]:6379:6379", "::1", 6379, true},
+		{"3000", "", 0, false},
+		{"8000-8010:8000-8010", "", 0, false},
+	}
+
+	for _, tc := range testCases {
+		address, port, ok := parseHostPort(tc.mapping)
+		suite.Equal(tc.ok, ok, tc.mapping)
+		suite.Equal(tc.address, address, tc.mapping)
+		suite.Equal(tc.port, port, tc.mapping)
+	}
+}
+
+func (suite *DoctorTestSuite) TestCheckPortConflicts() {
+	original := isTCPPortInUse
+	defer func() { isTCPPortInUse = original }()
+	isTCPPortInUse = func(address string, port int) bool { return port == 5432 }
+
+	compose := &DockerCompose{Services: map[string]DockerService{
+		"web":         {Ports: []string{"3000:3000"}},
+		"postgres-16": {Ports: []string{"127.0.0.1:5432:5432"}},
+	}}
+
+	check := checkPortConflicts(compose, nil)
+	suite.Equal(doctorFail, check.Status)
+	suite.Equal("in use by another process: 5432 (postgres-16)", check.Detail)
+	suite.NotEmpty(check.Fix)
+
+	check = checkPortConflicts(compose, map[string]bool{"postgres-16": true})
+	suite.Equal(doctorOK, check.Status, "Running services bind their own ports")
+	suite.Equal("1 published ports are free", check.Detail)
+}
+
+func (suite *DoctorTestSuite) TestCheckHostsEntries() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "web", Image: "nginx:alpine", Port: 80, Domain: "web.test"},
+		{Name: "api", Image: "node:20", Port: 3000, Domain: "api.test"},
+	}}
+	fleetSection := "# Fleet Services - START\n127.0.0.1 web.test\n# Fleet Services - END\n"
+
+	check := checkHostsEntries(config, fleetSection, false)
+	suite.Equal(doctorWarn, check.Status)
+	suite.Equal("not mapped: api.test", check.Detail)
+	suite.Contains(check.Fix, "fleet hosts add")
+
+	check = checkHostsEntries(config, fleetSection, true)
+	suite.Equal(doctorOK, check.Status, "The DNS service resolves missing domains")
+
+	check = checkHostsEntries(config, "10.0.0.5 api.test\n"+fleetSection, true)
+	suite.Equal(doctorFail, check.Status)
+	suite.Contains(check.Detail, "api.test -> 10.0.0.5 (line 1)")
+}
+
+func (suite *DoctorTestSuite) TestCheckDNSContainer() {
+	originalPath, originalRunning := getDNSStatePath, isDNSContainerRunning
+	defer func() { getDNSStatePath, isDNSContainerRunning = originalPath, originalRunning }()
+	getDNSStatePath = func() string { return filepath.Join(suite.helper.TempDir(), "dns.json") }
+
+	running := false
+	isDNSContainerRunning = func() bool { return running }
+
+	check := checkDNSContainer()
+	suite.Equal(doctorWarn, check.Status)
+	suite.Equal("Run 'fleet dns start'", check.Fix)
+
+	running = true
+	suite.Equal(doctorOK, checkDNSContainer().Status)
+
+	running = false
+	suite.Require().NoError(saveDNSState(&DNSState{Strategy: dnsStrategyHosts}))
+	suite.Equal(doctorOK, checkDNSContainer().Status, "Hosts file mode runs no container")
+}
+
+func (suite *DoctorTestSuite) TestCheckSSLCertificates() {
+	sslDir := filepath.Join(".fleet", "ssl")
+	suite.Equal("no certificates generated", checkSSLCertificates(sslDir).Detail)
+
+	suite.writeCertificate(filepath.Join(sslDir, "web_test.crt"), time.Now().Add(90*24*time.Hour))
+	suite.Equal(doctorOK, checkSSLCertificates(sslDir).Status)
+
+	suite.writeCertificate(filepath.Join(sslDir, "api_test.crt"), time.Now().Add(10*24*time.Hour))
+	check := checkSSLCertificates(sslDir)
+	suite.Equal(doctorWarn, check.Status)
+	suite.Contains(check.Detail, "expiring: api_test.crt")
+
+	suite.writeCertificate(filepath.Join(sslDir, "default.crt"), time.Now().Add(-24*time.Hour))
+	check = checkSSLCertificates(sslDir)
+	suite.Equal(doctorFail, check.Status)
+	suite.Contains(check.Detail, "expired: default.crt")
+	suite.Equal("Run 'fleet up' to generate new certificates", check.Fix)
+}
+
+func (suite *DoctorTestSuite) TestCheckStaleArtifacts() {
+	content := "project = \"shop\"\n\n[[services]]\nname = \"web\"\nimage = \"nginx:alpine\"\n"
+	suite.Require().NoError(os.WriteFile("fleet.toml", []byte(content), 0644))
+	config, err := loadConfig("fleet.toml")
+	suite.Require().NoError(err)
+	_, err = writeComposeFiles(config, generateDockerCompose(config))
+	suite.Require().NoError(err)
+
+	check := checkStaleArtifacts("fleet.toml", config)
+	suite.Equal(doctorOK, check.Status)
+
+	later := time.Now().Add(time.Minute)
+	suite.Require().NoError(os.Chtimes("fleet.toml", later, later))
+	check = checkStaleArtifacts("fleet.toml", config)
+	suite.Equal(doctorWarn, check.Status)
+	suite.Contains(check.Detail, "fleet.toml changed since")
+	suite.Contains(check.Fix, "fleet up -d")
+
+	suite.Require().NoError(os.Chtimes("fleet.toml", time.Now(), time.Now()))
+	suite.Require().NoError(os.MkdirAll(filepath.Join(".fleet", "staging"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(".fleet", "staging", composeFileName), []byte("services: {}\n"), 0644))
+	suite.Require().NoError(os.WriteFile(runningConfigFile, []byte(`{"config": "fleet.staging.toml"}`), 0644))
+	check = checkStaleArtifacts("fleet.toml", config)
+	suite.Equal(doctorWarn, check.Status)
+	suite.Contains(check.Detail, "records a stack of fleet.staging.toml, which no longer exists")
+	suite.Contains(check.Detail, filepath.Join(".fleet", "staging")+" belongs to no config of the project")
+}
+
+func TestDoctorSuite(t *testing.T) {
+	suite.Run(t, new(DoctorTestSuite))
+}
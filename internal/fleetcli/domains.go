@@ -0,0 +1,106 @@
+package fleetcli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// domainPattern matches a domain a service can be served on, e.g. shop.test or *.shop.test
+var domainPattern = regexp.MustCompile(`^(\*\.)?[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+
+// getConfiguredDomains returns the domains set in the config of a service: domain,
+// which older configs list several comma separated domains in, then domains
+func getConfiguredDomains(svc *Service) []string {
+	var domains []string
+	for _, domain := range append(strings.Split(svc.Domain, ","), svc.Domains...) {
+		domain = strings.TrimSpace(domain)
+		if domain != "" && !containsString(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// getServiceDomains returns every domain a service answers on. The first one is its
+// main domain, see getDomainForService.
+func getServiceDomains(svc *Service) []string {
+	// Services of other projects are served by their own project
+	if isExternalService(svc) {
+		return nil
+	}
+	if domains := getConfiguredDomains(svc); len(domains) > 0 {
+		return domains
+	}
+	// Auto-generate domain as {service-name}.test
+	if getServicePort(svc) > 0 {
+		return []string{fmt.Sprintf("%s.test", svc.Name)}
+	}
+	return nil
+}
+
+// getDomainAliases returns the domains of a service besides its main domain
+func getDomainAliases(svc *Service) []string {
+	domains := getServiceDomains(svc)
+	if len(domains) < 2 {
+		return nil
+	}
+	return domains[1:]
+}
+
+// getHostsDomains returns the domains of a service the hosts file can hold. Wildcards
+// are replaced by their base domain and tenants.
+func getHostsDomains(svc *Service) []string {
+	var domains []string
+	for _, domain := range getServiceDomains(svc) {
+		if isWildcardDomain(domain) {
+			continue
+		}
+		domains = append(domains, domain)
+	}
+	for _, domain := range getTenantDomains(svc) {
+		if !containsString(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// validateDomains checks the domains of the services, and that no two services claim
+// the same domain, since the proxy would only send it to one of them. Services routed
+// on different paths can share one, see path_routing.go.
+func validateDomains(config *Config) error {
+	owners := make(map[string]string)
+	for i := range config.Services {
+		svc := &config.Services[i]
+		seen := make(map[string]bool)
+		for _, domain := range append(strings.Split(svc.Domain, ","), svc.Domains...) {
+			domain = strings.ToLower(strings.TrimSpace(domain))
+			if domain == "" {
+				continue
+			}
+			if seen[domain] {
+				return fmt.Errorf("service %s: domain %s is listed twice", svc.Name, domain)
+			}
+			seen[domain] = true
+		}
+		for _, domain := range getServiceDomains(svc) {
+			if !isWildcardDomain(domain) && strings.Contains(domain, "*") {
+				// validateWildcardDomain explains where the wildcard goes
+				continue
+			}
+			if !domainPattern.MatchString(strings.ToLower(domain)) {
+				return fmt.Errorf("service %s: invalid domain %q, use a domain like %s.test", svc.Name, domain, svc.Name)
+			}
+			key := strings.ToLower(domain) + getRoutePath(svc)
+			if owner, exists := owners[key]; exists && owner != svc.Name {
+				if path := getRoutePath(svc); path != "" {
+					return fmt.Errorf("services %s and %s both route %s%s", owner, svc.Name, domain, path)
+				}
+				return fmt.Errorf("services %s and %s both use domain %s", owner, svc.Name, domain)
+			}
+			owners[key] = svc.Name
+		}
+	}
+	return nil
+}
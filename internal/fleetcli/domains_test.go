@@ -0,0 +1,117 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DomainsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *DomainsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *DomainsTestSuite) TearDownTest() {
+	os.Chdir(suite.originalDir)
+	suite.helper.Cleanup()
+}
+
+func (suite *DomainsTestSuite) TestGetServiceDomains() {
+	suite.Equal([]string{"app.test", "admin.app.test", "*.app.test"},
+		getServiceDomains(&Service{Name: "app", Domains: []string{"app.test", "admin.app.test", "*.app.test"}}))
+	suite.Equal([]string{"shop.test", "admin.shop.test", "api.shop.test"},
+		getServiceDomains(&Service{Name: "shop", Domain: "shop.test, admin.shop.test", Domains: []string{"api.shop.test", "shop.test"}}))
+	suite.Equal([]string{"web.test"}, getServiceDomains(&Service{Name: "web", Port: 8080}))
+	suite.Nil(getServiceDomains(&Service{Name: "worker"}))
+
+	svc := &Service{Name: "app", Domains: []string{"app.test", "admin.app.test"}}
+	suite.Equal("app.test", getDomainForService(svc))
+	suite.Equal([]string{"admin.app.test"}, getDomainAliases(svc))
+}
+
+func (suite *DomainsTestSuite) TestValidateDomains() {
+	suite.NoError(validateDomains(&Config{Services: []Service{
+		{Name: "app", Domains: []string{"app.test", "admin.app.test", "*.app.test"}},
+		{Name: "api", Domain: "api.test"},
+	}}))
+
+	err := validateDomains(&Config{Services: []Service{
+		{Name: "app", Domains: []string{"app.test", "api.test"}},
+		{Name: "api", Domain: "api.test"},
+	}})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "services app and api both use domain api.test")
+
+	// An auto-generated domain is claimed as well
+	suite.Error(validateDomains(&Config{Services: []Service{
+		{Name: "app", Domains: []string{"web.test"}},
+		{Name: "web", Port: 8080},
+	}}))
+	suite.Error(validateDomains(&Config{Services: []Service{{Name: "app", Domain: "app.test", Domains: []string{"App.test"}}}}))
+	suite.Error(validateDomains(&Config{Services: []Service{{Name: "app", Domains: []string{"app_test"}}}}))
+}
+
+func (suite *DomainsTestSuite) TestNginxServerNames() {
+	config := &Config{Services: []Service{
+		{Name: "app", Domains: []string{"app.test", "admin.app.test", "*.app.test"}, Port: 3000},
+	}}
+
+	services := getNginxServices(config)
+	suite.Require().Len(services, 1)
+	suite.Equal("app.test", services[0].Domain)
+	suite.Equal([]string{"admin.app.test", "*.app.test"}, services[0].Aliases)
+
+	nginxConf, err := generateNginxConfig(config)
+	suite.Require().NoError(err)
+	suite.Contains(nginxConf, "server_name app.test admin.app.test *.app.test;")
+}
+
+func (suite *DomainsTestSuite) TestHostsEntries() {
+	config := &Config{Services: []Service{
+		{Name: "app", Domains: []string{"app.test", "admin.app.test", "*.tenants.test"}, Tenants: []string{"acme"}, Port: 3000},
+	}}
+
+	suite.Equal(map[string]string{
+		"app.test":          "127.0.0.1",
+		"admin.app.test":    "127.0.0.1",
+		"tenants.test":      "127.0.0.1",
+		"acme.tenants.test": "127.0.0.1",
+	}, getDomainMappings(config))
+}
+
+func (suite *DomainsTestSuite) TestCertificateCoversAliases() {
+	config := &Config{Services: []Service{
+		{Name: "app", Domains: []string{"app.test", "admin.app.test", "*.app.test"}, Port: 3000, SSL: true},
+	}}
+
+	certs := getSSLCertificates(config, nil)
+	suite.Require().Len(certs, 4)
+	suite.Equal("app.test", certs[1].Domain)
+	suite.Equal([]string{"admin.app.test", "*.app.test"}, certs[1].Aliases)
+	suite.Equal([]string{"app.test", "www.app.test", "admin.app.test", "www.admin.app.test", "*.app.test"}, getCertificateHosts(certs[1]))
+
+	suite.Require().NoError(os.MkdirAll(getSSLDir(config), 0755))
+	suite.Require().NoError(generateSelfSignedCertificate(certs[1]))
+	parsed, err := readCertificate(filepath.Join(getSSLDir(config), "app_test.crt"))
+	suite.Require().NoError(err)
+	suite.NoError(parsed.VerifyHostname("admin.app.test"))
+	suite.NoError(parsed.VerifyHostname("acme.app.test"))
+	suite.False(needsSSLCertificate(certs[1]))
+
+	// Adding a domain renews the certificate so it covers it
+	certs[1].Aliases = append(certs[1].Aliases, "shop.test")
+	suite.True(needsSSLCertificate(certs[1]))
+}
+
+func TestDomainsSuite(t *testing.T) {
+	suite.Run(t, new(DomainsTestSuite))
+}
@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -15,21 +15,8 @@ type EmailConfig struct {
 	Password string // Optional SMTP password
 }
 
-// Supported email testing service versions
-var supportedEmailVersions = map[string]map[string]string{
-	"mailpit": {
-		"1.13":    "axllent/mailpit:v1.13",
-		"1.14":    "axllent/mailpit:v1.14",
-		"1.15":    "axllent/mailpit:v1.15",
-		"1.16":    "axllent/mailpit:v1.16",
-		"1.17":    "axllent/mailpit:v1.17",
-		"1.18":    "axllent/mailpit:v1.18",
-		"1.19":    "axllent/mailpit:v1.19",
-		"1.20":    "axllent/mailpit:v1.20",
-		"latest":  "axllent/mailpit:latest",
-		"default": "axllent/mailpit:v1.20",
-	},
-}
+// Supported email testing service versions, from config/versions.json
+var supportedEmailVersions = imageMaps(versionData.Email)
 
 // parseEmailType parses email service type and version from a string like "mailpit:1.20"
 func parseEmailType(emailString string) (emailType string, version string) {
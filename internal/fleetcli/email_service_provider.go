@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -13,12 +13,8 @@ type EmailServiceProvider struct {
 // NewEmailServiceProvider creates a new email service provider
 func NewEmailServiceProvider() *EmailServiceProvider {
 	return &EmailServiceProvider{
-		defaultVersions: map[string]string{
-			"mailpit": "1.20",
-		},
-		supportedVersions: map[string][]string{
-			"mailpit": {"1.13", "1.14", "1.15", "1.16", "1.17", "1.18", "1.19", "1.20", "latest"},
-		},
+		defaultVersions:   getDefaultVersions(versionData.Email),
+		supportedVersions: getVersionLists(versionData.Email),
 	}
 }
 
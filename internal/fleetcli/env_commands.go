@@ -0,0 +1,463 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+var (
+	// envKeyPattern matches the variable names fleet env accepts
+	envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// tomlHeaderPattern matches a table header, like [[services]] or [services.env]
+	tomlHeaderPattern = regexp.MustCompile(`^\[\[?\s*([A-Za-z0-9_.\s-]+?)\s*\]\]?\s*(#.*)?$`)
+	// tomlKeyPattern matches the key of a key/value line, bare or quoted
+	tomlKeyPattern = regexp.MustCompile(`^\s*(?:"([^"]*)"|'([^']*)'|([A-Za-z0-9_-]+))\s*=`)
+	// tomlServiceNamePattern matches the name of a service in its [[services]] table
+	tomlServiceNamePattern = regexp.MustCompile(`^\s*name\s*=\s*["']([^"']+)["']`)
+	// tomlInlineEnvPattern matches an env inline table, like env = { APP_DEBUG = "true" }
+	tomlInlineEnvPattern = regexp.MustCompile(`^(\s*)env\s*=\s*\{`)
+	// tomlInlineKeyPattern matches the keys of an inline table
+	tomlInlineKeyPattern = regexp.MustCompile(`[{,]\s*(?:"([^"]*)"|'([^']*)'|([A-Za-z0-9_-]+))\s*=`)
+	// tomlBareKeyPattern matches the keys TOML accepts without quotes
+	tomlBareKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// EnvAssignment is a variable fleet env set writes
+type EnvAssignment struct {
+	Key   string
+	Value string
+}
+
+// parseEnvAssignments parses KEY=value arguments
+func parseEnvAssignments(args []string) ([]EnvAssignment, error) {
+	var assignments []EnvAssignment
+	for _, arg := range args {
+		key, value, found := strings.Cut(arg, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid assignment %q, use KEY=value", arg)
+		}
+		if !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid variable name %q", key)
+		}
+		assignments = append(assignments, EnvAssignment{Key: key, Value: value})
+	}
+	return assignments, nil
+}
+
+// tomlString quotes a value as a TOML basic string
+func tomlString(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlKey returns a key as TOML accepts it, quoted when it isn't a bare key
+func tomlKey(key string) string {
+	if tomlBareKeyPattern.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// getTOMLKey returns the key of a key/value line, if it is one
+func getTOMLKey(line string) (string, bool) {
+	match := tomlKeyPattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", false
+	}
+	return match[1] + match[2] + match[3], true
+}
+
+// getTOMLHeader returns the table name of a header line, like services or services.env
+func getTOMLHeader(line string) (string, bool) {
+	match := tomlHeaderPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return "", false
+	}
+	return strings.Join(strings.Fields(match[1]), ""), true
+}
+
+// findTOMLServiceBlock returns the lines of the [[services]] table of a service, from its
+// header up to the next table that isn't one of its sub-tables
+func findTOMLServiceBlock(lines []string, name string) (start, end int, err error) {
+	start, current := -1, -1
+	for i, line := range lines {
+		if header, isHeader := getTOMLHeader(line); isHeader {
+			if start >= 0 && (header == "services" || !strings.HasPrefix(header, "services.")) {
+				return start, i, nil
+			}
+			current = -1
+			if header == "services" {
+				current = i
+			}
+			continue
+		}
+		// Only the name of the service itself, not those of its init containers
+		if current >= 0 && start < 0 {
+			if match := tomlServiceNamePattern.FindStringSubmatch(line); match != nil && match[1] == name {
+				start = current
+			}
+		}
+	}
+	if start < 0 {
+		return 0, 0, fmt.Errorf("service %s not found", name)
+	}
+	return start, len(lines), nil
+}
+
+// editServiceEnv sets and removes variables of the env table of a service in a TOML
+// config. Only the lines of the changed variables are touched, so comments and the layout
+// of the rest of the file stay as they are.
+func editServiceEnv(content []byte, name string, set []EnvAssignment, unset []string) ([]byte, error) {
+	var before Config
+	if err := toml.Unmarshal(content, &before); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	svc := findService(&before, name)
+	if svc == nil {
+		return nil, fmt.Errorf("service %s not found", name)
+	}
+	for _, key := range unset {
+		if _, exists := svc.Environment[key]; !exists {
+			return nil, fmt.Errorf("%s is not set on service %s", key, name)
+		}
+	}
+
+	lines := strings.Split(string(content), "\n")
+	start, end, err := findTOMLServiceBlock(lines, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find where the variables are: an [services.env] table or an inline table
+	envStart, envEnd, inline := -1, -1, -1
+	inMain := true
+	for i := start + 1; i < end; i++ {
+		if header, isHeader := getTOMLHeader(lines[i]); isHeader {
+			inMain = false
+			if envStart >= 0 && envEnd < 0 {
+				envEnd = i
+			}
+			if header == "services.env" {
+				envStart = i
+			}
+			continue
+		}
+		if inMain && tomlInlineEnvPattern.MatchString(lines[i]) {
+			inline = i
+		}
+	}
+	if envStart >= 0 && envEnd < 0 {
+		envEnd = end
+	}
+
+	switch {
+	case inline >= 0:
+		lines[inline] = renderInlineEnv(lines[inline], svc.Environment, set, unset)
+
+	case envStart >= 0:
+		var table []string
+		for _, line := range lines[envStart+1 : envEnd] {
+			if key, isKey := getTOMLKey(line); isKey && containsString(unset, key) {
+				continue
+			}
+			table = append(table, line)
+		}
+		// New variables go after the last one, before trailing blank lines and comments
+		last := len(table)
+		for last > 0 {
+			trimmed := strings.TrimSpace(table[last-1])
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				break
+			}
+			last--
+		}
+		var added []string
+		for _, assignment := range set {
+			line := fmt.Sprintf("%s = %s", assignment.Key, tomlString(assignment.Value))
+			replaced := false
+			for i, existing := range table {
+				if key, isKey := getTOMLKey(existing); isKey && key == assignment.Key {
+					indent := existing[:len(existing)-len(strings.TrimLeft(existing, " \t"))]
+					table[i] = indent + line
+					replaced = true
+				}
+			}
+			if !replaced {
+				added = append(added, line)
+			}
+		}
+		table = append(table[:last], append(added, table[last:]...)...)
+		lines = append(lines[:envStart+1], append(table, lines[envEnd:]...)...)
+
+	case len(set) > 0:
+		// No variables yet, add the table at the end of the service
+		last := end
+		for last > start+1 && strings.TrimSpace(lines[last-1]) == "" {
+			last--
+		}
+		table := []string{"", "[services.env]"}
+		for _, assignment := range set {
+			table = append(table, fmt.Sprintf("%s = %s", assignment.Key, tomlString(assignment.Value)))
+		}
+		lines = append(lines[:last], append(table, lines[last:]...)...)
+	}
+
+	edited := []byte(strings.Join(lines, "\n"))
+
+	// Only the variables may change, anything else means the file has a layout the line
+	// editing above doesn't understand
+	expected := before
+	expected.Services = append([]Service(nil), before.Services...)
+	env := make(map[string]string)
+	for key, value := range svc.Environment {
+		env[key] = value
+	}
+	for _, key := range unset {
+		delete(env, key)
+	}
+	for _, assignment := range set {
+		env[assignment.Key] = assignment.Value
+	}
+	expectedService := findService(&expected, name)
+	expectedService.Environment = env
+
+	var after Config
+	if err := toml.Unmarshal(edited, &after); err != nil {
+		return nil, fmt.Errorf("couldn't edit the env of %s safely, edit it by hand: %w", name, err)
+	}
+	if afterService := findService(&after, name); afterService != nil && len(afterService.Environment) == 0 && len(env) == 0 {
+		afterService.Environment = env
+	}
+	if !reflect.DeepEqual(expected, after) {
+		return nil, fmt.Errorf("couldn't edit the env of %s safely, edit it by hand", name)
+	}
+	return edited, nil
+}
+
+// renderInlineEnv rewrites an env inline table, keeping the order of its variables
+func renderInlineEnv(line string, env map[string]string, set []EnvAssignment, unset []string) string {
+	indent := tomlInlineEnvPattern.FindStringSubmatch(line)[1]
+
+	// Variables in the order they are written, then those only the parser saw
+	var keys []string
+	for _, match := range tomlInlineKeyPattern.FindAllStringSubmatch(line, -1) {
+		key := match[1] + match[2] + match[3]
+		if _, exists := env[key]; exists && !containsString(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range sortedKeys(env) {
+		if !containsString(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	values := make(map[string]string)
+	for key, value := range env {
+		values[key] = value
+	}
+	for _, assignment := range set {
+		if _, exists := values[assignment.Key]; !exists {
+			keys = append(keys, assignment.Key)
+		}
+		values[assignment.Key] = assignment.Value
+	}
+
+	var pairs []string
+	for _, key := range keys {
+		if !containsString(unset, key) {
+			pairs = append(pairs, fmt.Sprintf("%s = %s", tomlKey(key), tomlString(values[key])))
+		}
+	}
+	if len(pairs) == 0 {
+		return indent + "env = {}"
+	}
+	return fmt.Sprintf("%senv = { %s }", indent, strings.Join(pairs, ", "))
+}
+
+// updateServiceEnv edits the env of a service in a config file
+func updateServiceEnv(configFile, name string, set []EnvAssignment, unset []string) error {
+	if filepath.Ext(configFile) != ".toml" {
+		return fmt.Errorf("fleet env only edits TOML configs, change the env of %s in %s by hand", name, configFile)
+	}
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	edited, err := editServiceEnv(content, name, set, unset)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configFile, edited, 0644)
+}
+
+// applyServiceEnv recreates the containers of a service so they get its new env. The env
+// of a running process can't change, so they are started again with the new compose file.
+func applyServiceEnv(configFile, name string, lockOptions *ProjectLockOptions) error {
+	config, err := loadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("error loading config: %w", err)
+	}
+	svc := findService(config, name)
+
+	release := lockProject("env", lockOptions)
+	defer release()
+
+	compose := generateDockerCompose(config)
+	composeFiles, err := writeComposeFiles(config, compose)
+	if err != nil {
+		return fmt.Errorf("error writing docker-compose.yml: %w", err)
+	}
+
+	var containers []string
+	for _, container := range []string{svc.Name, getAppServiceName(svc)} {
+		if _, exists := compose.Services[container]; exists && !containsString(containers, container) {
+			containers = append(containers, container)
+		}
+	}
+	args := composeArgs(composeFiles, "up", "-d", "--no-deps")
+	return runDocker(append(args, containers...))
+}
+
+func handleEnv() {
+	if len(os.Args) < 3 {
+		printEnvUsage()
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "set", "unset", "list", "ls":
+		handleEnvCommand(os.Args[2], os.Args[3:])
+	case "help":
+		printEnvUsage()
+	default:
+		fmt.Printf("Unknown env command: %s\n\n", os.Args[2])
+		printEnvUsage()
+		os.Exit(1)
+	}
+}
+
+func printEnvUsage() {
+	fmt.Println("Fleet env - Manage the environment variables of services")
+	fmt.Println("\nUsage: fleet env <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  set <service> KEY=value...  Set variables in the env table of the service")
+	fmt.Println("  unset <service> KEY...      Remove variables from the env table of the service")
+	fmt.Println("  list <service>              Show the variables of the service")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --apply     Recreate the containers of the service so they get the change")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("\nThe config file is edited in place, comments and formatting are kept.")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet env set web APP_DEBUG=true LOG_LEVEL=debug  # Set two variables")
+	fmt.Println("  fleet env unset web APP_DEBUG --apply             # Remove one and restart web with the change")
+}
+
+func handleEnvCommand(command string, args []string) {
+	fs := newCommandFlagSet("env "+command, flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	apply := fs.Bool("apply", false, "Recreate the containers of the service")
+	lockOptions := addProjectLockFlags(fs)
+
+	positional := parseFlagsAndArgs(fs, args)
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	if command == "list" || command == "ls" {
+		if len(positional) != 1 {
+			log.Fatalf("❌ Usage: fleet env list <service>")
+		}
+		config, err := loadConfig(*configFile)
+		if err != nil {
+			log.Fatalf("❌ Error loading config: %v", err)
+		}
+		svc := findService(config, positional[0])
+		if svc == nil {
+			log.Fatalf("❌ Service %s not found in %s", positional[0], *configFile)
+		}
+		for _, key := range sortedKeys(svc.Environment) {
+			outputf("%s=%s\n", key, svc.Environment[key])
+		}
+		return
+	}
+
+	var set []EnvAssignment
+	var unset []string
+	if command == "set" {
+		if len(positional) < 2 {
+			log.Fatalf("❌ Usage: fleet env set <service> KEY=value...")
+		}
+		assignments, err := parseEnvAssignments(positional[1:])
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		set = assignments
+	} else {
+		if len(positional) < 2 {
+			log.Fatalf("❌ Usage: fleet env unset <service> KEY...")
+		}
+		for _, key := range positional[1:] {
+			if !envKeyPattern.MatchString(key) {
+				log.Fatalf("❌ Invalid variable name %q", key)
+			}
+		}
+		unset = positional[1:]
+	}
+
+	name := positional[0]
+	if err := updateServiceEnv(*configFile, name, set, unset); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if command == "set" {
+		var keys []string
+		for _, assignment := range set {
+			keys = append(keys, assignment.Key)
+		}
+		infof("✅ Set %s on %s in %s\n", strings.Join(keys, ", "), name, *configFile)
+	} else {
+		infof("✅ Removed %s from %s in %s\n", strings.Join(unset, ", "), name, *configFile)
+	}
+
+	if !*apply {
+		infof("💡 Run 'fleet up -d' to recreate %s with the change, or pass --apply\n", name)
+		return
+	}
+	infof("🔄 Recreating %s...\n", name)
+	if err := applyServiceEnv(*configFile, name, lockOptions); err != nil {
+		log.Fatalf("❌ Error recreating %s: %v", name, err)
+	}
+	infof("✅ %s is running with the new env\n", name)
+}
@@ -0,0 +1,158 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EnvCommandsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *EnvCommandsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *EnvCommandsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *EnvCommandsTestSuite) TestParseEnvAssignments() {
+	assignments, err := parseEnvAssignments([]string{"APP_DEBUG=true", "DSN=mysql://db/app?x=1", "EMPTY="})
+	suite.Require().NoError(err)
+	suite.Equal([]EnvAssignment{
+		{Key: "APP_DEBUG", Value: "true"},
+		{Key: "DSN", Value: "mysql://db/app?x=1"},
+		{Key: "EMPTY", Value: ""},
+	}, assignments)
+
+	_, err = parseEnvAssignments([]string{"APP_DEBUG"})
+	suite.ErrorContains(err, "use KEY=value")
+	_, err = parseEnvAssignments([]string{"APP-DEBUG=1"})
+	suite.ErrorContains(err, "invalid variable name")
+}
+
+func (suite *EnvCommandsTestSuite) TestEditServiceEnv() {
+	config := `project = "shop"
+
+# The storefront
+[[services]]
+name = "web"
+image = "node:20"  # LTS
+port = 3000
+
+[services.env]
+NODE_ENV = "development"  # Local only
+  API_URL = "http://api.test"
+
+[[services.init]]
+name = "migrate"
+command = "npm run migrate"
+
+[[services]]
+name = "api"
+image = "node:20"
+env = { LOG_LEVEL = "info", "APP_NAME" = "api" }
+
+[[services]]
+name = "worker"
+image = "node:20"
+`
+
+	testCases := []struct {
+		name     string
+		service  string
+		set      []EnvAssignment
+		unset    []string
+		expected string
+		wantErr  string
+	}{
+		{
+			name:     "replace and add in table",
+			service:  "web",
+			set:      []EnvAssignment{{"API_URL", "http://api.localhost"}, {"APP_DEBUG", "true"}},
+			expected: "[services.env]\nNODE_ENV = \"development\"  # Local only\n  API_URL = \"http://api.localhost\"\nAPP_DEBUG = \"true\"\n\n[[services.init]]",
+		},
+		{
+			name:     "unset in table",
+			service:  "web",
+			unset:    []string{"NODE_ENV"},
+			expected: "[services.env]\n  API_URL = \"http://api.test\"\n\n[[services.init]]",
+		},
+		{
+			name:     "inline table",
+			service:  "api",
+			set:      []EnvAssignment{{"APP_DEBUG", "say \"hi\""}},
+			unset:    []string{"LOG_LEVEL"},
+			expected: "env = { APP_NAME = \"api\", APP_DEBUG = \"say \\\"hi\\\"\" }\n",
+		},
+		{
+			name:     "new table",
+			service:  "worker",
+			set:      []EnvAssignment{{"QUEUE", "emails"}},
+			expected: "name = \"worker\"\nimage = \"node:20\"\n\n[services.env]\nQUEUE = \"emails\"\n",
+		},
+		{
+			name:    "unset missing variable",
+			service: "worker",
+			unset:   []string{"QUEUE"},
+			wantErr: "QUEUE is not set on service worker",
+		},
+		{
+			name:    "unknown service",
+			service: "db",
+			set:     []EnvAssignment{{"A", "1"}},
+			wantErr: "service db not found",
+		},
+		{
+			name:    "init container name",
+			service: "migrate",
+			set:     []EnvAssignment{{"A", "1"}},
+			wantErr: "service migrate not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			edited, err := editServiceEnv([]byte(config), tc.service, tc.set, tc.unset)
+			if tc.wantErr != "" {
+				suite.ErrorContains(err, tc.wantErr)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Contains(string(edited), tc.expected)
+			suite.Contains(string(edited), "# The storefront\n[[services]]", "Comments are kept")
+			suite.Contains(string(edited), `image = "node:20"  # LTS`)
+		})
+	}
+}
+
+func (suite *EnvCommandsTestSuite) TestUpdateServiceEnv() {
+	suite.Require().NoError(os.WriteFile("fleet.toml", []byte("project = \"shop\"\n\n[[services]]\nname = \"web\"\nimage = \"node:20\"\n"), 0644))
+
+	suite.Require().NoError(updateServiceEnv("fleet.toml", "web", []EnvAssignment{{"APP_DEBUG", "true"}}, nil))
+	config, err := loadConfig("fleet.toml")
+	suite.Require().NoError(err)
+	suite.Equal(map[string]string{"APP_DEBUG": "true"}, config.Services[0].Environment)
+
+	suite.Require().NoError(updateServiceEnv("fleet.toml", "web", nil, []string{"APP_DEBUG"}))
+	config, err = loadConfig("fleet.toml")
+	suite.Require().NoError(err)
+	suite.Empty(config.Services[0].Environment)
+
+	suite.Require().NoError(os.WriteFile("fleet.yml", []byte("project: shop\n"), 0644))
+	suite.ErrorContains(updateServiceEnv("fleet.yml", "web", []EnvAssignment{{"A", "1"}}, nil), "only edits TOML configs")
+}
+
+func TestEnvCommandsSuite(t *testing.T) {
+	suite.Run(t, new(EnvCommandsTestSuite))
+}
@@ -0,0 +1,186 @@
+package fleetcli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conventions of the variables Fleet injects for attached services
+const (
+	envStyleLaravel = "laravel"
+	envStyleRails   = "rails"
+	envStyleSpring  = "spring"
+	envStyleCustom  = "custom"
+)
+
+// envStyles are the values env_style accepts
+var envStyles = []string{envStyleLaravel, envStyleRails, envStyleSpring, envStyleCustom}
+
+// laravelEnvVars are injected for Laravel and mean nothing to other frameworks
+var laravelEnvVars = []string{
+	"DB_CONNECTION", "DB_HOST", "DB_PORT", "DB_DATABASE", "DB_USERNAME", "DB_PASSWORD",
+	"CACHE_DRIVER", "SESSION_DRIVER", "QUEUE_CONNECTION",
+	"MAIL_DRIVER", "MAIL_MAILER", "MAIL_ENCRYPTION",
+}
+
+// jdbcSchemes are the JDBC URL schemes of DB_CONNECTION values
+var jdbcSchemes = map[string]string{
+	"mysql":   "mysql",
+	"mariadb": "mariadb",
+	"pgsql":   "postgresql",
+}
+
+// envStylePresets rename the injected variables, which follow Laravel's names, for
+// other frameworks. An empty name drops the variable.
+var envStylePresets = map[string]map[string]string{
+	envStyleRails: dropLaravelEnvVars(map[string]string{}),
+	envStyleSpring: dropLaravelEnvVars(map[string]string{
+		"DATABASE_URL":   "",
+		"MONGODB_URI":    "SPRING_DATA_MONGODB_URI",
+		"REDIS_HOST":     "SPRING_DATA_REDIS_HOST",
+		"REDIS_PORT":     "SPRING_DATA_REDIS_PORT",
+		"REDIS_PASSWORD": "SPRING_DATA_REDIS_PASSWORD",
+		"MAIL_HOST":      "SPRING_MAIL_HOST",
+		"MAIL_PORT":      "SPRING_MAIL_PORT",
+		"MAIL_USERNAME":  "SPRING_MAIL_USERNAME",
+		"MAIL_PASSWORD":  "SPRING_MAIL_PASSWORD",
+	}),
+}
+
+// dropLaravelEnvVars adds the Laravel only variables to a preset as dropped
+func dropLaravelEnvVars(renames map[string]string) map[string]string {
+	for _, key := range laravelEnvVars {
+		renames[key] = ""
+	}
+	return renames
+}
+
+// validateEnvStyle checks env_style and the env_map of a service
+func validateEnvStyle(svc *Service) error {
+	if svc.EnvStyle != "" && !containsString(envStyles, svc.EnvStyle) {
+		return fmt.Errorf("service %s: unsupported env_style %q (supported: %s)", svc.Name, svc.EnvStyle, strings.Join(envStyles, ", "))
+	}
+	if svc.EnvStyle == envStyleCustom && len(svc.EnvMap) == 0 {
+		return fmt.Errorf("service %s: env_style custom needs an env_map of the variables to inject", svc.Name)
+	}
+
+	targets := make(map[string]string)
+	for _, key := range sortedKeys(svc.EnvMap) {
+		name := svc.EnvMap[key]
+		if !envKeyPattern.MatchString(key) {
+			return fmt.Errorf("service %s: env_map: invalid variable name %q", svc.Name, key)
+		}
+		if name == "" {
+			continue
+		}
+		if !envKeyPattern.MatchString(name) {
+			return fmt.Errorf("service %s: env_map: invalid variable name %q for %s", svc.Name, name, key)
+		}
+		if other, taken := targets[name]; taken {
+			return fmt.Errorf("service %s: env_map: %s and %s are both renamed to %s", svc.Name, other, key, name)
+		}
+		targets[name] = key
+	}
+	return nil
+}
+
+// getEnvKeys returns the names of the variables a compose service has
+func getEnvKeys(compose *DockerCompose, name string) map[string]bool {
+	keys := make(map[string]bool)
+	for key := range compose.Services[name].Environment {
+		keys[key] = true
+	}
+	return keys
+}
+
+// getEnvStyle returns the env_style of a service. Ruby services follow Rails' names
+// unless they set one.
+func getEnvStyle(svc *Service) string {
+	if svc.EnvStyle == "" && isRubyService(svc) {
+		return envStyleRails
+	}
+	return svc.EnvStyle
+}
+
+// applyEnvStyle renames or drops the variables attached services added to a service,
+// following its env_style and env_map. Variables it already had are kept as they are.
+func applyEnvStyle(compose *DockerCompose, svc *Service, existing map[string]bool) {
+	style := getEnvStyle(svc)
+	if style == "" && len(svc.EnvMap) == 0 {
+		return
+	}
+	service, exists := compose.Services[svc.Name]
+	if !exists || len(service.Environment) == 0 {
+		return
+	}
+
+	env := make(map[string]string, len(service.Environment))
+	injected := make(map[string]string)
+	for key, value := range service.Environment {
+		if existing[key] {
+			env[key] = value
+		} else {
+			injected[key] = value
+		}
+	}
+
+	switch style {
+	case envStyleRails:
+		addRailsDatabaseURL(injected)
+	case envStyleSpring:
+		addSpringDatasource(injected)
+	}
+
+	renames := make(map[string]string)
+	for key, name := range envStylePresets[style] {
+		renames[key] = name
+	}
+	for key, name := range svc.EnvMap {
+		renames[key] = name
+	}
+
+	// Variables keep their name unless renamed, custom only injects the mapped ones
+	for _, key := range sortedKeys(injected) {
+		if _, renamed := renames[key]; renamed || style == envStyleCustom {
+			continue
+		}
+		if _, set := env[key]; !set {
+			env[key] = injected[key]
+		}
+	}
+	// Renamed variables win over injected ones of the same name
+	for _, key := range sortedKeys(injected) {
+		name, renamed := renames[key]
+		if !renamed || name == "" || existing[name] {
+			continue
+		}
+		env[name] = injected[key]
+	}
+
+	service.Environment = env
+	compose.Services[svc.Name] = service
+}
+
+// addRailsDatabaseURL points DATABASE_URL of MySQL and MariaDB at the mysql2 adapter
+func addRailsDatabaseURL(env map[string]string) {
+	url, ok := env["DATABASE_URL"]
+	if !ok {
+		return
+	}
+	for _, scheme := range []string{"mysql://", "mariadb://"} {
+		if strings.HasPrefix(url, scheme) {
+			env["DATABASE_URL"] = "mysql2://" + strings.TrimPrefix(url, scheme)
+		}
+	}
+}
+
+// addSpringDatasource adds the spring.datasource properties of the database
+func addSpringDatasource(env map[string]string) {
+	scheme, ok := jdbcSchemes[env["DB_CONNECTION"]]
+	if !ok {
+		return
+	}
+	env["SPRING_DATASOURCE_URL"] = fmt.Sprintf("jdbc:%s://%s:%s/%s", scheme, env["DB_HOST"], env["DB_PORT"], env["DB_DATABASE"])
+	env["SPRING_DATASOURCE_USERNAME"] = env["DB_USERNAME"]
+	env["SPRING_DATASOURCE_PASSWORD"] = env["DB_PASSWORD"]
+}
@@ -0,0 +1,116 @@
+package fleetcli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultExecShell opens bash when the image has it, and sh otherwise
+var defaultExecShell = []string{"sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi"}
+
+// resolveExecService returns the compose service a name refers to: the container that
+// runs the code of a service of fleet.toml, or a service of the generated compose file,
+// like a shared database
+func resolveExecService(config *Config, compose *DockerCompose, name string) (string, error) {
+	target := name
+	if svc := findService(config, name); svc != nil {
+		target = getAppServiceName(svc)
+	}
+	if _, ok := compose.Services[target]; !ok {
+		return "", fmt.Errorf("unknown service %s, the stack has: %s", name, strings.Join(sortedKeys(compose.Services), ", "))
+	}
+	return target, nil
+}
+
+// getExecArgs returns the docker arguments running a command in a service. Without a
+// terminal, no TTY is allocated so the output can be piped.
+func getExecArgs(files ComposeFiles, target, user string, tty bool, command []string) []string {
+	args := composeArgs(files, "exec")
+	if !tty {
+		args = append(args, "-T")
+	}
+	if user != "" {
+		args = append(args, "--user", user)
+	}
+	args = append(args, target)
+	if len(command) == 0 {
+		command = defaultExecShell
+	}
+	return append(args, command...)
+}
+
+func printExecUsage() {
+	fmt.Println("Fleet exec - Run a command or open a shell in a service")
+	fmt.Println("\nUsage: fleet exec [options] <service> [command...]")
+	fmt.Println("\nThe service is a service of fleet.toml, PHP services run the command in their")
+	fmt.Println("PHP container, or a shared service like postgres-16. Without a command, a bash")
+	fmt.Println("shell is opened, or sh when the image has no bash.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -u, --user  Run as this user (name or uid[:gid])")
+	fmt.Println("  -T          Don't allocate a TTY, the default when not on a terminal")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet exec api                     # Open a shell in 'api'")
+	fmt.Println("  fleet exec api ls -la /app         # Run a command, its options are passed as is")
+	fmt.Println("  fleet exec -u root shop apk add git  # Run as root")
+	fmt.Println("  fleet exec postgres-16 psql -U postgres  # Open psql in the shared database")
+}
+
+func handleExec() {
+	if len(os.Args) > 2 && os.Args[2] == "help" {
+		printExecUsage()
+		return
+	}
+
+	fs := newCommandFlagSet("exec", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	user := fs.String("u", "", "User")
+	userLong := fs.String("user", "", "User")
+	noTTY := fs.Bool("T", false, "Don't allocate a TTY")
+	fs.Usage = printExecUsage
+
+	// Options come before the service, everything after it is the command
+	fs.Parse(os.Args[2:])
+	if fs.NArg() == 0 {
+		printExecUsage()
+		os.Exit(1)
+	}
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+	if *userLong != "" {
+		*user = *userLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+
+	files := getComposeFiles(config)
+	compose, err := readComposeFiles(files)
+	if err != nil {
+		log.Fatalf("❌ Error reading the compose file, start the services with 'fleet up' first: %v", err)
+	}
+	target, err := resolveExecService(config, compose, fs.Arg(0))
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	tty := !*noTTY && isInteractiveTerminal() && isOutputTerminal()
+	if err := runDocker(getExecArgs(files, target, *user, tty, fs.Args()[1:])); err != nil {
+		// The exit code of the command is the exit code of fleet exec
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		log.Fatalf("❌ Error running the command in %s: %v", target, err)
+	}
+}
@@ -0,0 +1,64 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExecCommandTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *ExecCommandTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *ExecCommandTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *ExecCommandTestSuite) TestResolveExecService() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Database: "postgres:16"},
+		{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "shop"},
+	}}
+	compose := generateDockerCompose(config)
+
+	testCases := map[string]string{
+		"api":         "api",
+		"shop":        "shop-php",
+		"postgres-16": "postgres-16",
+	}
+	for name, expected := range testCases {
+		target, err := resolveExecService(config, compose, name)
+		suite.NoError(err, name)
+		suite.Equal(expected, target, name)
+	}
+
+	_, err := resolveExecService(config, compose, "worker")
+	suite.ErrorContains(err, "unknown service worker")
+	suite.ErrorContains(err, "api, ")
+}
+
+func (suite *ExecCommandTestSuite) TestGetExecArgs() {
+	files := ComposeFiles{ProjectDir: ".fleet", Files: []string{".fleet/docker-compose.yml"}}
+
+	args := getExecArgs(files, "api", "", true, nil)
+	suite.Equal(append(composeArgs(files, "exec", "api"), defaultExecShell...), args, "Opens a shell")
+
+	args = getExecArgs(files, "shop-php", "root", false, []string{"ls", "-la"})
+	suite.Equal(composeArgs(files, "exec", "-T", "--user", "root", "shop-php", "ls", "-la"), args)
+}
+
+func TestExecCommandSuite(t *testing.T) {
+	suite.Run(t, new(ExecCommandTestSuite))
+}
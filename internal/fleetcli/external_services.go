@@ -0,0 +1,188 @@
+package fleetcli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExternalServiceTarget is a running container of another Fleet project
+type ExternalServiceTarget struct {
+	Container string
+	Networks  []string
+	Port      int
+}
+
+// parseExternalServiceRef parses an external_service reference like "other-project:api"
+func parseExternalServiceRef(ref string) (project string, service string, err error) {
+	parts := strings.SplitN(ref, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid external_service %q (expected project:service)", ref)
+	}
+	return parts[0], parts[1], nil
+}
+
+// isExternalService checks if a service only points at another project's service
+func isExternalService(svc *Service) bool {
+	return svc.ExternalService != ""
+}
+
+// validateExternalService checks the external_service settings of a service
+func validateExternalService(svc *Service) error {
+	if svc.ExternalService == "" {
+		return nil
+	}
+	if _, _, err := parseExternalServiceRef(svc.ExternalService); err != nil {
+		return fmt.Errorf("service %s: %w", svc.Name, err)
+	}
+	if svc.Image != "" || svc.Build != "" || svc.Runtime != "" || len(getConfiguredDomains(svc)) > 0 {
+		return fmt.Errorf("service %s: 'external_service' cannot be combined with 'image', 'build', 'runtime' or 'domain(s)'", svc.Name)
+	}
+	return nil
+}
+
+// parseExternalServiceList parses `docker ps --format '{{.Names}}\t{{.Networks}}\t{{.Label "com.fleet.port"}}'` output
+func parseExternalServiceList(output string) *ExternalServiceTarget {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+
+		target := &ExternalServiceTarget{
+			Container: fields[0],
+			Networks:  strings.Split(fields[1], ","),
+		}
+		if len(fields) > 2 {
+			target.Port, _ = strconv.Atoi(fields[2])
+		}
+		return target
+	}
+	return nil
+}
+
+// lookupExternalService finds the running container of a service in another Fleet project
+var lookupExternalService = func(project, service string) (*ExternalServiceTarget, error) {
+	output, err := newCommand("docker", "ps",
+		"--filter", fmt.Sprintf("label=%s=%s", fleetProjectLabel, project),
+		"--filter", fmt.Sprintf("label=%s=%s", fleetServiceLabel, service),
+		"--format", fmt.Sprintf("{{.Names}}\t{{.Networks}}\t{{.Label %q}}", fleetPortLabel),
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query containers: %w", err)
+	}
+
+	target := parseExternalServiceList(string(output))
+	if target == nil {
+		return nil, fmt.Errorf("%s:%s is not running (start it with 'fleet up' in that project)", project, service)
+	}
+	return target, nil
+}
+
+// labelProjectService labels a generated container so other projects can find it
+func labelProjectService(compose *DockerCompose, svc *Service, project string) {
+	service, exists := compose.Services[svc.Name]
+	if !exists {
+		return
+	}
+
+	labels := make(map[string]string, len(service.Labels)+3)
+	for k, v := range service.Labels {
+		labels[k] = v
+	}
+	labels[fleetProjectLabel] = project
+	labels[fleetServiceLabel] = svc.Name
+	if svc.Port > 0 {
+		labels[fleetPortLabel] = strconv.Itoa(svc.Port)
+	}
+	service.Labels = labels
+	compose.Services[svc.Name] = service
+}
+
+// addExternalServices wires services to the containers of other projects they reference.
+// Dependents get <NAME>_HOST, <NAME>_PORT and <NAME>_URL and join the target's networks.
+func addExternalServices(compose *DockerCompose, config *Config) {
+	for i := range config.Services {
+		external := &config.Services[i]
+		if !isExternalService(external) {
+			continue
+		}
+
+		// External services are not containers of this project
+		delete(compose.Services, external.Name)
+
+		project, serviceName, _ := parseExternalServiceRef(external.ExternalService)
+		target, err := lookupExternalService(project, serviceName)
+		if err != nil {
+			warnf("⚠️  Warning: external service %s: %v\n", external.Name, err)
+		}
+
+		prefix := getServiceEnvPrefix(external.Name)
+		vars := map[string]string{}
+		if target != nil {
+			port := target.Port
+			if external.Port > 0 {
+				port = external.Port
+			}
+			vars[prefix+"_HOST"] = target.Container
+			if port > 0 {
+				vars[prefix+"_PORT"] = strconv.Itoa(port)
+				vars[prefix+"_URL"] = fmt.Sprintf("http://%s:%d", target.Container, port)
+			}
+		}
+
+		for _, svc := range config.Services {
+			if !containsString(svc.Needs, external.Name) {
+				continue
+			}
+
+			for _, name := range []string{svc.Name, fmt.Sprintf("%s-php", svc.Name)} {
+				service, exists := compose.Services[name]
+				if !exists {
+					continue
+				}
+				service.DependsOn = removeString(service.DependsOn, external.Name)
+				if target != nil {
+					service.Networks = attachExternalNetworks(compose, service.Networks, target.Networks)
+				}
+				compose.Services[name] = service
+				mergeServiceEnvironment(compose, name, vars)
+			}
+		}
+	}
+}
+
+// attachExternalNetworks declares the target's networks as external and joins them
+func attachExternalNetworks(compose *DockerCompose, networks []string, targetNetworks []string) []string {
+	sorted := append([]string{}, targetNetworks...)
+	sort.Strings(sorted)
+
+	for _, network := range sorted {
+		if network == "" {
+			continue
+		}
+		// Our own network is already attached under its compose key
+		if network == fmt.Sprintf("%s_fleet-network", composeProjectName) {
+			continue
+		}
+		if _, exists := compose.Networks[network]; !exists {
+			compose.Networks[network] = DockerNetwork{Name: network, External: true}
+		}
+		if !containsString(networks, network) {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// removeString returns the slice without any occurrence of value
+func removeString(slice []string, value string) []string {
+	var result []string
+	for _, item := range slice {
+		if item != value {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,189 @@
+package fleetcli
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ExternalServicesTestSuite struct {
+	suite.Suite
+	helper         *TestHelper
+	originalDir    string
+	originalLookup func(project, service string) (*ExternalServiceTarget, error)
+}
+
+func (suite *ExternalServicesTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+
+	suite.originalDir, _ = os.Getwd()
+	suite.Require().NoError(os.Chdir(suite.helper.TempDir()))
+
+	suite.originalLookup = lookupExternalService
+}
+
+func (suite *ExternalServicesTestSuite) TearDownTest() {
+	lookupExternalService = suite.originalLookup
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *ExternalServicesTestSuite) TestParseExternalServiceRef() {
+	tests := []struct {
+		ref     string
+		project string
+		service string
+		wantErr bool
+	}{
+		{"billing:api", "billing", "api", false},
+		{"billing:api:v2", "billing", "api:v2", false},
+		{"billing", "", "", true},
+		{":api", "", "", true},
+		{"billing:", "", "", true},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.ref, func() {
+			project, service, err := parseExternalServiceRef(tt.ref)
+			if tt.wantErr {
+				suite.Error(err)
+				return
+			}
+			suite.NoError(err)
+			suite.Equal(tt.project, project)
+			suite.Equal(tt.service, service)
+		})
+	}
+}
+
+func (suite *ExternalServicesTestSuite) TestValidateExternalService() {
+	suite.NoError(validateExternalService(&Service{Name: "billing", ExternalService: "billing:api"}))
+	suite.NoError(validateExternalService(&Service{Name: "web", Image: "nginx"}))
+
+	err := validateExternalService(&Service{Name: "billing", ExternalService: "billing"})
+	suite.Error(err)
+	suite.Contains(err.Error(), "project:service")
+
+	err = validateExternalService(&Service{Name: "billing", ExternalService: "billing:api", Image: "nginx"})
+	suite.Error(err)
+	suite.Contains(err.Error(), "cannot be combined")
+}
+
+func (suite *ExternalServicesTestSuite) TestParseExternalServiceList() {
+	target := parseExternalServiceList("fleet-api-1\tfleet_fleet-network,billing-net\t8080\n")
+	suite.Require().NotNil(target)
+	suite.Equal("fleet-api-1", target.Container)
+	suite.Equal([]string{"fleet_fleet-network", "billing-net"}, target.Networks)
+	suite.Equal(8080, target.Port)
+
+	suite.Nil(parseExternalServiceList("\n"))
+}
+
+func (suite *ExternalServicesTestSuite) TestGeneratedServicesAreLabeled() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "api", Image: "node:20", Port: 3000},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	labels := compose.Services["api"].Labels
+	suite.Equal("shop", labels[fleetProjectLabel])
+	suite.Equal("api", labels[fleetServiceLabel])
+	suite.Equal("3000", labels[fleetPortLabel])
+}
+
+func (suite *ExternalServicesTestSuite) TestExternalServiceIsInjected() {
+	var lookedUp []string
+	lookupExternalService = func(project, service string) (*ExternalServiceTarget, error) {
+		lookedUp = append(lookedUp, project+":"+service)
+		return &ExternalServiceTarget{
+			Container: "fleet-api-1",
+			Networks:  []string{"fleet_fleet-network", "billing-net"},
+			Port:      8080,
+		}, nil
+	}
+
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "billing", ExternalService: "billing:api"},
+			{
+				Name:        "web",
+				Image:       "node:20",
+				Needs:       []string{"billing"},
+				Environment: map[string]string{"BILLING_URL": "http://override"},
+			},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	suite.Equal([]string{"billing:api"}, lookedUp)
+	_, exists := compose.Services["billing"]
+	suite.False(exists, "external services should not become containers")
+
+	web := compose.Services["web"]
+	suite.NotContains(web.DependsOn, "billing")
+	suite.Equal("fleet-api-1", web.Environment["BILLING_HOST"])
+	suite.Equal("8080", web.Environment["BILLING_PORT"])
+	suite.Equal("http://override", web.Environment["BILLING_URL"], "explicit env should win")
+	suite.Equal("http://override", config.Services[1].Environment["BILLING_URL"])
+	suite.Len(config.Services[1].Environment, 1, "config environment should not be modified")
+
+	// Only networks outside our own are attached
+	suite.Contains(web.Networks, "billing-net")
+	suite.NotContains(web.Networks, "fleet_fleet-network")
+	network, exists := compose.Networks["billing-net"]
+	suite.Require().True(exists)
+	suite.True(network.External)
+	suite.Equal("billing-net", network.Name)
+}
+
+func (suite *ExternalServicesTestSuite) TestExternalServicePortOverride() {
+	lookupExternalService = func(project, service string) (*ExternalServiceTarget, error) {
+		return &ExternalServiceTarget{Container: "fleet-api-1", Port: 8080}, nil
+	}
+
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "billing-api", ExternalService: "billing:api", Port: 9000},
+			{Name: "web", Image: "node:20", Needs: []string{"billing-api"}},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	suite.Equal("http://fleet-api-1:9000", compose.Services["web"].Environment["BILLING_API_URL"])
+}
+
+func (suite *ExternalServicesTestSuite) TestExternalServiceNotRunning() {
+	lookupExternalService = func(project, service string) (*ExternalServiceTarget, error) {
+		return nil, errors.New("billing:api is not running")
+	}
+
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "billing", ExternalService: "billing:api"},
+			{Name: "web", Image: "node:20", Needs: []string{"billing"}},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	web := compose.Services["web"]
+	suite.NotContains(web.DependsOn, "billing")
+	suite.NotContains(web.Environment, "BILLING_HOST")
+}
+
+func TestExternalServicesSuite(t *testing.T) {
+	suite.Run(t, new(ExternalServicesTestSuite))
+}
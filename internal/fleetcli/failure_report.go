@@ -0,0 +1,157 @@
+package fleetcli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Service states shown in the startup failure report
+const (
+	serviceStatusMissing = "not created"
+	serviceStatusCreated = "created"
+	serviceStatusStarted = "started"
+	serviceStatusHealthy = "healthy"
+	serviceStatusFailed  = "failed"
+)
+
+// classifyServiceState reduces a compose ps row to a startup state
+func classifyServiceState(entry ComposePSEntry) string {
+	switch entry.State {
+	case "created":
+		return serviceStatusCreated
+	case "running":
+		switch entry.Health {
+		case "healthy":
+			return serviceStatusHealthy
+		case "unhealthy":
+			return serviceStatusFailed
+		}
+		return serviceStatusStarted
+	case "exited":
+		if entry.ExitCode == 0 {
+			return serviceStatusStarted
+		}
+		return serviceStatusFailed
+	}
+	// dead, restarting, removing
+	return serviceStatusFailed
+}
+
+// collectServiceStatuses returns the startup state of every service in the compose file
+func collectServiceStatuses(compose *DockerCompose, entries []ComposePSEntry) map[string]string {
+	statuses := make(map[string]string, len(compose.Services))
+	for name := range compose.Services {
+		statuses[name] = serviceStatusMissing
+	}
+	for _, entry := range entries {
+		status := classifyServiceState(entry)
+		// A service is only as good as its worst replica
+		if current, ok := statuses[entry.Service]; !ok || current == serviceStatusMissing || status == serviceStatusFailed {
+			statuses[entry.Service] = status
+		}
+	}
+	return statuses
+}
+
+// hasFailedDependency reports whether any transitive dependency of a service failed
+func hasFailedDependency(compose *DockerCompose, statuses map[string]string, name string, seen map[string]bool) bool {
+	for _, dep := range compose.Services[name].DependsOn {
+		if seen[dep] {
+			continue
+		}
+		seen[dep] = true
+		if statuses[dep] == serviceStatusFailed || hasFailedDependency(compose, statuses, dep, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// findFailingChain returns the first failed service whose own dependencies are fine,
+// followed by the services that could not start because of it
+func findFailingChain(compose *DockerCompose, statuses map[string]string) []string {
+	var roots []string
+	for name, status := range statuses {
+		if status == serviceStatusFailed && !hasFailedDependency(compose, statuses, name, map[string]bool{}) {
+			roots = append(roots, name)
+		}
+	}
+	if len(roots) == 0 {
+		return nil
+	}
+	sort.Strings(roots)
+
+	reverse := reverseDependencies(compose)
+	chain := []string{roots[0]}
+	visited := map[string]bool{roots[0]: true}
+	for current := roots[0]; ; {
+		next := ""
+		for _, dependent := range reverse[current] {
+			status := statuses[dependent]
+			if visited[dependent] || status == serviceStatusStarted || status == serviceStatusHealthy {
+				continue
+			}
+			next = dependent
+			break
+		}
+		if next == "" {
+			return chain
+		}
+		chain = append(chain, next)
+		visited[next] = true
+		current = next
+	}
+}
+
+// printServiceFailureReport prints the state of every service and the chain that broke startup
+func printServiceFailureReport(out io.Writer, compose *DockerCompose, entries []ComposePSEntry) {
+	statuses := collectServiceStatuses(compose, entries)
+
+	details := make(map[string]string)
+	for _, entry := range entries {
+		if details[entry.Service] == "" || classifyServiceState(entry) == serviceStatusFailed {
+			details[entry.Service] = entry.Status
+		}
+	}
+
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(out, "\n📋 Service status:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  SERVICE\tSTATUS\tDETAILS")
+	for _, name := range names {
+		marker := ""
+		if statuses[name] == serviceStatusFailed {
+			marker = " ❌"
+		}
+		fmt.Fprintf(w, "  %s\t%s%s\t%s\n", name, statuses[name], marker, details[name])
+	}
+	w.Flush()
+
+	chain := findFailingChain(compose, statuses)
+	if len(chain) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "\n🔗 Failing dependency chain: %s\n", strings.Join(chain, " → "))
+	fmt.Fprintf(out, "💡 Run 'fleet logs %s' to see why it failed\n", chain[0])
+}
+
+// reportComposeFailure prints a per-service breakdown after compose up fails.
+// It returns false when compose could not be queried, so the caller can fall back to the raw error.
+func reportComposeFailure(compose *DockerCompose, composeFiles ComposeFiles) bool {
+	entries, err := getComposeServiceStates(composeFiles)
+	if err != nil || len(entries) == 0 {
+		return false
+	}
+	printServiceFailureReport(os.Stdout, compose, entries)
+	return true
+}
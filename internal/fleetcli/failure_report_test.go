@@ -0,0 +1,115 @@
+package fleetcli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FailureReportTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+}
+
+func (suite *FailureReportTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+}
+
+func (suite *FailureReportTestSuite) TearDownTest() {
+	suite.helper.Cleanup()
+}
+
+// failingStack is nginx -> app -> database, with a worker that doesn't need the database
+func (suite *FailureReportTestSuite) failingStack() *DockerCompose {
+	return &DockerCompose{
+		Services: map[string]DockerService{
+			"database": {Image: "mysql:8.0"},
+			"cache":    {Image: "redis:7"},
+			"app":      {Image: "php:8.3-fpm", DependsOn: []string{"database", "cache"}},
+			"nginx":    {Image: "nginx:alpine", DependsOn: []string{"app"}},
+			"worker":   {Image: "php:8.3-cli", DependsOn: []string{"cache"}},
+		},
+	}
+}
+
+func (suite *FailureReportTestSuite) TestClassifyServiceState() {
+	tests := []struct {
+		name     string
+		entry    ComposePSEntry
+		expected string
+	}{
+		{"created", ComposePSEntry{State: "created"}, serviceStatusCreated},
+		{"running", ComposePSEntry{State: "running"}, serviceStatusStarted},
+		{"starting", ComposePSEntry{State: "running", Health: "starting"}, serviceStatusStarted},
+		{"healthy", ComposePSEntry{State: "running", Health: "healthy"}, serviceStatusHealthy},
+		{"unhealthy", ComposePSEntry{State: "running", Health: "unhealthy"}, serviceStatusFailed},
+		{"completed", ComposePSEntry{State: "exited", ExitCode: 0}, serviceStatusStarted},
+		{"crashed", ComposePSEntry{State: "exited", ExitCode: 1}, serviceStatusFailed},
+		{"restarting", ComposePSEntry{State: "restarting"}, serviceStatusFailed},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			suite.Equal(tt.expected, classifyServiceState(tt.entry))
+		})
+	}
+}
+
+func (suite *FailureReportTestSuite) TestFindFailingChain() {
+	compose := suite.failingStack()
+	statuses := map[string]string{
+		"database": serviceStatusFailed,
+		"cache":    serviceStatusHealthy,
+		"app":      serviceStatusCreated,
+		"nginx":    serviceStatusCreated,
+		"worker":   serviceStatusStarted,
+	}
+
+	suite.Equal([]string{"database", "app", "nginx"}, findFailingChain(compose, statuses))
+}
+
+func (suite *FailureReportTestSuite) TestFindFailingChainSkipsDownstreamFailures() {
+	compose := suite.failingStack()
+	statuses := map[string]string{
+		"database": serviceStatusFailed,
+		"cache":    serviceStatusHealthy,
+		"app":      serviceStatusFailed,
+		"nginx":    serviceStatusMissing,
+		"worker":   serviceStatusStarted,
+	}
+
+	// app failed only because the database did, so the chain starts at the database
+	suite.Equal([]string{"database", "app", "nginx"}, findFailingChain(compose, statuses))
+}
+
+func (suite *FailureReportTestSuite) TestFindFailingChainWithoutFailures() {
+	compose := suite.failingStack()
+	statuses := collectServiceStatuses(compose, nil)
+
+	suite.Nil(findFailingChain(compose, statuses))
+}
+
+func (suite *FailureReportTestSuite) TestPrintServiceFailureReport() {
+	compose := suite.failingStack()
+	entries := []ComposePSEntry{
+		{Service: "database", State: "running", Health: "unhealthy", Status: "Up 30 seconds (unhealthy)"},
+		{Service: "cache", State: "running", Health: "healthy", Status: "Up 31 seconds (healthy)"},
+		{Service: "app", State: "created", Status: "Created"},
+		{Service: "worker", State: "running", Status: "Up 29 seconds"},
+	}
+
+	var out bytes.Buffer
+	printServiceFailureReport(&out, compose, entries)
+
+	report := out.String()
+	suite.Contains(report, "Up 30 seconds (unhealthy)")
+	suite.Regexp(`database\s+failed ❌`, report)
+	suite.Regexp(`nginx\s+not created`, report)
+	suite.Contains(report, "database → app → nginx")
+	suite.Contains(report, "fleet logs database")
+}
+
+func TestFailureReportSuite(t *testing.T) {
+	suite.Run(t, new(FailureReportTestSuite))
+}
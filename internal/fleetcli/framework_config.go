@@ -0,0 +1,282 @@
+package fleetcli
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Markers of the section Fleet manages in an env file of the app, lines around it are kept
+const (
+	frameworkConfigStart = "# Fleet - START"
+	frameworkConfigEnd   = "# Fleet - END"
+)
+
+// wordpressConfigFile is loaded by wp-config.php, Fleet owns all of it
+const wordpressConfigFile = "wp-config-fleet.php"
+
+// frameworkConfigFiles are the files framework_config writes into the folder of a service
+var frameworkConfigFiles = map[string]string{
+	"symfony":   ".env.local",
+	"wordpress": wordpressConfigFile,
+	"nextjs":    ".env.local",
+	"nuxt":      ".env",
+}
+
+// frameworkServerVars are the injected variables passed on to the server side of Node.js
+// frameworks, in the order they are written
+var frameworkServerVars = []string{
+	"DATABASE_URL", "MONGODB_URI", "REDIS_URL",
+	"SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD",
+	"S3_ENDPOINT", "S3_REGION", "S3_BUCKET", "S3_ACCESS_KEY", "S3_SECRET_KEY",
+	"MEILISEARCH_URL", "TYPESENSE_URL", "AMQP_URL", "KAFKA_BROKERS",
+}
+
+// getServiceFramework returns the framework of a service, detected from its folder
+// when not set
+func getServiceFramework(svc *Service) string {
+	if svc.Framework != "" {
+		return strings.ToLower(svc.Framework)
+	}
+	switch {
+	case strings.HasPrefix(svc.Runtime, "php"):
+		return detectPHPFramework(svc.hostPath(svc.Folder))
+	case strings.HasPrefix(svc.Runtime, "node"):
+		return detectNodeFramework(svc.hostPath(svc.Folder))
+	}
+	return ""
+}
+
+// validateFrameworkConfig checks that a service with framework_config has a folder and
+// a framework Fleet writes config for
+func validateFrameworkConfig(svc *Service) error {
+	if !svc.FrameworkConfig {
+		return nil
+	}
+	if svc.Folder == "" {
+		return fmt.Errorf("service %s: framework_config needs a folder to write to", svc.Name)
+	}
+	supported := sortedKeys(frameworkConfigFiles)
+	framework := getServiceFramework(svc)
+	if framework == "" {
+		return fmt.Errorf("service %s: framework_config: no framework detected in %s, set framework (supported: %s)", svc.Name, svc.Folder, strings.Join(supported, ", "))
+	}
+	if _, ok := frameworkConfigFiles[framework]; !ok {
+		return fmt.Errorf("service %s: framework_config doesn't support %s (supported: %s)", svc.Name, framework, strings.Join(supported, ", "))
+	}
+	return nil
+}
+
+// getFrameworkSiteURL returns the URL the browser reaches a service on, including the
+// path it is routed on
+func getFrameworkSiteURL(svc *Service, config *Config) string {
+	domain := getSiteDomain(svc)
+	prefix := strings.TrimSuffix(getRoutePath(svc), "/")
+	switch {
+	case domain == "":
+		return ""
+	case config.Cloud && svc.Port > 0:
+		return getForwardedURL(svc.Port)
+	case config.Unprivileged:
+		return fmt.Sprintf("http://%s:%d%s", getLocalhostAlias(svc), unprivilegedHTTPPort, prefix)
+	case svc.SSL:
+		return "https://" + domain + prefix
+	}
+	return "http://" + domain + prefix
+}
+
+// planFrameworkConfig plans the config file of the service's framework with the
+// variables its attached services injected, for frameworks that read their settings
+// from files instead of the environment
+func planFrameworkConfig(compose *DockerCompose, svc *Service, config *Config, existing map[string]bool) {
+	if !svc.FrameworkConfig || svc.Folder == "" {
+		return
+	}
+	framework := getServiceFramework(svc)
+	fileName, ok := frameworkConfigFiles[framework]
+	if !ok {
+		return
+	}
+
+	injected := make(map[string]string)
+	for key, value := range compose.Services[svc.Name].Environment {
+		if !existing[key] {
+			injected[key] = value
+		}
+	}
+	siteURL := getFrameworkSiteURL(svc, config)
+
+	path := filepath.Join(svc.Folder, fileName)
+	if framework == "wordpress" {
+		getArtifactPlan(compose).addFile(path, []byte(getWordPressConfig(injected, siteURL)), getFrameworkConfigMode(svc.hostPath(path)))
+		return
+	}
+
+	var vars []string
+	switch framework {
+	case "symfony":
+		vars = getSymfonyEnv(svc, injected, siteURL)
+	case "nextjs":
+		vars = getPrefixedEnv(injected, "", "NEXT_PUBLIC_APP_URL", siteURL)
+	case "nuxt":
+		vars = getPrefixedEnv(injected, "NUXT_", "NUXT_PUBLIC_SITE_URL", siteURL)
+	}
+
+	current, _ := os.ReadFile(svc.hostPath(path))
+	content := replaceFrameworkConfigSection(string(current), vars)
+	getArtifactPlan(compose).addFile(path, []byte(content), getFrameworkConfigMode(svc.hostPath(path)))
+}
+
+// getFrameworkConfigMode keeps the permissions of an existing file. New files are
+// readable by the user the app runs as in its container.
+func getFrameworkConfigMode(path string) os.FileMode {
+	if info, err := os.Stat(path); err == nil {
+		return info.Mode().Perm()
+	}
+	return 0644
+}
+
+// getSymfonyEnv returns the variables of the Symfony recipes of the attached services
+func getSymfonyEnv(svc *Service, env map[string]string, siteURL string) []string {
+	var lines []string
+	add := func(key, value string) {
+		if value != "" {
+			lines = append(lines, formatEnvFileLine(key, value))
+		}
+	}
+
+	add("DEFAULT_URI", siteURL)
+	add("DATABASE_URL", getDoctrineDatabaseURL(svc, env))
+	add("MONGODB_URL", env["MONGODB_URI"])
+	add("MONGODB_DB", env["MONGO_DB"])
+	add("REDIS_URL", env["REDIS_URL"])
+	if host := env["MAIL_HOST"]; host != "" {
+		dsn := url.URL{Scheme: "smtp", Host: host + ":" + env["MAIL_PORT"]}
+		if env["MAIL_USERNAME"] != "" {
+			dsn.User = url.UserPassword(env["MAIL_USERNAME"], env["MAIL_PASSWORD"])
+		}
+		add("MAILER_DSN", dsn.String())
+	}
+	if amqp := env["AMQP_URL"]; amqp != "" {
+		add("MESSENGER_TRANSPORT_DSN", amqp+"/%2f/messages")
+	}
+	return lines
+}
+
+// getDoctrineDatabaseURL returns DATABASE_URL the way Doctrine expects it, with the
+// server version of the database image
+func getDoctrineDatabaseURL(svc *Service, env map[string]string) string {
+	if env["DB_HOST"] == "" {
+		return ""
+	}
+	dbType, version, _ := strings.Cut(svc.Database, ":")
+
+	scheme := "mysql"
+	query := url.Values{"charset": {"utf8mb4"}}
+	switch dbType {
+	case "postgres":
+		scheme = "postgresql"
+		query.Set("charset", "utf8")
+		if version != "" {
+			query.Set("serverVersion", version)
+		}
+	case "mariadb":
+		if version != "" {
+			query.Set("serverVersion", version+"-MariaDB")
+		}
+	default:
+		if version != "" {
+			query.Set("serverVersion", version)
+		}
+	}
+
+	dsn := url.URL{
+		Scheme:   scheme,
+		User:     url.UserPassword(env["DB_USERNAME"], env["DB_PASSWORD"]),
+		Host:     env["DB_HOST"] + ":" + env["DB_PORT"],
+		Path:     "/" + env["DB_DATABASE"],
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
+
+// getPrefixedEnv returns the server variables of a Node.js framework with a prefix, and
+// the public URL of the site
+func getPrefixedEnv(env map[string]string, prefix, siteURLKey, siteURL string) []string {
+	var lines []string
+	if siteURL != "" {
+		lines = append(lines, formatEnvFileLine(siteURLKey, siteURL))
+	}
+	for _, key := range frameworkServerVars {
+		if value := env[key]; value != "" {
+			lines = append(lines, formatEnvFileLine(prefix+key, value))
+		}
+	}
+	return lines
+}
+
+// replaceFrameworkConfigSection replaces the section Fleet manages in an env file, or
+// appends it. Lines outside of it are the app's own and are kept.
+func replaceFrameworkConfigSection(content string, vars []string) string {
+	var kept []string
+	inSection := false
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		switch {
+		case strings.TrimSpace(line) == frameworkConfigStart:
+			inSection = true
+		case strings.TrimSpace(line) == frameworkConfigEnd:
+			inSection = false
+		case !inSection:
+			kept = append(kept, line)
+		}
+	}
+	for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+		kept = kept[:len(kept)-1]
+	}
+
+	var out strings.Builder
+	if len(kept) > 0 {
+		out.WriteString(strings.Join(kept, "\n") + "\n\n")
+	}
+	out.WriteString(frameworkConfigStart + "\n")
+	out.WriteString("# Generated by Fleet from the services of fleet.toml, changes are overwritten\n")
+	for _, line := range vars {
+		out.WriteString(line + "\n")
+	}
+	out.WriteString(frameworkConfigEnd + "\n")
+	return out.String()
+}
+
+// getWordPressConfig returns the constants of the database, site URL and Redis cache
+// of a WordPress site. Constants wp-config.php defined first are kept.
+func getWordPressConfig(env map[string]string, siteURL string) string {
+	var out strings.Builder
+	out.WriteString("<?php\n")
+	out.WriteString("// Generated by Fleet from the services of fleet.toml, changes are overwritten.\n")
+	out.WriteString("// Load it at the top of wp-config.php: require_once __DIR__ . '/" + wordpressConfigFile + "';\n")
+
+	define := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&out, "defined('%s') || define('%s', %s);\n", name, name, quotePHPString(value))
+		}
+	}
+	if env["DB_CONNECTION"] == "mysql" || env["DB_CONNECTION"] == "mariadb" {
+		define("DB_NAME", env["DB_DATABASE"])
+		define("DB_USER", env["DB_USERNAME"])
+		define("DB_PASSWORD", env["DB_PASSWORD"])
+		define("DB_HOST", env["DB_HOST"]+":"+env["DB_PORT"])
+	}
+	define("WP_HOME", siteURL)
+	define("WP_SITEURL", siteURL)
+	define("WP_REDIS_HOST", env["REDIS_HOST"])
+	define("WP_REDIS_PORT", env["REDIS_PORT"])
+	define("WP_REDIS_PASSWORD", env["REDIS_PASSWORD"])
+	return out.String()
+}
+
+// quotePHPString returns a single quoted PHP string literal
+func quotePHPString(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
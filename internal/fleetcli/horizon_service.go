@@ -0,0 +1,61 @@
+package fleetcli
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	horizonCommand = "php artisan horizon"
+	// horizonStopGracePeriod is how long fleet down waits for Horizon to finish the
+	// jobs it is running after SIGTERM, before Docker kills the workers. It matches the
+	// default timeout of Horizon supervisors.
+	horizonStopGracePeriod = "60s"
+)
+
+// getHorizonServiceName returns the compose service running the Horizon workers of a service
+func getHorizonServiceName(serviceName string) string {
+	return fmt.Sprintf("%s-horizon", serviceName)
+}
+
+// validateHorizon checks a service with horizon is a Laravel app using Redis
+func validateHorizon(svc *Service) error {
+	if !svc.Horizon {
+		return nil
+	}
+	if !strings.HasPrefix(svc.Runtime, "php") || svc.Folder == "" {
+		return fmt.Errorf("service %s: horizon runs the code of a PHP service, set 'runtime' and 'folder'", svc.Name)
+	}
+	framework := svc.Framework
+	if framework == "" {
+		framework = detectPHPFramework(svc.hostPath(svc.Folder))
+	}
+	if framework != "laravel" {
+		return fmt.Errorf("service %s: horizon requires the laravel framework", svc.Name)
+	}
+	if cacheType, _ := parseCacheType(svc.Cache); cacheType != "redis" {
+		return fmt.Errorf("service %s: horizon stores its queues in Redis, add cache = \"redis\"", svc.Name)
+	}
+	return nil
+}
+
+// addHorizonService runs the Horizon workers of a Laravel service in a container of their
+// own, with the image, code and environment of the app
+func addHorizonService(compose *DockerCompose, svc *Service) {
+	if !svc.Horizon {
+		return
+	}
+	horizon, ok := newWorkerService(compose, svc, horizonCommand)
+	if !ok {
+		return
+	}
+	if horizon.WorkingDir == "" {
+		horizon.WorkingDir = "/var/www/html"
+	}
+	// Horizon stops taking jobs on SIGTERM and exits once running ones are done. It is
+	// restarted when it exits otherwise, like after horizon:terminate.
+	horizon.StopSignal = "SIGTERM"
+	horizon.StopGracePeriod = horizonStopGracePeriod
+
+	compose.Services[getHorizonServiceName(svc.Name)] = horizon
+}
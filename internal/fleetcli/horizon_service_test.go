@@ -0,0 +1,115 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+)
+
+type HorizonServiceTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *HorizonServiceTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *HorizonServiceTestSuite) TearDownTest() {
+	os.Chdir(suite.originalDir)
+	suite.helper.Cleanup()
+}
+
+func (suite *HorizonServiceTestSuite) TestValidateHorizon() {
+	suite.NoError(validateHorizon(&Service{Name: "app"}))
+	suite.NoError(validateHorizon(&Service{Name: "app", Runtime: "php:8.3", Folder: "app", Framework: "laravel", Cache: "redis:7.2", Horizon: true}))
+
+	testCases := []struct {
+		name    string
+		svc     Service
+		wantErr string
+	}{
+		{"not PHP", Service{Runtime: "node:20", Folder: "app", Framework: "laravel", Cache: "redis"}, "runs the code of a PHP service"},
+		{"no folder", Service{Runtime: "php:8.3", Framework: "laravel", Cache: "redis"}, "runs the code of a PHP service"},
+		{"not Laravel", Service{Runtime: "php:8.3", Folder: "app", Framework: "symfony", Cache: "redis"}, "requires the laravel framework"},
+		{"memcached", Service{Runtime: "php:8.3", Folder: "app", Framework: "laravel", Cache: "memcached"}, "add cache = \"redis\""},
+		{"no cache", Service{Runtime: "php:8.3", Folder: "app", Framework: "laravel"}, "add cache = \"redis\""},
+	}
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.svc.Name = "app"
+			tc.svc.Horizon = true
+			err := validateHorizon(&tc.svc)
+			suite.Require().Error(err)
+			suite.Contains(err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func (suite *HorizonServiceTestSuite) TestHorizonService() {
+	config := &Config{Project: "test", Services: []Service{{
+		Name:        "app",
+		Image:       "nginx:alpine",
+		Runtime:     "php:8.3",
+		Framework:   "laravel",
+		Folder:      "app",
+		Cache:       "redis:7.2",
+		Horizon:     true,
+		Environment: map[string]string{"APP_NAME": "Shop"},
+	}}}
+
+	compose := generateDockerCompose(config)
+	suite.Require().Contains(compose.Services, "app-horizon")
+	horizon := compose.Services["app-horizon"]
+	php := compose.Services["app-php"]
+
+	suite.Equal(php.Image, horizon.Image)
+	suite.Equal(php.Volumes, horizon.Volumes)
+	suite.Equal("php artisan horizon", horizon.Command)
+	suite.Equal("/var/www/html", horizon.WorkingDir)
+	suite.Equal("unless-stopped", horizon.Restart)
+	suite.Equal("SIGTERM", horizon.StopSignal)
+	suite.Equal(horizonStopGracePeriod, horizon.StopGracePeriod)
+	suite.Nil(horizon.HealthCheck)
+	suite.Empty(horizon.Ports)
+
+	// Variables of the app and of Redis, which is set on the nginx container
+	suite.Equal("Shop", horizon.Environment["APP_NAME"])
+	suite.Equal("redis-72", horizon.Environment["REDIS_HOST"])
+	suite.Equal("redis", horizon.Environment["QUEUE_CONNECTION"])
+	suite.Equal([]string{"redis-72"}, horizon.DependsOn)
+
+	// Editing the worker leaves the app alone
+	horizon.Volumes[0] = "changed"
+	suite.NotEqual("changed", compose.Services["app-php"].Volumes[0])
+
+	data, err := yaml.Marshal(compose.Services["app-horizon"])
+	suite.Require().NoError(err)
+	suite.Contains(string(data), "stop_grace_period: 60s")
+	suite.Contains(string(data), "stop_signal: SIGTERM")
+}
+
+func (suite *HorizonServiceTestSuite) TestHorizonDisabled() {
+	config := &Config{Project: "test", Services: []Service{{
+		Name: "app", Image: "nginx:alpine", Runtime: "php:8.3", Framework: "laravel", Folder: "app", Cache: "redis",
+	}}}
+	suite.NotContains(generateDockerCompose(config).Services, "app-horizon")
+}
+
+func (suite *HorizonServiceTestSuite) TestStatusGroup() {
+	config := &Config{Services: []Service{{Name: "app", Horizon: true}}}
+	compose := &DockerCompose{Services: map[string]DockerService{"app": {}, "app-horizon": {}}}
+
+	groups := groupStatusServices(config, compose)
+	suite.Require().Len(groups, 1)
+	suite.Equal([]StatusMember{{"app-horizon", statusRoleHorizon}}, groups[0].Sidecars)
+}
+
+func TestHorizonServiceSuite(t *testing.T) {
+	suite.Run(t, new(HorizonServiceTestSuite))
+}
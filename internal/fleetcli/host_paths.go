@@ -0,0 +1,146 @@
+package fleetcli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+var (
+	// windowsDrivePattern matches a path on a Windows drive, like C:\Users or c:/Users
+	windowsDrivePattern = regexp.MustCompile(`^([A-Za-z]):([\\/].*)?$`)
+	// msysDrivePattern matches a Windows drive as Git Bash and MSYS write it, like /c/Users
+	msysDrivePattern = regexp.MustCompile(`^/([A-Za-z])(/.*)?$`)
+	// wslDrivePattern matches a Windows drive mounted in WSL, like /mnt/c/Users
+	wslDrivePattern = regexp.MustCompile(`^/mnt/([A-Za-z])(/.*)?$`)
+)
+
+// hostOS is the operating system Fleet runs on
+var hostOS = runtime.GOOS
+
+// usesWindowsDocker reports whether Fleet runs in WSL but drives the Windows docker.exe,
+// which only understands Windows paths. Docker Desktop's WSL integration doesn't need it.
+var usesWindowsDocker = sync.OnceValue(func() bool {
+	if os.Getenv("WSL_DISTRO_NAME") == "" {
+		return false
+	}
+	dockerPath, err := exec.LookPath("docker")
+	return err == nil && strings.HasSuffix(strings.ToLower(dockerPath), ".exe")
+})
+
+// formatWindowsDrive returns a path on a Windows drive the way Docker Desktop expects it
+func formatWindowsDrive(drive, rest string) string {
+	if rest == "" {
+		rest = "/"
+	}
+	return strings.ToUpper(drive) + ":" + rest
+}
+
+// normalizeMountPath returns a host path in the form Docker mounts from this host. Windows
+// paths use forward slashes and an uppercase drive letter, Git Bash paths like /c/Users
+// become C:/Users, and WSL paths are translated when the Windows docker.exe mounts them.
+// Other paths are returned as they are.
+func normalizeMountPath(hostPath string) string {
+	if hostOS == "windows" || windowsDrivePattern.MatchString(hostPath) {
+		hostPath = strings.ReplaceAll(hostPath, `\`, "/")
+	}
+
+	if match := windowsDrivePattern.FindStringSubmatch(hostPath); match != nil {
+		return formatWindowsDrive(match[1], match[2])
+	}
+	if hostOS == "windows" {
+		if match := msysDrivePattern.FindStringSubmatch(hostPath); match != nil {
+			return formatWindowsDrive(match[1], match[2])
+		}
+		return hostPath
+	}
+	if strings.HasPrefix(hostPath, "/") && usesWindowsDocker() {
+		if match := wslDrivePattern.FindStringSubmatch(hostPath); match != nil {
+			return formatWindowsDrive(match[1], match[2])
+		}
+		// The rest of the WSL file system is shared with Windows through the wsl$ share
+		return "//wsl$/" + os.Getenv("WSL_DISTRO_NAME") + hostPath
+	}
+	return hostPath
+}
+
+// splitVolume splits a short syntax volume into its source and the rest, the container
+// path and options. The colon of a Windows drive isn't a separator. source is "" for
+// anonymous volumes.
+func splitVolume(volume string) (source string, rest string) {
+	offset := 0
+	if len(volume) > 2 && windowsDrivePattern.MatchString(volume[:3]) {
+		offset = 2
+	}
+	index := strings.Index(volume[offset:], ":")
+	if index < 0 {
+		return "", volume
+	}
+	return volume[:offset+index], volume[offset+index+1:]
+}
+
+// isHostPathSource reports whether a volume source is a host path rather than a named volume
+func isHostPathSource(source string) bool {
+	return strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~") ||
+		strings.HasPrefix(source, `\`) || windowsDrivePattern.MatchString(source)
+}
+
+// normalizeVolume normalizes the host path of a volume from the config. Named volumes,
+// anonymous volumes and interpolation are left to compose.
+func normalizeVolume(volume string) string {
+	source, rest := splitVolume(volume)
+	if !isHostPathSource(source) {
+		return volume
+	}
+	return normalizeMountPath(source) + ":" + rest
+}
+
+// escapeComposeInterpolation escapes a value compose would otherwise interpolate, like a
+// folder named $HOME
+func escapeComposeInterpolation(value string) string {
+	return strings.ReplaceAll(value, "$", "$$")
+}
+
+// formatMountSource returns a host path Fleet computed as the source of a bind mount
+func formatMountSource(source string) string {
+	return escapeComposeInterpolation(normalizeMountPath(source))
+}
+
+// formatBindMount returns a short syntax bind mount of a host path Fleet computed, like a
+// service folder. target may end with options such as :ro.
+func formatBindMount(source, target string) string {
+	return fmt.Sprintf("%s:%s", formatMountSource(source), target)
+}
+
+// validateHostPath checks that Docker can bind mount a host path. Spaces and non-ASCII
+// characters are fine, but the short volume syntax splits on ':' and Docker rejects
+// control characters.
+func validateHostPath(description, path string) error {
+	rest := strings.TrimPrefix(path, filepath.VolumeName(path))
+	if windowsDrivePattern.MatchString(rest) {
+		rest = rest[2:]
+	}
+	if strings.Contains(rest, ":") {
+		return fmt.Errorf("%s %q contains ':', which Docker can't mount. Rename or move it", description, path)
+	}
+	for _, r := range path {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%s %q contains a control character, which Docker can't mount. Rename or move it", description, path)
+		}
+	}
+	return nil
+}
+
+// validateFolderPath checks the folder of a service can be mounted
+func validateFolderPath(svc *Service) error {
+	if svc.Folder == "" {
+		return nil
+	}
+	return validateHostPath(fmt.Sprintf("service %s: folder", svc.Name), svc.Folder)
+}
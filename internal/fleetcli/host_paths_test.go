@@ -0,0 +1,172 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+)
+
+type HostPathsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *HostPathsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+
+	// A project directory with spaces and non-ASCII characters
+	projectDir := filepath.Join(suite.helper.TempDir(), "Mes Projets", "café shop")
+	suite.Require().NoError(os.MkdirAll(projectDir, 0755))
+	os.Chdir(projectDir)
+}
+
+func (suite *HostPathsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *HostPathsTestSuite) TestValidateHostPath() {
+	testCases := []struct {
+		path  string
+		error string
+	}{
+		{"/home/me/My Projects/shop", ""},
+		{"/home/me/projets/café/日本", ""},
+		{"./apps/$HOME", ""},
+		{"/home/me/shop:v2", "contains ':'"},
+		{"./tabs\tand\nnewlines", "control character"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.path, func() {
+			err := validateHostPath("folder", tc.path)
+			if tc.error == "" {
+				suite.NoError(err)
+				return
+			}
+			suite.ErrorContains(err, tc.error)
+		})
+	}
+
+	config := &Config{Services: []Service{{Name: "web", Image: "nginx:alpine", Folder: "./site:old"}}}
+	suite.ErrorContains(Validate(config), "service web: folder")
+}
+
+func (suite *HostPathsTestSuite) TestNormalizeMountPath() {
+	originalOS := hostOS
+	originalDocker := usesWindowsDocker
+	suite.T().Setenv("WSL_DISTRO_NAME", "Ubuntu")
+	defer func() {
+		hostOS = originalOS
+		usesWindowsDocker = originalDocker
+	}()
+
+	testCases := []struct {
+		name          string
+		os            string
+		windowsDocker bool
+		path          string
+		expected      string
+	}{
+		{"linux", "linux", false, "/home/me/shop", "/home/me/shop"},
+		{"relative", "linux", false, "../shop", "../shop"},
+		{"windows", "windows", false, `C:\Users\Me\My Projects\shop`, "C:/Users/Me/My Projects/shop"},
+		{"lowercase drive", "windows", false, `d:\shop`, "D:/shop"},
+		{"windows relative", "windows", false, `..\shop\public`, "../shop/public"},
+		{"git bash", "windows", false, "/c/Users/Me/shop", "C:/Users/Me/shop"},
+		{"git bash drive root", "windows", false, "/d", "D:/"},
+		{"wsl with docker desktop integration", "linux", false, "/mnt/c/Users/Me/shop", "/mnt/c/Users/Me/shop"},
+		{"wsl drive with docker.exe", "linux", true, "/mnt/c/Users/Me/shop", "C:/Users/Me/shop"},
+		{"wsl home with docker.exe", "linux", true, "/home/me/shop", "//wsl$/Ubuntu/home/me/shop"},
+		{"wsl relative with docker.exe", "linux", true, "../shop", "../shop"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			hostOS = tc.os
+			usesWindowsDocker = func() bool { return tc.windowsDocker }
+			suite.Equal(tc.expected, normalizeMountPath(tc.path))
+		})
+	}
+
+	suite.Equal("//wsl$$/Ubuntu/home/me/shop:/app", formatBindMount("/home/me/shop", "/app"), "Compose would interpolate $/")
+}
+
+func (suite *HostPathsTestSuite) TestWindowsVolumes() {
+	originalOS := hostOS
+	defer func() { hostOS = originalOS }()
+	hostOS = "windows"
+
+	testCases := []struct {
+		volume         string
+		expected       string
+		expectedSource string
+		expectedTarget string
+	}{
+		{`C:\data\mysql:/var/lib/mysql:ro`, "C:/data/mysql:/var/lib/mysql:ro", `C:\data\mysql`, "/var/lib/mysql"},
+		{`.\storage:/app/storage`, "./storage:/app/storage", `.\storage`, "/app/storage"},
+		{"mysql-data:/var/lib/mysql", "mysql-data:/var/lib/mysql", "mysql-data", "/var/lib/mysql"},
+		{"/app/node_modules", "/app/node_modules", "", "/app/node_modules"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.volume, func() {
+			suite.Equal(tc.expected, normalizeVolume(tc.volume))
+			source, target := getVolumeTarget(tc.volume)
+			suite.Equal(tc.expectedSource, source)
+			suite.Equal(tc.expectedTarget, target)
+		})
+	}
+
+	suite.NoError(validateHostPath("folder", `C:\Users\Me\shop`))
+	suite.ErrorContains(validateHostPath("folder", `C:\Users\Me\shop:v2`), "contains ':'")
+
+	config := &Config{Project: "shop", Services: []Service{
+		{Name: "web", Runtime: "node:20", Folder: `apps\web`, Port: 3000, Volumes: []string{`C:\cache:/cache`}, MountExcludes: []string{"dist"}},
+	}}
+	compose := generateDockerCompose(config)
+	suite.Contains(compose.Services["web"].Volumes, "../apps/web:/app")
+	suite.Contains(compose.Services["web"].Volumes, "C:/cache:/cache")
+	suite.Contains(compose.Services["web"].Volumes, "/app/dist", "Excludes match the normalized folder")
+}
+
+func (suite *HostPathsTestSuite) TestGeneratedMounts() {
+	config := &Config{
+		Project: "café",
+		Services: []Service{
+			{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "./boutique en ligne", Domain: "shop.test"},
+			{Name: "web", Runtime: "node:20", Folder: "./apps/$web", Port: 3000},
+		},
+	}
+	suite.Require().NoError(Validate(config))
+
+	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
+	suite.Contains(compose.Services["shop-php"].Volumes, ".././boutique en ligne:/var/www/html")
+	suite.Contains(compose.Services["web"].Volumes, ".././apps/$$web:/app", "Compose would interpolate $web")
+
+	cwd, _ := os.Getwd()
+	nginxConfig := filepath.Join(cwd, ".fleet", "nginx.conf")
+	suite.Contains(compose.Services["nginx-proxy"].Volumes, nginxConfig+":/etc/nginx/nginx.conf:ro")
+
+	// The YAML round trip keeps the paths as they are
+	suite.Require().NoError(writeDockerCompose(compose, filepath.Join(".fleet", "docker-compose.yml")))
+	read, err := readDockerCompose(filepath.Join(".fleet", "docker-compose.yml"))
+	suite.Require().NoError(err)
+	suite.Equal(compose.Services["nginx-proxy"].Volumes, read.Services["nginx-proxy"].Volumes)
+
+	data, err := yaml.Marshal(read.Volumes)
+	suite.Require().NoError(err)
+	suite.Contains(string(data), "Mes Projets/café shop", "Volume labels keep the project directory")
+}
+
+func TestHostPathsSuite(t *testing.T) {
+	suite.Run(t, new(HostPathsTestSuite))
+}
@@ -0,0 +1,82 @@
+package fleetcli
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// hostnamePattern matches an RFC 1123 hostname: dot separated labels of letters, digits and -
+var hostnamePattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+// hostGateway is the address Docker replaces with the IP of the host
+const hostGateway = "host-gateway"
+
+// parseExtraHost splits an extra_hosts entry into its hostname and address. Like compose,
+// it accepts host:ip and host=ip, the latter being clearer with IPv6 addresses.
+func parseExtraHost(entry string) (host string, address string, err error) {
+	sep := strings.IndexAny(entry, "=:")
+	if sep <= 0 {
+		return "", "", fmt.Errorf("extra_hosts entry %q: expected host:ip or host=ip", entry)
+	}
+
+	host, address = entry[:sep], entry[sep+1:]
+	if !hostnamePattern.MatchString(host) {
+		return "", "", fmt.Errorf("extra_hosts entry %q: invalid hostname %q", entry, host)
+	}
+	if address != hostGateway && net.ParseIP(strings.Trim(address, "[]")) == nil {
+		return "", "", fmt.Errorf("extra_hosts entry %q: %q is not an IP address or %s", entry, address, hostGateway)
+	}
+	return host, address, nil
+}
+
+// validateHostnames checks the hostname and extra_hosts of a service
+func validateHostnames(svc *Service) error {
+	if svc.Hostname != "" && (len(svc.Hostname) > 253 || !hostnamePattern.MatchString(svc.Hostname)) {
+		return fmt.Errorf("service %s: invalid hostname %q", svc.Name, svc.Hostname)
+	}
+
+	for _, entry := range svc.ExtraHosts {
+		if _, _, err := parseExtraHost(entry); err != nil {
+			return fmt.Errorf("service %s: %w", svc.Name, err)
+		}
+	}
+	return nil
+}
+
+// configureHostnames sets the hostname and extra hosts of the container that runs a
+// service's code. Entries Fleet adds itself, like host.docker.internal for Xdebug,
+// aren't repeated.
+func configureHostnames(compose *DockerCompose, svc *Service) {
+	if svc.Hostname == "" && len(svc.ExtraHosts) == 0 {
+		return
+	}
+
+	name := getAppServiceName(svc)
+	service, exists := compose.Services[name]
+	if !exists {
+		name = svc.Name
+		if service, exists = compose.Services[name]; !exists {
+			return
+		}
+	}
+
+	if svc.Hostname != "" {
+		service.Hostname = svc.Hostname
+	}
+
+	for _, entry := range svc.ExtraHosts {
+		host, address, err := parseExtraHost(entry)
+		if err != nil {
+			continue
+		}
+		// Compose writes host:ip, so host=ip entries are compared in that form
+		normalized := fmt.Sprintf("%s:%s", host, address)
+		if !containsString(service.ExtraHosts, normalized) {
+			service.ExtraHosts = append(service.ExtraHosts, normalized)
+		}
+	}
+
+	compose.Services[name] = service
+}
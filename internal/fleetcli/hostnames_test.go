@@ -0,0 +1,93 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HostnamesTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *HostnamesTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *HostnamesTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *HostnamesTestSuite) TestParseExtraHost() {
+	testCases := []struct {
+		entry   string
+		host    string
+		address string
+		error   string
+	}{
+		{"api.internal:10.0.0.5", "api.internal", "10.0.0.5", ""},
+		{"api.internal=10.0.0.5", "api.internal", "10.0.0.5", ""},
+		{"legacy:host-gateway", "legacy", "host-gateway", ""},
+		{"ipv6host:::1", "ipv6host", "::1", ""},
+		{"ipv6host=[::1]", "ipv6host", "[::1]", ""},
+		{"api.internal", "", "", "expected host:ip"},
+		{":10.0.0.5", "", "", "expected host:ip"},
+		{"bad_host:10.0.0.5", "", "", "invalid hostname"},
+		{"api:localhost", "", "", "not an IP address"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.entry, func() {
+			host, address, err := parseExtraHost(tc.entry)
+			if tc.error != "" {
+				suite.ErrorContains(err, tc.error)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Equal(tc.host, host)
+			suite.Equal(tc.address, address)
+		})
+	}
+}
+
+func (suite *HostnamesTestSuite) TestValidateHostnames() {
+	suite.NoError(validateHostnames(&Service{Name: "api", Hostname: "api.shop.local", ExtraHosts: []string{"db:10.0.0.2"}}))
+	suite.ErrorContains(validateHostnames(&Service{Name: "api", Hostname: "-api"}), "invalid hostname")
+	suite.ErrorContains(validateHostnames(&Service{Name: "api", ExtraHosts: []string{"db"}}), "service api")
+}
+
+func (suite *HostnamesTestSuite) TestConfigureHostnames() {
+	config := &Config{
+		Project: "test",
+		Services: []Service{
+			{
+				Name:       "web",
+				Image:      "nginx:alpine",
+				Runtime:    "php:8.3",
+				Debug:      true,
+				Hostname:   "legacy-app",
+				ExtraHosts: []string{"host.docker.internal:host-gateway", "payments.internal=10.0.0.5"},
+			},
+			{Name: "api", Image: "node:20", Hostname: "api.local"},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+	php := compose.Services["web-php"]
+	suite.Equal("legacy-app", php.Hostname, "The PHP container runs the code")
+	suite.Equal([]string{"host.docker.internal:host-gateway", "payments.internal:10.0.0.5"}, php.ExtraHosts,
+		"The Xdebug entry isn't repeated")
+	suite.Equal("api.local", compose.Services["api"].Hostname)
+}
+
+func TestHostnamesSuite(t *testing.T) {
+	suite.Run(t, new(HostnamesTestSuite))
+}
@@ -0,0 +1,346 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// hostsLoopbackAddresses are written for every domain. Some resolvers prefer IPv6
+// and never consult the 127.0.0.1 entry, so both address families are mapped.
+var hostsLoopbackAddresses = []string{"127.0.0.1", "::1"}
+
+// HostsEntry is a single address mapping from the hosts file
+type HostsEntry struct {
+	IP             string
+	Hostnames      []string
+	LineNumber     int
+	InFleetSection bool
+}
+
+// HostsConflict is an entry outside the Fleet section that maps a project domain elsewhere
+type HostsConflict struct {
+	Domain     string
+	IP         string
+	LineNumber int
+}
+
+// formatHostsEntries returns sorted hosts file lines for the domain mappings,
+// adding an IPv6 loopback entry next to every IPv4 loopback entry
+func formatHostsEntries(mappings map[string]string) []string {
+	domains := make([]string, 0, len(mappings))
+	for domain := range mappings {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	var lines []string
+	for _, domain := range domains {
+		ip := mappings[domain]
+		if ip == "127.0.0.1" {
+			for _, addr := range hostsLoopbackAddresses {
+				lines = append(lines, fmt.Sprintf("%s %s", addr, domain))
+			}
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", ip, domain))
+	}
+	return lines
+}
+
+// parseHostsEntries parses the address mappings of a hosts file, skipping comments
+func parseHostsEntries(content string) []HostsEntry {
+	var entries []HostsEntry
+	inFleetSection := false
+
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, "# Fleet Services - START") {
+			inFleetSection = true
+			continue
+		}
+		if strings.Contains(line, "# Fleet Services - END") {
+			inFleetSection = false
+			continue
+		}
+
+		// Strip trailing comments
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		entries = append(entries, HostsEntry{
+			IP:             fields[0],
+			Hostnames:      fields[1:],
+			LineNumber:     i + 1,
+			InFleetSection: inFleetSection,
+		})
+	}
+
+	return entries
+}
+
+// isLoopbackAddress reports whether an address points at the local machine
+func isLoopbackAddress(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.IsLoopback()
+}
+
+// findHostsConflicts finds entries added by other tools that send project domains
+// somewhere other than this machine. Those entries win over Fleet's, since most
+// resolvers use the first match in the file.
+func findHostsConflicts(content string, domains []string) []HostsConflict {
+	wanted := make(map[string]bool)
+	for _, domain := range domains {
+		wanted[strings.ToLower(domain)] = true
+	}
+
+	var conflicts []HostsConflict
+	for _, entry := range parseHostsEntries(content) {
+		if entry.InFleetSection || isLoopbackAddress(entry.IP) {
+			continue
+		}
+		for _, hostname := range entry.Hostnames {
+			if wanted[strings.ToLower(hostname)] {
+				conflicts = append(conflicts, HostsConflict{
+					Domain:     hostname,
+					IP:         entry.IP,
+					LineNumber: entry.LineNumber,
+				})
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// diffHostsDomains compares the Fleet section of a hosts file with the domains it should
+// map, and returns the sorted domains to add and to remove
+func diffHostsDomains(content string, mappings map[string]string) (added, removed []string) {
+	current := make(map[string]bool)
+	for _, entry := range parseHostsEntries(content) {
+		if !entry.InFleetSection {
+			continue
+		}
+		for _, hostname := range entry.Hostnames {
+			current[hostname] = true
+		}
+	}
+	for domain := range mappings {
+		if !current[domain] {
+			added = append(added, domain)
+		}
+	}
+	for domain := range current {
+		if _, exists := mappings[domain]; !exists {
+			removed = append(removed, domain)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// syncHostsFile rewrites the Fleet section of the hosts file when domains were added or
+// removed, and leaves the file alone, without asking for sudo, when nothing changed
+func syncHostsFile(config *Config) (added, removed []string, err error) {
+	content, err := os.ReadFile(getHostsFilePath())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read hosts file: %w", err)
+	}
+	mappings := getDomainMappings(config)
+	added, removed = diffHostsDomains(string(content), mappings)
+	switch {
+	case len(added) == 0 && len(removed) == 0:
+		return nil, nil, nil
+	case len(mappings) == 0:
+		err = removeDomainsFromHostsFile()
+	default:
+		err = updateHostsFileWithDomains(config)
+	}
+	return added, removed, err
+}
+
+// guardHostsEdit restores the original hosts file if Fleet is interrupted while writing it,
+// so a half-written file never breaks name resolution. Call the returned function when done.
+func guardHostsEdit(hostsFile string, original []byte) func() {
+	return onInterrupt(func() {
+		if err := os.WriteFile(hostsFile, original, 0644); err == nil {
+			warnf("\n↩️  Restored %s\n", hostsFile)
+			return
+		}
+
+		// Without privileges, keep a copy the user can restore from
+		backup := filepath.Join(os.TempDir(), "fleet-hosts.backup")
+		if err := os.WriteFile(backup, original, 0644); err == nil {
+			warnf("\n⚠️  %s may be incomplete, the original was saved to %s\n", hostsFile, backup)
+		}
+	})
+}
+
+// getProjectDomains returns the sorted domains of all services in the project
+func getProjectDomains(config *Config) []string {
+	var domains []string
+	for domain := range getDomainMappings(config) {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// checkHostsConflicts reads the hosts file and reports conflicts for the project's domains
+func checkHostsConflicts(config *Config) ([]HostsConflict, error) {
+	content, err := os.ReadFile(getHostsFilePath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts file: %w", err)
+	}
+	return findHostsConflicts(string(content), getProjectDomains(config)), nil
+}
+
+// printHostsConflicts warns about conflicting hosts file entries
+func printHostsConflicts(conflicts []HostsConflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+	warnf("⚠️  Warning: %s has entries that override Fleet domains:\n", getHostsFilePath())
+	for _, conflict := range conflicts {
+		warnf("   line %d: %s -> %s\n", conflict.LineNumber, conflict.Domain, conflict.IP)
+	}
+	warnln("   Remove these entries so the domains resolve to Fleet")
+}
+
+func handleHosts() {
+	if len(os.Args) < 3 {
+		printHostsUsage()
+		os.Exit(0)
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "add":
+		handleHostsAdd(os.Args[3:])
+	case "remove":
+		handleHostsRemove()
+	case "list":
+		handleHostsList(os.Args[3:])
+	case "help":
+		printHostsUsage()
+	default:
+		fmt.Printf("Unknown hosts command: %s\n\n", subcommand)
+		printHostsUsage()
+		os.Exit(1)
+	}
+}
+
+func printHostsUsage() {
+	fmt.Println("Fleet hosts - Manage hosts file entries for the current project")
+	fmt.Println("\nUsage: fleet hosts <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  add         Add the project's domains to the hosts file")
+	fmt.Println("  remove      Remove Fleet domains from the hosts file")
+	fmt.Println("  list        Show the project's domains and their hosts file status")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet hosts add     # Map project domains to 127.0.0.1 and ::1")
+	fmt.Println("  fleet hosts list    # Check which domains are mapped")
+}
+
+// loadHostsConfig parses the common -f/--file flag and loads the config
+func loadHostsConfig(name string, args []string) *Config {
+	fs := newCommandFlagSet(name, flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+
+	fs.Parse(args)
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	return config
+}
+
+func handleHostsAdd(args []string) {
+	config := loadHostsConfig("hosts add", args)
+
+	domains := getProjectDomains(config)
+	if len(domains) == 0 {
+		infoln("ℹ️  No services with domains in this project")
+		return
+	}
+
+	if conflicts, err := checkHostsConflicts(config); err == nil {
+		printHostsConflicts(conflicts)
+	}
+
+	infoln("📝 Updating hosts file with service domains...")
+	if err := updateHostsFileWithDomains(config); err != nil {
+		log.Fatalf("❌ Error updating hosts file: %v", err)
+	}
+
+	for _, domain := range domains {
+		infof("   Added domain: %s\n", domain)
+	}
+	infoln("✅ Hosts file updated")
+}
+
+func handleHostsRemove() {
+	if err := removeDomainsFromHostsFile(); err != nil {
+		log.Fatalf("❌ Error updating hosts file: %v", err)
+	}
+	infoln("✅ Fleet domains removed from hosts file")
+}
+
+func handleHostsList(args []string) {
+	config := loadHostsConfig("hosts list", args)
+
+	content, err := os.ReadFile(getHostsFilePath())
+	if err != nil {
+		log.Fatalf("❌ Error reading hosts file: %v", err)
+	}
+
+	// Collect the addresses Fleet has written for each domain
+	mapped := make(map[string][]string)
+	for _, entry := range parseHostsEntries(string(content)) {
+		if !entry.InFleetSection {
+			continue
+		}
+		for _, hostname := range entry.Hostnames {
+			mapped[hostname] = append(mapped[hostname], entry.IP)
+		}
+	}
+
+	domains := getProjectDomains(config)
+	if len(domains) == 0 {
+		infoln("ℹ️  No services with domains in this project")
+		return
+	}
+
+	infof("🌐 Domains for project: %s\n\n", config.Project)
+	for _, domain := range domains {
+		if addrs, ok := mapped[domain]; ok {
+			outputf("  %s %-30s %s\n", emojiOr("✅", "ok"), domain, strings.Join(addrs, ", "))
+		} else {
+			outputf("  %s %-30s not in hosts file (run 'fleet hosts add')\n", emojiOr("❌", "--"), domain)
+		}
+	}
+
+	if conflicts := findHostsConflicts(string(content), domains); len(conflicts) > 0 {
+		warnln()
+		printHostsConflicts(conflicts)
+	}
+}
@@ -0,0 +1,152 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HostsTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+}
+
+func (suite *HostsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+}
+
+func (suite *HostsTestSuite) TearDownTest() {
+	suite.helper.Cleanup()
+}
+
+func (suite *HostsTestSuite) TestFormatHostsEntries() {
+	lines := formatHostsEntries(map[string]string{
+		"web.test": "127.0.0.1",
+		"api.test": "127.0.0.1",
+		"lan.test": "192.168.1.10",
+	})
+
+	suite.Equal([]string{
+		"127.0.0.1 api.test",
+		"::1 api.test",
+		"192.168.1.10 lan.test",
+		"127.0.0.1 web.test",
+		"::1 web.test",
+	}, lines)
+}
+
+func (suite *HostsTestSuite) TestUpdateHostsFileWritesIPv6() {
+	hostsFile := filepath.Join(suite.helper.TempDir(), "hosts")
+	suite.Require().NoError(os.WriteFile(hostsFile, []byte("127.0.0.1 localhost\n"), 0644))
+
+	originalGetHostsFilePath := getHostsFilePath
+	getHostsFilePath = func() string { return hostsFile }
+	defer func() { getHostsFilePath = originalGetHostsFilePath }()
+
+	config := &Config{
+		Services: []Service{
+			{Name: "web", Domain: "web.test", Port: 8080},
+		},
+	}
+	suite.Require().NoError(updateHostsFileWithDomains(config))
+
+	content, err := os.ReadFile(hostsFile)
+	suite.Require().NoError(err)
+	suite.Contains(string(content), "127.0.0.1 web.test")
+	suite.Contains(string(content), "::1 web.test")
+}
+
+func (suite *HostsTestSuite) TestParseHostsEntries() {
+	content := `127.0.0.1 localhost
+# a comment
+10.0.0.5 api.test other.test # trailing comment
+
+# Fleet Services - START
+127.0.0.1 web.test
+# Fleet Services - END`
+
+	entries := parseHostsEntries(content)
+	suite.Require().Len(entries, 3)
+
+	suite.Equal("10.0.0.5", entries[1].IP)
+	suite.Equal([]string{"api.test", "other.test"}, entries[1].Hostnames)
+	suite.Equal(3, entries[1].LineNumber)
+	suite.False(entries[1].InFleetSection)
+
+	suite.Equal([]string{"web.test"}, entries[2].Hostnames)
+	suite.True(entries[2].InFleetSection)
+}
+
+func (suite *HostsTestSuite) TestFindHostsConflicts() {
+	content := `127.0.0.1 localhost
+127.0.0.1 web.test
+10.0.0.5 API.test
+192.168.1.1 router.local
+
+# Fleet Services - START
+10.0.0.9 web.test
+# Fleet Services - END`
+
+	conflicts := findHostsConflicts(content, []string{"api.test", "web.test"})
+
+	// Loopback duplicates and Fleet's own entries are not conflicts
+	suite.Equal([]HostsConflict{
+		{Domain: "API.test", IP: "10.0.0.5", LineNumber: 3},
+	}, conflicts)
+}
+
+func (suite *HostsTestSuite) TestGetProjectDomains() {
+	config := &Config{
+		Services: []Service{
+			{Name: "web", Port: 80},
+			{Name: "api", Domain: "backend.test"},
+			{Name: "worker", Image: "alpine"},
+		},
+	}
+
+	suite.Equal([]string{"backend.test", "web.test"}, getProjectDomains(config))
+}
+
+func (suite *HostsTestSuite) TestSyncHostsFile() {
+	hostsFile := filepath.Join(suite.helper.TempDir(), "hosts")
+	suite.Require().NoError(os.WriteFile(hostsFile, []byte(`127.0.0.1 localhost
+# Fleet Services - START
+127.0.0.1 web.test
+::1 web.test
+127.0.0.1 old.test
+::1 old.test
+# Fleet Services - END`), 0644))
+
+	originalGetHostsFilePath := getHostsFilePath
+	getHostsFilePath = func() string { return hostsFile }
+	defer func() { getHostsFilePath = originalGetHostsFilePath }()
+
+	config := &Config{
+		Services: []Service{
+			{Name: "web", Domain: "web.test", Port: 8080},
+			{Name: "api", Domain: "api.test", Port: 3000},
+		},
+	}
+	added, removed, err := syncHostsFile(config)
+	suite.Require().NoError(err)
+	suite.Equal([]string{"api.test"}, added)
+	suite.Equal([]string{"old.test"}, removed)
+
+	content, err := os.ReadFile(hostsFile)
+	suite.Require().NoError(err)
+	suite.Contains(string(content), "127.0.0.1 localhost")
+	suite.Contains(string(content), "::1 api.test")
+	suite.NotContains(string(content), "old.test")
+
+	// Nothing to write the second time
+	added, removed, err = syncHostsFile(config)
+	suite.NoError(err)
+	suite.Empty(added)
+	suite.Empty(removed)
+}
+
+func TestHostsSuite(t *testing.T) {
+	suite.Run(t, new(HostsTestSuite))
+}
@@ -0,0 +1,289 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// imagesLockFile pins the images of the generated compose file to digests
+const imagesLockFile = ".fleet/images.lock"
+
+// ImageLock maps image references as written in the compose file to their digests
+type ImageLock struct {
+	Images map[string]string `json:"images"`
+}
+
+// lookup returns the digest of an image, on a nil lock too
+func (l *ImageLock) lookup(image string) (string, bool) {
+	if l == nil {
+		return "", false
+	}
+	digest, exists := l.Images[image]
+	return digest, exists
+}
+
+// loadImageLock reads a lockfile, imagesLockFile in the project. It returns nil if the
+// project has none.
+func loadImageLock(path string) (*ImageLock, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lock ImageLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if lock.Images == nil {
+		lock.Images = make(map[string]string)
+	}
+	return &lock, nil
+}
+
+// saveImageLock writes the lockfile with its images in order, so it diffs well
+func saveImageLock(lock *ImageLock) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(imagesLockFile), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(imagesLockFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", imagesLockFile, err)
+	}
+	return nil
+}
+
+// isLockableImage checks if an image comes from a registry and isn't pinned already
+func isLockableImage(service DockerService) bool {
+	return service.Image != "" && service.Build == "" && !strings.Contains(service.Image, "@")
+}
+
+// getLockableImages returns the images of a compose file that can be pinned, in order
+func getLockableImages(compose *DockerCompose) []string {
+	seen := make(map[string]bool)
+	var images []string
+	for _, service := range compose.Services {
+		if isLockableImage(service) && !seen[service.Image] {
+			seen[service.Image] = true
+			images = append(images, service.Image)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// applyImageLock pins the images of a compose file to the digests in the lockfile.
+// Projects without a lockfile keep using tags.
+func applyImageLock(compose *DockerCompose, config *Config) {
+	lock, err := loadImageLock(config.hostPath(imagesLockFile))
+	if err != nil {
+		warnf("⚠️  Warning: %v\n", err)
+		return
+	}
+	if lock == nil {
+		return
+	}
+
+	for name, service := range compose.Services {
+		if !isLockableImage(service) {
+			continue
+		}
+		if digest, exists := lock.Images[service.Image]; exists {
+			service.Image = fmt.Sprintf("%s@%s", service.Image, digest)
+			compose.Services[name] = service
+		}
+	}
+}
+
+// warnUnlockedImages warns about images the lockfile doesn't pin yet, like those of
+// services added after fleet lock ran
+func warnUnlockedImages(compose *DockerCompose) {
+	if lock, err := loadImageLock(imagesLockFile); err != nil || lock == nil {
+		return
+	}
+	if missing := getLockableImages(compose); len(missing) > 0 {
+		warnf("⚠️  Warning: %s not in %s, run 'fleet lock images' to pin them\n", strings.Join(missing, ", "), imagesLockFile)
+	}
+}
+
+// unpinImages reverts the images applyImageLock pinned, so they can be resolved again
+func unpinImages(compose *DockerCompose, lock *ImageLock) {
+	if lock == nil {
+		return
+	}
+	for name, service := range compose.Services {
+		image, digest, found := strings.Cut(service.Image, "@")
+		if found && lock.Images[image] == digest {
+			service.Image = image
+			compose.Services[name] = service
+		}
+	}
+}
+
+// getImageRepository returns an image reference without its tag or digest
+func getImageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	// A colon after the last slash separates the tag, one before it a registry port
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// selectRepoDigest returns the digest of an image from the RepoDigests Docker reports.
+// An image pulled from several repositories has a digest for each.
+func selectRepoDigest(image string, repoDigests []string) (string, error) {
+	repository := getImageRepository(image)
+	for _, repoDigest := range repoDigests {
+		repo, digest, found := strings.Cut(repoDigest, "@")
+		if found && (repo == repository || repo == "docker.io/library/"+repository || repo == "docker.io/"+repository) {
+			return digest, nil
+		}
+	}
+	return "", fmt.Errorf("no registry digest for %s, it may only exist locally", image)
+}
+
+// inspectRepoDigests returns the RepoDigests of a local image
+func inspectRepoDigests(image string) ([]string, error) {
+	output, err := newCommand("docker", "image", "inspect", "--format", "{{json .RepoDigests}}", image).Output()
+	if err != nil {
+		return nil, err
+	}
+	var repoDigests []string
+	if err := json.Unmarshal(output, &repoDigests); err != nil {
+		return nil, fmt.Errorf("failed to parse digests of %s: %w", image, err)
+	}
+	return repoDigests, nil
+}
+
+// resolveImageDigest returns the digest of an image. It pulls the image when it isn't
+// available locally, or always with pull to pick up the latest build of its tag.
+func resolveImageDigest(image string, pull bool) (string, error) {
+	if !pull {
+		if repoDigests, err := inspectRepoDigests(image); err == nil {
+			if digest, err := selectRepoDigest(image, repoDigests); err == nil {
+				return digest, nil
+			}
+		}
+	}
+
+	if output, err := newCommand("docker", "pull", "--quiet", image).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to pull %s: %s", image, strings.TrimSpace(string(output)))
+	}
+	repoDigests, err := inspectRepoDigests(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect %s: %w", image, err)
+	}
+	return selectRepoDigest(image, repoDigests)
+}
+
+func handleLock() {
+	if len(os.Args) < 3 {
+		printLockUsage()
+		os.Exit(0)
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "images":
+		handleLockImages(os.Args[3:], false)
+	case "update":
+		handleLockImages(os.Args[3:], true)
+	case "help":
+		printLockUsage()
+	default:
+		fmt.Printf("Unknown lock command: %s\n\n", subcommand)
+		printLockUsage()
+		os.Exit(1)
+	}
+}
+
+func printLockUsage() {
+	fmt.Println("Fleet lock - Pin images to digests for reproducible environments")
+	fmt.Println("\nUsage: fleet lock <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  images  Record the digest of every image in .fleet/images.lock, keeping pinned ones")
+	fmt.Println("  update  Pull every image and record its latest digest")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("  --wait      Wait for another fleet command in the project, e.g. --wait 1m")
+	fmt.Println("  --force     Take over the project from a stuck fleet command")
+	fmt.Println("\nOnce the lockfile exists, 'fleet up' runs the pinned digests. Commit it to share them.")
+}
+
+// handleLockImages writes the lockfile for the images the config uses. Images that are
+// already locked keep their digest unless update is set.
+func handleLockImages(args []string, update bool) {
+	fs := newCommandFlagSet("lock", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	lockOptions := addProjectLockFlags(fs)
+
+	fs.Parse(args)
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+
+	release := lockProject("lock", lockOptions)
+	defer release()
+
+	previous, err := loadImageLock(imagesLockFile)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	compose := generateDockerCompose(config)
+	unpinImages(compose, previous)
+
+	lock := &ImageLock{Images: make(map[string]string)}
+	failed := false
+	for _, image := range getLockableImages(compose) {
+		if digest, exists := previous.lookup(image); exists && !update {
+			lock.Images[image] = digest
+			continue
+		}
+
+		infof("🔍 Resolving %s\n", image)
+		digest, err := resolveImageDigest(image, update)
+		if err != nil {
+			warnf("⚠️  Warning: %v\n", err)
+			failed = true
+			continue
+		}
+		if old, exists := previous.lookup(image); exists && old != digest {
+			outputf("   %s: %s -> %s\n", image, old, digest)
+		}
+		lock.Images[image] = digest
+	}
+
+	if err := saveImageLock(lock); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if err := ensureFleetGitignore(); err != nil {
+		warnf("⚠️  Warning: %v\n", err)
+	}
+
+	outputf("🔒 Locked %d images in %s\n", len(lock.Images), imagesLockFile)
+	if failed {
+		os.Exit(1)
+	}
+}
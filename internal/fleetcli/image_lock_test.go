@@ -0,0 +1,109 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ImageLockTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *ImageLockTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *ImageLockTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+const testDigest = "sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"
+
+func (suite *ImageLockTestSuite) TestLoadMissingLock() {
+	lock, err := loadImageLock(imagesLockFile)
+	suite.NoError(err)
+	suite.Nil(lock)
+}
+
+func (suite *ImageLockTestSuite) TestSaveAndLoadLock() {
+	suite.Require().NoError(saveImageLock(&ImageLock{Images: map[string]string{"nginx:alpine": testDigest}}))
+
+	lock, err := loadImageLock(imagesLockFile)
+	suite.Require().NoError(err)
+	suite.Equal(map[string]string{"nginx:alpine": testDigest}, lock.Images)
+
+	digest, exists := lock.lookup("nginx:alpine")
+	suite.True(exists)
+	suite.Equal(testDigest, digest)
+
+	var missing *ImageLock
+	_, exists = missing.lookup("nginx:alpine")
+	suite.False(exists)
+}
+
+func (suite *ImageLockTestSuite) TestGetLockableImages() {
+	compose := &DockerCompose{Services: map[string]DockerService{
+		"web":    {Image: "nginx:alpine"},
+		"proxy":  {Image: "nginx:alpine"},
+		"api":    {Image: "my-api:dev", Build: "./api"},
+		"pinned": {Image: "redis:7@" + testDigest},
+		"db":     {Image: "postgres:16-alpine"},
+	}}
+
+	suite.Equal([]string{"nginx:alpine", "postgres:16-alpine"}, getLockableImages(compose), "Built and pinned images are skipped")
+}
+
+func (suite *ImageLockTestSuite) TestApplyImageLock() {
+	config := &Config{
+		Project:  "test",
+		Services: []Service{{Name: "web", Image: "nginx:alpine"}, {Name: "db", Image: "postgres:16"}},
+	}
+
+	compose := generateDockerCompose(config)
+	suite.Equal("nginx:alpine", compose.Services["web"].Image, "Without a lockfile images keep their tag")
+
+	lock := &ImageLock{Images: map[string]string{"nginx:alpine": testDigest}}
+	suite.Require().NoError(saveImageLock(lock))
+
+	compose = generateDockerCompose(config)
+	suite.Equal("nginx:alpine@"+testDigest, compose.Services["web"].Image)
+	suite.Equal("postgres:16", compose.Services["db"].Image, "Images missing from the lockfile keep their tag")
+
+	unpinImages(compose, lock)
+	suite.Equal("nginx:alpine", compose.Services["web"].Image)
+}
+
+func (suite *ImageLockTestSuite) TestSelectRepoDigest() {
+	testCases := []struct {
+		image       string
+		repoDigests []string
+		expected    string
+	}{
+		{"nginx:alpine", []string{"nginx@" + testDigest}, testDigest},
+		{"nginx", []string{"docker.io/library/nginx@" + testDigest}, testDigest},
+		{"ghcr.io/org/api:1.2", []string{"ghcr.io/org/mirror@sha256:other", "ghcr.io/org/api@" + testDigest}, testDigest},
+		{"localhost:5000/api:dev", []string{"localhost:5000/api@" + testDigest}, testDigest},
+	}
+
+	for _, tc := range testCases {
+		digest, err := selectRepoDigest(tc.image, tc.repoDigests)
+		suite.NoError(err, tc.image)
+		suite.Equal(tc.expected, digest, tc.image)
+	}
+
+	_, err := selectRepoDigest("my-api:dev", nil)
+	suite.ErrorContains(err, "only exist locally")
+}
+
+func TestImageLockSuite(t *testing.T) {
+	suite.Run(t, new(ImageLockTestSuite))
+}
@@ -0,0 +1,426 @@
+package fleetcli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fleet/fleet/internal/ui"
+)
+
+const (
+	// defaultDockerSocket is where the Docker daemon listens when DOCKER_HOST isn't set
+	defaultDockerSocket = "/var/run/docker.sock"
+	// imagePullConcurrency is how many images are pulled at the same time
+	imagePullConcurrency = 3
+	// pullProgressInterval is how often the progress bars are redrawn
+	pullProgressInterval = 200 * time.Millisecond
+	// pullBarWidth is the number of characters of a progress bar
+	pullBarWidth = 24
+)
+
+// errDockerAPIUnavailable means the daemon can't be reached through a local socket, so
+// images are pulled with the docker CLI instead
+var errDockerAPIUnavailable = errors.New("docker API socket unavailable")
+
+// PullMessage is a message of the JSON stream the Docker daemon sends while pulling
+type PullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	Error string `json:"error"`
+}
+
+// layerProgress is how far a layer of an image got
+type layerProgress struct {
+	Current int64
+	Total   int64
+	Done    bool
+}
+
+// PullProgress is how far the pull of an image got
+type PullProgress struct {
+	Image  string
+	Layers map[string]*layerProgress
+	Done   bool
+	Err    error
+}
+
+// newPullProgress returns the progress of an image that hasn't started pulling
+func newPullProgress(image string) *PullProgress {
+	return &PullProgress{Image: image, Layers: make(map[string]*layerProgress)}
+}
+
+// apply updates the progress with a message of the pull stream
+func (p *PullProgress) apply(msg PullMessage) {
+	// Messages without an ID are about the whole image, like the final digest
+	if msg.ID == "" {
+		return
+	}
+	layer, exists := p.Layers[msg.ID]
+	if !exists {
+		// The first message of an image is "Pulling from <repository>" with the tag as ID
+		if !strings.HasPrefix(msg.Status, "Pulling fs layer") && !strings.HasPrefix(msg.Status, "Waiting") &&
+			!strings.HasPrefix(msg.Status, "Downloading") && !strings.HasPrefix(msg.Status, "Already exists") {
+			return
+		}
+		layer = &layerProgress{}
+		p.Layers[msg.ID] = layer
+	}
+
+	switch {
+	case strings.HasPrefix(msg.Status, "Downloading"):
+		layer.Current = msg.ProgressDetail.Current
+		if msg.ProgressDetail.Total > 0 {
+			layer.Total = msg.ProgressDetail.Total
+		}
+	case strings.HasPrefix(msg.Status, "Verifying Checksum"), strings.HasPrefix(msg.Status, "Download complete"),
+		strings.HasPrefix(msg.Status, "Extracting"):
+		layer.Current = layer.Total
+	case strings.HasPrefix(msg.Status, "Pull complete"), strings.HasPrefix(msg.Status, "Already exists"):
+		layer.Current = layer.Total
+		layer.Done = true
+	}
+}
+
+// getBytes returns how many bytes of the image's layers were downloaded, out of how many.
+// Layers whose size isn't known yet don't count.
+func (p *PullProgress) getBytes() (current, total int64) {
+	for _, layer := range p.Layers {
+		current += layer.Current
+		total += layer.Total
+	}
+	return current, total
+}
+
+// getLayers returns how many layers are complete, out of how many
+func (p *PullProgress) getLayers() (done, total int) {
+	for _, layer := range p.Layers {
+		if layer.Done {
+			done++
+		}
+	}
+	return done, len(p.Layers)
+}
+
+// formatBytes formats a size like docker pull does, e.g. 12.3MB
+func formatBytes(size int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1000 && unit < len(units)-1 {
+		value /= 1000
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d%s", size, units[unit])
+	}
+	return fmt.Sprintf("%.1f%s", value, units[unit])
+}
+
+// render returns the progress bar line of the image
+func (p *PullProgress) render() string {
+	switch {
+	case p.Err != nil:
+		return fmt.Sprintf("%s %s: %v", emojiOr("❌", "--"), p.Image, p.Err)
+	case p.Done:
+		return fmt.Sprintf("%s %s", emojiOr("✅", "ok"), p.Image)
+	}
+
+	current, total := p.getBytes()
+	done, layers := p.getLayers()
+	filled := 0
+	percent := 0
+	if total > 0 {
+		filled = int(current * pullBarWidth / total)
+		percent = int(current * 100 / total)
+	}
+	bar := strings.Repeat("=", filled)
+	if filled < pullBarWidth {
+		bar += ">" + strings.Repeat(" ", pullBarWidth-filled-1)
+	}
+	return fmt.Sprintf("⬇️  %s [%s] %3d%% %s/%s (%d/%d layers)", p.Image, bar, percent,
+		formatBytes(current), formatBytes(total), done, layers)
+}
+
+// readPullStream applies the messages of a pull stream to the progress of an image. The
+// daemon reports failures inside the stream, after the response started.
+func readPullStream(stream io.Reader, progress *PullProgress, mu *sync.Mutex) error {
+	decoder := json.NewDecoder(stream)
+	for {
+		var msg PullMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read pull progress: %w", err)
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+		mu.Lock()
+		progress.apply(msg)
+		mu.Unlock()
+	}
+}
+
+// splitImageReference returns the repository of an image and its tag or digest, the way
+// the Docker API expects them. Images without a tag get latest.
+func splitImageReference(image string) (repository, tag string) {
+	repository = getImageRepository(image)
+	// A digest wins over a tag, like docker pull does
+	if _, digest, found := strings.Cut(image, "@"); found {
+		return repository, digest
+	}
+	if image != repository {
+		return repository, image[len(repository)+1:]
+	}
+	return repository, "latest"
+}
+
+// getDockerSocket returns the unix socket of the Docker daemon, or "" when it isn't reached
+// through one, like with a remote DOCKER_HOST
+func getDockerSocket() string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+	socket := defaultDockerSocket
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		if !strings.HasPrefix(host, "unix://") {
+			return ""
+		}
+		socket = strings.TrimPrefix(host, "unix://")
+	}
+	if _, err := os.Stat(socket); err != nil {
+		return ""
+	}
+	return socket
+}
+
+// streamImagePull starts pulling an image through the Docker API and returns its progress
+// stream. The docker CLI has no machine readable pull progress.
+var streamImagePull = func(ctx context.Context, image string) (io.ReadCloser, error) {
+	socket := getDockerSocket()
+	if socket == "" {
+		return nil, errDockerAPIUnavailable
+	}
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+
+	repository, tag := splitImageReference(image)
+	query := url.Values{"fromImage": {repository}, "tag": {tag}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://docker/images/create?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errDockerAPIUnavailable, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return nil, fmt.Errorf("failed to pull %s: %s", image, apiErr.Message)
+	}
+	return resp.Body, nil
+}
+
+// pullImageWithCLI pulls an image with the docker CLI, which knows the registry
+// credentials the API needs for private images
+var pullImageWithCLI = func(image string) error {
+	if output, err := newCommand("docker", "pull", "--quiet", image).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// isRegistryAuthError reports whether a pull failed because the registry wants credentials
+func isRegistryAuthError(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "unauthorized") || strings.Contains(message, "denied") ||
+		strings.Contains(message, "authentication required")
+}
+
+// pullImage pulls an image and records its progress
+func pullImage(ctx context.Context, progress *PullProgress, mu *sync.Mutex) error {
+	stream, err := streamImagePull(ctx, progress.Image)
+	if err == nil {
+		defer stream.Close()
+		err = readPullStream(stream, progress, mu)
+	}
+	if err != nil && (errors.Is(err, errDockerAPIUnavailable) || isRegistryAuthError(err)) {
+		err = pullImageWithCLI(progress.Image)
+	}
+	return err
+}
+
+// getPullImages returns the images of the compose services that aren't built, sorted
+func getPullImages(compose *DockerCompose) []string {
+	var images []string
+	for _, service := range compose.Services {
+		if service.Image != "" && service.Build == "" && !containsString(images, service.Image) {
+			images = append(images, service.Image)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// imageExists reports whether an image is available locally
+var imageExists = func(image string) bool {
+	return newCommand("docker", "image", "inspect", "--format", "{{.Id}}", image).Run() == nil
+}
+
+// findMissingImages returns the images that aren't available locally
+func findMissingImages(images []string) []string {
+	var missing []string
+	for _, image := range images {
+		if !imageExists(image) {
+			missing = append(missing, image)
+		}
+	}
+	return missing
+}
+
+// checkOfflineImages fails when an image would have to be pulled
+func checkOfflineImages(compose *DockerCompose) error {
+	missing := findMissingImages(getPullImages(compose))
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("offline, but %d images aren't available locally:\n   %s\n   Run 'fleet up' once while connected to pull them",
+		len(missing), strings.Join(missing, "\n   "))
+}
+
+// isOutputTerminal reports whether stdout is a terminal that progress bars can be redrawn on
+func isOutputTerminal() bool {
+	fileInfo, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return (fileInfo.Mode() & os.ModeCharDevice) != 0
+}
+
+// pullDisplay shows the progress of pulls in a progress step. The spinner shows a bar per
+// image being pulled, and each image gets a line that stays when it finishes.
+type pullDisplay struct {
+	mu       sync.Mutex
+	progress []*PullProgress
+	step     *ui.Step
+	printed  map[string]bool
+}
+
+// draw prints the images that finished and updates the bars of the others
+func (d *pullDisplay) draw() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var bars []string
+	for _, progress := range d.progress {
+		if progress.Done || progress.Err != nil {
+			if !d.printed[progress.Image] {
+				d.printed[progress.Image] = true
+				d.step.Log("   %s\n", progress.render())
+			}
+			continue
+		}
+		bars = append(bars, "   "+progress.render())
+	}
+	d.step.Update(bars...)
+}
+
+// pullImages pulls images in parallel while showing their progress. It returns an error
+// listing the images that failed.
+func pullImages(images []string) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	step := newProgress().Start(fmt.Sprintf("Pulling %d images", len(images)))
+	display := &pullDisplay{step: step, printed: make(map[string]bool)}
+	for _, image := range images {
+		display.progress = append(display.progress, newPullProgress(image))
+	}
+
+	stop := make(chan struct{})
+	drawn := make(chan struct{})
+	go func() {
+		defer close(drawn)
+		ticker := time.NewTicker(pullProgressInterval)
+		defer ticker.Stop()
+		for {
+			display.draw()
+			select {
+			case <-stop:
+				display.draw()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, imagePullConcurrency)
+	for _, progress := range display.progress {
+		wg.Add(1)
+		go func(progress *PullProgress) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			err := pullImage(rootContext, progress, &display.mu)
+			display.mu.Lock()
+			progress.Done = err == nil
+			progress.Err = err
+			display.mu.Unlock()
+		}(progress)
+	}
+	wg.Wait()
+	close(stop)
+	<-drawn
+
+	var failed []string
+	for _, progress := range display.progress {
+		if progress.Err != nil {
+			failed = append(failed, progress.Image)
+		}
+	}
+	if len(failed) > 0 {
+		err := fmt.Errorf("failed to pull %s", strings.Join(failed, ", "))
+		step.Fail(err)
+		return err
+	}
+	step.Done()
+	return nil
+}
+
+// prepullImages pulls the images of a project that aren't available locally, so compose
+// starts services without interleaving its own pull output
+func prepullImages(compose *DockerCompose) error {
+	// Starting reports a missing Docker more clearly
+	if _, err := exec.LookPath("docker"); err != nil {
+		return nil
+	}
+	return pullImages(findMissingImages(getPullImages(compose)))
+}
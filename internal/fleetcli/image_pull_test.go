@@ -0,0 +1,153 @@
+package fleetcli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ImagePullTestSuite struct {
+	suite.Suite
+	helper                  *TestHelper
+	originalStreamImagePull func(context.Context, string) (io.ReadCloser, error)
+	originalPullWithCLI     func(string) error
+	originalImageExists     func(string) bool
+}
+
+func (suite *ImagePullTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalStreamImagePull = streamImagePull
+	suite.originalPullWithCLI = pullImageWithCLI
+	suite.originalImageExists = imageExists
+}
+
+func (suite *ImagePullTestSuite) TearDownTest() {
+	streamImagePull = suite.originalStreamImagePull
+	pullImageWithCLI = suite.originalPullWithCLI
+	imageExists = suite.originalImageExists
+	suite.helper.Cleanup()
+}
+
+// pullStream is the progress the daemon sends for an image with two layers
+const pullStream = `{"status":"Pulling from library/redis","id":"7"}
+{"status":"Pulling fs layer","progressDetail":{},"id":"a1"}
+{"status":"Already exists","progressDetail":{},"id":"b2"}
+{"status":"Downloading","progressDetail":{"current":500,"total":1000},"progress":"[=====>  ]","id":"a1"}
+`
+
+func (suite *ImagePullTestSuite) TestReadPullStream() {
+	progress := newPullProgress("redis:7")
+	suite.Require().NoError(readPullStream(strings.NewReader(pullStream), progress, &sync.Mutex{}))
+
+	current, total := progress.getBytes()
+	suite.Equal(int64(500), current)
+	suite.Equal(int64(1000), total)
+	done, layers := progress.getLayers()
+	suite.Equal(1, done)
+	suite.Equal(2, layers)
+	suite.Contains(progress.render(), " 50% 500B/1.0kB (1/2 layers)")
+
+	progress.apply(PullMessage{ID: "a1", Status: "Pull complete"})
+	done, _ = progress.getLayers()
+	suite.Equal(2, done)
+
+	err := readPullStream(strings.NewReader(`{"error":"manifest for redis:99 not found"}`), newPullProgress("redis:99"), &sync.Mutex{})
+	suite.EqualError(err, "manifest for redis:99 not found")
+}
+
+func (suite *ImagePullTestSuite) TestSplitImageReference() {
+	testCases := []struct {
+		image      string
+		repository string
+		tag        string
+	}{
+		{"redis", "redis", "latest"},
+		{"redis:7", "redis", "7"},
+		{"localhost:5000/app", "localhost:5000/app", "latest"},
+		{"localhost:5000/app:dev", "localhost:5000/app", "dev"},
+		{"nginx:alpine@sha256:abc", "nginx", "sha256:abc"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.image, func() {
+			repository, tag := splitImageReference(tc.image)
+			suite.Equal(tc.repository, repository)
+			suite.Equal(tc.tag, tag)
+		})
+	}
+}
+
+func (suite *ImagePullTestSuite) TestFormatBytes() {
+	suite.Equal("999B", formatBytes(999))
+	suite.Equal("12.3MB", formatBytes(12_300_000))
+	suite.Equal("1.5GB", formatBytes(1_500_000_000))
+}
+
+func (suite *ImagePullTestSuite) TestGetDockerSocket() {
+	socket := filepath.Join(suite.helper.TempDir(), "docker.sock")
+	suite.Require().NoError(os.WriteFile(socket, nil, 0600))
+
+	suite.T().Setenv("DOCKER_HOST", "unix://"+socket)
+	suite.Equal(socket, getDockerSocket())
+
+	suite.T().Setenv("DOCKER_HOST", "tcp://10.0.0.5:2376")
+	suite.Equal("", getDockerSocket())
+}
+
+func (suite *ImagePullTestSuite) TestOfflineImages() {
+	compose := &DockerCompose{Services: map[string]DockerService{
+		"cache": {Image: "redis:7"},
+		"db":    {Image: "mysql:8.0"},
+		"db2":   {Image: "mysql:8.0"},
+		"app":   {Image: "shop-app", Build: "./app"},
+	}}
+	suite.Equal([]string{"mysql:8.0", "redis:7"}, getPullImages(compose))
+
+	imageExists = func(image string) bool { return image == "mysql:8.0" }
+	err := checkOfflineImages(compose)
+	suite.ErrorContains(err, "1 images aren't available locally")
+	suite.ErrorContains(err, "redis:7")
+	suite.NotContains(err.Error(), "mysql")
+
+	imageExists = func(image string) bool { return true }
+	suite.NoError(checkOfflineImages(compose))
+}
+
+func (suite *ImagePullTestSuite) TestPullImages() {
+	var cliPulls []string
+	pullImageWithCLI = func(image string) error {
+		cliPulls = append(cliPulls, image)
+		return nil
+	}
+	streamImagePull = func(ctx context.Context, image string) (io.ReadCloser, error) {
+		switch image {
+		case "redis:7":
+			return io.NopCloser(strings.NewReader(pullStream)), nil
+		case "ghcr.io/acme/private":
+			return io.NopCloser(strings.NewReader(`{"error":"unauthorized: authentication required"}`)), nil
+		case "mysql:99":
+			return io.NopCloser(strings.NewReader(`{"error":"manifest unknown"}`)), nil
+		}
+		return nil, errDockerAPIUnavailable
+	}
+
+	suite.NoError(pullImages([]string{"redis:7", "ghcr.io/acme/private", "nginx:alpine"}))
+	suite.ElementsMatch([]string{"ghcr.io/acme/private", "nginx:alpine"}, cliPulls)
+
+	err := pullImages([]string{"redis:7", "mysql:99"})
+	suite.EqualError(err, "failed to pull mysql:99")
+
+	pullImageWithCLI = func(image string) error { return errors.New("no route to host") }
+	suite.Error(pullImages([]string{"nginx:alpine"}))
+}
+
+func TestImagePullSuite(t *testing.T) {
+	suite.Run(t, new(ImagePullTestSuite))
+}
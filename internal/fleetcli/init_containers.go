@@ -0,0 +1,110 @@
+package fleetcli
+
+import (
+	"fmt"
+)
+
+// getInitContainerName returns the compose service name of the init container at index
+func getInitContainerName(serviceName string, index int, init InitContainer) string {
+	if init.Name != "" {
+		return init.Name
+	}
+	return fmt.Sprintf("%s-init-%d", serviceName, index+1)
+}
+
+// validateInitContainers checks the init containers of every service. Their names share
+// the compose namespace with services, so they must not collide.
+func validateInitContainers(config *Config) error {
+	names := make(map[string]string)
+	for _, svc := range config.Services {
+		names[svc.Name] = fmt.Sprintf("service %s", svc.Name)
+	}
+
+	for _, svc := range config.Services {
+		for i, init := range svc.Init {
+			name := getInitContainerName(svc.Name, i, init)
+			if init.Image == "" && init.Command == "" {
+				return fmt.Errorf("service %s: init container %s needs an 'image' or a 'command'", svc.Name, name)
+			}
+			if existing, exists := names[name]; exists {
+				return fmt.Errorf("service %s: init container name %s is already used by %s", svc.Name, name, existing)
+			}
+			names[name] = fmt.Sprintf("an init container of %s", svc.Name)
+		}
+	}
+	return nil
+}
+
+// getInitBaseService returns the compose service init containers inherit their image,
+// volumes and environment from. PHP apps behind nginx run their code in the FPM container.
+func getInitBaseService(compose *DockerCompose, svc *Service) (DockerService, bool) {
+	if appService, exists := compose.Services[getAppServiceName(svc)]; exists {
+		return appService, true
+	}
+	service, exists := compose.Services[svc.Name]
+	return service, exists
+}
+
+// addInitContainers adds the init containers of a service. They run one after another,
+// and the service only starts once all of them completed successfully.
+func addInitContainers(compose *DockerCompose, svc *Service) {
+	if len(svc.Init) == 0 {
+		return
+	}
+	service, exists := compose.Services[svc.Name]
+	if !exists {
+		return
+	}
+	base, _ := getInitBaseService(compose, svc)
+
+	// Init containers need what the service needs, e.g. migrations need the database
+	dependsOn := append([]string{}, service.DependsOn...)
+	conditions := make(map[string]string, len(service.DependsOnConditions))
+	for dep, condition := range service.DependsOnConditions {
+		conditions[dep] = condition
+	}
+
+	// DependsOn may share its array with the config
+	service.DependsOn = append([]string{}, service.DependsOn...)
+	if service.DependsOnConditions == nil {
+		service.DependsOnConditions = make(map[string]string)
+	}
+
+	for i, init := range svc.Init {
+		name := getInitContainerName(svc.Name, i, init)
+
+		container := DockerService{
+			Image:       base.Image,
+			Build:       base.Build,
+			Volumes:     append([]string{}, base.Volumes...),
+			Environment: base.Environment,
+			EnvFile:     base.EnvFile,
+			Networks:    base.Networks,
+			WorkingDir:  base.WorkingDir,
+			ExtraHosts:  base.ExtraHosts,
+			Command:     init.Command,
+			Restart:     "no",
+			DependsOn:   append([]string{}, dependsOn...),
+		}
+		if init.Image != "" {
+			container.Image = init.Image
+			container.Build = ""
+		}
+		if len(conditions) > 0 {
+			container.DependsOnConditions = make(map[string]string, len(conditions))
+			for dep, condition := range conditions {
+				container.DependsOnConditions[dep] = condition
+			}
+		}
+		compose.Services[name] = container
+
+		// The next init container runs after this one
+		dependsOn = append(dependsOn, name)
+		conditions[name] = dependsOnCompleted
+
+		service.DependsOn = append(service.DependsOn, name)
+		service.DependsOnConditions[name] = dependsOnCompleted
+	}
+
+	compose.Services[svc.Name] = service
+}
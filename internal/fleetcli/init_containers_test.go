@@ -0,0 +1,139 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InitContainersTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *InitContainersTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *InitContainersTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *InitContainersTestSuite) TestValidateInitContainers() {
+	testCases := []struct {
+		name    string
+		init    []InitContainer
+		wantErr string
+	}{
+		{"command only", []InitContainer{{Command: "php artisan migrate --force"}}, ""},
+		{"image only", []InitContainer{{Image: "busybox"}}, ""},
+		{"named", []InitContainer{{Name: "migrate", Command: "migrate"}}, ""},
+		{"empty", []InitContainer{{}}, "needs an 'image' or a 'command'"},
+		{"name of a service", []InitContainer{{Name: "database", Command: "true"}}, "already used by service database"},
+		{"duplicate name", []InitContainer{{Name: "setup", Command: "a"}, {Name: "setup", Command: "b"}}, "already used by an init container of app"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			config := &Config{
+				Project: "test",
+				Services: []Service{
+					{Name: "app", Image: "php:8.3", Init: tc.init},
+					{Name: "database", Image: "postgres"},
+				},
+			}
+			err := validateInitContainers(config)
+			if tc.wantErr == "" {
+				suite.NoError(err)
+			} else {
+				suite.Error(err)
+				suite.Contains(err.Error(), tc.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *InitContainersTestSuite) TestAddInitContainers() {
+	needs := make([]string, 1, 4)
+	needs[0] = "database"
+	config := &Config{
+		Project: "test",
+		Services: []Service{
+			{
+				Name:        "app",
+				Image:       "my-app:latest",
+				Needs:       needs,
+				Volumes:     []string{"./storage:/app/storage"},
+				Environment: map[string]string{"APP_ENV": "local"},
+				Init: []InitContainer{
+					{Name: "fix-permissions", Image: "busybox", Command: "chown -R 1000:1000 /app/storage"},
+					{Command: "./migrate up"},
+				},
+			},
+			{Name: "database", Image: "postgres"},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	permissions := compose.Services["fix-permissions"]
+	suite.Equal("busybox", permissions.Image)
+	suite.Equal("no", permissions.Restart)
+	suite.Equal([]string{"database"}, permissions.DependsOn)
+	suite.Equal(compose.Services["app"].Volumes, permissions.Volumes, "Init containers share the volumes of the service")
+
+	migrate, exists := compose.Services["app-init-2"]
+	suite.True(exists, "Unnamed init containers are named after their index")
+	suite.Equal("my-app:latest", migrate.Image, "Init containers default to the service image")
+	suite.Equal("./migrate up", migrate.Command)
+	suite.Equal("local", migrate.Environment["APP_ENV"])
+	suite.Equal([]string{"database", "fix-permissions"}, migrate.DependsOn)
+	suite.Equal(dependsOnCompleted, migrate.DependsOnConditions["fix-permissions"])
+
+	app := compose.Services["app"]
+	suite.Equal([]string{"database", "fix-permissions", "app-init-2"}, app.DependsOn)
+	suite.Equal(dependsOnCompleted, app.DependsOnConditions["fix-permissions"])
+	suite.Equal(dependsOnCompleted, app.DependsOnConditions["app-init-2"])
+	suite.Empty(app.DependsOnConditions["database"])
+	suite.Empty(needs[:2][1], "Dependencies must not be appended to the config")
+}
+
+func (suite *InitContainersTestSuite) TestDependsOnLongFormRoundTrip() {
+	compose := newDockerCompose()
+	compose.Services["app"] = DockerService{
+		Image:               "my-app",
+		DependsOn:           []string{"database", "app-init-1"},
+		DependsOnConditions: map[string]string{"app-init-1": dependsOnCompleted},
+	}
+	compose.Services["database"] = DockerService{Image: "postgres"}
+	compose.Services["app-init-1"] = DockerService{Image: "my-app", DependsOn: []string{"database"}}
+
+	composeFile := filepath.Join(".fleet", "docker-compose.yml")
+	suite.Require().NoError(os.MkdirAll(".fleet", 0755))
+	suite.Require().NoError(writeDockerCompose(compose, composeFile))
+
+	content, err := os.ReadFile(composeFile)
+	suite.Require().NoError(err)
+	suite.Contains(string(content), "condition: service_completed_successfully")
+	suite.Contains(string(content), "condition: service_started")
+
+	read, err := readDockerCompose(composeFile)
+	suite.Require().NoError(err)
+	suite.Equal([]string{"app-init-1", "database"}, read.Services["app"].DependsOn)
+	suite.Equal(map[string]string{"app-init-1": dependsOnCompleted}, read.Services["app"].DependsOnConditions)
+	suite.Equal("my-app", read.Services["app"].Image)
+	suite.Equal([]string{"database"}, read.Services["app-init-1"].DependsOn, "Short form still works")
+	suite.Nil(read.Services["app-init-1"].DependsOnConditions)
+}
+
+func TestInitContainersSuite(t *testing.T) {
+	suite.Run(t, new(InitContainersTestSuite))
+}
@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -804,8 +804,8 @@ func (ib *InteractiveBuilder) displayConfig() {
 		if svc.Port > 0 {
 			fmt.Printf("   Port: %d\n", svc.Port)
 		}
-		if svc.Domain != "" {
-			fmt.Printf("   Domain: %s\n", svc.Domain)
+		if domains := getConfiguredDomains(&svc); len(domains) > 0 {
+			fmt.Printf("   Domain: %s\n", strings.Join(domains, ", "))
 			if svc.SSL {
 				fmt.Printf("   SSL: enabled\n")
 			}
@@ -0,0 +1,228 @@
+package fleetcli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fleet/fleet/internal/nodecli"
+	"github.com/fleet/fleet/internal/phpcli"
+)
+
+const version = "1.0.0"
+
+// Run runs the fleet command with the arguments in os.Args
+func Run() {
+	// Stop child docker processes cleanly on Ctrl-C
+	handleSignals()
+
+	// --quiet, --no-emoji and --ci apply to every command
+	os.Args = append(os.Args[:1], configureOutput(os.Args[1:])...)
+
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(0)
+	}
+
+	command := os.Args[1]
+	started := time.Now()
+
+	switch command {
+	case "up", "start":
+		handleUp()
+	case "down", "stop":
+		handleDown()
+	case "restart":
+		handleRestart()
+	case "status", "ps":
+		handleStatus()
+	case "logs":
+		handleLogs()
+	case "exec":
+		handleExec()
+	case "shell", "sh":
+		handleShell()
+	case "run":
+		handleRun()
+	case "dev":
+		handleDev()
+	case "init":
+		handleInit()
+	case "add":
+		handleAdd()
+	case "scan":
+		handleScan()
+	case "configure", "config":
+		handleInteractiveConfigure()
+	case "dns":
+		handleDNS()
+	case "hosts":
+		handleHosts()
+	case "update-hosts":
+		handleHostsAdd(os.Args[2:])
+	case "volumes":
+		handleVolumes()
+	case "maintain", "maintenance":
+		handleMaintain()
+	case "lock":
+		handleLock()
+	case "versions":
+		handleVersions()
+	case "doctor":
+		handleDoctor()
+	case "metrics":
+		handleMetrics()
+	case "stats":
+		handleStats()
+	case "verify":
+		handleVerify()
+	case "route", "routes":
+		handleRoute()
+	case "cache":
+		handleCache()
+	case "docs":
+		handleDocs()
+	case "audit":
+		handleAudit()
+	case "bundle":
+		handleBundle()
+	case "db":
+		handleDB()
+	case "seed":
+		handleSeed()
+	case "env":
+		handleEnv()
+	case "php":
+		phpcli.Run("fleet php", os.Args[2:])
+	case "node":
+		nodecli.Run("fleet node", os.Args[2:])
+	case "native":
+		handleNative()
+	case "proxy":
+		handleProxy()
+	case "scale":
+		handleScale()
+	case "ssl":
+		handleSSL()
+	case "workspace", "ws":
+		handleWorkspace()
+	case "version", "-v", "--version":
+		handleVersion()
+	case "help", "-h", "--help":
+		printUsage()
+	default:
+		fmt.Printf("Unknown command: %s\n\n", command)
+		printUsage()
+		os.Exit(1)
+	}
+
+	// Failed commands exit before this, so only successful runs are recorded
+	recordCommandDuration(command, time.Since(started))
+}
+
+func printUsage() {
+	fmt.Printf("Fleet CLI v%s - Simple Docker Service Orchestration\n\n", version)
+	fmt.Println("Usage: fleet <command> [options]")
+	fmt.Println("\nCommands:")
+	
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  up, start\t Start all services")
+	fmt.Fprintln(w, "  down, stop\t Stop all services")  
+	fmt.Fprintln(w, "  restart\t Restart all or selected services")
+	fmt.Fprintln(w, "  scale\t Run more or fewer replicas of a service, e.g. fleet scale api=3")
+	fmt.Fprintln(w, "  status, ps\t Show service status")
+	fmt.Fprintln(w, "  logs\t Show service logs")
+	fmt.Fprintln(w, "  exec\t Run a command or open a shell in a service")
+	fmt.Fprintln(w, "  shell, sh\t Open a shell in the folder of a service's code")
+	fmt.Fprintln(w, "  run\t Run a command in a one-off container of a service, e.g. migrations in CI")
+	fmt.Fprintln(w, "  dev\t Start services and sync or rebuild them when their files change")
+	fmt.Fprintln(w, "  dns\t Manage DNS service for .test domains")
+	fmt.Fprintln(w, "  hosts\t Manage hosts file entries for project domains")
+	fmt.Fprintln(w, "  ssl\t List, renew and export the certificates of the proxy, or trust them with a local CA")
+	fmt.Fprintln(w, "  volumes\t List named volumes and their owning project")
+	fmt.Fprintln(w, "  maintain\t Run cache and database maintenance tasks")
+	fmt.Fprintln(w, "  env\t Set or unset the environment variables of a service in the config")
+	fmt.Fprintln(w, "  db\t List, open, dump, restore, create, drop or clone the databases of services")
+	fmt.Fprintln(w, "  seed\t Generate demo users and orders into the database of a service")
+	fmt.Fprintln(w, "  cache\t Flush caches or show their memory usage and hit rates")
+	fmt.Fprintln(w, "  php\t Run composer, php, artisan or console in a PHP service")
+	fmt.Fprintln(w, "  node\t Run npm, yarn, pnpm, node or npx in a Node.js service")
+	fmt.Fprintln(w, "  lock\t Pin images to digests in .fleet/images.lock")
+	fmt.Fprintln(w, "  workspace, ws\t Run the projects of a fleet-workspace.toml together")
+	fmt.Fprintln(w, "  native\t Start, stop or list services running on the host (experimental)")
+	fmt.Fprintln(w, "  init\t Create a sample fleet.toml")
+	fmt.Fprintln(w, "  add\t Add a service from a template")
+	fmt.Fprintln(w, "  scan\t Propose services for the apps found in subfolders")
+	fmt.Fprintln(w, "  configure\t Interactive configuration builder")
+	fmt.Fprintln(w, "  version\t Show version (--check verifies Docker supports the config)")
+	fmt.Fprintln(w, "  versions\t List supported runtime and service versions (update downloads new ones)")
+	fmt.Fprintln(w, "  doctor\t Diagnose Docker, ports, hosts entries, DNS, generated files and certificates")
+	fmt.Fprintln(w, "  audit\t Review the stack for exposed databases, default passwords and other security issues")
+	fmt.Fprintln(w, "  verify\t Run the HTTP checks of services through the proxy")
+	fmt.Fprintln(w, "  docs\t Describe the services, URLs, variables and dependencies in Markdown")
+	fmt.Fprintln(w, "  bundle\t Write a compose setup with relative paths that runs without Fleet")
+	fmt.Fprintln(w, "  route\t Show the proxy routing table, or test how a URL is routed")
+	fmt.Fprintln(w, "  proxy\t Reload the proxy with changed domains and services, without restarting the stack")
+	fmt.Fprintln(w, "  stats\t Show CPU, memory and restarts of containers, --watch checks their alerts")
+	fmt.Fprintln(w, "  metrics\t Print or serve Prometheus metrics about the project")
+	fmt.Fprintln(w, "  help\t Show this help")
+	w.Flush()
+	
+	fmt.Println("\nOptions:")
+	fmt.Println("  -d, --detach     Run in background (for 'up' command)")
+	fmt.Println("  --verify         Run the HTTP checks of services once they started (for 'up' command)")
+	fmt.Println("  --offline        Don't pull images, fail fast listing missing ones (for 'up' command)")
+	fmt.Println("  -f, --file       Specify config file, URL or git reference repo#ref:path (default: fleet.toml)")
+	fmt.Println("  -q, --quiet      Only print errors, warnings and results (or set FLEET_QUIET=1)")
+	fmt.Println("  --no-emoji       Print plain text without emoji (or set FLEET_NO_EMOJI=1)")
+	fmt.Println("  --ci             Print a line per step instead of spinners (or set FLEET_CI=1)")
+	fmt.Println("  --cloud          Forward ports instead of using .test domains, for Codespaces and Gitpod (for 'up' and 'down')")
+	fmt.Println("  --no-privileged  Leave the hosts file and ports 80/443 alone (for 'up' and 'down', or set FLEET_NO_PRIVILEGED=1)")
+	fmt.Println("  --wait 1m        Wait for another fleet command changing the project (for 'up', 'down', 'restart', 'scale' and 'lock')")
+	fmt.Println("  --force          Take over the project from a stuck fleet command (for 'up', 'down', 'restart', 'scale' and 'lock')")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet init           # Create a sample config")
+	fmt.Println("  fleet up            # Start all services")
+	fmt.Println("  fleet up -d         # Start in background")
+	fmt.Println("  fleet up --no-privileged  # Start without sudo, on http://<service>.localhost:8080")
+	fmt.Println("  fleet up -d --verify  # Start in background and check every service answers")
+	fmt.Println("  fleet up --watch    # Start in background and apply changes of fleet.toml")
+	fmt.Println("  fleet logs website  # Show logs for 'website' service")
+	fmt.Println("  fleet logs --previous website  # Show logs of the last run of 'website' that ended")
+	fmt.Println("  fleet exec website  # Open a shell in the 'website' service")
+	fmt.Println("  fleet shell shop    # Open a shell in /var/www/html of the PHP container of 'shop'")
+	fmt.Println("  fleet run --rm shop php artisan migrate --force  # Migrate in a one-off container")
+	fmt.Println("  fleet restart database --cascade  # Restart database and its dependents")
+	fmt.Println("  fleet restart api --rolling  # Restart the replicas of 'api' one at a time")
+	fmt.Println("  fleet add laravel-api --name api  # Add a service from a template")
+	fmt.Println("  fleet env set web APP_DEBUG=true --apply  # Change a variable and recreate 'web'")
+	fmt.Println("  fleet php artisan migrate  # Run artisan in the PHP service of the current folder")
+	fmt.Println("  fleet node --service=web npm test  # Run npm in the 'web' service")
+	fmt.Println("  fleet stats --watch --notify  # Notify when a service reaches its alerts")
+	fmt.Println("  fleet docs -o STACK.md  # Write onboarding docs generated from the config")
+	fmt.Println("  fleet bundle -o ci/stack  # Write a compose setup CI starts with 'docker compose up'")
+	fmt.Println("  fleet dns start     # Start DNS service for .test domains")
+	fmt.Println("  fleet up -f git@github.com:acme/stacks.git#v1:shop/fleet.toml  # Use a shared config")
+	fmt.Println("\nRun 'fleet dns help' for DNS service commands")
+	fmt.Println("Run 'fleet hosts help' for hosts file commands")
+	fmt.Println("Run 'fleet ssl help' for certificate commands")
+	fmt.Println("Run 'fleet exec help' for exec options")
+	fmt.Println("Run 'fleet dev help' for watch rules")
+	fmt.Println("Run 'fleet ws help' for workspace commands")
+	fmt.Println("Run 'fleet native help' for native service commands")
+	fmt.Println("Run 'fleet maintain help' for maintenance commands")
+	fmt.Println("Run 'fleet lock help' for image lock commands")
+	fmt.Println("Run 'fleet metrics help' for metrics commands")
+	fmt.Println("Run 'fleet route help' for routing commands")
+	fmt.Println("Run 'fleet proxy help' for proxy commands")
+	fmt.Println("Run 'fleet cache help' for cache commands")
+	fmt.Println("Run 'fleet db help' for database commands")
+	fmt.Println("Run 'fleet seed help' for demo data options")
+	fmt.Println("Run 'fleet env help' for environment variable commands")
+	fmt.Println("Run 'fleet docs help' for docs options")
+	fmt.Println("Run 'fleet bundle help' for bundle options")
+	fmt.Println("Run 'fleet audit help' for audit options")
+	fmt.Println("Run 'fleet doctor help' for the checks of doctor")
+	fmt.Println("Run 'fleet stats help' for stats and alert options")
+}
\ No newline at end of file
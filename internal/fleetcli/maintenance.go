@@ -0,0 +1,346 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Built-in maintenance chores
+const (
+	presetRedisFlushDB      = "redis-flushdb"
+	presetPostgresVacuum    = "postgres-vacuum"
+	presetMySQLOptimize     = "mysql-optimize"
+	presetQueuePruneFailed  = "queue-prune-failed"
+	maintenanceServiceName  = "fleet-maintenance"
+	maintenanceScriptFile   = "maintenance.sh"
+	maintenanceSchedulerImg = "docker:cli"
+)
+
+// maintenanceTaskName matches the names of maintenance tasks
+var maintenanceTaskName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// MaintenanceExec is how a task runs: a command in the container of a compose service
+type MaintenanceExec struct {
+	Target  string
+	Env     map[string]string
+	Command string
+}
+
+// findService returns the service with a name, or nil
+func findService(config *Config, name string) *Service {
+	for i := range config.Services {
+		if config.Services[i].Name == name {
+			return &config.Services[i]
+		}
+	}
+	return nil
+}
+
+// getAppServiceName returns the compose service that runs the code of a service.
+// PHP apps behind nginx run in the FPM container.
+func getAppServiceName(svc *Service) string {
+	if strings.Contains(strings.ToLower(svc.Image), "nginx") && strings.HasPrefix(svc.Runtime, "php") {
+		return fmt.Sprintf("%s-php", svc.Name)
+	}
+	return svc.Name
+}
+
+// resolveMaintenanceTask returns where and how a task runs
+func resolveMaintenanceTask(svc *Service, task MaintenanceTask) (*MaintenanceExec, error) {
+	switch task.Preset {
+	case "":
+		return &MaintenanceExec{Target: getAppServiceName(svc), Command: task.Command}, nil
+
+	case presetRedisFlushDB:
+		cacheType, version := parseCacheType(svc.Cache)
+		if cacheType != "redis" {
+			return nil, fmt.Errorf("preset %s needs a service with cache = \"redis\"", task.Preset)
+		}
+		exec := &MaintenanceExec{Target: getSharedCacheServiceName(cacheType, version), Command: "redis-cli FLUSHDB"}
+		if svc.CachePassword != "" {
+			exec.Env = map[string]string{"REDISCLI_AUTH": svc.CachePassword}
+		}
+		return exec, nil
+
+	case presetPostgresVacuum:
+		dbType, version := parseDatabaseType(svc.Database)
+		if dbType != "postgres" {
+			return nil, fmt.Errorf("preset %s needs a service with database = \"postgres\"", task.Preset)
+		}
+		return &MaintenanceExec{
+			Target: getSharedDatabaseServiceName(dbType, version),
+			Env: map[string]string{
+				"PGUSER":     getEnvOrDefault(svc.DatabaseUser, svc.Name),
+				"PGPASSWORD": getEnvOrDefault(svc.DatabasePassword, "password"),
+				"PGDATABASE": getEnvOrDefault(svc.DatabaseName, svc.Name),
+			},
+			Command: "vacuumdb --analyze",
+		}, nil
+
+	case presetMySQLOptimize:
+		dbType, version := parseDatabaseType(svc.Database)
+		if dbType != "mysql" && dbType != "mariadb" {
+			return nil, fmt.Errorf("preset %s needs a service with database = \"mysql\" or \"mariadb\"", task.Preset)
+		}
+		return &MaintenanceExec{
+			Target:  getSharedDatabaseServiceName(dbType, version),
+			Env:     map[string]string{"MYSQL_PWD": getEnvOrDefault(svc.DatabasePassword, "password")},
+			Command: fmt.Sprintf("mysqlcheck --optimize -u %s %s", getEnvOrDefault(svc.DatabaseUser, svc.Name), getEnvOrDefault(svc.DatabaseName, svc.Name)),
+		}, nil
+
+	case presetQueuePruneFailed:
+		if !strings.HasPrefix(svc.Runtime, "php") {
+			return nil, fmt.Errorf("preset %s needs a PHP service", task.Preset)
+		}
+		return &MaintenanceExec{Target: getAppServiceName(svc), Command: "php artisan queue:prune-failed"}, nil
+	}
+
+	return nil, fmt.Errorf("unknown preset %q (expected %s, %s, %s or %s)", task.Preset,
+		presetRedisFlushDB, presetPostgresVacuum, presetMySQLOptimize, presetQueuePruneFailed)
+}
+
+// validateMaintenance checks the maintenance tasks of a config
+func validateMaintenance(config *Config) error {
+	for name, task := range config.Maintenance {
+		if !maintenanceTaskName.MatchString(name) {
+			return fmt.Errorf("maintenance task %q: names may only contain lowercase letters, digits, - and _", name)
+		}
+		svc := findService(config, task.Service)
+		if svc == nil {
+			return fmt.Errorf("maintenance task %s: unknown service %q", name, task.Service)
+		}
+		if (task.Preset == "") == (task.Command == "") {
+			return fmt.Errorf("maintenance task %s: set either 'preset' or 'command'", name)
+		}
+		if _, err := resolveMaintenanceTask(svc, task); err != nil {
+			return fmt.Errorf("maintenance task %s: %w", name, err)
+		}
+		if task.Schedule != "" {
+			if err := validateCronSchedule(task.Schedule); err != nil {
+				return fmt.Errorf("maintenance task %s: invalid schedule %q (%v)", name, task.Schedule, err)
+			}
+		}
+	}
+	return nil
+}
+
+// getMaintenanceTaskNames returns the names of the maintenance tasks and cron jobs in order
+func getMaintenanceTaskNames(config *Config) []string {
+	tasks := getMaintenanceTasks(config)
+	names := make([]string, 0, len(tasks))
+	for name := range tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getMaintenanceExecArgs returns the command that runs a task inside its container
+func getMaintenanceExecArgs(exec *MaintenanceExec) []string {
+	var args []string
+	if len(exec.Env) > 0 {
+		args = append(args, "env")
+		keys := make([]string, 0, len(exec.Env))
+		for key := range exec.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			args = append(args, fmt.Sprintf("%s=%s", key, exec.Env[key]))
+		}
+	}
+	return append(args, "sh", "-c", exec.Command)
+}
+
+// shellQuote quotes a string for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// generateMaintenanceScript returns the script the scheduler runs with a task name.
+// It finds containers by their compose labels, since the scheduler has no compose file.
+func generateMaintenanceScript(config *Config) string {
+	var b strings.Builder
+	b.WriteString(`#!/bin/sh
+# Generated by Fleet CLI - DO NOT EDIT
+
+run() {
+	service="$1"
+	shift
+	container=$(docker ps -q --filter "label=com.docker.compose.project=` + composeProjectName + `" --filter "label=com.docker.compose.service=$service" | head -n 1)
+	if [ -z "$container" ]; then
+		echo "$(date) $task: $service is not running"
+		return 1
+	fi
+	echo "$(date) $task: running in $service"
+	docker exec "$container" "$@"
+}
+
+task="$1"
+case "$task" in
+`)
+
+	tasks := getMaintenanceTasks(config)
+	for _, name := range getMaintenanceTaskNames(config) {
+		task := tasks[name]
+		exec, err := resolveMaintenanceTask(findService(config, task.Service), task)
+		if err != nil {
+			continue
+		}
+		quoted := []string{shellQuote(exec.Target)}
+		for _, arg := range getMaintenanceExecArgs(exec) {
+			quoted = append(quoted, shellQuote(arg))
+		}
+		fmt.Fprintf(&b, "\t%s) run %s ;;\n", name, strings.Join(quoted, " "))
+	}
+
+	b.WriteString(`	*) echo "Unknown maintenance task: $task"; exit 1 ;;
+esac
+`)
+	return b.String()
+}
+
+// addMaintenanceScheduler adds a container that runs scheduled maintenance tasks with crond.
+// It talks to the Docker socket to run each task in the container of its service.
+func addMaintenanceScheduler(compose *DockerCompose, config *Config) {
+	var crontab []string
+	tasks := getMaintenanceTasks(config)
+	for _, name := range getMaintenanceTaskNames(config) {
+		if schedule := tasks[name].Schedule; schedule != "" {
+			crontab = append(crontab, fmt.Sprintf("%s sh /usr/local/bin/fleet-maintenance %s", strings.Join(strings.Fields(schedule), " "), name))
+		}
+	}
+	if len(crontab) == 0 {
+		return
+	}
+
+	plan := getArtifactPlan(compose)
+	plan.addFile(plan.path(maintenanceScriptFile), []byte(generateMaintenanceScript(config)), 0755)
+
+	compose.Services[maintenanceServiceName] = DockerService{
+		Image:   maintenanceSchedulerImg,
+		Restart: "unless-stopped",
+		Volumes: []string{
+			"/var/run/docker.sock:/var/run/docker.sock",
+			formatBindMount(plan.mountPath(maintenanceScriptFile), "/usr/local/bin/fleet-maintenance:ro"),
+		},
+		Command: fmt.Sprintf(`sh -c "printf '%s\n' > /etc/crontabs/root && crond -f -l 8"`, strings.Join(crontab, `\n`)),
+	}
+}
+
+func handleMaintain() {
+	if len(os.Args) < 3 {
+		printMaintainUsage()
+		os.Exit(0)
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "run":
+		handleMaintainRun(os.Args[3:])
+	case "list", "ls":
+		handleMaintainList(os.Args[3:])
+	case "help":
+		printMaintainUsage()
+	default:
+		fmt.Printf("Unknown maintain command: %s\n\n", subcommand)
+		printMaintainUsage()
+		os.Exit(1)
+	}
+}
+
+func printMaintainUsage() {
+	fmt.Println("Fleet maintain - Run cache and database chores")
+	fmt.Println("\nUsage: fleet maintain <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  run <task>  Run a maintenance task or a cron job now")
+	fmt.Println("  list        List maintenance tasks and cron jobs, and their schedules")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("\nPresets:")
+	fmt.Printf("  %-20s Flush the Redis database of a service\n", presetRedisFlushDB)
+	fmt.Printf("  %-20s Vacuum and analyze the PostgreSQL database of a service\n", presetPostgresVacuum)
+	fmt.Printf("  %-20s Optimize the tables of the MySQL or MariaDB database of a service\n", presetMySQLOptimize)
+	fmt.Printf("  %-20s Prune failed Laravel queue jobs\n", presetQueuePruneFailed)
+	fmt.Println("\nExample fleet.toml:")
+	fmt.Println("  [maintenance.vacuum]")
+	fmt.Println("  service = \"api\"")
+	fmt.Println("  preset = \"postgres-vacuum\"")
+	fmt.Println("  schedule = \"0 3 * * 0\"")
+}
+
+// loadMaintainConfig parses the common flags of maintain commands and loads the config
+func loadMaintainConfig(name string, args []string) (*Config, []string) {
+	fs := newCommandFlagSet(name, flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+
+	fs.Parse(args)
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	return config, fs.Args()
+}
+
+func handleMaintainRun(args []string) {
+	config, rest := loadMaintainConfig("maintain run", args)
+	if len(rest) != 1 {
+		log.Fatalf("❌ Usage: fleet maintain run <task>")
+	}
+
+	name := rest[0]
+	task, exists := getMaintenanceTasks(config)[name]
+	if !exists {
+		log.Fatalf("❌ Unknown maintenance task %q (run 'fleet maintain list')", name)
+	}
+	exec, err := resolveMaintenanceTask(findService(config, task.Service), task)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	infof("🧹 Running %s in %s\n", name, exec.Target)
+	dockerArgs := append(composeArgs(getComposeFiles(config), "exec", "-T", exec.Target), getMaintenanceExecArgs(exec)...)
+	if err := runDocker(dockerArgs); err != nil {
+		log.Fatalf("❌ Maintenance task %s failed: %v", name, err)
+	}
+	infof("✅ %s done\n", name)
+}
+
+func handleMaintainList(args []string) {
+	config, _ := loadMaintainConfig("maintain list", args)
+
+	tasks := getMaintenanceTasks(config)
+	if len(tasks) == 0 {
+		outputln("No maintenance tasks configured")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tSERVICE\tRUNS IN\tSCHEDULE\tCOMMAND")
+	for _, name := range getMaintenanceTaskNames(config) {
+		task := tasks[name]
+		exec, err := resolveMaintenanceTask(findService(config, task.Service), task)
+		if err != nil {
+			continue
+		}
+		schedule := task.Schedule
+		if schedule == "" {
+			schedule = "on demand"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, task.Service, exec.Target, schedule, exec.Command)
+	}
+	w.Flush()
+}
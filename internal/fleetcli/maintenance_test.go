@@ -0,0 +1,172 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MaintenanceTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *MaintenanceTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *MaintenanceTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *MaintenanceTestSuite) newConfig(tasks map[string]MaintenanceTask) *Config {
+	return &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "api", Image: "nginx:alpine", Runtime: "php:8.3", Database: "postgres:16", Cache: "redis:7", CachePassword: "secret"},
+			{Name: "legacy", Image: "node:20", Database: "mysql:8.0"},
+		},
+		Maintenance: tasks,
+	}
+}
+
+func (suite *MaintenanceTestSuite) TestResolveMaintenanceTask() {
+	config := suite.newConfig(nil)
+	api := &config.Services[0]
+	legacy := &config.Services[1]
+
+	testCases := []struct {
+		name     string
+		svc      *Service
+		task     MaintenanceTask
+		expected *MaintenanceExec
+	}{
+		{
+			name:     "redis flush",
+			svc:      api,
+			task:     MaintenanceTask{Preset: presetRedisFlushDB},
+			expected: &MaintenanceExec{Target: "redis-7", Env: map[string]string{"REDISCLI_AUTH": "secret"}, Command: "redis-cli FLUSHDB"},
+		},
+		{
+			name: "postgres vacuum",
+			svc:  api,
+			task: MaintenanceTask{Preset: presetPostgresVacuum},
+			expected: &MaintenanceExec{
+				Target:  "postgres-16",
+				Env:     map[string]string{"PGUSER": "api", "PGPASSWORD": "password", "PGDATABASE": "api"},
+				Command: "vacuumdb --analyze",
+			},
+		},
+		{
+			name:     "mysql optimize",
+			svc:      legacy,
+			task:     MaintenanceTask{Preset: presetMySQLOptimize},
+			expected: &MaintenanceExec{Target: "mysql-80", Env: map[string]string{"MYSQL_PWD": "password"}, Command: "mysqlcheck --optimize -u legacy legacy"},
+		},
+		{
+			name:     "queue prune runs in the PHP container",
+			svc:      api,
+			task:     MaintenanceTask{Preset: presetQueuePruneFailed},
+			expected: &MaintenanceExec{Target: "api-php", Command: "php artisan queue:prune-failed"},
+		},
+		{
+			name:     "custom command",
+			svc:      legacy,
+			task:     MaintenanceTask{Command: "npm run cleanup"},
+			expected: &MaintenanceExec{Target: "legacy", Command: "npm run cleanup"},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			exec, err := resolveMaintenanceTask(tc.svc, tc.task)
+			suite.Require().NoError(err)
+			suite.Equal(tc.expected, exec)
+		})
+	}
+}
+
+func (suite *MaintenanceTestSuite) TestValidateMaintenance() {
+	testCases := []struct {
+		name  string
+		task  MaintenanceTask
+		error string
+	}{
+		{"valid preset", MaintenanceTask{Service: "api", Preset: presetPostgresVacuum, Schedule: "0 3 * * 0"}, ""},
+		{"valid command", MaintenanceTask{Service: "legacy", Command: "npm run cleanup"}, ""},
+		{"unknown service", MaintenanceTask{Service: "web", Preset: presetRedisFlushDB}, "unknown service"},
+		{"preset and command", MaintenanceTask{Service: "api", Preset: presetRedisFlushDB, Command: "true"}, "either 'preset' or 'command'"},
+		{"neither preset nor command", MaintenanceTask{Service: "api"}, "either 'preset' or 'command'"},
+		{"unknown preset", MaintenanceTask{Service: "api", Preset: "defrag"}, "unknown preset"},
+		{"preset for another database", MaintenanceTask{Service: "legacy", Preset: presetPostgresVacuum}, "database = \"postgres\""},
+		{"invalid schedule", MaintenanceTask{Service: "api", Preset: presetRedisFlushDB, Schedule: "daily"}, "invalid schedule"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			err := validateMaintenance(suite.newConfig(map[string]MaintenanceTask{"task": tc.task}))
+			if tc.error == "" {
+				suite.NoError(err)
+			} else {
+				suite.ErrorContains(err, tc.error)
+			}
+		})
+	}
+
+	err := validateMaintenance(suite.newConfig(map[string]MaintenanceTask{"Flush Cache": {Service: "api", Preset: presetRedisFlushDB}}))
+	suite.ErrorContains(err, "names may only contain")
+}
+
+func (suite *MaintenanceTestSuite) TestGetMaintenanceExecArgs() {
+	args := getMaintenanceExecArgs(&MaintenanceExec{Env: map[string]string{"PGUSER": "api", "PGDATABASE": "api"}, Command: "vacuumdb --analyze"})
+	suite.Equal([]string{"env", "PGDATABASE=api", "PGUSER=api", "sh", "-c", "vacuumdb --analyze"}, args)
+
+	args = getMaintenanceExecArgs(&MaintenanceExec{Command: "php artisan queue:prune-failed"})
+	suite.Equal([]string{"sh", "-c", "php artisan queue:prune-failed"}, args)
+}
+
+func (suite *MaintenanceTestSuite) TestShellQuote() {
+	suite.Equal(`'redis-cli FLUSHDB'`, shellQuote("redis-cli FLUSHDB"))
+	suite.Equal(`'echo '\''done'\'''`, shellQuote("echo 'done'"))
+}
+
+func (suite *MaintenanceTestSuite) TestScheduledTasksAddScheduler() {
+	config := suite.newConfig(map[string]MaintenanceTask{
+		"vacuum":      {Service: "api", Preset: presetPostgresVacuum, Schedule: "0  3 * * 0"},
+		"flush-cache": {Service: "api", Preset: presetRedisFlushDB},
+	})
+
+	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
+	scheduler, exists := compose.Services[maintenanceServiceName]
+	suite.Require().True(exists, "Scheduled tasks add the scheduler")
+	suite.Contains(scheduler.Volumes, "/var/run/docker.sock:/var/run/docker.sock")
+	suite.Contains(scheduler.Command, "0 3 * * 0 sh /usr/local/bin/fleet-maintenance vacuum")
+	suite.NotContains(scheduler.Command, "flush-cache", "Tasks without a schedule only run on demand")
+
+	script, err := os.ReadFile(filepath.Join(".fleet", maintenanceScriptFile))
+	suite.Require().NoError(err)
+	suite.Contains(string(script), "vacuum) run 'postgres-16' 'env' 'PGDATABASE=api' 'PGPASSWORD=password' 'PGUSER=api' 'sh' '-c' 'vacuumdb --analyze' ;;")
+	suite.Contains(string(script), "flush-cache) run 'redis-7'")
+}
+
+func (suite *MaintenanceTestSuite) TestOnDemandTasksDontAddScheduler() {
+	config := suite.newConfig(map[string]MaintenanceTask{
+		"flush-cache": {Service: "api", Preset: presetRedisFlushDB},
+	})
+
+	compose := generateDockerCompose(config)
+	suite.NotContains(compose.Services, maintenanceServiceName)
+}
+
+func TestMaintenanceSuite(t *testing.T) {
+	suite.Run(t, new(MaintenanceTestSuite))
+}
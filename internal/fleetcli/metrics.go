@@ -0,0 +1,338 @@
+package fleetcli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commandMetricsFile keeps the durations of the fleet commands run in a project
+const commandMetricsFile = ".fleet/metrics.json"
+
+// defaultMetricsListen is where fleet metrics serve listens. It stays on localhost
+// unless --listen says otherwise.
+const defaultMetricsListen = "127.0.0.1:9464"
+
+// metricsCommands maps the commands whose durations are recorded to their metric label
+var metricsCommands = map[string]string{
+	"up":          "up",
+	"start":       "up",
+	"down":        "down",
+	"stop":        "down",
+	"restart":     "restart",
+	"status":      "status",
+	"ps":          "status",
+	"lock":        "lock",
+	"maintain":    "maintain",
+	"maintenance": "maintain",
+}
+
+// CommandMetric is the recorded durations of one fleet command
+type CommandMetric struct {
+	Count       int     `json:"count"`
+	SumSeconds  float64 `json:"sum_seconds"`
+	LastSeconds float64 `json:"last_seconds"`
+}
+
+// CommandMetrics is the content of .fleet/metrics.json
+type CommandMetrics struct {
+	Commands map[string]CommandMetric `json:"commands"`
+}
+
+// ServiceMetric is the state of a compose service as exported to Prometheus
+type ServiceMetric struct {
+	Service  string
+	Up       bool
+	Healthy  bool
+	Restarts int
+}
+
+// loadCommandMetrics reads recorded command durations. A missing file has none.
+func loadCommandMetrics(path string) (*CommandMetrics, error) {
+	metrics := &CommandMetrics{Commands: make(map[string]CommandMetric)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return metrics, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, metrics); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if metrics.Commands == nil {
+		metrics.Commands = make(map[string]CommandMetric)
+	}
+	return metrics, nil
+}
+
+// recordCommandDuration adds the duration of a successful command to .fleet/metrics.json.
+// Only project commands are recorded, and only in projects that were started once.
+func recordCommandDuration(command string, duration time.Duration) {
+	label, tracked := metricsCommands[command]
+	if !tracked {
+		return
+	}
+	if _, err := os.Stat(filepath.Dir(commandMetricsFile)); err != nil {
+		return
+	}
+
+	metrics, err := loadCommandMetrics(commandMetricsFile)
+	if err != nil {
+		// A broken file starts over rather than failing the command
+		metrics = &CommandMetrics{Commands: make(map[string]CommandMetric)}
+	}
+	metric := metrics.Commands[label]
+	metric.Count++
+	metric.SumSeconds += duration.Seconds()
+	metric.LastSeconds = duration.Seconds()
+	metrics.Commands[label] = metric
+
+	data, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return
+	}
+	os.WriteFile(commandMetricsFile, append(data, '\n'), 0644)
+}
+
+// parseRestartCounts parses the "<name> <restart count>" lines printed by docker inspect
+func parseRestartCounts(output []byte) map[string]int {
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if count, err := strconv.Atoi(fields[1]); err == nil {
+			counts[strings.TrimPrefix(fields[0], "/")] = count
+		}
+	}
+	return counts
+}
+
+// inspectRestartCounts returns how often Docker restarted each container
+var inspectRestartCounts = func(containers []string) (map[string]int, error) {
+	if len(containers) == 0 {
+		return map[string]int{}, nil
+	}
+	args := append([]string{"inspect", "--format", "{{.Name}} {{.RestartCount}}"}, containers...)
+	output, err := newCommand("docker", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect containers: %w", err)
+	}
+	return parseRestartCounts(output), nil
+}
+
+// buildServiceMetrics combines the services of the compose file with the state of their
+// containers. Services without containers are reported down.
+func buildServiceMetrics(compose *DockerCompose, entries []ComposePSEntry, restarts map[string]int) []ServiceMetric {
+	byService := make(map[string][]ComposePSEntry)
+	for _, entry := range entries {
+		byService[entry.Service] = append(byService[entry.Service], entry)
+	}
+
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	metrics := make([]ServiceMetric, 0, len(names))
+	for _, name := range names {
+		containers := byService[name]
+		metric := ServiceMetric{Service: name, Up: len(containers) > 0, Healthy: len(containers) > 0}
+		for _, entry := range containers {
+			if entry.State != "running" {
+				metric.Up = false
+			}
+			if !isServiceReady(entry) {
+				metric.Healthy = false
+			}
+			metric.Restarts += restarts[entry.Name]
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics
+}
+
+// escapeLabelValue escapes a Prometheus label value
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// boolMetric returns 1 for true and 0 for false
+func boolMetric(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// writeMetrics writes the metrics of a project in the Prometheus text format
+func writeMetrics(w io.Writer, project string, services []ServiceMetric, commands *CommandMetrics) {
+	projectLabel := fmt.Sprintf(`project="%s"`, escapeLabelValue(project))
+
+	serviceMetrics := []struct {
+		name, help, kind string
+		value            func(ServiceMetric) int
+	}{
+		{"fleet_service_up", "Whether every container of the service is running.", "gauge", func(m ServiceMetric) int { return boolMetric(m.Up) }},
+		{"fleet_service_healthy", "Whether every container of the service is running and passes its health check.", "gauge", func(m ServiceMetric) int { return boolMetric(m.Healthy) }},
+		{"fleet_service_restarts_total", "Times Docker restarted the containers of the service.", "counter", func(m ServiceMetric) int { return m.Restarts }},
+	}
+	for _, metric := range serviceMetrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+		for _, service := range services {
+			fmt.Fprintf(w, "%s{%s,service=\"%s\"} %d\n", metric.name, projectLabel, escapeLabelValue(service.Service), metric.value(service))
+		}
+	}
+
+	names := make([]string, 0, len(commands.Commands))
+	for name := range commands.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "# HELP fleet_command_duration_seconds Duration of successful fleet commands.")
+	fmt.Fprintln(w, "# TYPE fleet_command_duration_seconds summary")
+	for _, name := range names {
+		labels := fmt.Sprintf(`%s,command="%s"`, projectLabel, escapeLabelValue(name))
+		fmt.Fprintf(w, "fleet_command_duration_seconds_sum{%s} %g\n", labels, commands.Commands[name].SumSeconds)
+		fmt.Fprintf(w, "fleet_command_duration_seconds_count{%s} %d\n", labels, commands.Commands[name].Count)
+	}
+	fmt.Fprintln(w, "# HELP fleet_command_last_duration_seconds Duration of the last successful run of a fleet command.")
+	fmt.Fprintln(w, "# TYPE fleet_command_last_duration_seconds gauge")
+	for _, name := range names {
+		fmt.Fprintf(w, "fleet_command_last_duration_seconds{%s,command=\"%s\"} %g\n", projectLabel, escapeLabelValue(name), commands.Commands[name].LastSeconds)
+	}
+}
+
+// collectMetrics queries Docker and writes the metrics of the project
+func collectMetrics(w io.Writer, project string, composeFiles ComposeFiles) error {
+	compose, err := readComposeFiles(composeFiles)
+	if err != nil {
+		return err
+	}
+	entries, err := getComposeServiceStates(composeFiles)
+	if err != nil {
+		return err
+	}
+
+	containers := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		containers = append(containers, entry.Name)
+	}
+	restarts, err := inspectRestartCounts(containers)
+	if err != nil {
+		return err
+	}
+
+	commands, err := loadCommandMetrics(commandMetricsFile)
+	if err != nil {
+		return err
+	}
+
+	writeMetrics(w, project, buildServiceMetrics(compose, entries, restarts), commands)
+	return nil
+}
+
+func handleMetrics() {
+	subcommand := "print"
+	args := os.Args[2:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcommand = args[0]
+		args = args[1:]
+	}
+
+	switch subcommand {
+	case "print":
+		handleMetricsPrint(args)
+	case "serve":
+		handleMetricsServe(args)
+	case "help":
+		printMetricsUsage()
+	default:
+		fmt.Printf("Unknown metrics command: %s\n\n", subcommand)
+		printMetricsUsage()
+		os.Exit(1)
+	}
+}
+
+func printMetricsUsage() {
+	fmt.Println("Fleet metrics - Prometheus metrics about the project")
+	fmt.Println("\nUsage: fleet metrics <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  print   Print the metrics once, e.g. for the node_exporter textfile collector (default)")
+	fmt.Println("  serve   Serve the metrics on /metrics for Prometheus to scrape")
+	fmt.Println("\nOptions:")
+	fmt.Printf("  --listen  Address to serve on (for 'serve', default: %s)\n", defaultMetricsListen)
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+}
+
+// parseMetricsFlags parses the options of the metrics commands and loads the config
+func parseMetricsFlags(name string, args []string) (*Config, string) {
+	fs := newCommandFlagSet(name, flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	listen := fs.String("listen", defaultMetricsListen, "Address to serve metrics on")
+
+	fs.Parse(args)
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	return config, *listen
+}
+
+func handleMetricsPrint(args []string) {
+	config, _ := parseMetricsFlags("metrics print", args)
+	if err := collectMetrics(os.Stdout, config.Project, getComposeFiles(config)); err != nil {
+		log.Fatalf("❌ Error collecting metrics: %v", err)
+	}
+}
+
+func handleMetricsServe(args []string) {
+	config, listen := parseMetricsFlags("metrics serve", args)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		var body strings.Builder
+		if err := collectMetrics(&body, config.Project, getComposeFiles(config)); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		io.WriteString(w, body.String())
+	})
+
+	server := &http.Server{Addr: listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-rootContext.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+
+	infof("📈 Serving metrics of %s on http://%s/metrics (Ctrl-C to stop)\n", config.Project, listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("❌ Error serving metrics: %v", err)
+	}
+}
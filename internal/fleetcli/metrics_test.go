@@ -0,0 +1,79 @@
+package fleetcli
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *MetricsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *MetricsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *MetricsTestSuite) TestRecordCommandDuration() {
+	recordCommandDuration("up", time.Second)
+	suite.NoFileExists(commandMetricsFile, "Nothing is recorded outside a started project")
+
+	suite.Require().NoError(os.MkdirAll(".fleet", 0755))
+	recordCommandDuration("up", 2*time.Second)
+	recordCommandDuration("start", 4*time.Second)
+	recordCommandDuration("help", time.Second)
+
+	metrics, err := loadCommandMetrics(commandMetricsFile)
+	suite.Require().NoError(err)
+	suite.Equal(map[string]CommandMetric{"up": {Count: 2, SumSeconds: 6, LastSeconds: 4}}, metrics.Commands,
+		"Aliases share a label and other commands aren't recorded")
+}
+
+func (suite *MetricsTestSuite) TestBuildServiceMetrics() {
+	compose := &DockerCompose{Services: map[string]DockerService{"web": {}, "web-php": {}, "redis-7": {}, "worker": {}}}
+	entries := []ComposePSEntry{
+		{Name: "fleet-web-1", Service: "web", State: "running"},
+		{Name: "fleet-web-php-1", Service: "web-php", State: "running", Health: "unhealthy"},
+		{Name: "fleet-redis-7-1", Service: "redis-7", State: "restarting"},
+	}
+	restarts := parseRestartCounts([]byte("/fleet-web-1 0\n/fleet-web-php-1 2\n/fleet-redis-7-1 5\n"))
+
+	suite.Equal([]ServiceMetric{
+		{Service: "redis-7", Restarts: 5},
+		{Service: "web", Up: true, Healthy: true},
+		{Service: "web-php", Up: true, Restarts: 2},
+		{Service: "worker"},
+	}, buildServiceMetrics(compose, entries, restarts), "Services without containers are down")
+}
+
+func (suite *MetricsTestSuite) TestWriteMetrics() {
+	var out strings.Builder
+	writeMetrics(&out, "shop", []ServiceMetric{{Service: "web", Up: true, Healthy: true, Restarts: 1}},
+		&CommandMetrics{Commands: map[string]CommandMetric{"up": {Count: 2, SumSeconds: 6.5, LastSeconds: 4}}})
+
+	metrics := out.String()
+	suite.Contains(metrics, "# TYPE fleet_service_up gauge\nfleet_service_up{project=\"shop\",service=\"web\"} 1\n")
+	suite.Contains(metrics, "fleet_service_restarts_total{project=\"shop\",service=\"web\"} 1\n")
+	suite.Contains(metrics, "fleet_command_duration_seconds_sum{project=\"shop\",command=\"up\"} 6.5\n")
+	suite.Contains(metrics, "fleet_command_duration_seconds_count{project=\"shop\",command=\"up\"} 2\n")
+	suite.Contains(metrics, "fleet_command_last_duration_seconds{project=\"shop\",command=\"up\"} 4\n")
+	suite.Equal(`a\"b\\c\n`, escapeLabelValue("a\"b\\c\n"))
+}
+
+func TestMetricsSuite(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
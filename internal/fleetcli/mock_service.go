@@ -0,0 +1,109 @@
+package fleetcli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Mock API servers are provided by Prism, which serves example responses from an OpenAPI spec
+const (
+	mockServerImage = "stoplight/prism:5"
+	mockServerPort  = 4010
+)
+
+// isMockService checks if a service is an OpenAPI mock server (mock set, no image or build)
+func isMockService(svc *Service) bool {
+	return svc.Mock != "" && svc.Image == "" && svc.Build == ""
+}
+
+// getServicePort returns the port of a service. Mock services without one listen on the
+// port of Prism, so they get a .test domain too.
+func getServicePort(svc *Service) int {
+	if svc.Port == 0 && isMockService(svc) {
+		return mockServerPort
+	}
+	return svc.Port
+}
+
+// validateMockService checks the mock settings of a service
+func validateMockService(svc *Service) error {
+	if svc.Mock == "" {
+		return nil
+	}
+	if svc.Image != "" || svc.Build != "" {
+		return fmt.Errorf("service %s: 'mock' cannot be combined with 'image' or 'build'", svc.Name)
+	}
+	if svc.Runtime != "" {
+		return fmt.Errorf("service %s: 'mock' cannot be combined with 'runtime'", svc.Name)
+	}
+	return nil
+}
+
+// getMockSpecLocation returns the volume mount (if any) and the spec location inside the container.
+// Remote specs are passed to Prism as-is, local files are mounted read-only under /spec.
+func getMockSpecLocation(spec string) (volume string, location string) {
+	if strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://") {
+		return "", spec
+	}
+
+	location = fmt.Sprintf("/spec/%s", filepath.Base(spec))
+	hostPath := spec
+	if !filepath.IsAbs(spec) {
+		// Compose file lives in .fleet, so project-relative paths need a ../ prefix
+		hostPath = filepath.Join("..", spec)
+	}
+	return formatBindMount(hostPath, location+":ro"), location
+}
+
+// configureMockService turns a service into a Prism mock server for its OpenAPI spec
+func configureMockService(service *DockerService, svc *Service) {
+	port := getServicePort(svc)
+
+	volume, location := getMockSpecLocation(svc.Mock)
+	if volume != "" {
+		service.Volumes = append(service.Volumes, volume)
+	}
+
+	service.Image = mockServerImage
+	service.Command = fmt.Sprintf("mock -h 0.0.0.0 -p %d %s", port, location)
+}
+
+// addMockEnvVars injects mock server URLs into every service that needs a mock service.
+// <NAME>_URL is reachable from other containers, <NAME>_PUBLIC_URL from the browser.
+func addMockEnvVars(compose *DockerCompose, config *Config) {
+	mocks := make(map[string]*Service)
+	for i := range config.Services {
+		if isMockService(&config.Services[i]) {
+			mocks[config.Services[i].Name] = &config.Services[i]
+		}
+	}
+	if len(mocks) == 0 {
+		return
+	}
+
+	for _, svc := range config.Services {
+		for _, need := range svc.Needs {
+			mock, ok := mocks[need]
+			if !ok {
+				continue
+			}
+
+			vars := map[string]string{
+				getServiceEnvPrefix(mock.Name) + "_URL": fmt.Sprintf("http://%s:%d", mock.Name, getServicePort(mock)),
+			}
+			if domain := getSiteDomain(mock); domain != "" {
+				scheme := "http"
+				if mock.SSL {
+					scheme = "https"
+				}
+				vars[getServiceEnvPrefix(mock.Name)+"_PUBLIC_URL"] = fmt.Sprintf("%s://%s", scheme, domain)
+			}
+
+			// PHP code runs in the FPM container, so it needs the URLs as well
+			for _, name := range []string{svc.Name, fmt.Sprintf("%s-php", svc.Name)} {
+				mergeServiceEnvironment(compose, name, vars)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MockServiceTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *MockServiceTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+
+	// Compose generation writes nginx configs into .fleet
+	suite.originalDir, _ = os.Getwd()
+	suite.Require().NoError(os.Chdir(suite.helper.TempDir()))
+}
+
+func (suite *MockServiceTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *MockServiceTestSuite) TestMockServiceUsesPrism() {
+	config := &Config{
+		Project: "test-project",
+		Services: []Service{
+			{Name: "mock-api", Mock: "./openapi.yaml"},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	mock := compose.Services["mock-api"]
+	suite.Equal("stoplight/prism:5", mock.Image)
+	suite.Equal("mock -h 0.0.0.0 -p 4010 /spec/openapi.yaml", mock.Command)
+	suite.Contains(mock.Volumes, "../openapi.yaml:/spec/openapi.yaml:ro")
+	suite.Empty(mock.Ports, "mock is reached through the nginx proxy")
+
+	// Served at mock-api.test through the proxy, without changing the config
+	suite.Zero(config.Services[0].Port)
+	suite.Equal("mock-api.test", getDomainForService(&config.Services[0]))
+	suite.Equal(4010, getProxyTargetPort(&config.Services[0]))
+	suite.Contains(compose.Services["nginx-proxy"].DependsOn, "mock-api")
+}
+
+func (suite *MockServiceTestSuite) TestRemoteSpecIsNotMounted() {
+	volume, location := getMockSpecLocation("https://example.com/openapi.json")
+	suite.Empty(volume)
+	suite.Equal("https://example.com/openapi.json", location)
+
+	volume, location = getMockSpecLocation("/specs/api.yaml")
+	suite.Equal("/specs/api.yaml:/spec/api.yaml:ro", volume)
+	suite.Equal("/spec/api.yaml", location)
+}
+
+func (suite *MockServiceTestSuite) TestDependentsGetMockURLs() {
+	config := &Config{
+		Project: "test-project",
+		Services: []Service{
+			{Name: "mock-api", Mock: "./openapi.yaml", Port: 4020},
+			{Name: "frontend", Image: "node:20", Port: 3000, Needs: []string{"mock-api"}},
+			{Name: "web", Image: "nginx", Runtime: "php:8.3", Folder: "web", Needs: []string{"mock-api"}},
+			{Name: "worker", Image: "alpine", Environment: map[string]string{"MOCK_API_URL": "http://override"}, Needs: []string{"mock-api"}},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	frontend := compose.Services["frontend"]
+	suite.Equal("http://mock-api:4020", frontend.Environment["MOCK_API_URL"])
+	suite.Equal("http://mock-api.test", frontend.Environment["MOCK_API_PUBLIC_URL"])
+
+	// PHP code runs in the FPM container
+	suite.Equal("http://mock-api:4020", compose.Services["web-php"].Environment["MOCK_API_URL"])
+
+	// Explicit configuration wins and the original config is not modified
+	suite.Equal("http://override", compose.Services["worker"].Environment["MOCK_API_URL"])
+	suite.NotContains(config.Services[3].Environment, "MOCK_API_PUBLIC_URL")
+}
+
+func (suite *MockServiceTestSuite) TestValidation() {
+	tests := []struct {
+		name    string
+		service Service
+		wantErr string
+	}{
+		{
+			name:    "mock without image",
+			service: Service{Name: "mock-api", Mock: "./openapi.yaml"},
+		},
+		{
+			name:    "mock with image",
+			service: Service{Name: "api", Image: "node:20", Mock: "./openapi.yaml"},
+			wantErr: "cannot be combined with 'image' or 'build'",
+		},
+		{
+			name:    "mock with runtime",
+			service: Service{Name: "api", Runtime: "node:20", Mock: "./openapi.yaml"},
+			wantErr: "cannot be combined with 'runtime'",
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			err := Validate(&Config{Project: "test", Services: []Service{tt.service}})
+			if tt.wantErr == "" {
+				suite.NoError(err)
+			} else {
+				suite.Error(err)
+				suite.Contains(err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMockServiceSuite(t *testing.T) {
+	suite.Run(t, new(MockServiceTestSuite))
+}
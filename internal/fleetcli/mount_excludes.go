@@ -0,0 +1,74 @@
+package fleetcli
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// validateMountExcludes checks the mount_excludes of a service. Entries are paths inside
+// the service folder.
+func validateMountExcludes(svc *Service) error {
+	if len(svc.MountExcludes) > 0 && svc.Folder == "" {
+		return fmt.Errorf("service %s: mount_excludes needs a folder", svc.Name)
+	}
+	for _, exclude := range svc.MountExcludes {
+		cleaned := path.Clean(strings.TrimSpace(exclude))
+		if cleaned == "." || cleaned == ".." || path.IsAbs(cleaned) || strings.HasPrefix(cleaned, "../") {
+			return fmt.Errorf("service %s: mount_excludes entry %q must be a path inside the folder", svc.Name, exclude)
+		}
+	}
+	return nil
+}
+
+// getVolumeTarget returns the source and the container path of a volume entry
+func getVolumeTarget(volume string) (source string, target string) {
+	source, rest := splitVolume(volume)
+	target, _, _ = strings.Cut(rest, ":")
+	return source, target
+}
+
+// applyMountExcludes shadows the excluded paths of every container that mounts a service
+// folder with an anonymous volume. The container still sees them, but they live in Docker
+// instead of the bind mount, so file watchers and the host's file sharing skip them.
+func applyMountExcludes(compose *DockerCompose, config *Config) {
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, svc := range config.Services {
+		if len(svc.MountExcludes) == 0 {
+			continue
+		}
+		folderSource := formatMountSource("../" + svc.Folder)
+
+		for _, name := range names {
+			service := compose.Services[name]
+
+			targets := make(map[string]bool)
+			var mounts []string
+			for _, volume := range service.Volumes {
+				source, target := getVolumeTarget(volume)
+				targets[target] = true
+				if source == folderSource {
+					mounts = append(mounts, target)
+				}
+			}
+
+			for _, mount := range mounts {
+				for _, exclude := range svc.MountExcludes {
+					target := path.Join(mount, path.Clean(strings.TrimSpace(exclude)))
+					// Paths that already have a volume, like node_modules, keep it
+					if !targets[target] {
+						service.Volumes = append(service.Volumes, target)
+						targets[target] = true
+					}
+				}
+			}
+			compose.Services[name] = service
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MountExcludesTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *MountExcludesTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *MountExcludesTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *MountExcludesTestSuite) TestValidateMountExcludes() {
+	testCases := []struct {
+		name    string
+		service Service
+		error   string
+	}{
+		{"no excludes", Service{Name: "web"}, ""},
+		{"relative paths", Service{Name: "web", Folder: "./web", MountExcludes: []string{"node_modules", ".git", "storage/logs/"}}, ""},
+		{"no folder", Service{Name: "web", MountExcludes: []string{"node_modules"}}, "needs a folder"},
+		{"absolute", Service{Name: "web", Folder: "./web", MountExcludes: []string{"/app/node_modules"}}, "inside the folder"},
+		{"outside", Service{Name: "web", Folder: "./web", MountExcludes: []string{"../shared"}}, "inside the folder"},
+		{"folder itself", Service{Name: "web", Folder: "./web", MountExcludes: []string{""}}, "inside the folder"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			err := validateMountExcludes(&tc.service)
+			if tc.error == "" {
+				suite.NoError(err)
+				return
+			}
+			suite.ErrorContains(err, tc.error)
+		})
+	}
+}
+
+func (suite *MountExcludesTestSuite) TestGeneratedCompose() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "./shop", MountExcludes: []string{"vendor", ".git/"}},
+			{Name: "web", Runtime: "node:20", Folder: "./web", Port: 3000, MountExcludes: []string{"node_modules", ".next"}},
+		},
+	}
+	suite.Require().NoError(Validate(config))
+
+	compose := generateDockerCompose(config)
+	suite.Subset(compose.Services["shop"].Volumes, []string{"/var/www/html/vendor", "/var/www/html/.git"})
+	suite.Subset(compose.Services["shop-php"].Volumes, []string{"/var/www/html/vendor", "/var/www/html/.git"}, "Sidecars mounting the folder skip the paths too")
+	suite.Contains(compose.Services["web"].Volumes, "/app/.next")
+	suite.NotContains(compose.Services["web"].Volumes, "/app/node_modules", "node_modules already has a named volume")
+}
+
+func TestMountExcludesSuite(t *testing.T) {
+	suite.Run(t, new(MountExcludesTestSuite))
+}
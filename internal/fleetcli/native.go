@@ -0,0 +1,523 @@
+package fleetcli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// nativeHost is the name containers reach processes running on the host with
+const nativeHost = "host.docker.internal"
+
+const (
+	// nativeMinBackoff is how long the supervisor waits before restarting a command
+	// that exited, doubling up to nativeMaxBackoff while it keeps crashing
+	nativeMinBackoff = time.Second
+	nativeMaxBackoff = 30 * time.Second
+	// nativeStableRun is how long a command must run for its next crash to be
+	// restarted right away again
+	nativeStableRun = 10 * time.Second
+)
+
+// isNativeService checks if a service runs as a process on the host instead of a container
+func isNativeService(svc *Service) bool {
+	return svc.Native
+}
+
+// hasNativeServices checks if any service of the project runs on the host
+func hasNativeServices(config *Config) bool {
+	for i := range config.Services {
+		if isNativeService(&config.Services[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateNativeService checks the native settings of a service. Native services only
+// run their own command: databases, caches and other addons need a container.
+func validateNativeService(svc *Service) error {
+	if !svc.Native {
+		return nil
+	}
+	if svc.Image != "" || svc.Build != "" || svc.ExternalService != "" {
+		return fmt.Errorf("service %s: 'native' cannot be combined with 'image', 'build' or 'external_service'", svc.Name)
+	}
+	if svc.Database != "" || svc.Cache != "" || svc.Search != "" || svc.Email != "" || svc.Compat != "" ||
+		svc.Mock != "" || svc.AI != "" || svc.Queue != "" {
+		return fmt.Errorf("service %s: native services can't have addons, declare them on a container service", svc.Name)
+	}
+	if svc.Replicas > 1 || svc.DebugProxy {
+		return fmt.Errorf("service %s: 'native' cannot be combined with 'replicas' or 'debug_proxy'", svc.Name)
+	}
+	if svc.Runtime != "" && !strings.HasPrefix(svc.Runtime, "node") {
+		return fmt.Errorf("service %s: native services support the node runtime only, set 'command' to run others", svc.Name)
+	}
+	if svc.Command == "" && svc.Runtime == "" {
+		return fmt.Errorf("service %s: native services need a 'command', or a node runtime to run the dev script of", svc.Name)
+	}
+	return nil
+}
+
+// getNativeCommand returns the shell command a native service runs: its command, or the
+// dev or start script of its package.json like the node runtime runs in development
+func getNativeCommand(svc *Service) string {
+	if svc.Command != "" {
+		return svc.Command
+	}
+
+	nc := NewNodeConfigurator()
+	packageManager := svc.PackageManager
+	if packageManager == "" {
+		packageManager = detectPackageManager(svc.Folder)
+	}
+	framework := svc.Framework
+	if framework == "" {
+		framework = nc.DetectFramework(svc.Folder)
+	}
+	return nc.getStartCommand(svc.Folder, packageManager, framework, true)
+}
+
+// getNativeEnv returns the environment of a native service: Fleet's own, the env of the
+// service, and PORT and HOST so dev servers listen where the proxy reaches them
+func getNativeEnv(svc *Service) []string {
+	vars := map[string]string{}
+	if port := getProxyTargetPort(svc); port > 0 {
+		vars["PORT"] = strconv.Itoa(port)
+	}
+	// Containers reach the host through the Docker bridge, not its loopback
+	vars["HOST"] = "0.0.0.0"
+	for k, v := range svc.Environment {
+		vars[k] = v
+	}
+
+	env := os.Environ()
+	for _, k := range sortedKeys(vars) {
+		env = append(env, k+"="+vars[k])
+	}
+	return env
+}
+
+// addNativeServices removes the containers of native services and points their
+// dependents at the host, with <NAME>_HOST, <NAME>_PORT and <NAME>_URL
+func addNativeServices(compose *DockerCompose, config *Config) {
+	for i := range config.Services {
+		native := &config.Services[i]
+		if !isNativeService(native) {
+			continue
+		}
+
+		// Fleet runs the process itself, see superviseNative
+		delete(compose.Services, native.Name)
+		for name, service := range compose.Services {
+			if containsString(service.DependsOn, native.Name) {
+				service.DependsOn = removeString(service.DependsOn, native.Name)
+				compose.Services[name] = service
+			}
+		}
+
+		prefix := getServiceEnvPrefix(native.Name)
+		vars := map[string]string{prefix + "_HOST": nativeHost}
+		if port := getProxyTargetPort(native); port > 0 {
+			vars[prefix+"_PORT"] = strconv.Itoa(port)
+			vars[prefix+"_URL"] = fmt.Sprintf("http://%s:%d", nativeHost, port)
+		}
+
+		for _, svc := range config.Services {
+			if !containsString(svc.Needs, native.Name) {
+				continue
+			}
+			for _, name := range []string{svc.Name, fmt.Sprintf("%s-php", svc.Name)} {
+				service, exists := compose.Services[name]
+				if !exists {
+					continue
+				}
+				if entry := nativeHost + ":" + hostGateway; !containsString(service.ExtraHosts, entry) {
+					service.ExtraHosts = append(service.ExtraHosts, entry)
+				}
+				compose.Services[name] = service
+				mergeServiceEnvironment(compose, name, vars)
+			}
+		}
+	}
+}
+
+// getNativeDir returns the directory holding the logs and PID files of native services
+func getNativeDir() string {
+	return filepath.Join(".fleet", "native")
+}
+
+// getNativeLogFile returns the file the output of a native service is appended to
+func getNativeLogFile(name string) string {
+	return filepath.Join(getNativeDir(), name+".log")
+}
+
+// getNativePIDFile returns the file holding the PID of the supervisor of a native service
+func getNativePIDFile(name string) string {
+	return filepath.Join(getNativeDir(), name+".pid")
+}
+
+// readNativePID returns the PID of the supervisor of a native service, and whether it
+// is still running
+func readNativePID(name string) (int, bool) {
+	data, err := os.ReadFile(getNativePIDFile(name))
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, isProcessRunning(pid)
+}
+
+// getNativeServices returns the native services of a project, or the named ones
+func getNativeServices(config *Config, names []string) ([]*Service, error) {
+	var services []*Service
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if isNativeService(svc) && (len(names) == 0 || containsString(names, svc.Name)) {
+			services = append(services, svc)
+		}
+	}
+	for _, name := range names {
+		found := false
+		for _, svc := range services {
+			found = found || svc.Name == name
+		}
+		if !found {
+			return nil, fmt.Errorf("%s is not a native service", name)
+		}
+	}
+	return services, nil
+}
+
+// startNativeServices starts a supervisor for each native service that isn't running.
+// Supervisors aren't waited for, so the processes keep running after fleet up -d returns,
+// while Ctrl-C in the terminal of fleet up still reaches them.
+func startNativeServices(configFile string, services []*Service) error {
+	if len(services) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(getNativeDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", getNativeDir(), err)
+	}
+	fleetPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the fleet binary: %w", err)
+	}
+
+	for _, svc := range services {
+		if pid, running := readNativePID(svc.Name); running {
+			infof("🖥️  Native service %s is already running (PID %d)\n", svc.Name, pid)
+			continue
+		}
+
+		logFile, err := os.OpenFile(getNativeLogFile(svc.Name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open the log of %s: %w", svc.Name, err)
+		}
+		// Not newCommand: the supervisor must outlive this fleet command
+		cmd := exec.Command(fleetPath, "native", "supervise", "-f", configFile, svc.Name)
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+		err = cmd.Start()
+		logFile.Close()
+		if err != nil {
+			return fmt.Errorf("failed to start %s: %w", svc.Name, err)
+		}
+
+		pid := cmd.Process.Pid
+		cmd.Process.Release()
+		if err := os.WriteFile(getNativePIDFile(svc.Name), []byte(strconv.Itoa(pid)+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to record the PID of %s: %w", svc.Name, err)
+		}
+		infof("🖥️  Started native service %s: %s (logs: %s)\n", svc.Name, getNativeCommand(svc), getNativeLogFile(svc.Name))
+	}
+	return nil
+}
+
+// stopNativeServices stops the supervisors of native services, which stop their command
+func stopNativeServices(services []*Service) {
+	for _, svc := range services {
+		pid, running := readNativePID(svc.Name)
+		if running {
+			if process, err := os.FindProcess(pid); err == nil {
+				if err := process.Signal(syscall.SIGTERM); err != nil {
+					warnf("⚠️  Warning: failed to stop native service %s: %v\n", svc.Name, err)
+					continue
+				}
+				infof("   Stopping native service %s\n", svc.Name)
+				waitForProcessExit(pid, commandGracePeriod+5*time.Second)
+			}
+		}
+		os.Remove(getNativePIDFile(svc.Name))
+	}
+}
+
+// waitForProcessExit waits until a process is gone, for at most timeout
+func waitForProcessExit(pid int, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for isProcessRunning(pid) && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// nextNativeBackoff returns how long to wait before restarting a command that ran for
+// ran: crashes in a row wait longer each time, a command that ran a while restarts soon
+func nextNativeBackoff(backoff, ran time.Duration) time.Duration {
+	if ran >= nativeStableRun || backoff < nativeMinBackoff {
+		return nativeMinBackoff
+	}
+	if backoff *= 2; backoff > nativeMaxBackoff {
+		return nativeMaxBackoff
+	}
+	return backoff
+}
+
+// superviseNative runs the command of a native service until stop is closed, restarting
+// it whenever it exits. Its output and the supervisor's own messages go to out.
+func superviseNative(svc *Service, out io.Writer, stop <-chan struct{}) {
+	command := getNativeCommand(svc)
+	backoff := time.Duration(0)
+
+	for {
+		cmd := exec.Command("sh", "-c", getNativeShellCommand(command))
+		cmd.Dir = svc.Folder
+		cmd.Env = getNativeEnv(svc)
+		cmd.Stdout = out
+		cmd.Stderr = out
+
+		started := time.Now()
+		fmt.Fprintf(out, "[fleet] %s starting %s\n", started.Format(time.RFC3339), command)
+		if err := cmd.Start(); err != nil {
+			fmt.Fprintf(out, "[fleet] failed to start %s: %v\n", command, err)
+		} else {
+
+			done := make(chan error, 1)
+			go func() { done <- cmd.Wait() }()
+			select {
+			case err := <-done:
+				fmt.Fprintf(out, "[fleet] %s exited: %s\n", time.Now().Format(time.RFC3339), describeExit(err))
+			case <-stop:
+				cmd.Process.Signal(syscall.SIGTERM)
+				select {
+				case <-done:
+				case <-time.After(commandGracePeriod):
+					cmd.Process.Kill()
+					<-done
+				}
+				fmt.Fprintf(out, "[fleet] %s stopped\n", time.Now().Format(time.RFC3339))
+				return
+			}
+		}
+
+		backoff = nextNativeBackoff(backoff, time.Since(started))
+		fmt.Fprintf(out, "[fleet] restarting in %s\n", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-stop:
+			fmt.Fprintf(out, "[fleet] %s stopped\n", time.Now().Format(time.RFC3339))
+			return
+		}
+	}
+}
+
+// getNativeShellCommand returns the script sh runs for a command. A single command is
+// exec'd so it gets the signals of the supervisor instead of the shell.
+func getNativeShellCommand(command string) string {
+	if strings.ContainsAny(command, ";&|\n()`") {
+		return command
+	}
+	return "exec " + command
+}
+
+// describeExit describes how a command exited, for the log of a native service
+func describeExit(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.String()
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return "exit status 0"
+}
+
+// printNativeLogs prints the last lines of the log of a native service, and the lines
+// appended to it until interrupted when following
+func printNativeLogs(name string, lines int, follow bool) error {
+	file, err := os.Open(getNativeLogFile(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("native service %s has no logs yet, start it with 'fleet up'", name)
+		}
+		return err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	if text := tailLines(string(data), lines); text != "" {
+		fmt.Println(text)
+	}
+	if !follow {
+		return nil
+	}
+
+	reader := bufio.NewReader(file)
+	for rootContext.Err() == nil {
+		line, err := reader.ReadString('\n')
+		fmt.Print(line)
+		if err == io.EOF {
+			time.Sleep(250 * time.Millisecond)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printNativeStatus lists the native services of a project and whether they run
+func printNativeStatus(config *Config) {
+	services, _ := getNativeServices(config, nil)
+	if len(services) == 0 {
+		return
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NATIVE SERVICE\tSTATE\tCOMMAND")
+	for _, svc := range services {
+		state := "stopped"
+		if pid, running := readNativePID(svc.Name); running {
+			state = fmt.Sprintf("running (PID %d)", pid)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", svc.Name, state, getNativeCommand(svc))
+	}
+	w.Flush()
+}
+
+func printNativeUsage() {
+	fmt.Println("Fleet native - Manage services running on the host instead of in containers (experimental)")
+	fmt.Println("\nUsage: fleet native <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  list                  List native services and whether they run")
+	fmt.Println("  start [service...]    Start native services that aren't running")
+	fmt.Println("  stop [service...]     Stop native services")
+	fmt.Println("  restart [service...]  Restart native services")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("\nSet native = true on a service to run its command on the host. 'fleet up' starts")
+	fmt.Println("native services and restarts them when they exit, 'fleet down' stops them, and")
+	fmt.Println("'fleet logs <service>' shows their output from " + filepath.Join(getNativeDir(), "<service>.log"))
+}
+
+func handleNative() {
+	if len(os.Args) < 3 {
+		printNativeUsage()
+		os.Exit(0)
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "list", "ls":
+		config, _, _ := loadNativeConfig("native list", os.Args[3:])
+		printNativeStatus(config)
+	case "start", "stop", "restart":
+		handleNativeControl(subcommand, os.Args[3:])
+	case "supervise":
+		handleNativeSupervise(os.Args[3:])
+	case "help":
+		printNativeUsage()
+	default:
+		fmt.Printf("Unknown native command: %s\n\n", subcommand)
+		printNativeUsage()
+		os.Exit(1)
+	}
+}
+
+// loadNativeConfig parses the common flags of native commands and loads the config
+func loadNativeConfig(name string, args []string) (*Config, string, []string) {
+	fs := newCommandFlagSet(name, flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	fs.Usage = printNativeUsage
+
+	rest := parseFlagsAndArgs(fs, args)
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	return config, *configFile, rest
+}
+
+// handleNativeControl starts, stops or restarts native services
+func handleNativeControl(subcommand string, args []string) {
+	config, configFile, names := loadNativeConfig("native "+subcommand, args)
+	services, err := getNativeServices(config, names)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if len(services) == 0 {
+		outputln("No native services: set native = true on a service to run it on the host")
+		return
+	}
+
+	if subcommand != "start" {
+		stopNativeServices(services)
+	}
+	if subcommand != "stop" {
+		if err := startNativeServices(configFile, services); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+}
+
+// handleNativeSupervise runs a native service in the foreground, restarting it when it
+// exits. fleet up starts it in the background with its output going to the log file.
+func handleNativeSupervise(args []string) {
+	config, _, names := loadNativeConfig("native supervise", args)
+	if len(names) != 1 {
+		log.Fatalf("❌ Usage: fleet native supervise <service>")
+	}
+	services, err := getNativeServices(config, names)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	// The supervisor outlives the terminal fleet up was started from
+	signal.Ignore(syscall.SIGHUP)
+	stop := make(chan struct{})
+	go func() {
+		<-rootContext.Done()
+		close(stop)
+	}()
+
+	superviseNative(services[0], os.Stdout, stop)
+
+	// fleet native restart may already have recorded the next supervisor
+	if pid, _ := readNativePID(names[0]); pid == os.Getpid() {
+		os.Remove(getNativePIDFile(names[0]))
+	}
+}
@@ -0,0 +1,135 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NativeTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *NativeTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *NativeTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *NativeTestSuite) TestValidateNativeService() {
+	suite.NoError(validateNativeService(&Service{Name: "web", Native: true, Command: "python -m http.server"}))
+	suite.NoError(validateNativeService(&Service{Name: "web", Native: true, Runtime: "node:20", Folder: "web"}))
+	suite.NoError(validateNativeService(&Service{Name: "api", Image: "node:20"}))
+
+	suite.ErrorContains(validateNativeService(&Service{Name: "web", Native: true, Image: "node:20", Command: "npm start"}),
+		"cannot be combined with 'image'")
+	suite.ErrorContains(validateNativeService(&Service{Name: "web", Native: true, Command: "npm start", Database: "postgres:16"}),
+		"can't have addons")
+	suite.ErrorContains(validateNativeService(&Service{Name: "web", Native: true, Command: "npm start", Replicas: 2}),
+		"'replicas'")
+	suite.ErrorContains(validateNativeService(&Service{Name: "web", Native: true, Runtime: "php:8.3"}),
+		"node runtime only")
+	suite.ErrorContains(validateNativeService(&Service{Name: "web", Native: true}), "need a 'command'")
+}
+
+func (suite *NativeTestSuite) TestGetNativeCommand() {
+	suite.Equal("hugo server", getNativeCommand(&Service{Name: "docs", Native: true, Command: "hugo server"}))
+
+	suite.Require().NoError(os.MkdirAll("web", 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join("web", "package.json"),
+		[]byte(`{"scripts": {"dev": "vite", "start": "node server.js"}}`), 0644))
+	suite.Equal("npm run dev", getNativeCommand(&Service{Name: "web", Native: true, Runtime: "node:20", Folder: "web"}))
+
+	suite.Require().NoError(os.WriteFile(filepath.Join("web", "pnpm-lock.yaml"), nil, 0644))
+	suite.Equal("pnpm dev", getNativeCommand(&Service{Name: "web", Native: true, Runtime: "node:20", Folder: "web"}))
+}
+
+func (suite *NativeTestSuite) TestGetNativeShellCommand() {
+	suite.Equal("exec npm run dev", getNativeShellCommand("npm run dev"))
+	suite.Equal("npm run build && npm start", getNativeShellCommand("npm run build && npm start"))
+}
+
+func (suite *NativeTestSuite) TestGetNativeEnv() {
+	env := getNativeEnv(&Service{Name: "web", Native: true, Port: 5173, Environment: map[string]string{"HOST": "127.0.0.1"}})
+	suite.Contains(env, "PORT=5173")
+	suite.Contains(env, "HOST=127.0.0.1", "The env of the service overrides the defaults")
+	suite.NotContains(env, "HOST=0.0.0.0")
+}
+
+func (suite *NativeTestSuite) TestNativeServiceCompose() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "web", Native: true, Command: "npm run dev", Port: 5173},
+		{Name: "api", Image: "node:20", Port: 3000, Needs: []string{"web"}},
+	}}
+	compose := generateDockerCompose(config)
+
+	suite.NotContains(compose.Services, "web", "Native services have no container")
+	api := compose.Services["api"]
+	suite.NotContains(api.DependsOn, "web")
+	suite.Contains(api.ExtraHosts, "host.docker.internal:host-gateway")
+	suite.Equal("host.docker.internal", api.Environment["WEB_HOST"])
+	suite.Equal("http://host.docker.internal:5173", api.Environment["WEB_URL"])
+
+	proxy := compose.Services["nginx-proxy"]
+	suite.Equal([]string{"api"}, proxy.DependsOn)
+	suite.Contains(proxy.ExtraHosts, "host.docker.internal:host-gateway")
+
+	nginxConf, err := generateNginxConfig(config)
+	suite.Require().NoError(err)
+	suite.Contains(nginxConf, "server_name web.test;")
+	suite.Contains(nginxConf, "server host.docker.internal:5173;")
+}
+
+func (suite *NativeTestSuite) TestNextNativeBackoff() {
+	suite.Equal(nativeMinBackoff, nextNativeBackoff(0, time.Second))
+	suite.Equal(2*time.Second, nextNativeBackoff(time.Second, time.Second))
+	suite.Equal(nativeMaxBackoff, nextNativeBackoff(20*time.Second, time.Second))
+	suite.Equal(nativeMinBackoff, nextNativeBackoff(20*time.Second, time.Minute), "A command that ran a while restarts soon")
+}
+
+func (suite *NativeTestSuite) TestReadNativePID() {
+	_, running := readNativePID("web")
+	suite.False(running)
+
+	suite.Require().NoError(os.MkdirAll(getNativeDir(), 0755))
+	suite.Require().NoError(os.WriteFile(getNativePIDFile("web"), []byte(strconv.Itoa(os.Getpid())+"\n"), 0644))
+	pid, running := readNativePID("web")
+	suite.Equal(os.Getpid(), pid)
+	suite.True(running)
+
+	suite.Require().NoError(os.WriteFile(getNativePIDFile("web"), []byte("garbage"), 0644))
+	_, running = readNativePID("web")
+	suite.False(running)
+}
+
+func (suite *NativeTestSuite) TestGetNativeServices() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "web", Native: true, Command: "npm run dev"},
+		{Name: "api", Image: "node:20"},
+	}}
+
+	services, err := getNativeServices(config, nil)
+	suite.Require().NoError(err)
+	suite.Len(services, 1)
+	suite.Equal("web", services[0].Name)
+
+	_, err = getNativeServices(config, []string{"api"})
+	suite.ErrorContains(err, "api is not a native service")
+}
+
+func TestNativeSuite(t *testing.T) {
+	suite.Run(t, new(NativeTestSuite))
+}
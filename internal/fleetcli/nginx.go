@@ -0,0 +1,484 @@
+package fleetcli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// NginxConfig represents the nginx configuration
+type NginxConfig struct {
+	Services     []ServiceWithDomain
+	HasSSL       bool // Flag to indicate if any service has SSL enabled
+	Unprivileged bool // Proxy is published on high ports, see unprivileged.go
+	HTTPSPort    int  // Host port HTTPS is published on in unprivileged mode
+	DebugProxy   string // Domain of the debug proxy UI, see debug_proxy.go
+	QueueDashboards    []QueueDashboard // Web UIs of the queues, see queue_services.go
+	QueueDashboardAuth bool             // Queue dashboards ask for the user of queue_dashboard_auth
+	MinIORoutes        []MinIORoute     // S3 APIs and consoles of MinIO, see compat_services.go
+	HTTP3              bool             // HTTPS vhosts also listen for QUIC, with http3
+	TLSProtocols       string           // TLS versions HTTPS accepts, only TLSv1.3 with modern_tls_only
+}
+
+// ServiceWithDomain represents a service with domain configuration
+type ServiceWithDomain struct {
+	Name             string
+	Domain           string
+	Port             int
+	SSL              bool
+	SSLPort          int
+	CertPath         string
+	KeyPath          string
+	SanitizedDomain  string  // For certificate filenames
+	IsPHP            bool    // Flag to indicate if this is a PHP service
+	PHPVersion       string  // PHP version for FPM container name
+	Framework        string  // PHP framework (laravel, symfony, etc.)
+	Aliases          []string // Extra server names, e.g. web.localhost in unprivileged mode
+	ClientMaxBodySize string  // Request body limit matching php_fpm upload limits
+	Upstream         string  // host:port requests go to instead of the service, e.g. the debug proxy
+	QUICReuseport    bool    // First QUIC listener of a port other than 443, which the default server has
+	AltSvcPort       int     // Port browsers are told to try HTTP/3 on
+	WebSocket        bool    // location / carries websockets and gets long timeouts
+	WebSocketLocations []WebSocketLocation // Locations that only carry websockets
+	PathRoutes       []PathRoute // Locations of the services sharing the domain on a path
+	PathsOnly        bool        // No service serves the root of the domain
+}
+
+// shouldAddNginxProxy checks if we need to add nginx proxy
+func shouldAddNginxProxy(config *Config) bool {
+	// Cloud IDEs forward ports instead, see cloud.go
+	if config.Cloud {
+		return false
+	}
+	for _, svc := range config.Services {
+		// Queues have dashboards and MinIO its console behind the proxy
+		if len(getConfiguredDomains(&svc)) > 0 || getServicePort(&svc) > 0 || svc.Queue != "" || svc.Compat != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// getProxyTargetPort returns the port a service listens on inside its container
+func getProxyTargetPort(svc *Service) int {
+	// For nginx images, always use port 80 internally
+	if strings.Contains(strings.ToLower(svc.Image), "nginx") {
+		return 80
+	}
+
+	port := getServicePort(svc)
+	if port == 0 && len(svc.Ports) > 0 {
+		// Extract port from first port mapping
+		// Format can be: "8080:80", "127.0.0.1:8080:80", or "8080:80/tcp"
+		parts := strings.Split(svc.Ports[0], ":")
+		containerPort := parts[len(parts)-1]
+		// Remove protocol suffix if present (e.g., "80/tcp" -> "80")
+		if idx := strings.Index(containerPort, "/"); idx > 0 {
+			containerPort = containerPort[:idx]
+		}
+		fmt.Sscanf(containerPort, "%d", &port)
+	}
+	// Ruby apps listen on the port puma is started on
+	if port == 0 && isRubyService(svc) {
+		return getRubyPort(svc)
+	}
+	return port
+}
+
+// getDomainForService returns the main domain for a service, see getServiceDomains
+func getDomainForService(svc *Service) string {
+	if domains := getServiceDomains(svc); len(domains) > 0 {
+		return domains[0]
+	}
+	return ""
+}
+
+// generateNginxConfig generates nginx configuration from fleet config
+func generateNginxConfig(config *Config) (string, error) {
+	// Read the template
+	tmplContent, err := templatesFS.ReadFile("templates/nginx/nginx.conf.tmpl")
+	if err != nil {
+		return "", fmt.Errorf("failed to read nginx template: %w", err)
+	}
+
+	// Parse template
+	tmpl, err := template.New("nginx").Parse(string(tmplContent))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse nginx template: %w", err)
+	}
+
+	// Prepare services with domains
+	services := getNginxServices(config)
+
+	// Execute template
+	var buf bytes.Buffer
+	nginxConfig := NginxConfig{
+		Services:     services,
+		HasSSL:       hasSSLServices(config),
+		Unprivileged: config.Unprivileged,
+		HTTPSPort:    unprivilegedHTTPSPort,
+		HTTP3:        config.HTTP3,
+		TLSProtocols: getTLSProtocols(config),
+	}
+	if hasDebugProxy(config) {
+		nginxConfig.DebugProxy = debugProxyDomain
+	}
+	nginxConfig.QueueDashboards = getQueueDashboards(config)
+	nginxConfig.QueueDashboardAuth = config.QueueDashboardAuth != "" && len(nginxConfig.QueueDashboards) > 0
+	nginxConfig.MinIORoutes = getMinIORoutes(config)
+	if err := tmpl.Execute(&buf, nginxConfig); err != nil {
+		return "", fmt.Errorf("failed to execute nginx template: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// getNginxServices returns the services the proxy has a vhost for, in config order
+func getNginxServices(config *Config) []ServiceWithDomain {
+	services := []ServiceWithDomain{}
+	debugProxyPorts := getDebugProxyPorts(config)
+	for _, svc := range config.Services {
+		domain := getDomainForService(&svc)
+		if domain != "" {
+			// Determine the internal port for the proxy connection
+			port := getProxyTargetPort(&svc)
+			
+			svcWithDomain := ServiceWithDomain{
+				Name:            svc.Name,
+				Domain:          domain,
+				Port:            port,
+				SSL:             svc.SSL,
+				SanitizedDomain: sanitizeDomainForFilename(domain),
+			}
+			if config.Unprivileged {
+				svcWithDomain.Aliases = []string{getLocalhostAlias(&svc)}
+			}
+			svcWithDomain.Aliases = append(svcWithDomain.Aliases, getDomainAliases(&svc)...)
+			svcWithDomain.Aliases = append(svcWithDomain.Aliases, getWildcardServerNames(&svc, config.Unprivileged)...)
+
+			// Websockets get their own locations, or the whole vhost when no path is given
+			svcWithDomain.WebSocketLocations = getWebSocketLocations(&svc)
+			svcWithDomain.WebSocket = isWebSocketService(&svc) && len(svc.WebSocketPaths) == 0
+			
+			// Check if this is a PHP service
+			if strings.HasPrefix(svc.Runtime, "php") {
+				svcWithDomain.IsPHP = true
+				_, phpVersion := parsePHPRuntime(svc.Runtime)
+				svcWithDomain.PHPVersion = phpVersion
+				svcWithDomain.Framework = svc.Framework
+				svcWithDomain.ClientMaxBodySize = getClientMaxBodySize(&svc.PHPFPM)
+				// PHP-FPM listens on port 9000
+				svcWithDomain.Port = 9000
+			}
+
+			// Requests go through mitmweb, which forwards them to the service
+			if port, exists := debugProxyPorts[svc.Name]; exists {
+				svcWithDomain.Upstream = fmt.Sprintf("%s:%d", debugProxyServiceName, port)
+			}
+			// Native services run on the host, outside of the compose network
+			if isNativeService(&svc) {
+				svcWithDomain.Upstream = fmt.Sprintf("%s:%d", nativeHost, port)
+			}
+			
+			// Set SSL port
+			if svc.SSL {
+				svcWithDomain.SSLPort = 443
+				if svc.SSLPort != 0 {
+					svcWithDomain.SSLPort = svc.SSLPort
+				}
+				
+				// Set certificate paths
+				sslDir := getSSLDir(config)
+				svcWithDomain.CertPath = filepath.Join(sslDir, fmt.Sprintf("%s.crt", svcWithDomain.SanitizedDomain))
+				svcWithDomain.KeyPath = filepath.Join(sslDir, fmt.Sprintf("%s.key", svcWithDomain.SanitizedDomain))
+			}
+			
+			services = append(services, svcWithDomain)
+		}
+	}
+
+	services = mergePathRoutes(config, services)
+
+	if config.HTTP3 {
+		configureQUICListeners(services, config.Unprivileged)
+	}
+
+	return services
+}
+
+// getTLSProtocols returns the TLS versions the proxy accepts
+func getTLSProtocols(config *Config) string {
+	if config.ModernTLSOnly {
+		return "TLSv1.3"
+	}
+	return "TLSv1.2 TLSv1.3"
+}
+
+// configureQUICListeners sets up the QUIC listeners of HTTPS vhosts. nginx only accepts
+// reuseport on one listener of each port, the default server has it for 443.
+func configureQUICListeners(services []ServiceWithDomain, unprivileged bool) {
+	reused := map[int]bool{443: true}
+	for i := range services {
+		svc := &services[i]
+		if !svc.SSL {
+			continue
+		}
+		if !reused[svc.SSLPort] {
+			svc.QUICReuseport = true
+			reused[svc.SSLPort] = true
+		}
+		// Browsers reach the proxy on the published port
+		svc.AltSvcPort = svc.SSLPort
+		if unprivileged && svc.SSLPort == 443 {
+			svc.AltSvcPort = unprivilegedHTTPSPort
+		}
+	}
+}
+
+// writeNginxConfig writes nginx configuration to file
+func writeNginxConfig(config *Config, filename string) error {
+	nginxConf, err := generateNginxConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filename, []byte(nginxConf), 0644); err != nil {
+		return fmt.Errorf("failed to write nginx config: %w", err)
+	}
+
+	return nil
+}
+
+// addNginxProxyToCompose adds nginx proxy service to docker-compose
+func addNginxProxyToCompose(compose *DockerCompose, config *Config) {
+	if !shouldAddNginxProxy(config) {
+		return
+	}
+
+	// Get the project directory for absolute paths
+	cwd, err := getProjectDir(config)
+	if err != nil {
+		warnf("Warning: failed to get working directory: %v\n", err)
+		return
+	}
+
+	plan := getArtifactPlan(compose)
+	fleetDir := filepath.Join(cwd, plan.dir)
+	plan.addDir(plan.dir, 0755)
+
+	// Create the nginx config file path with absolute path
+	nginxConfigPath := filepath.Join(fleetDir, "nginx.conf")
+	nginxConf, err := generateNginxConfig(config)
+	if err != nil {
+		warnf("Warning: failed to generate nginx config: %v\n", err)
+		return
+	}
+	// Docker reads the file as another user
+	plan.addFile(plan.path("nginx.conf"), []byte(nginxConf), 0644)
+
+	// Prepare ports and volumes for nginx service
+	ports := []string{"80:80"}
+	if config.Unprivileged {
+		ports = []string{fmt.Sprintf("127.0.0.1:%d:80", unprivilegedHTTPPort)}
+	}
+	volumes := []string{formatBindMount(nginxConfigPath, "/etc/nginx/nginx.conf:ro")}
+	
+	// Add HTTPS port and SSL volumes if any service has SSL
+	if hasSSLServices(config) {
+		if config.Unprivileged {
+			ports = append(ports, fmt.Sprintf("127.0.0.1:%d:443", unprivilegedHTTPSPort))
+		} else {
+			ports = append(ports, "443:443")
+		}
+		// HTTP/3 runs over QUIC, on UDP
+		if config.HTTP3 {
+			ports = append(ports, ports[len(ports)-1]+"/udp")
+		}
+		
+		// Generate the certificates and mount the SSL directory
+		planSSLCertificates(plan, config)
+		volumes = append(volumes, formatBindMount(filepath.Join(cwd, getSSLDir(config)), "/etc/nginx/ssl:ro"))
+	}
+	
+	// Mount the user of the queue dashboards
+	dashboards := getQueueDashboards(config)
+	if config.QueueDashboardAuth != "" && len(dashboards) > 0 {
+		authPath := filepath.Join(fleetDir, queueDashboardAuthFile)
+		planQueueDashboardAuth(plan, config, plan.path(queueDashboardAuthFile))
+		volumes = append(volumes, formatBindMount(authPath, "/etc/nginx/"+queueDashboardAuthFile+":ro"))
+	}
+
+	// Mount PHP application directories for serving static files
+	for _, svc := range config.Services {
+		if strings.HasPrefix(svc.Runtime, "php") && svc.Folder != "" && getDomainForService(&svc) != "" {
+			// Mount each PHP service folder to nginx
+			volumes = append(volumes, formatBindMount("../"+svc.Folder, "/var/www/html/"+svc.Name))
+		}
+	}
+	
+	// Add nginx proxy service with absolute path for the volume mount
+	nginxService := DockerService{
+		Image:    "nginx:alpine",
+		Ports:    ports,
+		Volumes:  volumes,
+		Networks: []string{"fleet-network"},
+		Restart:  "unless-stopped",
+		DependsOn: []string{},
+		HealthCheck: &HealthCheckYAML{
+			Test:     []string{"CMD", "wget", "--no-verbose", "--tries=1", "--spider", "http://localhost/health"},
+			Interval: "30s",
+			Timeout:  "3s",
+			Retries:  3,
+		},
+	}
+
+	// Add all services with domains as dependencies
+	for _, svc := range config.Services {
+		if getDomainForService(&svc) != "" && !isNativeService(&svc) {
+			nginxService.DependsOn = append(nginxService.DependsOn, svc.Name)
+		}
+	}
+	if hasNativeServices(config) {
+		nginxService.ExtraHosts = []string{nativeHost + ":" + hostGateway}
+	}
+	if hasDebugProxy(config) {
+		nginxService.DependsOn = append(nginxService.DependsOn, debugProxyServiceName)
+	}
+	for _, dashboard := range dashboards {
+		if !containsString(nginxService.DependsOn, dashboard.Service) {
+			nginxService.DependsOn = append(nginxService.DependsOn, dashboard.Service)
+		}
+	}
+	for _, route := range getMinIORoutes(config) {
+		nginxService.DependsOn = append(nginxService.DependsOn, route.Service)
+	}
+
+	compose.Services["nginx-proxy"] = nginxService
+}
+
+// getDomainMappings returns all domain to IP mappings for hosts file
+func getDomainMappings(config *Config) map[string]string {
+	mappings := make(map[string]string)
+	
+	for _, svc := range config.Services {
+		// All domains point to localhost where nginx is listening. The hosts file
+		// can't hold wildcards, Fleet DNS resolves the other tenants.
+		for _, domain := range getHostsDomains(&svc) {
+			mappings[domain] = "127.0.0.1"
+		}
+	}
+	if hasDebugProxy(config) {
+		mappings[debugProxyDomain] = "127.0.0.1"
+	}
+	for _, dashboard := range getQueueDashboards(config) {
+		mappings[dashboard.Domain] = "127.0.0.1"
+		if containsString(dashboard.Aliases, queueDashboardDomain) {
+			mappings[queueDashboardDomain] = "127.0.0.1"
+		}
+	}
+	for _, route := range getMinIORoutes(config) {
+		mappings[route.Domain] = "127.0.0.1"
+		mappings[route.ConsoleDomain] = "127.0.0.1"
+	}
+	
+	return mappings
+}
+
+// getHostsFilePath returns the path to the system hosts file
+var getHostsFilePath = func() string {
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(os.Getenv("SYSTEMROOT"), "System32", "drivers", "etc", "hosts")
+	default:
+		return "/etc/hosts"
+	}
+}
+
+// updateHostsFileWithDomains updates the hosts file with service domains
+func updateHostsFileWithDomains(config *Config) error {
+	mappings := getDomainMappings(config)
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	hostsFile := getHostsFilePath()
+	
+	// Read current hosts file
+	content, err := os.ReadFile(hostsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read hosts file: %w", err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	newLines := []string{}
+	inFleetSection := false
+	
+	// Process existing lines, removing old Fleet service entries
+	for _, line := range lines {
+		if strings.Contains(line, "# Fleet Services - START") {
+			inFleetSection = true
+			continue
+		}
+		if strings.Contains(line, "# Fleet Services - END") {
+			inFleetSection = false
+			continue
+		}
+		if !inFleetSection {
+			newLines = append(newLines, line)
+		}
+	}
+
+	// Add new Fleet service entries
+	if len(mappings) > 0 {
+		newLines = append(newLines, "# Fleet Services - START")
+		newLines = append(newLines, formatHostsEntries(mappings)...)
+		newLines = append(newLines, "# Fleet Services - END")
+	}
+
+	// Write back to hosts file with privilege elevation if needed
+	newContent := strings.Join(newLines, "\n")
+	done := guardHostsEdit(hostsFile, content)
+	defer done()
+	if err := WriteFileWithPrivileges(hostsFile, []byte(newContent), 0644); err != nil {
+		return fmt.Errorf("failed to write hosts file: %w", err)
+	}
+
+	return nil
+}
+
+// removeDomainsFromHostsFile removes Fleet service domains from hosts file
+func removeDomainsFromHostsFile() error {
+	hostsFile := getHostsFilePath()
+	
+	content, err := os.ReadFile(hostsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read hosts file: %w", err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	newLines := []string{}
+	inFleetSection := false
+	
+	for _, line := range lines {
+		if strings.Contains(line, "# Fleet Services - START") {
+			inFleetSection = true
+			continue
+		}
+		if strings.Contains(line, "# Fleet Services - END") {
+			inFleetSection = false
+			continue
+		}
+		if !inFleetSection {
+			newLines = append(newLines, line)
+		}
+	}
+
+	newContent := strings.Join(newLines, "\n")
+	done := guardHostsEdit(hostsFile, content)
+	defer done()
+	return WriteFileWithPrivileges(hostsFile, []byte(newContent), 0644)
+}
\ No newline at end of file
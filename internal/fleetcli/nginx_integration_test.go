@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"os"
@@ -57,6 +57,7 @@ func (suite *NginxIntegrationTestSuite) TestNginxVolumeMount_FileExistsBeforeDoc
 
 	// When: Generating docker-compose with nginx proxy
 	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
 
 	// Then: nginx.conf should be created and accessible before docker-compose.yml
 	nginxConfigPath := filepath.Join(suite.tempDir, ".fleet", "nginx.conf")
@@ -117,6 +118,7 @@ func (suite *NginxIntegrationTestSuite) TestNginxVolumeMount_DockerComposeYAML()
 
 	// When: Generating and writing docker-compose
 	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
 	composeFile := filepath.Join(suite.tempDir, "docker-compose.yml")
 	err := writeDockerCompose(compose, composeFile)
 	suite.NoError(err, "Should write docker-compose.yml successfully")
@@ -179,6 +181,7 @@ func (suite *NginxIntegrationTestSuite) TestNginxVolumeMount_NoDirectoryCreation
 
 	// When: Generating docker-compose
 	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
 
 	// Then: Verify nginx.conf is a file, not a directory
 	fleetDir := filepath.Join(suite.tempDir, ".fleet")
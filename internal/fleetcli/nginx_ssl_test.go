@@ -1,6 +1,7 @@
-package main
+package fleetcli
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -158,17 +159,11 @@ func (suite *NginxSSLSuite) TestServiceWithDomainStructure() {
 }
 
 func (suite *NginxSSLSuite) TestGenerateNginxSSLConfig() {
-	// Create test certificates
-	certs := []SSLCertificate{
-		{
-			Domain:   "test.local",
-			CertPath: "/tmp/test.crt",
-			KeyPath:  "/tmp/test.key",
-		},
-	}
-
-	sslDir := suite.helper.TempDir()
-	err := generateNginxSSLConfig(certs, sslDir)
+	config := &Config{}
+	sslDir := getSSLDir(config)
+	plan := newArtifactPlan()
+	planNginxSSLConfig(plan, config)
+	err := writeArtifacts(plan)
 	assert.NoError(suite.T(), err)
 
 	// Check SSL params file was created
@@ -186,6 +181,57 @@ func (suite *NginxSSLSuite) TestGenerateNginxSSLConfig() {
 	assert.Contains(suite.T(), string(content), "ssl_session_cache shared:SSL:10m")
 }
 
+func (suite *NginxSSLSuite) TestNginxConfigWithHTTP3() {
+	config := &Config{
+		Project: "test-project",
+		HTTP3:   true,
+		Services: []Service{
+			{Name: "web", Domain: "web.test", Port: 80, SSL: true},
+			{Name: "api", Domain: "api.test", Port: 8080, SSL: true},
+			{Name: "admin", Domain: "admin.test", Port: 8000, SSL: true, SSLPort: 8443},
+			{Name: "docs", Domain: "docs.test", Port: 3000, SSL: true, SSLPort: 8443},
+		},
+	}
+
+	nginxConf, err := generateNginxConfig(config)
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), nginxConf, "listen 443 quic reuseport default_server;")
+	assert.Equal(suite.T(), 2, strings.Count(nginxConf, "listen 443 quic;"), "Only the default server reuses 443")
+	assert.Equal(suite.T(), 1, strings.Count(nginxConf, "listen 8443 quic reuseport;"), "The first vhost of another port reuses it")
+	assert.Equal(suite.T(), 1, strings.Count(nginxConf, "listen 8443 quic;"))
+	assert.Contains(suite.T(), nginxConf, `add_header Alt-Svc 'h3=":443"; ma=86400' always;`)
+	assert.Contains(suite.T(), nginxConf, `add_header Alt-Svc 'h3=":8443"; ma=86400' always;`)
+
+	compose := newDockerCompose()
+	addNginxProxyToCompose(compose, config)
+	assert.Contains(suite.T(), compose.Services["nginx-proxy"].Ports, "443:443/udp")
+
+	config.Unprivileged = true
+	nginxConf, err = generateNginxConfig(config)
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), nginxConf, fmt.Sprintf(`add_header Alt-Svc 'h3=":%d"; ma=86400' always;`, unprivilegedHTTPSPort))
+	compose = newDockerCompose()
+	addNginxProxyToCompose(compose, config)
+	assert.Contains(suite.T(), compose.Services["nginx-proxy"].Ports, fmt.Sprintf("127.0.0.1:%d:443/udp", unprivilegedHTTPSPort))
+}
+
+func (suite *NginxSSLSuite) TestNginxConfigModernTLSOnly() {
+	config := &Config{
+		Project:  "test-project",
+		Services: []Service{{Name: "web", Domain: "web.test", Port: 80, SSL: true}},
+	}
+	nginxConf, err := generateNginxConfig(config)
+	assert.NoError(suite.T(), err)
+	assert.NotContains(suite.T(), nginxConf, "quic", "HTTP/3 is off by default")
+	assert.NotContains(suite.T(), nginxConf, "Alt-Svc")
+
+	config.ModernTLSOnly = true
+	nginxConf, err = generateNginxConfig(config)
+	assert.NoError(suite.T(), err)
+	assert.NotContains(suite.T(), nginxConf, "TLSv1.2")
+	assert.Equal(suite.T(), 2, strings.Count(nginxConf, "ssl_protocols TLSv1.3;"), "The default server and the vhost")
+}
+
 func TestNginxSSLSuite(t *testing.T) {
 	suite.Run(t, new(NginxSSLSuite))
 }
\ No newline at end of file
@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"os"
@@ -267,6 +267,7 @@ func (suite *NginxTestSuite) TestAddNginxProxyToCompose_AddsService() {
 
 	// When: adding nginx proxy to compose
 	addNginxProxyToCompose(compose, config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
 
 	// Then: nginx-proxy service should be added
 	nginxService, exists := compose.Services["nginx-proxy"]
@@ -351,6 +352,7 @@ func (suite *NginxTestSuite) TestAddNginxProxyToCompose_FileCreationOrder() {
 
 	// When: adding nginx proxy to compose
 	addNginxProxyToCompose(compose, config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
 
 	// Then: verify the file was created before being referenced
 	nginxService, exists := compose.Services["nginx-proxy"]
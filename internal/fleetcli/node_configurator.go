@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -19,16 +19,8 @@ type NodeConfigurator struct {
 func NewNodeConfigurator() *NodeConfigurator {
 	nc := &NodeConfigurator{
 		frameworkDetectors: make(map[string]FrameworkDetector),
-		supportedVersions: map[string]string{
-			"16":       "node:16-alpine",
-			"18":       "node:18-alpine",
-			"20":       "node:20-alpine",
-			"22":       "node:22-alpine",
-			"latest":   "node:20-alpine",
-			"lts":      "node:20-alpine",
-			"default":  "node:20-alpine",
-		},
-		defaultVersion: "20",
+		supportedVersions: supportedNodeVersions,
+		defaultVersion: versionData.Node.Default,
 	}
 	
 	// Register framework detectors
@@ -125,7 +117,7 @@ func (nc *NodeConfigurator) BuildNodeService(svc *Service) *DockerService {
 	
 	// Mount folder
 	if svc.Folder != "" {
-		nodeService.Volumes = append(nodeService.Volumes, fmt.Sprintf("../%s:%s", svc.Folder, workDir))
+		nodeService.Volumes = append(nodeService.Volumes, formatBindMount("../"+svc.Folder, workDir))
 		
 		// Add node_modules volume for better performance
 		if !isBuildMode {
@@ -137,13 +129,13 @@ func (nc *NodeConfigurator) BuildNodeService(svc *Service) *DockerService {
 	// Detect package manager
 	packageManager := svc.PackageManager
 	if packageManager == "" {
-		packageManager = detectPackageManager(svc.Folder)
+		packageManager = detectPackageManager(svc.hostPath(svc.Folder))
 	}
 	
 	// Detect framework
 	framework := svc.Framework
 	if framework == "" {
-		framework = nc.DetectFramework(svc.Folder)
+		framework = nc.DetectFramework(svc.hostPath(svc.Folder))
 	}
 	
 	// Configure based on mode
@@ -164,7 +156,9 @@ func (nc *NodeConfigurator) BuildNodeService(svc *Service) *DockerService {
 	
 	// Add custom volumes
 	if len(svc.Volumes) > 0 {
-		nodeService.Volumes = append(nodeService.Volumes, svc.Volumes...)
+		for _, vol := range svc.Volumes {
+			nodeService.Volumes = append(nodeService.Volumes, normalizeVolume(vol))
+		}
 	}
 	
 	return nodeService
@@ -178,7 +172,7 @@ func (nc *NodeConfigurator) configureBuildMode(nodeService *DockerService, svc *
 	// Determine build command
 	buildCommand := svc.BuildCommand
 	if buildCommand == "" {
-		buildCommand = nc.getBuildCommand(svc.Folder, packageManager, framework)
+		buildCommand = nc.getBuildCommand(svc.hostPath(svc.Folder), packageManager, framework)
 	}
 	
 	// Create build script
@@ -202,7 +196,7 @@ func (nc *NodeConfigurator) configureBuildMode(nodeService *DockerService, svc *
 	nodeService.Command = buildScript
 	
 	// Add output volume for build artifacts
-	nodeService.Volumes = append(nodeService.Volumes, "../.fleet/build-output:/output")
+	nodeService.Volumes = append(nodeService.Volumes, formatBindMount("../.fleet/build-output", "/output"))
 }
 
 // configureServiceMode configures a Node.js container for long-running services
@@ -210,7 +204,7 @@ func (nc *NodeConfigurator) configureServiceMode(nodeService *DockerService, svc
 	// Determine start command
 	startCommand := svc.Command
 	if startCommand == "" {
-		startCommand = nc.getStartCommand(svc.Folder, packageManager, framework, svc.NodeEnv == "development")
+		startCommand = nc.getStartCommand(svc.hostPath(svc.Folder), packageManager, framework, svc.NodeEnv == "development")
 	}
 	
 	// Determine port
@@ -219,7 +213,7 @@ func (nc *NodeConfigurator) configureServiceMode(nodeService *DockerService, svc
 		nodeService.Environment["PORT"] = fmt.Sprintf("%d", port)
 		
 		// Only expose port if no domain (services with domains use nginx proxy)
-		if svc.Domain == "" && svc.Port > 0 {
+		if len(getConfiguredDomains(svc)) == 0 && svc.Port > 0 {
 			nodeService.Ports = []string{fmt.Sprintf("%d:%d", svc.Port, port)}
 		}
 	}
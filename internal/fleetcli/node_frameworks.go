@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"encoding/json"
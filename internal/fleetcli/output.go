@@ -0,0 +1,205 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Output levels, from least to most verbose
+const (
+	// outputQuiet prints errors, warnings and results only
+	outputQuiet = iota
+	// outputNormal also prints banners, progress and hints
+	outputNormal
+)
+
+// Environment variables that set the output flags for every command, e.g. in CI
+const (
+	quietEnvVar   = "FLEET_QUIET"
+	noEmojiEnvVar = "FLEET_NO_EMOJI"
+	ciEnvVar      = "FLEET_CI"
+	// ciServiceEnvVar is set by GitHub Actions, GitLab CI and most other CI services
+	ciServiceEnvVar = "CI"
+)
+
+var (
+	// outputLevel is set to outputQuiet by --quiet
+	outputLevel = outputNormal
+	// outputNoEmoji is set by --no-emoji
+	outputNoEmoji = false
+	// outputCI is set by --ci, long steps print lines instead of redrawing a spinner
+	outputCI = false
+)
+
+// isEnvEnabled reports whether a boolean environment variable is set to something other than 0 or false
+func isEnvEnabled(name string) bool {
+	value := strings.ToLower(os.Getenv(name))
+	return value != "" && value != "0" && value != "false"
+}
+
+// configureOutput applies the output flags given before the command (fleet --quiet up)
+// and returns args without them. Arguments after the command belong to it: commands
+// take the flags with their options, see newCommandFlagSet, and exec, run, php and node
+// pass them on to the program they run.
+func configureOutput(args []string) []string {
+	if isEnvEnabled(quietEnvVar) {
+		outputLevel = outputQuiet
+	}
+	if isEnvEnabled(noEmojiEnvVar) {
+		outputNoEmoji = true
+	}
+	if isEnvEnabled(ciEnvVar) || isEnvEnabled(ciServiceEnvVar) {
+		outputCI = true
+	}
+
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "-") && setOutputFlag(strings.TrimLeft(args[i], "-")) {
+		i++
+	}
+
+	if outputNoEmoji {
+		log.SetOutput(emojiWriter{os.Stderr})
+	}
+	return args[i:]
+}
+
+// setOutputFlag applies an output flag given without its dashes, and reports whether
+// it is one
+func setOutputFlag(name string) bool {
+	switch name {
+	case "q", "quiet":
+		outputLevel = outputQuiet
+	case "no-emoji":
+		outputNoEmoji = true
+		log.SetOutput(emojiWriter{os.Stderr})
+	case "ci":
+		outputCI = true
+	default:
+		return false
+	}
+	return true
+}
+
+// newCommandFlagSet returns the flag set of a command with the output flags, so they
+// also work among the options of the command (fleet up -d --quiet)
+func newCommandFlagSet(name string, errorHandling flag.ErrorHandling) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, errorHandling)
+	for flagName, usage := range map[string]string{
+		"q":        "Only print errors, warnings and results",
+		"quiet":    "Only print errors, warnings and results",
+		"no-emoji": "Print plain text without emoji",
+		"ci":       "Print a line when each step starts and ends instead of a spinner",
+	} {
+		flagName := flagName
+		fs.BoolFunc(flagName, usage, func(value string) error {
+			if enabled, err := strconv.ParseBool(value); err != nil || enabled {
+				setOutputFlag(flagName)
+			}
+			return nil
+		})
+	}
+	return fs
+}
+
+// isEmoji checks if a rune is an emoji or one of the invisible characters that shape them
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	case r >= 0x2190 && r <= 0x21FF, r >= 0x2300 && r <= 0x23FF, r >= 0x2600 && r <= 0x27BF, r >= 0x2B00 && r <= 0x2BFF:
+		return true
+	case r == 0x2139, r == 0x200D, r == 0xFE0F:
+		return true
+	}
+	return false
+}
+
+// stripEmoji removes emoji and the spaces that separate them from the text after them
+func stripEmoji(s string) string {
+	var b strings.Builder
+	stripped := false
+	for _, r := range s {
+		if isEmoji(r) {
+			stripped = true
+			continue
+		}
+		if stripped && r == ' ' {
+			continue
+		}
+		stripped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// emojiOr returns emoji, or text when emoji are turned off. Use it for emoji that carry
+// meaning, like the status markers of a list.
+func emojiOr(emoji, text string) string {
+	if outputNoEmoji {
+		return text
+	}
+	return emoji
+}
+
+// emojiWriter strips emoji from everything written through it
+type emojiWriter struct {
+	w io.Writer
+}
+
+func (e emojiWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(e.w, stripEmoji(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeOutput writes s to w, without emoji if they are turned off.
+// os.Stdout is looked up on every call so tests can capture it.
+func writeOutput(w io.Writer, s string) {
+	if outputNoEmoji {
+		s = stripEmoji(s)
+	}
+	io.WriteString(w, s)
+}
+
+// infof prints banners, progress and hints. --quiet hides them.
+func infof(format string, args ...interface{}) {
+	if outputLevel < outputNormal {
+		return
+	}
+	writeOutput(os.Stdout, fmt.Sprintf(format, args...))
+}
+
+// infoln is infof with the formatting of fmt.Println
+func infoln(args ...interface{}) {
+	if outputLevel < outputNormal {
+		return
+	}
+	writeOutput(os.Stdout, fmt.Sprintln(args...))
+}
+
+// outputf prints results that scripts rely on, like status and lists. It is never hidden.
+func outputf(format string, args ...interface{}) {
+	writeOutput(os.Stdout, fmt.Sprintf(format, args...))
+}
+
+// outputln is outputf with the formatting of fmt.Println
+func outputln(args ...interface{}) {
+	writeOutput(os.Stdout, fmt.Sprintln(args...))
+}
+
+// warnf prints a warning to stderr, so it doesn't end up in output scripts parse.
+// Warnings are shown even with --quiet.
+func warnf(format string, args ...interface{}) {
+	writeOutput(os.Stderr, fmt.Sprintf(format, args...))
+}
+
+// warnln is warnf with the formatting of fmt.Println
+func warnln(args ...interface{}) {
+	writeOutput(os.Stderr, fmt.Sprintln(args...))
+}
@@ -0,0 +1,141 @@
+package fleetcli
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OutputTestSuite struct {
+	suite.Suite
+}
+
+func (suite *OutputTestSuite) SetupTest() {
+	suite.T().Setenv(quietEnvVar, "")
+	suite.T().Setenv(noEmojiEnvVar, "")
+	outputLevel = outputNormal
+	outputNoEmoji = false
+}
+
+func (suite *OutputTestSuite) TearDownTest() {
+	outputLevel = outputNormal
+	outputNoEmoji = false
+	outputCI = false
+	log.SetOutput(os.Stderr)
+}
+
+// captureStdout returns what fn prints to stdout
+func (suite *OutputTestSuite) captureStdout(fn func()) string {
+	r, w, err := os.Pipe()
+	suite.Require().NoError(err)
+	oldStdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = oldStdout }()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	suite.Require().NoError(err)
+	return string(out)
+}
+
+func (suite *OutputTestSuite) TestConfigureOutput() {
+	testCases := []struct {
+		name    string
+		args    []string
+		rest    []string
+		quiet   bool
+		noEmoji bool
+	}{
+		{"no flags", []string{"up", "-d"}, []string{"up", "-d"}, false, false},
+		{"before the command", []string{"--quiet", "--no-emoji", "up"}, []string{"up"}, true, true},
+		{"after the command", []string{"up", "-d", "-q"}, []string{"up", "-d", "-q"}, false, false},
+		{"passed to a program", []string{"exec", "api", "php", "--no-emoji"}, []string{"exec", "api", "php", "--no-emoji"}, false, false},
+		{"only flags", []string{"-q"}, []string{}, true, false},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			outputLevel = outputNormal
+			outputNoEmoji = false
+			suite.Equal(tc.rest, configureOutput(tc.args))
+			suite.Equal(tc.quiet, outputLevel == outputQuiet)
+			suite.Equal(tc.noEmoji, outputNoEmoji)
+		})
+	}
+}
+
+func (suite *OutputTestSuite) TestCommandFlagSet() {
+	fs := newCommandFlagSet("up", flag.ContinueOnError)
+	detach := fs.Bool("d", false, "Detach")
+	args := parseFlagsAndArgs(fs, []string{"api", "-d", "--quiet", "--no-emoji"})
+
+	suite.Equal([]string{"api"}, args)
+	suite.True(*detach)
+	suite.Equal(outputQuiet, outputLevel)
+	suite.True(outputNoEmoji)
+
+	outputLevel = outputNormal
+	suite.Require().NoError(fs.Parse([]string{"--quiet=false"}))
+	suite.Equal(outputNormal, outputLevel)
+}
+
+func (suite *OutputTestSuite) TestConfigureOutputFromEnv() {
+	suite.T().Setenv(quietEnvVar, "1")
+	suite.T().Setenv(noEmojiEnvVar, "true")
+
+	configureOutput([]string{"status"})
+	suite.Equal(outputQuiet, outputLevel)
+	suite.True(outputNoEmoji)
+}
+
+func (suite *OutputTestSuite) TestStripEmoji() {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"🚀 Starting Fleet project: shop\n", "Starting Fleet project: shop\n"},
+		{"⚠️  Warning: failed\n", "Warning: failed\n"},
+		{"\n↩️  Restored /etc/hosts\n", "\nRestored /etc/hosts\n"},
+		{"ℹ️  No services", "No services"},
+		{"plain text -> kept", "plain text -> kept"},
+		{"café ü 日本", "café ü 日本"},
+	}
+
+	for _, tc := range testCases {
+		suite.Equal(tc.expected, stripEmoji(tc.input))
+	}
+}
+
+func (suite *OutputTestSuite) TestQuietHidesInfo() {
+	out := suite.captureStdout(func() {
+		infof("🚀 Starting %s\n", "shop")
+		outputf("web running\n")
+	})
+	suite.Equal("🚀 Starting shop\nweb running\n", out)
+
+	outputLevel = outputQuiet
+	out = suite.captureStdout(func() {
+		infof("🚀 Starting %s\n", "shop")
+		infoln("✅ Services started")
+		outputf("web running\n")
+	})
+	suite.Equal("web running\n", out)
+}
+
+func (suite *OutputTestSuite) TestNoEmoji() {
+	outputNoEmoji = true
+	out := suite.captureStdout(func() {
+		infoln("✅ Services started")
+		outputf("  %s %s\n", emojiOr("✅", "ok"), "web.test")
+	})
+	suite.Equal("Services started\n  ok web.test\n", out)
+}
+
+func TestOutputSuite(t *testing.T) {
+	suite.Run(t, new(OutputTestSuite))
+}
@@ -0,0 +1,84 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProject is the project name of configs without one
+const DefaultProject = "fleet-project"
+
+// Parse decodes a config in the format of a file extension: .toml, .yaml, .yml or
+// .json. It doesn't validate it.
+func Parse(data []byte, ext string) (*Config, error) {
+	var config Config
+	var err error
+	switch ext {
+	case ".toml":
+		err = toml.Unmarshal(data, &config)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &config)
+	case ".json":
+		err = json.Unmarshal(data, &config)
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s (use .toml, .yaml, .yml, or .json)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return &config, nil
+}
+
+// Load reads a config file and validates it. Unlike the fleet command, it doesn't fetch
+// remote configs.
+func Load(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	config, err := Parse(data, filepath.Ext(filename))
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(config); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	config.ArtifactSet = getArtifactSetName(filename)
+	return config, nil
+}
+
+// validateStructure checks what every config needs: services with a name, and an image,
+// a build or an addon providing the container. A config without a project gets
+// DefaultProject.
+func validateStructure(config *Config) error {
+	if config.Project == "" {
+		config.Project = DefaultProject
+	}
+
+	if len(config.Services) == 0 {
+		return fmt.Errorf("no services defined")
+	}
+
+	for i, svc := range config.Services {
+		if svc.Name == "" {
+			return fmt.Errorf("service #%d: name is required", i+1)
+		}
+		if !hasAddon(&svc) && svc.Image == "" && svc.Build == "" {
+			return fmt.Errorf("service %s: either 'image' or 'build' is required", svc.Name)
+		}
+	}
+	return nil
+}
+
+// hasAddon reports whether a service uses an addon that provides its container or
+// doesn't need one, like a database or a PHP runtime
+func hasAddon(svc *Service) bool {
+	return svc.Database != "" || svc.Cache != "" || svc.Search != "" || svc.Email != "" ||
+		svc.Compat != "" || svc.Runtime != "" || svc.Mock != "" || svc.ExternalService != "" ||
+		svc.AI != "" || svc.Queue != "" || svc.Native
+}
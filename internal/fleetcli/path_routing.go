@@ -0,0 +1,158 @@
+package fleetcli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PathRoute is a location of a vhost that sends a path prefix to a service sharing
+// the domain, e.g. /api/ of app.test to the api container
+type PathRoute struct {
+	Path      string // e.g. /api/
+	Prefix    string // Path without its trailing slash, e.g. /api
+	Service   string
+	Upstream  string // host:port requests go to
+	StripPath bool   // Removes the prefix before the request reaches the service
+	WebSocket bool   // Gets timeouts long enough for idle websockets
+}
+
+// getRoutePath returns the path prefix a service is routed on, with a leading and a
+// trailing slash, or "" when it is served from the root of its domain
+func getRoutePath(svc *Service) string {
+	path := strings.Trim(strings.TrimSpace(svc.Path), "/")
+	if path == "" {
+		return ""
+	}
+	return "/" + path + "/"
+}
+
+// getDomainOwner returns the service whose vhost serves a domain: the one serving it
+// from the root, or the first one routed on a path of it
+func getDomainOwner(config *Config, domain string) *Service {
+	var owner *Service
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if !containsString(getServiceDomains(svc), domain) {
+			continue
+		}
+		if getRoutePath(svc) == "" {
+			return svc
+		}
+		if owner == nil {
+			owner = svc
+		}
+	}
+	return owner
+}
+
+// validatePathRouting checks the path and strip_path options of the services
+func validatePathRouting(config *Config) error {
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if svc.Path == "" {
+			if svc.StripPath {
+				return fmt.Errorf("service %s: 'strip_path' requires 'path'", svc.Name)
+			}
+			continue
+		}
+		if !strings.HasPrefix(svc.Path, "/") || strings.ContainsAny(svc.Path, " \t\n;{}*?#") {
+			return fmt.Errorf("service %s: invalid path %q, use a prefix like /api", svc.Name, svc.Path)
+		}
+		if getRoutePath(svc) == "" {
+			if svc.StripPath {
+				return fmt.Errorf("service %s: 'strip_path' requires a path other than /", svc.Name)
+			}
+			continue
+		}
+
+		domains := getConfiguredDomains(svc)
+		switch {
+		case len(domains) == 0:
+			return fmt.Errorf("service %s: 'path' requires the domain it is routed on, e.g. domain = \"app.test\"", svc.Name)
+		case len(domains) > 1:
+			return fmt.Errorf("service %s: 'path' routes a single domain, found %s", svc.Name, strings.Join(domains, ", "))
+		// PHP-FPM is reached over FastCGI from the root of the vhost of its app
+		case strings.HasPrefix(svc.Runtime, "php"):
+			return fmt.Errorf("service %s: PHP services are served from the root of their domain, remove 'path'", svc.Name)
+		}
+		// The services share one vhost, and its SSL settings
+		if owner := getDomainOwner(config, domains[0]); owner != svc && (owner.SSL != svc.SSL || owner.SSL && getSSLPort(owner) != getSSLPort(svc)) {
+			return fmt.Errorf("services %s and %s share domain %s, so they need the same ssl and ssl_port", owner.Name, svc.Name, domains[0])
+		}
+	}
+	return nil
+}
+
+// getSSLPort returns the port a service with ssl is served on
+func getSSLPort(svc *Service) int {
+	if svc.SSLPort != 0 {
+		return svc.SSLPort
+	}
+	return 443
+}
+
+// mergePathRoutes moves the vhosts of services routed on a path into the vhost of their
+// domain as locations. Domains no service serves from the root keep a vhost with only
+// those locations.
+func mergePathRoutes(config *Config, vhosts []ServiceWithDomain) []ServiceWithDomain {
+	routed := make(map[string]*Service)
+	for i := range config.Services {
+		if svc := &config.Services[i]; getRoutePath(svc) != "" {
+			routed[svc.Name] = svc
+		}
+	}
+	if len(routed) == 0 {
+		return vhosts
+	}
+
+	var merged []ServiceWithDomain
+	for _, vhost := range vhosts {
+		if routed[vhost.Name] == nil {
+			merged = append(merged, vhost)
+		}
+	}
+	for _, vhost := range vhosts {
+		svc := routed[vhost.Name]
+		if svc == nil {
+			continue
+		}
+		owner := -1
+		for i := range merged {
+			if merged[i].Domain == vhost.Domain || containsString(merged[i].Aliases, vhost.Domain) {
+				owner = i
+				break
+			}
+		}
+		if owner == -1 {
+			merged = append(merged, vhost)
+			owner = len(merged) - 1
+			merged[owner].PathsOnly = true
+			merged[owner].WebSocketLocations = nil
+		} else if config.Unprivileged {
+			// <name>.localhost/<path> reaches the service too
+			if alias := getLocalhostAlias(svc); !containsString(merged[owner].Aliases, alias) {
+				merged[owner].Aliases = append(merged[owner].Aliases, alias)
+			}
+		}
+
+		upstream := vhost.Upstream
+		if upstream == "" {
+			upstream = fmt.Sprintf("%s:%d", vhost.Name, vhost.Port)
+		}
+		path := getRoutePath(svc)
+		merged[owner].PathRoutes = append(merged[owner].PathRoutes, PathRoute{
+			Path:      path,
+			Prefix:    strings.TrimSuffix(path, "/"),
+			Service:   svc.Name,
+			Upstream:  upstream,
+			StripPath: svc.StripPath,
+			WebSocket: isWebSocketService(svc) && len(svc.WebSocketPaths) == 0,
+		})
+		// The vhost of the service is gone, its websocket paths go straight to it
+		for _, location := range vhost.WebSocketLocations {
+			location.Upstream = upstream
+			merged[owner].WebSocketLocations = append(merged[owner].WebSocketLocations, location)
+		}
+	}
+	return merged
+}
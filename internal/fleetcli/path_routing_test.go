@@ -0,0 +1,143 @@
+package fleetcli
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PathRoutingTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+	config      *Config
+}
+
+func (suite *PathRoutingTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+
+	suite.config = &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Domain: "app.test", Path: "/api", StripPath: true},
+		{Name: "frontend", Image: "node:20", Port: 5173, Domain: "app.test"},
+		{Name: "admin", Image: "node:20", Port: 4000, Domain: "app.test", Path: "/admin/"},
+	}}
+}
+
+func (suite *PathRoutingTestSuite) TearDownTest() {
+	os.Chdir(suite.originalDir)
+	suite.helper.Cleanup()
+}
+
+func (suite *PathRoutingTestSuite) TestGetRoutePath() {
+	suite.Equal("/api/", getRoutePath(&Service{Path: "/api"}))
+	suite.Equal("/v1/api/", getRoutePath(&Service{Path: "/v1/api/"}))
+	suite.Equal("", getRoutePath(&Service{Path: "/"}))
+	suite.Equal("", getRoutePath(&Service{}))
+}
+
+func (suite *PathRoutingTestSuite) TestValidatePathRouting() {
+	suite.NoError(validatePathRouting(suite.config))
+	suite.NoError(validateDomains(suite.config))
+
+	invalid := []Service{
+		{Name: "api", Port: 3000, StripPath: true, Domain: "app.test"},
+		{Name: "api", Port: 3000, Path: "api", Domain: "app.test"},
+		{Name: "api", Port: 3000, Path: "/api/*", Domain: "app.test"},
+		{Name: "api", Port: 3000, Path: "/api"},
+		{Name: "api", Port: 3000, Path: "/api", Domains: []string{"app.test", "api.test"}},
+		{Name: "api", Runtime: "php:8.3", Path: "/api", Domain: "app.test"},
+	}
+	for _, svc := range invalid {
+		suite.Error(validatePathRouting(&Config{Services: []Service{svc}}), "path %q", svc.Path)
+	}
+
+	// The services share one vhost and its certificate
+	suite.config.Services[1].SSL = true
+	err := validatePathRouting(suite.config)
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "services frontend and api share domain app.test")
+
+	// Two services can't be routed on the same path
+	err = validateDomains(&Config{Services: []Service{
+		{Name: "api", Port: 3000, Domain: "app.test", Path: "/api"},
+		{Name: "legacy", Port: 3001, Domain: "app.test", Path: "/api/"},
+	}})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "services api and legacy both route app.test/api/")
+}
+
+func (suite *PathRoutingTestSuite) TestNginxLocations() {
+	services := getNginxServices(suite.config)
+	suite.Require().Len(services, 1)
+	suite.Equal("frontend", services[0].Name)
+	suite.False(services[0].PathsOnly)
+	suite.Equal([]PathRoute{
+		{Path: "/api/", Prefix: "/api", Service: "api", Upstream: "api:3000", StripPath: true},
+		{Path: "/admin/", Prefix: "/admin", Service: "admin", Upstream: "admin:4000"},
+	}, services[0].PathRoutes)
+
+	nginxConf, err := generateNginxConfig(suite.config)
+	suite.Require().NoError(err)
+	suite.Contains(nginxConf, "location ^~ /api/ {\n            proxy_pass http://api:3000/;")
+	suite.Contains(nginxConf, "proxy_set_header X-Forwarded-Prefix /api;")
+	suite.Contains(nginxConf, "location ^~ /admin/ {\n            proxy_pass http://admin:4000;")
+	suite.Contains(nginxConf, "proxy_pass http://frontend_backend;")
+	suite.Equal(1, strings.Count(nginxConf, "server_name app.test;"))
+}
+
+func (suite *PathRoutingTestSuite) TestDomainWithoutRoot() {
+	suite.config.Services = suite.config.Services[:1]
+	suite.config.Services = append(suite.config.Services, Service{Name: "docs", Image: "nginx:alpine", Port: 80, Domain: "app.test", Path: "/docs"})
+
+	services := getNginxServices(suite.config)
+	suite.Require().Len(services, 1)
+	suite.True(services[0].PathsOnly)
+	suite.Len(services[0].PathRoutes, 2)
+
+	nginxConf, err := generateNginxConfig(suite.config)
+	suite.Require().NoError(err)
+	suite.Contains(nginxConf, "location / {\n            return 404;")
+}
+
+func (suite *PathRoutingTestSuite) TestUnprivilegedAliases() {
+	suite.config.Unprivileged = true
+	services := getNginxServices(suite.config)
+	suite.Require().Len(services, 1)
+	suite.Equal([]string{"frontend.localhost", "api.localhost", "admin.localhost"}, services[0].Aliases)
+	suite.Equal("http://api.localhost:8080/api", getFrameworkSiteURL(&suite.config.Services[0], suite.config))
+}
+
+func (suite *PathRoutingTestSuite) TestRoutes() {
+	routes := getRoutes(suite.config, "fleet.toml")
+	suite.Require().Len(routes, 3)
+	suite.Equal("http://api:3000/", routes[1].Upstream)
+	suite.Equal("http://admin:4000/admin/", routes[2].Upstream)
+
+	suite.Equal("api", findRoute(routes, "app.test", "/api/users").Service)
+	suite.Equal("api", findRoute(routes, "app.test", "/api").Service)
+	suite.Equal("frontend", findRoute(routes, "app.test", "/apis").Service)
+	suite.Equal("frontend", findRoute(routes, "app.test", "/").Service)
+
+	suite.Equal([]string{"http://app.test/api/"}, getServiceURLs(suite.config, "fleet.toml")["api"])
+}
+
+func (suite *PathRoutingTestSuite) TestTraefikLabels() {
+	suite.config.Proxy = proxyTraefik
+	routes := getTraefikRoutes(suite.config)
+	suite.Require().Len(routes, 3)
+	suite.Equal("/api", routes[1].PathPrefix)
+	suite.Equal(3000, routes[1].Port)
+
+	labels := getTraefikLabels(routes[1], suite.config)
+	suite.Equal("(Host(`app.test`)) && (Path(`/api`) || PathPrefix(`/api/`))", labels["traefik.http.routers.api.rule"])
+	suite.Equal("/api", labels["traefik.http.middlewares.api-strip.stripprefix.prefixes"])
+	suite.Equal("api-strip", labels["traefik.http.routers.api.middlewares"])
+}
+
+func TestPathRoutingSuite(t *testing.T) {
+	suite.Run(t, new(PathRoutingTestSuite))
+}
@@ -0,0 +1,171 @@
+package fleetcli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// assetsDevServerPort is the port of the Vite dev server in the assets container
+const assetsDevServerPort = 5173
+
+// assetsProxyPath is where nginx serves the dev server next to the PHP app
+const assetsProxyPath = "/vite/"
+
+// hasAssetsBuild checks if a PHP service builds its frontend assets in a sidecar
+func hasAssetsBuild(svc *Service) bool {
+	return svc.AssetsRuntime != "" && strings.HasPrefix(svc.Runtime, "php")
+}
+
+// getAssetsServiceName returns the name of the assets container of a service
+func getAssetsServiceName(serviceName string) string {
+	return fmt.Sprintf("%s-assets", serviceName)
+}
+
+// getAssetsVolumeName returns the node_modules volume of the assets container
+func getAssetsVolumeName(serviceName string) string {
+	return fmt.Sprintf("%s_assets_node_modules", strings.ReplaceAll(serviceName, "-", "_"))
+}
+
+// validateAssetsBuild checks the assets settings of a service
+func validateAssetsBuild(svc *Service) error {
+	if svc.AssetsRuntime == "" {
+		if svc.AssetsCommand != "" || svc.AssetsDev {
+			return fmt.Errorf("service %s: 'assets_command' and 'assets_dev' require 'assets_runtime'", svc.Name)
+		}
+		return nil
+	}
+	if !strings.HasPrefix(svc.Runtime, "php") {
+		return fmt.Errorf("service %s: 'assets_runtime' is only supported for PHP services", svc.Name)
+	}
+	if lang, _ := parseNodeRuntime(svc.AssetsRuntime); lang != "node" {
+		return fmt.Errorf("service %s: unsupported assets_runtime %q (expected node or node:<version>)", svc.Name, svc.AssetsRuntime)
+	}
+	if svc.Folder == "" {
+		return fmt.Errorf("service %s: 'assets_runtime' requires 'folder'", svc.Name)
+	}
+	return nil
+}
+
+// getAssetsDevCommand returns the command that serves assets with HMR below the proxy path
+func (nc *NodeConfigurator) getAssetsDevCommand(packageManager string) string {
+	command := nc.getPackageManagerRunCommand(packageManager, "dev")
+	if packageManager == "npm" {
+		// npm needs -- to pass arguments through to the script
+		command += " --"
+	}
+	return fmt.Sprintf("%s --host 0.0.0.0 --port %d --base %s", command, assetsDevServerPort, assetsProxyPath)
+}
+
+// BuildAssetsService builds the Node.js sidecar that compiles a PHP service's assets
+func (nc *NodeConfigurator) BuildAssetsService(svc *Service) *DockerService {
+	if !hasAssetsBuild(svc) {
+		return nil
+	}
+
+	_, version := nc.ParseRuntime(svc.AssetsRuntime)
+
+	assetsService := &DockerService{
+		Image:      nc.GetNodeImage(version),
+		Networks:   []string{"fleet-network"},
+		WorkingDir: "/app",
+		Environment: map[string]string{
+			"NODE_ENV": "development",
+		},
+	}
+
+	// Share the PHP app's folder so the build lands in public/ for nginx and PHP.
+	// node_modules lives in a volume so Linux binaries don't end up on the host.
+	assetsService.Volumes = []string{
+		formatBindMount("../"+svc.Folder, "/app"),
+		fmt.Sprintf("%s:/app/node_modules", getAssetsVolumeName(svc.Name)),
+	}
+
+	packageManager := svc.PackageManager
+	if packageManager == "" {
+		packageManager = detectPackageManager(svc.hostPath(svc.Folder))
+	}
+
+	command := svc.AssetsCommand
+	if svc.AssetsDev {
+		// Dev server keeps running and serves hot updates
+		assetsService.Restart = "unless-stopped"
+		assetsService.Ports = []string{fmt.Sprintf("%d:%d", assetsDevServerPort, assetsDevServerPort)}
+		if command == "" {
+			command = nc.getAssetsDevCommand(packageManager)
+		}
+	} else {
+		// One-off build
+		assetsService.Restart = "no"
+		if command == "" {
+			command = nc.getBuildCommand(svc.hostPath(svc.Folder), packageManager, "")
+		}
+	}
+
+	installCmd := nc.getInstallCommand(packageManager)
+	assetsService.Command = fmt.Sprintf(`sh -c "
+		echo 'Installing dependencies with %s...';
+		%s && \
+		echo 'Building assets...';
+		%s
+	"`, packageManager, installCmd, command)
+
+	return assetsService
+}
+
+// addAssetsService adds the assets sidecar of a PHP service to the compose file
+func addAssetsService(compose *DockerCompose, svc *Service) {
+	configurator := NewNodeConfigurator()
+	assetsService := configurator.BuildAssetsService(svc)
+	if assetsService == nil {
+		return
+	}
+
+	compose.Services[getAssetsServiceName(svc.Name)] = *assetsService
+
+	if compose.Volumes == nil {
+		compose.Volumes = make(map[string]DockerVolume)
+	}
+	compose.Volumes[getAssetsVolumeName(svc.Name)] = DockerVolume{Driver: "local"}
+
+	// nginx resolves the dev server when it loads its config, so it has to start after it
+	if svc.AssetsDev {
+		if nginxSvc, exists := compose.Services[svc.Name]; exists && strings.Contains(strings.ToLower(svc.Image), "nginx") {
+			nginxSvc.DependsOn = append(nginxSvc.DependsOn, getAssetsServiceName(svc.Name))
+			compose.Services[svc.Name] = nginxSvc
+		}
+	}
+}
+
+// generateAssetsProxyLocation returns the nginx location that forwards the dev server,
+// including the websocket used for hot module replacement
+func generateAssetsProxyLocation(assetsServiceName string) string {
+	return fmt.Sprintf(`
+    # Vite dev server with hot module replacement
+    location %s {
+        proxy_pass http://%s:%d;
+        proxy_http_version 1.1;
+        proxy_set_header Upgrade $http_upgrade;
+        proxy_set_header Connection "upgrade";
+        proxy_set_header Host $host;
+        # Keep the idle websocket open
+        proxy_read_timeout 1h;
+        proxy_send_timeout 1h;
+    }
+`, assetsProxyPath, assetsServiceName, assetsDevServerPort)
+}
+
+// addAssetsProxyToNginxConfig adds the dev server location to a planned nginx config
+func addAssetsProxyToNginxConfig(plan *ArtifactPlan, configPath string, assetsServiceName string) error {
+	artifact, ok := plan.file(configPath)
+	if !ok {
+		return fmt.Errorf("failed to add assets proxy: %s isn't generated", configPath)
+	}
+
+	content := string(artifact.Content)
+	end := strings.LastIndex(content, "}")
+	if end < 0 {
+		return fmt.Errorf("failed to add assets proxy: %s has no server block", configPath)
+	}
+	artifact.Content = []byte(content[:end] + generateAssetsProxyLocation(assetsServiceName) + content[end:])
+	return nil
+}
@@ -0,0 +1,126 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PHPAssetsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *PHPAssetsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+
+	suite.originalDir, _ = os.Getwd()
+	suite.Require().NoError(os.Chdir(suite.helper.TempDir()))
+	suite.Require().NoError(os.MkdirAll(".fleet", 0755))
+	suite.Require().NoError(os.MkdirAll("app", 0755))
+}
+
+func (suite *PHPAssetsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *PHPAssetsTestSuite) TestValidateAssetsBuild() {
+	tests := []struct {
+		name    string
+		svc     Service
+		wantErr string
+	}{
+		{"valid", Service{Name: "app", Runtime: "php:8.3", Folder: "app", AssetsRuntime: "node:20"}, ""},
+		{"disabled", Service{Name: "app", Runtime: "php:8.3"}, ""},
+		{"not php", Service{Name: "app", Runtime: "node:20", Folder: "app", AssetsRuntime: "node:20"}, "only supported for PHP"},
+		{"bad runtime", Service{Name: "app", Runtime: "php:8.3", Folder: "app", AssetsRuntime: "bun"}, "unsupported assets_runtime"},
+		{"no folder", Service{Name: "app", Runtime: "php:8.3", AssetsRuntime: "node:20"}, "requires 'folder'"},
+		{"command without runtime", Service{Name: "app", Runtime: "php:8.3", AssetsCommand: "npm run build"}, "require 'assets_runtime'"},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			err := validateAssetsBuild(&tt.svc)
+			if tt.wantErr == "" {
+				suite.NoError(err)
+				return
+			}
+			suite.Error(err)
+			suite.Contains(err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func (suite *PHPAssetsTestSuite) TestBuildModeSidecar() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "web", Image: "nginx:alpine", Runtime: "php:8.3", Framework: "laravel", Folder: "app", AssetsRuntime: "node:20"},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	assets, exists := compose.Services["web-assets"]
+	suite.Require().True(exists)
+	suite.Equal("node:20-alpine", assets.Image)
+	suite.Equal("no", assets.Restart)
+	suite.Contains(assets.Volumes, "../app:/app")
+	suite.Contains(assets.Volumes, "web_assets_node_modules:/app/node_modules")
+	suite.Contains(assets.Command, "npm run build")
+	suite.Empty(assets.Ports)
+	suite.Contains(compose.Volumes, "web_assets_node_modules")
+
+	// The build runs alongside PHP, nothing waits for it
+	suite.NotContains(compose.Services["web"].DependsOn, "web-assets")
+}
+
+func (suite *PHPAssetsTestSuite) TestCustomAssetsCommand() {
+	svc := &Service{Name: "web", Runtime: "php:8.3", Folder: "app", AssetsRuntime: "node:18", AssetsCommand: "npm run production"}
+
+	assets := NewNodeConfigurator().BuildAssetsService(svc)
+	suite.Require().NotNil(assets)
+	suite.Equal("node:18-alpine", assets.Image)
+	suite.Contains(assets.Command, "npm run production")
+}
+
+func (suite *PHPAssetsTestSuite) TestDevServerIsProxied() {
+	suite.Require().NoError(os.WriteFile(filepath.Join("app", "pnpm-lock.yaml"), []byte(""), 0644))
+
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "web", Image: "nginx:alpine", Runtime: "php:8.3", Framework: "laravel", Folder: "app", AssetsRuntime: "node:20", AssetsDev: true},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
+
+	assets := compose.Services["web-assets"]
+	suite.Equal("unless-stopped", assets.Restart)
+	suite.Contains(assets.Ports, "5173:5173")
+	suite.Contains(assets.Command, "pnpm dev --host 0.0.0.0 --port 5173 --base /vite/")
+	suite.Contains(compose.Services["web"].DependsOn, "web-assets")
+
+	nginxConfig, err := os.ReadFile(filepath.Join(".fleet", "web-nginx.conf"))
+	suite.Require().NoError(err)
+	suite.Contains(string(nginxConfig), "location /vite/")
+	suite.Contains(string(nginxConfig), "proxy_pass http://web-assets:5173;")
+	suite.Contains(string(nginxConfig), "proxy_set_header Upgrade $http_upgrade;")
+}
+
+func (suite *PHPAssetsTestSuite) TestNpmDevCommandPassesArguments() {
+	suite.Equal("npm run dev -- --host 0.0.0.0 --port 5173 --base /vite/", NewNodeConfigurator().getAssetsDevCommand("npm"))
+	suite.Equal("yarn dev --host 0.0.0.0 --port 5173 --base /vite/", NewNodeConfigurator().getAssetsDevCommand("yarn"))
+}
+
+func TestPHPAssetsSuite(t *testing.T) {
+	suite.Run(t, new(PHPAssetsTestSuite))
+}
@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -13,9 +13,6 @@ type PHPConfigurator struct {
 	// Framework detection
 	frameworkDetectors map[string]FrameworkDetector
 	
-	// Nginx config generators
-	nginxGenerators map[string]NginxGenerator
-	
 	// Xdebug configuration
 	xdebugConfig XdebugConfig
 	
@@ -27,9 +24,6 @@ type PHPConfigurator struct {
 // FrameworkDetector detects if a framework is present in a folder
 type FrameworkDetector func(folder string) bool
 
-// NginxGenerator generates nginx configuration for a framework
-type NginxGenerator func(phpServiceName string) string
-
 // XdebugConfig holds Xdebug configuration settings
 type XdebugConfig struct {
 	DefaultPort int
@@ -41,31 +35,18 @@ type XdebugConfig struct {
 func NewPHPConfigurator() *PHPConfigurator {
 	pc := &PHPConfigurator{
 		frameworkDetectors: make(map[string]FrameworkDetector),
-		nginxGenerators:    make(map[string]NginxGenerator),
 		xdebugConfig: XdebugConfig{
 			DefaultPort: 9003,
 			Mode:        "develop,debug,coverage",
 			Trigger:     "yes",
 		},
-		supportedVersions: map[string]string{
-			"7.4":     "php:7.4-fpm-alpine",
-			"8.0":     "php:8.0-fpm-alpine",
-			"8.1":     "php:8.1-fpm-alpine",
-			"8.2":     "php:8.2-fpm-alpine",
-			"8.3":     "php:8.3-fpm-alpine",
-			"8.4":     "php:8.4-fpm-alpine",
-			"latest":  "php:8.4-fpm-alpine",
-			"default": "php:8.4-fpm-alpine",
-		},
-		defaultVersion: "8.4",
+		supportedVersions: supportedPHPVersions,
+		defaultVersion: versionData.PHP.Default,
 	}
 	
 	// Register framework detectors
 	pc.registerFrameworkDetectors()
 	
-	// Register nginx generators
-	pc.registerNginxGenerators()
-	
 	return pc
 }
 
@@ -160,19 +141,6 @@ func (pc *PHPConfigurator) registerFrameworkDetectors() {
 	}
 }
 
-// registerNginxGenerators registers all nginx config generators
-func (pc *PHPConfigurator) registerNginxGenerators() {
-	// Use existing generators but wrap them in the new structure
-	pc.nginxGenerators["laravel"] = generateLaravelNginxConfig
-	pc.nginxGenerators["lumen"] = generateLaravelNginxConfig // Same as Laravel
-	pc.nginxGenerators["symfony"] = generateSymfonyNginxConfig
-	pc.nginxGenerators["wordpress"] = generateWordPressNginxConfig
-	pc.nginxGenerators["drupal"] = generateDrupalNginxConfig
-	pc.nginxGenerators["codeigniter"] = generateCodeIgniterNginxConfig
-	pc.nginxGenerators["slim"] = generateSlimNginxConfig
-	pc.nginxGenerators["default"] = generateNginxPHPConfigWithService
-}
-
 // DetectFramework detects the PHP framework in the given folder
 func (pc *PHPConfigurator) DetectFramework(folder string) string {
 	if folder == "" {
@@ -228,22 +196,15 @@ func (pc *PHPConfigurator) GetPHPImage(version string) string {
 	return pc.supportedVersions["default"]
 }
 
-// GenerateNginxConfig generates nginx configuration for a service
+// GenerateNginxConfig generates nginx configuration for a service from the built-in
+// template of its framework
 func (pc *PHPConfigurator) GenerateNginxConfig(serviceName, framework string) string {
-	phpServiceName := fmt.Sprintf("%s-php", serviceName)
-	
-	// Use framework-specific generator if available
-	if generator, exists := pc.nginxGenerators[strings.ToLower(framework)]; exists {
-		return generator(phpServiceName)
-	}
-	
-	// Fallback to default
-	if defaultGenerator, exists := pc.nginxGenerators["default"]; exists {
-		return defaultGenerator(phpServiceName)
+	config, err := generatePHPNginxConfig(&Service{Name: serviceName}, framework)
+	if err != nil {
+		// Built-in templates are embedded and checked by the tests
+		panic(err)
 	}
-	
-	// Ultimate fallback (shouldn't happen)
-	return generateNginxPHPConfigWithService(phpServiceName)
+	return config
 }
 
 // ConfigureXdebug returns Xdebug configuration for a PHP service
@@ -407,13 +368,13 @@ func (pc *PHPConfigurator) BuildPHPService(svc *Service) *DockerService {
 	
 	// Mount folder
 	if svc.Folder != "" {
-		phpService.Volumes = append(phpService.Volumes, fmt.Sprintf("../%s:/var/www/html", svc.Folder))
+		phpService.Volumes = append(phpService.Volumes, formatBindMount("../"+svc.Folder, "/var/www/html"))
 	}
 	
 	// Detect and configure framework
 	framework := svc.Framework
 	if framework == "" {
-		framework = pc.DetectFramework(svc.Folder)
+		framework = pc.DetectFramework(svc.hostPath(svc.Folder))
 	}
 	
 	// Add framework-specific environment variables
@@ -431,13 +392,16 @@ func (pc *PHPConfigurator) BuildPHPService(svc *Service) *DockerService {
 				profileOutput = ".fleet/profiles"
 			}
 			// Ensure the directory is created relative to the compose file
-			phpService.Volumes = append(phpService.Volumes, fmt.Sprintf("../%s:/var/www/profiles", profileOutput))
+			phpService.Volumes = append(phpService.Volumes, formatBindMount("../"+profileOutput, "/var/www/profiles"))
 		}
 	} else {
 		// Install Composer by default for all PHP containers
 		pc.installComposer(phpService)
 	}
 	
+	// Use a prebuilt or custom image instead of installing at startup if configured
+	pc.ApplyImageStrategy(phpService, svc, version)
+	
 	// Add custom environment variables
 	if svc.Environment != nil {
 		for k, v := range svc.Environment {
@@ -470,18 +434,29 @@ func (pc *PHPConfigurator) configureFrameworkEnvironment(phpService *DockerServi
 	}
 }
 
+// PlanNginxConfig adds the nginx configuration file for a PHP service to a plan and
+// returns its path. A custom nginx_template that fails to render falls back to the
+// built-in template of the framework.
+func (pc *PHPConfigurator) PlanNginxConfig(plan *ArtifactPlan, svc *Service, framework string) string {
+	configPath := plan.path(fmt.Sprintf("%s-nginx.conf", svc.Name))
+	
+	config, err := generatePHPNginxConfig(svc, framework)
+	if err != nil {
+		warnf("⚠️  %v, using the built-in nginx config\n", err)
+		fallback := *svc
+		fallback.NginxTemplate = ""
+		config, _ = generatePHPNginxConfig(&fallback, framework)
+	}
+	
+	plan.addFile(configPath, []byte(config), 0644)
+	return configPath
+}
+
 // WriteNginxConfig writes the nginx configuration file for a PHP service
 func (pc *PHPConfigurator) WriteNginxConfig(serviceName, framework string) (string, error) {
-	configPath := filepath.Join(".fleet", fmt.Sprintf("%s-nginx.conf", serviceName))
-	
-	// Get framework-specific config or fallback to generic
-	if framework == "" {
-		framework = "default"
-	}
-	
-	config := pc.GenerateNginxConfig(serviceName, framework)
-	
-	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+	plan := newArtifactPlan()
+	configPath := pc.PlanNginxConfig(plan, &Service{Name: serviceName}, framework)
+	if err := writeArtifacts(plan); err != nil {
 		return "", fmt.Errorf("failed to write nginx PHP config: %w", err)
 	}
 	
@@ -0,0 +1,206 @@
+package fleetcli
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Where the official PHP images read extra configuration. Files are loaded in name order,
+// so zz-fleet comes after the image's own zz-docker.conf.
+const (
+	phpIniOverridePath     = "/usr/local/etc/php/conf.d/zz-fleet.ini"
+	phpFPMPoolOverridePath = "/usr/local/etc/php-fpm.d/zz-fleet.conf"
+)
+
+// phpSizePattern matches a PHP size like 64M, 1G or a number of bytes
+var phpSizePattern = regexp.MustCompile(`^[0-9]+[KkMmGg]?$`)
+
+// hasPHPFPMSettings reports whether any setting is set
+func hasPHPFPMSettings(s *PHPFPMSettings) bool {
+	return *s != PHPFPMSettings{}
+}
+
+// validatePHPFPM checks the php_fpm settings of a service
+func validatePHPFPM(svc *Service) error {
+	settings := &svc.PHPFPM
+	if !hasPHPFPMSettings(settings) {
+		return nil
+	}
+	if !strings.HasPrefix(svc.Runtime, "php") {
+		return fmt.Errorf("service %s: 'php_fpm' requires a PHP runtime", svc.Name)
+	}
+
+	switch settings.PM {
+	case "", "static", "dynamic", "ondemand":
+	default:
+		return fmt.Errorf("service %s: php_fpm.pm must be static, dynamic or ondemand, not %q", svc.Name, settings.PM)
+	}
+	if settings.MaxChildren < 0 || settings.OpcacheMemory < 0 || settings.OpcacheMaxFiles < 0 {
+		return fmt.Errorf("service %s: php_fpm max_children, opcache_memory and opcache_max_files must be positive", svc.Name)
+	}
+
+	sizes := []struct{ name, value string }{
+		{"upload_max_filesize", settings.UploadMaxFilesize},
+		{"post_max_size", settings.PostMaxSize},
+	}
+	// -1 lifts the memory limit
+	if settings.MemoryLimit != "-1" {
+		sizes = append(sizes, struct{ name, value string }{"memory_limit", settings.MemoryLimit})
+	}
+	for _, size := range sizes {
+		if size.value != "" && !phpSizePattern.MatchString(size.value) {
+			return fmt.Errorf("service %s: php_fpm.%s %q must be a size like 64M", svc.Name, size.name, size.value)
+		}
+	}
+	return nil
+}
+
+// formatIniBool formats a boolean for php.ini
+func formatIniBool(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// generatePHPIni returns the php.ini override of the settings, or "" when none apply
+func generatePHPIni(settings *PHPFPMSettings) string {
+	values := map[string]string{}
+	if settings.MemoryLimit != "" {
+		values["memory_limit"] = settings.MemoryLimit
+	}
+	if settings.UploadMaxFilesize != "" {
+		values["upload_max_filesize"] = settings.UploadMaxFilesize
+	}
+	if settings.PostMaxSize != "" {
+		values["post_max_size"] = settings.PostMaxSize
+	}
+	if settings.Opcache != nil {
+		values["opcache.enable"] = formatIniBool(*settings.Opcache)
+	}
+	if settings.OpcacheMemory > 0 {
+		values["opcache.memory_consumption"] = fmt.Sprintf("%d", settings.OpcacheMemory)
+	}
+	if settings.OpcacheMaxFiles > 0 {
+		values["opcache.max_accelerated_files"] = fmt.Sprintf("%d", settings.OpcacheMaxFiles)
+	}
+	if settings.OpcacheValidateTimestamps != nil {
+		values["opcache.validate_timestamps"] = formatIniBool(*settings.OpcacheValidateTimestamps)
+	}
+	return formatConfigLines("; Generated by Fleet from php_fpm in the fleet config\n", values)
+}
+
+// generatePHPFPMPool returns the FPM pool override of the settings, or "" when none apply
+func generatePHPFPMPool(settings *PHPFPMSettings) string {
+	values := map[string]string{}
+	if settings.PM != "" {
+		values["pm"] = settings.PM
+	}
+	if settings.MaxChildren > 0 {
+		values["pm.max_children"] = fmt.Sprintf("%d", settings.MaxChildren)
+		// The image's spare server counts must fit within max_children, or FPM won't start
+		if settings.PM == "" || settings.PM == "dynamic" {
+			minSpare := max(1, settings.MaxChildren/4)
+			maxSpare := max(minSpare, settings.MaxChildren/2)
+			values["pm.start_servers"] = fmt.Sprintf("%d", minSpare)
+			values["pm.min_spare_servers"] = fmt.Sprintf("%d", minSpare)
+			values["pm.max_spare_servers"] = fmt.Sprintf("%d", maxSpare)
+		}
+	}
+	return formatConfigLines("; Generated by Fleet from php_fpm in the fleet config\n[www]\n", values)
+}
+
+// formatConfigLines returns sorted key = value lines after a header, or "" without values
+func formatConfigLines(header string, values map[string]string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(header)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s = %s\n", key, values[key])
+	}
+	return b.String()
+}
+
+// getClientMaxBodySize returns the nginx client_max_body_size that lets uploads PHP accepts
+// through, or "" to keep the nginx default. post_max_size limits the whole request.
+func getClientMaxBodySize(settings *PHPFPMSettings) string {
+	size := settings.PostMaxSize
+	if size == "" {
+		size = settings.UploadMaxFilesize
+	}
+	// nginx uses the same units, in lowercase
+	return strings.ToLower(size)
+}
+
+// setClientMaxBodySize adds client_max_body_size to the server block of a planned nginx config
+func setClientMaxBodySize(artifact *Artifact, size string) error {
+	content := string(artifact.Content)
+	start := strings.Index(content, "server {")
+	if start < 0 {
+		return fmt.Errorf("failed to set client_max_body_size: %s has no server block", artifact.Path)
+	}
+	start += len("server {")
+	artifact.Content = []byte(content[:start] + fmt.Sprintf("\n    client_max_body_size %s;", size) + content[start:])
+	return nil
+}
+
+// planPHPFPMOverride plans a generated override with the generated files and returns its
+// absolute path
+func planPHPFPMOverride(plan *ArtifactPlan, svc *Service, name, content string) (string, error) {
+	path := plan.path(name)
+	plan.addFile(path, []byte(content), 0644)
+	return filepath.Abs(svc.hostPath(path))
+}
+
+// configurePHPFPM mounts the php.ini and FPM pool overrides of a service into the container
+// running its code, and raises the upload limit of its nginx to match
+func configurePHPFPM(compose *DockerCompose, svc *Service) {
+	settings := &svc.PHPFPM
+	if !hasPHPFPMSettings(settings) {
+		return
+	}
+	name := getAppServiceName(svc)
+	service, exists := compose.Services[name]
+	if !exists {
+		return
+	}
+
+	plan := getArtifactPlan(compose)
+	overrides := []struct{ file, content, target string }{
+		{fmt.Sprintf("%s-php.ini", svc.Name), generatePHPIni(settings), phpIniOverridePath},
+		{fmt.Sprintf("%s-php-fpm.conf", svc.Name), generatePHPFPMPool(settings), phpFPMPoolOverridePath},
+	}
+	for _, override := range overrides {
+		if override.content == "" {
+			continue
+		}
+		path, err := planPHPFPMOverride(plan, svc, override.file, override.content)
+		if err != nil {
+			warnf("⚠️  Warning: %v\n", err)
+			continue
+		}
+		service.Volumes = append(service.Volumes, formatBindMount(path, override.target+":ro"))
+	}
+	compose.Services[name] = service
+
+	// The nginx container in front of FPM rejects bodies over 1m by default
+	if size := getClientMaxBodySize(settings); size != "" && name != svc.Name {
+		configPath := plan.path(fmt.Sprintf("%s-nginx.conf", svc.Name))
+		if artifact, ok := plan.file(configPath); ok {
+			if err := setClientMaxBodySize(artifact, size); err != nil {
+				warnf("⚠️  Warning: %v\n", err)
+			}
+		}
+	}
+}
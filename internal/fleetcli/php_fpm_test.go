@@ -0,0 +1,117 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PHPFPMTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *PHPFPMTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *PHPFPMTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *PHPFPMTestSuite) TestValidatePHPFPM() {
+	testCases := []struct {
+		name     string
+		runtime  string
+		settings PHPFPMSettings
+		wantErr  string
+	}{
+		{"valid", "php:8.3", PHPFPMSettings{PM: "ondemand", MaxChildren: 20, MemoryLimit: "512M", UploadMaxFilesize: "64M", PostMaxSize: "1G"}, ""},
+		{"unlimited memory", "php:8.3", PHPFPMSettings{MemoryLimit: "-1"}, ""},
+		{"no settings without PHP", "node:20", PHPFPMSettings{}, ""},
+		{"not PHP", "node:20", PHPFPMSettings{MaxChildren: 5}, "requires a PHP runtime"},
+		{"unknown pm", "php:8.3", PHPFPMSettings{PM: "adaptive"}, "php_fpm.pm must be"},
+		{"negative children", "php:8.3", PHPFPMSettings{MaxChildren: -1}, "must be positive"},
+		{"invalid size", "php:8.3", PHPFPMSettings{UploadMaxFilesize: "64 MB"}, "php_fpm.upload_max_filesize"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			err := validatePHPFPM(&Service{Name: "web", Runtime: tc.runtime, PHPFPM: tc.settings})
+			if tc.wantErr == "" {
+				suite.NoError(err)
+			} else {
+				suite.ErrorContains(err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *PHPFPMTestSuite) TestGenerateOverrides() {
+	disabled := false
+	settings := &PHPFPMSettings{MemoryLimit: "512M", UploadMaxFilesize: "64M", Opcache: &disabled, OpcacheMemory: 256}
+	suite.Equal("; Generated by Fleet from php_fpm in the fleet config\n"+
+		"memory_limit = 512M\n"+
+		"opcache.enable = 0\n"+
+		"opcache.memory_consumption = 256\n"+
+		"upload_max_filesize = 64M\n", generatePHPIni(settings))
+	suite.Equal("", generatePHPFPMPool(settings))
+
+	// Dynamic pools get spare server counts that fit max_children
+	pool := generatePHPFPMPool(&PHPFPMSettings{MaxChildren: 2})
+	suite.Contains(pool, "[www]\n")
+	suite.Contains(pool, "pm.max_children = 2\n")
+	suite.Contains(pool, "pm.max_spare_servers = 1\n")
+
+	pool = generatePHPFPMPool(&PHPFPMSettings{PM: "static", MaxChildren: 8})
+	suite.Contains(pool, "pm = static\n")
+	suite.NotContains(pool, "spare")
+}
+
+func (suite *PHPFPMTestSuite) TestGetClientMaxBodySize() {
+	suite.Equal("", getClientMaxBodySize(&PHPFPMSettings{}))
+	suite.Equal("64m", getClientMaxBodySize(&PHPFPMSettings{UploadMaxFilesize: "64M"}))
+	suite.Equal("1g", getClientMaxBodySize(&PHPFPMSettings{UploadMaxFilesize: "64M", PostMaxSize: "1G"}))
+}
+
+func (suite *PHPFPMTestSuite) TestGenerateDockerCompose() {
+	suite.Require().NoError(os.MkdirAll("shop", 0755))
+	config := &Config{Project: "test", Services: []Service{{
+		Name:    "web",
+		Image:   "nginx:alpine",
+		Runtime: "php:8.3",
+		Folder:  "shop",
+		Domain:  "shop.test",
+		PHPFPM:  PHPFPMSettings{UploadMaxFilesize: "64M", MaxChildren: 10},
+	}}}
+
+	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
+
+	iniPath, _ := filepath.Abs(".fleet/web-php.ini")
+	poolPath, _ := filepath.Abs(".fleet/web-php-fpm.conf")
+	volumes := compose.Services["web-php"].Volumes
+	suite.Contains(volumes, iniPath+":"+phpIniOverridePath+":ro")
+	suite.Contains(volumes, poolPath+":"+phpFPMPoolOverridePath+":ro")
+	suite.FileExists(iniPath)
+
+	nginxConfig, err := os.ReadFile(".fleet/web-nginx.conf")
+	suite.Require().NoError(err)
+	suite.Contains(string(nginxConfig), "client_max_body_size 64m;")
+
+	proxyConfig, err := generateNginxConfig(config)
+	suite.Require().NoError(err)
+	suite.Contains(proxyConfig, "client_max_body_size 64m;")
+}
+
+func TestPHPFPMSuite(t *testing.T) {
+	suite.Run(t, new(PHPFPMTestSuite))
+}
@@ -0,0 +1,45 @@
+package fleetcli
+
+import (
+	"os"
+)
+
+// SupportedPHPFrameworks lists all supported PHP frameworks
+var SupportedPHPFrameworks = []string{
+	"laravel",
+	"symfony", 
+	"wordpress",
+	"drupal",
+	"codeigniter",
+	"slim",
+	"lumen",
+}
+
+// detectPHPFramework auto-detects the PHP framework from project files
+func detectPHPFramework(folder string) string {
+	// Use the PHPConfigurator for framework detection
+	configurator := NewPHPConfigurator()
+	return configurator.DetectFramework(folder)
+}
+
+// fileExists checks if a file exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// getNginxConfigForFramework returns the appropriate nginx configuration for a PHP framework
+func getNginxConfigForFramework(serviceName, framework string) string {
+	// Use the PHPConfigurator for nginx config generation
+	configurator := NewPHPConfigurator()
+	return configurator.GenerateNginxConfig(serviceName, framework)
+}
+
+// getNginxConfigForFrameworkWithVersion returns nginx config for framework with specific PHP version  
+func getNginxConfigForFrameworkWithVersion(serviceName, framework, phpVersion string) string {
+	// Suppress unused parameter warning - kept for compatibility
+	_ = phpVersion
+	// For compatibility, just use the standard method
+	// The version is already handled in the runtime configuration
+	return getNginxConfigForFramework(serviceName, framework)
+}
@@ -0,0 +1,147 @@
+package fleetcli
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// PHP image strategies selectable with php_image_strategy
+const (
+	// PHPImageStrategyOfficialPecl uses the official image and installs Xdebug with pecl at startup
+	PHPImageStrategyOfficialPecl = "official-pecl"
+	// PHPImageStrategyFleetPrebuilt builds a local image with Xdebug and Composer baked in
+	PHPImageStrategyFleetPrebuilt = "fleet-prebuilt"
+	// PHPImageStrategyCustom uses the image given in php_image as-is
+	PHPImageStrategyCustom = "custom"
+)
+
+// hostArch is the architecture prebuilt images are tagged for (overridable in tests)
+var hostArch = runtime.GOARCH
+
+// resolvePHPImageStrategy returns the image strategy for a service. It defaults to
+// official-pecl, and to fleet-prebuilt on arm64, where building Xdebug with pecl at
+// every start takes minutes.
+func resolvePHPImageStrategy(svc *Service) string {
+	if svc.PHPImageStrategy != "" {
+		return svc.PHPImageStrategy
+	}
+	if hostArch == "arm64" {
+		return PHPImageStrategyFleetPrebuilt
+	}
+	return PHPImageStrategyOfficialPecl
+}
+
+// validatePHPImageStrategy checks the php_image_strategy and php_image settings of a service
+func validatePHPImageStrategy(svc *Service) error {
+	switch resolvePHPImageStrategy(svc) {
+	case PHPImageStrategyOfficialPecl, PHPImageStrategyFleetPrebuilt:
+		return nil
+	case PHPImageStrategyCustom:
+		if svc.PHPImage == "" {
+			return fmt.Errorf("service %s: php_image is required when php_image_strategy is %q", svc.Name, PHPImageStrategyCustom)
+		}
+		return nil
+	default:
+		return fmt.Errorf("service %s: unknown php_image_strategy %q (expected %s, %s or %s)",
+			svc.Name, svc.PHPImageStrategy, PHPImageStrategyOfficialPecl, PHPImageStrategyFleetPrebuilt, PHPImageStrategyCustom)
+	}
+}
+
+// getPrebuiltPHPImage returns the local tag for a prebuilt PHP image.
+// The architecture is part of the tag so amd64 and arm64 builds never share a cache entry.
+func getPrebuiltPHPImage(version, arch string) string {
+	return fmt.Sprintf("fleet-php:%s-%s", version, arch)
+}
+
+// getPrebuiltPHPBuildContext returns the build context of a prebuilt image, relative to .fleet
+func getPrebuiltPHPBuildContext(version string) string {
+	return fmt.Sprintf("./php/%s", version)
+}
+
+// xdebugPeclPackage returns the newest Xdebug release that supports the PHP version
+func xdebugPeclPackage(version string) string {
+	if strings.HasPrefix(version, "7.") {
+		return "xdebug-3.1.6"
+	}
+	return "xdebug"
+}
+
+// generatePHPDockerfile generates the Dockerfile for a prebuilt PHP image.
+// Each step is its own layer so the slow pecl build is cached independently.
+func generatePHPDockerfile(baseImage, version string) string {
+	return fmt.Sprintf(`# Generated by Fleet - do not edit
+FROM %s
+
+RUN apk add --no-cache --virtual .fleet-build-deps $PHPIZE_DEPS linux-headers \
+    && pecl install %s \
+    && docker-php-ext-enable xdebug \
+    && apk del .fleet-build-deps
+
+COPY --from=composer:2 /usr/bin/composer /usr/local/bin/composer
+
+RUN mkdir -p /var/www/profiles && chmod 777 /var/www/profiles
+
+# Xdebug stays loaded but idle unless XDEBUG_MODE is set
+ENV XDEBUG_MODE=off
+`, baseImage, xdebugPeclPackage(version))
+}
+
+// planPHPDockerfile plans the Dockerfile for a prebuilt PHP image in .fleet/php/<version>
+func planPHPDockerfile(plan *ArtifactPlan, baseImage, version string) string {
+	path := filepath.Join(".fleet", "php", version, "Dockerfile")
+	plan.addFile(path, []byte(generatePHPDockerfile(baseImage, version)), 0644)
+	return path
+}
+
+// configEnv returns the complete Xdebug configuration as an XDEBUG_CONFIG value.
+// Used when Xdebug is already in the image and no install command writes the ini file.
+func (xs *XdebugSettings) configEnv() string {
+	settings := []string{
+		fmt.Sprintf("client_host=%s", xs.ClientHost),
+		fmt.Sprintf("client_port=%d", xs.Port),
+		fmt.Sprintf("start_with_request=%s", xs.Trigger),
+		fmt.Sprintf("log=%s", xs.LogPath),
+	}
+
+	if xs.ProfileEnabled {
+		settings = append(settings, "output_dir=/var/www/profiles")
+		if xs.ProfileTrigger == "request" {
+			settings[2] = "start_with_request=trigger"
+			settings = append(settings, "trigger_value=PROFILE")
+		} else if xs.ProfileTrigger == "always" {
+			settings[2] = "start_with_request=yes"
+		}
+	}
+
+	return strings.Join(settings, " ")
+}
+
+// ApplyImageStrategy swaps the startup install command for a prebuilt or custom image
+func (pc *PHPConfigurator) ApplyImageStrategy(phpService *DockerService, svc *Service, version string) {
+	strategy := resolvePHPImageStrategy(svc)
+	if strategy == PHPImageStrategyOfficialPecl {
+		return
+	}
+
+	if version == "" {
+		version = pc.defaultVersion
+	}
+
+	switch strategy {
+	case PHPImageStrategyFleetPrebuilt:
+		phpService.Image = getPrebuiltPHPImage(version, hostArch)
+		phpService.Build = getPrebuiltPHPBuildContext(version)
+	case PHPImageStrategyCustom:
+		phpService.Image = svc.PHPImage
+	}
+
+	// Xdebug and Composer ship with the image, so just run the image's default command
+	phpService.Command = ""
+
+	if svc.Debug || svc.Profile {
+		xdebugSettings := pc.ConfigureXdebug(svc)
+		phpService.Environment["XDEBUG_CONFIG"] = xdebugSettings.configEnv()
+	}
+}
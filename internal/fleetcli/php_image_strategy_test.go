@@ -0,0 +1,194 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PHPImageStrategyTestSuite struct {
+	suite.Suite
+	helper       *TestHelper
+	originalDir  string
+	originalArch string
+}
+
+func (suite *PHPImageStrategyTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+
+	// Dockerfiles are written relative to the working directory
+	suite.originalDir, _ = os.Getwd()
+	suite.Require().NoError(os.Chdir(suite.helper.TempDir()))
+
+	suite.originalArch = hostArch
+	hostArch = "arm64"
+}
+
+func (suite *PHPImageStrategyTestSuite) TearDownTest() {
+	hostArch = suite.originalArch
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *PHPImageStrategyTestSuite) TestDefaultStrategyInstallsWithPecl() {
+	hostArch = "amd64"
+	config := &Config{
+		Project: "test-project",
+		Services: []Service{
+			{Name: "api", Image: "nginx", Runtime: "php:8.3", Folder: "api", Debug: true},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	phpService := compose.Services["api-php"]
+	suite.Equal("php:8.3-fpm-alpine", phpService.Image)
+	suite.Empty(phpService.Build)
+	suite.Contains(phpService.Command, "pecl install xdebug")
+	suite.NoFileExists(filepath.Join(".fleet", "php", "8.3", "Dockerfile"))
+}
+
+func (suite *PHPImageStrategyTestSuite) TestDefaultStrategyOnARM() {
+	svc := &Service{Name: "api", Image: "nginx", Runtime: "php:8.3", Folder: "api"}
+	suite.Equal(PHPImageStrategyFleetPrebuilt, resolvePHPImageStrategy(svc))
+
+	svc.PHPImageStrategy = PHPImageStrategyOfficialPecl
+	suite.Equal(PHPImageStrategyOfficialPecl, resolvePHPImageStrategy(svc), "The config wins")
+
+	hostArch = "amd64"
+	svc.PHPImageStrategy = ""
+	suite.Equal(PHPImageStrategyOfficialPecl, resolvePHPImageStrategy(svc))
+}
+
+func (suite *PHPImageStrategyTestSuite) TestFleetPrebuiltStrategy() {
+	config := &Config{
+		Project: "test-project",
+		Services: []Service{
+			{
+				Name:             "api",
+				Image:            "nginx",
+				Runtime:          "php:8.3",
+				Folder:           "api",
+				Debug:            true,
+				DebugPort:        9005,
+				PHPImageStrategy: "fleet-prebuilt",
+			},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
+
+	phpService := compose.Services["api-php"]
+	suite.Equal("fleet-php:8.3-arm64", phpService.Image)
+	suite.Equal("./php/8.3", phpService.Build)
+	suite.Empty(phpService.Command, "nothing should be installed at startup")
+	suite.Equal("develop,debug,coverage", phpService.Environment["XDEBUG_MODE"])
+	suite.Contains(phpService.Environment["XDEBUG_CONFIG"], "client_port=9005")
+	suite.Contains(phpService.Environment["XDEBUG_CONFIG"], "start_with_request=yes")
+
+	dockerfile, err := os.ReadFile(filepath.Join(".fleet", "php", "8.3", "Dockerfile"))
+	suite.Require().NoError(err)
+	suite.Contains(string(dockerfile), "FROM php:8.3-fpm-alpine")
+	suite.Contains(string(dockerfile), "pecl install xdebug")
+	suite.Contains(string(dockerfile), "composer")
+}
+
+func (suite *PHPImageStrategyTestSuite) TestFleetPrebuiltProfilerConfig() {
+	config := &Config{
+		Project: "test-project",
+		Services: []Service{
+			{
+				Name:             "api",
+				Image:            "nginx",
+				Runtime:          "php:8.2",
+				Folder:           "api",
+				Profile:          true,
+				PHPImageStrategy: "fleet-prebuilt",
+			},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	xdebugConfig := compose.Services["api-php"].Environment["XDEBUG_CONFIG"]
+	suite.Contains(xdebugConfig, "output_dir=/var/www/profiles")
+	suite.Contains(xdebugConfig, "start_with_request=trigger")
+	suite.Contains(xdebugConfig, "trigger_value=PROFILE")
+}
+
+func (suite *PHPImageStrategyTestSuite) TestCustomStrategy() {
+	config := &Config{
+		Project: "test-project",
+		Services: []Service{
+			{
+				Name:             "api",
+				Image:            "nginx",
+				Runtime:          "php:8.3",
+				Folder:           "api",
+				PHPImageStrategy: "custom",
+				PHPImage:         "ghcr.io/acme/php:8.3-dev",
+			},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	phpService := compose.Services["api-php"]
+	suite.Equal("ghcr.io/acme/php:8.3-dev", phpService.Image)
+	suite.Empty(phpService.Build)
+	suite.Empty(phpService.Command)
+}
+
+func (suite *PHPImageStrategyTestSuite) TestXdebugPackageForOldPHP() {
+	suite.Equal("xdebug-3.1.6", xdebugPeclPackage("7.4"))
+	suite.Equal("xdebug", xdebugPeclPackage("8.3"))
+	suite.Contains(generatePHPDockerfile("php:7.4-fpm-alpine", "7.4"), "pecl install xdebug-3.1.6")
+}
+
+func (suite *PHPImageStrategyTestSuite) TestValidation() {
+	tests := []struct {
+		name    string
+		service Service
+		wantErr string
+	}{
+		{
+			name:    "default strategy",
+			service: Service{Name: "api", Image: "nginx", Runtime: "php:8.3"},
+		},
+		{
+			name:    "prebuilt strategy",
+			service: Service{Name: "api", Image: "nginx", Runtime: "php:8.3", PHPImageStrategy: "fleet-prebuilt"},
+		},
+		{
+			name:    "custom without image",
+			service: Service{Name: "api", Image: "nginx", Runtime: "php:8.3", PHPImageStrategy: "custom"},
+			wantErr: "php_image is required",
+		},
+		{
+			name:    "unknown strategy",
+			service: Service{Name: "api", Image: "nginx", Runtime: "php:8.3", PHPImageStrategy: "magic"},
+			wantErr: "unknown php_image_strategy",
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			err := Validate(&Config{Project: "test", Services: []Service{tt.service}})
+			if tt.wantErr == "" {
+				suite.NoError(err)
+			} else {
+				suite.Error(err)
+				suite.Contains(err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPHPImageStrategySuite(t *testing.T) {
+	suite.Run(t, new(PHPImageStrategyTestSuite))
+}
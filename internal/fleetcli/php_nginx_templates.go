@@ -0,0 +1,129 @@
+package fleetcli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// PHPNginxTemplateData holds the variables of the nginx templates of PHP services
+type PHPNginxTemplateData struct {
+	Service       string            // Fleet service, e.g. shop
+	PHPService    string            // PHP-FPM container requests are passed to, e.g. shop-php
+	Framework     string            // Detected or configured framework, empty for plain PHP
+	Root          string            // Document root, e.g. /var/www/html/public
+	FastCGIParams map[string]string // Extra fastcgi_param lines, from fastcgi_params
+}
+
+// phpNginxTemplates maps frameworks to their embedded template in templates/nginx/php
+var phpNginxTemplates = map[string]string{
+	"laravel":     "laravel",
+	"lumen":       "laravel",
+	"symfony":     "symfony",
+	"wordpress":   "wordpress",
+	"drupal":      "drupal",
+	"codeigniter": "codeigniter",
+	"slim":        "slim",
+}
+
+// fastCGIParamPattern matches the name of a FastCGI parameter
+var fastCGIParamPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// getPHPDocumentRoot returns the folder a framework serves, inside the nginx container
+func getPHPDocumentRoot(framework string) string {
+	switch strings.ToLower(framework) {
+	case "laravel", "lumen", "symfony", "codeigniter", "slim":
+		return "/var/www/html/public"
+	}
+	return "/var/www/html"
+}
+
+// getPHPNginxTemplateData returns the variables of the nginx template of a PHP service
+func getPHPNginxTemplateData(serviceName, framework string, params map[string]string) PHPNginxTemplateData {
+	return PHPNginxTemplateData{
+		Service:       serviceName,
+		PHPService:    fmt.Sprintf("%s-php", serviceName),
+		Framework:     strings.ToLower(framework),
+		Root:          getPHPDocumentRoot(framework),
+		FastCGIParams: params,
+	}
+}
+
+// nginxQuote quotes a value for an nginx directive. Variables like $host still expand.
+func nginxQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// getEmbeddedPHPNginxTemplate returns the built-in template of a framework, or the
+// generic PHP one
+func getEmbeddedPHPNginxTemplate(framework string) (string, error) {
+	name, exists := phpNginxTemplates[strings.ToLower(framework)]
+	if !exists {
+		name = "default"
+	}
+	content, err := templatesFS.ReadFile("templates/nginx/php/" + name + ".conf.tmpl")
+	if err != nil {
+		return "", fmt.Errorf("failed to read nginx template %s: %w", name, err)
+	}
+	return string(content), nil
+}
+
+// renderPHPNginxTemplate renders an nginx template of a PHP service
+func renderPHPNginxTemplate(name, content string, data PHPNginxTemplateData) (string, error) {
+	tmpl, err := template.New(name).Funcs(template.FuncMap{"nginxQuote": nginxQuote}).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse nginx template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render nginx template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// generatePHPNginxConfig returns the nginx config of a PHP service, from its
+// nginx_template or the built-in template of its framework
+func generatePHPNginxConfig(svc *Service, framework string) (string, error) {
+	data := getPHPNginxTemplateData(svc.Name, framework, svc.FastCGIParams)
+	if svc.NginxTemplate != "" {
+		content, err := os.ReadFile(svc.hostPath(svc.NginxTemplate))
+		if err != nil {
+			return "", fmt.Errorf("failed to read nginx_template of %s: %w", svc.Name, err)
+		}
+		return renderPHPNginxTemplate(svc.NginxTemplate, string(content), data)
+	}
+
+	content, err := getEmbeddedPHPNginxTemplate(framework)
+	if err != nil {
+		return "", err
+	}
+	return renderPHPNginxTemplate(framework, content, data)
+}
+
+// validatePHPNginxTemplate checks the nginx_template and fastcgi_params of a service,
+// rendering the template so mistakes show when the config is loaded
+func validatePHPNginxTemplate(svc *Service) error {
+	if svc.NginxTemplate == "" && len(svc.FastCGIParams) == 0 {
+		return nil
+	}
+	if !strings.HasPrefix(svc.Runtime, "php") {
+		return fmt.Errorf("service %s: 'nginx_template' and 'fastcgi_params' require a php runtime", svc.Name)
+	}
+	for name, value := range svc.FastCGIParams {
+		if !fastCGIParamPattern.MatchString(name) {
+			return fmt.Errorf("service %s: invalid fastcgi_params name %q", svc.Name, name)
+		}
+		if strings.ContainsAny(value, "\n\r") {
+			return fmt.Errorf("service %s: fastcgi_params %s can't span lines", svc.Name, name)
+		}
+	}
+	if svc.NginxTemplate != "" {
+		if _, err := generatePHPNginxConfig(svc, svc.Framework); err != nil {
+			return fmt.Errorf("service %s: %w", svc.Name, err)
+		}
+	}
+	return nil
+}
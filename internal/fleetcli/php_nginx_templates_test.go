@@ -0,0 +1,92 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PHPNginxTemplatesTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *PHPNginxTemplatesTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *PHPNginxTemplatesTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *PHPNginxTemplatesTestSuite) TestEmbeddedTemplates() {
+	for _, framework := range []string{"", "laravel", "lumen", "symfony", "wordpress", "drupal", "codeigniter", "slim", "unknown"} {
+		config, err := generatePHPNginxConfig(&Service{Name: "shop"}, framework)
+		suite.Require().NoError(err, framework)
+		suite.Contains(config, "fastcgi_pass shop-php:9000;", framework)
+		suite.Contains(config, "root "+getPHPDocumentRoot(framework)+";", framework)
+		suite.NotContains(config, "{{", framework)
+	}
+
+	laravel, _ := generatePHPNginxConfig(&Service{Name: "shop"}, "laravel")
+	lumen, _ := generatePHPNginxConfig(&Service{Name: "shop"}, "lumen")
+	suite.Equal(laravel, lumen, "Lumen uses the Laravel template")
+}
+
+func (suite *PHPNginxTemplatesTestSuite) TestFastCGIParams() {
+	svc := &Service{Name: "shop", Runtime: "php:8.3", FastCGIParams: map[string]string{
+		"HTTPS":      "on",
+		"APP_TENANT": `$host "main"`,
+	}}
+	config, err := generatePHPNginxConfig(svc, "symfony")
+	suite.Require().NoError(err)
+	suite.Contains(config, "        fastcgi_param APP_TENANT \"$host \\\"main\\\"\";\n        fastcgi_param HTTPS \"on\";\n")
+}
+
+func (suite *PHPNginxTemplatesTestSuite) TestCustomTemplate() {
+	suite.Require().NoError(os.MkdirAll("custom", 0755))
+	suite.Require().NoError(os.WriteFile("custom/laravel.conf.tmpl", []byte(
+		"server { root {{.Root}}; location ~ \\.php$ { fastcgi_pass {{.PHPService}}:9000;{{range $k, $v := .FastCGIParams}} fastcgi_param {{$k}} {{nginxQuote $v}};{{end}} } }\n"), 0644))
+
+	svc := &Service{Name: "shop", Runtime: "php:8.3", Framework: "laravel", NginxTemplate: "./custom/laravel.conf.tmpl",
+		FastCGIParams: map[string]string{"HTTPS": "on"}}
+	suite.NoError(validatePHPNginxTemplate(svc))
+
+	config := generateDockerCompose(&Config{Project: "test", Services: []Service{
+		{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "shop", Framework: "laravel",
+			NginxTemplate: "./custom/laravel.conf.tmpl", FastCGIParams: map[string]string{"HTTPS": "on"}},
+	}})
+	artifact, planned := config.Artifacts.file(".fleet/shop-nginx.conf")
+	suite.Require().True(planned)
+	suite.Equal("server { root /var/www/html/public; location ~ \\.php$ { fastcgi_pass shop-php:9000; fastcgi_param HTTPS \"on\"; } }\n",
+		string(artifact.Content))
+}
+
+func (suite *PHPNginxTemplatesTestSuite) TestValidatePHPNginxTemplate() {
+	suite.NoError(validatePHPNginxTemplate(&Service{Name: "api", Image: "node:20"}))
+	suite.NoError(validatePHPNginxTemplate(&Service{Name: "shop", Runtime: "php:8.3", FastCGIParams: map[string]string{"HTTPS": "on"}}))
+
+	suite.ErrorContains(validatePHPNginxTemplate(&Service{Name: "api", Runtime: "node:20", FastCGIParams: map[string]string{"HTTPS": "on"}}),
+		"require a php runtime")
+	suite.ErrorContains(validatePHPNginxTemplate(&Service{Name: "shop", Runtime: "php:8.3", FastCGIParams: map[string]string{"APP-ENV": "local"}}),
+		`invalid fastcgi_params name "APP-ENV"`)
+	suite.ErrorContains(validatePHPNginxTemplate(&Service{Name: "shop", Runtime: "php:8.3", FastCGIParams: map[string]string{"A": "x\ny"}}),
+		"can't span lines")
+	suite.ErrorContains(validatePHPNginxTemplate(&Service{Name: "shop", Runtime: "php:8.3", NginxTemplate: "./missing.tmpl"}),
+		"failed to read nginx_template of shop")
+
+	suite.Require().NoError(os.WriteFile("broken.tmpl", []byte("root {{.Rot}};"), 0644))
+	suite.ErrorContains(validatePHPNginxTemplate(&Service{Name: "shop", Runtime: "php:8.3", NginxTemplate: "broken.tmpl"}),
+		"failed to render nginx template broken.tmpl")
+}
+
+func TestPHPNginxTemplatesSuite(t *testing.T) {
+	suite.Run(t, new(PHPNginxTemplatesTestSuite))
+}
@@ -1,7 +1,8 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -183,14 +184,12 @@ func (m *PHPRuntimeManager) GetAvailableCommands(service *PHPService) []string {
 	return commands
 }
 
-// RunComposerInstall runs composer install for a service
-func (m *PHPRuntimeManager) RunComposerInstall(service *PHPService) error {
+// RunComposerInstall runs composer install for a service, writing its output to out
+func (m *PHPRuntimeManager) RunComposerInstall(service *PHPService, out io.Writer) error {
 	if service == nil {
 		return fmt.Errorf("no PHP service provided")
 	}
 	
-	fmt.Printf("📦 Running composer install for service '%s'...\n", service.Name)
-	
 	// Build docker exec command
 	args := []string{
 		"exec",
@@ -199,6 +198,6 @@ func (m *PHPRuntimeManager) RunComposerInstall(service *PHPService) error {
 		"composer", "install", "--no-interaction", "--prefer-dist",
 	}
 	
-	return runDocker(args)
+	return runDockerWithOutput(args, out, out)
 }
 
@@ -0,0 +1,88 @@
+package fleetcli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// prewarmTimeout is how long to wait for a PHP container to become healthy before warming it
+const prewarmTimeout = 3 * time.Minute
+
+// getPrewarmCommands returns the cache building commands for a PHP framework
+func getPrewarmCommands(framework string) [][]string {
+	switch framework {
+	case "laravel":
+		return [][]string{
+			{"php", "artisan", "optimize"},
+			{"php", "artisan", "view:cache"},
+		}
+	case "symfony":
+		return [][]string{
+			{"php", "bin/console", "cache:warmup"},
+		}
+	}
+	return nil
+}
+
+// validatePrewarm checks that prewarm is only enabled on PHP services
+func validatePrewarm(svc *Service) error {
+	if svc.Prewarm && !strings.HasPrefix(svc.Runtime, "php") {
+		return fmt.Errorf("service %s: 'prewarm' requires a PHP runtime", svc.Name)
+	}
+	return nil
+}
+
+// getPrewarmServices returns the PHP services with prewarm enabled
+func (m *PHPRuntimeManager) getPrewarmServices() []PHPService {
+	var services []PHPService
+	for _, phpService := range m.services {
+		for _, svc := range m.config.Services {
+			if svc.Name == phpService.Name && svc.Prewarm {
+				services = append(services, phpService)
+			}
+		}
+	}
+	return services
+}
+
+// prewarmService waits for a service's PHP container and builds the framework caches
+func prewarmService(composeFiles ComposeFiles, service *PHPService, framework string) error {
+	commands := getPrewarmCommands(framework)
+	if len(commands) == 0 {
+		return fmt.Errorf("prewarm is not supported for framework %q", framework)
+	}
+
+	phpServiceName := fmt.Sprintf("%s-php", service.Name)
+	if err := waitForServiceReady(composeFiles, phpServiceName, prewarmTimeout); err != nil {
+		return err
+	}
+
+	for _, command := range commands {
+		args := append(composeArgs(composeFiles, "exec", "-T", phpServiceName), command...)
+		cmd, err := dockerCommand(args...)
+		if err != nil {
+			return err
+		}
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %v\n%s", strings.Join(command, " "), err, output)
+		}
+	}
+	return nil
+}
+
+// prewarmServices builds framework caches for every service with prewarm enabled
+func prewarmServices(config *Config, composeFiles ComposeFiles) {
+	manager := NewPHPRuntimeManager(config)
+	for _, service := range manager.getPrewarmServices() {
+		framework := manager.DetectFramework(&service)
+		infof("🔥 Prewarming caches for service '%s'...\n", service.Name)
+
+		start := time.Now()
+		if err := prewarmService(composeFiles, &service, framework); err != nil {
+			warnf("⚠️  Warning: prewarm failed for '%s': %v\n", service.Name, err)
+			continue
+		}
+		infof("✅ Caches warmed for '%s' in %s\n", service.Name, time.Since(start).Round(100*time.Millisecond))
+	}
+}
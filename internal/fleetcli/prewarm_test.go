@@ -0,0 +1,75 @@
+package fleetcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type PrewarmTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+}
+
+func (suite *PrewarmTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+}
+
+func (suite *PrewarmTestSuite) TearDownTest() {
+	suite.helper.Cleanup()
+}
+
+func (suite *PrewarmTestSuite) TestGetPrewarmCommands() {
+	tests := []struct {
+		framework string
+		expected  [][]string
+	}{
+		{"laravel", [][]string{{"php", "artisan", "optimize"}, {"php", "artisan", "view:cache"}}},
+		{"symfony", [][]string{{"php", "bin/console", "cache:warmup"}}},
+		{"wordpress", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.framework, func() {
+			suite.Equal(tt.expected, getPrewarmCommands(tt.framework))
+		})
+	}
+}
+
+func (suite *PrewarmTestSuite) TestValidatePrewarm() {
+	suite.NoError(validatePrewarm(&Service{Name: "app", Runtime: "php:8.3", Prewarm: true}))
+	suite.NoError(validatePrewarm(&Service{Name: "web", Image: "nginx"}))
+
+	err := validatePrewarm(&Service{Name: "web", Image: "nginx", Prewarm: true})
+	suite.Error(err)
+	suite.Contains(err.Error(), "requires a PHP runtime")
+
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "frontend", Runtime: "node:20", Prewarm: true},
+		},
+	}
+	suite.Error(Validate(config))
+}
+
+func (suite *PrewarmTestSuite) TestGetPrewarmServices() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "app", Runtime: "php:8.3", Framework: "laravel", Prewarm: true},
+			{Name: "admin", Runtime: "php:8.2", Framework: "symfony"},
+			{Name: "web", Image: "nginx"},
+		},
+	}
+
+	services := NewPHPRuntimeManager(config).getPrewarmServices()
+	suite.Require().Len(services, 1)
+	suite.Equal("app", services[0].Name)
+	suite.Equal("laravel", services[0].Framework)
+}
+
+func TestPrewarmSuite(t *testing.T) {
+	suite.Run(t, new(PrewarmTestSuite))
+}
@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -19,7 +19,7 @@ type PrivilegedOperation struct {
 // RunWithPrivileges executes a command with elevated privileges if needed
 func RunWithPrivileges(op PrivilegedOperation) error {
 	// First try without privileges
-	cmd := exec.Command(op.Command, op.Args...)
+	cmd := newCommand(op.Command, op.Args...)
 	if len(op.StdinData) > 0 {
 		cmd.Stdin = strings.NewReader(string(op.StdinData))
 	}
@@ -38,7 +38,7 @@ func RunWithPrivileges(op PrivilegedOperation) error {
 	}
 	
 	// Request elevated privileges
-	fmt.Printf("⚠️  %s requires administrator privileges.\n", op.Description)
+	outputf("⚠️  %s requires administrator privileges.\n", op.Description)
 	return runElevated(op)
 }
 
@@ -80,7 +80,7 @@ func runElevatedWindows(op PrivilegedOperation) error {
 	psCmd := fmt.Sprintf("Start-Process '%s' -ArgumentList '%s' -Verb RunAs -Wait",
 		op.Command, strings.Join(op.Args, "','"))
 	
-	cmd := exec.Command("powershell", "-Command", psCmd)
+	cmd := newCommand("powershell", "-Command", psCmd)
 	return cmd.Run()
 }
 
@@ -88,7 +88,7 @@ func runElevatedWindows(op PrivilegedOperation) error {
 func runElevatedMac(op PrivilegedOperation) error {
 	// macOS uses sudo but may also use osascript for GUI prompts
 	args := append([]string{"-S", op.Command}, op.Args...)
-	cmd := exec.Command("sudo", args...)
+	cmd := newCommand("sudo", args...)
 	
 	if len(op.StdinData) > 0 {
 		// For operations that need stdin, we need to handle it differently
@@ -118,7 +118,7 @@ func runElevatedUnix(op PrivilegedOperation) error {
 	
 	// Fall back to regular sudo
 	args := append([]string{"-S", op.Command}, op.Args...)
-	cmd := exec.Command("sudo", args...)
+	cmd := newCommand("sudo", args...)
 	
 	if len(op.StdinData) > 0 {
 		return runWithSudoScript(op)
@@ -128,7 +128,7 @@ func runElevatedUnix(op PrivilegedOperation) error {
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	
-	fmt.Println("🔐 Please enter your password for sudo access:")
+	outputln("🔐 Please enter your password for sudo access:")
 	return cmd.Run()
 }
 
@@ -139,11 +139,11 @@ func runWithGUITool(tool string, op PrivilegedOperation) error {
 	switch tool {
 	case "pkexec":
 		args := append([]string{op.Command}, op.Args...)
-		cmd = exec.Command(tool, args...)
+		cmd = newCommand(tool, args...)
 	default:
 		// gksudo, kdesudo
 		fullCmd := fmt.Sprintf("%s %s", op.Command, strings.Join(op.Args, " "))
-		cmd = exec.Command(tool, fullCmd)
+		cmd = newCommand(tool, fullCmd)
 	}
 	
 	if len(op.StdinData) > 0 {
@@ -171,12 +171,12 @@ func runWithSudoScript(op PrivilegedOperation) error {
 	}
 	
 	// Run with sudo
-	cmd := exec.Command("sudo", "-S", "bash", tmpFile.Name())
+	cmd := newCommand("sudo", "-S", "bash", tmpFile.Name())
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	
-	fmt.Println("🔐 Please enter your password for sudo access:")
+	outputln("🔐 Please enter your password for sudo access:")
 	return cmd.Run()
 }
 
@@ -220,9 +220,9 @@ func WriteFileWithPrivileges(filename string, data []byte, perm os.FileMode) err
 // BackupFileWithPrivileges creates a backup of a file that may require elevated privileges
 func BackupFileWithPrivileges(source, dest string) error {
 	// First try normal copy
-	cmd := exec.Command("cp", source, dest)
+	cmd := newCommand("cp", source, dest)
 	if runtime.GOOS == "windows" {
-		cmd = exec.Command("copy", source, dest)
+		cmd = newCommand("copy", source, dest)
 	}
 	
 	if err := cmd.Run(); err == nil {
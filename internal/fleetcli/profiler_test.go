@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"os"
@@ -18,6 +18,10 @@ func (suite *ProfilerTestSuite) SetupTest() {
 	suite.helper = NewTestHelper(suite.T())
 	// Change to temp directory for testing
 	os.Chdir(suite.helper.TempDir())
+	// The official image installs Xdebug at startup, except on arm64
+	originalArch := hostArch
+	hostArch = "amd64"
+	suite.T().Cleanup(func() { hostArch = originalArch })
 }
 
 func (suite *ProfilerTestSuite) TearDownTest() {
@@ -86,6 +90,7 @@ func (suite *ProfilerTestSuite) TestProfileWithCustomOutput() {
 	}
 
 	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
 	suite.Require().NotNil(compose)
 
 	phpService, exists := compose.Services["api-php"]
@@ -251,7 +256,8 @@ func (suite *ProfilerTestSuite) TestProfileDirectoryCreation() {
 		},
 	}
 
-	generateDockerCompose(config)
+	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
 
 	// Check that .fleet/profiles directory was created
 	profileDir := filepath.Join(".fleet", "profiles")
@@ -0,0 +1,35 @@
+package fleetcli
+
+import (
+	"io"
+	"os"
+
+	"github.com/fleet/fleet/internal/ui"
+)
+
+// getProgressMode returns how long steps are shown: redrawn in place on a terminal, a
+// line when they start and end with --ci or when output is piped, and only when they
+// fail with --quiet
+func getProgressMode() ui.Mode {
+	switch {
+	case outputLevel < outputNormal:
+		return ui.Quiet
+	case outputCI || !isOutputTerminal():
+		return ui.Plain
+	}
+	return ui.Spinner
+}
+
+// newProgress returns the progress UI of long steps, like pulls, composer install and
+// certificate generation. Failures shown with --quiet go to stderr, like warnings.
+func newProgress() *ui.Progress {
+	mode := getProgressMode()
+	var out io.Writer = os.Stdout
+	if mode == ui.Quiet {
+		out = os.Stderr
+	}
+	if outputNoEmoji {
+		out = emojiWriter{out}
+	}
+	return ui.New(out, mode)
+}
@@ -0,0 +1,111 @@
+package fleetcli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fleet/fleet/internal/ui"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProgressTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ProgressTestSuite) SetupTest() {
+	suite.T().Setenv(ciEnvVar, "")
+	suite.T().Setenv(ciServiceEnvVar, "")
+	outputLevel = outputNormal
+	outputCI = false
+}
+
+func (suite *ProgressTestSuite) TearDownTest() {
+	outputLevel = outputNormal
+	outputCI = false
+}
+
+func (suite *ProgressTestSuite) TestConfigureCI() {
+	suite.Equal([]string{"up", "-d"}, configureOutput([]string{"--ci", "up", "-d"}))
+	suite.True(outputCI)
+
+	outputCI = false
+	suite.T().Setenv(ciServiceEnvVar, "true")
+	configureOutput([]string{"up"})
+	suite.True(outputCI)
+}
+
+func (suite *ProgressTestSuite) TestGetProgressMode() {
+	// Test output isn't a terminal
+	suite.Equal(ui.Plain, getProgressMode())
+
+	outputLevel = outputQuiet
+	suite.Equal(ui.Quiet, getProgressMode())
+}
+
+func (suite *ProgressTestSuite) TestPlainSteps() {
+	var out bytes.Buffer
+	progress := ui.New(&out, ui.Plain)
+
+	err := progress.Run("Generating 2 SSL certificates", func(step *ui.Step) error {
+		step.Log("   %s\n", "shop.test")
+		fmt.Fprintln(step.Writer(), "composer output")
+		return nil
+	})
+	suite.NoError(err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	suite.Require().Len(lines, 4)
+	suite.Equal("⏳ Generating 2 SSL certificates...", lines[0])
+	suite.Equal("   shop.test", lines[1])
+	suite.Equal("composer output", lines[2])
+	suite.Regexp(`^✅ Generating 2 SSL certificates \(\d+ms\)$`, lines[3])
+}
+
+func (suite *ProgressTestSuite) TestQuietStepsOnlyShowFailures() {
+	var out bytes.Buffer
+	progress := ui.New(&out, ui.Quiet)
+
+	progress.Run("Pulling 1 images", func(step *ui.Step) error {
+		step.Log("   redis:7\n")
+		return nil
+	})
+	suite.Empty(out.String())
+
+	err := progress.Run("Running composer install for shop", func(step *ui.Step) error {
+		fmt.Fprintln(step.Writer(), "Your requirements could not be resolved")
+		return errors.New("exit status 2")
+	})
+	suite.EqualError(err, "exit status 2")
+	suite.Contains(out.String(), "Your requirements could not be resolved\n")
+	suite.Contains(out.String(), "❌ Running composer install for shop")
+	suite.Contains(out.String(), ": exit status 2")
+}
+
+func (suite *ProgressTestSuite) TestSpinnerShowsOutputOfFailedSteps() {
+	var out bytes.Buffer
+	progress := ui.New(&out, ui.Spinner)
+
+	step := progress.Start("Starting dnsmasq container")
+	fmt.Fprint(step.Writer(), "Building dnsmasq\nport is already allocated\n")
+	step.Fail(errors.New("exit status 1"))
+
+	output := out.String()
+	suite.Contains(output, "Starting dnsmasq container (")
+	suite.Contains(output, "\033[J", "The spinner is erased before the result")
+	suite.Contains(output, "Building dnsmasq\nport is already allocated\n❌ Starting dnsmasq container")
+
+	out.Reset()
+	progress.Run("Pulling 1 images", func(step *ui.Step) error {
+		fmt.Fprintln(step.Writer(), "hidden unless the step fails")
+		return nil
+	})
+	suite.NotContains(out.String(), "hidden unless the step fails\n✅")
+	suite.Contains(out.String(), "✅ Pulling 1 images")
+}
+
+func TestProgressSuite(t *testing.T) {
+	suite.Run(t, new(ProgressTestSuite))
+}
@@ -0,0 +1,236 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// projectLockFile is held by the command that is changing the project
+const projectLockFile = ".fleet/command.lock"
+
+// projectLockMaxAge is when a lock is considered abandoned even though its PID is in use,
+// since the PID may belong to another process by now
+const projectLockMaxAge = time.Hour
+
+// projectLockGracePeriod is how long a lock that can't be parsed is left alone before it
+// is considered abandoned
+const projectLockGracePeriod = 10 * time.Second
+
+// projectLockPollInterval is how often a waiting command checks the lock
+const projectLockPollInterval = 500 * time.Millisecond
+
+// ProjectLock is the content of the lock file
+type ProjectLock struct {
+	PID     int       `json:"pid"`
+	Command string    `json:"command"`
+	Started time.Time `json:"started"`
+}
+
+// ProjectLockOptions are the --wait and --force options of commands that change a project
+type ProjectLockOptions struct {
+	Wait  time.Duration
+	Force bool
+}
+
+// errProjectLocked is returned when another command holds the lock
+var errProjectLocked = errors.New("project is locked")
+
+// addProjectLockFlags adds --wait and --force to a command that changes the project
+func addProjectLockFlags(fs *flag.FlagSet) *ProjectLockOptions {
+	options := &ProjectLockOptions{}
+	fs.DurationVar(&options.Wait, "wait", 0, "Wait this long for another fleet command in the project to finish")
+	fs.BoolVar(&options.Force, "force", false, "Take the project lock even if another fleet command holds it")
+	return options
+}
+
+// isProcessRunning reports whether a process with the PID exists
+var isProcessRunning = func(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// FindProcess only finds running processes on Windows, elsewhere it always succeeds
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission)
+}
+
+// isStale reports whether the command holding a lock is gone. A lock that couldn't be
+// parsed is only stale once it is older than projectLockGracePeriod.
+func (lock *ProjectLock) isStale(now time.Time) bool {
+	if lock.PID == 0 {
+		return now.Sub(lock.Started) > projectLockGracePeriod
+	}
+	return !isProcessRunning(lock.PID) || now.Sub(lock.Started) > projectLockMaxAge
+}
+
+// describe returns the command holding a lock, for messages
+func (lock *ProjectLock) describe(now time.Time) string {
+	if lock.PID == 0 {
+		return fmt.Sprintf("fleet command (unreadable lock, taken %s ago)", now.Sub(lock.Started).Round(time.Second))
+	}
+	return fmt.Sprintf("fleet %s (PID %d, running for %s)", lock.Command, lock.PID, now.Sub(lock.Started).Round(time.Second))
+}
+
+// readProjectLock reads a lock file and returns it with the file info, to tell whether
+// the file was replaced since. A lock that can't be parsed gets the modification time of
+// the file as its start and no PID.
+func readProjectLock(path string) (*ProjectLock, os.FileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	var lock ProjectLock
+	if err := json.Unmarshal(data, &lock); err != nil || lock.PID == 0 {
+		return &ProjectLock{Started: info.ModTime()}, info, nil
+	}
+	return &lock, info, nil
+}
+
+// linkProjectLock creates the lock file with its content in one step, so that other
+// commands never read it half written: the content goes to a temporary file, which is
+// linked to the lock file unless it exists.
+func linkProjectLock(path string, data []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), ".command.lock-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Link(temp.Name(), path)
+}
+
+// tryProjectLock creates the lock file unless another running command holds it.
+// Stale locks are replaced.
+func tryProjectLock(path, command string, force bool) (*ProjectLock, error) {
+	lock := &ProjectLock{PID: os.Getpid(), Command: command, Started: time.Now()}
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		err := linkProjectLock(path, data)
+		if err == nil {
+			// Another command replacing the same stale lock may have removed ours
+			holder, _, err := readProjectLock(path)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			if holder.PID != lock.PID || !holder.Started.Equal(lock.Started) {
+				return holder, errProjectLocked
+			}
+			return nil, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create %s: %w", path, err)
+		}
+
+		holder, info, err := readProjectLock(path)
+		if errors.Is(err, os.ErrNotExist) {
+			// Released in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if !force && !holder.isStale(time.Now()) {
+			return holder, errProjectLocked
+		}
+		// Only remove the lock that was found stale, not one another command took since
+		if current, err := os.Stat(path); err == nil && !os.SameFile(info, current) {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return nil, fmt.Errorf("failed to create %s: another command keeps taking it", path)
+}
+
+// acquireProjectLock takes the lock of the project in the current directory, waiting up to
+// options.Wait for the command holding it. The returned function releases it.
+func acquireProjectLock(command string, options *ProjectLockOptions) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(projectLockFile), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create .fleet directory: %w", err)
+	}
+
+	deadline := time.Now().Add(options.Wait)
+	waiting := false
+	for {
+		holder, err := tryProjectLock(projectLockFile, command, options.Force)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errProjectLocked) {
+			return nil, err
+		}
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("another %s is changing this project. Wait for it with --wait 2m, or use --force if it is stuck", holder.describe(time.Now()))
+		}
+		if !waiting {
+			infof("⏳ Waiting for %s to finish...\n", holder.describe(time.Now()))
+			waiting = true
+		}
+		select {
+		case <-rootContext.Done():
+			return nil, rootContext.Err()
+		case <-time.After(projectLockPollInterval):
+		}
+	}
+
+	pid := os.Getpid()
+	release := func() {
+		// Only remove the lock if it wasn't taken over with --force
+		if lock, _, err := readProjectLock(projectLockFile); err == nil && lock.PID == pid {
+			os.Remove(projectLockFile)
+		}
+	}
+	unregister := onInterrupt(release)
+	return func() {
+		unregister()
+		release()
+	}, nil
+}
+
+// lockProject takes the project lock for a command or exits. The returned function
+// releases it.
+func lockProject(command string, options *ProjectLockOptions) func() {
+	release, err := acquireProjectLock(command, options)
+	if err != nil {
+		exitIfInterrupted()
+		log.Fatalf("❌ %v", err)
+	}
+	return release
+}
@@ -0,0 +1,148 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProjectLockTestSuite struct {
+	suite.Suite
+	helper            *TestHelper
+	originalDir       string
+	originalIsRunning func(int) bool
+}
+
+func (suite *ProjectLockTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+	suite.originalIsRunning = isProcessRunning
+}
+
+func (suite *ProjectLockTestSuite) TearDownTest() {
+	isProcessRunning = suite.originalIsRunning
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+// writeLock writes a lock held by another command
+func (suite *ProjectLockTestSuite) writeLock(pid int, started time.Time) {
+	data, err := json.Marshal(ProjectLock{PID: pid, Command: "up", Started: started})
+	suite.Require().NoError(err)
+	suite.Require().NoError(os.MkdirAll(".fleet", 0755))
+	suite.Require().NoError(os.WriteFile(projectLockFile, data, 0644))
+}
+
+func (suite *ProjectLockTestSuite) TestAcquireAndRelease() {
+	release, err := acquireProjectLock("up", &ProjectLockOptions{})
+	suite.Require().NoError(err)
+
+	lock, _, err := readProjectLock(projectLockFile)
+	suite.Require().NoError(err)
+	suite.Equal(os.Getpid(), lock.PID)
+	suite.Equal("up", lock.Command)
+
+	release()
+	suite.NoFileExists(projectLockFile)
+}
+
+func (suite *ProjectLockTestSuite) TestHeldByRunningCommand() {
+	isProcessRunning = func(pid int) bool { return true }
+	suite.writeLock(4242, time.Now().Add(-time.Minute))
+
+	_, err := acquireProjectLock("down", &ProjectLockOptions{Wait: time.Second})
+	suite.ErrorContains(err, "fleet up (PID 4242")
+	suite.ErrorContains(err, "--force")
+
+	release, err := acquireProjectLock("down", &ProjectLockOptions{Force: true})
+	suite.Require().NoError(err)
+	lock, _, _ := readProjectLock(projectLockFile)
+	suite.Equal("down", lock.Command)
+
+	// A lock taken over by someone else isn't removed on release
+	suite.writeLock(4242, time.Now())
+	release()
+	suite.FileExists(projectLockFile)
+}
+
+func (suite *ProjectLockTestSuite) TestWaitForRelease() {
+	isProcessRunning = func(pid int) bool { return true }
+	suite.writeLock(4242, time.Now())
+
+	go func() {
+		time.Sleep(2 * projectLockPollInterval)
+		os.Remove(projectLockFile)
+	}()
+
+	release, err := acquireProjectLock("up", &ProjectLockOptions{Wait: 10 * time.Second})
+	suite.Require().NoError(err)
+	release()
+}
+
+func (suite *ProjectLockTestSuite) TestStaleLocks() {
+	testCases := []struct {
+		name    string
+		running bool
+		started time.Time
+	}{
+		{"process exited", false, time.Now()},
+		{"too old", true, time.Now().Add(-2 * projectLockMaxAge)},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			isProcessRunning = func(pid int) bool { return tc.running }
+			suite.writeLock(4242, tc.started)
+
+			release, err := acquireProjectLock("up", &ProjectLockOptions{})
+			suite.Require().NoError(err)
+			release()
+		})
+	}
+
+	// A lock that can't be parsed is only stale after a grace period
+	suite.Require().NoError(os.WriteFile(projectLockFile, []byte("{"), 0644))
+	_, err := acquireProjectLock("up", &ProjectLockOptions{})
+	suite.ErrorContains(err, "unreadable lock")
+
+	old := time.Now().Add(-2 * projectLockGracePeriod)
+	suite.Require().NoError(os.Chtimes(projectLockFile, old, old))
+	release, err := acquireProjectLock("up", &ProjectLockOptions{})
+	suite.Require().NoError(err)
+	release()
+}
+
+func (suite *ProjectLockTestSuite) TestConcurrentTakeover() {
+	isProcessRunning = func(pid int) bool { return pid != 4242 }
+	suite.writeLock(4242, time.Now())
+
+	results := make(chan error)
+	for i := 0; i < 8; i++ {
+		go func() {
+			_, err := tryProjectLock(projectLockFile, "up", false)
+			results <- err
+		}()
+	}
+	taken := 0
+	for i := 0; i < 8; i++ {
+		if err := <-results; err == nil {
+			taken++
+		}
+	}
+	suite.Equal(1, taken, "Only one command takes over a stale lock")
+
+	matches, err := filepath.Glob(filepath.Join(".fleet", ".command.lock-*"))
+	suite.Require().NoError(err)
+	suite.Empty(matches, "Temporary files are removed")
+}
+
+func TestProjectLockSuite(t *testing.T) {
+	suite.Run(t, new(ProjectLockTestSuite))
+}
@@ -0,0 +1,163 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+func handleProxy() {
+	if len(os.Args) < 3 {
+		printProxyUsage()
+		os.Exit(0)
+	}
+
+	switch subcommand := os.Args[2]; subcommand {
+	case "reload":
+		handleProxyReload(os.Args[3:])
+	case "help":
+		printProxyUsage()
+	default:
+		fmt.Printf("Unknown proxy command: %s\n\n", subcommand)
+		printProxyUsage()
+		os.Exit(1)
+	}
+}
+
+func printProxyUsage() {
+	fmt.Println("Fleet proxy - Manage the reverse proxy of the running project")
+	fmt.Println("\nUsage: fleet proxy <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  reload  Apply changed domains and services to the proxy without restarting the stack")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file       Specify config file (default: the config the stack was started from)")
+	fmt.Println("  --no-privileged  The project was started with --no-privileged, leave the hosts file alone")
+	fmt.Println("\nreload writes the generated files again, starts the containers of new services,")
+	fmt.Println("reloads nginx once its new config passes 'nginx -t', and only adds or removes the")
+	fmt.Println("hosts file entries of domains that changed. Traefik reads routes from the labels of")
+	fmt.Println("containers, so the services whose routes changed are recreated instead.")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet proxy reload  # After adding a domain or a service to fleet.toml")
+}
+
+// getProxyServiceName returns the compose service of the reverse proxy of a project
+func getProxyServiceName(config *Config) string {
+	if isTraefikProxy(config) {
+		return traefikServiceName
+	}
+	return "nginx-proxy"
+}
+
+// getRunningComposeServices returns the compose services with a running container
+var getRunningComposeServices = func(composeFiles ComposeFiles) ([]string, error) {
+	cmd, err := dockerCommand(composeArgs(composeFiles, "ps", "--services", "--filter", "status=running")...)
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list running services: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// getProxyReloadServices returns the services the proxy routes to that have to be
+// started before it reloads. nginx fails to load a config naming a container that
+// doesn't exist, and Traefik only sees the routes of containers created with their labels.
+func getProxyReloadServices(config *Config, compose *DockerCompose, running []string) []string {
+	var services []string
+	for _, name := range compose.Services[getProxyServiceName(config)].DependsOn {
+		if isTraefikProxy(config) || !containsString(running, name) {
+			services = append(services, name)
+		}
+	}
+	return services
+}
+
+func handleProxyReload(args []string) {
+	fs := newCommandFlagSet("proxy reload", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	noPrivileged := fs.Bool("no-privileged", false, "Leave the hosts file alone")
+	lockOptions := addProjectLockFlags(fs)
+	fs.Usage = printProxyUsage
+
+	fs.Parse(args)
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+	*configFile = resolveRunningConfigFile(fs, *configFile)
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	config.Unprivileged = isUnprivileged(*noPrivileged)
+	if isCloud(false) {
+		log.Fatalf("❌ Cloud IDEs reach services through forwarded ports, there is no proxy to reload")
+	}
+	if !shouldAddNginxProxy(config) {
+		log.Fatalf("❌ No service has a domain or port, so the project has no proxy")
+	}
+
+	release := lockProject("proxy reload", lockOptions)
+	defer release()
+
+	// Regenerate nginx.conf, the certificates and the compose files new services start from
+	compose := generateDockerCompose(config)
+	composeFiles, err := writeComposeFiles(config, compose)
+	if err != nil {
+		log.Fatalf("❌ Error writing docker-compose.yml: %v", err)
+	}
+
+	proxy := getProxyServiceName(config)
+	running, err := getRunningComposeServices(composeFiles)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if !containsString(running, proxy) {
+		log.Fatalf("❌ The proxy isn't running, start the project with 'fleet up'")
+	}
+
+	if services := getProxyReloadServices(config, compose, running); len(services) > 0 {
+		upArgs := composeArgs(composeFiles, "up", "-d")
+		if isTraefikProxy(config) {
+			// Containers whose labels didn't change are left running
+			infof("🔄 Updating the routes of %s\n", strings.Join(services, ", "))
+		} else {
+			infof("🚀 Starting %s\n", strings.Join(services, ", "))
+			upArgs = append(upArgs, "--no-recreate")
+		}
+		if err := runDocker(append(upArgs, services...)); err != nil {
+			log.Fatalf("❌ Error starting services: %v", err)
+		}
+	}
+
+	if err := reloadProxy(config); err != nil {
+		log.Fatalf("❌ The proxy kept its previous config: %v", err)
+	}
+	infof("✅ Reloaded %s\n", proxy)
+
+	if config.Unprivileged {
+		return
+	}
+	added, removed, err := syncHostsFile(config)
+	if err != nil {
+		warnf("⚠️  Warning: failed to update hosts file: %v\n", err)
+		warnln("   Run 'fleet hosts add' with sudo, or update the hosts file manually")
+		return
+	}
+	for _, domain := range added {
+		infof("   Added domain: %s\n", domain)
+	}
+	for _, domain := range removed {
+		infof("   Removed domain: %s\n", domain)
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		infoln("   Hosts file is up to date")
+	}
+	printWildcardDNSStatus(config)
+}
@@ -0,0 +1,71 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProxyCommandsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+	config      *Config
+}
+
+func (suite *ProxyCommandsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+
+	suite.config = &Config{Project: "test", Services: []Service{
+		{Name: "web", Image: "nginx:alpine", Port: 80, Domain: "shop.test"},
+		{Name: "api", Image: "node:20", Port: 3000, Domain: "api.shop.test"},
+		{Name: "worker", Image: "node:20"},
+	}}
+}
+
+func (suite *ProxyCommandsTestSuite) TearDownTest() {
+	os.Chdir(suite.originalDir)
+	suite.helper.Cleanup()
+}
+
+func (suite *ProxyCommandsTestSuite) TestGetProxyServiceName() {
+	suite.Equal("nginx-proxy", getProxyServiceName(suite.config))
+	suite.config.Proxy = proxyTraefik
+	suite.Equal(traefikServiceName, getProxyServiceName(suite.config))
+}
+
+func (suite *ProxyCommandsTestSuite) TestGetProxyReloadServices() {
+	compose := generateDockerCompose(suite.config)
+
+	// nginx only needs the containers it doesn't find yet
+	suite.Equal([]string{"api"}, getProxyReloadServices(suite.config, compose, []string{"nginx-proxy", "web", "worker"}))
+	suite.Empty(getProxyReloadServices(suite.config, compose, []string{"nginx-proxy", "web", "api"}))
+
+	// Traefik reads the routes of every container from its labels
+	suite.config.Proxy = proxyTraefik
+	compose = generateDockerCompose(suite.config)
+	suite.ElementsMatch([]string{"web", "api"}, getProxyReloadServices(suite.config, compose, []string{traefikServiceName, "web", "api"}))
+}
+
+func (suite *ProxyCommandsTestSuite) TestReloadProxyChecksNginxConfig() {
+	originalExec := execInService
+	defer func() { execInService = originalExec }()
+
+	var executed *MaintenanceExec
+	execInService = func(composeFiles ComposeFiles, exec *MaintenanceExec) (string, error) {
+		executed = exec
+		return "", nil
+	}
+
+	suite.Require().NoError(reloadProxy(suite.config))
+	suite.Require().NotNil(executed)
+	suite.Equal("nginx-proxy", executed.Target)
+	suite.Equal("nginx -t -q && nginx -s reload", executed.Command)
+}
+
+func TestProxyCommandsSuite(t *testing.T) {
+	suite.Run(t, new(ProxyCommandsTestSuite))
+}
@@ -0,0 +1,395 @@
+package fleetcli
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Reverse proxies the proxy option picks between
+const (
+	proxyNginx   = "nginx"
+	proxyTraefik = "traefik"
+)
+
+const (
+	traefikServiceName = "traefik"
+	traefikImage       = "traefik:v3.1"
+	// traefikDynamicFile holds the certificates and TLS options, which docker labels can't
+	traefikDynamicFile = "traefik.yml"
+)
+
+// TraefikRoute is a router of Traefik, set up with labels on the container it reaches
+type TraefikRoute struct {
+	Name    string   // Router and service name, unique in the compose project
+	Service string   // Compose service the labels go on
+	Hosts   []string // Domains and aliases, *.myapp.test for wildcards
+	Port    int      // Port the container listens on
+	SSL     bool
+	SSLPort int // Port HTTPS is served on, 443 unless ssl_port is set
+	// PathPrefix limits the route to a path of the hosts, e.g. /api, see path_routing.go
+	PathPrefix string
+	StripPath  bool
+}
+
+// TraefikDynamicConfig is the file provider config of Traefik
+type TraefikDynamicConfig struct {
+	TLS TraefikTLSConfig `yaml:"tls"`
+}
+
+// TraefikTLSConfig holds the certificates Traefik picks from by SNI
+type TraefikTLSConfig struct {
+	Options      map[string]TraefikTLSOptions `yaml:"options"`
+	Stores       map[string]TraefikTLSStore   `yaml:"stores"`
+	Certificates []TraefikCertificate         `yaml:"certificates"`
+}
+
+// TraefikTLSOptions are the TLS settings of the HTTPS entrypoints
+type TraefikTLSOptions struct {
+	MinVersion string `yaml:"minVersion"`
+}
+
+// TraefikTLSStore holds the certificate served to unknown domains
+type TraefikTLSStore struct {
+	DefaultCertificate TraefikCertificate `yaml:"defaultCertificate"`
+}
+
+// TraefikCertificate is a certificate of .fleet/ssl, as mounted in the container
+type TraefikCertificate struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
+
+// isTraefikProxy reports whether the project is served by Traefik instead of nginx
+func isTraefikProxy(config *Config) bool {
+	return config.Proxy == proxyTraefik
+}
+
+// validateProxy checks the proxy option and what the chosen proxy can serve
+func validateProxy(config *Config) error {
+	switch config.Proxy {
+	case "", proxyNginx:
+		return nil
+	case proxyTraefik:
+	default:
+		return fmt.Errorf("proxy must be %s or %s, got %q", proxyNginx, proxyTraefik, config.Proxy)
+	}
+
+	if config.QueueDashboardAuth != "" {
+		return fmt.Errorf("'queue_dashboard_auth' requires the nginx proxy")
+	}
+	for i := range config.Services {
+		svc := &config.Services[i]
+		switch {
+		case svc.Name == traefikServiceName:
+			return fmt.Errorf("service %s: the name is used by the Traefik proxy", svc.Name)
+		case getDomainForService(svc) == "":
+			continue
+		// Traefik can't talk FastCGI to PHP-FPM
+		case strings.HasPrefix(svc.Runtime, "php"):
+			return fmt.Errorf("service %s: PHP services require the nginx proxy", svc.Name)
+		case isNativeService(svc):
+			return fmt.Errorf("service %s: native services require the nginx proxy", svc.Name)
+		case svc.DebugProxy:
+			return fmt.Errorf("service %s: 'debug_proxy' requires the nginx proxy", svc.Name)
+		}
+	}
+	return nil
+}
+
+// getURLPort returns the port of an upstream URL like http://minio:9000
+func getURLPort(upstream string) int {
+	parsed, err := url.Parse(upstream)
+	if err != nil {
+		return 0
+	}
+	port, _ := strconv.Atoi(parsed.Port())
+	return port
+}
+
+// getTraefikRoutes returns the routers of the services, queue dashboards and MinIO
+// routes nginx would have a vhost for
+func getTraefikRoutes(config *Config) []TraefikRoute {
+	var routes []TraefikRoute
+	for _, svc := range getNginxServices(config) {
+		hosts := append([]string{svc.Domain}, svc.Aliases...)
+		if !svc.PathsOnly {
+			routes = append(routes, TraefikRoute{
+				Name:    svc.Name,
+				Service: svc.Name,
+				Hosts:   hosts,
+				Port:    svc.Port,
+				SSL:     svc.SSL,
+				SSLPort: svc.SSLPort,
+			})
+		}
+		for _, route := range svc.PathRoutes {
+			routes = append(routes, TraefikRoute{
+				Name:       route.Service,
+				Service:    route.Service,
+				Hosts:      hosts,
+				Port:       getURLPort("http://" + route.Upstream),
+				SSL:        svc.SSL,
+				SSLPort:    svc.SSLPort,
+				PathPrefix: route.Prefix,
+				StripPath:  route.StripPath,
+			})
+		}
+	}
+	for _, dashboard := range getQueueDashboards(config) {
+		routes = append(routes, TraefikRoute{
+			Name:    "queue-" + dashboard.Name,
+			Service: dashboard.Service,
+			Hosts:   append([]string{dashboard.Domain}, dashboard.Aliases...),
+			Port:    getURLPort(dashboard.Upstream),
+		})
+	}
+	for _, route := range getMinIORoutes(config) {
+		routes = append(routes,
+			TraefikRoute{
+				Name:    route.Service,
+				Service: route.Service,
+				Hosts:   append([]string{route.Domain}, route.Aliases...),
+				Port:    getURLPort(route.Upstream),
+			},
+			TraefikRoute{
+				Name:    route.Service + "-console",
+				Service: route.Service,
+				Hosts:   append([]string{route.ConsoleDomain}, route.ConsoleAliases...),
+				Port:    getURLPort(route.ConsoleUpstream),
+			},
+		)
+	}
+	return routes
+}
+
+// getTraefikRule returns the rule matching the hosts of a route. Wildcard domains match
+// every subdomain of their base.
+func getTraefikRule(hosts []string) string {
+	var matchers []string
+	for _, host := range hosts {
+		if isWildcardDomain(host) {
+			matchers = append(matchers, fmt.Sprintf("HostRegexp(`^.+\\.%s$`)", regexp.QuoteMeta(getWildcardBase(host))))
+			continue
+		}
+		matchers = append(matchers, fmt.Sprintf("Host(`%s`)", host))
+	}
+	return strings.Join(matchers, " || ")
+}
+
+// getTraefikEntrypoint returns the entrypoint HTTPS is served on for a port
+func getTraefikEntrypoint(sslPort int) string {
+	if sslPort == 443 {
+		return "websecure"
+	}
+	return fmt.Sprintf("websecure-%d", sslPort)
+}
+
+// getTraefikLabels returns the docker labels that set up a route
+func getTraefikLabels(route TraefikRoute, config *Config) map[string]string {
+	router := "traefik.http.routers." + route.Name
+	rule := getTraefikRule(route.Hosts)
+	// Longer rules win, so paths take precedence over the route of the whole domain.
+	// PathPrefix(`/api`) alone would match /apis too.
+	if route.PathPrefix != "" {
+		rule = fmt.Sprintf("(%s) && (Path(`%s`) || PathPrefix(`%s/`))", rule, route.PathPrefix, route.PathPrefix)
+	}
+	// Regular expressions end with $, which compose would interpolate
+	rule = escapeComposeInterpolation(rule)
+	labels := map[string]string{
+		"traefik.enable":         "true",
+		"traefik.docker.network": composeProjectName + "_fleet-network",
+		"traefik.http.services." + route.Name + ".loadbalancer.server.port": strconv.Itoa(route.Port),
+		router + ".rule":        rule,
+		router + ".entrypoints": "web",
+		router + ".service":     route.Name,
+	}
+	// The service sees paths without the prefix, Traefik sets X-Forwarded-Prefix
+	if route.StripPath {
+		labels["traefik.http.middlewares."+route.Name+"-strip.stripprefix.prefixes"] = route.PathPrefix
+		labels[router+".middlewares"] = route.Name + "-strip"
+	}
+	if !route.SSL {
+		return labels
+	}
+
+	labels[router+"-secure.rule"] = rule
+	labels[router+"-secure.entrypoints"] = getTraefikEntrypoint(route.SSLPort)
+	labels[router+"-secure.tls"] = "true"
+	labels[router+"-secure.service"] = route.Name
+	if route.StripPath {
+		labels[router+"-secure.middlewares"] = route.Name + "-strip"
+	}
+
+	// Redirect HTTP to HTTPS, on the port browsers reach the proxy on
+	middleware := "traefik.http.middlewares." + route.Name + "-https.redirectscheme"
+	labels[middleware+".scheme"] = "https"
+	labels[middleware+".permanent"] = "true"
+	port := route.SSLPort
+	if config.Unprivileged && port == 443 {
+		port = unprivilegedHTTPSPort
+	}
+	if port != 443 {
+		labels[middleware+".port"] = strconv.Itoa(port)
+	}
+	labels[router+".middlewares"] = route.Name + "-https"
+	return labels
+}
+
+// generateTraefikDynamicConfig returns the file provider config listing the
+// certificates of .fleet/ssl
+func generateTraefikDynamicConfig(config *Config) ([]byte, error) {
+	minVersion := "VersionTLS12"
+	if config.ModernTLSOnly {
+		minVersion = "VersionTLS13"
+	}
+	mounted := func(path string) string {
+		return "/etc/traefik/ssl/" + filepath.Base(path)
+	}
+
+	dynamic := TraefikDynamicConfig{TLS: TraefikTLSConfig{
+		Options: map[string]TraefikTLSOptions{"default": {MinVersion: minVersion}},
+		Stores:  map[string]TraefikTLSStore{},
+	}}
+	for _, cert := range getSSLCertificates(config, nil) {
+		certificate := TraefikCertificate{CertFile: mounted(cert.CertPath), KeyFile: mounted(cert.KeyPath)}
+		if cert.Domain == "default" {
+			dynamic.TLS.Stores["default"] = TraefikTLSStore{DefaultCertificate: certificate}
+			continue
+		}
+		dynamic.TLS.Certificates = append(dynamic.TLS.Certificates, certificate)
+	}
+
+	data, err := yaml.Marshal(dynamic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Traefik config: %w", err)
+	}
+	return data, nil
+}
+
+// addTraefikProxyToCompose adds Traefik to docker-compose and labels the containers it
+// routes to, instead of templating nginx.conf
+func addTraefikProxyToCompose(compose *DockerCompose, config *Config) {
+	if !shouldAddNginxProxy(config) {
+		return
+	}
+
+	cwd, err := getProjectDir(config)
+	if err != nil {
+		warnf("Warning: failed to get working directory: %v\n", err)
+		return
+	}
+
+	plan := getArtifactPlan(compose)
+	plan.addDir(plan.dir, 0755)
+
+	routes := getTraefikRoutes(config)
+	var dependsOn []string
+	for _, route := range routes {
+		service, exists := compose.Services[route.Service]
+		if !exists {
+			continue
+		}
+		labels := make(map[string]string, len(service.Labels))
+		for k, v := range service.Labels {
+			labels[k] = v
+		}
+		for k, v := range getTraefikLabels(route, config) {
+			labels[k] = v
+		}
+		service.Labels = labels
+		compose.Services[route.Service] = service
+		if !containsString(dependsOn, route.Service) {
+			dependsOn = append(dependsOn, route.Service)
+		}
+	}
+
+	// Only the containers of Fleet projects are routed
+	command := []string{
+		"--providers.docker=true",
+		"--providers.docker.exposedbydefault=false",
+		fmt.Sprintf("--providers.docker.constraints=Label(`com.docker.compose.project`,`%s`)", composeProjectName),
+		"--entrypoints.web.address=:80",
+		"--ping=true",
+	}
+	ports := []string{"80:80"}
+	if config.Unprivileged {
+		ports = []string{fmt.Sprintf("127.0.0.1:%d:80", unprivilegedHTTPPort)}
+	}
+	volumes := []string{"/var/run/docker.sock:/var/run/docker.sock:ro"}
+
+	if hasSSLServices(config) {
+		// Generate the certificates and list them in the dynamic config
+		planSSLCertificates(plan, config)
+		dynamic, err := generateTraefikDynamicConfig(config)
+		if err != nil {
+			warnf("Warning: %v\n", err)
+			return
+		}
+		plan.addFile(plan.path(traefikDynamicFile), dynamic, 0644)
+		command = append(command, "--providers.file.filename=/etc/traefik/dynamic.yml")
+		volumes = append(volumes,
+			formatBindMount(filepath.Join(cwd, plan.path(traefikDynamicFile)), "/etc/traefik/dynamic.yml:ro"),
+			formatBindMount(filepath.Join(cwd, getSSLDir(config)), "/etc/traefik/ssl:ro"),
+		)
+
+		var sslPorts []int
+		seen := map[int]bool{}
+		for _, route := range routes {
+			if route.SSL && !seen[route.SSLPort] {
+				seen[route.SSLPort] = true
+				sslPorts = append(sslPorts, route.SSLPort)
+			}
+		}
+		sort.Ints(sslPorts)
+		for _, sslPort := range sslPorts {
+			entrypoint := getTraefikEntrypoint(sslPort)
+			command = append(command, fmt.Sprintf("--entrypoints.%s.address=:%d", entrypoint, sslPort))
+			published := strconv.Itoa(sslPort)
+			if config.Unprivileged && sslPort == 443 {
+				published = fmt.Sprintf("127.0.0.1:%d", unprivilegedHTTPSPort)
+			}
+			ports = append(ports, fmt.Sprintf("%s:%d", published, sslPort))
+			// HTTP/3 runs over QUIC, on UDP, and Traefik sends the Alt-Svc header
+			if config.HTTP3 {
+				command = append(command, fmt.Sprintf("--entrypoints.%s.http3=true", entrypoint))
+				if config.Unprivileged && sslPort == 443 {
+					command = append(command, fmt.Sprintf("--entrypoints.%s.http3.advertisedport=%d", entrypoint, unprivilegedHTTPSPort))
+				}
+				ports = append(ports, fmt.Sprintf("%s:%d/udp", published, sslPort))
+			}
+		}
+	}
+
+	sort.Strings(dependsOn)
+	compose.Services[traefikServiceName] = DockerService{
+		Image:     traefikImage,
+		Command:   strings.Join(command, " "),
+		Ports:     ports,
+		Volumes:   volumes,
+		Networks:  []string{"fleet-network"},
+		Restart:   "unless-stopped",
+		DependsOn: dependsOn,
+		HealthCheck: &HealthCheckYAML{
+			Test:     []string{"CMD", "traefik", "healthcheck", "--ping"},
+			Interval: "30s",
+			Timeout:  "3s",
+			Retries:  3,
+		},
+	}
+}
+
+// addProxyToCompose adds the reverse proxy the project picked
+func addProxyToCompose(compose *DockerCompose, config *Config) {
+	if isTraefikProxy(config) {
+		addTraefikProxyToCompose(compose, config)
+		return
+	}
+	addNginxProxyToCompose(compose, config)
+}
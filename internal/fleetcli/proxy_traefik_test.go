@@ -0,0 +1,134 @@
+package fleetcli
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+)
+
+type ProxyTraefikTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *ProxyTraefikTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *ProxyTraefikTestSuite) TearDownTest() {
+	os.Chdir(suite.originalDir)
+	suite.helper.Cleanup()
+}
+
+func (suite *ProxyTraefikTestSuite) TestValidateProxy() {
+	suite.NoError(validateProxy(&Config{}))
+	suite.NoError(validateProxy(&Config{Proxy: "nginx", Services: []Service{{Name: "shop", Runtime: "php:8.3", Domain: "shop.test"}}}))
+	suite.NoError(validateProxy(&Config{Proxy: "traefik", Services: []Service{{Name: "web", Image: "node:20", Port: 3000}}}))
+
+	suite.Error(validateProxy(&Config{Proxy: "caddy"}))
+	suite.Error(validateProxy(&Config{Proxy: "traefik", Services: []Service{{Name: "shop", Runtime: "php:8.3", Domain: "shop.test"}}}))
+	suite.Error(validateProxy(&Config{Proxy: "traefik", Services: []Service{{Name: "web", Image: "node:20", Port: 3000, DebugProxy: true}}}))
+	suite.Error(validateProxy(&Config{Proxy: "traefik", Services: []Service{{Name: "traefik", Image: "nginx"}}}))
+	suite.Error(validateProxy(&Config{Proxy: "traefik", QueueDashboardAuth: "admin:secret"}))
+}
+
+func (suite *ProxyTraefikTestSuite) TestGetTraefikRule() {
+	suite.Equal("Host(`web.test`)", getTraefikRule([]string{"web.test"}))
+	suite.Equal("HostRegexp(`^.+\\.shop\\.test$`) || Host(`shop.test`)", getTraefikRule([]string{"*.shop.test", "shop.test"}))
+}
+
+func (suite *ProxyTraefikTestSuite) TestTraefikReplacesNginx() {
+	config := &Config{
+		Project: "test-project",
+		Proxy:   "traefik",
+		Services: []Service{
+			{Name: "web", Image: "node:20", Domain: "web.test", Port: 3000},
+			{Name: "shop", Image: "node:20", Domain: "*.shop.test", Port: 4000, SSL: true},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+	suite.NotContains(compose.Services, "nginx-proxy")
+	proxy, exists := compose.Services[traefikServiceName]
+	suite.Require().True(exists)
+	suite.Equal([]string{"80:80", "443:443"}, proxy.Ports)
+	suite.Equal([]string{"shop", "web"}, proxy.DependsOn)
+	suite.Contains(proxy.Volumes, "/var/run/docker.sock:/var/run/docker.sock:ro")
+	suite.Contains(proxy.Command, "--providers.docker.exposedbydefault=false")
+	suite.Contains(proxy.Command, "--entrypoints.websecure.address=:443")
+
+	web := compose.Services["web"].Labels
+	suite.Equal("true", web["traefik.enable"])
+	suite.Equal("Host(`web.test`)", web["traefik.http.routers.web.rule"])
+	suite.Equal("3000", web["traefik.http.services.web.loadbalancer.server.port"])
+	suite.Equal("web", web[fleetServiceLabel], "Labels of the project are kept")
+	suite.NotContains(web, "traefik.http.routers.web-secure.tls")
+
+	shop := compose.Services["shop"].Labels
+	suite.Equal("HostRegexp(`^.+\\.shop\\.test$$`) || Host(`shop.test`)", shop["traefik.http.routers.shop-secure.rule"])
+	suite.Equal("websecure", shop["traefik.http.routers.shop-secure.entrypoints"])
+	suite.Equal("true", shop["traefik.http.routers.shop-secure.tls"])
+	suite.Equal("shop-https", shop["traefik.http.routers.shop.middlewares"])
+	suite.Equal("https", shop["traefik.http.middlewares.shop-https.redirectscheme.scheme"])
+	suite.NotContains(shop, "traefik.http.middlewares.shop-https.redirectscheme.port")
+}
+
+func (suite *ProxyTraefikTestSuite) TestTraefikUnprivilegedHTTP3() {
+	config := &Config{
+		Project:      "test-project",
+		Proxy:        "traefik",
+		HTTP3:        true,
+		Unprivileged: true,
+		Services: []Service{
+			{Name: "web", Image: "node:20", Domain: "web.test", Port: 3000, SSL: true},
+			{Name: "admin", Image: "node:20", Domain: "admin.test", Port: 8000, SSL: true, SSLPort: 9443},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+	proxy := compose.Services[traefikServiceName]
+	suite.Equal([]string{
+		fmt.Sprintf("127.0.0.1:%d:80", unprivilegedHTTPPort),
+		fmt.Sprintf("127.0.0.1:%d:443", unprivilegedHTTPSPort),
+		fmt.Sprintf("127.0.0.1:%d:443/udp", unprivilegedHTTPSPort),
+		"9443:9443",
+		"9443:9443/udp",
+	}, proxy.Ports)
+	suite.Contains(proxy.Command, "--entrypoints.websecure.http3=true")
+	suite.Contains(proxy.Command, fmt.Sprintf("--entrypoints.websecure.http3.advertisedport=%d", unprivilegedHTTPSPort))
+	suite.Contains(proxy.Command, "--entrypoints.websecure-9443.address=:9443")
+
+	suite.Equal(fmt.Sprint(unprivilegedHTTPSPort), compose.Services["web"].Labels["traefik.http.middlewares.web-https.redirectscheme.port"])
+	suite.Equal("websecure-9443", compose.Services["admin"].Labels["traefik.http.routers.admin-secure.entrypoints"])
+}
+
+func (suite *ProxyTraefikTestSuite) TestGenerateTraefikDynamicConfig() {
+	config := &Config{
+		ModernTLSOnly: true,
+		Services: []Service{
+			{Name: "shop", Domain: "*.shop.test", Port: 4000, SSL: true},
+		},
+	}
+
+	data, err := generateTraefikDynamicConfig(config)
+	suite.Require().NoError(err)
+
+	var dynamic TraefikDynamicConfig
+	suite.Require().NoError(yaml.Unmarshal(data, &dynamic))
+	suite.Equal("VersionTLS13", dynamic.TLS.Options["default"].MinVersion)
+	suite.Equal("/etc/traefik/ssl/default.crt", dynamic.TLS.Stores["default"].DefaultCertificate.CertFile)
+	suite.Equal([]TraefikCertificate{{
+		CertFile: "/etc/traefik/ssl/wildcard_shop_test.crt",
+		KeyFile:  "/etc/traefik/ssl/wildcard_shop_test.key",
+	}}, dynamic.TLS.Certificates)
+}
+
+func TestProxyTraefikSuite(t *testing.T) {
+	suite.Run(t, new(ProxyTraefikTestSuite))
+}
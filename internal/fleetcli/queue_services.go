@@ -0,0 +1,374 @@
+package fleetcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Supported message queue versions, from config/versions.json
+var supportedQueueVersions = imageMaps(versionData.Queue)
+
+const (
+	// queueDashboardDomain serves the first queue dashboard, each dashboard also has a
+	// subdomain of it like rabbitmq.queue.test
+	queueDashboardDomain = "queue.test"
+	// queueDashboardAuthFile holds the basic auth user of queue_dashboard_auth, next to
+	// nginx.conf
+	queueDashboardAuthFile = "queue-dashboards.htpasswd"
+
+	// kafkaUIServiceName is the dashboard of every Kafka broker of the project
+	kafkaUIServiceName = "kafka-ui"
+	kafkaUIImage       = "provectuslabs/kafka-ui:v0.7.2"
+	kafkaUIPort        = 8080
+	kafkaPort          = 9092
+
+	rabbitMQPort           = 5672
+	rabbitMQManagementPort = 15672
+	// The default guest user of RabbitMQ can only connect from localhost
+	rabbitMQUser     = "fleet"
+	rabbitMQPassword = "password"
+)
+
+// QueueDashboard is the web UI of a queue, routed through the proxy
+type QueueDashboard struct {
+	Name     string   // rabbitmq, kafka or horizon, the subdomain of queue.test
+	Domain   string   // e.g. rabbitmq.queue.test
+	Aliases  []string // queue.test for the first dashboard, .localhost names in unprivileged mode
+	Service  string   // Compose service serving it
+	Upstream string   // URL nginx proxies requests to
+	Host     string   // Host header of proxied requests, empty to keep the requested one
+	Index    string   // Path the root redirects to, like /horizon
+}
+
+// parseQueueType parses the queue type and version from a string like "rabbitmq:3.13"
+func parseQueueType(queueString string) (queueType string, version string) {
+	if queueString == "" {
+		return "", ""
+	}
+
+	queueType, version, _ = strings.Cut(strings.ToLower(queueString), ":")
+	if version == "" {
+		if set, ok := versionData.Queue[queueType]; ok {
+			version = set.Default
+		}
+	}
+	return queueType, version
+}
+
+// getQueueImage returns the Docker image of a queue, or "" for unknown types
+func getQueueImage(queueType, version string) string {
+	versions, ok := supportedQueueVersions[queueType]
+	if !ok {
+		return ""
+	}
+	if image, ok := versions[version]; ok {
+		return image
+	}
+	return versions["default"]
+}
+
+// getSharedQueueServiceName returns the shared service name of a queue, like rabbitmq-313
+func getSharedQueueServiceName(queueType, version string) string {
+	return fmt.Sprintf("%s-%s", queueType, strings.ReplaceAll(version, ".", ""))
+}
+
+// validateQueueService checks the queue of a service
+func validateQueueService(svc *Service) error {
+	queueType, version := parseQueueType(svc.Queue)
+	if svc.QueueUI && queueType != "kafka" {
+		return fmt.Errorf("service %s: queue_ui adds Kafka UI and needs a kafka queue, RabbitMQ has its management UI", svc.Name)
+	}
+	if svc.Queue == "" {
+		return nil
+	}
+
+	set, ok := versionData.Queue[queueType]
+	if !ok {
+		return fmt.Errorf("service %s: unsupported queue %q (supported: kafka, rabbitmq)", svc.Name, queueType)
+	}
+	if _, ok := set.Images[version]; !ok {
+		return fmt.Errorf("service %s: unsupported %s version %s (supported: %s)", svc.Name, queueType, version, strings.Join(set.getVersions(), ", "))
+	}
+	return nil
+}
+
+// validateQueueDashboardAuth checks queue_dashboard_auth is a user:password pair
+func validateQueueDashboardAuth(config *Config) error {
+	if config.QueueDashboardAuth == "" {
+		return nil
+	}
+	user, password, found := strings.Cut(config.QueueDashboardAuth, ":")
+	if !found || user == "" || password == "" || strings.ContainsAny(config.QueueDashboardAuth, "\n\r") {
+		return fmt.Errorf("queue_dashboard_auth must be user:password")
+	}
+	return nil
+}
+
+// addQueueEnvVars gives a service the address of its queue. Variables the service
+// already has, like those of the config, are kept.
+func addQueueEnvVars(service *DockerService, queueType, queueServiceName string) {
+	vars := map[string]string{}
+	switch queueType {
+	case "rabbitmq":
+		url := fmt.Sprintf("amqp://%s:%s@%s:%d", rabbitMQUser, rabbitMQPassword, queueServiceName, rabbitMQPort)
+		vars["RABBITMQ_HOST"] = queueServiceName
+		vars["RABBITMQ_PORT"] = fmt.Sprint(rabbitMQPort)
+		vars["RABBITMQ_USER"] = rabbitMQUser
+		vars["RABBITMQ_PASSWORD"] = rabbitMQPassword
+		vars["RABBITMQ_URL"] = url
+		vars["AMQP_URL"] = url
+	case "kafka":
+		brokers := fmt.Sprintf("%s:%d", queueServiceName, kafkaPort)
+		vars["KAFKA_BROKERS"] = brokers
+		vars["KAFKA_BOOTSTRAP_SERVERS"] = brokers
+	}
+
+	if service.Environment == nil {
+		service.Environment = make(map[string]string)
+	}
+	for key, value := range vars {
+		if _, set := service.Environment[key]; !set {
+			service.Environment[key] = value
+		}
+	}
+}
+
+// configureRabbitMQService configures a RabbitMQ broker with the management plugin. The
+// management UI is published on localhost, the first broker on 15672.
+func configureRabbitMQService(service *DockerService, queueServiceName string, config *Config) {
+	service.Volumes = append(service.Volumes, fmt.Sprintf("%s-data:/var/lib/rabbitmq", queueServiceName))
+	service.Environment["RABBITMQ_DEFAULT_USER"] = rabbitMQUser
+	service.Environment["RABBITMQ_DEFAULT_PASS"] = rabbitMQPassword
+
+	for i, broker := range getQueueBrokers(config, "rabbitmq") {
+		if broker == queueServiceName {
+			service.Ports = []string{fmt.Sprintf("127.0.0.1:%d:%d", rabbitMQManagementPort+i, rabbitMQManagementPort)}
+		}
+	}
+
+	service.HealthCheck = &HealthCheckYAML{
+		Test:     []string{"CMD", "rabbitmq-diagnostics", "-q", "ping"},
+		Interval: "10s",
+		Timeout:  "5s",
+		Retries:  5,
+	}
+}
+
+// configureKafkaService configures a single node Kafka broker in KRaft mode, without
+// ZooKeeper
+func configureKafkaService(service *DockerService, queueServiceName string) {
+	service.Volumes = append(service.Volumes, fmt.Sprintf("%s-data:/var/lib/kafka/data", queueServiceName))
+	for key, value := range map[string]string{
+		"KAFKA_NODE_ID":                                  "1",
+		"KAFKA_PROCESS_ROLES":                            "broker,controller",
+		"KAFKA_LISTENERS":                                fmt.Sprintf("PLAINTEXT://:%d,CONTROLLER://:9093", kafkaPort),
+		"KAFKA_ADVERTISED_LISTENERS":                     fmt.Sprintf("PLAINTEXT://%s:%d", queueServiceName, kafkaPort),
+		"KAFKA_CONTROLLER_LISTENER_NAMES":                "CONTROLLER",
+		"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP":           "CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT",
+		"KAFKA_CONTROLLER_QUORUM_VOTERS":                 "1@localhost:9093",
+		"KAFKA_LOG_DIRS":                                 "/var/lib/kafka/data",
+		"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR":         "1",
+		"KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR": "1",
+		"KAFKA_TRANSACTION_STATE_LOG_MIN_ISR":            "1",
+		"KAFKA_GROUP_INITIAL_REBALANCE_DELAY_MS":         "0",
+	} {
+		service.Environment[key] = value
+	}
+	service.HealthCheck = &HealthCheckYAML{
+		Test:     []string{"CMD-SHELL", fmt.Sprintf("/opt/kafka/bin/kafka-broker-api-versions.sh --bootstrap-server localhost:%d >/dev/null 2>&1", kafkaPort)},
+		Interval: "10s",
+		Timeout:  "10s",
+		Retries:  10,
+	}
+}
+
+// getQueueBrokers returns the brokers of a type of queue in the project, in config order
+func getQueueBrokers(config *Config, brokerType string) []string {
+	var brokers []string
+	for _, svc := range config.Services {
+		queueType, version := parseQueueType(svc.Queue)
+		if queueType != brokerType || getQueueImage(queueType, version) == "" {
+			continue
+		}
+		if name := getSharedQueueServiceName(queueType, version); !containsString(brokers, name) {
+			brokers = append(brokers, name)
+		}
+	}
+	return brokers
+}
+
+// hasKafkaUI reports whether a service of the project asks for Kafka UI
+func hasKafkaUI(config *Config) bool {
+	for _, svc := range config.Services {
+		if queueType, _ := parseQueueType(svc.Queue); svc.QueueUI && queueType == "kafka" {
+			return true
+		}
+	}
+	return false
+}
+
+// addKafkaUI adds the dashboard of the Kafka brokers, one cluster per broker
+func addKafkaUI(compose *DockerCompose, brokers []string) {
+	env := map[string]string{"DYNAMIC_CONFIG_ENABLED": "false"}
+	for i, broker := range brokers {
+		env[fmt.Sprintf("KAFKA_CLUSTERS_%d_NAME", i)] = broker
+		env[fmt.Sprintf("KAFKA_CLUSTERS_%d_BOOTSTRAPSERVERS", i)] = fmt.Sprintf("%s:%d", broker, kafkaPort)
+	}
+	compose.Services[kafkaUIServiceName] = DockerService{
+		Image:       kafkaUIImage,
+		Networks:    []string{"fleet-network"},
+		Restart:     "unless-stopped",
+		Environment: env,
+		DependsOn:   brokers,
+	}
+}
+
+// addQueueService adds the broker of a service, shared by every service using the same
+// queue and version, and gives the service its address
+func addQueueService(compose *DockerCompose, svc *Service, config *Config) {
+	queueType, version := parseQueueType(svc.Queue)
+	image := getQueueImage(queueType, version)
+	if image == "" {
+		return
+	}
+	queueServiceName := getSharedQueueServiceName(queueType, version)
+
+	// A service with only queue has no container of its own, it just asks for the broker
+	if service, exists := compose.Services[svc.Name]; exists && service.Image == "" && service.Build == "" {
+		delete(compose.Services, svc.Name)
+	}
+
+	if _, exists := compose.Services[queueServiceName]; !exists {
+		queueService := DockerService{
+			Image:       image,
+			Networks:    []string{"fleet-network"},
+			Restart:     "unless-stopped",
+			Volumes:     []string{},
+			Environment: make(map[string]string),
+		}
+
+		switch queueType {
+		case "rabbitmq":
+			configureRabbitMQService(&queueService, queueServiceName, config)
+		case "kafka":
+			configureKafkaService(&queueService, queueServiceName)
+			// One dashboard shows every broker of the project
+			if _, exists := compose.Services[kafkaUIServiceName]; !exists && hasKafkaUI(config) && shouldAddNginxProxy(config) {
+				addKafkaUI(compose, getQueueBrokers(config, "kafka"))
+			}
+		}
+
+		compose.Services[queueServiceName] = queueService
+	}
+
+	// PHP code runs in the FPM container, so it needs the address as well
+	for _, name := range []string{svc.Name, getAppServiceName(svc)} {
+		service, exists := compose.Services[name]
+		if !exists {
+			continue
+		}
+		if !containsString(service.DependsOn, queueServiceName) {
+			service.DependsOn = append(service.DependsOn, queueServiceName)
+		}
+		addQueueEnvVars(&service, queueType, queueServiceName)
+		compose.Services[name] = service
+	}
+}
+
+// hasHorizon reports whether a Laravel service has Horizon installed
+func hasHorizon(svc *Service) bool {
+	if svc.Framework != "laravel" || svc.Folder == "" {
+		return false
+	}
+	content, err := os.ReadFile(filepath.Join(svc.hostPath(svc.Folder), "composer.json"))
+	return err == nil && strings.Contains(string(content), `"laravel/horizon"`)
+}
+
+// getQueueDashboards returns the dashboards of the queues of the project: the RabbitMQ
+// management UI, Kafka UI when queue_ui is set, and Horizon of the Laravel services that have it. When two
+// dashboards have the same name, the next ones get the service name appended.
+func getQueueDashboards(config *Config) []QueueDashboard {
+	if !shouldAddNginxProxy(config) {
+		return nil
+	}
+
+	var dashboards []QueueDashboard
+	add := func(name, suffix string, dashboard QueueDashboard) {
+		for _, existing := range dashboards {
+			if existing.Name == name {
+				name = name + "-" + suffix
+				break
+			}
+		}
+		dashboard.Name = name
+		dashboards = append(dashboards, dashboard)
+	}
+
+	for _, svc := range config.Services {
+		queueType, version := parseQueueType(svc.Queue)
+		if getQueueImage(queueType, version) == "" || queueType != "rabbitmq" {
+			continue
+		}
+		serviceName := getSharedQueueServiceName(queueType, version)
+		if !containsQueueDashboard(dashboards, serviceName) {
+			add("rabbitmq", serviceName, QueueDashboard{
+				Service:  serviceName,
+				Upstream: fmt.Sprintf("http://%s:%d", serviceName, rabbitMQManagementPort),
+			})
+		}
+	}
+	if hasKafkaUI(config) {
+		add("kafka", kafkaUIServiceName, QueueDashboard{
+			Service:  kafkaUIServiceName,
+			Upstream: fmt.Sprintf("http://%s:%d", kafkaUIServiceName, kafkaUIPort),
+		})
+	}
+	for i := range config.Services {
+		svc := &config.Services[i]
+		domain := getDomainForService(svc)
+		if domain == "" || !hasHorizon(svc) {
+			continue
+		}
+		// Horizon is a page of the app, the request goes back through the vhost of the app
+		upstream := "http://127.0.0.1:80"
+		if svc.SSL {
+			port := svc.SSLPort
+			if port == 0 {
+				port = 443
+			}
+			upstream = fmt.Sprintf("https://127.0.0.1:%d", port)
+		}
+		add("horizon", svc.Name, QueueDashboard{Service: svc.Name, Upstream: upstream, Host: domain, Index: "/horizon"})
+	}
+
+	for i := range dashboards {
+		dashboards[i].Domain = fmt.Sprintf("%s.%s", dashboards[i].Name, queueDashboardDomain)
+		if i == 0 {
+			dashboards[i].Aliases = append(dashboards[i].Aliases, queueDashboardDomain)
+		}
+		if config.Unprivileged {
+			dashboards[i].Aliases = append(dashboards[i].Aliases, fmt.Sprintf("%s.queue.localhost", dashboards[i].Name))
+		}
+	}
+	return dashboards
+}
+
+// containsQueueDashboard reports whether a dashboard of a service is in the list
+func containsQueueDashboard(dashboards []QueueDashboard, service string) bool {
+	for _, dashboard := range dashboards {
+		if dashboard.Service == service {
+			return true
+		}
+	}
+	return false
+}
+
+// planQueueDashboardAuth plans the basic auth user of the queue dashboards in the
+// htpasswd format nginx reads, with a plain text password
+func planQueueDashboardAuth(plan *ArtifactPlan, config *Config, filename string) {
+	user, password, _ := strings.Cut(config.QueueDashboardAuth, ":")
+	// nginx workers don't run as the owner of the file, they need to read it
+	plan.addFile(filename, []byte(fmt.Sprintf("%s:{PLAIN}%s\n", user, password)), 0644)
+}
@@ -0,0 +1,225 @@
+package fleetcli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// remoteConfigLimit is the largest config Fleet downloads
+const remoteConfigLimit = 1 << 20
+
+// checksumPattern matches the checksum pin at the end of a remote config, like
+// https://example.com/fleet.toml@sha256:<hex>
+var checksumPattern = regexp.MustCompile(`@sha256:([0-9a-f]{64})$`)
+
+// RemoteConfig is a config file fetched from a URL or a git repository
+type RemoteConfig struct {
+	// Source is the reference without its checksum
+	Source string
+	// URL is set for configs downloaded over HTTP
+	URL string
+	// Repo, Ref and Path are set for configs read from a git repository: repo#ref:path
+	Repo string
+	Ref  string
+	Path string
+	// Checksum is the sha256 the config must have, or "" when it isn't pinned
+	Checksum string
+}
+
+// remoteConfigPaths caches the local copy of each remote config a command already fetched
+var (
+	remoteConfigPaths   = map[string]string{}
+	remoteConfigPathsMu sync.Mutex
+)
+
+// isRemoteConfig reports whether the config flag is a URL or a git reference instead of a file
+func isRemoteConfig(source string) bool {
+	for _, prefix := range []string{"http://", "https://", "ssh://", "git://", "file://", "git@"} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRemoteConfig parses a remote config reference. References with a #ref:path fragment
+// are read from a git repository, other http(s) URLs are downloaded.
+func parseRemoteConfig(source string) (*RemoteConfig, error) {
+	remote := &RemoteConfig{Source: source}
+	if match := checksumPattern.FindStringSubmatch(source); match != nil {
+		remote.Checksum = match[1]
+		remote.Source = strings.TrimSuffix(source, match[0])
+	} else if strings.Contains(source, "@sha256:") {
+		return nil, fmt.Errorf("invalid checksum in %s, use @sha256: followed by 64 lowercase hex digits", source)
+	}
+
+	if repo, fragment, found := strings.Cut(remote.Source, "#"); found {
+		ref, configPath, hasPath := strings.Cut(fragment, ":")
+		if !hasPath || ref == "" || configPath == "" {
+			return nil, fmt.Errorf("invalid git reference %s, use <repo>#<ref>:<path>", remote.Source)
+		}
+		remote.Repo, remote.Ref, remote.Path = repo, ref, configPath
+		return remote, nil
+	}
+
+	parsed, err := url.Parse(remote.Source)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid config source %s, use an http(s) URL or <repo>#<ref>:<path>", remote.Source)
+	}
+	remote.URL = remote.Source
+	remote.Path = parsed.Path
+	return remote, nil
+}
+
+// getCacheName returns the file name of the local copy. It keeps the extension of the
+// config, which selects its format.
+func (remote *RemoteConfig) getCacheName() string {
+	sum := sha256.Sum256([]byte(remote.Source))
+	return fmt.Sprintf("%s-%s", hex.EncodeToString(sum[:8]), path.Base(remote.Path))
+}
+
+// fetch downloads the config
+func (remote *RemoteConfig) fetch() ([]byte, error) {
+	if remote.URL != "" {
+		return fetchConfigURL(remote.URL)
+	}
+	return fetchGitFile(remote.Repo, remote.Ref, remote.Path)
+}
+
+// getRemoteConfigDir returns where remote configs are cached
+var getRemoteConfigDir = func() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".fleet", "configs")
+}
+
+// fetchConfigURL downloads a config over HTTP
+var fetchConfigURL = func(configURL string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(configURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", configURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", configURL, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, remoteConfigLimit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", configURL, err)
+	}
+	if len(data) > remoteConfigLimit {
+		return nil, fmt.Errorf("failed to download %s: larger than %s", configURL, formatBytes(remoteConfigLimit))
+	}
+	return data, nil
+}
+
+// fetchGitFile reads a file at a ref of a git repository, fetching only that ref
+var fetchGitFile = func(repo, ref, file string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "fleet-config-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) ([]byte, error) {
+		cmd := newCommand("git", append([]string{"-C", dir}, args...)...)
+		// Fail instead of waiting for credentials nobody will type
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		output, err := cmd.Output()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return output, err
+	}
+
+	if _, err := git("init", "-q"); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", repo, err)
+	}
+	// A repo or ref starting with a dash must not be read as an option like --upload-pack
+	if _, err := git("fetch", "-q", "--depth", "1", "--", repo, ref); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s from %s: %w", ref, repo, err)
+	}
+	data, err := git("show", "FETCH_HEAD:"+file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", file, ref, err)
+	}
+	return data, nil
+}
+
+// getChecksum returns the sha256 of a config in hex
+func getChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// resolveRemoteConfig fetches a remote config and returns the path of its local copy.
+// Pinned configs are only fetched when the cached copy doesn't match the checksum.
+// Unpinned configs are fetched every time, the cached copy is used when that fails.
+func resolveRemoteConfig(source string) (string, error) {
+	remoteConfigPathsMu.Lock()
+	defer remoteConfigPathsMu.Unlock()
+	if configPath, exists := remoteConfigPaths[source]; exists {
+		return configPath, nil
+	}
+
+	remote, err := parseRemoteConfig(source)
+	if err != nil {
+		return "", err
+	}
+	configPath := filepath.Join(getRemoteConfigDir(), remote.getCacheName())
+
+	if remote.Checksum != "" {
+		if data, err := os.ReadFile(configPath); err == nil && getChecksum(data) == remote.Checksum {
+			remoteConfigPaths[source] = configPath
+			return configPath, nil
+		}
+	}
+
+	data, err := remote.fetch()
+	if err != nil {
+		info, statErr := os.Stat(configPath)
+		if remote.Checksum != "" || statErr != nil {
+			return "", err
+		}
+		warnf("⚠️  Warning: %v\n   Using the copy fetched on %s\n", err, info.ModTime().Format("2006-01-02 15:04"))
+		remoteConfigPaths[source] = configPath
+		return configPath, nil
+	}
+
+	checksum := getChecksum(data)
+	if remote.Checksum != "" && checksum != remote.Checksum {
+		return "", fmt.Errorf("%s has checksum sha256:%s, not the pinned sha256:%s. It changed since it was pinned", remote.Source, checksum, remote.Checksum)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to cache %s: %w", remote.Source, err)
+	}
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to cache %s: %w", remote.Source, err)
+	}
+	if remote.Checksum == "" {
+		infof("📥 Fetched %s (sha256:%s)\n", remote.Source, checksum)
+	}
+
+	remoteConfigPaths[source] = configPath
+	return configPath, nil
+}
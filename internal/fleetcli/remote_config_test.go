@@ -0,0 +1,192 @@
+package fleetcli
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RemoteConfigTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+	cacheDir    string
+}
+
+const remoteConfigSample = "project = \"shop\"\n\n[[services]]\nname = \"web\"\nimage = \"nginx:alpine\"\nport = 80\n"
+
+func (suite *RemoteConfigTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+
+	suite.cacheDir = filepath.Join(suite.helper.TempDir(), "configs")
+	originalDir := getRemoteConfigDir
+	originalURL := fetchConfigURL
+	originalGit := fetchGitFile
+	getRemoteConfigDir = func() string { return suite.cacheDir }
+	remoteConfigPaths = map[string]string{}
+	suite.T().Cleanup(func() {
+		getRemoteConfigDir = originalDir
+		fetchConfigURL = originalURL
+		fetchGitFile = originalGit
+		remoteConfigPaths = map[string]string{}
+	})
+}
+
+func (suite *RemoteConfigTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *RemoteConfigTestSuite) TestParseRemoteConfig() {
+	checksum := getChecksum([]byte(remoteConfigSample))
+	testCases := []struct {
+		name     string
+		source   string
+		expected *RemoteConfig
+		wantErr  string
+	}{
+		{
+			name:     "url",
+			source:   "https://example.com/stacks/fleet.toml",
+			expected: &RemoteConfig{Source: "https://example.com/stacks/fleet.toml", URL: "https://example.com/stacks/fleet.toml", Path: "/stacks/fleet.toml"},
+		},
+		{
+			name:     "pinned url",
+			source:   "https://example.com/fleet.yml@sha256:" + checksum,
+			expected: &RemoteConfig{Source: "https://example.com/fleet.yml", URL: "https://example.com/fleet.yml", Path: "/fleet.yml", Checksum: checksum},
+		},
+		{
+			name:     "git over ssh",
+			source:   "git@github.com:acme/dev-stacks.git#v1.2:shop/fleet.toml",
+			expected: &RemoteConfig{Source: "git@github.com:acme/dev-stacks.git#v1.2:shop/fleet.toml", Repo: "git@github.com:acme/dev-stacks.git", Ref: "v1.2", Path: "shop/fleet.toml"},
+		},
+		{
+			name:     "git over https",
+			source:   "https://github.com/acme/dev-stacks.git#main:fleet.toml",
+			expected: &RemoteConfig{Source: "https://github.com/acme/dev-stacks.git#main:fleet.toml", Repo: "https://github.com/acme/dev-stacks.git", Ref: "main", Path: "fleet.toml"},
+		},
+		{name: "git without path", source: "git@github.com:acme/dev-stacks.git#main", wantErr: "use <repo>#<ref>:<path>"},
+		{name: "ssh without ref", source: "ssh://git@github.com/acme/dev-stacks.git", wantErr: "invalid config source"},
+		{name: "short checksum", source: "https://example.com/fleet.toml@sha256:abc", wantErr: "invalid checksum"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			remote, err := parseRemoteConfig(tc.source)
+			if tc.wantErr != "" {
+				suite.ErrorContains(err, tc.wantErr)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Equal(tc.expected, remote)
+		})
+	}
+
+	suite.False(isRemoteConfig("fleet.toml"))
+	suite.False(isRemoteConfig("configs/fleet#1.toml"))
+}
+
+func (suite *RemoteConfigTestSuite) TestLoadConfigFromURL() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, remoteConfigSample)
+	}))
+	defer server.Close()
+
+	config, err := loadConfig(server.URL + "/fleet.toml")
+	suite.Require().NoError(err)
+	suite.Equal("shop", config.Project)
+
+	entries, err := os.ReadDir(suite.cacheDir)
+	suite.Require().NoError(err)
+	suite.Require().Len(entries, 1)
+	suite.Contains(entries[0].Name(), "-fleet.toml")
+}
+
+func (suite *RemoteConfigTestSuite) TestChecksumPinning() {
+	fetches := 0
+	content := remoteConfigSample
+	fetchConfigURL = func(configURL string) ([]byte, error) {
+		fetches++
+		return []byte(content), nil
+	}
+	source := "https://example.com/fleet.toml@sha256:" + getChecksum([]byte(remoteConfigSample))
+
+	_, err := resolveRemoteConfig(source)
+	suite.Require().NoError(err)
+
+	// A pinned config is read from the cache while it matches
+	remoteConfigPaths = map[string]string{}
+	_, err = resolveRemoteConfig(source)
+	suite.Require().NoError(err)
+	suite.Equal(1, fetches)
+
+	content = remoteConfigSample + "\n[[services]]\nname = \"miner\"\nimage = \"miner\"\n"
+	_, err = resolveRemoteConfig("https://example.com/other.toml@sha256:" + getChecksum([]byte(remoteConfigSample)))
+	suite.ErrorContains(err, "not the pinned sha256:")
+}
+
+func (suite *RemoteConfigTestSuite) TestFallsBackToCachedCopy() {
+	fetchConfigURL = func(configURL string) ([]byte, error) {
+		return []byte(remoteConfigSample), nil
+	}
+	configPath, err := resolveRemoteConfig("https://example.com/fleet.toml")
+	suite.Require().NoError(err)
+
+	remoteConfigPaths = map[string]string{}
+	fetchConfigURL = func(configURL string) ([]byte, error) {
+		return nil, errors.New("failed to download: no such host")
+	}
+	cached, err := resolveRemoteConfig("https://example.com/fleet.toml")
+	suite.Require().NoError(err)
+	suite.Equal(configPath, cached)
+
+	_, err = resolveRemoteConfig("https://example.com/never-fetched.toml")
+	suite.ErrorContains(err, "no such host")
+}
+
+func (suite *RemoteConfigTestSuite) TestFetchGitFile() {
+	if _, err := exec.LookPath("git"); err != nil {
+		suite.T().Skip("git is not installed")
+	}
+
+	repo := filepath.Join(suite.helper.TempDir(), "stacks")
+	suite.Require().NoError(os.MkdirAll(filepath.Join(repo, "shop"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(repo, "shop", "fleet.toml"), []byte(remoteConfigSample), 0644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=Fleet", "-c", "user.email=fleet@example.com", "commit", "-q", "-m", "Add shop"},
+		{"tag", "v1"},
+	} {
+		output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		suite.Require().NoError(err, string(output))
+	}
+
+	config, err := loadConfig("file://" + repo + "#v1:shop/fleet.toml")
+	suite.Require().NoError(err)
+	suite.Equal("shop", config.Project)
+
+	_, err = fetchGitFile("file://"+repo, "v1", "missing.toml")
+	suite.ErrorContains(err, "failed to read missing.toml at v1")
+
+	// A repo looking like an option is still a repo
+	marker := filepath.Join(suite.helper.TempDir(), "injected")
+	_, err = fetchGitFile("--upload-pack=touch "+marker, "file://"+repo, "shop/fleet.toml")
+	suite.ErrorContains(err, "failed to fetch")
+	suite.NoFileExists(marker)
+}
+
+func TestRemoteConfigSuite(t *testing.T) {
+	suite.Run(t, new(RemoteConfigTestSuite))
+}
@@ -0,0 +1,103 @@
+package fleetcli
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// rollingPollInterval is how often a rolling restart checks whether a container is ready
+var rollingPollInterval = 2 * time.Second
+
+// validateReplicas checks the replicas of a service
+func validateReplicas(svc *Service) error {
+	if svc.Replicas < 0 {
+		return fmt.Errorf("service %s: replicas must be positive", svc.Name)
+	}
+	if svc.Replicas <= 1 {
+		return nil
+	}
+	// Services with a domain are reached through the proxy, the others publish their ports
+	if getDomainForService(svc) == "" && len(svc.Ports) > 0 {
+		return fmt.Errorf("service %s: only one replica can publish its ports, give it a domain to run %d replicas behind the proxy", svc.Name, svc.Replicas)
+	}
+	return nil
+}
+
+// configureReplicas runs replicas of a service, and of the container running its code
+// when that is a sidecar like PHP-FPM. Docker's DNS returns every replica, and nginx
+// balances requests between them.
+func configureReplicas(compose *DockerCompose, svc *Service) {
+	if svc.Replicas <= 1 {
+		return
+	}
+
+	for _, name := range []string{svc.Name, getAppServiceName(svc)} {
+		if service, exists := compose.Services[name]; exists {
+			service.Deploy = &DockerDeploy{Replicas: svc.Replicas}
+			compose.Services[name] = service
+		}
+	}
+}
+
+// getContainerStates returns the containers of a service, see getComposeServiceStates
+var getContainerStates = getComposeServiceStates
+
+// restartContainer restarts a single container
+var restartContainer = func(name string) error {
+	return runDocker([]string{"restart", name})
+}
+
+// waitForContainerReady polls compose until a container of the service is ready
+func waitForContainerReady(composeFiles ComposeFiles, service, container string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		entries, err := getContainerStates(composeFiles, service)
+		if err == nil {
+			for _, entry := range entries {
+				if entry.Name == container && isServiceReady(entry) {
+					return nil
+				}
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s did not become healthy within %s, stopping before the next instance", container, timeout)
+		}
+		time.Sleep(rollingPollInterval)
+	}
+}
+
+// rollingRestart restarts the containers of a service one at a time, waiting for each to
+// be healthy before the next, so the others keep serving traffic
+func rollingRestart(composeFiles ComposeFiles, service string, timeout time.Duration) error {
+	entries, err := getContainerStates(composeFiles, service)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("service %s has no containers, is the project running?", service)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	if len(entries) == 1 {
+		warnf("⚠️  %s runs a single instance, set replicas to 2 or more to restart it without dropping traffic\n", service)
+	}
+	for _, entry := range entries {
+		if !isServiceReady(entry) {
+			warnf("⚠️  %s is %s, restarting another instance leaves fewer serving traffic\n", entry.Name, entry.Status)
+		}
+	}
+
+	for i, entry := range entries {
+		infof("🔄 Restarting %s (%d/%d)\n", entry.Name, i+1, len(entries))
+		if err := restartContainer(entry.Name); err != nil {
+			return err
+		}
+		infof("⏳ Waiting for %s to become healthy...\n", entry.Name)
+		if err := waitForContainerReady(composeFiles, service, entry.Name, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
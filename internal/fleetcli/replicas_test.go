@@ -0,0 +1,135 @@
+package fleetcli
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ReplicasTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *ReplicasTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *ReplicasTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *ReplicasTestSuite) TestValidateReplicas() {
+	testCases := []struct {
+		name    string
+		service Service
+		wantErr string
+	}{
+		{"behind the proxy", Service{Name: "api", Image: "node:20", Port: 3000, Replicas: 3}, ""},
+		{"single replica with ports", Service{Name: "worker", Image: "node:20", Ports: []string{"9000:9000"}, Replicas: 1}, ""},
+		{"no ports", Service{Name: "worker", Image: "node:20", Replicas: 2}, ""},
+		{"negative", Service{Name: "api", Image: "node:20", Replicas: -1}, "must be positive"},
+		{"published ports", Service{Name: "worker", Image: "node:20", Ports: []string{"9000:9000"}, Replicas: 2}, "only one replica can publish"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			err := validateReplicas(&tc.service)
+			if tc.wantErr == "" {
+				suite.NoError(err)
+			} else {
+				suite.ErrorContains(err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *ReplicasTestSuite) TestGenerateDockerCompose() {
+	suite.Require().NoError(os.MkdirAll("shop", 0755))
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Replicas: 3},
+		{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "shop", Domain: "shop.test", Replicas: 2},
+		{Name: "worker", Image: "node:20"},
+	}}
+
+	compose := generateDockerCompose(config)
+
+	suite.Equal(&DockerDeploy{Replicas: 3}, compose.Services["api"].Deploy)
+	suite.Equal(&DockerDeploy{Replicas: 2}, compose.Services["shop"].Deploy)
+	suite.Equal(&DockerDeploy{Replicas: 2}, compose.Services["shop-php"].Deploy)
+	suite.Nil(compose.Services["worker"].Deploy)
+
+	// A forwarded port can't be shared by replicas
+	config.Cloud = true
+	compose = generateDockerCompose(config)
+	suite.Nil(compose.Services["api"].Deploy)
+}
+
+func (suite *ReplicasTestSuite) TestRollingRestart() {
+	originalStates := getContainerStates
+	originalRestart := restartContainer
+	originalInterval := rollingPollInterval
+	defer func() {
+		getContainerStates = originalStates
+		restartContainer = originalRestart
+		rollingPollInterval = originalInterval
+	}()
+	rollingPollInterval = time.Millisecond
+
+	// Each restarted container reports starting once before it is healthy
+	starting := map[string]int{}
+	var events []string
+	getContainerStates = func(composeFiles ComposeFiles, services ...string) ([]ComposePSEntry, error) {
+		var entries []ComposePSEntry
+		for _, name := range []string{"test-api-2", "test-api-1"} {
+			entry := ComposePSEntry{Name: name, Service: "api", State: "running", Health: "healthy"}
+			if starting[name] > 0 {
+				starting[name]--
+				entry.Health = "starting"
+				events = append(events, "wait "+name)
+			}
+			entries = append(entries, entry)
+		}
+		return entries, nil
+	}
+	restartContainer = func(name string) error {
+		events = append(events, "restart "+name)
+		starting[name] = 1
+		return nil
+	}
+
+	suite.Require().NoError(rollingRestart(ComposeFiles{}, "api", time.Second))
+	suite.Equal([]string{"restart test-api-1", "wait test-api-1", "restart test-api-2", "wait test-api-2"}, events)
+
+	// The next instance isn't restarted while one is unhealthy
+	events = nil
+	restartContainer = func(name string) error {
+		events = append(events, "restart "+name)
+		starting[name] = 1000
+		return nil
+	}
+	err := rollingRestart(ComposeFiles{}, "api", 10*time.Millisecond)
+	suite.ErrorContains(err, "test-api-1 did not become healthy")
+	suite.NotContains(events, "restart test-api-2")
+
+	restartContainer = func(name string) error { return errors.New("no such container") }
+	suite.Error(rollingRestart(ComposeFiles{}, "api", time.Second))
+
+	getContainerStates = func(composeFiles ComposeFiles, services ...string) ([]ComposePSEntry, error) {
+		return nil, nil
+	}
+	suite.ErrorContains(rollingRestart(ComposeFiles{}, "api", time.Second), "is the project running?")
+}
+
+func TestReplicasSuite(t *testing.T) {
+	suite.Run(t, new(ReplicasTestSuite))
+}
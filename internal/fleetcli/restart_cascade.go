@@ -0,0 +1,232 @@
+package fleetcli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ComposePSEntry is a single row of `docker compose ps --format json`
+type ComposePSEntry struct {
+	Name     string `json:"Name"`
+	Service  string `json:"Service"`
+	State    string `json:"State"`
+	Health   string `json:"Health"`
+	Status   string `json:"Status"`
+	ExitCode int    `json:"ExitCode"`
+}
+
+// parseComposePS parses compose ps JSON output.
+// Compose v2.21+ prints one JSON object per line, older releases print a single array.
+func parseComposePS(output []byte) ([]ComposePSEntry, error) {
+	trimmed := bytes.TrimSpace(output)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	if trimmed[0] == '[' {
+		var entries []ComposePSEntry
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse compose ps output: %w", err)
+		}
+		return entries, nil
+	}
+
+	var entries []ComposePSEntry
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry ComposePSEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse compose ps output: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// getComposeServiceStates queries compose for the state of all (or the given) services
+func getComposeServiceStates(composeFiles ComposeFiles, services ...string) ([]ComposePSEntry, error) {
+	args := composeArgs(composeFiles, "ps", "--all", "--format", "json")
+	args = append(args, services...)
+	cmd, err := dockerCommand(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service status: %w", err)
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service status: %w", err)
+	}
+	return parseComposePS(output)
+}
+
+// isServiceReady reports whether a compose service is up and, if it has a health check, healthy
+func isServiceReady(entry ComposePSEntry) bool {
+	if entry.State != "running" {
+		return false
+	}
+	return entry.Health == "" || entry.Health == "healthy"
+}
+
+// waitForServiceReady polls compose until every container of the service is ready
+func waitForServiceReady(composeFiles ComposeFiles, service string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		entries, err := getComposeServiceStates(composeFiles, service)
+		if errors.Is(err, errComposeV2Required) {
+			return err
+		}
+		if err == nil && len(entries) > 0 {
+			ready := true
+			for _, entry := range entries {
+				if !isServiceReady(entry) {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("service %s did not become healthy within %s", service, timeout)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// reverseDependencies maps each service to the services that depend on it
+func reverseDependencies(compose *DockerCompose) map[string][]string {
+	reverse := make(map[string][]string)
+	for name, service := range compose.Services {
+		for _, dep := range service.DependsOn {
+			reverse[dep] = append(reverse[dep], name)
+		}
+	}
+	for dep := range reverse {
+		sort.Strings(reverse[dep])
+	}
+	return reverse
+}
+
+// cascadeRestartOrder returns the transitive dependents of the targets in
+// topological order (a service always comes after everything it depends on).
+// The targets themselves are not included.
+func cascadeRestartOrder(compose *DockerCompose, targets []string) []string {
+	reverse := reverseDependencies(compose)
+
+	// Collect all transitive dependents
+	affected := make(map[string]bool)
+	queue := append([]string{}, targets...)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range reverse[current] {
+			if !affected[dependent] {
+				affected[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+	for _, target := range targets {
+		delete(affected, target)
+	}
+
+	// Depth-first topological sort restricted to affected services
+	var order []string
+	visited := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		deps := append([]string{}, compose.Services[name].DependsOn...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if affected[dep] {
+				visit(dep)
+			}
+		}
+		order = append(order, name)
+	}
+
+	names := make([]string, 0, len(affected))
+	for name := range affected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		visit(name)
+	}
+
+	return order
+}
+
+// findReloadSignal returns the configured reload signal for a compose service, if any
+func findReloadSignal(config *Config, serviceName string) string {
+	for _, svc := range config.Services {
+		if svc.Name == serviceName {
+			return svc.ReloadSignal
+		}
+	}
+	return ""
+}
+
+// restartWithCascade restarts the targets, waits for them to be healthy and then
+// restarts (or signals) every dependent service in dependency order
+func restartWithCascade(config *Config, composeFiles ComposeFiles, targets []string, timeout time.Duration) error {
+	compose, err := readComposeFiles(composeFiles)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		if _, ok := compose.Services[target]; !ok {
+			return fmt.Errorf("unknown service: %s", target)
+		}
+	}
+
+	args := append(composeArgs(composeFiles, "restart"), targets...)
+	if err := runDocker(args); err != nil {
+		return err
+	}
+
+	dependents := cascadeRestartOrder(compose, targets)
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	for _, target := range targets {
+		infof("⏳ Waiting for %s to become healthy...\n", target)
+		if err := waitForServiceReady(composeFiles, target, timeout); err != nil {
+			return err
+		}
+	}
+
+	for _, dependent := range dependents {
+		if signal := findReloadSignal(config, dependent); signal != "" {
+			infof("🔁 Sending %s to %s\n", signal, dependent)
+			if err := runDocker(composeArgs(composeFiles, "kill", "-s", signal, dependent)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		infof("🔄 Restarting dependent service %s\n", dependent)
+		if err := runDocker(composeArgs(composeFiles, "restart", dependent)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
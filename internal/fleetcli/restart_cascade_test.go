@@ -0,0 +1,168 @@
+package fleetcli
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RestartCascadeTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+}
+
+func (suite *RestartCascadeTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+}
+
+func (suite *RestartCascadeTestSuite) TearDownTest() {
+	suite.helper.Cleanup()
+}
+
+func (suite *RestartCascadeTestSuite) sampleCompose() *DockerCompose {
+	return &DockerCompose{
+		Services: map[string]DockerService{
+			"database": {},
+			"cache":    {},
+			"api":      {DependsOn: []string{"database", "cache"}},
+			"worker":   {DependsOn: []string{"api", "database"}},
+			"web":      {DependsOn: []string{"api"}},
+			"mailpit":  {},
+		},
+	}
+}
+
+func (suite *RestartCascadeTestSuite) TestReverseDependencies() {
+	reverse := reverseDependencies(suite.sampleCompose())
+
+	suite.Equal([]string{"api", "worker"}, reverse["database"])
+	suite.Equal([]string{"api"}, reverse["cache"])
+	suite.Equal([]string{"web", "worker"}, reverse["api"])
+	suite.Empty(reverse["mailpit"])
+}
+
+func (suite *RestartCascadeTestSuite) TestCascadeRestartOrder() {
+	tests := []struct {
+		name     string
+		targets  []string
+		expected []string
+	}{
+		{
+			name:     "database restarts all transitive dependents",
+			targets:  []string{"database"},
+			expected: []string{"api", "web", "worker"},
+		},
+		{
+			name:     "cache restarts api and its dependents",
+			targets:  []string{"cache"},
+			expected: []string{"api", "web", "worker"},
+		},
+		{
+			name:     "leaf service has no dependents",
+			targets:  []string{"web"},
+			expected: nil,
+		},
+		{
+			name:     "targets are not restarted twice",
+			targets:  []string{"database", "api"},
+			expected: []string{"web", "worker"},
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			order := cascadeRestartOrder(suite.sampleCompose(), tt.targets)
+			suite.Equal(tt.expected, order)
+		})
+	}
+}
+
+func (suite *RestartCascadeTestSuite) TestCascadeRestartOrderIsTopological() {
+	order := cascadeRestartOrder(suite.sampleCompose(), []string{"database"})
+
+	position := make(map[string]int)
+	for i, name := range order {
+		position[name] = i
+	}
+	suite.Less(position["api"], position["worker"])
+	suite.Less(position["api"], position["web"])
+}
+
+func (suite *RestartCascadeTestSuite) TestParseComposePS() {
+	tests := []struct {
+		name     string
+		output   string
+		expected []ComposePSEntry
+	}{
+		{
+			name:     "empty output",
+			output:   "",
+			expected: nil,
+		},
+		{
+			name:   "json array",
+			output: `[{"Name":"app-db-1","Service":"database","State":"running","Health":"healthy"}]`,
+			expected: []ComposePSEntry{
+				{Name: "app-db-1", Service: "database", State: "running", Health: "healthy"},
+			},
+		},
+		{
+			name: "one object per line",
+			output: `{"Name":"app-db-1","Service":"database","State":"running","Health":"starting"}
+{"Name":"app-api-1","Service":"api","State":"exited","ExitCode":1}
+`,
+			expected: []ComposePSEntry{
+				{Name: "app-db-1", Service: "database", State: "running", Health: "starting"},
+				{Name: "app-api-1", Service: "api", State: "exited", ExitCode: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			entries, err := parseComposePS([]byte(tt.output))
+			suite.Require().NoError(err)
+			suite.Equal(tt.expected, entries)
+		})
+	}
+
+	_, err := parseComposePS([]byte("not json"))
+	suite.Error(err)
+}
+
+func (suite *RestartCascadeTestSuite) TestIsServiceReady() {
+	suite.True(isServiceReady(ComposePSEntry{State: "running"}))
+	suite.True(isServiceReady(ComposePSEntry{State: "running", Health: "healthy"}))
+	suite.False(isServiceReady(ComposePSEntry{State: "running", Health: "starting"}))
+	suite.False(isServiceReady(ComposePSEntry{State: "restarting"}))
+}
+
+func (suite *RestartCascadeTestSuite) TestFindReloadSignal() {
+	config := &Config{
+		Services: []Service{
+			{Name: "web", Image: "nginx", ReloadSignal: "SIGHUP"},
+			{Name: "api", Image: "node"},
+		},
+	}
+
+	suite.Equal("SIGHUP", findReloadSignal(config, "web"))
+	suite.Equal("", findReloadSignal(config, "api"))
+	suite.Equal("", findReloadSignal(config, "missing"))
+}
+
+func (suite *RestartCascadeTestSuite) TestParseFlagsAndArgs() {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cascade := fs.Bool("cascade", false, "")
+	file := fs.String("file", "fleet.toml", "")
+
+	args := parseFlagsAndArgs(fs, []string{"database", "--cascade", "--file", "custom.toml", "cache"})
+
+	suite.Equal([]string{"database", "cache"}, args)
+	suite.True(*cascade)
+	suite.Equal("custom.toml", *file)
+}
+
+func TestRestartCascadeSuite(t *testing.T) {
+	suite.Run(t, new(RestartCascadeTestSuite))
+}
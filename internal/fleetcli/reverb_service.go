@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -39,6 +39,7 @@ func addReverbService(compose *DockerCompose, svc *Service, config *Config) {
 			}
 			// Add Reverb environment variables
 			addReverbEnvVars(&appService, svc)
+			addReverbBrowserEnvVars(&appService, svc, config)
 			compose.Services[svc.Name] = appService
 		}
 		return
@@ -66,7 +67,7 @@ func addReverbService(compose *DockerCompose, svc *Service, config *Config) {
 	// Mount the Laravel application code
 	if svc.Folder != "" {
 		// Share the same code volume as the main application
-		reverbService.Volumes = append(reverbService.Volumes, fmt.Sprintf("./%s:/app", svc.Folder))
+		reverbService.Volumes = append(reverbService.Volumes, formatBindMount("./"+svc.Folder, "/app"))
 	}
 	
 	// Configure Reverb environment
@@ -94,6 +95,7 @@ func addReverbService(compose *DockerCompose, svc *Service, config *Config) {
 		
 		// Add Reverb environment variables to the app
 		addReverbEnvVars(&appService, svc)
+		addReverbBrowserEnvVars(&appService, svc, config)
 		compose.Services[svc.Name] = appService
 	}
 }
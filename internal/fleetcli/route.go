@@ -0,0 +1,317 @@
+package fleetcli
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// routeTestTimeout is how long fleet route test waits for the proxy to answer
+const routeTestTimeout = 10 * time.Second
+
+// configNamePattern matches the name key of a service in a TOML, YAML or JSON config:
+// name = "web", - name: web, "name": "web"
+var configNamePattern = regexp.MustCompile(`(?:^|[{,\s])"?name"?\s*[=:]\s*["']?([A-Za-z0-9_.-]+)`)
+
+// Route is a vhost of the proxy
+type Route struct {
+	Domains  []string
+	Path     string // Prefix of the services routed on a path, e.g. /api/
+	Service  string
+	Upstream string
+	SSL      string
+	// Source is where the service is defined, like fleet.toml:12
+	Source string
+}
+
+// findServiceLines returns the line each service is defined on in a config file
+func findServiceLines(configFile string) map[string]int {
+	lines := make(map[string]int)
+	file, err := os.Open(configFile)
+	if err != nil {
+		return lines
+	}
+	defer file.Close()
+
+	isTOML := filepath.Ext(configFile) == ".toml"
+	inServices := !isTOML
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		// Only names directly in [[services]] tables, not those of init containers
+		if isTOML && strings.HasPrefix(line, "[") {
+			inServices = strings.HasPrefix(line, "[[services]]")
+			continue
+		}
+		if !inServices {
+			continue
+		}
+		if match := configNamePattern.FindStringSubmatch(line); match != nil {
+			if _, exists := lines[match[1]]; !exists {
+				lines[match[1]] = number
+			}
+		}
+	}
+	return lines
+}
+
+// getRoutes returns the vhosts the proxy generates for a config, in config order
+func getRoutes(config *Config, configFile string) []Route {
+	lines := findServiceLines(configFile)
+
+	var routes []Route
+	for _, service := range getNginxServices(config) {
+		route := Route{
+			Domains:  append([]string{service.Domain}, service.Aliases...),
+			Service:  service.Name,
+			Upstream: fmt.Sprintf("http://%s:%d", service.Name, service.Port),
+			SSL:      "-",
+			Source:   configFile,
+		}
+		if service.IsPHP {
+			route.Upstream = fmt.Sprintf("fastcgi://%s:%d", service.Name, service.Port)
+		}
+		if service.Upstream != "" {
+			route.Upstream += " via " + debugProxyServiceName
+		}
+		if service.SSL {
+			route.SSL = fmt.Sprintf("https:%d", service.SSLPort)
+		}
+		if line, exists := lines[service.Name]; exists {
+			route.Source = fmt.Sprintf("%s:%d", configFile, line)
+		}
+		if !service.PathsOnly {
+			routes = append(routes, route)
+		}
+		for _, pathRoute := range service.PathRoutes {
+			routed := Route{
+				Domains:  route.Domains,
+				Path:     pathRoute.Path,
+				Service:  pathRoute.Service,
+				Upstream: "http://" + pathRoute.Upstream + pathRoute.Path,
+				SSL:      route.SSL,
+				Source:   configFile,
+			}
+			if pathRoute.StripPath {
+				routed.Upstream = "http://" + pathRoute.Upstream + "/"
+			}
+			if line, exists := lines[pathRoute.Service]; exists {
+				routed.Source = fmt.Sprintf("%s:%d", configFile, line)
+			}
+			routes = append(routes, routed)
+		}
+	}
+	if hasDebugProxy(config) {
+		routes = append(routes, Route{
+			Domains:  []string{debugProxyDomain},
+			Service:  debugProxyServiceName,
+			Upstream: fmt.Sprintf("http://%s:%d", debugProxyServiceName, debugProxyUIPort),
+			SSL:      "-",
+			Source:   configFile,
+		})
+	}
+	for _, dashboard := range getQueueDashboards(config) {
+		route := Route{
+			Domains:  append([]string{dashboard.Domain}, dashboard.Aliases...),
+			Service:  dashboard.Service,
+			Upstream: dashboard.Upstream,
+			SSL:      "-",
+			Source:   configFile,
+		}
+		// Horizon is served by the vhost of its app
+		if dashboard.Host != "" {
+			route.Upstream = fmt.Sprintf("vhost %s%s", dashboard.Host, dashboard.Index)
+		}
+		if line, exists := lines[dashboard.Service]; exists {
+			route.Source = fmt.Sprintf("%s:%d", configFile, line)
+		}
+		routes = append(routes, route)
+	}
+	for _, minio := range getMinIORoutes(config) {
+		routes = append(routes, Route{
+			Domains:  append([]string{minio.Domain}, minio.Aliases...),
+			Service:  minio.Service,
+			Upstream: minio.Upstream,
+			SSL:      "-",
+			Source:   configFile,
+		}, Route{
+			Domains:  append([]string{minio.ConsoleDomain}, minio.ConsoleAliases...),
+			Service:  minio.Service,
+			Upstream: minio.ConsoleUpstream,
+			SSL:      "-",
+			Source:   configFile,
+		})
+	}
+	return routes
+}
+
+// findRoute returns the route nginx picks for a host and path, the one with the longest
+// matching path prefix, or nil when the default server drops the request
+func findRoute(routes []Route, host, path string) *Route {
+	host = strings.ToLower(host)
+	var found *Route
+	for i := range routes {
+		if !containsString(routes[i].Domains, host) || !strings.HasPrefix(path+"/", routes[i].Path) {
+			continue
+		}
+		if found == nil || len(routes[i].Path) > len(found.Path) {
+			found = &routes[i]
+		}
+	}
+	return found
+}
+
+// printRoutes prints the routing table of the proxy
+func printRoutes(out io.Writer, routes []Route) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DOMAIN\tUPSTREAM\tSSL\tSOURCE")
+	for _, route := range routes {
+		domains := route.Domains
+		if route.Path != "" {
+			domains = nil
+			for _, domain := range route.Domains {
+				domains = append(domains, domain+route.Path)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", strings.Join(domains, ", "), route.Upstream, route.SSL, route.Source)
+	}
+	w.Flush()
+}
+
+// parseRouteTarget parses the argument of fleet route test, like shop.test/api or
+// https://shop.test/api
+func parseRouteTarget(target string) (string, error) {
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil || req.URL.Hostname() == "" {
+		return "", fmt.Errorf("invalid target %q, use <domain>/<path>", target)
+	}
+	return req.URL.String(), nil
+}
+
+// testRoute requests a URL with a client connecting to the proxy, whatever its domain
+// resolves to, and prints the route nginx picks and the response
+func testRoute(ctx context.Context, client *http.Client, routes []Route, target string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "fleet-route/"+version)
+
+	host := req.URL.Hostname()
+	if route := findRoute(routes, host, req.URL.Path); route != nil {
+		outputf("Route:    %s -> %s (service %s, %s)\n", host, route.Upstream, route.Service, route.Source)
+	} else {
+		outputf("Route:    %s has no vhost, the proxy's default server closes the connection\n", host)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("GET %s: %s", target, describeCheckError(err))
+	}
+	defer resp.Body.Close()
+
+	outputf("Response: %s in %s\n", resp.Status, time.Since(start).Round(time.Millisecond))
+	if location := resp.Header.Get("Location"); location != "" {
+		outputf("Location: %s\n", location)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		infoln("   The vhost matched, but the service behind it isn't answering. Run 'fleet logs' for the service")
+	case http.StatusNotFound:
+		infoln("   The service answered 404, the proxy routed the request")
+	}
+	return nil
+}
+
+// loadRouteConfig loads the config of fleet route and applies the mode the project runs in
+func loadRouteConfig(fs *flag.FlagSet, args []string) (*Config, string, []string) {
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	noPrivileged := fs.Bool("no-privileged", false, "The project was started with --no-privileged")
+
+	rest := parseFlagsAndArgs(fs, args)
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	config.Unprivileged = isUnprivileged(*noPrivileged)
+	if isCloud(false) {
+		log.Fatalf("❌ Cloud IDEs reach services through forwarded ports, there is no proxy to route")
+	}
+	return config, *configFile, rest
+}
+
+func handleRoute() {
+	args := os.Args[2:]
+	subcommand := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcommand = args[0]
+		args = args[1:]
+	}
+
+	switch subcommand {
+	case "list", "ls":
+		config, configFile, _ := loadRouteConfig(newCommandFlagSet("route", flag.ExitOnError), args)
+		routes := getRoutes(config, configFile)
+		if len(routes) == 0 {
+			infoln("No service has a domain or port, so the proxy has no routes")
+			return
+		}
+		printRoutes(os.Stdout, routes)
+	case "test":
+		config, configFile, targets := loadRouteConfig(newCommandFlagSet("route test", flag.ExitOnError), args)
+		if len(targets) != 1 {
+			log.Fatalf("❌ Usage: fleet route test <domain>/<path>")
+		}
+		target, err := parseRouteTarget(targets[0])
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		ctx, cancel := context.WithTimeout(rootContext, routeTestTimeout)
+		defer cancel()
+		client := newCheckClient(func(host, port string) string {
+			return getProxyListenAddress(config, port)
+		})
+		if err := testRoute(ctx, client, getRoutes(config, configFile), target); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	case "help":
+		printRouteUsage()
+	default:
+		fmt.Printf("Unknown route command: %s\n\n", subcommand)
+		printRouteUsage()
+		os.Exit(1)
+	}
+}
+
+func printRouteUsage() {
+	fmt.Println("Fleet route - Inspect how the proxy routes domains to services")
+	fmt.Println("\nUsage: fleet route [command] [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  list                    List every domain, its upstream, SSL and config line (default)")
+	fmt.Println("  test <domain>/<path>    Request a URL through the proxy and show the route it takes")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file       Specify config file (default: fleet.toml)")
+	fmt.Println("  --no-privileged  The project runs without privileges, on ports 8080/8443")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet route")
+	fmt.Println("  fleet route test shop.test/api/health")
+}
@@ -0,0 +1,138 @@
+package fleetcli
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RouteTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *RouteTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *RouteTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *RouteTestSuite) TestFindServiceLines() {
+	testCases := []struct {
+		file    string
+		content string
+		want    map[string]int
+	}{
+		{"fleet.toml", `project = "shop"
+
+[[services]]
+name = "web"  # The storefront
+image = "nginx:alpine"
+
+[[services.init]]
+name = "migrate"
+
+[[services]]
+name = "api"
+`, map[string]int{"web": 4, "api": 11}},
+		{"fleet.yml", `project: shop
+services:
+  - name: web
+    image: nginx:alpine
+  - name: "api"
+`, map[string]int{"web": 3, "api": 5}},
+		{"fleet.json", `{
+  "services": [
+    {"name": "web", "port": 80},
+    {
+      "name": "api"
+    }
+  ]
+}
+`, map[string]int{"web": 3, "api": 5}},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.file, func() {
+			suite.Require().NoError(os.WriteFile(tc.file, []byte(tc.content), 0644))
+			suite.Equal(tc.want, findServiceLines(tc.file))
+		})
+	}
+
+	suite.Empty(findServiceLines("missing.toml"))
+}
+
+func (suite *RouteTestSuite) TestGetRoutes() {
+	suite.Require().NoError(os.WriteFile("fleet.toml", []byte("[[services]]\nname = \"web\"\n\n[[services]]\nname = \"api\"\n"), 0644))
+	config := &Config{Unprivileged: true, Services: []Service{
+		{Name: "web", Image: "nginx:alpine", Runtime: "php:8.3", Domain: "shop.test", SSL: true},
+		{Name: "api", Image: "node:20", Port: 3000},
+		{Name: "db", Image: "postgres:16"},
+	}}
+
+	routes := getRoutes(config, "fleet.toml")
+	suite.Require().Len(routes, 2)
+	suite.Equal(Route{
+		Domains:  []string{"shop.test", "web.localhost"},
+		Service:  "web",
+		Upstream: "fastcgi://web:9000",
+		SSL:      "https:443",
+		Source:   "fleet.toml:2",
+	}, routes[0])
+	suite.Equal("http://api:3000", routes[1].Upstream)
+	suite.Equal("fleet.toml:5", routes[1].Source)
+
+	suite.Equal("web", findRoute(routes, "Web.localhost", "/").Service)
+	suite.Nil(findRoute(routes, "blog.test", "/"))
+
+	var out bytes.Buffer
+	printRoutes(&out, routes)
+	suite.Contains(out.String(), "DOMAIN")
+	suite.Contains(out.String(), "api.test")
+	suite.Contains(out.String(), "fleet.toml:5")
+}
+
+func (suite *RouteTestSuite) TestParseRouteTarget() {
+	target, err := parseRouteTarget("shop.test/api/health?full=1")
+	suite.Require().NoError(err)
+	suite.Equal("http://shop.test/api/health?full=1", target)
+
+	target, err = parseRouteTarget("https://shop.test")
+	suite.Require().NoError(err)
+	suite.Equal("https://shop.test", target)
+
+	_, err = parseRouteTarget("/api")
+	suite.Error(err)
+}
+
+func (suite *RouteTestSuite) TestTestRoute() {
+	var host string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := newCheckClient(func(string, string) string { return server.Listener.Addr().String() })
+	routes := []Route{{Domains: []string{"shop.test"}, Service: "web", Upstream: "http://web:3000"}}
+
+	suite.NoError(testRoute(context.Background(), client, routes, "http://shop.test/cart"))
+	suite.Equal("shop.test", host)
+}
+
+func TestRouteSuite(t *testing.T) {
+	suite.Run(t, new(RouteTestSuite))
+}
@@ -0,0 +1,144 @@
+package fleetcli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SupportedRubyFrameworks lists all supported Ruby frameworks
+var SupportedRubyFrameworks = []string{
+	"rails",
+	"sinatra",
+}
+
+// RubyConfigurator manages Ruby service configuration
+type RubyConfigurator struct {
+	// Framework detection
+	frameworkDetectors map[string]FrameworkDetector
+}
+
+// NewRubyConfigurator creates a new Ruby configurator
+func NewRubyConfigurator() *RubyConfigurator {
+	rc := &RubyConfigurator{
+		frameworkDetectors: make(map[string]FrameworkDetector),
+	}
+	rc.registerFrameworkDetectors()
+	return rc
+}
+
+// registerFrameworkDetectors registers all framework detection functions
+func (rc *RubyConfigurator) registerFrameworkDetectors() {
+	rc.frameworkDetectors["rails"] = func(folder string) bool {
+		gems := getGemfileGems(folder)
+		return gems["rails"] || gems["railties"]
+	}
+	rc.frameworkDetectors["sinatra"] = func(folder string) bool {
+		return getGemfileGems(folder)["sinatra"]
+	}
+}
+
+// DetectFramework detects the Ruby framework in the given folder
+func (rc *RubyConfigurator) DetectFramework(folder string) string {
+	if folder == "" {
+		return ""
+	}
+
+	// Rails apps often mount Sinatra apps, like the Sidekiq UI, so Rails goes first
+	for _, framework := range SupportedRubyFrameworks {
+		if detector, exists := rc.frameworkDetectors[framework]; exists && detector(folder) {
+			return framework
+		}
+	}
+	return ""
+}
+
+// ParseRuntime parses the Ruby runtime string and returns language and version
+func (rc *RubyConfigurator) ParseRuntime(runtime string) (string, string) {
+	return parseRubyRuntime(runtime)
+}
+
+// GetRubyImage returns the appropriate Ruby image for the version
+func (rc *RubyConfigurator) GetRubyImage(version string) string {
+	return getRubyImage(version)
+}
+
+// ConfigureRubyService sets up the container of a Ruby service. Variables and a command
+// set in the config win over the defaults.
+func (rc *RubyConfigurator) ConfigureRubyService(service *DockerService, svc *Service) {
+	_, version := rc.ParseRuntime(svc.Runtime)
+	service.Image = rc.GetRubyImage(version)
+	service.WorkingDir = "/app"
+
+	framework := strings.ToLower(svc.Framework)
+	if framework == "" {
+		framework = rc.DetectFramework(svc.hostPath(svc.Folder))
+	}
+	port := getRubyPort(svc)
+
+	if service.Environment == nil {
+		service.Environment = make(map[string]string)
+	}
+	for key, value := range rc.getEnvironment(svc, framework, port) {
+		if _, set := service.Environment[key]; !set {
+			service.Environment[key] = value
+		}
+	}
+
+	if svc.Command == "" {
+		service.Command = rc.getStartScript(svc, framework, port)
+	}
+}
+
+// getEnvironment returns the variables a Ruby app needs to run in development
+func (rc *RubyConfigurator) getEnvironment(svc *Service, framework string, port int) map[string]string {
+	env := map[string]string{
+		"RACK_ENV": "development",
+		"PORT":     fmt.Sprintf("%d", port),
+	}
+	if framework == "rails" {
+		env["RAILS_ENV"] = "development"
+		env["RAILS_LOG_TO_STDOUT"] = "true"
+		// Rails only answers to localhost in development unless the domain is allowed
+		if domain := getDomainForService(svc); isWildcardDomain(domain) {
+			// A leading dot allows the domain and all of its subdomains
+			env["RAILS_DEVELOPMENT_HOSTS"] = "." + getWildcardBase(domain)
+		} else if domain != "" {
+			env["RAILS_DEVELOPMENT_HOSTS"] = domain
+		}
+	}
+	return env
+}
+
+// getStartScript installs the gems of the Gemfile, prepares the database of Rails apps
+// and starts the app
+func (rc *RubyConfigurator) getStartScript(svc *Service, framework string, port int) string {
+	steps := []string{"bundle check || bundle install"}
+	if framework == "rails" {
+		// A container that was killed leaves its pid file behind, and puma won't start
+		steps = append(steps, "rm -f tmp/pids/server.pid")
+		if svc.Database != "" {
+			steps = append(steps, "bundle exec rails db:prepare")
+		}
+	}
+	steps = append(steps, "exec "+rc.getStartCommand(svc.hostPath(svc.Folder), framework, port))
+	return fmt.Sprintf(`sh -c "%s"`, strings.Join(steps, " && "))
+}
+
+// getStartCommand returns the command serving the app with puma, or rackup for Rack apps
+// without puma in their Gemfile
+func (rc *RubyConfigurator) getStartCommand(folder, framework string, port int) string {
+	if framework == "rails" || getGemfileGems(folder)["puma"] {
+		return fmt.Sprintf("bundle exec puma -b tcp://0.0.0.0:%d", port)
+	}
+	return fmt.Sprintf("bundle exec rackup --host 0.0.0.0 --port %d", port)
+}
+
+// GetSupportedVersions returns all supported Ruby versions
+func (rc *RubyConfigurator) GetSupportedVersions() []string {
+	return versionData.Ruby.getVersions()
+}
+
+// GetSupportedFrameworks returns all supported Ruby frameworks
+func (rc *RubyConfigurator) GetSupportedFrameworks() []string {
+	return SupportedRubyFrameworks
+}
@@ -0,0 +1,125 @@
+package fleetcli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+)
+
+// RunOptions are the options of a one-off container
+type RunOptions struct {
+	Remove bool   // Remove the container once the command exits
+	NoDeps bool   // Don't start the services the service depends on
+	User   string // User the command runs as
+}
+
+// getRunArgs returns the docker arguments running a command in a one-off container of a
+// service, with its image, environment, volumes and network. Without a command the
+// service runs its own command once.
+func getRunArgs(files ComposeFiles, target string, options RunOptions, tty bool, command []string) []string {
+	args := composeArgs(files, "run")
+	if options.Remove {
+		args = append(args, "--rm")
+	}
+	if options.NoDeps {
+		args = append(args, "--no-deps")
+	}
+	if !tty {
+		args = append(args, "-T")
+	}
+	if options.User != "" {
+		args = append(args, "--user", options.User)
+	}
+	args = append(args, target)
+	return append(args, command...)
+}
+
+func printRunUsage() {
+	fmt.Println("Fleet run - Run a command in a one-off container of a service")
+	fmt.Println("\nUsage: fleet run [options] <service> [command...]")
+	fmt.Println("\nThe container has the image, environment, volumes and network of the service,")
+	fmt.Println("which doesn't need to be running: the compose files are generated from the")
+	fmt.Println("config first, and the databases and caches of the service are started when")
+	fmt.Println("they aren't. Use it to run migrations or seeds in CI before 'fleet up'.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --rm        Remove the container once the command exits")
+	fmt.Println("  --no-deps   Don't start the services the service depends on")
+	fmt.Println("  -u, --user  Run as this user (name or uid[:gid])")
+	fmt.Println("  -T          Don't allocate a TTY, the default when not on a terminal")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet run --rm shop php artisan migrate --force  # Migrate before the stack starts")
+	fmt.Println("  fleet run --rm api npm run seed")
+	fmt.Println("\nRun 'fleet exec help' to run a command in a running service instead")
+}
+
+func handleRun() {
+	if len(os.Args) > 2 && os.Args[2] == "help" {
+		printRunUsage()
+		return
+	}
+
+	fs := newCommandFlagSet("run", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	remove := fs.Bool("rm", false, "Remove the container once the command exits")
+	noDeps := fs.Bool("no-deps", false, "Don't start the services the service depends on")
+	user := fs.String("u", "", "User")
+	userLong := fs.String("user", "", "User")
+	noTTY := fs.Bool("T", false, "Don't allocate a TTY")
+	lockOptions := addProjectLockFlags(fs)
+	fs.Usage = printRunUsage
+
+	// Options come before the service, everything after it is the command
+	fs.Parse(os.Args[2:])
+	if fs.NArg() == 0 {
+		printRunUsage()
+		os.Exit(1)
+	}
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+	if *userLong != "" {
+		*user = *userLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	config.Unprivileged = isUnprivileged(false)
+	config.Cloud = isCloud(false)
+	warnUnknownAddonVersions(config)
+
+	compose := generateDockerCompose(config)
+	target, err := resolveExecService(config, compose, fs.Arg(0))
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	// The service may not be up, so the compose files are written from the config
+	release := lockProject("run", lockOptions)
+	if err := ensureFleetGitignore(); err != nil {
+		warnf("⚠️  Warning: %v\n", err)
+	}
+	files, err := writeComposeFiles(config, compose)
+	release()
+	if err != nil {
+		log.Fatalf("❌ Error writing docker-compose.yml: %v", err)
+	}
+
+	options := RunOptions{Remove: *remove, NoDeps: *noDeps, User: *user}
+	tty := !*noTTY && isInteractiveTerminal() && isOutputTerminal()
+	if err := runDocker(getRunArgs(files, target, options, tty, fs.Args()[1:])); err != nil {
+		// The exit code of the command is the exit code of fleet run, so CI fails with it
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		log.Fatalf("❌ Error running the command in %s: %v", target, err)
+	}
+}
@@ -0,0 +1,51 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RunCommandTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *RunCommandTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *RunCommandTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *RunCommandTestSuite) TestGetRunArgs() {
+	files := ComposeFiles{ProjectDir: ".fleet", Files: []string{".fleet/docker-compose.yml"}}
+
+	args := getRunArgs(files, "shop-php", RunOptions{Remove: true}, true, []string{"php", "artisan", "migrate", "--force"})
+	suite.Equal(append(composeArgs(files, "run", "--rm", "shop-php"), "php", "artisan", "migrate", "--force"), args)
+
+	args = getRunArgs(files, "api", RunOptions{NoDeps: true, User: "node"}, false, nil)
+	suite.Equal(composeArgs(files, "run", "--no-deps", "-T", "--user", "node", "api"), args)
+}
+
+func (suite *RunCommandTestSuite) TestRunsTheAppContainer() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "shop", Database: "mysql:8.0"},
+	}}
+
+	target, err := resolveExecService(config, generateDockerCompose(config), "shop")
+	suite.Require().NoError(err)
+	suite.Equal("shop-php", target, "Commands of PHP services run in their PHP container")
+}
+
+func TestRunCommandSuite(t *testing.T) {
+	suite.Run(t, new(RunCommandTestSuite))
+}
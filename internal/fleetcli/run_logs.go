@@ -0,0 +1,239 @@
+package fleetcli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runLogsDir holds the logs of container runs that ended, by service
+const runLogsDir = ".fleet/logs/runs"
+
+// maxRunLogsPerService is how many runs of a service are kept, older ones are removed
+const maxRunLogsPerService = 20
+
+// runLogTimeFormat names run logs after their start time, so they sort by it
+const runLogTimeFormat = "20060102T150405.000Z"
+
+// ContainerRun is one run of a container, from its start until it exited
+type ContainerRun struct {
+	Container  string
+	Service    string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	ExitCode   int
+}
+
+// containerEvent is a line of docker events --format '{{json .}}'
+type containerEvent struct {
+	Action   string `json:"Action"`
+	TimeNano int64  `json:"timeNano"`
+	Actor    struct {
+		Attributes map[string]string `json:"Attributes"`
+	} `json:"Actor"`
+}
+
+// parseContainerEvent parses a start or die event of a compose container
+func parseContainerEvent(line string) (action string, run ContainerRun, ok bool) {
+	var event containerEvent
+	if err := json.Unmarshal([]byte(line), &event); err != nil {
+		return "", ContainerRun{}, false
+	}
+	attributes := event.Actor.Attributes
+	if attributes["name"] == "" || attributes["com.docker.compose.service"] == "" {
+		return "", ContainerRun{}, false
+	}
+
+	run = ContainerRun{Container: attributes["name"], Service: attributes["com.docker.compose.service"]}
+	at := time.Unix(0, event.TimeNano).UTC()
+	switch event.Action {
+	case "start":
+		run.StartedAt = at
+	case "die":
+		run.FinishedAt = at
+		run.ExitCode, _ = strconv.Atoi(attributes["exitCode"])
+	default:
+		return "", ContainerRun{}, false
+	}
+	return event.Action, run, true
+}
+
+// getRunLogPath returns the file the logs of a run are saved to
+func getRunLogPath(run ContainerRun) string {
+	started := run.StartedAt
+	if started.IsZero() {
+		started = run.FinishedAt
+	}
+	return filepath.Join(runLogsDir, run.Service, fmt.Sprintf("%s_%s.log", started.UTC().Format(runLogTimeFormat), run.Container))
+}
+
+// captureContainerLogs returns the output a container printed between two times, zero
+// times leaving the range open
+var captureContainerLogs = func(container string, since, until time.Time) ([]byte, error) {
+	args := []string{"logs", "--timestamps"}
+	if !since.IsZero() {
+		args = append(args, "--since", since.Format(time.RFC3339Nano))
+	}
+	if !until.IsZero() {
+		args = append(args, "--until", until.Format(time.RFC3339Nano))
+	}
+	// The container's stderr is printed on docker's stderr
+	output, err := newCommand("docker", append(args, container)...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the logs of %s: %w", container, err)
+	}
+	return output, nil
+}
+
+// inspectContainerStart returns when a container last started
+var inspectContainerStart = func(container string) (time.Time, error) {
+	output, err := newCommand("docker", "inspect", "--format", "{{.State.StartedAt}}", container).Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to inspect %s: %w", container, err)
+	}
+	return time.Parse(time.RFC3339Nano, strings.TrimSpace(string(output)))
+}
+
+// saveRunLogs saves the logs of a run that ended and removes the oldest runs of the
+// service beyond maxRunLogsPerService
+func saveRunLogs(run ContainerRun) (string, error) {
+	output, err := captureContainerLogs(run.Container, run.StartedAt, run.FinishedAt)
+	if err != nil {
+		return "", err
+	}
+
+	path := getRunLogPath(run)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	started := "before recording started"
+	if !run.StartedAt.IsZero() {
+		started = run.StartedAt.Format(time.RFC3339)
+	}
+	header := fmt.Sprintf("# %s (%s) started %s, exited with code %d at %s\n",
+		run.Container, run.Service, started, run.ExitCode, run.FinishedAt.Format(time.RFC3339))
+	if err := os.WriteFile(path, append([]byte(header), output...), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	runs := getRecordedRuns(run.Service)
+	for len(runs) > maxRunLogsPerService {
+		os.Remove(runs[0])
+		runs = runs[1:]
+	}
+	return path, nil
+}
+
+// getRecordedRuns returns the saved run logs of a service, oldest first
+func getRecordedRuns(service string) []string {
+	runs, _ := filepath.Glob(filepath.Join(runLogsDir, service, "*.log"))
+	sort.Strings(runs)
+	return runs
+}
+
+// recordRunLogs saves the logs of every run of the services that ends, until Fleet is
+// interrupted. Docker keeps the output of restarted containers in one log, and drops it
+// when a container is recreated, so each run is cut at its start and die events.
+func recordRunLogs(services []string) error {
+	cmd := newCommand("docker", "events",
+		"--filter", "type=container",
+		"--filter", "event=start",
+		"--filter", "event=die",
+		"--filter", "label=com.docker.compose.project="+composeProjectName,
+		"--format", "{{json .}}")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to follow docker events: %w", err)
+	}
+
+	started := make(map[string]time.Time)
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		action, run, ok := parseContainerEvent(scanner.Text())
+		if !ok || !containsString(services, run.Service) {
+			continue
+		}
+		if action == "start" {
+			started[run.Container] = run.StartedAt
+			continue
+		}
+
+		// Containers already running when recording started
+		if start, known := started[run.Container]; known {
+			run.StartedAt = start
+		} else if start, err := inspectContainerStart(run.Container); err == nil && start.Before(run.FinishedAt) {
+			run.StartedAt = start
+		}
+		delete(started, run.Container)
+
+		path, err := saveRunLogs(run)
+		if err != nil {
+			warnf("⚠️  Warning: %v\n", err)
+			continue
+		}
+		infof("💥 %s exited with code %d, logs saved to %s\n", run.Container, run.ExitCode, path)
+	}
+
+	if err := cmd.Wait(); err != nil && rootContext.Err() == nil {
+		return fmt.Errorf("docker events stopped: %w", err)
+	}
+	return nil
+}
+
+// printPreviousRunLogs prints the logs of the last recorded run of a service. Without
+// one it prints what the running containers printed before their last restart.
+func printPreviousRunLogs(config *Config, service string, tail int) error {
+	if _, exists := generateDockerCompose(config).Services[service]; !exists {
+		return fmt.Errorf("service %s not found in the config", service)
+	}
+
+	if runs := getRecordedRuns(service); len(runs) > 0 {
+		content, err := os.ReadFile(runs[len(runs)-1])
+		if err != nil {
+			return err
+		}
+		outputln(tailLines(string(content), tail))
+		return nil
+	}
+
+	entries, err := getComposeServiceStates(getComposeFiles(config), service)
+	if err != nil {
+		return err
+	}
+	printed := false
+	for _, entry := range entries {
+		start, err := inspectContainerStart(entry.Name)
+		if err != nil {
+			continue
+		}
+		output, err := captureContainerLogs(entry.Name, time.Time{}, start)
+		if err != nil || len(strings.TrimSpace(string(output))) == 0 {
+			continue
+		}
+		outputf("# %s before it started %s\n", entry.Name, start.Format(time.RFC3339))
+		outputln(tailLines(string(output), tail))
+		printed = true
+	}
+	if !printed {
+		return fmt.Errorf("no previous run of %s, record runs with 'fleet logs --record'", service)
+	}
+	return nil
+}
+
+// tailLines returns the last lines of a text, all of them for a negative count
+func tailLines(text string, count int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if count >= 0 && len(lines) > count {
+		lines = lines[len(lines)-count:]
+	}
+	return strings.Join(lines, "\n")
+}
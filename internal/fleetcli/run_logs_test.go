@@ -0,0 +1,91 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RunLogsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *RunLogsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *RunLogsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *RunLogsTestSuite) TestParseContainerEvent() {
+	line := `{"Type":"container","Action":"die","timeNano":1700000000500000000,` +
+		`"Actor":{"ID":"abc","Attributes":{"name":"fleet-web-1","com.docker.compose.service":"web","exitCode":"137"}}}`
+	action, run, ok := parseContainerEvent(line)
+	suite.Require().True(ok)
+	suite.Equal("die", action)
+	suite.Equal(ContainerRun{Container: "fleet-web-1", Service: "web", FinishedAt: time.Unix(1700000000, 500000000).UTC(), ExitCode: 137}, run)
+
+	action, run, ok = parseContainerEvent(`{"Action":"start","timeNano":1700000000000000000,"Actor":{"Attributes":{"name":"fleet-web-1","com.docker.compose.service":"web"}}}`)
+	suite.Require().True(ok)
+	suite.Equal("start", action)
+	suite.Equal(time.Unix(1700000000, 0).UTC(), run.StartedAt)
+
+	_, _, ok = parseContainerEvent(`{"Action":"die","Actor":{"Attributes":{"name":"dns"}}}`)
+	suite.False(ok, "Containers outside of compose are ignored")
+	_, _, ok = parseContainerEvent("not json")
+	suite.False(ok)
+}
+
+func (suite *RunLogsTestSuite) TestSaveRunLogs() {
+	original := captureContainerLogs
+	defer func() { captureContainerLogs = original }()
+	var since, until time.Time
+	captureContainerLogs = func(container string, s, u time.Time) ([]byte, error) {
+		since, until = s, u
+		return []byte("panic: boom\n"), nil
+	}
+
+	started := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	run := ContainerRun{Container: "fleet-web-1", Service: "web", StartedAt: started, FinishedAt: started.Add(5 * time.Second), ExitCode: 2}
+	path, err := saveRunLogs(run)
+	suite.Require().NoError(err)
+	suite.Equal(filepath.Join(runLogsDir, "web", "20261016T093000.000Z_fleet-web-1.log"), path)
+	suite.Equal(run.StartedAt, since)
+	suite.Equal(run.FinishedAt, until)
+
+	content, err := os.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.Equal("# fleet-web-1 (web) started 2026-10-16T09:30:00Z, exited with code 2 at 2026-10-16T09:30:05Z\npanic: boom\n", string(content))
+
+	for i := 1; i <= maxRunLogsPerService; i++ {
+		run.StartedAt = started.Add(time.Duration(i) * time.Minute)
+		_, err := saveRunLogs(run)
+		suite.Require().NoError(err)
+	}
+	runs := getRecordedRuns("web")
+	suite.Len(runs, maxRunLogsPerService)
+	suite.NotContains(runs, path, "The oldest run is removed")
+}
+
+func (suite *RunLogsTestSuite) TestTailLines() {
+	text := "one\ntwo\nthree\n"
+	suite.Equal("two\nthree", tailLines(text, 2))
+	suite.Equal("one\ntwo\nthree", tailLines(text, 10))
+	suite.Equal("one\ntwo\nthree", tailLines(text, -1))
+	suite.Empty(tailLines(text, 0))
+}
+
+func TestRunLogsSuite(t *testing.T) {
+	suite.Run(t, new(RunLogsTestSuite))
+}
@@ -0,0 +1,149 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// runtimeClassPattern matches the name of a runtime registered with the Docker daemon
+var runtimeClassPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// validateRuntimeClass checks the runtime_class of a service
+func validateRuntimeClass(svc *Service) error {
+	if svc.RuntimeClass != "" && !runtimeClassPattern.MatchString(svc.RuntimeClass) {
+		return fmt.Errorf("service %s: invalid runtime_class %q", svc.Name, svc.RuntimeClass)
+	}
+	return nil
+}
+
+// configureRuntimeClass runs a service, and the container running its code when that is
+// a sidecar like PHP-FPM, with the OCI runtime of runtime_class
+func configureRuntimeClass(compose *DockerCompose, svc *Service) {
+	if svc.RuntimeClass == "" {
+		return
+	}
+
+	for _, name := range []string{svc.Name, getAppServiceName(svc)} {
+		if service, exists := compose.Services[name]; exists {
+			service.Runtime = svc.RuntimeClass
+			compose.Services[name] = service
+		}
+	}
+}
+
+// getRuntimeClasses maps each runtime_class of a config to the services using it
+func getRuntimeClasses(config *Config) map[string][]string {
+	classes := make(map[string][]string)
+	for _, svc := range config.Services {
+		if svc.RuntimeClass != "" {
+			classes[svc.RuntimeClass] = append(classes[svc.RuntimeClass], svc.Name)
+		}
+	}
+	return classes
+}
+
+// parseDockerRuntimes returns the runtime names of the JSON printed by
+// docker info --format '{{json .Runtimes}}'
+func parseDockerRuntimes(output []byte) ([]string, error) {
+	var runtimes map[string]json.RawMessage
+	if err := json.Unmarshal(output, &runtimes); err != nil {
+		return nil, fmt.Errorf("failed to parse Docker runtimes: %w", err)
+	}
+
+	names := make([]string, 0, len(runtimes))
+	for name := range runtimes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// queryDockerRuntimes returns the runtimes registered with the Docker daemon
+var queryDockerRuntimes = func() ([]string, error) {
+	output, err := newCommand("docker", "info", "--format", "{{json .Runtimes}}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Docker runtimes: %w", err)
+	}
+	return parseDockerRuntimes(output)
+}
+
+// findMissingRuntimeClasses returns the runtime classes of a config the host doesn't have,
+// with the services using them
+func findMissingRuntimeClasses(config *Config, available []string) map[string][]string {
+	missing := make(map[string][]string)
+	for class, services := range getRuntimeClasses(config) {
+		if !containsString(available, class) {
+			missing[class] = services
+		}
+	}
+	return missing
+}
+
+// checkRuntimeClasses fails when a service needs a runtime the Docker daemon doesn't have.
+// It stays quiet when Docker can't be queried, since starting will report that anyway.
+func checkRuntimeClasses(config *Config) error {
+	if len(getRuntimeClasses(config)) == 0 {
+		return nil
+	}
+	available, err := queryDockerRuntimes()
+	if err != nil {
+		return nil
+	}
+
+	missing := findMissingRuntimeClasses(config, available)
+	if len(missing) == 0 {
+		return nil
+	}
+	classes := make([]string, 0, len(missing))
+	for class := range missing {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+
+	var problems []string
+	for _, class := range classes {
+		problems = append(problems, fmt.Sprintf("%s (used by %s)", class, strings.Join(missing[class], ", ")))
+	}
+	return fmt.Errorf("runtime_class %s not registered with Docker (available: %s), see 'fleet doctor'",
+		strings.Join(problems, ", "), strings.Join(available, ", "))
+}
+
+// printRuntimeClassChecks prints whether the runtimes of a config are available, for
+// fleet doctor. It returns false when one is missing.
+func printRuntimeClassChecks(config *Config) bool {
+	classes := getRuntimeClasses(config)
+	if len(classes) == 0 {
+		return true
+	}
+
+	available, err := queryDockerRuntimes()
+	if err != nil {
+		outputf("%s Runtimes: %v\n", emojiOr("❌", "--"), err)
+		return false
+	}
+
+	names := make([]string, 0, len(classes))
+	for class := range classes {
+		names = append(names, class)
+	}
+	sort.Strings(names)
+
+	ok := true
+	for _, class := range names {
+		services := strings.Join(classes[class], ", ")
+		if containsString(available, class) {
+			outputf("%s Runtime %s: available (%s)\n", emojiOr("✅", "ok"), class, services)
+			continue
+		}
+		outputf("%s Runtime %s: not registered with Docker (%s)\n", emojiOr("❌", "--"), class, services)
+		infof("   Available runtimes: %s\n", strings.Join(available, ", "))
+		if class == "runsc" {
+			infoln("   Install gVisor: https://gvisor.dev/docs/user_guide/install/")
+		}
+		ok = false
+	}
+	return ok
+}
@@ -0,0 +1,73 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RuntimeClassTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *RuntimeClassTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *RuntimeClassTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *RuntimeClassTestSuite) TestValidateRuntimeClass() {
+	suite.NoError(validateRuntimeClass(&Service{Name: "web"}))
+	suite.NoError(validateRuntimeClass(&Service{Name: "web", RuntimeClass: "runsc"}))
+	suite.NoError(validateRuntimeClass(&Service{Name: "web", RuntimeClass: "io.containerd.runc.v2"}))
+	suite.ErrorContains(validateRuntimeClass(&Service{Name: "web", RuntimeClass: "runsc --debug"}), "invalid runtime_class")
+}
+
+func (suite *RuntimeClassTestSuite) TestGeneratedCompose() {
+	config := &Config{
+		Project: "sandbox",
+		Services: []Service{
+			{Name: "scraper", Image: "vendor/scraper:latest", RuntimeClass: "runsc"},
+			{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "./shop", RuntimeClass: "runsc"},
+			{Name: "api", Image: "node:20"},
+		},
+	}
+	suite.Require().NoError(Validate(config))
+
+	compose := generateDockerCompose(config)
+	suite.Equal("runsc", compose.Services["scraper"].Runtime)
+	suite.Equal("runsc", compose.Services["shop"].Runtime)
+	suite.Equal("runsc", compose.Services["shop-php"].Runtime, "The container running the PHP code is sandboxed too")
+	suite.Empty(compose.Services["api"].Runtime)
+}
+
+func (suite *RuntimeClassTestSuite) TestCheckRuntimeClasses() {
+	original := queryDockerRuntimes
+	defer func() { queryDockerRuntimes = original }()
+
+	runtimes, err := parseDockerRuntimes([]byte(`{"io.containerd.runc.v2":{"path":"runc"},"runc":{"path":"runc"}}`))
+	suite.Require().NoError(err)
+	suite.Equal([]string{"io.containerd.runc.v2", "runc"}, runtimes)
+	queryDockerRuntimes = func() ([]string, error) { return runtimes, nil }
+
+	config := &Config{Services: []Service{{Name: "scraper", RuntimeClass: "runsc"}, {Name: "worker", RuntimeClass: "runc"}}}
+	suite.Equal(map[string][]string{"runsc": {"scraper"}}, findMissingRuntimeClasses(config, runtimes))
+	suite.ErrorContains(checkRuntimeClasses(config), "runsc (used by scraper) not registered with Docker")
+
+	queryDockerRuntimes = func() ([]string, error) { return append(runtimes, "runsc"), nil }
+	suite.NoError(checkRuntimeClasses(config))
+}
+
+func TestRuntimeClassSuite(t *testing.T) {
+	suite.Run(t, new(RuntimeClassTestSuite))
+}
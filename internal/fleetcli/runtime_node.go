@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -13,20 +13,8 @@ type NodeVersion struct {
 	Image   string
 }
 
-// Supported Node.js LTS versions with their Docker images
-var supportedNodeVersions = map[string]string{
-	"16":       "node:16-alpine",
-	"16-alpine": "node:16-alpine",
-	"18":       "node:18-alpine",
-	"18-alpine": "node:18-alpine",
-	"20":       "node:20-alpine",
-	"20-alpine": "node:20-alpine",
-	"22":       "node:22-alpine",
-	"22-alpine": "node:22-alpine",
-	"latest":   "node:20-alpine", // Default to Node.js 20 LTS
-	"lts":      "node:20-alpine", // LTS alias
-	"default":  "node:20-alpine", // Default to Node.js 20 LTS
-}
+// Supported Node.js LTS versions with their Docker images, from config/versions.json
+var supportedNodeVersions = versionData.Node.imageMap()
 
 // parseNodeRuntime parses the runtime string and returns language and version
 // Examples: "node", "node:20", "node:18-alpine"
@@ -38,7 +26,7 @@ func parseNodeRuntime(runtime string) (string, string) {
 	parts := strings.Split(runtime, ":")
 	if len(parts) == 1 {
 		// Just "node" - use default version
-		return "node", "20"
+		return "node", versionData.Node.Default
 	}
 
 	// "node:20" or "node:18-alpine" format
@@ -48,7 +36,7 @@ func parseNodeRuntime(runtime string) (string, string) {
 // getNodeImage returns the appropriate Node.js Docker image for the version
 func getNodeImage(version string) string {
 	if version == "" {
-		version = "20"
+		version = versionData.Node.Default
 	}
 
 	if image, ok := supportedNodeVersions[version]; ok {
@@ -186,7 +174,7 @@ func getNodePort(svc *Service) int {
 	}
 
 	// Check common Node.js frameworks default ports
-	framework := detectNodeFramework(svc.Folder)
+	framework := detectNodeFramework(svc.hostPath(svc.Folder))
 	switch framework {
 	case "nextjs":
 		return 3000
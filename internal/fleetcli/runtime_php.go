@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -23,17 +23,8 @@ type PHPVersion struct {
 	Image   string
 }
 
-// Supported PHP versions with their FPM images
-var supportedPHPVersions = map[string]string{
-	"7.4":     "php:7.4-fpm-alpine",
-	"8.0":     "php:8.0-fpm-alpine",
-	"8.1":     "php:8.1-fpm-alpine",
-	"8.2":     "php:8.2-fpm-alpine",
-	"8.3":     "php:8.3-fpm-alpine",
-	"8.4":     "php:8.4-fpm-alpine",
-	"latest":  "php:8.4-fpm-alpine", // Default to 8.4
-	"default": "php:8.4-fpm-alpine", // Default to 8.4
-}
+// Supported PHP versions with their FPM images, from config/versions.json
+var supportedPHPVersions = versionData.PHP.imageMap()
 
 // parsePHPRuntime parses the runtime string and returns version
 // Examples: "php", "php:8.2", "php:7.4"
@@ -45,7 +36,7 @@ func parsePHPRuntime(runtime string) (string, string) {
 	parts := strings.Split(runtime, ":")
 	if len(parts) == 1 {
 		// Just "php" - use default version
-		return "php", "8.4"
+		return "php", versionData.PHP.Default
 	}
 
 	// "php:8.2" format
@@ -55,7 +46,7 @@ func parsePHPRuntime(runtime string) (string, string) {
 // getPHPImage returns the appropriate PHP-FPM image for the version
 func getPHPImage(version string) string {
 	if version == "" {
-		version = "8.4"
+		version = versionData.PHP.Default
 	}
 
 	if image, ok := supportedPHPVersions[version]; ok {
@@ -84,6 +75,12 @@ func addPHPFPMService(compose *DockerCompose, svc *Service, config *Config) {
 	
 	phpServiceName := fmt.Sprintf("%s-php", svc.Name)
 	
+	// Prebuilt images are built locally from a generated Dockerfile
+	if resolvePHPImageStrategy(svc) == PHPImageStrategyFleetPrebuilt {
+		_, version := configurator.ParseRuntime(svc.Runtime)
+		planPHPDockerfile(getArtifactPlan(compose), configurator.GetPHPImage(version), version)
+	}
+	
 	// Add the PHP service to compose
 	compose.Services[phpServiceName] = *phpService
 
@@ -115,50 +112,14 @@ func generateNginxPHPConfigWithVersion(serviceName, phpVersion string) string {
 
 // generateNginxPHPConfigWithService generates nginx config with specific PHP service
 func generateNginxPHPConfigWithService(phpServiceName string) string {
-	return fmt.Sprintf(`server {
-    listen 80;
-    server_name _;
-    
-    root /var/www/html;
-    index index.php index.html index.htm;
-    
-    location / {
-        try_files $uri $uri/ /index.php?$query_string;
-    }
-    
-    location ~ \.php$ {
-        try_files $uri =404;
-        fastcgi_split_path_info ^(.+\.php)(/.+)$;
-        fastcgi_pass %s:9000;
-        fastcgi_index index.php;
-        include fastcgi_params;
-        fastcgi_param SCRIPT_FILENAME $document_root$fastcgi_script_name;
-        fastcgi_param PATH_INFO $fastcgi_path_info;
-        
-        # Performance tweaks
-        fastcgi_buffer_size 128k;
-        fastcgi_buffers 256 16k;
-        fastcgi_busy_buffers_size 256k;
-    }
-    
-    # Security headers
-    add_header X-Frame-Options "SAMEORIGIN" always;
-    add_header X-Content-Type-Options "nosniff" always;
-    add_header X-XSS-Protection "1; mode=block" always;
-    
-    # Cache static files
-    location ~* \.(jpg|jpeg|gif|png|css|js|ico|xml)$ {
-        expires 30d;
-        add_header Cache-Control "public, immutable";
-    }
-    
-    # Deny access to hidden files
-    location ~ /\. {
-        deny all;
-        access_log off;
-        log_not_found off;
-    }
-}`, phpServiceName)
+	data := getPHPNginxTemplateData(strings.TrimSuffix(phpServiceName, "-php"), "", nil)
+	data.PHPService = phpServiceName
+	content, _ := getEmbeddedPHPNginxTemplate("")
+	config, err := renderPHPNginxTemplate("default", content, data)
+	if err != nil {
+		panic(err)
+	}
+	return config
 }
 
 // writeNginxPHPConfig writes the nginx configuration for PHP
@@ -168,11 +129,10 @@ func writeNginxPHPConfig(serviceName string, framework string) (string, error) {
 	return configurator.WriteNginxConfig(serviceName, framework)
 }
 
-// writeNginxPHPConfigWithVersion writes nginx config with specific PHP version
-func writeNginxPHPConfigWithVersion(serviceName, framework, _ string) (string, error) {
-	// For compatibility, just use the standard method
+// planNginxPHPConfigWithVersion plans nginx config with specific PHP version
+func planNginxPHPConfigWithVersion(plan *ArtifactPlan, svc *Service, framework, _ string) string {
 	// The version is already handled in the runtime configuration
-	return writeNginxPHPConfig(serviceName, framework)
+	return NewPHPConfigurator().PlanNginxConfig(plan, svc, framework)
 }
 
 // Helper function to write file
@@ -0,0 +1,109 @@
+package fleetcli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Supported Ruby versions with their Docker images, from config/versions.json
+var supportedRubyVersions = versionData.Ruby.imageMap()
+
+// rubyBundlePath is where the official Ruby images install gems
+const rubyBundlePath = "/usr/local/bundle"
+
+// defaultRubyPort is the port puma listens on when the service sets none
+const defaultRubyPort = 3000
+
+// gemPattern matches a gem line of a Gemfile and captures the gem's name
+var gemPattern = regexp.MustCompile(`^\s*gem\s+["']([A-Za-z0-9_.-]+)["']`)
+
+// parseRubyRuntime parses the runtime string and returns language and version
+// Examples: "ruby", "ruby:3.3", "ruby:3.2.4"
+func parseRubyRuntime(runtime string) (string, string) {
+	if runtime == "" || !strings.HasPrefix(runtime, "ruby") {
+		return "", ""
+	}
+
+	parts := strings.Split(runtime, ":")
+	if len(parts) == 1 {
+		return "ruby", versionData.Ruby.Default
+	}
+	return parts[0], parts[1]
+}
+
+// getRubyImage returns the Ruby Docker image for the version. The full images are used
+// since they have the headers native gems like pg and mysql2 compile against.
+func getRubyImage(version string) string {
+	if version == "" {
+		version = versionData.Ruby.Default
+	}
+	if image, ok := supportedRubyVersions[version]; ok {
+		return image
+	}
+
+	// Versions like "3.2.4" that aren't listed
+	if matched, _ := regexp.MatchString(`^\d+\.\d+(\.\d+)?$`, version); matched {
+		return fmt.Sprintf("ruby:%s", version)
+	}
+	return supportedRubyVersions["default"]
+}
+
+// isRubyService checks if a service runs in a Ruby container Fleet configures
+func isRubyService(svc *Service) bool {
+	return strings.HasPrefix(svc.Runtime, "ruby") && svc.Image == "" && svc.Build == ""
+}
+
+// getRubyPort returns the port puma listens on
+func getRubyPort(svc *Service) int {
+	if svc.Port > 0 {
+		return svc.Port
+	}
+	return defaultRubyPort
+}
+
+// getRubyBundleVolume returns the named volume that keeps the installed gems of a
+// service across restarts
+func getRubyBundleVolume(svc *Service) string {
+	return fmt.Sprintf("%s_bundle", strings.ReplaceAll(svc.Name, "-", "_"))
+}
+
+// getGemfileGems returns the gems the Gemfile of a folder lists
+func getGemfileGems(folder string) map[string]bool {
+	gems := make(map[string]bool)
+	if folder == "" {
+		return gems
+	}
+	file, err := os.Open(filepath.Join(folder, "Gemfile"))
+	if err != nil {
+		return gems
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if match := gemPattern.FindStringSubmatch(scanner.Text()); match != nil {
+			gems[match[1]] = true
+		}
+	}
+	return gems
+}
+
+// detectRubyFramework auto-detects the Ruby framework from the Gemfile
+func detectRubyFramework(folder string) string {
+	return NewRubyConfigurator().DetectFramework(folder)
+}
+
+// addRubyService completes the container of a Ruby service with its bundle, environment
+// and puma command
+func addRubyService(compose *DockerCompose, svc *Service) {
+	service, exists := compose.Services[svc.Name]
+	if !exists || !isRubyService(svc) {
+		return
+	}
+	NewRubyConfigurator().ConfigureRubyService(&service, svc)
+	compose.Services[svc.Name] = service
+}
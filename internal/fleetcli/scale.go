@@ -0,0 +1,178 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScaleRequest is a service of fleet.toml and the number of replicas fleet scale runs
+type ScaleRequest struct {
+	Service  string
+	Replicas int
+}
+
+func printScaleUsage() {
+	fmt.Println("Fleet scale - Run more or fewer replicas of stateless services")
+	fmt.Println("\nUsage: fleet scale <service>=<replicas>... [options]")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file  Specify config file (default: the config the stack was started from)")
+	fmt.Println("  --timeout   How long to wait for the nginx container of a PHP app to become healthy (default: 2m)")
+	fmt.Println("\nThe proxy balances requests between the replicas of services with a domain.")
+	fmt.Println("Databases, caches and search engines keep state, so they can't be scaled. The")
+	fmt.Println("next 'fleet up' runs the replicas of fleet.toml again, set 'replicas' to keep a count.")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet scale api=3")
+	fmt.Println("  fleet scale api=2 web=2")
+	fmt.Println("  fleet scale api=1  # Back to a single container")
+}
+
+// parseScaleArgs parses service=replicas arguments
+func parseScaleArgs(args []string) ([]ScaleRequest, error) {
+	var requests []ScaleRequest
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		name, count, found := strings.Cut(arg, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid argument %q, expected <service>=<replicas>", arg)
+		}
+		replicas, err := strconv.Atoi(count)
+		if err != nil || replicas < 1 {
+			return nil, fmt.Errorf("invalid replicas %q for %s, expected a number of 1 or more", count, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("service %s is given twice", name)
+		}
+		seen[name] = true
+		requests = append(requests, ScaleRequest{Service: name, Replicas: replicas})
+	}
+	return requests, nil
+}
+
+// validateScale checks a service can run the requested replicas. compose holds the
+// generated services, to tell shared databases apart from unknown names.
+func validateScale(config *Config, compose *DockerCompose, request ScaleRequest) error {
+	svc := findService(config, request.Service)
+	if svc == nil {
+		if service, exists := compose.Services[request.Service]; exists && isDataImage(service.Image) {
+			return fmt.Errorf("%s is shared by the services using it and keeps their data, it can't be scaled", request.Service)
+		}
+		return fmt.Errorf("unknown service %q, only the services of fleet.toml can be scaled", request.Service)
+	}
+	if svc.Native || svc.ExternalService != "" {
+		return fmt.Errorf("service %s runs no container of this project, it can't be scaled", svc.Name)
+	}
+	if service, exists := compose.Services[svc.Name]; (exists && isDataImage(service.Image)) || isDataImage(svc.Image) {
+		return fmt.Errorf("service %s runs a database, cache or search engine, its replicas wouldn't share their data", svc.Name)
+	}
+
+	scaled := *svc
+	scaled.Replicas = request.Replicas
+	return validateReplicas(&scaled)
+}
+
+// getScaleServices returns the compose services scaled with a service: the service, and
+// the container running its code when that is a sidecar like PHP-FPM, see configureReplicas
+func getScaleServices(svc *Service) []string {
+	if appName := getAppServiceName(svc); appName != svc.Name {
+		return []string{svc.Name, appName}
+	}
+	return []string{svc.Name}
+}
+
+// getScaleUpArgs returns the compose command that scales the services. Running containers
+// are kept, and dependencies are left alone.
+func getScaleUpArgs(config *Config, composeFiles ComposeFiles, requests []ScaleRequest) []string {
+	args := composeArgs(composeFiles, "up", "-d", "--no-deps", "--no-recreate")
+	var services []string
+	for _, request := range requests {
+		for _, name := range getScaleServices(findService(config, request.Service)) {
+			args = append(args, "--scale", fmt.Sprintf("%s=%d", name, request.Replicas))
+			services = append(services, name)
+		}
+	}
+	return append(args, services...)
+}
+
+func handleScale() {
+	fs := newCommandFlagSet("scale", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	timeout := fs.Duration("timeout", 2*time.Minute, "How long to wait for a container to become healthy")
+	lockOptions := addProjectLockFlags(fs)
+	fs.Usage = printScaleUsage
+
+	args := parseFlagsAndArgs(fs, os.Args[2:])
+	if len(args) == 0 || args[0] == "help" {
+		printScaleUsage()
+		os.Exit(0)
+	}
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+	*configFile = resolveRunningConfigFile(fs, *configFile)
+
+	requests, err := parseScaleArgs(args)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	composeFiles := getComposeFiles(config)
+	compose, err := readComposeFiles(composeFiles)
+	if err != nil {
+		log.Fatalf("❌ The project isn't running, start it with 'fleet up': %v", err)
+	}
+	for _, request := range requests {
+		if err := validateScale(config, compose, request); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	}
+
+	release := lockProject("scale", lockOptions)
+	defer release()
+
+	for _, request := range requests {
+		infof("⚖️  Scaling %s to %d replicas\n", request.Service, request.Replicas)
+	}
+	if err := runDocker(getScaleUpArgs(config, composeFiles, requests)); err != nil {
+		log.Fatalf("❌ Error scaling services: %v", err)
+	}
+
+	proxied := false
+	for _, request := range requests {
+		svc := findService(config, request.Service)
+		if getDomainForService(svc) != "" {
+			proxied = true
+		}
+		// nginx in front of PHP-FPM resolves the FPM containers when it starts
+		if getAppServiceName(svc) != svc.Name {
+			if err := rollingRestart(composeFiles, svc.Name, *timeout); err != nil {
+				warnf("⚠️  Warning: %v\n", err)
+			}
+		}
+	}
+
+	// nginx resolves the replicas of an upstream when it loads its config, Traefik
+	// watches containers and routes to new ones by itself
+	if proxied && !isTraefikProxy(config) && !isCloud(false) {
+		if err := reloadProxy(config); err != nil {
+			warnf("⚠️  Warning: the proxy didn't reload, new replicas get requests after 'fleet proxy reload': %v\n", err)
+		}
+	}
+
+	for _, request := range requests {
+		infof("✅ %s runs %d replicas\n", request.Service, request.Replicas)
+		if svc := findService(config, request.Service); max(svc.Replicas, 1) != request.Replicas {
+			infof("   Set replicas = %d on %s in fleet.toml to keep them on the next 'fleet up'\n", request.Replicas, svc.Name)
+		}
+	}
+}
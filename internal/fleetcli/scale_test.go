@@ -0,0 +1,79 @@
+package fleetcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ScaleTestSuite struct {
+	suite.Suite
+	config  *Config
+	compose *DockerCompose
+}
+
+func (suite *ScaleTestSuite) SetupTest() {
+	suite.config = &Config{Project: "shop", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Domain: "api.test", Database: "postgres:16"},
+		{Name: "web", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "web", Domain: "web.test"},
+		{Name: "tools", Image: "node:20", Ports: []string{"9000:9000"}},
+		{Name: "db", Image: "postgres:16"},
+		{Name: "host", Native: true, Command: "npm run dev"},
+	}}
+	suite.compose = &DockerCompose{Services: map[string]DockerService{
+		"api":         {Image: "node:20"},
+		"web":         {Image: "nginx:alpine"},
+		"web-php":     {Image: "php:8.3-fpm-alpine"},
+		"db":          {Image: "postgres:16"},
+		"postgres-16": {Image: "postgres:16-alpine"},
+		"mailpit":     {Image: "axllent/mailpit"},
+	}}
+}
+
+func (suite *ScaleTestSuite) TestParseScaleArgs() {
+	requests, err := parseScaleArgs([]string{"api=3", "web=1"})
+	suite.Require().NoError(err)
+	suite.Equal([]ScaleRequest{{"api", 3}, {"web", 1}}, requests)
+
+	for _, args := range [][]string{{"api"}, {"=2"}, {"api=two"}, {"api=0"}, {"api=-1"}, {"api=2", "api=3"}} {
+		_, err := parseScaleArgs(args)
+		suite.Error(err, "%v", args)
+	}
+}
+
+func (suite *ScaleTestSuite) TestValidateScale() {
+	suite.NoError(validateScale(suite.config, suite.compose, ScaleRequest{"api", 3}))
+	suite.NoError(validateScale(suite.config, suite.compose, ScaleRequest{"web", 2}))
+	suite.NoError(validateScale(suite.config, suite.compose, ScaleRequest{"tools", 1}))
+
+	testCases := []struct {
+		name    string
+		request ScaleRequest
+		wantErr string
+	}{
+		{"shared database", ScaleRequest{"postgres-16", 2}, "postgres-16 is shared by the services using it"},
+		{"database service", ScaleRequest{"db", 2}, "runs a database, cache or search engine"},
+		{"sidecar", ScaleRequest{"mailpit", 2}, "only the services of fleet.toml can be scaled"},
+		{"unknown", ScaleRequest{"billing", 2}, "unknown service"},
+		{"native", ScaleRequest{"host", 2}, "runs no container"},
+		{"published ports", ScaleRequest{"tools", 2}, "only one replica can publish its ports"},
+	}
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			err := validateScale(suite.config, suite.compose, tc.request)
+			suite.Require().Error(err)
+			suite.Contains(err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func (suite *ScaleTestSuite) TestGetScaleUpArgs() {
+	files := ComposeFiles{Files: []string{".fleet/docker-compose.yml"}}
+	args := getScaleUpArgs(suite.config, files, []ScaleRequest{{"api", 3}, {"web", 2}})
+	suite.Equal(append(composeArgs(files, "up", "-d", "--no-deps", "--no-recreate"),
+		"--scale", "api=3", "--scale", "web=2", "--scale", "web-php=2", "api", "web", "web-php"), args)
+}
+
+func TestScaleSuite(t *testing.T) {
+	suite.Run(t, new(ScaleTestSuite))
+}
@@ -0,0 +1,400 @@
+package fleetcli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// scanDefaultDepth is how many folder levels fleet scan searches by default
+const scanDefaultDepth = 3
+
+// scanSkipDirs are dependency and build output folders fleet scan never searches
+var scanSkipDirs = []string{"node_modules", "vendor", "dist", "build", "target", "storage", "venv", "__pycache__"}
+
+// Versions read from project files
+var (
+	composerPHPVersion = regexp.MustCompile(`(\d+)\.(\d+)`)
+	goModVersion       = regexp.MustCompile(`(?m)^go (\d+\.\d+)`)
+)
+
+// serviceNameInvalid matches what a folder name can't keep in a service name
+var serviceNameInvalid = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ScannedService is a service fleet scan proposes for a folder of the repository
+type ScannedService struct {
+	// Kind is what was detected: php, node, dockerfile, go or python
+	Kind    string
+	Service Service
+}
+
+// describe returns what was detected, like "php (laravel)"
+func (s ScannedService) describe() string {
+	if s.Service.Framework != "" {
+		return fmt.Sprintf("%s (%s)", s.Kind, s.Service.Framework)
+	}
+	return s.Kind
+}
+
+// scanProject looks for applications in root and its subfolders, up to depth levels
+// deep. A folder holding an application isn't searched further, so the package.json of
+// a Laravel app doesn't become a second service. Folders of existing services are skipped.
+func scanProject(root string, depth int, existing *Config) ([]ScannedService, error) {
+	project := getScanProjectName(root, existing)
+	taken := make(map[string]bool)
+	configured := make(map[string]bool)
+	for _, svc := range existing.Services {
+		taken[svc.Name] = true
+		for _, folder := range []string{svc.Folder, svc.Build} {
+			if folder != "" {
+				configured[filepath.Clean(folder)] = true
+			}
+		}
+	}
+
+	var found []ScannedService
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." {
+			name := entry.Name()
+			if strings.HasPrefix(name, ".") || containsString(scanSkipDirs, name) || configured[rel] {
+				return filepath.SkipDir
+			}
+			if strings.Count(rel, string(filepath.Separator))+1 > depth {
+				return filepath.SkipDir
+			}
+		} else if configured[rel] {
+			return nil
+		}
+
+		scanned, ok := detectScannedService(path)
+		if !ok {
+			return nil
+		}
+
+		folder := "."
+		if rel != "." {
+			folder = "./" + filepath.ToSlash(rel)
+		}
+		scanned.Service.Name = getScannedServiceName(rel, project, taken)
+		if scanned.Service.Build != "" {
+			scanned.Service.Build = folder
+		} else {
+			scanned.Service.Folder = folder
+		}
+		taken[scanned.Service.Name] = true
+		found = append(found, scanned)
+		return filepath.SkipDir
+	})
+	return found, err
+}
+
+// detectScannedService checks a folder for an application Fleet can run. PHP and Node.js
+// apps use Fleet's runtimes, which mount the code. Other apps are built from their
+// Dockerfile, or run in the official Go or Python image when they have none.
+func detectScannedService(dir string) (ScannedService, bool) {
+	switch {
+	case fileExists(filepath.Join(dir, "composer.json")):
+		return ScannedService{Kind: "php", Service: Service{
+			Image:     "nginx:alpine",
+			Runtime:   "php:" + getComposerPHPVersion(dir),
+			Framework: detectPHPFramework(dir),
+		}}, true
+	case fileExists(filepath.Join(dir, "package.json")):
+		return ScannedService{Kind: "node", Service: Service{
+			Runtime:   "node:" + versionData.Node.Default,
+			Framework: detectNodeFramework(dir),
+			Port:      getNodePort(&Service{Folder: dir}),
+		}}, true
+	case fileExists(filepath.Join(dir, "Dockerfile")):
+		return ScannedService{Kind: "dockerfile", Service: Service{
+			Build: dir,
+			Port:  getDockerfilePort(filepath.Join(dir, "Dockerfile")),
+		}}, true
+	case fileExists(filepath.Join(dir, "go.mod")):
+		image := "golang:alpine"
+		if content, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			if match := goModVersion.FindSubmatch(content); match != nil {
+				image = fmt.Sprintf("golang:%s-alpine", match[1])
+			}
+		}
+		return ScannedService{Kind: "go", Service: Service{
+			Image:   image,
+			Port:    8080,
+			Command: `sh -c "cd /app && go run ."`,
+		}}, true
+	case fileExists(filepath.Join(dir, "requirements.txt")):
+		return ScannedService{Kind: "python", Service: Service{
+			Image:   "python:3.12-slim",
+			Port:    8000,
+			Command: fmt.Sprintf(`sh -c "cd /app && pip install -r requirements.txt && %s"`, getPythonStartCommand(dir)),
+		}}, true
+	}
+	return ScannedService{}, false
+}
+
+// getComposerPHPVersion returns the PHP version for the php requirement of composer.json.
+// Requirements allowing the default version, like ^8.1, get the default. Pinned ones,
+// like ~7.4 or 8.1.*, get their version when Fleet supports it.
+func getComposerPHPVersion(dir string) string {
+	defaultVersion := versionData.PHP.Default
+
+	content, err := os.ReadFile(filepath.Join(dir, "composer.json"))
+	if err != nil {
+		return defaultVersion
+	}
+	var composer struct {
+		Require map[string]string `json:"require"`
+	}
+	if err := json.Unmarshal(content, &composer); err != nil {
+		return defaultVersion
+	}
+
+	constraint := strings.TrimSpace(composer.Require["php"])
+	match := composerPHPVersion.FindStringSubmatch(constraint)
+	if match == nil {
+		return defaultVersion
+	}
+	version := match[1] + "." + match[2]
+	defaultMajor, _, _ := strings.Cut(defaultVersion, ".")
+	if (strings.HasPrefix(constraint, "^") || strings.HasPrefix(constraint, ">=")) && match[1] == defaultMajor {
+		return defaultVersion
+	}
+	if _, supported := versionData.PHP.Images[version]; supported {
+		return version
+	}
+	return defaultVersion
+}
+
+// getDockerfilePort returns the first port a Dockerfile exposes, or 0
+func getDockerfilePort(path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "EXPOSE") {
+			continue
+		}
+		port, _, _ := strings.Cut(fields[1], "/")
+		if value, err := strconv.Atoi(port); err == nil {
+			return value
+		}
+	}
+	return 0
+}
+
+// getPythonStartCommand guesses how a Python app starts from its entry point
+func getPythonStartCommand(dir string) string {
+	switch {
+	case fileExists(filepath.Join(dir, "manage.py")):
+		return "python manage.py runserver 0.0.0.0:8000"
+	case fileExists(filepath.Join(dir, "app.py")):
+		return "python app.py"
+	default:
+		return "python main.py"
+	}
+}
+
+// getScannedServiceName names a service after its folder. Names already taken get the
+// parent folders as a prefix, then a number.
+func getScannedServiceName(rel, project string, taken map[string]bool) string {
+	if rel == "." {
+		rel = project
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := range parts {
+		parts[i] = strings.Trim(serviceNameInvalid.ReplaceAllString(strings.ToLower(parts[i]), "-"), "-")
+	}
+
+	name := parts[len(parts)-1]
+	if name == "" {
+		name = "app"
+	}
+	if !taken[name] {
+		return name
+	}
+	if len(parts) > 1 {
+		if prefixed := strings.Join(parts, "-"); !taken[prefixed] {
+			return prefixed
+		}
+	}
+	for i := 2; ; i++ {
+		if numbered := fmt.Sprintf("%s-%d", name, i); !taken[numbered] {
+			return numbered
+		}
+	}
+}
+
+// getScanProjectName returns the project of the config, or the name of the scanned folder
+func getScanProjectName(root string, existing *Config) string {
+	if existing.Project != "" {
+		return existing.Project
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "fleet-project"
+	}
+	name := strings.Trim(serviceNameInvalid.ReplaceAllString(strings.ToLower(filepath.Base(abs)), "-"), "-")
+	if name == "" {
+		return "fleet-project"
+	}
+	return name
+}
+
+// renderScannedService renders a [[services]] block for a scanned service
+func renderScannedService(svc Service) string {
+	var b strings.Builder
+	b.WriteString("[[services]]\n")
+	fmt.Fprintf(&b, "name = %q\n", svc.Name)
+	for _, field := range []struct{ key, value string }{
+		{"image", svc.Image},
+		{"build", svc.Build},
+		{"runtime", svc.Runtime},
+		{"framework", svc.Framework},
+		{"folder", svc.Folder},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&b, "%s = %q\n", field.key, field.value)
+		}
+	}
+	if svc.Port > 0 {
+		fmt.Fprintf(&b, "port = %d\n", svc.Port)
+	}
+	if svc.Command != "" {
+		fmt.Fprintf(&b, "command = %q\n", svc.Command)
+	}
+	return b.String()
+}
+
+// readScanConfig reads the services already in the config file. A missing file is empty.
+func readScanConfig(configFile string) (*Config, error) {
+	content, err := os.ReadFile(configFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	var config Config
+	if err := toml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return &config, nil
+}
+
+// writeScannedServices appends scanned services to the config file, creating it for
+// the project when it doesn't exist
+func writeScannedServices(configFile, project string, services []ScannedService) error {
+	if _, err := os.Stat(configFile); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(configFile, []byte(fmt.Sprintf("project = %q\n", project)), 0644); err != nil {
+			return fmt.Errorf("failed to create %s: %w", configFile, err)
+		}
+	}
+	for _, scanned := range services {
+		if err := appendServiceBlock(configFile, scanned.Service.Name, renderScannedService(scanned.Service)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func handleScan() {
+	fs := newCommandFlagSet("scan", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	yes := fs.Bool("yes", false, "Add every service found without asking")
+	depth := fs.Int("depth", scanDefaultDepth, "How many folder levels to search")
+
+	fs.Parse(os.Args[2:])
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	existing, err := readScanConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	root := filepath.Dir(*configFile)
+	found, err := scanProject(root, *depth, existing)
+	if err != nil {
+		log.Fatalf("❌ Error scanning %s: %v", root, err)
+	}
+	if len(found) == 0 {
+		outputf("No new services found in %s\n", root)
+		return
+	}
+
+	outputf("🔍 Found %d services:\n", len(found))
+	for _, scanned := range found {
+		location := scanned.Service.Folder
+		if location == "" {
+			location = scanned.Service.Build
+		}
+		outputf("  %-16s %-24s %s\n", scanned.Service.Name, location, scanned.describe())
+	}
+	outputln()
+
+	selected := found
+	if !*yes {
+		if !isInteractiveTerminal() {
+			for _, scanned := range found {
+				outputln(renderScannedService(scanned.Service))
+			}
+			infof("Run 'fleet scan --yes' to add them to %s\n", *configFile)
+			return
+		}
+
+		selected = nil
+		for _, scanned := range found {
+			add := true
+			prompt := &survey.Confirm{
+				Message: fmt.Sprintf("Add %s (%s in %s)?", scanned.Service.Name, scanned.describe(), scanned.Service.Folder+scanned.Service.Build),
+				Default: true,
+			}
+			if err := survey.AskOne(prompt, &add); err != nil {
+				log.Fatalf("❌ %v", err)
+			}
+			if add {
+				selected = append(selected, scanned)
+			}
+		}
+		if len(selected) == 0 {
+			outputln("No services added")
+			return
+		}
+	}
+
+	if err := writeScannedServices(*configFile, getScanProjectName(root, existing), selected); err != nil {
+		log.Fatalf("❌ Error adding services: %v", err)
+	}
+	outputf("✅ Added %d services to %s\n", len(selected), *configFile)
+	infoln("Review them, add databases and caches, then run 'fleet up'")
+}
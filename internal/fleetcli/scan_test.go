@@ -0,0 +1,126 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ScanTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *ScanTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *ScanTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+// writeFiles creates files with their content, relative to the test directory
+func (suite *ScanTestSuite) writeFiles(files map[string]string) {
+	for path, content := range files {
+		suite.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		suite.Require().NoError(os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func (suite *ScanTestSuite) TestScanMonorepo() {
+	suite.writeFiles(map[string]string{
+		"backend/composer.json":          `{"require": {"php": "^8.2", "laravel/framework": "^11.0"}}`,
+		"backend/artisan":                "",
+		"backend/package.json":           `{"devDependencies": {"vite": "^5.0"}}`,
+		"apps/web/package.json":          `{"dependencies": {"next": "14.0.0"}}`,
+		"apps/web/node_modules/x/go.mod": "module x\n",
+		"services/worker/go.mod":         "module worker\n\ngo 1.22.1\n",
+		"services/ml/requirements.txt":   "flask\n",
+		"services/ml/app.py":             "",
+		"gateway/Dockerfile":             "FROM nginx\nEXPOSE 8443/tcp\n",
+		".github/Dockerfile":             "FROM alpine\n",
+		"docs/README.md":                 "",
+	})
+
+	found, err := scanProject(".", scanDefaultDepth, &Config{})
+	suite.Require().NoError(err)
+
+	services := make(map[string]ScannedService)
+	for _, scanned := range found {
+		services[scanned.Service.Name] = scanned
+	}
+	suite.Len(services, 5, "Dependencies, hidden folders and the assets of the PHP app aren't services")
+
+	suite.Equal(Service{Name: "backend", Image: "nginx:alpine", Runtime: "php:" + versionData.PHP.Default, Framework: "laravel", Folder: "./backend"}, services["backend"].Service)
+	suite.Equal(Service{Name: "web", Runtime: "node:" + versionData.Node.Default, Framework: "nextjs", Folder: "./apps/web", Port: 3000}, services["web"].Service)
+	suite.Equal("golang:1.22-alpine", services["worker"].Service.Image)
+	suite.Equal(`sh -c "cd /app && pip install -r requirements.txt && python app.py"`, services["ml"].Service.Command)
+	suite.Equal(Service{Name: "gateway", Build: "./gateway", Port: 8443}, services["gateway"].Service)
+}
+
+func (suite *ScanTestSuite) TestScanSkipsConfiguredFolders() {
+	suite.writeFiles(map[string]string{
+		"api/package.json":    `{}`,
+		"admin/package.json":  `{}`,
+		"site/api/Dockerfile": "FROM node\n",
+	})
+
+	existing := &Config{Project: "shop", Services: []Service{{Name: "api", Folder: "./api", Runtime: "node:20"}}}
+	found, err := scanProject(".", scanDefaultDepth, existing)
+	suite.Require().NoError(err)
+
+	var names []string
+	for _, scanned := range found {
+		names = append(names, scanned.Service.Name)
+	}
+	suite.ElementsMatch([]string{"admin", "site-api"}, names, "Taken names get their parent folder as a prefix")
+}
+
+func (suite *ScanTestSuite) TestGetComposerPHPVersion() {
+	testCases := []struct {
+		constraint string
+		expected   string
+	}{
+		{"^8.1", versionData.PHP.Default},
+		{">=8.0", versionData.PHP.Default},
+		{"~8.1.0", "8.1"},
+		{"7.4.*", "7.4"},
+		{"^5.6", versionData.PHP.Default},
+		{"", versionData.PHP.Default},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.constraint, func() {
+			suite.writeFiles(map[string]string{"app/composer.json": `{"require": {"php": "` + tc.constraint + `"}}`})
+			suite.Equal(tc.expected, getComposerPHPVersion("app"))
+		})
+	}
+}
+
+func (suite *ScanTestSuite) TestWriteScannedServices() {
+	services := []ScannedService{
+		{Kind: "node", Service: Service{Name: "web", Runtime: "node:20", Folder: "./web", Port: 3000}},
+		{Kind: "go", Service: Service{Name: "worker", Image: "golang:1.22-alpine", Folder: "./worker", Port: 8080, Command: `sh -c "cd /app && go run ."`}},
+	}
+	suite.Require().NoError(writeScannedServices("fleet.toml", "shop", services))
+
+	config, err := loadConfig("fleet.toml")
+	suite.Require().NoError(err)
+	suite.Equal("shop", config.Project)
+	suite.Require().Len(config.Services, 2)
+	suite.Equal(services[1].Service.Command, config.Services[1].Command)
+
+	suite.ErrorContains(writeScannedServices("fleet.toml", "shop", services[:1]), "already exists")
+}
+
+func TestScanSuite(t *testing.T) {
+	suite.Run(t, new(ScanTestSuite))
+}
@@ -219,7 +219,7 @@ main() {
     echo -e "  - ${YELLOW}$(dirname "$HOSTS_FILE")/hosts.backup${NC} (local)"
     echo
     echo "To test the DNS configuration:"
-    echo "  1. Start the dnsmasq container: docker-compose -f templates/compose/docker-compose.dnsmasq.yml up -d"
+    echo "  1. Start the dnsmasq container: docker-compose -f internal/fleetcli/templates/compose/docker-compose.dnsmasq.yml up -d"
     echo "  2. Test DNS resolution: nslookup test.test 127.0.0.1"
     echo
     echo "To remove Fleet DNS configuration:"
@@ -20,7 +20,7 @@ if docker ps | grep -q "dnsmasq"; then
     echo -e "${GREEN}✓ Dnsmasq container is running${NC}"
 else
     echo -e "${RED}✗ Dnsmasq container is not running${NC}"
-    echo "Start it with: docker-compose -f templates/compose/docker-compose.dnsmasq.yml up -d"
+    echo "Start it with: docker-compose -f internal/fleetcli/templates/compose/docker-compose.dnsmasq.yml up -d"
     exit 1
 fi
 
@@ -70,5 +70,5 @@ docker logs dnsmasq --tail 10 2>&1 | grep -E "query|reply" || echo "No recent qu
 
 echo -e "\n${GREEN}Test complete!${NC}"
 echo -e "\nTo add custom .test domains:"
-echo -e "  1. Edit: config/services/hosts.test"
-echo -e "  2. Restart: docker-compose -f templates/compose/docker-compose.dnsmasq.yml restart"
\ No newline at end of file
+echo -e "  1. Edit: internal/fleetcli/config/services/hosts.test"
+echo -e "  2. Restart: docker-compose -f internal/fleetcli/templates/compose/docker-compose.dnsmasq.yml restart"
\ No newline at end of file
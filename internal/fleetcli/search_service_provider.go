@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -13,14 +13,8 @@ type SearchServiceProvider struct {
 // NewSearchServiceProvider creates a new search service provider
 func NewSearchServiceProvider() *SearchServiceProvider {
 	return &SearchServiceProvider{
-		defaultVersions: map[string]string{
-			"meilisearch": "1.6",
-			"typesense":   "27.1",
-		},
-		supportedVersions: map[string][]string{
-			"meilisearch": {"1.0", "1.1", "1.2", "1.3", "1.4", "1.5", "1.6"},
-			"typesense":   {"0.24", "0.25", "26.0", "27.0", "27.1"},
-		},
+		defaultVersions:   getDefaultVersions(versionData.Search),
+		supportedVersions: getVersionLists(versionData.Search),
 	}
 }
 
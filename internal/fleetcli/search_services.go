@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -13,29 +13,8 @@ type SearchConfig struct {
 	APIKey    string // For Typesense authentication
 }
 
-// Supported search service versions
-var supportedSearchVersions = map[string]map[string]string{
-	"meilisearch": {
-		"1.0":     "getmeili/meilisearch:v1.0",
-		"1.1":     "getmeili/meilisearch:v1.1",
-		"1.2":     "getmeili/meilisearch:v1.2",
-		"1.3":     "getmeili/meilisearch:v1.3",
-		"1.4":     "getmeili/meilisearch:v1.4",
-		"1.5":     "getmeili/meilisearch:v1.5",
-		"1.6":     "getmeili/meilisearch:v1.6",
-		"latest":  "getmeili/meilisearch:latest",
-		"default": "getmeili/meilisearch:v1.6",
-	},
-	"typesense": {
-		"0.24":    "typesense/typesense:0.24.0",
-		"0.25":    "typesense/typesense:0.25.2",
-		"26.0":    "typesense/typesense:26.0",
-		"27.0":    "typesense/typesense:27.0",
-		"27.1":    "typesense/typesense:27.1",
-		"latest":  "typesense/typesense:latest",
-		"default": "typesense/typesense:27.1",
-	},
-}
+// Supported search service versions, from config/versions.json
+var supportedSearchVersions = imageMaps(versionData.Search)
 
 // parseSearchType parses search type and version from a string like "meilisearch:1.6"
 func parseSearchType(searchString string) (searchType string, version string) {
@@ -0,0 +1,171 @@
+package fleetcli
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// secretsInline keeps secrets in the environment section of the compose file
+	secretsInline = "inline"
+	// secretsEnvFile moves secrets into per-service env files only the owner can read
+	secretsEnvFile = "env_file"
+)
+
+// secretEnvDir is where per-service env files are written, with the generated files
+const secretEnvDir = "env"
+
+// fleetGitignore keeps generated files, which contain local credentials, out of version control
+const fleetGitignore = `# Generated by Fleet CLI
+# Everything in .fleet is generated and may contain local credentials
+*
+# except the image lockfile, which is shared like any other lockfile
+!images.lock
+`
+
+// secretKeyPattern matches environment variable names that hold credentials
+var secretKeyPattern = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|PWD|SECRET|TOKEN|KEY|CREDENTIAL)`)
+
+// validateSecrets checks the secrets setting of a config
+func validateSecrets(config *Config) error {
+	switch config.Secrets {
+	case "", secretsInline, secretsEnvFile:
+		return nil
+	}
+	return fmt.Errorf("invalid secrets %q (expected %s or %s)", config.Secrets, secretsInline, secretsEnvFile)
+}
+
+// isSecretEnvVar checks if an environment variable holds a credential, either by its
+// name or because its value is a URL with a password
+func isSecretEnvVar(key, value string) bool {
+	if secretKeyPattern.MatchString(key) {
+		return true
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if password, ok := u.User.Password(); ok && password != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// formatEnvFileLine returns a KEY=VALUE line that compose reads back unchanged
+func formatEnvFileLine(key, value string) string {
+	if !strings.Contains(value, "'") {
+		// Single quoted values are taken literally
+		return fmt.Sprintf("%s='%s'", key, value)
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`)
+	return fmt.Sprintf(`%s="%s"`, key, replacer.Replace(value))
+}
+
+// parseEnvFileLine reads back a KEY=VALUE line written by formatEnvFileLine
+func parseEnvFileLine(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	key, value, found := strings.Cut(line, "=")
+	if !found {
+		return "", "", false
+	}
+	switch {
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		value = value[1 : len(value)-1]
+	case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+		replacer := strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\$`, `$`, `\n`, "\n")
+		value = replacer.Replace(value[1 : len(value)-1])
+	}
+	return key, value, true
+}
+
+// readServiceEnvironment returns the variables of a generated compose service, with the
+// secrets moved to its env files
+func readServiceEnvironment(files ComposeFiles, compose *DockerCompose, name string) (map[string]string, error) {
+	service := compose.Services[name]
+	env := make(map[string]string, len(service.Environment))
+	for key, value := range service.Environment {
+		env[key] = value
+	}
+	for _, envFile := range service.EnvFile {
+		path := envFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(files.ProjectDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the env file of %s: %w", name, err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if key, value, ok := parseEnvFileLine(line); ok {
+				env[key] = value
+			}
+		}
+	}
+	return env, nil
+}
+
+// getSecretEnvFileName returns the env file of a service, relative to the compose project
+// directory in .fleet
+func getSecretEnvFileName(plan *ArtifactPlan, serviceName string) string {
+	return plan.mountPath(filepath.Join(secretEnvDir, serviceName+".env"))
+}
+
+// moveSecretsToEnvFiles moves the secrets of every compose service to an env file
+// readable only by the owner and references it instead of inlining the values
+func moveSecretsToEnvFiles(compose *DockerCompose) {
+	plan := getArtifactPlan(compose)
+	for name, service := range compose.Services {
+		var keys []string
+		for key, value := range service.Environment {
+			if isSecretEnvVar(key, value) {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		sort.Strings(keys)
+
+		plan.addDir(plan.path(secretEnvDir), 0700)
+
+		// The environment map may be shared with the config, so build a new one
+		env := make(map[string]string, len(service.Environment)-len(keys))
+		var content strings.Builder
+		content.WriteString("# Generated by Fleet CLI - DO NOT EDIT\n")
+		for key, value := range service.Environment {
+			if !containsString(keys, key) {
+				env[key] = value
+			}
+		}
+		for _, key := range keys {
+			content.WriteString(formatEnvFileLine(key, service.Environment[key]) + "\n")
+		}
+
+		plan.addFile(plan.path(filepath.Join(secretEnvDir, name+".env")), []byte(content.String()), 0600)
+
+		service.Environment = env
+		service.EnvFile = append(service.EnvFile, getSecretEnvFileName(plan, name))
+		compose.Services[name] = service
+	}
+}
+
+// ensureFleetGitignore adds a .gitignore to .fleet unless one is already there
+func ensureFleetGitignore() error {
+	path := filepath.Join(".fleet", ".gitignore")
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(".fleet", 0755); err != nil {
+		return fmt.Errorf("failed to create .fleet directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(fleetGitignore), 0644); err != nil {
+		return fmt.Errorf("failed to write .fleet/.gitignore: %w", err)
+	}
+	return nil
+}
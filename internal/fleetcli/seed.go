@@ -0,0 +1,387 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// fakeDataBatchSize is how many rows one INSERT of fake data holds
+const fakeDataBatchSize = 500
+
+// tablePrefixPattern matches a prefix every supported database accepts in table names
+var tablePrefixPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,40}$`)
+
+// FakeLocale is what demo data of a country looks like
+type FakeLocale struct {
+	Country    string
+	Currency   string
+	Decimals   int     // Decimals of amounts in the currency, 0 for yen
+	PriceScale float64 // Turns a price in dollars into one in the currency
+	EmailHost  string
+	Phone      string // Pattern where # is a random digit
+	FirstNames []string
+	LastNames  []string
+	Cities     []string
+}
+
+// fakeLocales are the locales fleet seed fake generates data for
+var fakeLocales = map[string]FakeLocale{
+	"en_US": {Country: "US", Currency: "USD", Decimals: 2, PriceScale: 1, EmailHost: "example.com", Phone: "(###) ###-####",
+		FirstNames: []string{"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Emily", "Daniel", "Olivia"},
+		LastNames:  []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Wilson", "Moore", "Taylor", "Clark"},
+		Cities:     []string{"New York", "Chicago", "Houston", "Phoenix", "Seattle", "Denver", "Boston", "Austin"}},
+	"en_GB": {Country: "GB", Currency: "GBP", Decimals: 2, PriceScale: 0.8, EmailHost: "example.co.uk", Phone: "07### ######",
+		FirstNames: []string{"Oliver", "Amelia", "George", "Isla", "Harry", "Ava", "Jack", "Mia", "Charlie", "Grace", "Thomas", "Freya"},
+		LastNames:  []string{"Smith", "Jones", "Taylor", "Brown", "Williams", "Wilson", "Evans", "Thomas", "Roberts", "Walker", "Wright", "Hughes"},
+		Cities:     []string{"London", "Manchester", "Birmingham", "Leeds", "Glasgow", "Bristol", "Liverpool", "Edinburgh"}},
+	"fr_FR": {Country: "FR", Currency: "EUR", Decimals: 2, PriceScale: 0.9, EmailHost: "exemple.fr", Phone: "06 ## ## ## ##",
+		FirstNames: []string{"Gabriel", "Louise", "Léo", "Jade", "Raphaël", "Chloé", "Louis", "Emma", "Noé", "Inès", "Hugo", "Léa"},
+		LastNames:  []string{"Martin", "Bernard", "Dubois", "Thomas", "Robert", "Richard", "Petit", "Durand", "Leroy", "Moreau", "Lefèvre", "Girard"},
+		Cities:     []string{"Paris", "Lyon", "Marseille", "Toulouse", "Nantes", "Bordeaux", "Lille", "Strasbourg"}},
+	"de_DE": {Country: "DE", Currency: "EUR", Decimals: 2, PriceScale: 0.9, EmailHost: "beispiel.de", Phone: "0151 ########",
+		FirstNames: []string{"Lukas", "Mia", "Jonas", "Emma", "Felix", "Hannah", "Maximilian", "Sophie", "Paul", "Lena", "Jürgen", "Käthe"},
+		LastNames:  []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann", "Koch", "Krüger"},
+		Cities:     []string{"Berlin", "Hamburg", "München", "Köln", "Frankfurt", "Stuttgart", "Düsseldorf", "Leipzig"}},
+	"es_ES": {Country: "ES", Currency: "EUR", Decimals: 2, PriceScale: 0.9, EmailHost: "ejemplo.es", Phone: "6## ### ###",
+		FirstNames: []string{"Hugo", "Lucía", "Martín", "Sofía", "Pablo", "Martina", "Alejandro", "María", "Álvaro", "Paula", "Mateo", "Julia"},
+		LastNames:  []string{"García", "Rodríguez", "González", "Fernández", "López", "Martínez", "Sánchez", "Pérez", "Gómez", "Martín", "Jiménez", "Ruiz"},
+		Cities:     []string{"Madrid", "Barcelona", "Valencia", "Sevilla", "Zaragoza", "Málaga", "Bilbao", "Granada"}},
+	"pt_BR": {Country: "BR", Currency: "BRL", Decimals: 2, PriceScale: 5, EmailHost: "exemplo.com.br", Phone: "(11) 9####-####",
+		FirstNames: []string{"Miguel", "Helena", "Arthur", "Alice", "Heitor", "Laura", "Davi", "Manuela", "Théo", "Valentina", "João", "Sofia"},
+		LastNames:  []string{"Silva", "Santos", "Oliveira", "Souza", "Rodrigues", "Ferreira", "Alves", "Pereira", "Lima", "Gomes", "Costa", "Ribeiro"},
+		Cities:     []string{"São Paulo", "Rio de Janeiro", "Belo Horizonte", "Salvador", "Curitiba", "Recife", "Fortaleza", "Porto Alegre"}},
+	"ja_JP": {Country: "JP", Currency: "JPY", Decimals: 0, PriceScale: 150, EmailHost: "example.jp", Phone: "090-####-####",
+		FirstNames: []string{"Haruto", "Himari", "Sota", "Yui", "Minato", "Aoi", "Ren", "Sakura", "Yuto", "Hina", "Riku", "Mei"},
+		LastNames:  []string{"Sato", "Suzuki", "Takahashi", "Tanaka", "Watanabe", "Ito", "Yamamoto", "Nakamura", "Kobayashi", "Kato", "Yoshida", "Yamada"},
+		Cities:     []string{"Tokyo", "Osaka", "Yokohama", "Nagoya", "Sapporo", "Fukuoka", "Kobe", "Kyoto"}},
+}
+
+// fakeOrderStatuses are weighted by how often orders end up in them
+var fakeOrderStatuses = []string{"delivered", "delivered", "delivered", "shipped", "shipped", "paid", "pending", "cancelled", "refunded"}
+
+// emailTransliterator keeps email addresses ASCII
+var emailTransliterator = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "ae", "ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"í", "i", "î", "i", "ï", "i", "ñ", "n", "ó", "o", "ô", "o", "õ", "o", "ö", "oe", "ú", "u", "ü", "ue", "ß", "ss",
+	"Á", "a", "É", "e", "Í", "i", "Ó", "o", "Ú", "u", "Ä", "ae", "Ö", "oe", "Ü", "ue", "Ç", "c",
+)
+
+// FakeDataOptions control the demo data fleet seed fake generates
+type FakeDataOptions struct {
+	Rows   int
+	Locale string
+	Seed   int64 // The same seed generates the same data
+	Now    time.Time
+}
+
+// FakeUser is a row of the demo users table
+type FakeUser struct {
+	ID        int
+	FirstName string
+	LastName  string
+	Email     string
+	Phone     string
+	City      string
+	Country   string
+	CreatedAt time.Time
+}
+
+// FakeOrder is a row of the demo orders table
+type FakeOrder struct {
+	ID        int
+	UserID    int
+	Number    string
+	Total     int64 // In the smallest unit of the currency, like cents
+	Currency  string
+	Status    string
+	CreatedAt time.Time
+}
+
+// getFakeLocaleNames returns the supported locales, sorted
+func getFakeLocaleNames() []string {
+	return sortedKeys(fakeLocales)
+}
+
+// generateFakeData returns users of a locale and as many orders, placed by them after
+// they signed up within the last year
+func generateFakeData(options FakeDataOptions) ([]FakeUser, []FakeOrder, error) {
+	locale, ok := fakeLocales[options.Locale]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported locale %q (supported: %s)", options.Locale, strings.Join(getFakeLocaleNames(), ", "))
+	}
+	if options.Rows <= 0 {
+		return nil, nil, fmt.Errorf("rows must be positive")
+	}
+
+	random := rand.New(rand.NewSource(options.Seed))
+	pick := func(values []string) string { return values[random.Intn(len(values))] }
+	year := 365 * 24 * time.Hour
+
+	users := make([]FakeUser, options.Rows)
+	for i := range users {
+		first, last := pick(locale.FirstNames), pick(locale.LastNames)
+		local := emailTransliterator.Replace(strings.ToLower(first + "." + last))
+		local = strings.ReplaceAll(local, " ", "")
+		phone := []byte(locale.Phone)
+		for j := range phone {
+			if phone[j] == '#' {
+				phone[j] = byte('0' + random.Intn(10))
+			}
+		}
+		users[i] = FakeUser{
+			ID:        i + 1,
+			FirstName: first,
+			LastName:  last,
+			// The id keeps addresses unique
+			Email:     fmt.Sprintf("%s%d@%s", local, i+1, locale.EmailHost),
+			Phone:     string(phone),
+			City:      pick(locale.Cities),
+			Country:   locale.Country,
+			CreatedAt: options.Now.Add(-time.Duration(random.Int63n(int64(year)))).Truncate(time.Second),
+		}
+	}
+
+	unit := 1.0
+	for i := 0; i < locale.Decimals; i++ {
+		unit *= 10
+	}
+	orders := make([]FakeOrder, options.Rows)
+	for i := range orders {
+		user := users[random.Intn(len(users))]
+		// Between 5 and 500 dollars, in the currency
+		price := (5 + random.Float64()*495) * locale.PriceScale
+		orders[i] = FakeOrder{
+			ID:        i + 1,
+			UserID:    user.ID,
+			Number:    fmt.Sprintf("%s-%06d", locale.Country, i+1),
+			Total:     int64(price * unit),
+			Currency:  locale.Currency,
+			Status:    pick(fakeOrderStatuses),
+			CreatedAt: user.CreatedAt.Add(time.Duration(random.Int63n(int64(options.Now.Sub(user.CreatedAt)) + 1))).Truncate(time.Second),
+		}
+	}
+	return users, orders, nil
+}
+
+// formatFakeAmount formats an amount in the smallest unit of a currency as a decimal
+func formatFakeAmount(amount int64, decimals int) string {
+	if decimals == 0 {
+		return fmt.Sprint(amount)
+	}
+	unit := int64(1)
+	for i := 0; i < decimals; i++ {
+		unit *= 10
+	}
+	return fmt.Sprintf("%d.%0*d", amount/unit, decimals, amount%unit)
+}
+
+// sqlQuote returns a SQL string literal
+func sqlQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// getFakeDataSQL returns the statements replacing the demo tables with the generated rows
+func getFakeDataSQL(dbType, prefix string, locale FakeLocale, users []FakeUser, orders []FakeOrder) string {
+	timestamp := "TIMESTAMP"
+	if dbType != "postgres" {
+		timestamp = "DATETIME"
+	}
+	usersTable, ordersTable := prefix+"users", prefix+"orders"
+
+	var sql strings.Builder
+	fmt.Fprintf(&sql, "DROP TABLE IF EXISTS %s;\nDROP TABLE IF EXISTS %s;\n", ordersTable, usersTable)
+	fmt.Fprintf(&sql, "CREATE TABLE %s (id INTEGER PRIMARY KEY, first_name VARCHAR(100) NOT NULL, last_name VARCHAR(100) NOT NULL, "+
+		"email VARCHAR(255) NOT NULL UNIQUE, phone VARCHAR(50), city VARCHAR(100), country CHAR(2), created_at %s NOT NULL);\n", usersTable, timestamp)
+	fmt.Fprintf(&sql, "CREATE TABLE %s (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL REFERENCES %s (id), number VARCHAR(20) NOT NULL UNIQUE, "+
+		"total DECIMAL(14,2) NOT NULL, currency CHAR(3) NOT NULL, status VARCHAR(20) NOT NULL, created_at %s NOT NULL);\n", ordersTable, usersTable, timestamp)
+
+	const timeFormat = "2006-01-02 15:04:05"
+	for start := 0; start < len(users); start += fakeDataBatchSize {
+		end := min(start+fakeDataBatchSize, len(users))
+		fmt.Fprintf(&sql, "INSERT INTO %s (id, first_name, last_name, email, phone, city, country, created_at) VALUES\n", usersTable)
+		for i, user := range users[start:end] {
+			if i > 0 {
+				sql.WriteString(",\n")
+			}
+			fmt.Fprintf(&sql, "(%d, %s, %s, %s, %s, %s, %s, %s)", user.ID, sqlQuote(user.FirstName), sqlQuote(user.LastName),
+				sqlQuote(user.Email), sqlQuote(user.Phone), sqlQuote(user.City), sqlQuote(user.Country), sqlQuote(user.CreatedAt.Format(timeFormat)))
+		}
+		sql.WriteString(";\n")
+	}
+	for start := 0; start < len(orders); start += fakeDataBatchSize {
+		end := min(start+fakeDataBatchSize, len(orders))
+		fmt.Fprintf(&sql, "INSERT INTO %s (id, user_id, number, total, currency, status, created_at) VALUES\n", ordersTable)
+		for i, order := range orders[start:end] {
+			if i > 0 {
+				sql.WriteString(",\n")
+			}
+			fmt.Fprintf(&sql, "(%d, %d, %s, %s, %s, %s, %s)", order.ID, order.UserID, sqlQuote(order.Number),
+				formatFakeAmount(order.Total, locale.Decimals), sqlQuote(order.Currency), sqlQuote(order.Status), sqlQuote(order.CreatedAt.Format(timeFormat)))
+		}
+		sql.WriteString(";\n")
+	}
+	return sql.String()
+}
+
+// getSeedExec returns how to run SQL from stdin against the database of a service, as
+// the service's own user
+func getSeedExec(svc *Service) (*MaintenanceExec, error) {
+	dbType, version := parseDatabaseType(svc.Database)
+	target := getSharedDatabaseServiceName(dbType, version)
+	database := getEnvOrDefault(svc.DatabaseName, svc.Name)
+	user := getEnvOrDefault(svc.DatabaseUser, svc.Name)
+	password := getEnvOrDefault(svc.DatabasePassword, "password")
+
+	switch dbType {
+	case "postgres":
+		return &MaintenanceExec{
+			Target:  target,
+			Env:     map[string]string{"PGUSER": user, "PGPASSWORD": password},
+			Command: fmt.Sprintf("psql -q -v ON_ERROR_STOP=1 -d %s --single-transaction", shellQuote(database)),
+		}, nil
+	case "mysql", "mariadb":
+		client := "mysql"
+		if dbType == "mariadb" {
+			client = "mariadb"
+		}
+		return &MaintenanceExec{
+			Target:  target,
+			Env:     map[string]string{"MYSQL_PWD": password},
+			Command: fmt.Sprintf("%s -u%s %s", client, shellQuote(user), shellQuote(database)),
+		}, nil
+	case "":
+		return nil, fmt.Errorf("service %s has no database", svc.Name)
+	}
+	return nil, fmt.Errorf("fake data can be loaded into PostgreSQL, MySQL and MariaDB, not %s", dbType)
+}
+
+// pipeToService runs a command in a compose service with input on its stdin
+var pipeToService = func(composeFiles ComposeFiles, exec *MaintenanceExec, input string) (string, error) {
+	cmd, err := dockerCommand(append(composeArgs(composeFiles, "exec", "-T", exec.Target), getMaintenanceExecArgs(exec)...)...)
+	if err != nil {
+		return "", err
+	}
+	cmd.Stdin = strings.NewReader(input)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+func handleSeed() {
+	if len(os.Args) < 3 {
+		printSeedUsage()
+		os.Exit(1)
+	}
+
+	switch os.Args[2] {
+	case "fake":
+		handleSeedFake(os.Args[3:])
+	case "help":
+		printSeedUsage()
+	default:
+		fmt.Printf("Unknown seed command: %s\n\n", os.Args[2])
+		printSeedUsage()
+		os.Exit(1)
+	}
+}
+
+func printSeedUsage() {
+	fmt.Println("Fleet seed - Fill the databases of services with data")
+	fmt.Println("\nUsage: fleet seed <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  fake  Generate demo users and orders into a service's database")
+	fmt.Println("\nOptions of fake:")
+	fmt.Println("  --service <name>  Service whose database to fill (default: the only service with a database)")
+	fmt.Println("  --rows <n>        Users and orders to generate (default: 100)")
+	fmt.Printf("  --locale <name>   Names, cities, phones and currency of the data: %s (default: en_US)\n", strings.Join(getFakeLocaleNames(), ", "))
+	fmt.Println("  --seed <n>        Generate the same data on every run")
+	fmt.Println("  --prefix <name>   Prefix of the demo tables (default: demo_)")
+	fmt.Println("  --sql             Print the SQL instead of running it")
+	fmt.Println("  -f, --file        Specify config file (default: fleet.toml)")
+	fmt.Println("\nThe demo tables are replaced on every run, other tables are left alone.")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet seed fake --service api --rows 1000")
+	fmt.Println("  fleet seed fake --service shop --locale fr_FR --seed 42")
+}
+
+func handleSeedFake(args []string) {
+	fs := newCommandFlagSet("seed fake", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	serviceName := fs.String("service", "", "Service whose database to fill")
+	rows := fs.Int("rows", 100, "Users and orders to generate")
+	locale := fs.String("locale", "en_US", "Locale of the data")
+	seed := fs.Int64("seed", 0, "Generate the same data on every run")
+	prefix := fs.String("prefix", "demo_", "Prefix of the demo tables")
+	printSQL := fs.Bool("sql", false, "Print the SQL instead of running it")
+	fs.Usage = printSeedUsage
+
+	fs.Parse(args)
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+	if !tablePrefixPattern.MatchString(*prefix) {
+		log.Fatalf("❌ Invalid prefix %q, use letters, digits and _", *prefix)
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+
+	var svc *Service
+	if *serviceName != "" {
+		if svc = findService(config, *serviceName); svc == nil {
+			log.Fatalf("❌ Service %s not found in %s", *serviceName, *configFile)
+		}
+	} else {
+		for i := range config.Services {
+			if config.Services[i].Database == "" {
+				continue
+			}
+			if svc != nil {
+				log.Fatalf("❌ Several services have a database, choose one with --service")
+			}
+			svc = &config.Services[i]
+		}
+		if svc == nil {
+			log.Fatalf("❌ No service has a database")
+		}
+	}
+
+	exec, err := getSeedExec(svc)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	options := FakeDataOptions{Rows: *rows, Locale: *locale, Seed: *seed, Now: time.Now().UTC()}
+	if options.Seed == 0 {
+		options.Seed = time.Now().UnixNano()
+	}
+	users, orders, err := generateFakeData(options)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	dbType, _ := parseDatabaseType(svc.Database)
+	sql := getFakeDataSQL(dbType, *prefix, fakeLocales[*locale], users, orders)
+
+	if *printSQL {
+		fmt.Print(sql)
+		return
+	}
+
+	infof("🌱 Generating %d %s users and orders into the database of %s...\n", *rows, *locale, svc.Name)
+	if _, err := pipeToService(getComposeFiles(config), exec, sql); err != nil {
+		log.Fatalf("❌ Failed to load the data (is %s running?): %v", exec.Target, err)
+	}
+	infof("✅ Filled %susers and %sorders\n", *prefix, *prefix)
+}
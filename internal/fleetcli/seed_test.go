@@ -0,0 +1,116 @@
+package fleetcli
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SeedTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *SeedTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *SeedTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *SeedTestSuite) TestGenerateFakeData() {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	options := FakeDataOptions{Rows: 50, Locale: "de_DE", Seed: 42, Now: now}
+	users, orders, err := generateFakeData(options)
+	suite.Require().NoError(err)
+	suite.Len(users, 50)
+	suite.Len(orders, 50)
+
+	again, _, _ := generateFakeData(options)
+	suite.Equal(users, again, "The same seed generates the same data")
+
+	emails := make(map[string]bool)
+	for _, user := range users {
+		suite.Equal("DE", user.Country)
+		suite.Regexp(`^[a-z.]+[0-9]+@beispiel\.de$`, user.Email, "Addresses are ASCII")
+		suite.Regexp(`^0151 [0-9]{8}$`, user.Phone)
+		suite.False(user.CreatedAt.After(now))
+		emails[user.Email] = true
+	}
+	suite.Len(emails, 50, "Addresses are unique")
+
+	for _, order := range orders {
+		suite.Equal("EUR", order.Currency)
+		suite.GreaterOrEqual(order.UserID, 1)
+		suite.LessOrEqual(order.UserID, 50)
+		suite.False(order.CreatedAt.Before(users[order.UserID-1].CreatedAt), "Orders follow the signup")
+		suite.False(order.CreatedAt.After(now))
+	}
+
+	_, _, err = generateFakeData(FakeDataOptions{Rows: 10, Locale: "xx_XX"})
+	suite.ErrorContains(err, "unsupported locale \"xx_XX\"")
+	_, _, err = generateFakeData(FakeDataOptions{Rows: 0, Locale: "en_US"})
+	suite.Error(err)
+}
+
+func (suite *SeedTestSuite) TestFormatFakeAmount() {
+	suite.Equal("12.05", formatFakeAmount(1205, 2))
+	suite.Equal("0.99", formatFakeAmount(99, 2))
+	suite.Equal("4500", formatFakeAmount(4500, 0))
+}
+
+func (suite *SeedTestSuite) TestGetFakeDataSQL() {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	users := []FakeUser{{ID: 1, FirstName: "Saoirse", LastName: "O'Brien", Email: "saoirse.o'brien1@example.com", Country: "GB", CreatedAt: created}}
+	orders := []FakeOrder{{ID: 1, UserID: 1, Number: "GB-000001", Total: 1999, Currency: "GBP", Status: "paid", CreatedAt: created}}
+
+	sql := getFakeDataSQL("postgres", "demo_", fakeLocales["en_GB"], users, orders)
+	suite.Contains(sql, "DROP TABLE IF EXISTS demo_orders;\nDROP TABLE IF EXISTS demo_users;\n", "Orders are dropped before the users they reference")
+	suite.Contains(sql, "created_at TIMESTAMP NOT NULL")
+	suite.Contains(sql, "(1, 'Saoirse', 'O''Brien', 'saoirse.o''brien1@example.com', '', '', 'GB', '2026-01-02 03:04:05');\n")
+	suite.Contains(sql, "(1, 1, 'GB-000001', 19.99, 'GBP', 'paid', '2026-01-02 03:04:05');\n")
+
+	sql = getFakeDataSQL("mysql", "fake_", fakeLocales["ja_JP"], users, orders)
+	suite.Contains(sql, "CREATE TABLE fake_users")
+	suite.Contains(sql, "created_at DATETIME NOT NULL")
+	suite.Contains(sql, "(1, 1, 'GB-000001', 1999, ")
+
+	many := make([]FakeUser, fakeDataBatchSize+1)
+	for i := range many {
+		many[i] = FakeUser{ID: i + 1, CreatedAt: created}
+	}
+	sql = getFakeDataSQL("postgres", "demo_", fakeLocales["en_US"], many, nil)
+	suite.Equal(2, strings.Count(sql, "INSERT INTO demo_users"), "Rows are inserted in batches")
+}
+
+func (suite *SeedTestSuite) TestGetSeedExec() {
+	exec, err := getSeedExec(&Service{Name: "api", Database: "postgres:16"})
+	suite.Require().NoError(err)
+	suite.Equal("postgres-16", exec.Target)
+	suite.Equal(map[string]string{"PGUSER": "api", "PGPASSWORD": "password"}, exec.Env)
+	suite.Equal("psql -q -v ON_ERROR_STOP=1 -d 'api' --single-transaction", exec.Command)
+
+	exec, err = getSeedExec(&Service{Name: "shop", Database: "mariadb:11", DatabaseName: "store", DatabasePassword: "secret"})
+	suite.Require().NoError(err)
+	suite.Equal(map[string]string{"MYSQL_PWD": "secret"}, exec.Env)
+	suite.Equal("mariadb -u'shop' 'store'", exec.Command)
+
+	_, err = getSeedExec(&Service{Name: "api", Database: "mongodb:7"})
+	suite.ErrorContains(err, "not mongodb")
+	_, err = getSeedExec(&Service{Name: "api"})
+	suite.ErrorContains(err, "has no database")
+}
+
+func TestSeedSuite(t *testing.T) {
+	suite.Run(t, new(SeedTestSuite))
+}
@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 // ServiceProvider defines the interface for all service providers
 // This standardizes how different service types are handled in Fleet
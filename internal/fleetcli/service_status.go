@@ -0,0 +1,217 @@
+package fleetcli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// Roles of the containers Fleet runs next to an app service
+const (
+	statusRolePHP       = "php"
+	statusRoleNode      = "node"
+	statusRoleAssets    = "assets"
+	statusRoleReverb    = "reverb"
+	statusRoleHorizon   = "horizon"
+	statusRoleWorker    = "worker"
+	statusRoleBackup    = "backup"
+	statusRoleInit      = "init"
+	statusRoleScheduler = "scheduler"
+)
+
+// StatusMember is a compose service shown by fleet status, with its role for sidecars
+type StatusMember struct {
+	Service string
+	Role    string
+}
+
+// StatusGroup is a service of fleet.toml, or a shared service, and the sidecar
+// containers Fleet runs for it
+type StatusGroup struct {
+	StatusMember
+	Sidecars []StatusMember
+}
+
+// statusCheck is an app level health check run in a running sidecar
+type statusCheck struct {
+	Command string
+	OK      string
+	Failed  string
+}
+
+// statusChecks are the health checks of sidecar roles. Roles without one show the
+// container state only.
+var statusChecks = map[string]statusCheck{
+	// Reverb always listens on 8080 in its container, see addReverbService
+	statusRoleReverb: {
+		Command: `php -r 'exit(@fsockopen("127.0.0.1", 8080) ? 0 : 1);'`,
+		OK:      "websocket port 8080 open",
+		Failed:  "websocket port 8080 closed",
+	},
+	statusRoleHorizon: {
+		Command: "php artisan horizon:status",
+		OK:      "workers running",
+		Failed:  "horizon inactive",
+	},
+	statusRoleBackup: {
+		Command: "pidof crond",
+		OK:      "schedule active",
+		Failed:  "crond not running",
+	},
+	statusRoleScheduler: {
+		Command: "pidof crond",
+		OK:      "schedule active",
+		Failed:  "crond not running",
+	},
+}
+
+// groupStatusServices groups the compose services under the fleet.toml service they
+// belong to. Services no app owns, like shared databases, get a group of their own.
+func groupStatusServices(config *Config, compose *DockerCompose) []StatusGroup {
+	claimed := make(map[string]bool)
+	claim := func(name string) bool {
+		if _, exists := compose.Services[name]; !exists || claimed[name] {
+			return false
+		}
+		claimed[name] = true
+		return true
+	}
+
+	var groups []StatusGroup
+	for _, svc := range config.Services {
+		group := StatusGroup{StatusMember: StatusMember{Service: svc.Name}}
+		hasMain := claim(svc.Name)
+
+		sidecars := []StatusMember{
+			{fmt.Sprintf("%s-php", svc.Name), statusRolePHP},
+			{fmt.Sprintf("%s-node", svc.Name), statusRoleNode},
+			{getAssetsServiceName(svc.Name), statusRoleAssets},
+		}
+		if svc.Reverb {
+			// Reverb is shared, it shows under the first app using it
+			sidecars = append(sidecars, StatusMember{"reverb", statusRoleReverb})
+		}
+		if svc.Horizon {
+			sidecars = append(sidecars, StatusMember{getHorizonServiceName(svc.Name), statusRoleHorizon})
+		}
+		for i, worker := range svc.Workers {
+			sidecars = append(sidecars, StatusMember{getWorkerServiceName(svc.Name, i, worker), statusRoleWorker})
+		}
+		sidecars = append(sidecars, StatusMember{getBackupServiceName(svc.Name), statusRoleBackup})
+		for i, init := range svc.Init {
+			sidecars = append(sidecars, StatusMember{getInitContainerName(svc.Name, i, init), statusRoleInit})
+		}
+
+		for _, sidecar := range sidecars {
+			if claim(sidecar.Service) {
+				group.Sidecars = append(group.Sidecars, sidecar)
+			}
+		}
+		if hasMain || len(group.Sidecars) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	var shared []string
+	for name := range compose.Services {
+		if !claimed[name] {
+			shared = append(shared, name)
+		}
+	}
+	sort.Strings(shared)
+	for _, name := range shared {
+		group := StatusGroup{StatusMember: StatusMember{Service: name}}
+		if name == maintenanceServiceName {
+			group.Role = statusRoleScheduler
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// describeContainerState summarizes the compose state of a service
+func describeContainerState(entry *ComposePSEntry) string {
+	if entry == nil {
+		return "not created"
+	}
+	switch entry.State {
+	case "running":
+		if entry.Health != "" {
+			return fmt.Sprintf("running (%s)", entry.Health)
+		}
+		return "running"
+	case "exited":
+		return fmt.Sprintf("exited (%d)", entry.ExitCode)
+	default:
+		return entry.State
+	}
+}
+
+// describeMemberHealth returns the app level health of a service: the result of its
+// role's check while it runs, and whether an init container completed
+func describeMemberHealth(member StatusMember, entry *ComposePSEntry, run func(service, command string) error) string {
+	if entry == nil {
+		return ""
+	}
+	if member.Role == statusRoleInit {
+		if entry.State == "exited" && entry.ExitCode == 0 {
+			return "completed"
+		}
+		if entry.State == "exited" {
+			return "failed"
+		}
+		return ""
+	}
+
+	check, exists := statusChecks[member.Role]
+	if !exists || entry.State != "running" {
+		return ""
+	}
+	if err := run(member.Service, check.Command); err != nil {
+		return check.Failed
+	}
+	return check.OK
+}
+
+// printServiceStatus prints the services of the project grouped by app, with the app
+// level health of their sidecars
+func printServiceStatus(config *Config, composeFiles ComposeFiles) error {
+	compose, err := readComposeFiles(composeFiles)
+	if err != nil {
+		return err
+	}
+	entries, err := getComposeServiceStates(composeFiles)
+	if err != nil {
+		return err
+	}
+
+	states := make(map[string]*ComposePSEntry, len(entries))
+	for i := range entries {
+		states[entries[i].Service] = &entries[i]
+	}
+
+	run := func(service, command string) error {
+		cmd, err := dockerCommand(composeArgs(composeFiles, "exec", "-T", service, "sh", "-c", command)...)
+		if err != nil {
+			return err
+		}
+		return cmd.Run()
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tROLE\tSTATE\tHEALTH")
+	for _, group := range groupStatusServices(config, compose) {
+		members := append([]StatusMember{group.StatusMember}, group.Sidecars...)
+		for i, member := range members {
+			name := member.Service
+			if i > 0 {
+				name = "  " + name
+			}
+			entry := states[member.Service]
+			health := describeMemberHealth(member, entry, run)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, member.Role, describeContainerState(entry), health)
+		}
+	}
+	return w.Flush()
+}
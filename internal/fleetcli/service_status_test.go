@@ -0,0 +1,85 @@
+package fleetcli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ServiceStatusTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+}
+
+func (suite *ServiceStatusTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+}
+
+func (suite *ServiceStatusTestSuite) TearDownTest() {
+	suite.helper.Cleanup()
+}
+
+func (suite *ServiceStatusTestSuite) TestGroupStatusServices() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "web", Reverb: true, Init: []InitContainer{{Command: "php artisan migrate"}}},
+			{Name: "admin", Reverb: true},
+			{Name: "db", Database: "postgres:16", BackupSchedule: "0 3 * * *"},
+		},
+	}
+	compose := &DockerCompose{Services: map[string]DockerService{
+		"web":               {},
+		"web-php":           {},
+		"web-assets":        {},
+		"web-init-1":        {},
+		"admin":             {},
+		"reverb":            {},
+		"db-backup":         {},
+		"postgres-16":       {},
+		"fleet-maintenance": {},
+	}}
+
+	groups := groupStatusServices(config, compose)
+
+	suite.Equal([]StatusGroup{
+		{StatusMember: StatusMember{Service: "web"}, Sidecars: []StatusMember{
+			{"web-php", statusRolePHP},
+			{"web-assets", statusRoleAssets},
+			{"reverb", statusRoleReverb},
+			{"web-init-1", statusRoleInit},
+		}},
+		{StatusMember: StatusMember{Service: "admin"}},
+		{StatusMember: StatusMember{Service: "db"}, Sidecars: []StatusMember{{"db-backup", statusRoleBackup}}},
+		{StatusMember: StatusMember{Service: "fleet-maintenance", Role: statusRoleScheduler}},
+		{StatusMember: StatusMember{Service: "postgres-16"}},
+	}, groups, "Reverb shows once, a service without a container shows its sidecars")
+}
+
+func (suite *ServiceStatusTestSuite) TestDescribeMemberHealth() {
+	var ran []string
+	succeed := func(service, command string) error {
+		ran = append(ran, service)
+		return nil
+	}
+	fail := func(service, command string) error { return errors.New("exit status 1") }
+
+	reverb := StatusMember{"reverb", statusRoleReverb}
+	running := &ComposePSEntry{Service: "reverb", State: "running"}
+	suite.Equal("websocket port 8080 open", describeMemberHealth(reverb, running, succeed))
+	suite.Equal("websocket port 8080 closed", describeMemberHealth(reverb, running, fail))
+	suite.Equal("", describeMemberHealth(reverb, &ComposePSEntry{State: "exited", ExitCode: 255}, succeed), "Stopped containers aren't checked")
+	suite.Equal("", describeMemberHealth(StatusMember{"web-php", statusRolePHP}, running, succeed), "Roles without a check show their state only")
+	suite.Equal([]string{"reverb"}, ran)
+
+	init := StatusMember{"web-init-1", statusRoleInit}
+	suite.Equal("completed", describeMemberHealth(init, &ComposePSEntry{State: "exited"}, succeed))
+	suite.Equal("failed", describeMemberHealth(init, &ComposePSEntry{State: "exited", ExitCode: 1}, succeed))
+	suite.Equal("not created", describeContainerState(nil))
+	suite.Equal("running (healthy)", describeContainerState(&ComposePSEntry{State: "running", Health: "healthy"}))
+}
+
+func TestServiceStatusSuite(t *testing.T) {
+	suite.Run(t, new(ServiceStatusTestSuite))
+}
@@ -0,0 +1,329 @@
+package fleetcli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ServiceTemplate describes a reusable [[services]] block that can be added with `fleet add`
+type ServiceTemplate struct {
+	Name        string           `toml:"-"`
+	Description string           `toml:"description"`
+	Body        string           `toml:"body"`
+	Prompts     []TemplatePrompt `toml:"prompts"`
+}
+
+// TemplatePrompt is a value the user is asked for before the template is rendered
+type TemplatePrompt struct {
+	Key     string `toml:"key"`
+	Message string `toml:"message"`
+	Default string `toml:"default"`
+}
+
+// templateData is passed to the template body and prompt defaults
+type templateData struct {
+	Name   string
+	Values map[string]string
+}
+
+// parseServiceTemplate parses a template definition file
+func parseServiceTemplate(name string, data []byte) (*ServiceTemplate, error) {
+	var tmpl ServiceTemplate
+	if err := toml.Unmarshal(data, &tmpl); err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	if strings.TrimSpace(tmpl.Body) == "" {
+		return nil, fmt.Errorf("template %s has no body", name)
+	}
+	tmpl.Name = name
+	return &tmpl, nil
+}
+
+// listEmbeddedTemplates returns all templates bundled with Fleet, sorted by name
+func listEmbeddedTemplates() ([]*ServiceTemplate, error) {
+	entries, err := serviceTemplatesFS.ReadDir("templates/services")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded templates: %w", err)
+	}
+
+	var templates []*ServiceTemplate
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".toml" {
+			continue
+		}
+		tmpl, err := loadEmbeddedTemplate(strings.TrimSuffix(entry.Name(), ".toml"))
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tmpl)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// loadEmbeddedTemplate loads a bundled template by name
+func loadEmbeddedTemplate(name string) (*ServiceTemplate, error) {
+	data, err := serviceTemplatesFS.ReadFile(fmt.Sprintf("templates/services/%s.toml", name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown template: %s (run 'fleet add --list' to see available templates)", name)
+	}
+	return parseServiceTemplate(name, data)
+}
+
+// loadTemplateFromGit clones a template repository and loads <name>.toml from it
+func loadTemplateFromGit(repoURL, name string) (*ServiceTemplate, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is required to fetch templates from %s", repoURL)
+	}
+
+	tempDir, err := os.MkdirTemp("", "fleet-templates-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cmd := newCommand("git", "clone", "--depth", "1", "--quiet", "--", repoURL, tempDir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %v\n%s", repoURL, err, output)
+	}
+
+	// Templates may live at the repository root or in a templates/ folder
+	for _, candidate := range []string{
+		filepath.Join(tempDir, name+".toml"),
+		filepath.Join(tempDir, "templates", name+".toml"),
+	} {
+		if data, err := os.ReadFile(candidate); err == nil {
+			return parseServiceTemplate(name, data)
+		}
+	}
+
+	return nil, fmt.Errorf("template %s not found in %s", name, repoURL)
+}
+
+// renderString executes a small text/template snippet with the given data
+func renderString(name, text string, data templateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// ResolveValues fills in prompt values from overrides, the asker, or defaults
+func (t *ServiceTemplate) ResolveValues(serviceName string, overrides map[string]string, ask func(TemplatePrompt) (string, error)) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, prompt := range t.Prompts {
+		if value, ok := overrides[prompt.Key]; ok {
+			values[prompt.Key] = value
+			continue
+		}
+
+		// Defaults may reference the service name (e.g. "{{.Name}}.test")
+		def, err := renderString(prompt.Key, prompt.Default, templateData{Name: serviceName, Values: values})
+		if err != nil {
+			return nil, err
+		}
+		prompt.Default = def
+
+		if ask != nil {
+			answer, err := ask(prompt)
+			if err != nil {
+				return nil, err
+			}
+			if answer != "" {
+				def = answer
+			}
+		}
+		values[prompt.Key] = def
+	}
+	return values, nil
+}
+
+// Render produces the [[services]] block for the given service name and values
+func (t *ServiceTemplate) Render(serviceName string, values map[string]string) (string, error) {
+	block, err := renderString(t.Name, t.Body, templateData{Name: serviceName, Values: values})
+	if err != nil {
+		return "", err
+	}
+
+	// Make sure the rendered block is valid configuration before touching fleet.toml
+	var parsed Config
+	if err := toml.Unmarshal([]byte(block), &parsed); err != nil {
+		return "", fmt.Errorf("template %s rendered invalid TOML: %w", t.Name, err)
+	}
+	if len(parsed.Services) == 0 {
+		return "", fmt.Errorf("template %s does not define a [[services]] block", t.Name)
+	}
+
+	return strings.TrimSpace(block) + "\n", nil
+}
+
+// appendServiceBlock appends a rendered service block to the config file
+func appendServiceBlock(configFile, serviceName, block string) error {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var existing Config
+	if err := toml.Unmarshal(content, &existing); err != nil {
+		return fmt.Errorf("failed to parse config: %w", err)
+	}
+	for _, svc := range existing.Services {
+		if svc.Name == serviceName {
+			return fmt.Errorf("service %s already exists in %s", serviceName, configFile)
+		}
+	}
+
+	text := string(content)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	text += "\n" + block
+
+	return os.WriteFile(configFile, []byte(text), 0644)
+}
+
+// askTemplatePrompt asks a single template prompt interactively
+func askTemplatePrompt(prompt TemplatePrompt) (string, error) {
+	var answer string
+	err := survey.AskOne(&survey.Input{
+		Message: prompt.Message + ":",
+		Default: prompt.Default,
+	}, &answer)
+	return answer, err
+}
+
+func handleAdd() {
+	fs := newCommandFlagSet("add", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	name := fs.String("name", "", "Service name (defaults to the template name)")
+	from := fs.String("from", "", "Git repository to fetch the template from")
+	list := fs.Bool("list", false, "List available templates")
+	yes := fs.Bool("yes", false, "Accept defaults without prompting")
+	var sets stringSliceFlag
+	fs.Var(&sets, "set", "Set a template value (key=value), can be repeated")
+
+	// Allow `fleet add <template> --name api` as well as flags before the template
+	args := os.Args[2:]
+	var templateName string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		templateName = args[0]
+		args = args[1:]
+	}
+	fs.Parse(args)
+	if templateName == "" && fs.NArg() > 0 {
+		templateName = fs.Arg(0)
+	}
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	if *list || templateName == "" {
+		printServiceTemplates()
+		return
+	}
+
+	var tmpl *ServiceTemplate
+	var err error
+	if *from != "" {
+		tmpl, err = loadTemplateFromGit(*from, templateName)
+	} else {
+		tmpl, err = loadEmbeddedTemplate(templateName)
+	}
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	serviceName := *name
+	if serviceName == "" {
+		serviceName = templateName
+	}
+
+	overrides := make(map[string]string)
+	for _, kv := range sets {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			log.Fatalf("❌ Invalid --set value %q (expected key=value)", kv)
+		}
+		overrides[parts[0]] = parts[1]
+	}
+
+	var ask func(TemplatePrompt) (string, error)
+	if !*yes && isInteractiveTerminal() {
+		ask = askTemplatePrompt
+	}
+
+	values, err := tmpl.ResolveValues(serviceName, overrides, ask)
+	if err != nil {
+		log.Fatalf("❌ Error reading template values: %v", err)
+	}
+
+	block, err := tmpl.Render(serviceName, values)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if err := appendServiceBlock(*configFile, serviceName, block); err != nil {
+		log.Fatalf("❌ Error adding service: %v", err)
+	}
+
+	infof("✅ Added service '%s' from template '%s' to %s\n\n", serviceName, tmpl.Name, *configFile)
+	infoln(block)
+	infoln("Run 'fleet up' to start the new service")
+}
+
+// printServiceTemplates prints the bundled templates
+func printServiceTemplates() {
+	templates, err := listEmbeddedTemplates()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	fmt.Println("Available service templates:")
+	for _, tmpl := range templates {
+		fmt.Printf("  %-14s %s\n", tmpl.Name, tmpl.Description)
+	}
+	fmt.Println("\nUsage: fleet add <template> [--name <service>] [--set key=value] [--from <git-url>]")
+}
+
+// stringSliceFlag collects repeated string flags
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// isInteractiveTerminal reports whether stdin is attached to a terminal
+func isInteractiveTerminal() bool {
+	fileInfo, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return (fileInfo.Mode() & os.ModeCharDevice) != 0
+}
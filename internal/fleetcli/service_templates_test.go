@@ -0,0 +1,140 @@
+package fleetcli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServiceTemplatesTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+}
+
+func (suite *ServiceTemplatesTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+}
+
+func (suite *ServiceTemplatesTestSuite) TearDownTest() {
+	suite.helper.Cleanup()
+}
+
+func (suite *ServiceTemplatesTestSuite) TestEmbeddedTemplatesAreValid() {
+	templates, err := listEmbeddedTemplates()
+	suite.Require().NoError(err)
+	suite.NotEmpty(templates)
+
+	for _, tmpl := range templates {
+		suite.Run(tmpl.Name, func() {
+			suite.NotEmpty(tmpl.Description)
+
+			values, err := tmpl.ResolveValues("svc", nil, nil)
+			suite.Require().NoError(err)
+
+			block, err := tmpl.Render("svc", values)
+			suite.Require().NoError(err)
+
+			var config Config
+			suite.Require().NoError(toml.Unmarshal([]byte(block), &config))
+			suite.Len(config.Services, 1)
+			suite.Equal("svc", config.Services[0].Name)
+			suite.NoError(Validate(&config))
+		})
+	}
+}
+
+func (suite *ServiceTemplatesTestSuite) TestUnknownTemplate() {
+	_, err := loadEmbeddedTemplate("does-not-exist")
+	suite.Error(err)
+	suite.Contains(err.Error(), "unknown template")
+}
+
+func (suite *ServiceTemplatesTestSuite) TestResolveValues() {
+	tmpl, err := loadEmbeddedTemplate("laravel-api")
+	suite.Require().NoError(err)
+
+	// Defaults reference the service name
+	values, err := tmpl.ResolveValues("api", nil, nil)
+	suite.Require().NoError(err)
+	suite.Equal("./api", values["folder"])
+	suite.Equal("api.test", values["domain"])
+
+	// Overrides win over defaults
+	values, err = tmpl.ResolveValues("api", map[string]string{"domain": "backend.test"}, nil)
+	suite.Require().NoError(err)
+	suite.Equal("backend.test", values["domain"])
+
+	// Answers from the asker replace defaults, empty answers keep them
+	values, err = tmpl.ResolveValues("api", nil, func(p TemplatePrompt) (string, error) {
+		if p.Key == "php" {
+			return "8.2", nil
+		}
+		return "", nil
+	})
+	suite.Require().NoError(err)
+	suite.Equal("8.2", values["php"])
+	suite.Equal("./api", values["folder"])
+}
+
+func (suite *ServiceTemplatesTestSuite) TestRenderRejectsInvalidTOML() {
+	tmpl, err := parseServiceTemplate("broken", []byte(`description = "broken"
+body = "[[services]\nname = {{.Name}}"
+`))
+	suite.Require().NoError(err)
+
+	_, err = tmpl.Render("svc", map[string]string{})
+	suite.Error(err)
+}
+
+func (suite *ServiceTemplatesTestSuite) TestAppendServiceBlock() {
+	configPath := suite.helper.CreateFile("fleet.toml", `project = "demo"
+
+[[services]]
+name = "web"
+image = "nginx:alpine"`)
+
+	tmpl, err := loadEmbeddedTemplate("node-api")
+	suite.Require().NoError(err)
+	values, err := tmpl.ResolveValues("api", nil, nil)
+	suite.Require().NoError(err)
+	block, err := tmpl.Render("api", values)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(appendServiceBlock(configPath, "api", block))
+
+	config, err := loadConfig(configPath)
+	suite.Require().NoError(err)
+	suite.Len(config.Services, 2)
+	suite.Equal("api", config.Services[1].Name)
+	suite.Equal("node:20", config.Services[1].Runtime)
+
+	// Original content is preserved verbatim
+	content, err := os.ReadFile(configPath)
+	suite.Require().NoError(err)
+	suite.Contains(string(content), "name = \"web\"\nimage = \"nginx:alpine\"\n")
+
+	// Adding the same service twice fails
+	err = appendServiceBlock(configPath, "api", block)
+	suite.Error(err)
+	suite.Contains(err.Error(), "already exists")
+}
+
+func (suite *ServiceTemplatesTestSuite) TestLoadTemplateFromGitRejectsOptions() {
+	if _, err := exec.LookPath("git"); err != nil {
+		suite.T().Skip("git is not installed")
+	}
+
+	// A repository looking like an option is still a repository
+	marker := filepath.Join(suite.helper.TempDir(), "injected")
+	_, err := loadTemplateFromGit("--upload-pack=touch "+marker, "redis")
+	suite.ErrorContains(err, "repository '--upload-pack=touch "+marker+"' does not exist")
+	suite.NoFileExists(marker)
+}
+
+func TestServiceTemplatesSuite(t *testing.T) {
+	suite.Run(t, new(ServiceTemplatesTestSuite))
+}
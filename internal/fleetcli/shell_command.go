@@ -0,0 +1,117 @@
+package fleetcli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// getShellWorkdir returns the folder a shell in a service opens in: where the code of the
+// service is mounted, or the working directory of its image for other services
+func getShellWorkdir(config *Config, compose *DockerCompose, target string) string {
+	if service, ok := compose.Services[target]; ok && service.WorkingDir != "" {
+		return service.WorkingDir
+	}
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if getAppServiceName(svc) != target || svc.Folder == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(svc.Runtime, "php"):
+			return "/var/www/html"
+		case strings.Contains(strings.ToLower(svc.Image), "nginx"):
+			return "/usr/share/nginx/html"
+		default:
+			return "/app"
+		}
+	}
+	return ""
+}
+
+// getShellArgs returns the docker arguments opening an interactive shell in a service
+func getShellArgs(files ComposeFiles, target, user, workdir string, tty bool) []string {
+	args := composeArgs(files, "exec")
+	if !tty {
+		args = append(args, "-T")
+	}
+	if user != "" {
+		args = append(args, "--user", user)
+	}
+	if workdir != "" {
+		args = append(args, "--workdir", workdir)
+	}
+	args = append(args, target)
+	return append(args, defaultExecShell...)
+}
+
+func printShellUsage() {
+	fmt.Println("Fleet shell - Open a shell in a service")
+	fmt.Println("\nUsage: fleet shell [options] <service>")
+	fmt.Println("\nOpens bash, or sh when the image has no bash, in the folder the code of the")
+	fmt.Println("service is mounted in: /var/www/html for PHP services, in their PHP container,")
+	fmt.Println("and /app for the others. Shared services like postgres-16 work too.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -u, --user  Open the shell as this user (name or uid[:gid])")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet shell shop           # Shell in the PHP container of 'shop'")
+	fmt.Println("  fleet shell -u root api    # Shell as root")
+	fmt.Println("\nRun 'fleet exec help' to run a single command instead")
+}
+
+func handleShell() {
+	if len(os.Args) > 2 && os.Args[2] == "help" {
+		printShellUsage()
+		return
+	}
+
+	fs := newCommandFlagSet("shell", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	user := fs.String("u", "", "User")
+	userLong := fs.String("user", "", "User")
+	fs.Usage = printShellUsage
+
+	fs.Parse(os.Args[2:])
+	if fs.NArg() != 1 {
+		printShellUsage()
+		os.Exit(1)
+	}
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+	if *userLong != "" {
+		*user = *userLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+
+	files := getComposeFiles(config)
+	compose, err := readComposeFiles(files)
+	if err != nil {
+		log.Fatalf("❌ Error reading the compose file, start the services with 'fleet up' first: %v", err)
+	}
+	target, err := resolveExecService(config, compose, fs.Arg(0))
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	tty := isInteractiveTerminal() && isOutputTerminal()
+	if err := runDocker(getShellArgs(files, target, *user, getShellWorkdir(config, compose, target), tty)); err != nil {
+		// The shell exits with the code of its last command
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		log.Fatalf("❌ Error opening a shell in %s: %v", target, err)
+	}
+}
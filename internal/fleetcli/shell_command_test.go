@@ -0,0 +1,64 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ShellCommandTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *ShellCommandTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *ShellCommandTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *ShellCommandTestSuite) TestGetShellWorkdir() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Runtime: "node:20", Folder: "api", Port: 3000, Database: "postgres:16"},
+		{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "shop"},
+		{Name: "site", Image: "nginx:alpine", Folder: "site"},
+		{Name: "worker", Image: "python:3.12", Folder: "worker"},
+		{Name: "cache", Image: "redis:7"},
+	}}
+	compose := generateDockerCompose(config)
+
+	testCases := map[string]string{
+		"api":         "/app",
+		"shop-php":    "/var/www/html",
+		"site":        "/usr/share/nginx/html",
+		"worker":      "/app",
+		"cache":       "",
+		"postgres-16": "",
+	}
+	for target, expected := range testCases {
+		suite.Equal(expected, getShellWorkdir(config, compose, target), target)
+	}
+}
+
+func (suite *ShellCommandTestSuite) TestGetShellArgs() {
+	files := ComposeFiles{ProjectDir: ".fleet", Files: []string{".fleet/docker-compose.yml"}}
+
+	args := getShellArgs(files, "shop-php", "", "/var/www/html", true)
+	suite.Equal(append(composeArgs(files, "exec", "--workdir", "/var/www/html", "shop-php"), defaultExecShell...), args)
+
+	args = getShellArgs(files, "postgres-16", "root", "", false)
+	suite.Equal(append(composeArgs(files, "exec", "-T", "--user", "root", "postgres-16"), defaultExecShell...), args)
+}
+
+func TestShellCommandSuite(t *testing.T) {
+	suite.Run(t, new(ShellCommandTestSuite))
+}
@@ -0,0 +1,436 @@
+package fleetcli
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// defaultSlowQueryThreshold is how long a query runs before it is logged
+const defaultSlowQueryThreshold = 500 * time.Millisecond
+
+// slowQueryLogDir is where database containers write their slow query log
+const slowQueryLogDir = "/var/log/fleet"
+
+// SlowQuery is an entry of a slow query log
+type SlowQuery struct {
+	Time     string
+	Duration time.Duration
+	User     string
+	Database string
+	Query    string
+}
+
+// validateSlowQueryLog checks the slow_query_log settings of a service
+func validateSlowQueryLog(svc *Service) error {
+	if !svc.SlowQueryLog && svc.SlowQueryThreshold == "" {
+		return nil
+	}
+	if !svc.SlowQueryLog {
+		return fmt.Errorf("service %s: 'slow_query_threshold' requires 'slow_query_log = true'", svc.Name)
+	}
+	switch dbType, _ := parseDatabaseType(svc.Database); dbType {
+	case "mysql", "mariadb", "postgres":
+	default:
+		return fmt.Errorf("service %s: 'slow_query_log' requires a MySQL, MariaDB or PostgreSQL 'database'", svc.Name)
+	}
+	if _, err := getSlowQueryThreshold(svc); err != nil {
+		return fmt.Errorf("service %s: %w", svc.Name, err)
+	}
+	return nil
+}
+
+// getSlowQueryThreshold returns the slow_query_threshold of a service, 500ms by default
+func getSlowQueryThreshold(svc *Service) (time.Duration, error) {
+	if svc.SlowQueryThreshold == "" {
+		return defaultSlowQueryThreshold, nil
+	}
+	threshold, err := time.ParseDuration(svc.SlowQueryThreshold)
+	if err != nil || threshold < 0 {
+		return 0, fmt.Errorf("invalid slow_query_threshold %q, use a duration like 200ms or 1s", svc.SlowQueryThreshold)
+	}
+	return threshold, nil
+}
+
+// getContainerSlowQueryThreshold returns the lowest threshold of the services sharing a
+// database container that log slow queries, or false when none does
+func getContainerSlowQueryThreshold(config *Config, dbServiceName string) (time.Duration, bool) {
+	var threshold time.Duration
+	found := false
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if !svc.SlowQueryLog || getSharedDatabaseServiceName(parseDatabaseType(svc.Database)) != dbServiceName {
+			continue
+		}
+		value, err := getSlowQueryThreshold(svc)
+		if err != nil {
+			continue
+		}
+		if !found || value < threshold {
+			threshold, found = value, true
+		}
+	}
+	return threshold, found
+}
+
+// getSlowQueryLogFile returns the slow query log of a database container, in .fleet/logs
+func getSlowQueryLogFile(dbType, dbServiceName string) string {
+	name := "slow.log"
+	if dbType == "postgres" {
+		name = "postgresql.log"
+	}
+	return filepath.Join(".fleet", "logs", dbServiceName, name)
+}
+
+// generateMySQLSlowQueryConfig returns the option file turning on the slow query log
+func generateMySQLSlowQueryConfig(threshold time.Duration) string {
+	return fmt.Sprintf(`# Fleet: slow query log, see fleet db slowlog
+[mysqld]
+slow_query_log = 1
+slow_query_log_file = %s/slow.log
+long_query_time = %s
+log_output = FILE
+`, slowQueryLogDir, strconv.FormatFloat(threshold.Seconds(), 'f', -1, 64))
+}
+
+// getPostgresSlowQueryCommand returns the server command logging statements slower than
+// the threshold. The logging collector writes the whole server log to the mounted folder,
+// with the user and database of each statement. Compose splits the command like a shell.
+func getPostgresSlowQueryCommand(threshold time.Duration) string {
+	return strings.Join([]string{
+		"postgres",
+		"-c", "logging_collector=on",
+		"-c", "log_directory=" + slowQueryLogDir,
+		"-c", "log_filename=postgresql.log",
+		"-c", "log_file_mode=0644",
+		"-c", "log_rotation_age=0",
+		"-c", "log_rotation_size=100MB",
+		"-c", shellQuote("log_line_prefix=%m [%p] %q%u@%d "),
+		"-c", fmt.Sprintf("log_min_duration_statement=%d", threshold.Milliseconds()),
+	}, " ")
+}
+
+// addDatabaseSlowQueryLog turns on the slow query log of a database container when a
+// service using it asks for it, with the lowest threshold of those services
+func addDatabaseSlowQueryLog(compose *DockerCompose, config *Config, dbType, dbServiceName string) {
+	threshold, enabled := getContainerSlowQueryThreshold(config, dbServiceName)
+	service, exists := compose.Services[dbServiceName]
+	if !enabled || !exists {
+		return
+	}
+
+	plan := getArtifactPlan(compose)
+	logDir := filepath.Dir(getSlowQueryLogFile(dbType, dbServiceName))
+	// The database server runs as its own user
+	plan.addDir(logDir, 0777)
+	mount := formatBindMount("./"+filepath.Join("logs", dbServiceName), slowQueryLogDir)
+	if containsString(service.Volumes, mount) {
+		return
+	}
+	service.Volumes = append(service.Volumes, mount)
+
+	switch dbType {
+	case "mysql", "mariadb":
+		configName := fmt.Sprintf("%s-slow-query.cnf", dbServiceName)
+		plan.addFile(plan.path(configName), []byte(generateMySQLSlowQueryConfig(threshold)), 0644)
+		service.Volumes = append(service.Volumes, formatBindMount(plan.mountPath(configName), "/etc/mysql/conf.d/fleet-slow-query.cnf:ro"))
+		// Let the user of the host read the log
+		service.Environment["UMASK"] = "0644"
+	case "postgres":
+		service.Command = getPostgresSlowQueryCommand(threshold)
+	}
+	compose.Services[dbServiceName] = service
+}
+
+var (
+	mysqlQueryTimePattern = regexp.MustCompile(`^# Query_time: ([\d.]+)`)
+	mysqlUserHostPattern  = regexp.MustCompile(`^# User@Host: ([^\[\s]*)`)
+	postgresSlowPattern   = regexp.MustCompile(`^(\S+ \S+(?: \S+)?) \[\d+\] (?:(\S*)@(\S*) )?LOG:\s+duration: ([\d.]+) ms\s+(?:statement|execute [^:]*|parse [^:]*|bind [^:]*): (.*)$`)
+)
+
+// parseMySQLSlowLog returns the queries of a MySQL or MariaDB slow query log
+func parseMySQLSlowLog(text string) []SlowQuery {
+	var queries []SlowQuery
+	var current *SlowQuery
+	var statement []string
+	flush := func() {
+		if current != nil && len(statement) > 0 {
+			current.Query = strings.Join(statement, " ")
+			queries = append(queries, *current)
+		}
+		current, statement = nil, nil
+	}
+	entryTime, user, database := "", "", ""
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "# Time: "):
+			flush()
+			entryTime = strings.TrimPrefix(line, "# Time: ")
+		case strings.HasPrefix(line, "# User@Host: "):
+			flush()
+			if match := mysqlUserHostPattern.FindStringSubmatch(line); match != nil {
+				user = match[1]
+			}
+		case strings.HasPrefix(line, "# Query_time: "):
+			flush()
+			if match := mysqlQueryTimePattern.FindStringSubmatch(line); match != nil {
+				seconds, _ := strconv.ParseFloat(match[1], 64)
+				current = &SlowQuery{Time: entryTime, Duration: time.Duration(seconds * float64(time.Second)), User: user}
+			}
+		case strings.HasPrefix(line, "#"), current == nil:
+			// Other comments, and the header of the file
+		case strings.HasPrefix(line, "SET timestamp="):
+		case strings.HasPrefix(strings.ToLower(line), "use ") && strings.HasSuffix(line, ";"):
+			database = strings.TrimSuffix(strings.TrimSpace(line[4:]), ";")
+		default:
+			if len(statement) == 0 {
+				current.Database = database
+			}
+			if line = strings.TrimSpace(line); line != "" {
+				statement = append(statement, line)
+			}
+		}
+	}
+	flush()
+	return queries
+}
+
+// parsePostgresSlowLog returns the statements logged by log_min_duration_statement
+func parsePostgresSlowLog(text string) []SlowQuery {
+	var queries []SlowQuery
+	inStatement := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		// Statements on several lines continue with a tab
+		if strings.HasPrefix(line, "\t") {
+			if inStatement {
+				queries[len(queries)-1].Query += " " + strings.TrimSpace(line)
+			}
+			continue
+		}
+		inStatement = false
+		match := postgresSlowPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		ms, _ := strconv.ParseFloat(match[4], 64)
+		queries = append(queries, SlowQuery{
+			Time:     match[1],
+			Duration: time.Duration(ms * float64(time.Millisecond)),
+			User:     match[2],
+			Database: match[3],
+			Query:    strings.TrimSpace(match[5]),
+		})
+		inStatement = true
+	}
+	return queries
+}
+
+// parseSlowQueryLog parses the slow query log of a database type
+func parseSlowQueryLog(dbType, text string) []SlowQuery {
+	if dbType == "postgres" {
+		return parsePostgresSlowLog(text)
+	}
+	return parseMySQLSlowLog(text)
+}
+
+var (
+	sqlStringPattern     = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'`)
+	sqlNumberPattern     = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	sqlParamPattern      = regexp.MustCompile(`\$\d+`)
+	sqlListPattern       = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	sqlWhitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// normalizeQuery replaces the values of a query with ?, so runs of the same query with
+// other values are counted together
+func normalizeQuery(query string) string {
+	query = sqlStringPattern.ReplaceAllString(query, "?")
+	query = sqlParamPattern.ReplaceAllString(query, "?")
+	query = sqlNumberPattern.ReplaceAllString(query, "?")
+	query = sqlListPattern.ReplaceAllString(query, "(?, ...)")
+	return strings.TrimSpace(sqlWhitespacePattern.ReplaceAllString(query, " "))
+}
+
+// SlowQuerySummary is the total time taken by a query of a slow query log
+type SlowQuerySummary struct {
+	Query string
+	Count int
+	Total time.Duration
+	Max   time.Duration
+}
+
+// summarizeSlowQueries groups queries by their normalized text, the slowest in total first
+func summarizeSlowQueries(queries []SlowQuery) []SlowQuerySummary {
+	byQuery := make(map[string]*SlowQuerySummary)
+	for _, query := range queries {
+		key := normalizeQuery(query.Query)
+		summary, exists := byQuery[key]
+		if !exists {
+			summary = &SlowQuerySummary{Query: key}
+			byQuery[key] = summary
+		}
+		summary.Count++
+		summary.Total += query.Duration
+		if query.Duration > summary.Max {
+			summary.Max = query.Duration
+		}
+	}
+
+	summaries := make([]SlowQuerySummary, 0, len(byQuery))
+	for _, key := range sortedKeys(byQuery) {
+		summaries = append(summaries, *byQuery[key])
+	}
+	sort.SliceStable(summaries, func(i, j int) bool { return summaries[i].Total > summaries[j].Total })
+	return summaries
+}
+
+// filterSlowQueries keeps the queries of the database and user of a service
+func filterSlowQueries(queries []SlowQuery, target *DatabaseTarget) []SlowQuery {
+	if target.Service == "" {
+		return queries
+	}
+	var filtered []SlowQuery
+	for _, query := range queries {
+		if (query.User == "" || query.User == target.User) && (query.Database == "" || query.Database == target.Database) {
+			filtered = append(filtered, query)
+		}
+	}
+	return filtered
+}
+
+// truncateQuery shortens a query to fit a line
+func truncateQuery(query string, length int) string {
+	query = sqlWhitespacePattern.ReplaceAllString(query, " ")
+	if len(query) <= length {
+		return query
+	}
+	return query[:length-3] + "..."
+}
+
+// formatQueryDuration prints a duration in milliseconds or seconds
+func formatQueryDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+// printSlowQueries prints the last queries of a slow query log
+func printSlowQueries(queries []SlowQuery, count int) {
+	if count >= 0 && len(queries) > count {
+		queries = queries[len(queries)-count:]
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tDURATION\tUSER\tQUERY")
+	for _, query := range queries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", query.Time, formatQueryDuration(query.Duration), query.User, truncateQuery(query.Query, 100))
+	}
+	w.Flush()
+}
+
+// printSlowQuerySummary prints the queries that took the most time in total
+func printSlowQuerySummary(summaries []SlowQuerySummary, count int) {
+	if count >= 0 && len(summaries) > count {
+		summaries = summaries[:count]
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COUNT\tTOTAL\tAVG\tMAX\tQUERY")
+	for _, summary := range summaries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", summary.Count, formatQueryDuration(summary.Total),
+			formatQueryDuration(summary.Total/time.Duration(summary.Count)), formatQueryDuration(summary.Max),
+			truncateQuery(summary.Query, 100))
+	}
+	w.Flush()
+}
+
+// followSlowQueryLog prints what the database appends to its slow query log
+func followSlowQueryLog(file *os.File) error {
+	reader := bufio.NewReader(file)
+	for rootContext.Err() == nil {
+		line, err := reader.ReadString('\n')
+		fmt.Print(line)
+		if err == io.EOF {
+			time.Sleep(250 * time.Millisecond)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleDBSlowlog runs fleet db slowlog
+func handleDBSlowlog(args []string) {
+	fs := newCommandFlagSet("db slowlog", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	count := fs.Int("n", 20, "Number of queries")
+	summary := fs.Bool("summary", false, "Group queries and show the slowest in total")
+	follow := fs.Bool("follow", false, "Print new entries as they are logged")
+	fs.Usage = printDBUsage
+
+	positional := parseFlagsAndArgs(fs, args)
+	if len(positional) != 1 {
+		log.Fatalf("❌ Usage: fleet db slowlog <service|container> [-n 20] [--summary] [--follow]")
+	}
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, files, compose := loadDatabaseCommand(*configFile)
+	target, err := resolveDatabaseTarget(config, files, compose, positional[0])
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if _, enabled := getContainerSlowQueryThreshold(config, target.Container); !enabled {
+		log.Fatalf("❌ %s doesn't log slow queries, set slow_query_log = true on a service using it", target.Container)
+	}
+
+	path := getSlowQueryLogFile(target.Type, target.Container)
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			log.Fatalf("❌ No slow query log in %s yet, is %s running?", path, target.Container)
+		}
+		log.Fatalf("❌ %v", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	queries := filterSlowQueries(parseSlowQueryLog(target.Type, string(data)), target)
+
+	switch {
+	case *summary:
+		if len(queries) == 0 {
+			outputln("No slow queries logged")
+			return
+		}
+		printSlowQuerySummary(summarizeSlowQueries(queries), *count)
+	case *follow:
+		printSlowQueries(queries, *count)
+		if err := followSlowQueryLog(file); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	default:
+		if len(queries) == 0 {
+			outputln("No slow queries logged")
+			return
+		}
+		printSlowQueries(queries, *count)
+	}
+}
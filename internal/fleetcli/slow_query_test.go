@@ -0,0 +1,143 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SlowQueryTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *SlowQueryTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *SlowQueryTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *SlowQueryTestSuite) TestValidateSlowQueryLog() {
+	suite.NoError(validateSlowQueryLog(&Service{Name: "api", Database: "postgres:16", SlowQueryLog: true, SlowQueryThreshold: "200ms"}))
+	suite.NoError(validateSlowQueryLog(&Service{Name: "api", Database: "mariadb:11", SlowQueryLog: true}))
+	suite.NoError(validateSlowQueryLog(&Service{Name: "api", Image: "node:20"}))
+
+	suite.ErrorContains(validateSlowQueryLog(&Service{Name: "api", Database: "postgres:16", SlowQueryThreshold: "1s"}),
+		"requires 'slow_query_log = true'")
+	suite.ErrorContains(validateSlowQueryLog(&Service{Name: "api", Database: "mongodb:7", SlowQueryLog: true}),
+		"requires a MySQL, MariaDB or PostgreSQL")
+	suite.ErrorContains(validateSlowQueryLog(&Service{Name: "api", Database: "mysql:8.0", SlowQueryLog: true, SlowQueryThreshold: "fast"}),
+		`invalid slow_query_threshold "fast"`)
+}
+
+func (suite *SlowQueryTestSuite) TestSharedMySQLContainer() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "shop", Image: "node:20", Database: "mysql:8.0"},
+		{Name: "blog", Image: "node:20", Database: "mysql:8.0", SlowQueryLog: true, SlowQueryThreshold: "1s"},
+		{Name: "admin", Image: "node:20", Database: "mysql:8.0", SlowQueryLog: true, SlowQueryThreshold: "250ms"},
+	}}
+	compose := generateDockerCompose(config)
+
+	mysql := compose.Services["mysql-80"]
+	suite.Contains(mysql.Volumes, "./logs/mysql-80:/var/log/fleet", "A service joining the container turns the log on")
+	suite.Contains(mysql.Volumes, "./mysql-80-slow-query.cnf:/etc/mysql/conf.d/fleet-slow-query.cnf:ro")
+	suite.Equal("0644", mysql.Environment["UMASK"])
+
+	artifact, planned := compose.Artifacts.file(".fleet/mysql-80-slow-query.cnf")
+	suite.Require().True(planned)
+	suite.Contains(string(artifact.Content), "long_query_time = 0.25", "The lowest threshold applies")
+	suite.Contains(string(artifact.Content), "slow_query_log_file = /var/log/fleet/slow.log")
+
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
+	info, err := os.Stat(filepath.Join(".fleet", "logs", "mysql-80"))
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0777), info.Mode().Perm(), "The database server can write the log")
+}
+
+func (suite *SlowQueryTestSuite) TestPostgresCommand() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Database: "postgres:16", SlowQueryLog: true},
+	}}
+	compose := generateDockerCompose(config)
+
+	postgres := compose.Services["postgres-16"]
+	suite.Contains(postgres.Volumes, "./logs/postgres-16:/var/log/fleet")
+	suite.Contains(postgres.Command, "-c log_min_duration_statement=500")
+	suite.Contains(postgres.Command, "-c 'log_line_prefix=%m [%p] %q%u@%d '")
+
+	compose = generateDockerCompose(&Config{Project: "test", Services: []Service{{Name: "api", Image: "node:20", Database: "postgres:16"}}})
+	suite.Empty(compose.Services["postgres-16"].Command)
+}
+
+func (suite *SlowQueryTestSuite) TestParseMySQLSlowLog() {
+	log := `/usr/sbin/mysqld, Version: 8.0.36 (MySQL Community Server - GPL). started with:
+Tcp port: 3306  Unix socket: /var/run/mysqld/mysqld.sock
+Time                 Id Command    Argument
+# Time: 2024-05-01T10:00:00.123456Z
+# User@Host: shop[shop] @  [172.18.0.5]  Id:     8
+# Query_time: 1.500000  Lock_time: 0.000002 Rows_sent: 1  Rows_examined: 100000
+use shop;
+SET timestamp=1714557600;
+SELECT *
+  FROM orders WHERE customer_id = 42;
+# Time: 2024-05-01T10:00:01.000000Z
+# User@Host: blog[blog] @  [172.18.0.6]  Id:     9
+# Query_time: 0.600000  Lock_time: 0.000000 Rows_sent: 0  Rows_examined: 0
+SET timestamp=1714557601;
+SELECT SLEEP(0.6);
+`
+	queries := parseMySQLSlowLog(log)
+	suite.Require().Len(queries, 2)
+	suite.Equal(SlowQuery{Time: "2024-05-01T10:00:00.123456Z", Duration: 1500 * time.Millisecond, User: "shop", Database: "shop",
+		Query: "SELECT * FROM orders WHERE customer_id = 42;"}, queries[0])
+	suite.Equal("blog", queries[1].User)
+	suite.Equal(600*time.Millisecond, queries[1].Duration)
+
+	shop := filterSlowQueries(queries, &DatabaseTarget{Service: "shop", User: "shop", Database: "shop"})
+	suite.Len(shop, 1)
+	suite.Len(filterSlowQueries(queries, &DatabaseTarget{Container: "mysql-80"}), 2, "The container shows every query")
+}
+
+func (suite *SlowQueryTestSuite) TestParsePostgresSlowLog() {
+	log := `2024-05-01 10:00:00.000 UTC [1] LOG:  database system is ready to accept connections
+2024-05-01 10:00:05.123 UTC [57] api@api LOG:  duration: 812.500 ms  statement: SELECT *
+	FROM users
+	WHERE email = 'a@b.test'
+2024-05-01 10:00:06.000 UTC [58] api@api LOG:  duration: 1200.000 ms  execute <unnamed>: SELECT * FROM users WHERE id = $1
+2024-05-01 10:00:06.000 UTC [58] api@api DETAIL:  parameters: $1 = '7'
+`
+	queries := parsePostgresSlowLog(log)
+	suite.Require().Len(queries, 2)
+	suite.Equal(SlowQuery{Time: "2024-05-01 10:00:05.123 UTC", Duration: 812500 * time.Microsecond, User: "api", Database: "api",
+		Query: "SELECT * FROM users WHERE email = 'a@b.test'"}, queries[0])
+	suite.Equal("SELECT * FROM users WHERE id = $1", queries[1].Query)
+}
+
+func (suite *SlowQueryTestSuite) TestSummarizeSlowQueries() {
+	suite.Equal("SELECT * FROM t WHERE id IN (?, ...) AND name = ? AND x = ?",
+		normalizeQuery("SELECT *  FROM t WHERE id IN (1, 2, 3) AND name = 'it''s' AND x = $1"))
+
+	summaries := summarizeSlowQueries([]SlowQuery{
+		{Duration: time.Second, Query: "SELECT * FROM orders WHERE id = 1"},
+		{Duration: 3 * time.Second, Query: "SELECT * FROM orders WHERE id = 2"},
+		{Duration: 2 * time.Second, Query: "SELECT SLEEP(2)"},
+	})
+	suite.Require().Len(summaries, 2)
+	suite.Equal(SlowQuerySummary{Query: "SELECT * FROM orders WHERE id = ?", Count: 2, Total: 4 * time.Second, Max: 3 * time.Second}, summaries[0])
+	suite.Equal("SELECT SLEEP(?)", summaries[1].Query)
+}
+
+func TestSlowQuerySuite(t *testing.T) {
+	suite.Run(t, new(SlowQueryTestSuite))
+}
@@ -0,0 +1,103 @@
+package fleetcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Where the SSH agent socket is mounted in containers
+const (
+	containerSSHAuthSock = "/run/ssh-agent.sock"
+	// Docker Desktop for Mac can't share host sockets, it proxies the agent at this path instead
+	dockerDesktopSSHAuthSock = "/run/host-services/ssh-auth.sock"
+)
+
+// containerHome is where credential files are mounted. docker exec and the official
+// images run as root, so tools like git, composer and npm look there.
+const containerHome = "/root"
+
+// getSSHAgentMount returns the volume and SSH_AUTH_SOCK that give a container the host
+// SSH agent on an OS
+func getSSHAgentMount(goos, hostSocket string) (volume string, socket string, err error) {
+	switch goos {
+	case "darwin":
+		return fmt.Sprintf("%s:%s", dockerDesktopSSHAuthSock, dockerDesktopSSHAuthSock), dockerDesktopSSHAuthSock, nil
+	case "windows":
+		return "", "", fmt.Errorf("forwarding the SSH agent isn't supported on Windows, run Fleet from WSL instead")
+	}
+
+	if hostSocket == "" {
+		return "", "", fmt.Errorf("SSH_AUTH_SOCK is not set, start an agent with 'eval $(ssh-agent)' and 'ssh-add'")
+	}
+	return fmt.Sprintf("%s:%s", hostSocket, containerSSHAuthSock), containerSSHAuthSock, nil
+}
+
+// getGitCredentialMounts returns read-only mounts of the git and netrc files in the
+// host home directory. Missing files are skipped.
+func getGitCredentialMounts(home string) []string {
+	var volumes []string
+	for _, file := range []string{".gitconfig", ".netrc", ".git-credentials"} {
+		hostPath := filepath.Join(home, file)
+		if _, err := os.Stat(hostPath); err == nil {
+			volumes = append(volumes, formatBindMount(hostPath, fmt.Sprintf("%s/%s:ro", containerHome, file)))
+		}
+	}
+	return volumes
+}
+
+// getKnownHostsMount returns a read-only mount of the host known_hosts, so ssh in the
+// container trusts the same hosts without prompting
+func getKnownHostsMount(home string) string {
+	hostPath := filepath.Join(home, ".ssh", "known_hosts")
+	if _, err := os.Stat(hostPath); err != nil {
+		return ""
+	}
+	return formatBindMount(hostPath, containerHome+"/.ssh/known_hosts:ro")
+}
+
+// configureCredentialForwarding gives the container that runs a service's code the host
+// SSH agent and git credentials, so installs from private repositories work
+func configureCredentialForwarding(compose *DockerCompose, svc *Service) {
+	if !svc.ForwardSSHAgent && !svc.GitCredentials {
+		return
+	}
+
+	name := getAppServiceName(svc)
+	service, exists := compose.Services[name]
+	if !exists {
+		name = svc.Name
+		if service, exists = compose.Services[name]; !exists {
+			return
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		warnf("⚠️  Warning: service %s: %v\n", svc.Name, err)
+		return
+	}
+
+	if svc.ForwardSSHAgent {
+		volume, socket, err := getSSHAgentMount(runtime.GOOS, os.Getenv("SSH_AUTH_SOCK"))
+		if err != nil {
+			warnf("⚠️  Warning: service %s: %v\n", svc.Name, err)
+		} else {
+			service.Volumes = append(service.Volumes, volume)
+			if service.Environment == nil {
+				service.Environment = make(map[string]string)
+			}
+			service.Environment["SSH_AUTH_SOCK"] = socket
+			if knownHosts := getKnownHostsMount(home); knownHosts != "" {
+				service.Volumes = append(service.Volumes, knownHosts)
+			}
+		}
+	}
+
+	if svc.GitCredentials {
+		service.Volumes = append(service.Volumes, getGitCredentialMounts(home)...)
+	}
+
+	compose.Services[name] = service
+}
@@ -0,0 +1,102 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SSHAgentTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+	home        string
+}
+
+func (suite *SSHAgentTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+
+	suite.home = suite.T().TempDir()
+	suite.T().Setenv("HOME", suite.home)
+	suite.T().Setenv("SSH_AUTH_SOCK", "/tmp/ssh-XXXX/agent.1234")
+}
+
+func (suite *SSHAgentTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *SSHAgentTestSuite) TestGetSSHAgentMount() {
+	testCases := []struct {
+		name       string
+		goos       string
+		hostSocket string
+		volume     string
+		socket     string
+		error      string
+	}{
+		{"linux", "linux", "/tmp/ssh-XXXX/agent.1234", "/tmp/ssh-XXXX/agent.1234:/run/ssh-agent.sock", containerSSHAuthSock, ""},
+		{"linux without an agent", "linux", "", "", "", "SSH_AUTH_SOCK is not set"},
+		{"macOS uses the Docker Desktop socket", "darwin", "/private/tmp/launchd/Listeners", "/run/host-services/ssh-auth.sock:/run/host-services/ssh-auth.sock", dockerDesktopSSHAuthSock, ""},
+		{"windows", "windows", "", "", "", "WSL"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			volume, socket, err := getSSHAgentMount(tc.goos, tc.hostSocket)
+			if tc.error != "" {
+				suite.ErrorContains(err, tc.error)
+				return
+			}
+			suite.Require().NoError(err)
+			suite.Equal(tc.volume, volume)
+			suite.Equal(tc.socket, socket)
+		})
+	}
+}
+
+func (suite *SSHAgentTestSuite) TestGetGitCredentialMounts() {
+	suite.Empty(getGitCredentialMounts(suite.home), "Missing files aren't mounted")
+
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.home, ".gitconfig"), []byte("[user]\n"), 0644))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.home, ".netrc"), []byte("machine github.com\n"), 0600))
+
+	suite.Equal([]string{
+		filepath.Join(suite.home, ".gitconfig") + ":/root/.gitconfig:ro",
+		filepath.Join(suite.home, ".netrc") + ":/root/.netrc:ro",
+	}, getGitCredentialMounts(suite.home))
+}
+
+func (suite *SSHAgentTestSuite) TestForwardingTargetsThePHPContainer() {
+	if runtime.GOOS == "windows" {
+		suite.T().Skip("SSH agent forwarding isn't supported on Windows")
+	}
+	suite.Require().NoError(os.MkdirAll(filepath.Join(suite.home, ".ssh"), 0700))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.home, ".ssh", "known_hosts"), []byte("github.com ssh-ed25519 AAAA\n"), 0644))
+
+	config := &Config{
+		Project: "test",
+		Services: []Service{
+			{Name: "web", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "app", ForwardSSHAgent: true},
+			{Name: "api", Image: "node:20", Folder: "api"},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+	php := compose.Services["web-php"]
+	suite.NotEmpty(php.Environment["SSH_AUTH_SOCK"], "Composer runs in the PHP container")
+	suite.Contains(php.Volumes, filepath.Join(suite.home, ".ssh", "known_hosts")+":/root/.ssh/known_hosts:ro")
+	suite.Empty(compose.Services["web"].Environment["SSH_AUTH_SOCK"])
+	suite.Empty(compose.Services["api"].Environment["SSH_AUTH_SOCK"], "Only services that ask for it get the agent")
+}
+
+func TestSSHAgentSuite(t *testing.T) {
+	suite.Run(t, new(SSHAgentTestSuite))
+}
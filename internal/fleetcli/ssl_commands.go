@@ -0,0 +1,377 @@
+package fleetcli
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// sslRenewBefore is how long before they expire certificates are renewed by fleet up
+const sslRenewBefore = 30 * 24 * time.Hour
+
+// SSLCertificateInfo describes a certificate file of .fleet/ssl
+type SSLCertificateInfo struct {
+	Domain   string
+	CertPath string
+	KeyPath  string
+	Expires  time.Time
+	Issuer   string // self-signed, local CA, or the name of the issuer
+	Status   string // valid, expiring, expired, missing, orphaned or invalid
+}
+
+func handleSSL() {
+	if len(os.Args) < 3 {
+		printSSLUsage()
+		os.Exit(0)
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "list", "ls":
+		handleSSLList(os.Args[3:])
+	case "renew":
+		handleSSLRenew(os.Args[3:])
+	case "clean":
+		handleSSLClean(os.Args[3:])
+	case "export":
+		handleSSLExport(os.Args[3:])
+	case "trust":
+		handleSSLTrust(os.Args[3:])
+	case "untrust":
+		handleSSLUntrust(os.Args[3:])
+	case "status":
+		handleSSLStatus(os.Args[3:])
+	case "help":
+		printSSLUsage()
+	default:
+		fmt.Printf("Unknown ssl command: %s\n\n", subcommand)
+		printSSLUsage()
+		os.Exit(1)
+	}
+}
+
+func printSSLUsage() {
+	fmt.Println("Fleet ssl - Manage the certificates of the proxy and the local CA signing them")
+	fmt.Println("\nUsage: fleet ssl <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  list, ls           List the certificates in .fleet/ssl with their expiry date")
+	fmt.Println("  renew [domain...]  Generate certificates again, all of them by default")
+	fmt.Println("  clean              Remove certificates of domains no service uses anymore")
+	fmt.Println("  export <domain>    Copy the certificate and key of a domain, for other tools")
+	fmt.Println("  trust              Create the local CA, install it in the trust stores and sign the certificates of the project")
+	fmt.Println("  untrust            Remove the local CA from the trust stores")
+	fmt.Println("  status             Show the local CA and whether it is trusted")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("  -o, --output  Folder to export to (for 'export', default: current folder)")
+	fmt.Println("  --dry-run   Only list the files to remove (for 'clean')")
+	fmt.Println("\nmkcert manages the CA when it is installed, otherwise it is kept in ~/.fleet/ca.")
+	fmt.Println("Once the CA exists, the certificates of every project are signed with it.")
+	fmt.Println("\nExamples:")
+	fmt.Println("  fleet ssl renew shop.test        # Replace the certificate of shop.test")
+	fmt.Println("  fleet ssl export shop.test -o ~/certs  # Copy shop_test.crt and shop_test.key")
+}
+
+// loadSSLConfig parses the common flags of ssl commands and loads the config
+func loadSSLConfig(name string, args []string, setup func(fs *flag.FlagSet)) (*Config, []string) {
+	fs := newCommandFlagSet(name, flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	fs.Usage = printSSLUsage
+	if setup != nil {
+		setup(fs)
+	}
+
+	rest := parseFlagsAndArgs(fs, args)
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	return config, rest
+}
+
+// readCertificate reads a PEM certificate
+func readCertificate(path string) (*x509.Certificate, error) {
+	certPEM, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM certificate", path)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// describeCertificateIssuer tells whether a certificate is self-signed or signed by the
+// local CA
+func describeCertificateIssuer(cert *x509.Certificate, ca *LocalCA) string {
+	switch {
+	case ca != nil && cert.CheckSignatureFrom(ca.Cert) == nil:
+		return "local CA"
+	case cert.CheckSignatureFrom(cert) == nil, cert.Issuer.String() == cert.Subject.String():
+		return "self-signed"
+	case cert.Issuer.CommonName != "":
+		return cert.Issuer.CommonName
+	}
+	return cert.Issuer.String()
+}
+
+// getSSLCertificateInfos returns the certificates the project needs and the other
+// certificate files of .fleet/ssl, sorted by domain
+func getSSLCertificateInfos(config *Config, ca *LocalCA, now time.Time) []SSLCertificateInfo {
+	var infos []SSLCertificateInfo
+	expected := make(map[string]bool)
+	for _, cert := range getSSLCertificates(config, ca) {
+		expected[filepath.Clean(cert.CertPath)] = true
+		infos = append(infos, getSSLCertificateInfo(cert.Domain, cert.CertPath, cert.KeyPath, ca, now))
+	}
+
+	files, _ := filepath.Glob(filepath.Join(getSSLDir(config), "*.crt"))
+	for _, certPath := range files {
+		if expected[filepath.Clean(certPath)] {
+			continue
+		}
+		keyPath := strings.TrimSuffix(certPath, ".crt") + ".key"
+		info := getSSLCertificateInfo(strings.TrimSuffix(filepath.Base(certPath), ".crt"), certPath, keyPath, ca, now)
+		info.Status = "orphaned"
+		if cert, err := readCertificate(certPath); err == nil && cert.Subject.CommonName != "" {
+			info.Domain = cert.Subject.CommonName
+		}
+		infos = append(infos, info)
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		// The certificate of the catch-all server comes first
+		if (infos[i].Domain == "default") != (infos[j].Domain == "default") {
+			return infos[i].Domain == "default"
+		}
+		return infos[i].Domain < infos[j].Domain
+	})
+	return infos
+}
+
+// getSSLCertificateInfo reads a certificate of .fleet/ssl and whether it is still valid
+func getSSLCertificateInfo(domain, certPath, keyPath string, ca *LocalCA, now time.Time) SSLCertificateInfo {
+	info := SSLCertificateInfo{Domain: domain, CertPath: certPath, KeyPath: keyPath}
+	cert, err := readCertificate(certPath)
+	if os.IsNotExist(err) {
+		info.Status = "missing"
+		return info
+	}
+	if err != nil {
+		info.Status = "invalid"
+		return info
+	}
+
+	info.Expires = cert.NotAfter
+	info.Issuer = describeCertificateIssuer(cert, ca)
+	switch {
+	case now.After(cert.NotAfter):
+		info.Status = "expired"
+	case cert.NotAfter.Sub(now) < sslRenewBefore:
+		info.Status = "expiring"
+	default:
+		info.Status = "valid"
+	}
+	if _, err := os.Stat(keyPath); os.IsNotExist(err) {
+		info.Status = "invalid"
+	}
+	return info
+}
+
+func handleSSLList(args []string) {
+	config, _ := loadSSLConfig("ssl list", args, nil)
+
+	ca, err := loadLocalCA(getLocalCADir())
+	if err != nil {
+		warnf("⚠️  Warning: %v\n", err)
+	}
+	infos := getSSLCertificateInfos(config, ca, time.Now())
+	if !hasSSLServices(config) && len(infos) <= 1 {
+		outputln("No service uses ssl = true")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DOMAIN\tFILE\tEXPIRES\tISSUER\tSTATUS")
+	for _, info := range infos {
+		expires := "-"
+		if !info.Expires.IsZero() {
+			expires = info.Expires.Format("2006-01-02")
+		}
+		issuer := info.Issuer
+		if issuer == "" {
+			issuer = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", info.Domain, info.CertPath, expires, issuer, info.Status)
+	}
+	w.Flush()
+}
+
+// reloadProxy makes the running proxy load its config and the certificates again.
+// nginx checks the config first, and keeps serving the old one when it is broken.
+func reloadProxy(config *Config) error {
+	// Traefik reads the certificates of its dynamic config when it starts
+	if isTraefikProxy(config) {
+		return runDocker(composeArgs(getComposeFiles(config), "restart", traefikServiceName))
+	}
+	_, err := execInService(getComposeFiles(config), &MaintenanceExec{Target: "nginx-proxy", Command: "nginx -t -q && nginx -s reload"})
+	return err
+}
+
+func handleSSLRenew(args []string) {
+	var lockOptions *ProjectLockOptions
+	config, domains := loadSSLConfig("ssl renew", args, func(fs *flag.FlagSet) {
+		lockOptions = addProjectLockFlags(fs)
+	})
+	if !hasSSLServices(config) {
+		log.Fatalf("❌ No service uses ssl = true")
+	}
+
+	ca, err := loadLocalCA(getLocalCADir())
+	if err != nil {
+		warnf("⚠️  Warning: %v, using self-signed certificates\n", err)
+	}
+
+	plan := newArtifactPlan()
+	plan.addDir(getSSLDir(config), 0755)
+	renewed := make(map[string]bool)
+	for _, cert := range getSSLCertificates(config, ca) {
+		if len(domains) == 0 || containsString(domains, cert.Domain) {
+			plan.addCertificate(cert)
+			renewed[cert.Domain] = true
+		}
+	}
+	for _, domain := range domains {
+		if !renewed[domain] {
+			log.Fatalf("❌ No service with ssl = true uses the domain %s", domain)
+		}
+	}
+	planNginxSSLConfig(plan, config)
+
+	release := lockProject("ssl renew", lockOptions)
+	defer release()
+	if err := writeArtifacts(plan); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if err := reloadProxy(config); err != nil {
+		outputln("Run 'fleet up' to serve the new certificates")
+		return
+	}
+	infoln("✅ Reloaded the proxy with the new certificates")
+}
+
+// getOrphanedSSLFiles returns the certificate and key files of .fleet/ssl no service
+// uses anymore
+func getOrphanedSSLFiles(config *Config) []string {
+	expected := make(map[string]bool)
+	for _, cert := range getSSLCertificates(config, nil) {
+		expected[filepath.Clean(cert.CertPath)] = true
+		expected[filepath.Clean(cert.KeyPath)] = true
+	}
+
+	var files []string
+	for _, pattern := range []string{"*.crt", "*.key"} {
+		matches, _ := filepath.Glob(filepath.Join(getSSLDir(config), pattern))
+		for _, path := range matches {
+			if !expected[filepath.Clean(path)] {
+				files = append(files, path)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+func handleSSLClean(args []string) {
+	var dryRun *bool
+	config, _ := loadSSLConfig("ssl clean", args, func(fs *flag.FlagSet) {
+		dryRun = fs.Bool("dry-run", false, "Only list the files to remove")
+	})
+
+	files := getOrphanedSSLFiles(config)
+	if len(files) == 0 {
+		outputln("No orphaned certificates in " + getSSLDir(config))
+		return
+	}
+	for _, file := range files {
+		if *dryRun {
+			outputln("Would remove " + file)
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			log.Fatalf("❌ Failed to remove %s: %v", file, err)
+		}
+		infof("🗑️  Removed %s\n", file)
+	}
+}
+
+func handleSSLExport(args []string) {
+	var output, outputLong *string
+	config, domains := loadSSLConfig("ssl export", args, func(fs *flag.FlagSet) {
+		output = fs.String("o", ".", "Output folder")
+		outputLong = fs.String("output", ".", "Output folder")
+	})
+	if len(domains) != 1 {
+		log.Fatalf("❌ Usage: fleet ssl export <domain> [-o folder]")
+	}
+	if *outputLong != "." {
+		*output = *outputLong
+	}
+
+	ca, _ := loadLocalCA(getLocalCADir())
+	var cert *SSLCertificate
+	for _, candidate := range getSSLCertificates(config, ca) {
+		if candidate.Domain == domains[0] {
+			cert = &candidate
+			break
+		}
+	}
+	if cert == nil {
+		log.Fatalf("❌ No service with ssl = true uses the domain %s", domains[0])
+	}
+	if _, err := readCertificate(cert.CertPath); err != nil {
+		log.Fatalf("❌ No certificate for %s yet, run 'fleet up' or 'fleet ssl renew %s'", cert.Domain, cert.Domain)
+	}
+
+	// Tools that don't read the system trust store need the CA of the certificate too
+	files := map[string]string{filepath.Base(cert.CertPath): cert.CertPath, filepath.Base(cert.KeyPath): cert.KeyPath}
+	if info := getSSLCertificateInfo(cert.Domain, cert.CertPath, cert.KeyPath, ca, time.Now()); info.Issuer == "local CA" {
+		files["fleet-ca.crt"] = ca.CertPath
+	}
+
+	if err := os.MkdirAll(*output, 0755); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	for _, name := range sortedKeys(files) {
+		data, err := os.ReadFile(files[name])
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		mode := os.FileMode(0644)
+		if files[name] == cert.KeyPath {
+			mode = 0600
+		}
+		target := filepath.Join(*output, name)
+		if err := os.WriteFile(target, data, mode); err != nil {
+			log.Fatalf("❌ Failed to write %s: %v", target, err)
+		}
+		outputln(target)
+	}
+}
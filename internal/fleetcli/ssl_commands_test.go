@@ -0,0 +1,107 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SSLCommandsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+	config      *Config
+}
+
+func (suite *SSLCommandsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+
+	suite.config = &Config{Project: "test", Services: []Service{
+		{Name: "shop", Image: "nginx:alpine", Port: 80, Domain: "shop.test, admin.shop.test", SSL: true},
+		{Name: "api", Image: "node:20", Port: 3000},
+	}}
+}
+
+func (suite *SSLCommandsTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+// writeCertificate generates a self-signed certificate into .fleet/ssl
+func (suite *SSLCommandsTestSuite) writeCertificate(domain string) {
+	suite.Require().NoError(os.MkdirAll(getSSLDir(&Config{}), 0755))
+	name := sanitizeDomainForFilename(domain)
+	suite.Require().NoError(generateSelfSignedCertificate(SSLCertificate{
+		Domain:     domain,
+		CertPath:   filepath.Join(getSSLDir(&Config{}), name+".crt"),
+		KeyPath:    filepath.Join(getSSLDir(&Config{}), name+".key"),
+		CommonName: domain,
+	}))
+}
+
+func (suite *SSLCommandsTestSuite) TestGetSSLCertificates() {
+	certs := getSSLCertificates(suite.config, nil)
+	suite.Require().Len(certs, 3)
+	suite.Equal("default", certs[0].Domain)
+	suite.Equal("admin.shop.test", certs[2].Domain)
+	suite.Equal(filepath.Join(".fleet", "ssl", "admin_shop_test.key"), certs[2].KeyPath)
+}
+
+func (suite *SSLCommandsTestSuite) TestGetSSLCertificateInfos() {
+	suite.Require().NoError(generateSSLCertificates(suite.config))
+	suite.writeCertificate("old.test")
+	suite.Require().NoError(os.Remove(filepath.Join(getSSLDir(&Config{}), "admin_shop_test.crt")))
+
+	infos := getSSLCertificateInfos(suite.config, nil, time.Now())
+	suite.Require().Len(infos, 4)
+	suite.Equal("default", infos[0].Domain)
+	statuses := make(map[string]string)
+	for _, info := range infos {
+		statuses[info.Domain] = info.Status
+	}
+	suite.Equal(map[string]string{"default": "valid", "shop.test": "valid", "admin.shop.test": "missing", "old.test": "orphaned"}, statuses)
+	suite.Equal("self-signed", infos[len(infos)-1].Issuer)
+
+	soon := getSSLCertificateInfos(suite.config, nil, time.Now().Add(350*24*time.Hour))
+	suite.Equal("expiring", soon[0].Status)
+	later := getSSLCertificateInfos(suite.config, nil, time.Now().Add(400*24*time.Hour))
+	suite.Equal("expired", later[0].Status)
+}
+
+func (suite *SSLCommandsTestSuite) TestGetOrphanedSSLFiles() {
+	suite.Require().NoError(generateSSLCertificates(suite.config))
+	suite.writeCertificate("old.test")
+	suite.Require().NoError(os.WriteFile(filepath.Join(getSSLDir(&Config{}), "stale.key"), []byte("key"), 0600))
+
+	suite.Equal([]string{
+		filepath.Join(".fleet", "ssl", "old_test.crt"),
+		filepath.Join(".fleet", "ssl", "old_test.key"),
+		filepath.Join(".fleet", "ssl", "stale.key"),
+	}, getOrphanedSSLFiles(suite.config), "ssl-params.conf and dhparam.pem stay")
+}
+
+func (suite *SSLCommandsTestSuite) TestDescribeCertificateIssuer() {
+	ca, err := createLocalCA(filepath.Join(suite.helper.TempDir(), "ca"))
+	suite.Require().NoError(err)
+	suite.Require().NoError(os.MkdirAll(getSSLDir(&Config{}), 0755))
+	certPath := filepath.Join(getSSLDir(&Config{}), "shop_test.crt")
+	suite.Require().NoError(generateSelfSignedCertificate(SSLCertificate{Domain: "shop.test", CertPath: certPath,
+		KeyPath: filepath.Join(getSSLDir(&Config{}), "shop_test.key"), CommonName: "shop.test", CA: ca}))
+
+	cert, err := readCertificate(certPath)
+	suite.Require().NoError(err)
+	suite.Equal("local CA", describeCertificateIssuer(cert, ca))
+	suite.Equal("Fleet Local CA", describeCertificateIssuer(cert, nil)[:14])
+	suite.Equal("self-signed", describeCertificateIssuer(ca.Cert, nil))
+}
+
+func TestSSLCommandsSuite(t *testing.T) {
+	suite.Run(t, new(SSLCommandsTestSuite))
+}
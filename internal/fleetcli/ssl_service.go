@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"crypto/rand"
@@ -7,7 +7,6 @@ import (
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
-	"math/big"
 	"net"
 	"os"
 	"path/filepath"
@@ -21,75 +20,87 @@ type SSLCertificate struct {
 	CertPath   string
 	KeyPath    string
 	CommonName string
+	CA         *LocalCA // Signs the certificate when set, otherwise it is self-signed
+	Aliases    []string // Other domains of the service, which nginx serves with this certificate
 }
 
-// generateSSLCertificates generates self-signed SSL certificates for services with domains
+// generateSSLCertificates generates the SSL certificates for services with domains
 func generateSSLCertificates(config *Config) error {
-	// Create SSL directory in .fleet
-	sslDir := filepath.Join(".fleet", "ssl")
-	if err := os.MkdirAll(sslDir, 0755); err != nil {
-		return fmt.Errorf("failed to create SSL directory: %v", err)
-	}
+	plan := newArtifactPlan()
+	planSSLCertificates(plan, config)
+	return writeArtifacts(plan)
+}
 
-	certificates := []SSLCertificate{}
+// getSSLDir returns the folder of the certificates of the proxy
+func getSSLDir(config *Config) string {
+	return filepath.Join(getArtifactDir(config), "ssl")
+}
+
+// getSSLCertificates returns the certificates the proxy needs: the default one of the
+// catch-all server, and one for each domain of the services with ssl. The certificate
+// of the main domain of a service also covers its other domains.
+func getSSLCertificates(config *Config, ca *LocalCA) []SSLCertificate {
+	sslDir := getSSLDir(config)
 
 	// Always generate a default certificate for the catch-all server
-	defaultCert := SSLCertificate{
+	certs := []SSLCertificate{{
 		Domain:     "default",
 		CertPath:   filepath.Join(sslDir, "default.crt"),
 		KeyPath:    filepath.Join(sslDir, "default.key"),
 		CommonName: "localhost",
-	}
-	
-	if !needsNewCertificate(defaultCert.CertPath, defaultCert.KeyPath) {
-		fmt.Println("Default SSL certificate already exists and is valid")
-	} else {
-		if err := generateSelfSignedCertificate(defaultCert); err != nil {
-			return fmt.Errorf("failed to generate default certificate: %v", err)
-		}
-		fmt.Println("Generated default SSL certificate")
-	}
-	certificates = append(certificates, defaultCert)
+		CA:         ca,
+	}}
 
-	// Generate certificates for each service with SSL enabled and a domain
+	seen := make(map[string]bool)
 	for _, service := range config.Services {
-		if service.SSL && service.Domain != "" {
-			domains := strings.Split(service.Domain, ",")
-			for _, domain := range domains {
-				domain = strings.TrimSpace(domain)
+		if service.SSL && len(getConfiguredDomains(&service)) > 0 {
+			for i, domain := range getServiceDomains(&service) {
+				// Services routed on a path share the certificate of their domain
+				if seen[domain] {
+					continue
+				}
+				seen[domain] = true
 				cert := SSLCertificate{
 					Domain:     domain,
 					CertPath:   filepath.Join(sslDir, fmt.Sprintf("%s.crt", sanitizeDomainForFilename(domain))),
 					KeyPath:    filepath.Join(sslDir, fmt.Sprintf("%s.key", sanitizeDomainForFilename(domain))),
 					CommonName: domain,
+					CA:         ca,
 				}
-
-				// Check if certificate already exists and is valid
-				if !needsNewCertificate(cert.CertPath, cert.KeyPath) {
-					fmt.Printf("SSL certificate for %s already exists and is valid\n", domain)
-					certificates = append(certificates, cert)
-					continue
-				}
-
-				// Generate new certificate
-				if err := generateSelfSignedCertificate(cert); err != nil {
-					return fmt.Errorf("failed to generate certificate for %s: %v", domain, err)
+				if i == 0 {
+					cert.Aliases = getDomainAliases(&service)
 				}
-
-				fmt.Printf("Generated SSL certificate for %s\n", domain)
-				certificates = append(certificates, cert)
+				certs = append(certs, cert)
 			}
 		}
 	}
+	return certs
+}
 
-	// Generate nginx SSL configuration
-	if len(certificates) > 0 {
-		if err := generateNginxSSLConfig(certificates, sslDir); err != nil {
-			return fmt.Errorf("failed to generate nginx SSL config: %v", err)
+// planSSLCertificates plans the SSL certificates for services with domains that don't
+// have a valid one yet. They are signed by the local CA once it exists, see
+// 'fleet ssl trust', and self-signed otherwise.
+func planSSLCertificates(plan *ArtifactPlan, config *Config) {
+	// Create SSL directory in .fleet
+	sslDir := getSSLDir(config)
+	plan.addDir(sslDir, 0755)
+
+	ca, err := loadLocalCA(getLocalCADir())
+	if err != nil {
+		warnf("⚠️  Warning: %v, using self-signed certificates\n", err)
+	}
+
+	// Keep certificates that exist and are valid
+	for _, cert := range getSSLCertificates(config, ca) {
+		existing := cert
+		existing.CertPath, existing.KeyPath = config.hostPath(cert.CertPath), config.hostPath(cert.KeyPath)
+		if needsSSLCertificate(existing) {
+			plan.addCertificate(cert)
 		}
 	}
 
-	return nil
+	// Plan nginx SSL configuration
+	planNginxSSLConfig(plan, config)
 }
 
 // sanitizeDomainForFilename converts a domain to a safe filename
@@ -127,14 +138,56 @@ func needsNewCertificate(certPath, keyPath string) bool {
 	}
 
 	// Check if certificate expires within 30 days
-	if time.Until(cert.NotAfter) < 30*24*time.Hour {
+	if time.Until(cert.NotAfter) < sslRenewBefore {
 		return true
 	}
 
 	return false
 }
 
-// generateSelfSignedCertificate generates a self-signed SSL certificate
+// needsSSLCertificate checks if a certificate has to be generated, also replacing the
+// self-signed ones once the local CA exists
+func needsSSLCertificate(cert SSLCertificate) bool {
+	if needsNewCertificate(cert.CertPath, cert.KeyPath) {
+		return true
+	}
+	// Certificates made before a wildcard base or an alias was added don't cover it
+	parsed, err := readCertificate(cert.CertPath)
+	if err != nil {
+		return true
+	}
+	for _, host := range getCertificateHosts(cert) {
+		if !isWildcardDomain(host) && parsed.VerifyHostname(host) != nil {
+			return true
+		}
+	}
+	return cert.CA != nil && !isSignedByLocalCA(cert.CertPath, cert.CA)
+}
+
+// getCertificateHosts returns the subject alternative names of a certificate: its
+// domain and aliases, with the www subdomain of plain domains and the base of wildcards
+func getCertificateHosts(cert SSLCertificate) []string {
+	var hosts []string
+	add := func(host string) {
+		if !containsString(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	for _, domain := range append([]string{cert.Domain}, cert.Aliases...) {
+		add(domain)
+		switch {
+		case isWildcardDomain(domain):
+			// *.myapp.test doesn't match myapp.test itself
+			add(getWildcardBase(domain))
+		case !strings.HasPrefix(domain, "www."):
+			add("www." + domain)
+		}
+	}
+	return hosts
+}
+
+// generateSelfSignedCertificate generates an SSL certificate, signed by the local CA
+// when the certificate has one and self-signed otherwise
 func generateSelfSignedCertificate(cert SSLCertificate) error {
 	// Generate RSA key
 	priv, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -142,9 +195,14 @@ func generateSelfSignedCertificate(cert SSLCertificate) error {
 		return fmt.Errorf("failed to generate private key: %v", err)
 	}
 
+	serial, err := newCertificateSerial()
+	if err != nil {
+		return err
+	}
+
 	// Certificate template
 	template := x509.Certificate{
-		SerialNumber: big.NewInt(1),
+		SerialNumber: serial,
 		Subject: pkix.Name{
 			Organization:  []string{"Fleet Local Development"},
 			Country:       []string{"US"},
@@ -162,17 +220,7 @@ func generateSelfSignedCertificate(cert SSLCertificate) error {
 	}
 
 	// Add Subject Alternative Names
-	hosts := []string{cert.Domain}
-	
-	// Add wildcard support
-	if !strings.HasPrefix(cert.Domain, "*.") {
-		// Add www subdomain if not a wildcard
-		if !strings.HasPrefix(cert.Domain, "www.") {
-			hosts = append(hosts, "www."+cert.Domain)
-		}
-	}
-
-	for _, h := range hosts {
+	for _, h := range getCertificateHosts(cert) {
 		if ip := net.ParseIP(h); ip != nil {
 			template.IPAddresses = append(template.IPAddresses, ip)
 		} else {
@@ -185,7 +233,12 @@ func generateSelfSignedCertificate(cert SSLCertificate) error {
 	template.IPAddresses = append(template.IPAddresses, net.IPv4(127, 0, 0, 1))
 
 	// Generate certificate
-	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	var certDER []byte
+	if cert.CA != nil {
+		certDER, err = x509.CreateCertificate(rand.Reader, &template, cert.CA.Cert, &priv.PublicKey, cert.CA.Key)
+	} else {
+		certDER, err = x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create certificate: %v", err)
 	}
@@ -225,8 +278,10 @@ func generateSelfSignedCertificate(cert SSLCertificate) error {
 	return nil
 }
 
-// generateNginxSSLConfig generates nginx SSL configuration
-func generateNginxSSLConfig(certificates []SSLCertificate, sslDir string) error {
+// planNginxSSLConfig plans the nginx SSL configuration
+func planNginxSSLConfig(plan *ArtifactPlan, config *Config) {
+	sslDir := getSSLDir(config)
+
 	// Create SSL params file with modern SSL configuration
 	sslParamsPath := filepath.Join(sslDir, "ssl-params.conf")
 	sslParams := `# Modern SSL configuration
@@ -250,13 +305,11 @@ ssl_stapling_verify off;
 ssl_session_tickets off;
 `
 
-	if err := os.WriteFile(sslParamsPath, []byte(sslParams), 0644); err != nil {
-		return fmt.Errorf("failed to write SSL params: %v", err)
-	}
+	plan.addFile(sslParamsPath, []byte(sslParams), 0644)
 
 	// Generate dhparam file (use a pre-generated one for speed in development)
 	dhparamPath := filepath.Join(sslDir, "dhparam.pem")
-	if _, err := os.Stat(dhparamPath); os.IsNotExist(err) {
+	if _, err := os.Stat(config.hostPath(dhparamPath)); os.IsNotExist(err) {
 		// Use a pre-generated 2048-bit dhparam for development
 		// In production, you'd want to generate this with: openssl dhparam -out dhparam.pem 2048
 		dhparam := `-----BEGIN DH PARAMETERS-----
@@ -268,18 +321,14 @@ kxnsmFzV1FQsJYfDzJqKUmgeL9TZ7iBpiTqC3nVkZCPLxAUi8EyQFHCTbCXev1Fh
 E2KSA6pDYLKqV9neLFPx5fwKMgbcCzFjIwIBAg==
 -----END DH PARAMETERS-----
 `
-		if err := os.WriteFile(dhparamPath, []byte(dhparam), 0644); err != nil {
-			return fmt.Errorf("failed to write dhparam: %v", err)
-		}
+		plan.addFile(dhparamPath, []byte(dhparam), 0644)
 	}
-
-	return nil
 }
 
 // hasSSLServices checks if any service has SSL enabled
 func hasSSLServices(config *Config) bool {
 	for _, service := range config.Services {
-		if service.SSL && service.Domain != "" {
+		if service.SSL && len(getConfiguredDomains(&service)) > 0 {
 			return true
 		}
 	}
@@ -0,0 +1,475 @@
+package fleetcli
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Local CA files, named like those of mkcert so both tools can share a CA
+const (
+	localCACertFile = "rootCA.pem"
+	localCAKeyFile  = "rootCA-key.pem"
+)
+
+// LocalCA is the certificate authority signing the certificates of the proxy, so
+// browsers trust them once it is installed
+type LocalCA struct {
+	Cert     *x509.Certificate
+	Key      crypto.Signer
+	CertPath string
+}
+
+// getLocalCADir returns where the local CA lives: the CAROOT of mkcert when it is
+// installed, or ~/.fleet/ca
+var getLocalCADir = func() string {
+	if _, err := exec.LookPath("mkcert"); err == nil {
+		if output, err := newCommand("mkcert", "-CAROOT").Output(); err == nil && strings.TrimSpace(string(output)) != "" {
+			return strings.TrimSpace(string(output))
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".fleet", "ca")
+}
+
+// hasMkcert reports whether mkcert manages the local CA
+var hasMkcert = func() bool {
+	_, err := exec.LookPath("mkcert")
+	return err == nil
+}
+
+// loadLocalCA reads the local CA, it returns nil when it doesn't exist yet
+func loadLocalCA(dir string) (*LocalCA, error) {
+	certPath := filepath.Join(dir, localCACertFile)
+	certPEM, err := os.ReadFile(certPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local CA: %w", err)
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(dir, localCAKeyFile))
+	if errors.Is(err, os.ErrNotExist) {
+		// mkcert -install without the key, e.g. a CA shared from another machine
+		return nil, fmt.Errorf("the local CA in %s has no %s, it can't sign certificates", dir, localCAKeyFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local CA key: %w", err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("invalid local CA certificate %s", certPath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local CA certificate %s: %w", certPath, err)
+	}
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local CA key in %s: %w", dir, err)
+	}
+	return &LocalCA{Cert: cert, Key: key, CertPath: certPath}, nil
+}
+
+// parsePrivateKey parses a PEM private key in the PKCS #8, PKCS #1 or EC format
+func parsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported private key format %s", block.Type)
+}
+
+// getLocalCAName returns the name of the local CA, with the user and machine it was
+// created on to tell apart the CAs of several machines in a trust store
+func getLocalCAName() string {
+	name := "fleet"
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return "Fleet Local CA " + name
+}
+
+// createLocalCA creates a CA valid for 10 years in dir
+func createLocalCA(dir string) (*LocalCA, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 3072)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+	serial, err := newCertificateSerial()
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode CA key: %w", err)
+	}
+	keyID := sha1.Sum(publicKey)
+
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"Fleet Local Development"},
+			CommonName:   getLocalCAName(),
+		},
+		SubjectKeyId:          keyID[:],
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode CA key: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, localCAKeyFile), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0400); err != nil {
+		return nil, fmt.Errorf("failed to write CA key: %w", err)
+	}
+	certPath := filepath.Join(dir, localCACertFile)
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write CA certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalCA{Cert: cert, Key: key, CertPath: certPath}, nil
+}
+
+// newCertificateSerial returns a random serial number. Browsers reject two
+// certificates of the same CA with the same serial.
+func newCertificateSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serial, nil
+}
+
+// isLocalCATrusted reports whether the system trust store has the local CA
+func isLocalCATrusted(ca *LocalCA) bool {
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		return false
+	}
+	_, err = ca.Cert.Verify(x509.VerifyOptions{Roots: roots})
+	return err == nil
+}
+
+// isSignedByLocalCA reports whether a certificate was issued by the local CA
+func isSignedByLocalCA(certPath string, ca *LocalCA) bool {
+	cert, err := readCertificate(certPath)
+	return err == nil && cert.CheckSignatureFrom(ca.Cert) == nil
+}
+
+// ensureLocalCA creates the local CA if needed and installs it into the trust stores.
+// mkcert does both when it is installed, and also covers Firefox and Java.
+func ensureLocalCA() (*LocalCA, error) {
+	dir := getLocalCADir()
+	if hasMkcert() {
+		output, err := newCommand("mkcert", "-install").CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("mkcert -install failed: %v\n%s", err, output)
+		}
+		ca, err := loadLocalCA(dir)
+		if err == nil && ca == nil {
+			err = fmt.Errorf("mkcert created no CA in %s", dir)
+		}
+		return ca, err
+	}
+
+	ca, err := loadLocalCA(dir)
+	if err != nil {
+		return nil, err
+	}
+	if ca == nil {
+		infof("🔐 Creating the local CA in %s\n", dir)
+		if ca, err = createLocalCA(dir); err != nil {
+			return nil, err
+		}
+	}
+	if !isLocalCATrusted(ca) {
+		infoln("🔐 Installing the local CA into the system trust store")
+		if err := installLocalCA(ca); err != nil {
+			return ca, err
+		}
+	}
+	installLocalCAInNSS(ca)
+	return ca, nil
+}
+
+// getLinuxTrustStore returns the folder of the CA certificates of the system and the
+// command updating the bundle, for Debian, Alpine, Fedora and Arch based systems
+func getLinuxTrustStore() (string, []string) {
+	switch {
+	case dirExists("/usr/local/share/ca-certificates"):
+		return "/usr/local/share/ca-certificates", []string{"update-ca-certificates"}
+	case dirExists("/etc/pki/ca-trust/source/anchors"):
+		return "/etc/pki/ca-trust/source/anchors", []string{"update-ca-trust", "extract"}
+	case dirExists("/etc/ca-certificates/trust-source/anchors"):
+		return "/etc/ca-certificates/trust-source/anchors", []string{"trust", "extract-compat"}
+	}
+	return "", nil
+}
+
+// dirExists reports whether path is a directory
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// getLinuxTrustStorePath returns where the local CA is copied in the system trust
+// store. The serial tells apart the CAs of several users.
+func getLinuxTrustStorePath(dir string, ca *LocalCA) string {
+	return filepath.Join(dir, fmt.Sprintf("fleet-local-ca-%s.crt", ca.Cert.SerialNumber.Text(16)))
+}
+
+// installLocalCA adds the local CA to the system trust store
+func installLocalCA(ca *LocalCA) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return runElevated(PrivilegedOperation{
+			Description: "Trusting the local CA",
+			Command:     "security",
+			Args:        []string{"add-trusted-cert", "-d", "-k", "/Library/Keychains/System.keychain", ca.CertPath},
+		})
+	case "windows":
+		// The store of the user doesn't need administrator rights, Windows asks to confirm
+		return newCommand("certutil", "-addstore", "-user", "-f", "Root", ca.CertPath).Run()
+	}
+
+	dir, update := getLinuxTrustStore()
+	if dir == "" {
+		return fmt.Errorf("no system trust store found, install mkcert or add %s to it by hand", ca.CertPath)
+	}
+	certPEM, err := os.ReadFile(ca.CertPath)
+	if err != nil {
+		return err
+	}
+	if err := WriteFileWithPrivileges(getLinuxTrustStorePath(dir, ca), certPEM, 0644); err != nil {
+		return fmt.Errorf("failed to add the local CA to %s: %w", dir, err)
+	}
+	return RunWithPrivileges(PrivilegedOperation{
+		Description: "Updating the system trust store",
+		Command:     update[0],
+		Args:        update[1:],
+	})
+}
+
+// uninstallLocalCA removes the local CA from the system trust store
+func uninstallLocalCA(ca *LocalCA) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return runElevated(PrivilegedOperation{
+			Description: "Removing the local CA",
+			Command:     "security",
+			Args:        []string{"remove-trusted-cert", "-d", ca.CertPath},
+		})
+	case "windows":
+		return newCommand("certutil", "-delstore", "-user", "Root", ca.Cert.SerialNumber.Text(16)).Run()
+	}
+
+	dir, update := getLinuxTrustStore()
+	if dir == "" {
+		return nil
+	}
+	path := getLinuxTrustStorePath(dir, ca)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	if err := RunWithPrivileges(PrivilegedOperation{
+		Description: "Removing the local CA",
+		Command:     "rm",
+		Args:        []string{"-f", path},
+	}); err != nil {
+		return err
+	}
+	return RunWithPrivileges(PrivilegedOperation{
+		Description: "Updating the system trust store",
+		Command:     update[0],
+		Args:        update[1:],
+	})
+}
+
+// getNSSDatabases returns the certificate databases of Firefox profiles and of Chrome on
+// Linux, which don't read the system trust store
+func getNSSDatabases() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	patterns := []string{
+		filepath.Join(home, ".pki", "nssdb"),
+		filepath.Join(home, ".mozilla", "firefox", "*"),
+		filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox", "*"),
+		filepath.Join(home, "Library", "Application Support", "Firefox", "Profiles", "*"),
+	}
+	var databases []string
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			if _, err := os.Stat(filepath.Join(match, "cert9.db")); err == nil {
+				databases = append(databases, match)
+			}
+		}
+	}
+	return databases
+}
+
+// installLocalCAInNSS adds the local CA to the browser databases with certutil of NSS.
+// Without it, Firefox keeps rejecting the certificates.
+func installLocalCAInNSS(ca *LocalCA) {
+	databases := getNSSDatabases()
+	if len(databases) == 0 {
+		return
+	}
+	if _, err := exec.LookPath("certutil"); err != nil || runtime.GOOS == "windows" {
+		warnf("⚠️  Firefox doesn't use the system trust store: install mkcert or NSS tools (certutil) and run 'fleet ssl trust' again\n")
+		return
+	}
+	for _, db := range databases {
+		args := []string{"-A", "-d", "sql:" + db, "-t", "C,,", "-n", ca.Cert.Subject.CommonName, "-i", ca.CertPath}
+		if output, err := newCommand("certutil", args...).CombinedOutput(); err != nil {
+			warnf("⚠️  Failed to add the local CA to %s: %v\n%s", db, err, output)
+		}
+	}
+}
+
+// uninstallLocalCAFromNSS removes the local CA from the browser databases
+func uninstallLocalCAFromNSS(ca *LocalCA) {
+	if _, err := exec.LookPath("certutil"); err != nil || runtime.GOOS == "windows" {
+		return
+	}
+	for _, db := range getNSSDatabases() {
+		newCommand("certutil", "-D", "-d", "sql:"+db, "-n", ca.Cert.Subject.CommonName).Run()
+	}
+}
+
+func handleSSLTrust(args []string) {
+	fs := newCommandFlagSet("ssl trust", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	lockOptions := addProjectLockFlags(fs)
+	parseFlagsAndArgs(fs, args)
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	ca, err := ensureLocalCA()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	infof("✅ %s is trusted (%s)\n", ca.Cert.Subject.CommonName, ca.CertPath)
+
+	// Replace the self-signed certificates of the project
+	config, err := loadConfig(*configFile)
+	if err != nil || !hasSSLServices(config) {
+		return
+	}
+	release := lockProject("ssl trust", lockOptions)
+	defer release()
+	if err := generateSSLCertificates(config); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	outputln("Run 'fleet up' to serve the new certificates, and restart the browser if it still warns")
+}
+
+func handleSSLUntrust(args []string) {
+	fs := newCommandFlagSet("ssl untrust", flag.ExitOnError)
+	parseFlagsAndArgs(fs, args)
+
+	dir := getLocalCADir()
+	if hasMkcert() {
+		output, err := newCommand("mkcert", "-uninstall").CombinedOutput()
+		if err != nil {
+			log.Fatalf("❌ mkcert -uninstall failed: %v\n%s", err, output)
+		}
+		infoln("✅ The local CA is no longer trusted")
+		return
+	}
+
+	ca, err := loadLocalCA(dir)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if ca == nil {
+		outputln("No local CA in " + dir)
+		return
+	}
+	if err := uninstallLocalCA(ca); err != nil {
+		log.Fatalf("❌ Failed to remove the local CA: %v", err)
+	}
+	uninstallLocalCAFromNSS(ca)
+	infof("✅ The local CA is no longer trusted, delete %s to stop signing certificates with it\n", dir)
+}
+
+func handleSSLStatus(args []string) {
+	fs := newCommandFlagSet("ssl status", flag.ExitOnError)
+	parseFlagsAndArgs(fs, args)
+
+	dir := getLocalCADir()
+	ca, err := loadLocalCA(dir)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if ca == nil {
+		outputf("No local CA in %s, run 'fleet ssl trust' to create it\n", dir)
+		return
+	}
+
+	manager := "fleet"
+	if hasMkcert() {
+		manager = "mkcert"
+	}
+	outputf("CA:       %s\n", ca.Cert.Subject.CommonName)
+	outputf("Path:     %s (managed by %s)\n", ca.CertPath, manager)
+	outputf("Expires:  %s\n", ca.Cert.NotAfter.Format("2006-01-02"))
+	outputf("Trusted:  %t\n", isLocalCATrusted(ca))
+}
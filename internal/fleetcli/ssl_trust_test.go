@@ -0,0 +1,127 @@
+package fleetcli
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SSLTrustTestSuite struct {
+	suite.Suite
+	helper            *TestHelper
+	originalDir       string
+	originalCADir     func() string
+	originalHasMkcert func() bool
+	caDir             string
+}
+
+func (suite *SSLTrustTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+
+	suite.caDir = filepath.Join(suite.helper.TempDir(), "ca")
+	suite.originalCADir = getLocalCADir
+	suite.originalHasMkcert = hasMkcert
+	getLocalCADir = func() string { return suite.caDir }
+	hasMkcert = func() bool { return false }
+}
+
+func (suite *SSLTrustTestSuite) TearDownTest() {
+	getLocalCADir = suite.originalCADir
+	hasMkcert = suite.originalHasMkcert
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *SSLTrustTestSuite) TestCreateAndLoadLocalCA() {
+	ca, err := loadLocalCA(suite.caDir)
+	suite.NoError(err)
+	suite.Nil(ca, "No CA before it is created")
+
+	created, err := createLocalCA(suite.caDir)
+	suite.Require().NoError(err)
+	suite.True(created.Cert.IsCA)
+	suite.Contains(created.Cert.Subject.CommonName, "Fleet Local CA")
+
+	info, err := os.Stat(filepath.Join(suite.caDir, localCAKeyFile))
+	suite.Require().NoError(err)
+	suite.Equal(os.FileMode(0400), info.Mode().Perm())
+
+	loaded, err := loadLocalCA(suite.caDir)
+	suite.Require().NoError(err)
+	suite.Equal(created.Cert.Raw, loaded.Cert.Raw)
+	suite.Equal(filepath.Join(suite.caDir, localCACertFile), loaded.CertPath)
+
+	suite.Require().NoError(os.Remove(filepath.Join(suite.caDir, localCAKeyFile)))
+	_, err = loadLocalCA(suite.caDir)
+	suite.ErrorContains(err, "it can't sign certificates")
+}
+
+func (suite *SSLTrustTestSuite) TestParsePrivateKey() {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	suite.Require().NoError(err)
+	key, err := parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+	suite.NoError(err)
+	suite.IsType(&rsa.PrivateKey{}, key)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.Require().NoError(err)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	suite.Require().NoError(err)
+	key, err = parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}))
+	suite.NoError(err)
+	suite.IsType(&ecdsa.PrivateKey{}, key)
+
+	_, err = parsePrivateKey([]byte("garbage"))
+	suite.Error(err)
+}
+
+func (suite *SSLTrustTestSuite) TestCertificatesSignedByLocalCA() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "shop", Image: "nginx:alpine", Port: 80, Domain: "shop.test", SSL: true},
+	}}
+	certPath := filepath.Join(".fleet", "ssl", "shop_test.crt")
+
+	// Self-signed without a CA
+	suite.Require().NoError(generateSSLCertificates(config))
+	ca, err := createLocalCA(suite.caDir)
+	suite.Require().NoError(err)
+	suite.False(isSignedByLocalCA(certPath, ca))
+
+	// Replaced once the CA exists
+	plan := newArtifactPlan()
+	planSSLCertificates(plan, config)
+	suite.Len(plan.certificates, 2, "The default and shop.test certificates are signed again")
+	suite.Require().NoError(writeArtifacts(plan))
+	suite.True(isSignedByLocalCA(certPath, ca))
+
+	certPEM, err := os.ReadFile(certPath)
+	suite.Require().NoError(err)
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	suite.Require().NoError(err)
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+	_, err = cert.Verify(x509.VerifyOptions{DNSName: "shop.test", Roots: roots})
+	suite.NoError(err, "Clients trusting the CA accept the certificate")
+
+	// Kept afterwards
+	plan = newArtifactPlan()
+	planSSLCertificates(plan, config)
+	suite.Empty(plan.certificates)
+}
+
+func TestSSLTrustSuite(t *testing.T) {
+	suite.Run(t, new(SSLTrustTestSuite))
+}
@@ -0,0 +1,48 @@
+package fleetcli
+
+import "fmt"
+
+// Ports the proxy binds on localhost when Fleet runs without privileges
+const (
+	unprivilegedHTTPPort  = 8080
+	unprivilegedHTTPSPort = 8443
+)
+
+// noPrivilegedEnvVar turns on unprivileged mode for every command, e.g. from a shell profile
+const noPrivilegedEnvVar = "FLEET_NO_PRIVILEGED"
+
+// isUnprivileged reports whether Fleet must avoid the hosts file and ports below 1024
+func isUnprivileged(flagValue bool) bool {
+	return flagValue || isEnvEnabled(noPrivilegedEnvVar)
+}
+
+// getLocalhostAlias returns the name a service answers to without hosts file entries.
+// Browsers and systemd-resolved resolve *.localhost to the loopback address.
+func getLocalhostAlias(svc *Service) string {
+	return fmt.Sprintf("%s.localhost", svc.Name)
+}
+
+// getUnprivilegedURLs returns the localhost URLs of the services behind the proxy
+func getUnprivilegedURLs(config *Config) []string {
+	var urls []string
+	for _, svc := range config.Services {
+		if getDomainForService(&svc) == "" {
+			continue
+		}
+		urls = append(urls, fmt.Sprintf("http://%s:%d", getLocalhostAlias(&svc), unprivilegedHTTPPort))
+	}
+	return urls
+}
+
+// printUnprivilegedURLs explains how to reach services when the hosts file isn't touched
+func printUnprivilegedURLs(config *Config) {
+	urls := getUnprivilegedURLs(config)
+	if len(urls) == 0 {
+		return
+	}
+
+	infof("🔓 Running without privileges: the hosts file and ports 80/443 are left alone\n")
+	for _, url := range urls {
+		outputf("   %s\n", url)
+	}
+}
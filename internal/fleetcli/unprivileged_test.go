@@ -0,0 +1,113 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type UnprivilegedTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *UnprivilegedTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *UnprivilegedTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *UnprivilegedTestSuite) TestIsUnprivileged() {
+	testCases := []struct {
+		name     string
+		flag     bool
+		env      string
+		expected bool
+	}{
+		{"flag set", true, "", true},
+		{"nothing set", false, "", false},
+		{"env enabled", false, "1", true},
+		{"env true", false, "TRUE", true},
+		{"env disabled", false, "0", false},
+		{"env false", false, "false", false},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			suite.T().Setenv(noPrivilegedEnvVar, tc.env)
+			suite.Equal(tc.expected, isUnprivileged(tc.flag))
+		})
+	}
+}
+
+func (suite *UnprivilegedTestSuite) TestGetUnprivilegedURLs() {
+	config := &Config{
+		Project: "test",
+		Services: []Service{
+			{Name: "web", Image: "nginx", Domain: "shop.test"},
+			{Name: "api", Image: "node", Port: 3000},
+			{Name: "database", Image: "postgres"},
+		},
+	}
+
+	urls := getUnprivilegedURLs(config)
+	suite.Equal([]string{"http://web.localhost:8080", "http://api.localhost:8080"}, urls)
+}
+
+func (suite *UnprivilegedTestSuite) TestNginxConfigAddsLocalhostAliases() {
+	config := &Config{
+		Project:  "test",
+		Services: []Service{{Name: "web", Image: "nginx", Domain: "shop.test"}},
+	}
+
+	nginxConf, err := generateNginxConfig(config)
+	suite.NoError(err)
+	suite.Contains(nginxConf, "server_name shop.test;", "Aliases are only added in unprivileged mode")
+
+	config.Unprivileged = true
+	nginxConf, err = generateNginxConfig(config)
+	suite.NoError(err)
+	suite.Contains(nginxConf, "server_name shop.test web.localhost;")
+}
+
+func (suite *UnprivilegedTestSuite) TestSSLRedirectKeepsHighPort() {
+	config := &Config{
+		Project:      "test",
+		Unprivileged: true,
+		Services:     []Service{{Name: "web", Image: "nginx", Domain: "shop.test", SSL: true}},
+	}
+
+	nginxConf, err := generateNginxConfig(config)
+	suite.NoError(err)
+	suite.Contains(nginxConf, "return 301 https://$host:8443$request_uri;")
+	suite.NotContains(nginxConf, "https://$server_name$request_uri")
+}
+
+func (suite *UnprivilegedTestSuite) TestProxyBindsHighPortsOnLocalhost() {
+	config := &Config{
+		Project:  "test",
+		Services: []Service{{Name: "web", Image: "nginx", Domain: "shop.test", SSL: true}},
+	}
+
+	compose := newDockerCompose()
+	addNginxProxyToCompose(compose, config)
+	suite.Equal([]string{"80:80", "443:443"}, compose.Services["nginx-proxy"].Ports)
+
+	config.Unprivileged = true
+	compose = newDockerCompose()
+	addNginxProxyToCompose(compose, config)
+	suite.Equal([]string{"127.0.0.1:8080:80", "127.0.0.1:8443:443"}, compose.Services["nginx-proxy"].Ports)
+}
+
+func TestUnprivilegedSuite(t *testing.T) {
+	suite.Run(t, new(UnprivilegedTestSuite))
+}
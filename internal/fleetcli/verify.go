@@ -0,0 +1,312 @@
+package fleetcli
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCheckStatus is the status a check expects when none is set
+	defaultCheckStatus = http.StatusOK
+	// defaultCheckTimeout is how long a check retries while the service is starting
+	defaultCheckTimeout = 30 * time.Second
+	// checkRetryInterval is the pause between two attempts of a failing check
+	checkRetryInterval = time.Second
+	// checkBodyLimit is how much of a response is searched for the expected body
+	checkBodyLimit = 1 << 20
+)
+
+// getCheckStatus returns the status the check expects
+func getCheckStatus(check *HTTPCheck) int {
+	if check.Status == 0 {
+		return defaultCheckStatus
+	}
+	return check.Status
+}
+
+// getCheckTimeout returns how long the check may retry
+func getCheckTimeout(check *HTTPCheck) time.Duration {
+	if timeout, err := time.ParseDuration(check.Timeout); err == nil {
+		return timeout
+	}
+	return defaultCheckTimeout
+}
+
+// validateHTTPChecks checks the [[services.checks]] of a service
+func validateHTTPChecks(svc *Service) error {
+	for _, check := range svc.Checks {
+		switch {
+		case check.URL == "":
+			return fmt.Errorf("service %s: checks need a url", svc.Name)
+		case strings.HasPrefix(check.URL, "/"):
+			if getDomainForService(svc) == "" {
+				return fmt.Errorf("service %s: check %s needs a domain or port, or a full URL", svc.Name, check.URL)
+			}
+		default:
+			parsed, err := url.Parse(check.URL)
+			if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("service %s: check url %q must be a path like /health or an http(s) URL", svc.Name, check.URL)
+			}
+		}
+
+		if check.Status != 0 && (check.Status < 100 || check.Status > 599) {
+			return fmt.Errorf("service %s: check %s has invalid status %d", svc.Name, check.URL, check.Status)
+		}
+		if check.Timeout != "" {
+			if timeout, err := time.ParseDuration(check.Timeout); err != nil || timeout <= 0 {
+				return fmt.Errorf("service %s: check %s has invalid timeout %q", svc.Name, check.URL, check.Timeout)
+			}
+		}
+	}
+	return nil
+}
+
+// getCheckURL returns the URL a check requests. Paths are requested on the service domain,
+// or on the forwarded port in a cloud IDE.
+func getCheckURL(config *Config, svc *Service, check *HTTPCheck) string {
+	if !strings.HasPrefix(check.URL, "/") {
+		return check.URL
+	}
+	if config.Cloud {
+		for _, port := range getCloudPorts(config) {
+			if port.Service == svc.Name {
+				return fmt.Sprintf("http://localhost:%d%s", port.HostPort, check.URL)
+			}
+		}
+	}
+	return "http://" + getSiteDomain(svc) + check.URL
+}
+
+// getProxyAddress returns where the proxy listens on the host for a port of a project
+// domain, or "" when the address isn't served by the proxy. Checks connect to it directly,
+// so they test the proxy even when the hosts file isn't set up.
+func getProxyAddress(config *Config, host, port string) string {
+	if config.Cloud || !shouldAddNginxProxy(config) || !containsString(getProjectDomains(config), host) {
+		return ""
+	}
+	return getProxyListenAddress(config, port)
+}
+
+// getProxyListenAddress returns where the proxy listens on the host for port 80 or 443,
+// or "" for other ports
+func getProxyListenAddress(config *Config, port string) string {
+	switch {
+	case port == "80" && config.Unprivileged:
+		return fmt.Sprintf("127.0.0.1:%d", unprivilegedHTTPPort)
+	case port == "443" && config.Unprivileged:
+		return fmt.Sprintf("127.0.0.1:%d", unprivilegedHTTPSPort)
+	case port == "80" || port == "443":
+		return net.JoinHostPort("127.0.0.1", port)
+	}
+	return ""
+}
+
+// newCheckClient returns the HTTP client of checks. resolve returns the address to connect
+// to for a host and port, or "" to connect to them.
+func newCheckClient(resolve func(host, port string) string) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			if host, port, err := net.SplitHostPort(address); err == nil {
+				if proxy := resolve(host, port); proxy != "" {
+					address = proxy
+				}
+			}
+			return dialer.DialContext(ctx, network, address)
+		},
+		// Checks test routing, not certificates, and Fleet's certificates are self-signed
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	}
+	return &http.Client{
+		Transport: transport,
+		// A check expecting a redirect must see it, not the page it points to
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// describeCheckError explains why a request didn't get a response
+func describeCheckError(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, io.EOF) || strings.Contains(err.Error(), "EOF"):
+		// nginx closes the connection of domains it has no vhost for
+		return "the proxy closed the connection, it has no vhost for this domain"
+	case strings.Contains(err.Error(), "connection refused"):
+		return "connection refused, is the project running?"
+	case strings.Contains(err.Error(), "no such host"):
+		return "the domain doesn't resolve, run 'fleet hosts add' or use a path instead of a full URL"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timed out"
+	}
+	return err.Error()
+}
+
+// runHTTPCheck requests a URL until it answers as the check expects or the check times out
+func runHTTPCheck(ctx context.Context, client *http.Client, checkURL string, check *HTTPCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, getCheckTimeout(check))
+	defer cancel()
+
+	for {
+		err := requestHTTPCheck(ctx, client, checkURL, check)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(checkRetryInterval):
+		}
+	}
+}
+
+// requestHTTPCheck sends a check's request once
+func requestHTTPCheck(ctx context.Context, client *http.Client, checkURL string, check *HTTPCheck) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checkURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "fleet-verify/"+version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.New(describeCheckError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != getCheckStatus(check) {
+		message := fmt.Sprintf("got %s, want %d", resp.Status, getCheckStatus(check))
+		if resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusGatewayTimeout {
+			message += ", the service behind the proxy isn't answering"
+		}
+		return errors.New(message)
+	}
+	if check.Body != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, checkBodyLimit))
+		if err != nil {
+			return fmt.Errorf("failed to read the response: %w", err)
+		}
+		if !strings.Contains(string(body), check.Body) {
+			return fmt.Errorf("response doesn't contain %q", check.Body)
+		}
+	}
+	return nil
+}
+
+// CheckResult is the outcome of one check
+type CheckResult struct {
+	Service  string
+	URL      string
+	Err      error
+	Duration time.Duration
+}
+
+// runServiceChecks runs the checks of the selected services, or of all services, in parallel.
+// Results are in config order.
+func runServiceChecks(ctx context.Context, config *Config, services []string) []CheckResult {
+	client := newCheckClient(func(host, port string) string {
+		return getProxyAddress(config, host, port)
+	})
+
+	var results []CheckResult
+	var checks []*HTTPCheck
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if len(services) > 0 && !containsString(services, svc.Name) {
+			continue
+		}
+		for j := range svc.Checks {
+			results = append(results, CheckResult{Service: svc.Name, URL: getCheckURL(config, svc, &svc.Checks[j])})
+			checks = append(checks, &svc.Checks[j])
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(result *CheckResult, check *HTTPCheck) {
+			defer wg.Done()
+			start := time.Now()
+			result.Err = runHTTPCheck(ctx, client, result.URL, check)
+			result.Duration = time.Since(start)
+		}(&results[i], checks[i])
+	}
+	wg.Wait()
+	return results
+}
+
+// printCheckResults prints whether each check passed and returns false when one failed
+func printCheckResults(results []CheckResult) bool {
+	ok := true
+	for _, result := range results {
+		if result.Err != nil {
+			outputf("%s %-20s %s: %v\n", emojiOr("❌", "--"), result.Service, result.URL, result.Err)
+			ok = false
+			continue
+		}
+		outputf("%s %-20s %s (%s)\n", emojiOr("✅", "ok"), result.Service, result.URL, result.Duration.Round(time.Millisecond))
+	}
+	return ok
+}
+
+// verifyServices runs the checks of a project and returns false when one failed
+func verifyServices(config *Config, services []string) bool {
+	results := runServiceChecks(rootContext, config, services)
+	if len(results) == 0 {
+		infoln("No checks configured. Add [[services.checks]] with a url to a service")
+		return true
+	}
+
+	infof("🔎 Verifying %d HTTP checks...\n", len(results))
+	if !printCheckResults(results) {
+		warnln("   Run 'fleet logs <service>' to see why a service isn't answering")
+		return false
+	}
+	return true
+}
+
+func handleVerify() {
+	fs := newCommandFlagSet("verify", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	noPrivileged := fs.Bool("no-privileged", false, "The project was started with --no-privileged")
+	cloud := fs.Bool("cloud", false, "The project was started with --cloud")
+
+	services := parseFlagsAndArgs(fs, os.Args[2:])
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	config.Unprivileged = isUnprivileged(*noPrivileged)
+	config.Cloud = isCloud(*cloud)
+
+	for _, name := range services {
+		if findService(config, name) == nil {
+			log.Fatalf("❌ Unknown service: %s", name)
+		}
+	}
+
+	if !verifyServices(config, services) {
+		exitIfInterrupted()
+		os.Exit(1)
+	}
+}
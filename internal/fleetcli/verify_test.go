@@ -0,0 +1,178 @@
+package fleetcli
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type VerifyTestSuite struct {
+	suite.Suite
+	server *httptest.Server
+	client *http.Client
+}
+
+func (suite *VerifyTestSuite) SetupTest() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "shop.test" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("status: ok"))
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/dashboard", http.StatusFound)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	suite.server = httptest.NewServer(mux)
+
+	// The test server plays the proxy for shop.test
+	address := suite.server.Listener.Addr().String()
+	suite.client = newCheckClient(func(host, port string) string {
+		if host == "shop.test" {
+			return address
+		}
+		return ""
+	})
+}
+
+func (suite *VerifyTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *VerifyTestSuite) TestValidateHTTPChecks() {
+	testCases := []struct {
+		name    string
+		service Service
+		wantErr string
+	}{
+		{"path on domain", Service{Name: "web", Domain: "shop.test", Checks: []HTTPCheck{{URL: "/health", Status: 204, Timeout: "10s"}}}, ""},
+		{"full URL", Service{Name: "worker", Checks: []HTTPCheck{{URL: "http://localhost:9000/ping"}}}, ""},
+		{"missing url", Service{Name: "web", Port: 80, Checks: []HTTPCheck{{Body: "ok"}}}, "need a url"},
+		{"path without domain", Service{Name: "worker", Checks: []HTTPCheck{{URL: "/health"}}}, "needs a domain"},
+		{"not http", Service{Name: "web", Checks: []HTTPCheck{{URL: "ftp://shop.test"}}}, "must be a path"},
+		{"invalid status", Service{Name: "web", Port: 80, Checks: []HTTPCheck{{URL: "/", Status: 42}}}, "invalid status"},
+		{"invalid timeout", Service{Name: "web", Port: 80, Checks: []HTTPCheck{{URL: "/", Timeout: "soon"}}}, "invalid timeout"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			err := validateHTTPChecks(&tc.service)
+			if tc.wantErr == "" {
+				suite.NoError(err)
+			} else {
+				suite.ErrorContains(err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *VerifyTestSuite) TestGetCheckURL() {
+	config := &Config{Services: []Service{
+		{Name: "web", Domain: "shop.test", Port: 3000},
+		{Name: "api", Port: 8000},
+	}}
+	health := &HTTPCheck{URL: "/health"}
+
+	suite.Equal("http://shop.test/health", getCheckURL(config, &config.Services[0], health))
+	suite.Equal("http://api.test/health", getCheckURL(config, &config.Services[1], health))
+	suite.Equal("http://localhost:9000/", getCheckURL(config, &config.Services[0], &HTTPCheck{URL: "http://localhost:9000/"}))
+
+	config.Cloud = true
+	suite.Equal("http://localhost:8000/health", getCheckURL(config, &config.Services[1], health))
+}
+
+func (suite *VerifyTestSuite) TestGetProxyAddress() {
+	config := &Config{Services: []Service{{Name: "web", Domain: "shop.test", Port: 3000}}}
+
+	suite.Equal("127.0.0.1:80", getProxyAddress(config, "shop.test", "80"))
+	suite.Equal("127.0.0.1:443", getProxyAddress(config, "shop.test", "443"))
+	suite.Equal("", getProxyAddress(config, "shop.test", "3000"))
+	suite.Equal("", getProxyAddress(config, "example.com", "80"))
+
+	config.Unprivileged = true
+	suite.Equal("127.0.0.1:8080", getProxyAddress(config, "shop.test", "80"))
+	suite.Equal("127.0.0.1:8443", getProxyAddress(config, "shop.test", "443"))
+
+	config.Unprivileged = false
+	config.Cloud = true
+	suite.Equal("", getProxyAddress(config, "shop.test", "80"))
+}
+
+func (suite *VerifyTestSuite) TestRunHTTPCheck() {
+	testCases := []struct {
+		name    string
+		url     string
+		check   HTTPCheck
+		wantErr string
+	}{
+		{"passes through the proxy", "http://shop.test/health", HTTPCheck{Body: "ok"}, ""},
+		{"wrong body", "http://shop.test/health", HTTPCheck{Body: "ready"}, `doesn't contain "ready"`},
+		{"redirect is not followed", "http://shop.test/login", HTTPCheck{Status: http.StatusFound}, ""},
+		{"wrong status", "http://shop.test/login", HTTPCheck{}, "got 302 Found, want 200"},
+		{"bad gateway", "http://shop.test/broken", HTTPCheck{}, "isn't answering"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.check.Timeout = "100ms"
+			err := runHTTPCheck(context.Background(), suite.client, tc.url, &tc.check)
+			if tc.wantErr == "" {
+				suite.NoError(err)
+			} else {
+				suite.ErrorContains(err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *VerifyTestSuite) TestMissingVhost() {
+	// nginx closes connections for domains without a vhost
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().NoError(err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	client := newCheckClient(func(host, port string) string { return listener.Addr().String() })
+	err = runHTTPCheck(context.Background(), client, "http://shop.test/", &HTTPCheck{Timeout: "100ms"})
+	suite.ErrorContains(err, "no vhost")
+}
+
+func (suite *VerifyTestSuite) TestRunServiceChecks() {
+	config := &Config{Services: []Service{
+		{Name: "web", Checks: []HTTPCheck{{URL: suite.server.URL + "/login", Status: http.StatusFound}}},
+		{Name: "api", Checks: []HTTPCheck{{URL: suite.server.URL + "/broken", Timeout: "100ms"}}},
+		{Name: "db"},
+	}}
+
+	results := runServiceChecks(context.Background(), config, nil)
+	suite.Require().Len(results, 2)
+	suite.Equal("web", results[0].Service)
+	suite.NoError(results[0].Err)
+	suite.Equal("api", results[1].Service)
+	suite.Error(results[1].Err)
+	suite.False(printCheckResults(results))
+
+	results = runServiceChecks(context.Background(), config, []string{"web"})
+	suite.Require().Len(results, 1)
+	suite.True(printCheckResults(results))
+}
+
+func TestVerifySuite(t *testing.T) {
+	suite.Run(t, new(VerifyTestSuite))
+}
@@ -0,0 +1,251 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// featureRequirement is the minimum Docker Engine and Compose plugin version a generated
+// compose feature needs. Empty versions mean no requirement.
+type featureRequirement struct {
+	Feature    string
+	MinEngine  string
+	MinCompose string
+	// uses reports whether the generated compose file relies on the feature
+	uses func(compose *DockerCompose) bool
+}
+
+// featureRequirements is the compatibility matrix between generated YAML and Docker
+var featureRequirements = []featureRequirement{
+	{
+		Feature:    "docker compose plugin",
+		MinEngine:  "20.10.0",
+		MinCompose: "2.0.0",
+		uses:       func(compose *DockerCompose) bool { return true },
+	},
+	{
+		Feature:   "host.docker.internal via host-gateway (Xdebug)",
+		MinEngine: "20.10.0",
+		uses: func(compose *DockerCompose) bool {
+			for _, service := range compose.Services {
+				for _, host := range service.ExtraHosts {
+					if strings.HasSuffix(host, ":host-gateway") {
+						return true
+					}
+				}
+			}
+			return false
+		},
+	},
+	{
+		Feature:    "project-scoped volume names",
+		MinCompose: "2.0.0",
+		uses: func(compose *DockerCompose) bool {
+			for _, volume := range compose.Volumes {
+				if volume.Name != "" {
+					return true
+				}
+			}
+			return false
+		},
+	},
+	{
+		Feature:    "external networks of other projects",
+		MinCompose: "2.0.0",
+		uses: func(compose *DockerCompose) bool {
+			for _, network := range compose.Networks {
+				if network.External {
+					return true
+				}
+			}
+			return false
+		},
+	},
+	{
+		Feature:    "watch rules (fleet dev)",
+		MinCompose: "2.22.0",
+		uses: func(compose *DockerCompose) bool {
+			for _, service := range compose.Services {
+				if service.Develop != nil {
+					return true
+				}
+			}
+			return false
+		},
+	},
+}
+
+// DockerVersions are the versions of the local Docker installation
+type DockerVersions struct {
+	Engine  string
+	Compose string
+}
+
+// RequirementResult is the outcome of checking one feature against the local versions
+type RequirementResult struct {
+	Requirement featureRequirement
+	Problems    []string
+}
+
+// parseVersion parses a version like "v2.24.6-desktop.1" or "24.0.7" into numbers
+func parseVersion(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if idx := strings.IndexAny(version, "-+ "); idx >= 0 {
+		version = version[:idx]
+	}
+
+	var parts []int
+	for _, part := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// compareVersions returns -1, 0 or 1 when a is older, equal or newer than b
+func compareVersions(a, b string) int {
+	pa, pb := parseVersion(a), parseVersion(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// queryDockerEngineVersion returns the version of the Docker Engine
+func queryDockerEngineVersion() (string, error) {
+	engine, err := newCommand("docker", "version", "--format", "{{.Server.Version}}").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to query Docker Engine version (is Docker running?): %w", err)
+	}
+	return strings.TrimSpace(string(engine)), nil
+}
+
+// getDockerVersions queries the Docker Engine version and the version of the compose
+// implementation in use
+var getDockerVersions = func() (*DockerVersions, error) {
+	engine, err := queryDockerEngineVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	compose, err := getComposeCLI()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DockerVersions{
+		Engine:  engine,
+		Compose: compose.Version,
+	}, nil
+}
+
+// checkFeatureRequirements checks the features used by a compose file against the local versions
+func checkFeatureRequirements(compose *DockerCompose, versions *DockerVersions) []RequirementResult {
+	var results []RequirementResult
+	for _, requirement := range featureRequirements {
+		if !requirement.uses(compose) {
+			continue
+		}
+
+		result := RequirementResult{Requirement: requirement}
+		if requirement.MinEngine != "" && compareVersions(versions.Engine, requirement.MinEngine) < 0 {
+			result.Problems = append(result.Problems, fmt.Sprintf("needs Docker Engine %s or newer (found %s)", requirement.MinEngine, versions.Engine))
+		}
+		if requirement.MinCompose != "" && compareVersions(versions.Compose, requirement.MinCompose) < 0 {
+			result.Problems = append(result.Problems, fmt.Sprintf("needs Docker Compose %s or newer (found %s)", requirement.MinCompose, versions.Compose))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// unmetRequirements returns the results that have problems
+func unmetRequirements(results []RequirementResult) []RequirementResult {
+	var unmet []RequirementResult
+	for _, result := range results {
+		if len(result.Problems) > 0 {
+			unmet = append(unmet, result)
+		}
+	}
+	return unmet
+}
+
+// warnUnsupportedFeatures warns when the local Docker can't run the generated compose file.
+// It stays quiet when Docker can't be queried, since starting will report that anyway.
+func warnUnsupportedFeatures(compose *DockerCompose) {
+	versions, err := getDockerVersions()
+	if err != nil {
+		return
+	}
+
+	for _, result := range unmetRequirements(checkFeatureRequirements(compose, versions)) {
+		warnf("⚠️  Warning: %s %s\n", result.Requirement.Feature, strings.Join(result.Problems, ", "))
+	}
+}
+
+func handleVersion() {
+	fs := newCommandFlagSet("version", flag.ExitOnError)
+	check := fs.Bool("check", false, "Check Docker versions against the current config")
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+
+	if len(os.Args) > 2 {
+		fs.Parse(os.Args[2:])
+	}
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	outputf("Fleet CLI v%s\n", version)
+	if !*check {
+		return
+	}
+
+	versions, err := getDockerVersions()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	outputf("🐳 Docker Engine:  %s\n", versions.Engine)
+	outputf("🧩 Docker Compose: %s\n", versions.Compose)
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+
+	outputf("\nFeatures used by %s:\n", *configFile)
+	results := checkFeatureRequirements(generateDockerCompose(config), versions)
+	for _, result := range results {
+		if len(result.Problems) > 0 {
+			outputf("  %s %s: %s\n", emojiOr("❌", "--"), result.Requirement.Feature, strings.Join(result.Problems, ", "))
+		} else {
+			outputf("  %s %s\n", emojiOr("✅", "ok"), result.Requirement.Feature)
+		}
+	}
+
+	if len(unmetRequirements(results)) > 0 {
+		infoln("\nUpgrade Docker to run this project: https://docs.docker.com/engine/install/")
+		os.Exit(1)
+	}
+	outputln("\n✅ Local Docker supports this project")
+}
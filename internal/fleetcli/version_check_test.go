@@ -0,0 +1,88 @@
+package fleetcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type VersionCheckTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+}
+
+func (suite *VersionCheckTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+}
+
+func (suite *VersionCheckTestSuite) TearDownTest() {
+	suite.helper.Cleanup()
+}
+
+func (suite *VersionCheckTestSuite) TestCompareVersions() {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"24.0.7", "20.10.0", 1},
+		{"20.10.0", "20.10", 0},
+		{"v2.24.6-desktop.1", "2.24.6", 0},
+		{"2.3.3", "2.10.0", -1},
+		{"19.03.12", "20.10.0", -1},
+		{"", "2.0.0", -1},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.a+"_"+tt.b, func() {
+			suite.Equal(tt.expected, compareVersions(tt.a, tt.b))
+		})
+	}
+}
+
+func (suite *VersionCheckTestSuite) TestCheckFeatureRequirements() {
+	compose := &DockerCompose{
+		Services: map[string]DockerService{
+			"web-php": {Image: "php:8.3-fpm-alpine", ExtraHosts: []string{"host.docker.internal:host-gateway"}},
+		},
+		Volumes: map[string]DockerVolume{},
+	}
+
+	results := checkFeatureRequirements(compose, &DockerVersions{Engine: "19.03.12", Compose: "2.24.0"})
+
+	features := []string{}
+	for _, result := range results {
+		features = append(features, result.Requirement.Feature)
+	}
+	suite.Equal([]string{"docker compose plugin", "host.docker.internal via host-gateway (Xdebug)"}, features)
+
+	unmet := unmetRequirements(results)
+	suite.Require().Len(unmet, 2)
+	suite.Equal([]string{"needs Docker Engine 20.10.0 or newer (found 19.03.12)"}, unmet[1].Problems)
+}
+
+func (suite *VersionCheckTestSuite) TestRequirementsMetOnCurrentDocker() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "app", Image: "php:8.3-fpm", Database: "mysql:8.0"},
+		},
+	}
+
+	results := checkFeatureRequirements(generateDockerCompose(config), &DockerVersions{Engine: "26.1.0", Compose: "v2.27.0"})
+
+	suite.NotEmpty(results)
+	suite.Empty(unmetRequirements(results))
+}
+
+func (suite *VersionCheckTestSuite) TestOldComposeIsReported() {
+	compose := &DockerCompose{Services: map[string]DockerService{}}
+
+	unmet := unmetRequirements(checkFeatureRequirements(compose, &DockerVersions{Engine: "24.0.0", Compose: "1.29.2"}))
+
+	suite.Require().Len(unmet, 1)
+	suite.Contains(unmet[0].Problems[0], "Docker Compose 2.0.0")
+}
+
+func TestVersionCheckSuite(t *testing.T) {
+	suite.Run(t, new(VersionCheckTestSuite))
+}
@@ -0,0 +1,387 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// versionDataSchema is the schema of versions.json this build understands
+const versionDataSchema = 1
+
+// Where fleet versions update downloads the latest version data from
+const (
+	versionDataURL    = "https://raw.githubusercontent.com/jmaisonguillard/fleet/main/internal/fleetcli/config/versions.json"
+	versionDataURLEnv = "FLEET_VERSIONS_URL"
+)
+
+// imageReference matches an image name with a tag, without whitespace or a digest
+var imageReference = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]*(:[A-Za-z0-9._-]+)?$`)
+
+// VersionSet holds the images of the supported versions of a runtime or service
+type VersionSet struct {
+	Default string            `json:"default"`
+	Images  map[string]string `json:"images"`
+}
+
+// VersionData holds the supported versions of every runtime and service. It ships in
+// config/versions.json and can be refreshed with fleet versions update.
+type VersionData struct {
+	Schema   int                   `json:"schema"`
+	Updated  string                `json:"updated"`
+	PHP      VersionSet            `json:"php"`
+	Node     VersionSet            `json:"node"`
+	Ruby     VersionSet            `json:"ruby"`
+	Database map[string]VersionSet `json:"database"`
+	Cache    map[string]VersionSet `json:"cache"`
+	Search   map[string]VersionSet `json:"search"`
+	Email    map[string]VersionSet `json:"email"`
+	Compat   map[string]VersionSet `json:"compat"`
+	AI       map[string]VersionSet `json:"ai"`
+	Queue    map[string]VersionSet `json:"queue"`
+}
+
+// getVersionDataPath returns where fleet versions update stores the downloaded data
+var getVersionDataPath = func() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".fleet", "versions.json")
+}
+
+// versionData is the version data in use, read before the maps built from it
+var versionData = loadVersionData()
+
+// imageMap returns the images by version, with the image of the default version under
+// "default", the form the service code looks versions up in
+func (s VersionSet) imageMap() map[string]string {
+	images := make(map[string]string, len(s.Images)+1)
+	for version, image := range s.Images {
+		images[version] = image
+	}
+	images["default"] = s.Images[s.Default]
+	return images
+}
+
+// getVersions returns the versions of a set in order, without aliases like latest
+func (s VersionSet) getVersions() []string {
+	var versions []string
+	for version := range s.Images {
+		if version != "latest" && version != "lts" {
+			versions = append(versions, version)
+		}
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// imageMaps returns the image maps of a group of services by service type
+func imageMaps(sets map[string]VersionSet) map[string]map[string]string {
+	maps := make(map[string]map[string]string, len(sets))
+	for serviceType, set := range sets {
+		maps[serviceType] = set.imageMap()
+	}
+	return maps
+}
+
+// getDefaultVersions returns the default version of every service type in a group
+func getDefaultVersions(sets map[string]VersionSet) map[string]string {
+	defaults := make(map[string]string, len(sets))
+	for serviceType, set := range sets {
+		defaults[serviceType] = set.Default
+	}
+	return defaults
+}
+
+// getVersionLists returns the versions every service type in a group accepts, aliases included
+func getVersionLists(sets map[string]VersionSet) map[string][]string {
+	lists := make(map[string][]string, len(sets))
+	for serviceType, set := range sets {
+		versions := make([]string, 0, len(set.Images))
+		for version := range set.Images {
+			versions = append(versions, version)
+		}
+		sort.Strings(versions)
+		lists[serviceType] = versions
+	}
+	return lists
+}
+
+// validateVersionSet checks that a set has images and that its default is one of them
+func validateVersionSet(name string, set VersionSet) error {
+	if len(set.Images) == 0 {
+		return fmt.Errorf("%s: no images", name)
+	}
+	if _, exists := set.Images[set.Default]; !exists {
+		return fmt.Errorf("%s: default version %q has no image", name, set.Default)
+	}
+	for version, image := range set.Images {
+		if version == "" || version == "default" {
+			return fmt.Errorf("%s: invalid version %q", name, version)
+		}
+		if !imageReference.MatchString(image) {
+			return fmt.Errorf("%s %s: invalid image %q", name, version, image)
+		}
+	}
+	return nil
+}
+
+// versionRuntime is a runtime of VersionData by name
+type versionRuntime struct {
+	name string
+	set  *VersionSet
+}
+
+// versionGroup is a group of services of VersionData by name
+type versionGroup struct {
+	name string
+	sets *map[string]VersionSet
+}
+
+// runtimes returns the runtimes of the data in the order of versions.json
+func (data *VersionData) runtimes() []versionRuntime {
+	return []versionRuntime{{"php", &data.PHP}, {"node", &data.Node}, {"ruby", &data.Ruby}}
+}
+
+// groups returns the service groups of the data in the order of versions.json
+func (data *VersionData) groups() []versionGroup {
+	return []versionGroup{
+		{"database", &data.Database},
+		{"cache", &data.Cache},
+		{"search", &data.Search},
+		{"email", &data.Email},
+		{"compat", &data.Compat},
+		{"ai", &data.AI},
+		{"queue", &data.Queue},
+	}
+}
+
+// validateVersionData checks version data before it is used, so a bad download can't
+// break service generation
+func validateVersionData(data *VersionData) error {
+	if data.Schema != versionDataSchema {
+		return fmt.Errorf("unsupported schema %d (this version of Fleet reads schema %d)", data.Schema, versionDataSchema)
+	}
+	if _, err := time.Parse("2006-01-02", data.Updated); err != nil {
+		return fmt.Errorf("invalid updated date %q", data.Updated)
+	}
+
+	for _, runtime := range data.runtimes() {
+		if err := validateVersionSet(runtime.name, *runtime.set); err != nil {
+			return err
+		}
+	}
+	for _, group := range data.groups() {
+		if len(*group.sets) == 0 {
+			return fmt.Errorf("%s: no services", group.name)
+		}
+		for serviceType, set := range *group.sets {
+			if err := validateVersionSet(group.name+"."+serviceType, set); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mergeVersionData returns base with the runtimes and service types data has. Data
+// downloaded before a runtime or service was added to Fleet keeps the versions of base
+// for it.
+func mergeVersionData(base, data *VersionData) *VersionData {
+	merged := *data
+	baseRuntimes := base.runtimes()
+	for i, runtime := range merged.runtimes() {
+		if len(runtime.set.Images) == 0 {
+			*runtime.set = *baseRuntimes[i].set
+		}
+	}
+	baseGroups := base.groups()
+	for i, group := range merged.groups() {
+		sets := make(map[string]VersionSet, len(*baseGroups[i].sets)+len(*group.sets))
+		for serviceType, set := range *baseGroups[i].sets {
+			sets[serviceType] = set
+		}
+		for serviceType, set := range *group.sets {
+			sets[serviceType] = set
+		}
+		*group.sets = sets
+	}
+	return &merged
+}
+
+// parseVersionData reads version data, merges it into base unless base is nil, and
+// validates the result
+func parseVersionData(raw []byte, base *VersionData) (*VersionData, error) {
+	var data VersionData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse version data: %w", err)
+	}
+	merged := &data
+	if base != nil {
+		merged = mergeVersionData(base, &data)
+	}
+	if err := validateVersionData(merged); err != nil {
+		return nil, fmt.Errorf("invalid version data: %w", err)
+	}
+	return merged, nil
+}
+
+// loadEmbeddedVersionData returns the version data this build ships with
+func loadEmbeddedVersionData() *VersionData {
+	data, err := parseVersionData(embeddedVersionData, nil)
+	if err != nil {
+		// The embedded data is checked by the tests, so this is a broken build
+		panic(err)
+	}
+	return data
+}
+
+// loadVersionData returns the embedded version data, or the downloaded data merged into
+// it when it is newer. An invalid download is ignored with a warning.
+func loadVersionData() *VersionData {
+	data := loadEmbeddedVersionData()
+
+	raw, err := os.ReadFile(getVersionDataPath())
+	if err != nil {
+		return data
+	}
+	downloaded, err := parseVersionData(raw, data)
+	if err != nil {
+		warnf("⚠️  Warning: ignoring %s: %v\n", getVersionDataPath(), err)
+		return data
+	}
+	if downloaded.Updated > data.Updated {
+		return downloaded
+	}
+	return data
+}
+
+func handleVersions() {
+	if len(os.Args) < 3 {
+		handleVersionsList()
+		return
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "list", "ls":
+		handleVersionsList()
+	case "update":
+		handleVersionsUpdate(os.Args[3:])
+	case "reset":
+		handleVersionsReset()
+	case "help":
+		printVersionsUsage()
+	default:
+		fmt.Printf("Unknown versions command: %s\n\n", subcommand)
+		printVersionsUsage()
+		os.Exit(1)
+	}
+}
+
+func printVersionsUsage() {
+	fmt.Println("Fleet versions - Supported runtime and service versions")
+	fmt.Println("\nUsage: fleet versions <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  list    List supported versions and their defaults (default)")
+	fmt.Println("  update  Download the latest supported versions")
+	fmt.Println("  reset   Go back to the versions this release of Fleet ships with")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --url   Download from another URL (for 'update', or set FLEET_VERSIONS_URL)")
+}
+
+func handleVersionsList() {
+	outputf("Supported versions (updated %s):\n\n", versionData.Updated)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tDEFAULT\tVERSIONS")
+	for _, runtime := range versionData.runtimes() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", runtime.name, runtime.set.Default, strings.Join(runtime.set.getVersions(), ", "))
+	}
+	for _, group := range versionData.groups() {
+		sets := *group.sets
+		serviceTypes := make([]string, 0, len(sets))
+		for serviceType := range sets {
+			serviceTypes = append(serviceTypes, serviceType)
+		}
+		sort.Strings(serviceTypes)
+		for _, serviceType := range serviceTypes {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", serviceType, sets[serviceType].Default, strings.Join(sets[serviceType].getVersions(), ", "))
+		}
+	}
+	w.Flush()
+}
+
+// downloadVersionData fetches version data and validates it merged into the embedded data
+func downloadVersionData(url string) ([]byte, *VersionData, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	// Version data is a few KB, anything much larger isn't version data
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+
+	data, err := parseVersionData(raw, loadEmbeddedVersionData())
+	if err != nil {
+		return nil, nil, err
+	}
+	return raw, data, nil
+}
+
+func handleVersionsUpdate(args []string) {
+	fs := newCommandFlagSet("versions update", flag.ExitOnError)
+	url := fs.String("url", getEnvOrDefault(os.Getenv(versionDataURLEnv), versionDataURL), "URL of versions.json")
+
+	fs.Parse(args)
+
+	infof("📥 Downloading supported versions from %s\n", *url)
+	raw, data, err := downloadVersionData(*url)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	if data.Updated <= versionData.Updated {
+		outputf("✅ Already up to date (updated %s)\n", versionData.Updated)
+		return
+	}
+
+	path := getVersionDataPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Fatalf("❌ Error creating %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		log.Fatalf("❌ Error writing %s: %v", path, err)
+	}
+	outputf("✅ Supported versions updated to %s\n", data.Updated)
+}
+
+func handleVersionsReset() {
+	path := getVersionDataPath()
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("❌ Error removing %s: %v", path, err)
+	}
+	outputln("✅ Using the versions this release of Fleet ships with")
+}
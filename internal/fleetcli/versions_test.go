@@ -0,0 +1,167 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type VersionsTestSuite struct {
+	suite.Suite
+	helper             *TestHelper
+	originalDataPath   func() string
+	downloadedDataPath string
+}
+
+func (suite *VersionsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDataPath = getVersionDataPath
+	suite.downloadedDataPath = filepath.Join(suite.helper.TempDir(), "versions.json")
+	getVersionDataPath = func() string { return suite.downloadedDataPath }
+}
+
+func (suite *VersionsTestSuite) TearDownTest() {
+	getVersionDataPath = suite.originalDataPath
+	suite.helper.Cleanup()
+}
+
+// newVersionData returns a copy of the embedded data to modify
+func (suite *VersionsTestSuite) newVersionData() *VersionData {
+	return loadEmbeddedVersionData()
+}
+
+func (suite *VersionsTestSuite) marshal(data *VersionData) []byte {
+	raw, err := json.Marshal(data)
+	suite.Require().NoError(err)
+	return raw
+}
+
+func (suite *VersionsTestSuite) TestEmbeddedDataIsValid() {
+	data := suite.newVersionData()
+	suite.Equal("8.4", data.PHP.Default)
+	suite.Equal("php:8.4-fpm-alpine", supportedPHPVersions["default"])
+	suite.Equal("node:20-alpine", supportedNodeVersions["default"])
+	suite.Equal("postgres:15-alpine", supportedDatabaseVersions["postgres"]["default"])
+	suite.Equal("redis:7.2-alpine", supportedCacheVersions["redis"]["default"])
+	suite.Equal("getmeili/meilisearch:v1.6", supportedSearchVersions["meilisearch"]["default"])
+	suite.Equal("axllent/mailpit:v1.20", supportedEmailVersions["mailpit"]["default"])
+	suite.Equal("minio/minio:RELEASE.2024-01-16T16-07-38Z", supportedCompatVersions["minio"]["default"])
+}
+
+func (suite *VersionsTestSuite) TestVersionDataURLPointsAtEmbeddedFile() {
+	// Find the root of the repository from this file, other tests change the working directory
+	_, file, _, ok := runtime.Caller(0)
+	suite.Require().True(ok)
+	dir := filepath.Dir(file)
+	root := dir
+	for !fileExists(filepath.Join(root, "go.mod")) {
+		suite.Require().NotEqual(root, filepath.Dir(root), "go.mod not found above %s", dir)
+		root = filepath.Dir(root)
+	}
+
+	path, err := filepath.Rel(root, filepath.Join(dir, "config", "versions.json"))
+	suite.Require().NoError(err)
+	suite.Equal("https://raw.githubusercontent.com/jmaisonguillard/fleet/main/"+filepath.ToSlash(path), versionDataURL)
+}
+
+func (suite *VersionsTestSuite) TestValidateVersionData() {
+	testCases := []struct {
+		name   string
+		modify func(data *VersionData)
+		error  string
+	}{
+		{"newer schema", func(data *VersionData) { data.Schema = 2 }, "unsupported schema 2"},
+		{"bad date", func(data *VersionData) { data.Updated = "yesterday" }, "invalid updated date"},
+		{"default without image", func(data *VersionData) { data.PHP.Default = "9.0" }, "php: default version \"9.0\" has no image"},
+		{"invalid image", func(data *VersionData) { data.Cache["redis"].Images["8.0"] = "redis 8" }, "cache.redis 8.0: invalid image"},
+		{"missing group", func(data *VersionData) { data.Email = nil }, "email: no services"},
+		{"missing runtime", func(data *VersionData) { data.Ruby = VersionSet{} }, "ruby: no images"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			data := suite.newVersionData()
+			tc.modify(data)
+			suite.ErrorContains(validateVersionData(data), tc.error)
+		})
+	}
+}
+
+func (suite *VersionsTestSuite) TestMergeVersionData() {
+	// Data downloaded before ruby, ai, queue and memcached were added
+	older := suite.newVersionData()
+	older.Ruby = VersionSet{}
+	older.AI = nil
+	older.Queue = nil
+	older.Cache = map[string]VersionSet{"redis": {Default: "6.2", Images: map[string]string{"6.2": "redis:6.2-alpine"}}}
+
+	data, err := parseVersionData(suite.marshal(older), suite.newVersionData())
+	suite.Require().NoError(err)
+	suite.Equal(suite.newVersionData().Ruby, data.Ruby)
+	suite.Equal(suite.newVersionData().AI, data.AI)
+	suite.Equal(suite.newVersionData().Queue, data.Queue)
+	suite.Equal("6.2", data.Cache["redis"].Default, "Downloaded services replace the embedded ones")
+	suite.Equal(suite.newVersionData().Cache["valkey"], data.Cache["valkey"], "Services missing from the download are kept")
+
+	_, err = parseVersionData(suite.marshal(older), nil)
+	suite.ErrorContains(err, "ruby: no images", "Without base data every group is required")
+}
+
+func (suite *VersionsTestSuite) TestLoadVersionDataPrefersNewerDownload() {
+	suite.Equal(suite.newVersionData().Updated, loadVersionData().Updated, "Without a download the embedded data is used")
+
+	newer := suite.newVersionData()
+	newer.Updated = "2099-01-01"
+	newer.PHP.Images["9.0"] = "php:9.0-fpm-alpine"
+	newer.PHP.Default = "9.0"
+	suite.Require().NoError(os.WriteFile(suite.downloadedDataPath, suite.marshal(newer), 0644))
+
+	data := loadVersionData()
+	suite.Equal("9.0", data.PHP.Default)
+	suite.Equal("php:9.0-fpm-alpine", data.PHP.imageMap()["default"])
+
+	older := suite.newVersionData()
+	older.Updated = "2000-01-01"
+	suite.Require().NoError(os.WriteFile(suite.downloadedDataPath, suite.marshal(older), 0644))
+	suite.Equal(suite.newVersionData().Updated, loadVersionData().Updated, "An older download doesn't replace newer embedded data")
+
+	suite.Require().NoError(os.WriteFile(suite.downloadedDataPath, []byte(`{"schema": 1}`), 0644))
+	suite.Equal(suite.newVersionData().Updated, loadVersionData().Updated, "An invalid download is ignored")
+}
+
+func (suite *VersionsTestSuite) TestDownloadVersionData() {
+	newer := suite.newVersionData()
+	newer.Updated = "2099-01-01"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/versions.json" {
+			w.Write(suite.marshal(newer))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	_, data, err := downloadVersionData(server.URL + "/versions.json")
+	suite.Require().NoError(err)
+	suite.Equal("2099-01-01", data.Updated)
+
+	_, _, err = downloadVersionData(server.URL + "/missing.json")
+	suite.ErrorContains(err, "404")
+}
+
+func (suite *VersionsTestSuite) TestGetVersions() {
+	suite.Equal([]string{"16", "16-alpine", "18", "18-alpine", "20", "20-alpine", "22", "22-alpine"}, versionData.Node.getVersions())
+	suite.Equal([]string{"3.1", "3.2", "3.3"}, versionData.Ruby.getVersions())
+	suite.Contains(getVersionLists(versionData.Email)["mailpit"], "latest", "Providers accept aliases")
+}
+
+func TestVersionsSuite(t *testing.T) {
+	suite.Run(t, new(VersionsTestSuite))
+}
@@ -0,0 +1,295 @@
+package fleetcli
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// volumeKeyLabel records the compose key of a volume so ownership survives project renames
+const volumeKeyLabel = "com.fleet.volume"
+
+// legacyVolumePrefix is the prefix compose gave volumes before they were scoped,
+// when every project shared the same compose project
+const legacyVolumePrefix = composeProjectName + "_"
+
+var volumeNameSanitizer = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// labelKeyPattern matches the label keys Docker and Fleet use, like com.fleet.project-dir
+var labelKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_./-]*$`)
+
+// getProjectVolumeName returns the Docker volume name for a volume of a project
+func getProjectVolumeName(project, volume string) string {
+	prefix := volumeNameSanitizer.ReplaceAllString(strings.ToLower(project), "-")
+	prefix = strings.Trim(prefix, "-")
+	if prefix == "" {
+		prefix = "fleet-project"
+	}
+	return fmt.Sprintf("%s_%s", prefix, volume)
+}
+
+// scopeVolumesToProject gives every named volume a project-prefixed name and ownership labels
+func scopeVolumesToProject(compose *DockerCompose, project, projectDir string) {
+	for key, volume := range compose.Volumes {
+		volume.Name = getProjectVolumeName(project, key)
+		volume.Labels = map[string]string{
+			fleetProjectLabel:    project,
+			fleetProjectDirLabel: escapeComposeInterpolation(projectDir),
+			volumeKeyLabel:       key,
+		}
+		compose.Volumes[key] = volume
+	}
+}
+
+// DockerVolumeInfo is a volume as reported by `docker volume ls`
+type DockerVolumeInfo struct {
+	Name   string
+	Labels map[string]string
+}
+
+// parseVolumeLabels parses the comma separated key=value labels printed by docker.
+// Docker doesn't escape commas in values, like a project directory with a comma, so a
+// part that doesn't start with a label key continues the previous value.
+func parseVolumeLabels(labels string) map[string]string {
+	result := make(map[string]string)
+	lastKey := ""
+	for _, pair := range strings.Split(labels, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) == 2 && labelKeyPattern.MatchString(parts[0]) {
+			lastKey = parts[0]
+			result[lastKey] = parts[1]
+		} else if lastKey != "" {
+			result[lastKey] += "," + pair
+		}
+	}
+	return result
+}
+
+// parseDockerVolumeList parses `docker volume ls --format '{{.Name}}\t{{.Labels}}'` output
+func parseDockerVolumeList(output []byte) []DockerVolumeInfo {
+	var volumes []DockerVolumeInfo
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "\t", 2)
+		info := DockerVolumeInfo{Name: parts[0], Labels: map[string]string{}}
+		if len(parts) == 2 {
+			info.Labels = parseVolumeLabels(parts[1])
+		}
+		volumes = append(volumes, info)
+	}
+	return volumes
+}
+
+// listDockerVolumes returns all Docker volumes with their labels
+func listDockerVolumes() ([]DockerVolumeInfo, error) {
+	output, err := newCommand("docker", "volume", "ls", "--format", "{{.Name}}\t{{.Labels}}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+	return parseDockerVolumeList(output), nil
+}
+
+// VolumeMigration copies the data of an old volume into its new project-scoped volume
+type VolumeMigration struct {
+	Volume string
+	From   string
+	To     string
+}
+
+// planVolumeMigrations finds data to carry over for volumes that don't exist yet.
+// A volume from the same project directory under an older project name is preferred,
+// then the unscoped volume every project used to share.
+func planVolumeMigrations(compose *DockerCompose, existing []DockerVolumeInfo, projectDir string) []VolumeMigration {
+	byName := make(map[string]DockerVolumeInfo)
+	for _, volume := range existing {
+		byName[volume.Name] = volume
+	}
+
+	keys := make([]string, 0, len(compose.Volumes))
+	for key := range compose.Volumes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var migrations []VolumeMigration
+	for _, key := range keys {
+		target := compose.Volumes[key].Name
+		if target == "" {
+			continue
+		}
+		if _, exists := byName[target]; exists {
+			continue
+		}
+
+		source := ""
+		for _, volume := range existing {
+			if volume.Name != target && volume.Labels[volumeKeyLabel] == key && volume.Labels[fleetProjectDirLabel] == projectDir {
+				source = volume.Name
+				break
+			}
+		}
+		if source == "" {
+			if _, exists := byName[legacyVolumePrefix+key]; exists {
+				source = legacyVolumePrefix + key
+			}
+		}
+
+		if source != "" {
+			migrations = append(migrations, VolumeMigration{Volume: key, From: source, To: target})
+		}
+	}
+
+	return migrations
+}
+
+// runVolumeMigration creates the new volume with the compose labels and copies the data over.
+// The old volume is left in place so other projects that shared it keep working.
+func runVolumeMigration(migration VolumeMigration, labels map[string]string) error {
+	args := []string{"volume", "create"}
+	for key, value := range labels {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", key, value))
+	}
+	args = append(args, migration.To)
+	if output, err := newCommand("docker", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create volume %s: %v\n%s", migration.To, err, output)
+	}
+
+	copyArgs := []string{
+		"run", "--rm",
+		"-v", fmt.Sprintf("%s:/from:ro", migration.From),
+		"-v", fmt.Sprintf("%s:/to", migration.To),
+		"alpine", "sh", "-c", "cp -a /from/. /to/",
+	}
+	if output, err := newCommand("docker", copyArgs...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %v\n%s", migration.From, migration.To, err, output)
+	}
+
+	return nil
+}
+
+// migrateProjectVolumes carries data over into project-scoped volumes before startup
+func migrateProjectVolumes(compose *DockerCompose, projectDir string) {
+	if len(compose.Volumes) == 0 {
+		return
+	}
+
+	existing, err := listDockerVolumes()
+	if err != nil {
+		return
+	}
+
+	for _, migration := range planVolumeMigrations(compose, existing, projectDir) {
+		infof("📦 Migrating volume %s -> %s\n", migration.From, migration.To)
+		if err := runVolumeMigration(migration, compose.Volumes[migration.Volume].Labels); err != nil {
+			warnf("⚠️  Warning: %v\n", err)
+			continue
+		}
+		infof("   Data copied. Remove the old volume with 'docker volume rm %s' once nothing else uses it\n", migration.From)
+	}
+}
+
+func handleVolumes() {
+	if len(os.Args) < 3 {
+		printVolumesUsage()
+		os.Exit(0)
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "list", "ls":
+		handleVolumesList(os.Args[3:])
+	case "help":
+		printVolumesUsage()
+	default:
+		fmt.Printf("Unknown volumes command: %s\n\n", subcommand)
+		printVolumesUsage()
+		os.Exit(1)
+	}
+}
+
+func printVolumesUsage() {
+	fmt.Println("Fleet volumes - Inspect named volumes and their owners")
+	fmt.Println("\nUsage: fleet volumes <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  list        List Fleet volumes and the project that owns them")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("  --all       Include volumes of other projects")
+}
+
+// describeVolumeOwner returns the owner column for a volume in `fleet volumes list`
+func describeVolumeOwner(volume DockerVolumeInfo, project, projectDir string) string {
+	owner, ok := volume.Labels[fleetProjectLabel]
+	if !ok {
+		return "legacy (shared by all projects)"
+	}
+	if volume.Labels[fleetProjectDirLabel] == projectDir && owner != project {
+		return fmt.Sprintf("%s (renamed, now %s)", owner, project)
+	}
+	return owner
+}
+
+func handleVolumesList(args []string) {
+	fs := newCommandFlagSet("volumes list", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	all := fs.Bool("all", false, "Include volumes of other projects")
+
+	fs.Parse(args)
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+
+	projectDir, _ := os.Getwd()
+
+	volumes, err := listDockerVolumes()
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VOLUME\tOWNER\tDOCKER NAME")
+	count := 0
+	for _, volume := range volumes {
+		_, scoped := volume.Labels[volumeKeyLabel]
+		legacy := !scoped && strings.HasPrefix(volume.Name, legacyVolumePrefix)
+		if !scoped && !legacy {
+			continue
+		}
+
+		mine := legacy || volume.Labels[fleetProjectDirLabel] == projectDir
+		if !mine && !*all {
+			continue
+		}
+
+		key := volume.Labels[volumeKeyLabel]
+		if legacy {
+			key = strings.TrimPrefix(volume.Name, legacyVolumePrefix)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", key, describeVolumeOwner(volume, config.Project, projectDir), volume.Name)
+		count++
+	}
+	w.Flush()
+
+	if count == 0 {
+		outputln("No Fleet volumes found")
+	}
+}
@@ -0,0 +1,138 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type VolumeScopeTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *VolumeScopeTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+
+	suite.originalDir, _ = os.Getwd()
+	suite.Require().NoError(os.Chdir(suite.helper.TempDir()))
+}
+
+func (suite *VolumeScopeTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *VolumeScopeTestSuite) TestGetProjectVolumeName() {
+	tests := []struct {
+		project  string
+		expected string
+	}{
+		{"shop", "shop_mysql-80-data"},
+		{"My Shop", "my-shop_mysql-80-data"},
+		{"acme/api", "acme-api_mysql-80-data"},
+		{"", "fleet-project_mysql-80-data"},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.project, func() {
+			suite.Equal(tt.expected, getProjectVolumeName(tt.project, "mysql-80-data"))
+		})
+	}
+}
+
+func (suite *VolumeScopeTestSuite) TestGeneratedVolumesAreScoped() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "app", Image: "php:8.3-fpm", Database: "mysql:8.0"},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	volume, exists := compose.Volumes["mysql-80-data"]
+	suite.Require().True(exists)
+	suite.Equal("shop_mysql-80-data", volume.Name)
+	suite.Equal("shop", volume.Labels[fleetProjectLabel])
+	suite.Equal("mysql-80-data", volume.Labels[volumeKeyLabel])
+	suite.NotEmpty(volume.Labels[fleetProjectDirLabel])
+
+	// Services keep referring to the volume by its compose key
+	suite.Contains(compose.Services["mysql-80"].Volumes, "mysql-80-data:/var/lib/mysql")
+}
+
+func (suite *VolumeScopeTestSuite) TestParseDockerVolumeList() {
+	output := []byte("fleet_mysql-80-data\t\n" +
+		"shop_mysql-80-data\tcom.fleet.project=shop,com.fleet.volume=mysql-80-data\n")
+
+	volumes := parseDockerVolumeList(output)
+	suite.Require().Len(volumes, 2)
+	suite.Equal("fleet_mysql-80-data", volumes[0].Name)
+	suite.Empty(volumes[0].Labels)
+	suite.Equal("shop", volumes[1].Labels[fleetProjectLabel])
+	suite.Equal("mysql-80-data", volumes[1].Labels[volumeKeyLabel])
+}
+
+func (suite *VolumeScopeTestSuite) TestParseVolumeLabelsWithCommas() {
+	labels := parseVolumeLabels("com.fleet.project=shop,com.fleet.project-dir=/work/Clients, Inc/shop,com.fleet.volume=data")
+	suite.Equal("/work/Clients, Inc/shop", labels[fleetProjectDirLabel])
+	suite.Equal("data", labels[volumeKeyLabel])
+}
+
+func (suite *VolumeScopeTestSuite) TestPlanVolumeMigrations() {
+	compose := &DockerCompose{
+		Volumes: map[string]DockerVolume{
+			"mysql-80-data":    {Driver: "local"},
+			"redis-72-data":    {Driver: "local"},
+			"mailpit-data":     {Driver: "local"},
+			"postgres-15-data": {Driver: "local"},
+		},
+	}
+	scopeVolumesToProject(compose, "new-name", "/work/shop")
+
+	existing := []DockerVolumeInfo{
+		// Same directory under the old project name
+		{Name: "old-name_mysql-80-data", Labels: map[string]string{
+			fleetProjectLabel: "old-name", fleetProjectDirLabel: "/work/shop", volumeKeyLabel: "mysql-80-data",
+		}},
+		// Another project's volume must never be picked up
+		{Name: "blog_redis-72-data", Labels: map[string]string{
+			fleetProjectLabel: "blog", fleetProjectDirLabel: "/work/blog", volumeKeyLabel: "redis-72-data",
+		}},
+		// Unscoped volume from before scoping
+		{Name: "fleet_redis-72-data", Labels: map[string]string{}},
+		// Already migrated
+		{Name: "new-name_mailpit-data", Labels: map[string]string{}},
+	}
+
+	migrations := planVolumeMigrations(compose, existing, "/work/shop")
+
+	suite.Equal([]VolumeMigration{
+		{Volume: "mysql-80-data", From: "old-name_mysql-80-data", To: "new-name_mysql-80-data"},
+		{Volume: "redis-72-data", From: "fleet_redis-72-data", To: "new-name_redis-72-data"},
+	}, migrations)
+}
+
+func (suite *VolumeScopeTestSuite) TestDescribeVolumeOwner() {
+	legacy := DockerVolumeInfo{Name: "fleet_mysql-80-data", Labels: map[string]string{}}
+	suite.Contains(describeVolumeOwner(legacy, "shop", "/work/shop"), "legacy")
+
+	renamed := DockerVolumeInfo{Name: "old_mysql-80-data", Labels: map[string]string{
+		fleetProjectLabel: "old", fleetProjectDirLabel: "/work/shop",
+	}}
+	suite.Equal("old (renamed, now shop)", describeVolumeOwner(renamed, "shop", "/work/shop"))
+
+	current := DockerVolumeInfo{Name: "shop_mysql-80-data", Labels: map[string]string{
+		fleetProjectLabel: "shop", fleetProjectDirLabel: "/work/shop",
+	}}
+	suite.Equal("shop", describeVolumeOwner(current, "shop", "/work/shop"))
+}
+
+func TestVolumeScopeSuite(t *testing.T) {
+	suite.Run(t, new(VolumeScopeTestSuite))
+}
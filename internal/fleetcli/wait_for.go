@@ -0,0 +1,209 @@
+package fleetcli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// waitForScriptPath is where the wait-for wrapper is mounted in containers
+const waitForScriptPath = "/usr/local/bin/fleet-wait-for"
+
+// imageDefaultPorts are the ports Fleet's support service images listen on
+var imageDefaultPorts = map[string]int{
+	"mysql":       3306,
+	"mariadb":     3306,
+	"postgres":    5432,
+	"postgis":     5432,
+	"pgvector":    5432,
+	"mongo":       27017,
+	"redis":       6379,
+	"memcached":   11211,
+	"meilisearch": 7700,
+	"typesense":   8108,
+	"mailpit":     1025,
+	"minio":       9000,
+	"prism":       mockServerPort,
+}
+
+// WaitForTarget is a dependency a service waits for before starting its command
+type WaitForTarget struct {
+	Service string
+	Port    int
+}
+
+// parseWaitFor parses a wait_for entry: "<service>:healthy" or "<service>:port:<port>"
+func parseWaitFor(entry string) (service string, port int, err error) {
+	parts := strings.Split(entry, ":")
+	switch {
+	case len(parts) == 2 && parts[1] == "healthy":
+		return parts[0], 0, nil
+	case len(parts) == 3 && parts[1] == "port":
+		port, err := strconv.Atoi(parts[2])
+		if err != nil || port <= 0 || port > 65535 {
+			return "", 0, fmt.Errorf("invalid port in wait_for %q", entry)
+		}
+		return parts[0], port, nil
+	}
+	return "", 0, fmt.Errorf("invalid wait_for %q (expected <service>:healthy or <service>:port:<port>)", entry)
+}
+
+// validateWaitFor checks the wait_for entries of a service
+func validateWaitFor(svc *Service) error {
+	for _, entry := range svc.WaitFor {
+		service, _, err := parseWaitFor(entry)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", svc.Name, err)
+		}
+		if service == "" || service == svc.Name {
+			return fmt.Errorf("service %s: invalid wait_for %q", svc.Name, entry)
+		}
+	}
+	return nil
+}
+
+// getImageDefaultPort returns the port a known image listens on
+func getImageDefaultPort(image string) int {
+	name := image
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	if idx := strings.Index(name, ":"); idx >= 0 {
+		name = name[:idx]
+	}
+	return imageDefaultPorts[name]
+}
+
+// resolveWaitForTargets turns wait_for entries into host and port pairs.
+// A service is considered healthy once its main port accepts connections.
+func resolveWaitForTargets(compose *DockerCompose, config *Config, svc *Service) ([]WaitForTarget, error) {
+	var targets []WaitForTarget
+	for _, entry := range svc.WaitFor {
+		service, port, _ := parseWaitFor(entry)
+		if port == 0 {
+			for _, other := range config.Services {
+				if other.Name == service && getServicePort(&other) > 0 {
+					port = getServicePort(&other)
+				}
+			}
+		}
+		if port == 0 {
+			if dependency, exists := compose.Services[service]; exists {
+				port = getImageDefaultPort(dependency.Image)
+			}
+		}
+		if port == 0 {
+			return nil, fmt.Errorf("can't tell which port %s listens on, use \"%s:port:<port>\"", service, service)
+		}
+		targets = append(targets, WaitForTarget{Service: service, Port: port})
+	}
+	return targets, nil
+}
+
+// generateWaitForScript returns the POSIX shell wrapper that blocks until every
+// host:port given before -- accepts connections, then runs the command after it.
+// It falls back through the tools commonly found in images, since none is everywhere.
+func generateWaitForScript() string {
+	return `#!/bin/sh
+# Generated by Fleet CLI - DO NOT EDIT
+# Usage: fleet-wait-for host:port... -- command [args...]
+
+timeout="${FLEET_WAIT_TIMEOUT:-120}"
+
+can_connect() {
+    if command -v nc >/dev/null 2>&1; then
+        nc -z "$1" "$2" >/dev/null 2>&1
+    elif command -v bash >/dev/null 2>&1; then
+        bash -c "exec 3<>/dev/tcp/$1/$2" >/dev/null 2>&1
+    elif command -v php >/dev/null 2>&1; then
+        php -r "exit(@fsockopen('$1', $2) ? 0 : 1);" >/dev/null 2>&1
+    elif command -v node >/dev/null 2>&1; then
+        node -e "require('net').connect($2, '$1').on('connect', () => process.exit(0)).on('error', () => process.exit(1))" >/dev/null 2>&1
+    else
+        echo "fleet-wait-for: no tool to check connections (need nc, bash, php or node)" >&2
+        exit 1
+    fi
+}
+
+while [ $# -gt 0 ] && [ "$1" != "--" ]; do
+    host="${1%:*}"
+    port="${1##*:}"
+    shift
+
+    echo "Waiting for $host:$port..."
+    waited=0
+    until can_connect "$host" "$port"; do
+        if [ "$waited" -ge "$timeout" ]; then
+            echo "Timed out after ${timeout}s waiting for $host:$port" >&2
+            exit 1
+        fi
+        sleep 1
+        waited=$((waited + 1))
+    done
+done
+
+[ "$1" = "--" ] && shift
+exec "$@"
+`
+}
+
+// planWaitForScript plans the wait-for wrapper with the generated files and returns the
+// source of its mount
+func planWaitForScript(plan *ArtifactPlan) string {
+	plan.addFile(plan.path("wait-for.sh"), []byte(generateWaitForScript()), 0755)
+	return plan.mountPath("wait-for.sh")
+}
+
+// wrapCommandWithWaitFor prefixes a command with the wait-for wrapper
+func wrapCommandWithWaitFor(command string, targets []WaitForTarget) string {
+	args := []string{"sh", waitForScriptPath}
+	for _, target := range targets {
+		args = append(args, fmt.Sprintf("%s:%d", target.Service, target.Port))
+	}
+	args = append(args, "--", command)
+	return strings.Join(args, " ")
+}
+
+// applyWaitFor wraps the commands of services with wait_for so they only start once
+// their dependencies accept connections
+func applyWaitFor(compose *DockerCompose, config *Config) {
+	for i := range config.Services {
+		svc := &config.Services[i]
+		if len(svc.WaitFor) == 0 {
+			continue
+		}
+
+		service, exists := compose.Services[svc.Name]
+		if !exists {
+			continue
+		}
+		if service.Command == "" {
+			warnf("⚠️  Warning: service %s: wait_for needs a 'command' to wrap, ignoring it\n", svc.Name)
+			continue
+		}
+
+		targets, err := resolveWaitForTargets(compose, config, svc)
+		if err != nil {
+			warnf("⚠️  Warning: service %s: %v\n", svc.Name, err)
+			continue
+		}
+
+		script := planWaitForScript(getArtifactPlan(compose))
+
+		service.Command = wrapCommandWithWaitFor(service.Command, targets)
+		service.Volumes = append(service.Volumes, formatBindMount(script, waitForScriptPath+":ro"))
+
+		// Make sure the dependencies are started with the service
+		var dependencies []string
+		for _, target := range targets {
+			if _, exists := compose.Services[target.Service]; exists && !containsString(service.DependsOn, target.Service) {
+				dependencies = append(dependencies, target.Service)
+			}
+		}
+		sort.Strings(dependencies)
+		service.DependsOn = append(append([]string{}, service.DependsOn...), dependencies...)
+
+		compose.Services[svc.Name] = service
+	}
+}
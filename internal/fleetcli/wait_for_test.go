@@ -0,0 +1,147 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WaitForTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *WaitForTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+
+	suite.originalDir, _ = os.Getwd()
+	suite.Require().NoError(os.Chdir(suite.helper.TempDir()))
+}
+
+func (suite *WaitForTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *WaitForTestSuite) TestParseWaitFor() {
+	tests := []struct {
+		entry   string
+		service string
+		port    int
+		wantErr bool
+	}{
+		{"mysql-80:healthy", "mysql-80", 0, false},
+		{"api:port:3000", "api", 3000, false},
+		{"api", "", 0, true},
+		{"api:ready", "", 0, true},
+		{"api:port:http", "", 0, true},
+		{"api:port:70000", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.entry, func() {
+			service, port, err := parseWaitFor(tt.entry)
+			if tt.wantErr {
+				suite.Error(err)
+				return
+			}
+			suite.NoError(err)
+			suite.Equal(tt.service, service)
+			suite.Equal(tt.port, port)
+		})
+	}
+}
+
+func (suite *WaitForTestSuite) TestValidateWaitFor() {
+	suite.NoError(validateWaitFor(&Service{Name: "worker", WaitFor: []string{"mysql-80:healthy", "api:port:3000"}}))
+	suite.Error(validateWaitFor(&Service{Name: "worker", WaitFor: []string{"worker:healthy"}}))
+	suite.Error(validateWaitFor(&Service{Name: "worker", WaitFor: []string{"mysql-80"}}))
+}
+
+func (suite *WaitForTestSuite) TestGetImageDefaultPort() {
+	suite.Equal(3306, getImageDefaultPort("mysql:8.0"))
+	suite.Equal(5432, getImageDefaultPort("postgis/postgis:15-3.4"))
+	suite.Equal(1025, getImageDefaultPort("axllent/mailpit:latest"))
+	suite.Equal(0, getImageDefaultPort("nginx:alpine"))
+}
+
+func (suite *WaitForTestSuite) TestCommandIsWrapped() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "api", Image: "node:20", Port: 3000, Command: "node server.js"},
+			{
+				Name:     "worker",
+				Image:    "php:8.3-cli",
+				Database: "mysql:8.0",
+				Command:  "php artisan queue:work",
+				WaitFor:  []string{"mysql-80:healthy", "api:healthy"},
+			},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
+
+	worker := compose.Services["worker"]
+	suite.Equal("sh /usr/local/bin/fleet-wait-for mysql-80:3306 api:3000 -- php artisan queue:work", worker.Command)
+	suite.Contains(worker.Volumes, "./wait-for.sh:/usr/local/bin/fleet-wait-for:ro")
+	suite.Contains(worker.DependsOn, "api")
+	suite.Contains(worker.DependsOn, "mysql-80")
+
+	script, err := os.ReadFile(filepath.Join(".fleet", "wait-for.sh"))
+	suite.Require().NoError(err)
+	suite.Contains(string(script), `exec "$@"`)
+}
+
+func (suite *WaitForTestSuite) TestExplicitPort() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "legacy", Image: "example/legacy"},
+			{Name: "app", Image: "alpine", Command: "./start.sh", WaitFor: []string{"legacy:port:8080"}},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	suite.Equal("sh /usr/local/bin/fleet-wait-for legacy:8080 -- ./start.sh", compose.Services["app"].Command)
+}
+
+func (suite *WaitForTestSuite) TestUnknownPortIsSkipped() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "legacy", Image: "example/legacy"},
+			{Name: "app", Image: "alpine", Command: "./start.sh", Needs: []string{"legacy"}, WaitFor: []string{"legacy:healthy"}},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	suite.Equal("./start.sh", compose.Services["app"].Command)
+	suite.Equal([]string{"legacy"}, config.Services[1].Needs)
+}
+
+func (suite *WaitForTestSuite) TestServiceWithoutCommandIsLeftAlone() {
+	config := &Config{
+		Project: "shop",
+		Services: []Service{
+			{Name: "app", Image: "nginx", Database: "mysql:8.0", WaitFor: []string{"mysql-80:healthy"}},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+
+	suite.Empty(compose.Services["app"].Command)
+	suite.NoFileExists(filepath.Join(".fleet", "wait-for.sh"))
+}
+
+func TestWaitForSuite(t *testing.T) {
+	suite.Run(t, new(WaitForTestSuite))
+}
@@ -0,0 +1,158 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Actions of compose watch rules
+const (
+	watchSync        = "sync"
+	watchRebuild     = "rebuild"
+	watchSyncRestart = "sync+restart"
+	watchRestart     = "restart"
+)
+
+// watchActions are the actions a watch rule can take
+var watchActions = []string{watchSync, watchRebuild, watchSyncRestart, watchRestart}
+
+// DockerDevelop is the develop section of a compose service
+type DockerDevelop struct {
+	Watch []DockerWatch `yaml:"watch"`
+}
+
+// DockerWatch is a rule of develop.watch in a compose file
+type DockerWatch struct {
+	Action string   `yaml:"action"`
+	Path   string   `yaml:"path"`
+	Target string   `yaml:"target,omitempty"`
+	Ignore []string `yaml:"ignore,omitempty"`
+}
+
+// validateWatch checks the watch rules of a service
+func validateWatch(svc *Service) error {
+	for i, rule := range svc.Watch {
+		if !containsString(watchActions, rule.Action) {
+			return fmt.Errorf("service %s: watch rule %d: invalid action %q (supported: sync, rebuild, sync+restart, restart)", svc.Name, i+1, rule.Action)
+		}
+		if rule.Path == "" && svc.Folder == "" {
+			return fmt.Errorf("service %s: watch rule %d: 'path' is required for services without a folder", svc.Name, i+1)
+		}
+		if (rule.Action == watchSync || rule.Action == watchSyncRestart) && rule.Target == "" {
+			return fmt.Errorf("service %s: watch rule %d: %s needs a 'target' in the container", svc.Name, i+1, rule.Action)
+		}
+		if rule.Action == watchRebuild && svc.Build == "" {
+			return fmt.Errorf("service %s: watch rule %d: rebuild needs 'build', images can't be rebuilt", svc.Name, i+1)
+		}
+	}
+	return nil
+}
+
+// getWatchPath returns the path of a watch rule in the compose file. Compose resolves
+// it from .fleet, like the bind mounts of service folders.
+func getWatchPath(svc *Service, rule WatchRule) string {
+	path := rule.Path
+	if path == "" {
+		path = svc.Folder
+	}
+	if !filepath.IsAbs(path) {
+		path = "../" + filepath.ToSlash(filepath.Clean(path))
+	}
+	return formatMountSource(path)
+}
+
+// configureWatch adds the watch rules of a service to the container running its code
+func configureWatch(compose *DockerCompose, svc *Service) {
+	if len(svc.Watch) == 0 {
+		return
+	}
+	name := getAppServiceName(svc)
+	service, exists := compose.Services[name]
+	if !exists {
+		return
+	}
+
+	develop := &DockerDevelop{}
+	for _, rule := range svc.Watch {
+		develop.Watch = append(develop.Watch, DockerWatch{
+			Action: rule.Action,
+			Path:   getWatchPath(svc, rule),
+			Target: rule.Target,
+			Ignore: rule.Ignore,
+		})
+	}
+	service.Develop = develop
+	compose.Services[name] = service
+}
+
+// hasWatch checks if any service has watch rules
+func hasWatch(config *Config) bool {
+	for _, svc := range config.Services {
+		if len(svc.Watch) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func printDevUsage() {
+	fmt.Println("Fleet dev - Start services and sync or rebuild them when their files change")
+	fmt.Println("\nUsage: fleet dev [options]")
+	fmt.Println("\nRuns docker compose watch with the watch rules of fleet.toml. Services that")
+	fmt.Println("aren't running are started first. Stop watching with Ctrl-C, the services keep")
+	fmt.Println("running.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  -f, --file  Specify config file (default: fleet.toml)")
+	fmt.Println("\nExample fleet.toml:")
+	fmt.Println("  [[services.watch]]")
+	fmt.Println("  action = \"sync\"")
+	fmt.Println("  path = \"api/src\"")
+	fmt.Println("  target = \"/app/src\"")
+}
+
+func handleDev() {
+	if len(os.Args) > 2 && os.Args[2] == "help" {
+		printDevUsage()
+		return
+	}
+
+	fs := newCommandFlagSet("dev", flag.ExitOnError)
+	configFile := fs.String("f", "fleet.toml", "Config file")
+	configFileLong := fs.String("file", "fleet.toml", "Config file")
+	lockOptions := addProjectLockFlags(fs)
+	fs.Usage = printDevUsage
+
+	fs.Parse(os.Args[2:])
+
+	if *configFileLong != "fleet.toml" {
+		*configFile = *configFileLong
+	}
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading config: %v", err)
+	}
+	if !hasWatch(config) {
+		log.Fatalf("❌ No service has watch rules in %s, see 'fleet dev help'", *configFile)
+	}
+
+	release := lockProject("dev", lockOptions)
+	defer release()
+
+	compose := generateDockerCompose(config)
+	warnUnsupportedFeatures(compose)
+	composeFiles, err := writeComposeFiles(config, compose)
+	if err != nil {
+		log.Fatalf("❌ Error writing docker-compose.yml: %v", err)
+	}
+	// Watching runs until interrupted, other commands must not wait for it
+	release()
+
+	infof("👀 Watching the files of %s, press Ctrl-C to stop\n", config.Project)
+	if err := runDocker(composeArgs(composeFiles, "watch")); err != nil {
+		log.Fatalf("❌ Error watching services: %v", err)
+	}
+}
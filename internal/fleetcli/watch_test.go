@@ -0,0 +1,84 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/yaml.v3"
+)
+
+type WatchTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *WatchTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *WatchTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+func (suite *WatchTestSuite) TestValidateWatch() {
+	testCases := []struct {
+		name    string
+		service Service
+		wantErr string
+	}{
+		{"sync", Service{Name: "api", Folder: "api", Watch: []WatchRule{{Action: "sync", Target: "/app"}}}, ""},
+		{"rebuild", Service{Name: "api", Build: "../api", Watch: []WatchRule{{Action: "rebuild", Path: "api/package.json"}}}, ""},
+		{"unknown action", Service{Name: "api", Folder: "api", Watch: []WatchRule{{Action: "reload"}}}, "invalid action \"reload\""},
+		{"no path", Service{Name: "api", Image: "node:20", Watch: []WatchRule{{Action: "restart"}}}, "'path' is required"},
+		{"sync without target", Service{Name: "api", Folder: "api", Watch: []WatchRule{{Action: "sync+restart"}}}, "needs a 'target'"},
+		{"rebuild an image", Service{Name: "api", Image: "node:20", Folder: "api", Watch: []WatchRule{{Action: "rebuild"}}}, "rebuild needs 'build'"},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			err := validateWatch(&tc.service)
+			if tc.wantErr == "" {
+				suite.NoError(err)
+			} else {
+				suite.ErrorContains(err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func (suite *WatchTestSuite) TestConfigureWatch() {
+	config := &Config{Project: "test", Services: []Service{
+		{Name: "api", Image: "node:20", Port: 3000, Folder: "api", Watch: []WatchRule{
+			{Action: "sync", Path: "api/src", Target: "/app/src", Ignore: []string{"node_modules/"}},
+			{Action: "restart", Path: "./api/package.json"},
+		}},
+		{Name: "shop", Image: "nginx:alpine", Runtime: "php:8.3", Folder: "shop", Watch: []WatchRule{
+			{Action: "sync", Target: "/var/www/html"},
+		}},
+		{Name: "web", Image: "nginx:alpine", Port: 80},
+	}}
+
+	compose := generateDockerCompose(config)
+	suite.Equal(&DockerDevelop{Watch: []DockerWatch{
+		{Action: "sync", Path: "../api/src", Target: "/app/src", Ignore: []string{"node_modules/"}},
+		{Action: "restart", Path: "../api/package.json"},
+	}}, compose.Services["api"].Develop)
+	suite.Equal([]DockerWatch{{Action: "sync", Path: "../shop", Target: "/var/www/html"}},
+		compose.Services["shop-php"].Develop.Watch, "PHP code is synced to the FPM container")
+	suite.Nil(compose.Services["web"].Develop)
+
+	data, err := yaml.Marshal(compose.Services["api"])
+	suite.Require().NoError(err)
+	suite.Contains(string(data), "develop:\n    watch:\n        - action: sync\n          path: ../api/src\n")
+}
+
+func TestWatchSuite(t *testing.T) {
+	suite.Run(t, new(WatchTestSuite))
+}
@@ -0,0 +1,110 @@
+package fleetcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// reverbProxyPaths are the paths of the Pusher protocol Reverb speaks: /app/<key> for
+// websockets and /apps/<id>/events for the HTTP API Laravel broadcasts through
+var reverbProxyPaths = []string{"/app/", "/apps/"}
+
+// WebSocketLocation is a location of a vhost that carries websockets, with timeouts
+// long enough for idle connections
+type WebSocketLocation struct {
+	Path     string // e.g. /socket.io/
+	Upstream string // host:port or upstream name requests go to
+}
+
+// isViteDevServer reports whether a Node.js service runs the Vite dev server, whose hot
+// module replacement uses a websocket
+func isViteDevServer(svc *Service) bool {
+	if !strings.HasPrefix(svc.Runtime, "node") || svc.BuildCommand != "" || svc.Folder == "" {
+		return false
+	}
+	data, err := os.ReadFile(filepath.Join(svc.hostPath(svc.Folder), "package.json"))
+	if err != nil {
+		return false
+	}
+	var pkg PackageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return false
+	}
+	return hasPackage(pkg, "vite")
+}
+
+// isWebSocketService reports whether a service carries websockets, because of websocket
+// or because it runs a Vite dev server
+func isWebSocketService(svc *Service) bool {
+	return svc.WebSocket || isViteDevServer(svc)
+}
+
+// getReverbPort returns the port Reverb listens on
+func getReverbPort(svc *Service) int {
+	if svc.ReverbPort > 0 {
+		return svc.ReverbPort
+	}
+	return 8080
+}
+
+// getWebSocketLocations returns the locations of a vhost that only carry websockets: the
+// websocket_paths of the service, and the paths of Reverb for Laravel apps using it
+func getWebSocketLocations(svc *Service) []WebSocketLocation {
+	var locations []WebSocketLocation
+	for _, path := range svc.WebSocketPaths {
+		locations = append(locations, WebSocketLocation{Path: path, Upstream: svc.Name + "_backend"})
+	}
+	if svc.Reverb && (svc.Framework == "laravel" || svc.Framework == "lumen") {
+		for _, path := range reverbProxyPaths {
+			locations = append(locations, WebSocketLocation{Path: path, Upstream: fmt.Sprintf("reverb:%d", getReverbPort(svc))})
+		}
+	}
+	return locations
+}
+
+// validateWebSocket checks the websocket options of a service
+func validateWebSocket(svc *Service) error {
+	if !svc.WebSocket && len(svc.WebSocketPaths) == 0 {
+		return nil
+	}
+	// PHP-FPM answers one request at a time, Reverb serves the websockets of Laravel apps
+	if strings.HasPrefix(svc.Runtime, "php") {
+		return fmt.Errorf("service %s: PHP services can't serve websockets, use 'reverb = true'", svc.Name)
+	}
+	for _, path := range svc.WebSocketPaths {
+		if !strings.HasPrefix(path, "/") || strings.ContainsAny(path, " \t\n;{}") {
+			return fmt.Errorf("service %s: invalid websocket_paths entry %q, use a path like /socket.io/", svc.Name, path)
+		}
+	}
+	return nil
+}
+
+// addReverbBrowserEnvVars points the browser at Reverb through the vhost of the app, since
+// it can't resolve the reverb container
+func addReverbBrowserEnvVars(service *DockerService, svc *Service, config *Config) {
+	if config == nil || config.Cloud || !shouldAddNginxProxy(config) || getDomainForService(svc) == "" {
+		return
+	}
+	scheme, port := "http", 80
+	if svc.SSL {
+		scheme, port = "https", 443
+		if svc.SSLPort != 0 {
+			port = svc.SSLPort
+		}
+	}
+	if config.Unprivileged {
+		switch port {
+		case 80:
+			port = unprivilegedHTTPPort
+		case 443:
+			port = unprivilegedHTTPSPort
+		}
+	}
+	service.Environment["VITE_REVERB_HOST"] = getSiteDomain(svc)
+	service.Environment["VITE_REVERB_PORT"] = strconv.Itoa(port)
+	service.Environment["VITE_REVERB_SCHEME"] = scheme
+}
@@ -0,0 +1,97 @@
+package fleetcli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WebSocketTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *WebSocketTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *WebSocketTestSuite) TearDownTest() {
+	os.Chdir(suite.originalDir)
+	suite.helper.Cleanup()
+}
+
+func (suite *WebSocketTestSuite) TestValidateWebSocket() {
+	suite.NoError(validateWebSocket(&Service{Name: "web", Image: "node:20"}))
+	suite.NoError(validateWebSocket(&Service{Name: "web", Image: "node:20", WebSocket: true, WebSocketPaths: []string{"/socket.io/"}}))
+
+	suite.Error(validateWebSocket(&Service{Name: "shop", Runtime: "php:8.3", WebSocket: true}))
+	suite.Error(validateWebSocket(&Service{Name: "web", Image: "node:20", WebSocketPaths: []string{"socket.io"}}))
+	suite.Error(validateWebSocket(&Service{Name: "web", Image: "node:20", WebSocketPaths: []string{"/ws; return 200"}}))
+}
+
+func (suite *WebSocketTestSuite) TestIsViteDevServer() {
+	os.MkdirAll("web", 0755)
+	os.WriteFile(filepath.Join("web", "package.json"), []byte(`{"devDependencies": {"vite": "^5.0.0"}}`), 0644)
+
+	suite.True(isWebSocketService(&Service{Name: "web", Runtime: "node:20", Folder: "web"}))
+	suite.False(isWebSocketService(&Service{Name: "web", Runtime: "node:20", Folder: "web", BuildCommand: "npm run build"}), "Built assets don't use hot reload")
+	suite.False(isWebSocketService(&Service{Name: "api", Runtime: "node:20", Folder: "api"}))
+}
+
+func (suite *WebSocketTestSuite) TestWebSocketServiceTimeouts() {
+	config := &Config{
+		Project: "test-project",
+		Services: []Service{
+			{Name: "chat", Image: "node:20", Domain: "chat.test", Port: 3000, WebSocket: true, WebSocketPaths: []string{"/socket.io/"}},
+			{Name: "web", Image: "node:20", Domain: "web.test", Port: 4000},
+		},
+	}
+
+	nginxConf, err := generateNginxConfig(config)
+	suite.Require().NoError(err)
+	suite.Contains(nginxConf, "map $http_upgrade $connection_upgrade")
+	suite.Contains(nginxConf, "location /socket.io/ {\n            proxy_pass http://chat_backend;")
+	suite.Contains(nginxConf, "proxy_read_timeout 1h;")
+	suite.Contains(nginxConf, "proxy_read_timeout 60s;", "Services without websockets keep the usual timeouts")
+	suite.NotContains(nginxConf, `Connection "upgrade"`)
+}
+
+func (suite *WebSocketTestSuite) TestReverbLocations() {
+	config := &Config{
+		Project: "test-project",
+		Services: []Service{
+			{Name: "api", Image: "nginx", Runtime: "php:8.3", Framework: "laravel", Folder: "api", Domain: "api.test", Reverb: true, ReverbPort: 8888},
+		},
+	}
+
+	nginxConf, err := generateNginxConfig(config)
+	suite.Require().NoError(err)
+	suite.Contains(nginxConf, "location /app/ {\n            proxy_pass http://reverb:8888;")
+	suite.Contains(nginxConf, "location /apps/ {\n            proxy_pass http://reverb:8888;")
+}
+
+func (suite *WebSocketTestSuite) TestReverbBrowserEnvVars() {
+	config := &Config{
+		Project:      "test-project",
+		Unprivileged: true,
+		Services: []Service{
+			{Name: "api", Image: "nginx", Runtime: "php:8.3", Framework: "laravel", Folder: "api", Domain: "api.test", SSL: true, Reverb: true},
+		},
+	}
+
+	compose := generateDockerCompose(config)
+	env := compose.Services["api"].Environment
+	suite.Equal("api.test", env["VITE_REVERB_HOST"])
+	suite.Equal(fmt.Sprint(unprivilegedHTTPSPort), env["VITE_REVERB_PORT"])
+	suite.Equal("https", env["VITE_REVERB_SCHEME"])
+}
+
+func TestWebSocketSuite(t *testing.T) {
+	suite.Run(t, new(WebSocketTestSuite))
+}
@@ -0,0 +1,150 @@
+package fleetcli
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tenantPattern matches a tenant subdomain, e.g. acme or eu.acme
+var tenantPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+
+// isWildcardDomain reports whether a domain serves every subdomain, e.g. *.myapp.test
+func isWildcardDomain(domain string) bool {
+	return strings.HasPrefix(domain, "*.")
+}
+
+// getWildcardBase returns the domain a wildcard covers the subdomains of, e.g.
+// myapp.test for *.myapp.test. Other domains are returned as-is.
+func getWildcardBase(domain string) string {
+	return strings.TrimPrefix(domain, "*.")
+}
+
+// getSiteDomain returns the domain to build the URLs of a service on. Wildcard domains
+// use their base, since a browser can't open *.myapp.test.
+func getSiteDomain(svc *Service) string {
+	return getWildcardBase(getDomainForService(svc))
+}
+
+// getWildcardDomains returns the wildcard domains of a service
+func getWildcardDomains(svc *Service) []string {
+	var wildcards []string
+	for _, domain := range getServiceDomains(svc) {
+		if isWildcardDomain(domain) {
+			wildcards = append(wildcards, domain)
+		}
+	}
+	return wildcards
+}
+
+// getTenantDomains returns the domains of the wildcards of a service the hosts file
+// can hold: each base domain and one per entry of tenants
+func getTenantDomains(svc *Service) []string {
+	var domains []string
+	for _, wildcard := range getWildcardDomains(svc) {
+		base := getWildcardBase(wildcard)
+		domains = append(domains, base)
+		for _, tenant := range svc.Tenants {
+			domains = append(domains, tenant+"."+base)
+		}
+	}
+	return domains
+}
+
+// getWildcardServerNames returns the extra server names of a wildcard service, so the
+// base domains and the localhost alias answer too
+func getWildcardServerNames(svc *Service, unprivileged bool) []string {
+	domains := getServiceDomains(svc)
+	var names []string
+	for _, wildcard := range getWildcardDomains(svc) {
+		if base := getWildcardBase(wildcard); !containsString(domains, base) && !containsString(names, base) {
+			names = append(names, base)
+		}
+	}
+	if unprivileged && isWildcardDomain(getDomainForService(svc)) {
+		names = append(names, "*."+getLocalhostAlias(svc))
+	}
+	return names
+}
+
+// isResolvedByFleetDNS reports whether dnsmasq answers for a domain. The bundled
+// config resolves all of .test to the loopback address.
+func isResolvedByFleetDNS(domain string) bool {
+	return strings.HasSuffix(domain, ".test")
+}
+
+// isDNSRunning reports whether dnsmasq is running and resolving .test domains
+var isDNSRunning = func() bool {
+	state, err := loadDNSState()
+	if err != nil || state.Strategy == dnsStrategyHosts {
+		return false
+	}
+	output, err := newCommand("docker", "ps", "-q", "--filter", "name=dnsmasq").Output()
+	return err == nil && len(strings.TrimSpace(string(output))) > 0
+}
+
+// validateWildcardDomain checks the wildcard domains of a service and its tenants
+func validateWildcardDomain(svc *Service) error {
+	hasWildcard := false
+	for _, domain := range getConfiguredDomains(svc) {
+		if !isWildcardDomain(domain) {
+			if strings.Contains(domain, "*") {
+				return fmt.Errorf("service %s: wildcard domains must start with '*.', e.g. *.myapp.test", svc.Name)
+			}
+			continue
+		}
+		hasWildcard = true
+		base := getWildcardBase(domain)
+		if strings.Contains(base, "*") {
+			return fmt.Errorf("service %s: only the first label of domain %s can be a wildcard", svc.Name, domain)
+		}
+		// *.test would catch the domains of every other project
+		if !strings.Contains(base, ".") {
+			return fmt.Errorf("service %s: wildcard domain %s must have a base domain, e.g. *.myapp.%s", svc.Name, domain, base)
+		}
+	}
+	if !hasWildcard {
+		if len(svc.Tenants) > 0 {
+			return fmt.Errorf("service %s: 'tenants' requires a wildcard domain, e.g. *.myapp.test", svc.Name)
+		}
+		return nil
+	}
+
+	for _, tenant := range svc.Tenants {
+		if !tenantPattern.MatchString(tenant) {
+			return fmt.Errorf("service %s: invalid tenant %q, use a lowercase subdomain like acme", svc.Name, tenant)
+		}
+	}
+	return nil
+}
+
+// printWildcardDNSStatus tells how the tenant subdomains of wildcard services resolve.
+// The hosts file can't hold wildcards, so only Fleet DNS resolves any tenant.
+func printWildcardDNSStatus(config *Config) {
+	dnsChecked, dnsRunning := false, false
+	for i := range config.Services {
+		svc := &config.Services[i]
+		for _, domain := range getWildcardDomains(svc) {
+			base := getWildcardBase(domain)
+			// Browsers and systemd-resolved resolve *.localhost on their own
+			if strings.HasSuffix(base, ".localhost") {
+				continue
+			}
+
+			if isResolvedByFleetDNS(base) {
+				if !dnsChecked {
+					dnsChecked, dnsRunning = true, isDNSRunning()
+				}
+				if dnsRunning {
+					infof("🌐 %s resolves through Fleet DNS\n", domain)
+					continue
+				}
+				warnf("⚠️  %s needs Fleet DNS, the hosts file can't hold wildcards\n", domain)
+				warnln("   Run 'fleet dns start' to resolve every tenant")
+			} else {
+				warnf("⚠️  %s isn't under .test, Fleet DNS can't resolve its tenants\n", domain)
+			}
+			warnf("   Only %s resolve through the hosts file, list more subdomains in 'tenants'\n", strings.Join(getTenantDomains(svc), ", "))
+		}
+	}
+}
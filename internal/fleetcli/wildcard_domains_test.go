@@ -0,0 +1,100 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WildcardDomainsTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *WildcardDomainsTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *WildcardDomainsTestSuite) TearDownTest() {
+	os.Chdir(suite.originalDir)
+	suite.helper.Cleanup()
+}
+
+func (suite *WildcardDomainsTestSuite) TestValidateWildcardDomain() {
+	suite.NoError(validateWildcardDomain(&Service{Name: "shop", Domain: "*.shop.test", Tenants: []string{"acme", "eu.globex"}}))
+	suite.NoError(validateWildcardDomain(&Service{Name: "shop", Domain: "shop.test"}))
+
+	suite.Error(validateWildcardDomain(&Service{Name: "shop", Domain: "*.test"}))
+	suite.Error(validateWildcardDomain(&Service{Name: "shop", Domain: "shop.*.test"}))
+	suite.Error(validateWildcardDomain(&Service{Name: "shop", Domain: "*.*.shop.test"}))
+	suite.Error(validateWildcardDomain(&Service{Name: "shop", Domain: "shop.test", Tenants: []string{"acme"}}))
+	suite.Error(validateWildcardDomain(&Service{Name: "shop", Domain: "*.shop.test", Tenants: []string{"Acme"}}))
+	suite.Error(validateWildcardDomain(&Service{Name: "shop", Domain: "*.shop.test", Tenants: []string{"acme."}}))
+}
+
+func (suite *WildcardDomainsTestSuite) TestDomainMappingsSkipWildcard() {
+	config := &Config{
+		Services: []Service{
+			{Name: "shop", Domain: "*.shop.test", Port: 3000, Tenants: []string{"acme"}},
+			{Name: "api", Domain: "api.test", Port: 8080},
+		},
+	}
+
+	suite.Equal(map[string]string{
+		"shop.test":      "127.0.0.1",
+		"acme.shop.test": "127.0.0.1",
+		"api.test":       "127.0.0.1",
+	}, getDomainMappings(config))
+}
+
+func (suite *WildcardDomainsTestSuite) TestNginxServerNames() {
+	config := &Config{
+		Services: []Service{{Name: "shop", Domain: "*.shop.test", Port: 3000}},
+	}
+
+	services := getNginxServices(config)
+	suite.Require().Len(services, 1)
+	suite.Equal("*.shop.test", services[0].Domain)
+	suite.Equal([]string{"shop.test"}, services[0].Aliases)
+	suite.Equal("wildcard_shop_test", services[0].SanitizedDomain)
+
+	config.Unprivileged = true
+	services = getNginxServices(config)
+	suite.Equal([]string{"shop.localhost", "shop.test", "*.shop.localhost"}, services[0].Aliases)
+}
+
+func (suite *WildcardDomainsTestSuite) TestWildcardCertificateCoversBaseDomain() {
+	sslDir := filepath.Join(suite.helper.TempDir(), "ssl")
+	suite.Require().NoError(os.MkdirAll(sslDir, 0755))
+	cert := SSLCertificate{
+		Domain:     "*.shop.test",
+		CommonName: "*.shop.test",
+		CertPath:   filepath.Join(sslDir, "wildcard_shop_test.crt"),
+		KeyPath:    filepath.Join(sslDir, "wildcard_shop_test.key"),
+	}
+	suite.Require().NoError(generateSelfSignedCertificate(cert))
+
+	parsed, err := readCertificate(cert.CertPath)
+	suite.Require().NoError(err)
+	suite.NoError(parsed.VerifyHostname("shop.test"))
+	suite.NoError(parsed.VerifyHostname("acme.shop.test"))
+	suite.False(needsSSLCertificate(cert))
+}
+
+func (suite *WildcardDomainsTestSuite) TestSiteDomain() {
+	svc := &Service{Name: "shop", Domain: "*.shop.test", Port: 3000, SSL: true}
+
+	suite.Equal("shop.test", getSiteDomain(svc))
+	suite.Equal("https://shop.test", getFrameworkSiteURL(svc, &Config{}))
+
+	suite.Equal(".shop.test", (&RubyConfigurator{}).getEnvironment(svc, "rails", 3000)["RAILS_DEVELOPMENT_HOSTS"])
+}
+
+func TestWildcardDomainsSuite(t *testing.T) {
+	suite.Run(t, new(WildcardDomainsTestSuite))
+}
@@ -0,0 +1,113 @@
+package fleetcli
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var workerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// getWorkerServiceName returns the compose service running the worker at index
+func getWorkerServiceName(serviceName string, index int, worker Worker) string {
+	if worker.Name != "" {
+		return fmt.Sprintf("%s-worker-%s", serviceName, worker.Name)
+	}
+	return fmt.Sprintf("%s-worker-%d", serviceName, index+1)
+}
+
+// validateWorkers checks the workers of every service. Their names share the compose
+// namespace with services and init containers, so they must not collide.
+func validateWorkers(config *Config) error {
+	names := make(map[string]string)
+	for _, svc := range config.Services {
+		names[svc.Name] = fmt.Sprintf("service %s", svc.Name)
+		for i, init := range svc.Init {
+			names[getInitContainerName(svc.Name, i, init)] = fmt.Sprintf("an init container of %s", svc.Name)
+		}
+	}
+
+	for _, svc := range config.Services {
+		if len(svc.Workers) > 0 && (svc.Native || svc.ExternalService != "") {
+			return fmt.Errorf("service %s: workers run in containers, and the service has none", svc.Name)
+		}
+		for i, worker := range svc.Workers {
+			name := getWorkerServiceName(svc.Name, i, worker)
+			if worker.Name != "" && !workerNamePattern.MatchString(worker.Name) {
+				return fmt.Errorf("service %s: invalid worker name %q, use lowercase letters, digits, - and _", svc.Name, worker.Name)
+			}
+			if worker.Command == "" {
+				return fmt.Errorf("service %s: worker %s needs a 'command'", svc.Name, name)
+			}
+			if worker.Replicas < 0 {
+				return fmt.Errorf("service %s: worker %s: replicas must be positive", svc.Name, name)
+			}
+			if existing, exists := names[name]; exists {
+				return fmt.Errorf("service %s: worker name %s is already used by %s", svc.Name, name, existing)
+			}
+			names[name] = fmt.Sprintf("a worker of %s", svc.Name)
+		}
+	}
+	return nil
+}
+
+// newWorkerService returns a container running command with the image, code and
+// environment of the container running the code of a service. The variables of attached
+// services, like the address of Redis, are set on the main container, those of the app
+// itself on the container running PHP, so workers get both.
+func newWorkerService(compose *DockerCompose, svc *Service, command string) (DockerService, bool) {
+	appName := getAppServiceName(svc)
+	app, exists := compose.Services[appName]
+	if !exists {
+		return DockerService{}, false
+	}
+
+	worker := DockerService{
+		Image:       app.Image,
+		Build:       app.Build,
+		Volumes:     append([]string{}, app.Volumes...),
+		EnvFile:     append([]string{}, app.EnvFile...),
+		Networks:    append([]string{}, app.Networks...),
+		WorkingDir:  app.WorkingDir,
+		ExtraHosts:  append([]string{}, app.ExtraHosts...),
+		Runtime:     app.Runtime,
+		Entrypoint:  app.Entrypoint,
+		Command:     command,
+		Restart:     "unless-stopped",
+		Environment: make(map[string]string),
+	}
+
+	for _, name := range []string{svc.Name, appName} {
+		service := compose.Services[name]
+		for key, value := range service.Environment {
+			worker.Environment[key] = value
+		}
+		for _, dep := range service.DependsOn {
+			// Workers don't need the app itself, nor its assets dev server
+			if dep == appName || dep == getAssetsServiceName(svc.Name) || containsString(worker.DependsOn, dep) {
+				continue
+			}
+			worker.DependsOn = append(worker.DependsOn, dep)
+			if condition, ok := service.DependsOnConditions[dep]; ok {
+				if worker.DependsOnConditions == nil {
+					worker.DependsOnConditions = make(map[string]string)
+				}
+				worker.DependsOnConditions[dep] = condition
+			}
+		}
+	}
+	return worker, true
+}
+
+// addWorkers adds the workers of a service, each running its replicas
+func addWorkers(compose *DockerCompose, svc *Service) {
+	for i, worker := range svc.Workers {
+		service, ok := newWorkerService(compose, svc, worker.Command)
+		if !ok {
+			return
+		}
+		if worker.Replicas > 1 {
+			service.Deploy = &DockerDeploy{Replicas: worker.Replicas}
+		}
+		compose.Services[getWorkerServiceName(svc.Name, i, worker)] = service
+	}
+}
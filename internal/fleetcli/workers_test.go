@@ -0,0 +1,128 @@
+package fleetcli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WorkersTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+}
+
+func (suite *WorkersTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+	suite.originalDir, _ = os.Getwd()
+	os.Chdir(suite.helper.TempDir())
+}
+
+func (suite *WorkersTestSuite) TearDownTest() {
+	os.Chdir(suite.originalDir)
+	suite.helper.Cleanup()
+}
+
+func (suite *WorkersTestSuite) TestGetWorkerServiceName() {
+	suite.Equal("api-worker-emails", getWorkerServiceName("api", 0, Worker{Name: "emails"}))
+	suite.Equal("api-worker-2", getWorkerServiceName("api", 1, Worker{}))
+}
+
+func (suite *WorkersTestSuite) TestValidateWorkers() {
+	valid := &Config{Services: []Service{{Name: "api", Image: "node:20", Workers: []Worker{
+		{Name: "emails", Command: "node worker.js", Replicas: 3},
+		{Command: "node cleanup.js"},
+	}}}}
+	suite.NoError(validateWorkers(valid))
+
+	testCases := []struct {
+		name    string
+		svc     Service
+		wantErr string
+	}{
+		{"no command", Service{Workers: []Worker{{Name: "emails"}}}, "needs a 'command'"},
+		{"invalid name", Service{Workers: []Worker{{Name: "Emails Queue", Command: "run"}}}, "invalid worker name"},
+		{"negative replicas", Service{Workers: []Worker{{Command: "run", Replicas: -1}}}, "replicas must be positive"},
+		{"duplicate", Service{Workers: []Worker{{Name: "a", Command: "run"}, {Name: "a", Command: "run"}}}, "already used by a worker of api"},
+		{"native", Service{Native: true, Workers: []Worker{{Command: "run"}}}, "has none"},
+	}
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			tc.svc.Name = "api"
+			err := validateWorkers(&Config{Services: []Service{tc.svc}})
+			suite.Require().Error(err)
+			suite.Contains(err.Error(), tc.wantErr)
+		})
+	}
+
+	// Workers can't take the name of another service
+	err := validateWorkers(&Config{Services: []Service{
+		{Name: "api", Workers: []Worker{{Name: "emails", Command: "run"}}},
+		{Name: "api-worker-emails", Image: "node:20"},
+	}})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "already used by service api-worker-emails")
+}
+
+func (suite *WorkersTestSuite) TestWorkerServices() {
+	config := &Config{Project: "test", Services: []Service{{
+		Name:        "shop",
+		Image:       "nginx:alpine",
+		Runtime:     "php:8.3",
+		Framework:   "laravel",
+		Folder:      "shop",
+		Domain:      "shop.test",
+		Cache:       "redis",
+		Environment: map[string]string{"APP_NAME": "Shop"},
+		Workers: []Worker{
+			{Name: "default", Command: "php artisan queue:work", Replicas: 3},
+			{Command: "php artisan queue:work --queue=emails"},
+		},
+	}}}
+
+	compose := generateDockerCompose(config)
+	php := compose.Services["shop-php"]
+
+	suite.Require().Contains(compose.Services, "shop-worker-default")
+	worker := compose.Services["shop-worker-default"]
+	suite.Equal(php.Image, worker.Image)
+	suite.Equal(php.Volumes, worker.Volumes)
+	suite.Equal("php artisan queue:work", worker.Command)
+	suite.Equal("unless-stopped", worker.Restart)
+	suite.Require().NotNil(worker.Deploy)
+	suite.Equal(3, worker.Deploy.Replicas)
+	suite.Nil(worker.HealthCheck)
+	suite.Empty(worker.Ports)
+	suite.Equal("Shop", worker.Environment["APP_NAME"])
+	suite.Contains(worker.Environment, "REDIS_HOST")
+	suite.NotContains(worker.DependsOn, "shop-php")
+
+	suite.Require().Contains(compose.Services, "shop-worker-2")
+	suite.Nil(compose.Services["shop-worker-2"].Deploy)
+	suite.Equal("php artisan queue:work --queue=emails", compose.Services["shop-worker-2"].Command)
+}
+
+func (suite *WorkersTestSuite) TestNodeWorker() {
+	config := &Config{Project: "test", Services: []Service{{
+		Name:    "api",
+		Image:   "node:20",
+		Port:    3000,
+		Domain:  "api.test",
+		Folder:  "api",
+		Workers: []Worker{{Name: "jobs", Command: "node dist/worker.js", Replicas: 2}},
+	}}}
+
+	compose := generateDockerCompose(config)
+	api := compose.Services["api"]
+	worker := compose.Services["api-worker-jobs"]
+	suite.Equal("node:20", worker.Image)
+	suite.Equal(api.Volumes, worker.Volumes)
+	suite.Equal("node dist/worker.js", worker.Command)
+	suite.Empty(worker.Labels)
+	suite.Equal(2, worker.Deploy.Replicas)
+}
+
+func TestWorkersSuite(t *testing.T) {
+	suite.Run(t, new(WorkersTestSuite))
+}
@@ -0,0 +1,448 @@
+package fleetcli
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// defaultWorkspaceFile is the workspace file `fleet ws` reads unless -f is given
+const defaultWorkspaceFile = "fleet-workspace.toml"
+
+// workspaceComposeFile runs the proxy shared by every project of a workspace
+const workspaceComposeFile = ".fleet/workspace-compose.yml"
+
+// projectConfigFiles are the config files looked for in a workspace project, in order
+var projectConfigFiles = []string{"fleet.toml", "fleet.yaml", "fleet.yml", "fleet.json"}
+
+// Workspace lists project directories that run together
+type Workspace struct {
+	Name     string   `toml:"name"`
+	Projects []string `toml:"projects"`
+}
+
+// WorkspaceProject is a project of a workspace with its loaded config
+type WorkspaceProject struct {
+	Dir    string
+	Config *Config
+}
+
+// getComposeFiles returns the generated compose files of the project
+func (p *WorkspaceProject) getComposeFiles() ComposeFiles {
+	return findComposeFiles(p.Dir, p.Config)
+}
+
+// loadWorkspace reads a workspace file
+func loadWorkspace(filename string) (*Workspace, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace file: %w", err)
+	}
+
+	var workspace Workspace
+	if err := toml.Unmarshal(data, &workspace); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace file: %w", err)
+	}
+	if len(workspace.Projects) == 0 {
+		return nil, fmt.Errorf("workspace %s has no projects", filename)
+	}
+	if workspace.Name == "" {
+		absPath, _ := filepath.Abs(filename)
+		workspace.Name = filepath.Base(filepath.Dir(absPath))
+	}
+	return &workspace, nil
+}
+
+// findProjectConfig returns the config file of a project directory
+func findProjectConfig(dir string) (string, error) {
+	for _, name := range projectConfigFiles {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no fleet config found in %s", dir)
+}
+
+// loadWorkspaceProjects loads the config of every project, resolving directories
+// relative to the workspace file
+func loadWorkspaceProjects(workspace *Workspace, baseDir string) ([]WorkspaceProject, error) {
+	var projects []WorkspaceProject
+	for _, dir := range workspace.Projects {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(baseDir, dir)
+		}
+
+		configFile, err := findProjectConfig(dir)
+		if err != nil {
+			return nil, err
+		}
+		config, err := loadConfig(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", configFile, err)
+		}
+		projects = append(projects, WorkspaceProject{Dir: filepath.Clean(dir), Config: config})
+	}
+
+	if err := validateWorkspaceProjects(projects); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// validateWorkspaceProjects checks that projects can run side by side. Every Fleet
+// project joins the same compose project, so service names must be unique.
+func validateWorkspaceProjects(projects []WorkspaceProject) error {
+	owners := make(map[string]string)
+	for _, project := range projects {
+		for _, svc := range project.Config.Services {
+			if owner, exists := owners[svc.Name]; exists {
+				return fmt.Errorf("service %s is defined by both %s and %s", svc.Name, owner, project.Config.Project)
+			}
+			owners[svc.Name] = project.Config.Project
+		}
+	}
+	return nil
+}
+
+// mergeWorkspaceConfig combines the services of all projects so one proxy routes
+// every domain. Folders are made relative to the workspace directory.
+func mergeWorkspaceConfig(name, baseDir string, projects []WorkspaceProject) *Config {
+	merged := &Config{Project: name}
+	for _, project := range projects {
+		for _, svc := range project.Config.Services {
+			if svc.Folder != "" {
+				if folder, err := filepath.Rel(baseDir, filepath.Join(project.Dir, svc.Folder)); err == nil {
+					svc.Folder = folder
+				}
+			}
+			merged.Services = append(merged.Services, svc)
+		}
+	}
+	return merged
+}
+
+// inDirectory runs fn with dir as the working directory, since compose generation
+// writes relative to it
+func inDirectory(dir string, fn func() error) error {
+	originalDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	defer os.Chdir(originalDir)
+	return fn()
+}
+
+// prepareWorkspaceProject generates the compose file of a project without its own proxy
+func prepareWorkspaceProject(project *WorkspaceProject) error {
+	if err := validateHostPath("project directory", project.Dir); err != nil {
+		return err
+	}
+	return inDirectory(project.Dir, func() error {
+		release, err := acquireProjectLock("ws up", &ProjectLockOptions{})
+		if err != nil {
+			return err
+		}
+		defer release()
+
+		compose := generateDockerCompose(project.Config)
+		// The workspace runs one proxy for every project
+		delete(compose.Services, "nginx-proxy")
+		delete(compose.Services, traefikServiceName)
+
+		if err := ensureFleetGitignore(); err != nil {
+			warnf("⚠️  Warning: %v\n", err)
+		}
+		if _, err := writeComposeFiles(project.Config, compose); err != nil {
+			return err
+		}
+
+		migrateProjectVolumes(compose, project.Dir)
+		return nil
+	})
+}
+
+// buildWorkspaceProxy returns the compose file of the proxy shared by the workspace
+func buildWorkspaceProxy(merged *Config) *DockerCompose {
+	compose := newDockerCompose()
+	compose.Volumes = nil
+
+	addNginxProxyToCompose(compose, merged)
+	if proxy, exists := compose.Services["nginx-proxy"]; exists {
+		// Services live in the project compose files and are started first
+		proxy.DependsOn = nil
+		compose.Services["nginx-proxy"] = proxy
+	}
+	return compose
+}
+
+// runWorkspaceCommand runs a compose command for a project, printing its output only on failure
+func runWorkspaceCommand(project *WorkspaceProject, args ...string) error {
+	args = composeArgs(project.getComposeFiles(), args...)
+	cmd, err := dockerCommand(args...)
+	if err != nil {
+		return err
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		exitIfInterrupted()
+		return fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// forEachProject runs fn for every project in parallel and reports each result
+func forEachProject(projects []WorkspaceProject, verb string, fn func(*WorkspaceProject) error) bool {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ok := true
+
+	for i := range projects {
+		wg.Add(1)
+		go func(project *WorkspaceProject) {
+			defer wg.Done()
+			err := fn(project)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				ok = false
+				warnf("❌ %s failed to %s: %v\n", project.Config.Project, verb, err)
+				return
+			}
+			infof("✅ %s\n", project.Config.Project)
+		}(&projects[i])
+	}
+	wg.Wait()
+	return ok
+}
+
+// summarizeProjectStatus counts the services of a project that are up
+func summarizeProjectStatus(compose *DockerCompose, entries []ComposePSEntry) (up, total int) {
+	statuses := collectServiceStatuses(compose, entries)
+	for name := range compose.Services {
+		if statuses[name] == serviceStatusStarted || statuses[name] == serviceStatusHealthy {
+			up++
+		}
+	}
+	return up, len(compose.Services)
+}
+
+func handleWorkspace() {
+	if len(os.Args) < 3 {
+		printWorkspaceUsage()
+		os.Exit(0)
+	}
+
+	subcommand := os.Args[2]
+
+	switch subcommand {
+	case "up", "start":
+		handleWorkspaceUp(os.Args[3:])
+	case "down", "stop":
+		handleWorkspaceDown(os.Args[3:])
+	case "status", "ps":
+		handleWorkspaceStatus(os.Args[3:])
+	case "help":
+		printWorkspaceUsage()
+	default:
+		fmt.Printf("Unknown workspace command: %s\n\n", subcommand)
+		printWorkspaceUsage()
+		os.Exit(1)
+	}
+}
+
+func printWorkspaceUsage() {
+	fmt.Println("Fleet workspace - Run several projects together")
+	fmt.Println("\nUsage: fleet ws <command> [options]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  up          Start every project and the shared proxy")
+	fmt.Println("  down        Stop every project and the shared proxy")
+	fmt.Println("  status      Show the status of every project")
+	fmt.Println("\nOptions:")
+	fmt.Printf("  -f, --file       Specify workspace file (default: %s)\n", defaultWorkspaceFile)
+	fmt.Println("  -v               Remove volumes (for 'down' command)")
+	fmt.Println("  --no-privileged  Leave the hosts file and ports 80/443 alone")
+	fmt.Println("\nExample fleet-workspace.toml:")
+	fmt.Println("  name = \"acme\"")
+	fmt.Println("  projects = [\"../billing\", \"../shop\"]")
+}
+
+// loadWorkspaceFromArgs parses the common workspace flags and loads the workspace
+func loadWorkspaceFromArgs(name string, args []string, setup func(fs *flag.FlagSet)) (*Workspace, []WorkspaceProject, string) {
+	fs := newCommandFlagSet(name, flag.ExitOnError)
+	workspaceFile := fs.String("f", defaultWorkspaceFile, "Workspace file")
+	workspaceFileLong := fs.String("file", defaultWorkspaceFile, "Workspace file")
+	if setup != nil {
+		setup(fs)
+	}
+
+	fs.Parse(args)
+
+	if *workspaceFileLong != defaultWorkspaceFile {
+		*workspaceFile = *workspaceFileLong
+	}
+
+	workspace, err := loadWorkspace(*workspaceFile)
+	if err != nil {
+		log.Fatalf("❌ Error loading workspace: %v", err)
+	}
+
+	baseDir, err := filepath.Abs(filepath.Dir(*workspaceFile))
+	if err != nil {
+		log.Fatalf("❌ Error resolving workspace directory: %v", err)
+	}
+
+	projects, err := loadWorkspaceProjects(workspace, baseDir)
+	if err != nil {
+		log.Fatalf("❌ Error loading workspace projects: %v", err)
+	}
+	return workspace, projects, baseDir
+}
+
+func handleWorkspaceUp(args []string) {
+	var noPrivileged *bool
+	workspace, projects, baseDir := loadWorkspaceFromArgs("ws up", args, func(fs *flag.FlagSet) {
+		noPrivileged = fs.Bool("no-privileged", false, "Leave the hosts file and ports 80/443 alone")
+	})
+
+	infof("🚀 Starting Fleet workspace: %s (%d projects)\n", workspace.Name, len(projects))
+
+	// Generation works in the project directory, so it can't run in parallel
+	for i := range projects {
+		if err := prepareWorkspaceProject(&projects[i]); err != nil {
+			log.Fatalf("❌ Error preparing %s: %v", projects[i].Config.Project, err)
+		}
+	}
+
+	// The first project creates the network the others join
+	start := func(project *WorkspaceProject) error {
+		return runWorkspaceCommand(project, "up", "-d")
+	}
+	ok := forEachProject(projects[:1], "start", start)
+	if ok && len(projects) > 1 {
+		ok = forEachProject(projects[1:], "start", start)
+	}
+
+	merged := mergeWorkspaceConfig(workspace.Name, baseDir, projects)
+	merged.Unprivileged = isUnprivileged(*noPrivileged)
+	if shouldAddNginxProxy(merged) {
+		err := inDirectory(baseDir, func() error {
+			proxy := buildWorkspaceProxy(merged)
+			if err := writeArtifacts(proxy.Artifacts); err != nil {
+				return err
+			}
+			if err := writeDockerCompose(proxy, workspaceComposeFile); err != nil {
+				return err
+			}
+			return runDocker([]string{"compose", "-f", workspaceComposeFile, "up", "-d"})
+		})
+		if err != nil {
+			log.Fatalf("❌ Error starting workspace proxy: %v", err)
+		}
+
+		if merged.Unprivileged {
+			printUnprivilegedURLs(merged)
+		} else {
+			infoln("📝 Updating hosts file with workspace domains...")
+			if err := updateHostsFileWithDomains(merged); err != nil {
+				warnf("⚠️  Warning: failed to update hosts file: %v\n", err)
+				infoln("   You may need to run with sudo or update hosts file manually")
+			}
+		}
+	}
+
+	if !ok {
+		log.Fatalf("❌ Some projects of workspace %s failed to start", workspace.Name)
+	}
+	infoln("✅ Workspace started")
+}
+
+func handleWorkspaceDown(args []string) {
+	var volumes, noPrivileged *bool
+	workspace, projects, baseDir := loadWorkspaceFromArgs("ws down", args, func(fs *flag.FlagSet) {
+		volumes = fs.Bool("v", false, "Remove volumes")
+		noPrivileged = fs.Bool("no-privileged", false, "Leave the hosts file alone")
+	})
+
+	infof("🛑 Stopping Fleet workspace: %s\n", workspace.Name)
+
+	// Stop the proxy first so it doesn't route to stopped services
+	if _, err := os.Stat(filepath.Join(baseDir, workspaceComposeFile)); err == nil {
+		err := inDirectory(baseDir, func() error {
+			return runDocker([]string{"compose", "-f", workspaceComposeFile, "down"})
+		})
+		if err != nil {
+			warnf("⚠️  Warning: failed to stop workspace proxy: %v\n", err)
+		}
+	}
+
+	downArgs := []string{"down"}
+	if *volumes {
+		downArgs = append(downArgs, "-v")
+		infoln("   Removing volumes...")
+	}
+	ok := forEachProject(projects, "stop", func(project *WorkspaceProject) error {
+		return runWorkspaceCommand(project, downArgs...)
+	})
+
+	if shouldAddNginxProxy(mergeWorkspaceConfig(workspace.Name, baseDir, projects)) && !isUnprivileged(*noPrivileged) {
+		if err := removeDomainsFromHostsFile(); err != nil {
+			warnf("⚠️  Warning: failed to clean hosts file: %v\n", err)
+		}
+	}
+
+	if !ok {
+		log.Fatalf("❌ Some projects of workspace %s failed to stop", workspace.Name)
+	}
+	infoln("✅ Workspace stopped")
+}
+
+func handleWorkspaceStatus(args []string) {
+	workspace, projects, _ := loadWorkspaceFromArgs("ws status", args, nil)
+
+	infof("📊 Fleet workspace status: %s\n\n", workspace.Name)
+
+	sort.SliceStable(projects, func(i, j int) bool {
+		return projects[i].Config.Project < projects[j].Config.Project
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tSERVICES\tSTATUS\tDIRECTORY")
+	for i := range projects {
+		project := &projects[i]
+		compose, err := readComposeFiles(project.getComposeFiles())
+		if err != nil {
+			fmt.Fprintf(w, "%s\t-\tnot started\t%s\n", project.Config.Project, project.Dir)
+			continue
+		}
+
+		entries, err := getComposeServiceStates(project.getComposeFiles())
+		if err != nil {
+			fmt.Fprintf(w, "%s\t-\tunknown\t%s\n", project.Config.Project, project.Dir)
+			continue
+		}
+
+		up, total := summarizeProjectStatus(compose, entries)
+		status := "✅ running"
+		switch {
+		case up == 0:
+			status = "⏹  stopped"
+		case up < total:
+			status = "⚠️  degraded"
+		}
+		fmt.Fprintf(w, "%s\t%d/%d\t%s\t%s\n", project.Config.Project, up, total, status, project.Dir)
+	}
+	w.Flush()
+}
@@ -0,0 +1,190 @@
+package fleetcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WorkspaceTestSuite struct {
+	suite.Suite
+	helper      *TestHelper
+	originalDir string
+	baseDir     string
+}
+
+func (suite *WorkspaceTestSuite) SetupTest() {
+	suite.helper = NewTestHelper(suite.T())
+
+	suite.originalDir, _ = os.Getwd()
+	suite.baseDir = suite.helper.TempDir()
+	suite.Require().NoError(os.Chdir(suite.baseDir))
+}
+
+func (suite *WorkspaceTestSuite) TearDownTest() {
+	if suite.originalDir != "" {
+		os.Chdir(suite.originalDir)
+	}
+	suite.helper.Cleanup()
+}
+
+// writeProject creates a project directory with a fleet.toml
+func (suite *WorkspaceTestSuite) writeProject(dir, config string) {
+	suite.Require().NoError(os.MkdirAll(filepath.Join(suite.baseDir, dir), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.baseDir, dir, "fleet.toml"), []byte(config), 0644))
+}
+
+func (suite *WorkspaceTestSuite) TestLoadWorkspace() {
+	suite.Require().NoError(os.WriteFile("fleet-workspace.toml", []byte(`
+name = "acme"
+projects = ["./billing", "./shop"]
+`), 0644))
+
+	workspace, err := loadWorkspace("fleet-workspace.toml")
+	suite.Require().NoError(err)
+	suite.Equal("acme", workspace.Name)
+	suite.Equal([]string{"./billing", "./shop"}, workspace.Projects)
+}
+
+func (suite *WorkspaceTestSuite) TestLoadWorkspaceWithoutProjects() {
+	suite.Require().NoError(os.WriteFile("fleet-workspace.toml", []byte(`name = "acme"`), 0644))
+
+	_, err := loadWorkspace("fleet-workspace.toml")
+	suite.Error(err)
+	suite.Contains(err.Error(), "no projects")
+}
+
+func (suite *WorkspaceTestSuite) TestLoadWorkspaceProjects() {
+	suite.writeProject("billing", `
+project = "billing"
+
+[[services]]
+name = "billing-api"
+image = "node:20"
+port = 3000
+`)
+	suite.writeProject("shop", `
+project = "shop"
+
+[[services]]
+name = "shop-web"
+image = "nginx"
+port = 80
+`)
+
+	projects, err := loadWorkspaceProjects(&Workspace{Projects: []string{"billing", "shop"}}, suite.baseDir)
+	suite.Require().NoError(err)
+	suite.Require().Len(projects, 2)
+	suite.Equal(filepath.Join(suite.baseDir, "billing"), projects[0].Dir)
+	suite.Equal("shop", projects[1].Config.Project)
+}
+
+func (suite *WorkspaceTestSuite) TestLoadWorkspaceProjectsWithoutConfig() {
+	suite.Require().NoError(os.MkdirAll("empty", 0755))
+
+	_, err := loadWorkspaceProjects(&Workspace{Projects: []string{"empty"}}, suite.baseDir)
+	suite.Error(err)
+	suite.Contains(err.Error(), "no fleet config found")
+}
+
+func (suite *WorkspaceTestSuite) TestServiceNamesMustBeUnique() {
+	projects := []WorkspaceProject{
+		{Config: &Config{Project: "billing", Services: []Service{{Name: "api"}}}},
+		{Config: &Config{Project: "shop", Services: []Service{{Name: "api"}}}},
+	}
+
+	err := validateWorkspaceProjects(projects)
+	suite.Error(err)
+	suite.Contains(err.Error(), "api is defined by both billing and shop")
+}
+
+func (suite *WorkspaceTestSuite) TestMergeWorkspaceConfig() {
+	projects := []WorkspaceProject{
+		{Dir: filepath.Join(suite.baseDir, "billing"), Config: &Config{Services: []Service{{Name: "billing-api", Folder: "./api"}}}},
+		{Dir: filepath.Join(suite.baseDir, "shop"), Config: &Config{Services: []Service{{Name: "shop-web", Port: 80}}}},
+	}
+
+	merged := mergeWorkspaceConfig("acme", suite.baseDir, projects)
+
+	suite.Equal("acme", merged.Project)
+	suite.Require().Len(merged.Services, 2)
+	suite.Equal(filepath.Join("billing", "api"), merged.Services[0].Folder)
+	suite.Empty(merged.Services[1].Folder)
+
+	// The project configs aren't modified
+	suite.Equal("./api", projects[0].Config.Services[0].Folder)
+}
+
+func (suite *WorkspaceTestSuite) TestPrepareWorkspaceProjectSkipsProxy() {
+	suite.writeProject("shop", `
+project = "shop"
+
+[[services]]
+name = "shop-web"
+image = "nginx"
+port = 80
+`)
+	projects, err := loadWorkspaceProjects(&Workspace{Projects: []string{"shop"}}, suite.baseDir)
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(prepareWorkspaceProject(&projects[0]))
+
+	compose, err := readComposeFiles(projects[0].getComposeFiles())
+	suite.Require().NoError(err)
+	suite.Contains(compose.Services, "shop-web")
+	suite.NotContains(compose.Services, "nginx-proxy")
+	suite.FileExists(filepath.Join(suite.baseDir, "shop", ".fleet", ".gitignore"))
+
+	// The working directory is restored
+	cwd, _ := os.Getwd()
+	suite.Equal(suite.baseDir, cwd)
+}
+
+func (suite *WorkspaceTestSuite) TestBuildWorkspaceProxy() {
+	merged := &Config{
+		Project: "acme",
+		Services: []Service{
+			{Name: "billing-api", Image: "node:20", Port: 3000},
+			{Name: "shop-web", Image: "nginx", Port: 80},
+		},
+	}
+
+	compose := buildWorkspaceProxy(merged)
+	suite.Require().NoError(writeArtifacts(compose.Artifacts))
+
+	proxy, exists := compose.Services["nginx-proxy"]
+	suite.Require().True(exists)
+	suite.Len(compose.Services, 1)
+	suite.Empty(proxy.DependsOn)
+	suite.Contains(compose.Networks, "fleet-network")
+
+	nginxConfig, err := os.ReadFile(filepath.Join(".fleet", "nginx.conf"))
+	suite.Require().NoError(err)
+	suite.Contains(string(nginxConfig), "billing-api.test")
+	suite.Contains(string(nginxConfig), "shop-web.test")
+}
+
+func (suite *WorkspaceTestSuite) TestSummarizeProjectStatus() {
+	compose := &DockerCompose{Services: map[string]DockerService{
+		"api":      {},
+		"mysql-80": {},
+		"worker":   {},
+	}}
+	entries := []ComposePSEntry{
+		{Service: "api", State: "running"},
+		{Service: "mysql-80", State: "running", Health: "healthy"},
+		{Service: "worker", State: "exited", ExitCode: 1},
+		// Containers of other projects share the compose project
+		{Service: "shop-web", State: "running"},
+	}
+
+	up, total := summarizeProjectStatus(compose, entries)
+	suite.Equal(2, up)
+	suite.Equal(3, total)
+}
+
+func TestWorkspaceSuite(t *testing.T) {
+	suite.Run(t, new(WorkspaceTestSuite))
+}
@@ -1,4 +1,4 @@
-package main
+package fleetcli
 
 import (
 	"fmt"
@@ -14,6 +14,10 @@ type XdebugSuite struct {
 
 func (suite *XdebugSuite) SetupTest() {
 	suite.helper = NewTestHelper(suite.T())
+	// The official image installs Xdebug at startup, except on arm64
+	originalArch := hostArch
+	hostArch = "amd64"
+	suite.T().Cleanup(func() { hostArch = originalArch })
 }
 
 func (suite *XdebugSuite) TearDownTest() {
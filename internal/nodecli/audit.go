@@ -0,0 +1,374 @@
+package nodecli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fleet/fleet/internal/containers"
+)
+
+// severityOrder ranks audit severities from most to least severe
+var severityOrder = []string{"critical", "high", "moderate", "low", "info"}
+
+// PackageVulnerability is a vulnerable package reported by an audit
+type PackageVulnerability struct {
+	Name     string
+	Severity string
+}
+
+// OutdatedPackage is a dependency with a newer version available
+type OutdatedPackage struct {
+	Name    string
+	Current string
+	Wanted  string
+	Latest  string
+}
+
+// ServiceReport is the result of an audit or outdated check in one service
+type ServiceReport struct {
+	Service         string
+	Vulnerabilities []PackageVulnerability
+	Outdated        []OutdatedPackage
+	Err             error
+}
+
+// getMaintenanceCommand returns the package manager command that prints JSON for audit or outdated
+func getMaintenanceCommand(packageManager, command string) []string {
+	switch packageManager {
+	case "pnpm":
+		if command == "outdated" {
+			return []string{"pnpm", "outdated", "--format", "json"}
+		}
+		return []string{"pnpm", "audit", "--json"}
+	case "yarn":
+		return []string{"yarn", command, "--json"}
+	default:
+		return []string{"npm", command, "--json"}
+	}
+}
+
+// severityRank returns the position of a severity in severityOrder
+func severityRank(severity string) int {
+	for i, s := range severityOrder {
+		if s == severity {
+			return i
+		}
+	}
+	return len(severityOrder)
+}
+
+// addVulnerability records a vulnerable package, keeping the highest severity when it is reported twice
+func addVulnerability(found map[string]string, name, severity string) {
+	if current, exists := found[name]; !exists || severityRank(severity) < severityRank(current) {
+		found[name] = severity
+	}
+}
+
+// sortedVulnerabilities turns found packages into a list ordered by severity, then name
+func sortedVulnerabilities(found map[string]string) []PackageVulnerability {
+	var vulnerabilities []PackageVulnerability
+	for name, severity := range found {
+		vulnerabilities = append(vulnerabilities, PackageVulnerability{Name: name, Severity: severity})
+	}
+	sort.Slice(vulnerabilities, func(i, j int) bool {
+		ri, rj := severityRank(vulnerabilities[i].Severity), severityRank(vulnerabilities[j].Severity)
+		if ri != rj {
+			return ri < rj
+		}
+		return vulnerabilities[i].Name < vulnerabilities[j].Name
+	})
+	return vulnerabilities
+}
+
+// parseAuditOutput parses the JSON printed by npm, pnpm or yarn audit
+func parseAuditOutput(packageManager string, output []byte) ([]PackageVulnerability, error) {
+	found := make(map[string]string)
+
+	if packageManager == "yarn" {
+		// yarn prints one JSON object per line
+		scanner := bufio.NewScanner(bytes.NewReader(output))
+		scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var line struct {
+				Type string `json:"type"`
+				Data struct {
+					Advisory struct {
+						ModuleName string `json:"module_name"`
+						Severity   string `json:"severity"`
+					} `json:"advisory"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				continue
+			}
+			if line.Type == "auditAdvisory" {
+				addVulnerability(found, line.Data.Advisory.ModuleName, line.Data.Advisory.Severity)
+			}
+		}
+		return sortedVulnerabilities(found), scanner.Err()
+	}
+
+	// npm 7+ reports vulnerabilities by package, npm 6 and pnpm by advisory
+	var report struct {
+		Vulnerabilities map[string]struct {
+			Name     string `json:"name"`
+			Severity string `json:"severity"`
+		} `json:"vulnerabilities"`
+		Advisories map[string]struct {
+			ModuleName string `json:"module_name"`
+			Severity   string `json:"severity"`
+		} `json:"advisories"`
+		Error *struct {
+			Summary string `json:"summary"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse audit output: %w", err)
+	}
+	if report.Error != nil {
+		return nil, fmt.Errorf("audit failed: %s", report.Error.Summary)
+	}
+
+	for name, vulnerability := range report.Vulnerabilities {
+		addVulnerability(found, name, vulnerability.Severity)
+	}
+	for _, advisory := range report.Advisories {
+		addVulnerability(found, advisory.ModuleName, advisory.Severity)
+	}
+	return sortedVulnerabilities(found), nil
+}
+
+// parseOutdatedOutput parses the JSON printed by npm, pnpm or yarn outdated
+func parseOutdatedOutput(packageManager string, output []byte) ([]OutdatedPackage, error) {
+	var outdated []OutdatedPackage
+
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+
+	if packageManager == "yarn" {
+		// yarn prints a table object among its other output lines
+		scanner := bufio.NewScanner(bytes.NewReader(output))
+		scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var line struct {
+				Type string `json:"type"`
+				Data struct {
+					Body [][]string `json:"body"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil || line.Type != "table" {
+				continue
+			}
+			for _, row := range line.Data.Body {
+				if len(row) >= 4 {
+					outdated = append(outdated, OutdatedPackage{Name: row[0], Current: row[1], Wanted: row[2], Latest: row[3]})
+				}
+			}
+		}
+		sort.Slice(outdated, func(i, j int) bool { return outdated[i].Name < outdated[j].Name })
+		return outdated, scanner.Err()
+	}
+
+	type versions struct {
+		Current string `json:"current"`
+		Wanted  string `json:"wanted"`
+		Latest  string `json:"latest"`
+	}
+	var packages map[string]json.RawMessage
+	if err := json.Unmarshal(output, &packages); err != nil {
+		return nil, fmt.Errorf("failed to parse outdated output: %w", err)
+	}
+
+	for name, raw := range packages {
+		var entry versions
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			// npm lists a package once per workspace that depends on it
+			var entries []versions
+			if err := json.Unmarshal(raw, &entries); err != nil || len(entries) == 0 {
+				continue
+			}
+			entry = entries[0]
+		}
+		outdated = append(outdated, OutdatedPackage{Name: name, Current: entry.Current, Wanted: entry.Wanted, Latest: entry.Latest})
+	}
+	sort.Slice(outdated, func(i, j int) bool { return outdated[i].Name < outdated[j].Name })
+	return outdated, nil
+}
+
+// runDockerOutput runs a docker command and returns its standard output.
+// audit and outdated exit non-zero when they find something, so the output
+// is returned along with the error for the caller to parse.
+func runDockerOutput(args []string) ([]byte, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = 10 * time.Second
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil && len(bytes.TrimSpace(output)) == 0 {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s", message)
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+// checkService runs audit or outdated in the container of a service
+func checkService(service NodeService, command string) ServiceReport {
+	report := ServiceReport{Service: service.Name}
+
+	container, err := containers.FindServiceContainer(service.ComposeService)
+	if err != nil {
+		report.Err = err
+		return report
+	}
+	args := append([]string{"exec", "-w", "/app", container}, getMaintenanceCommand(service.PackageManager, command)...)
+	output, err := runDockerOutput(args)
+	if err != nil {
+		report.Err = err
+		return report
+	}
+
+	if command == "audit" {
+		report.Vulnerabilities, report.Err = parseAuditOutput(service.PackageManager, output)
+	} else {
+		report.Outdated, report.Err = parseOutdatedOutput(service.PackageManager, output)
+	}
+	return report
+}
+
+// printAuditSummary prints the vulnerable packages of every service in one table
+func printAuditSummary(reports []ServiceReport) int {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tCRITICAL\tHIGH\tMODERATE\tLOW\tINFO")
+	affected := make(map[string]map[string]string)
+	total := 0
+	for _, report := range reports {
+		if report.Err != nil {
+			fmt.Fprintf(w, "%s\terror: %v\n", report.Service, report.Err)
+			continue
+		}
+		counts := make(map[string]int)
+		for _, vulnerability := range report.Vulnerabilities {
+			counts[vulnerability.Severity]++
+			if affected[vulnerability.Name] == nil {
+				affected[vulnerability.Name] = make(map[string]string)
+			}
+			affected[vulnerability.Name][report.Service] = vulnerability.Severity
+		}
+		total += len(report.Vulnerabilities)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\n", report.Service,
+			counts["critical"], counts["high"], counts["moderate"], counts["low"], counts["info"])
+	}
+	w.Flush()
+
+	if total == 0 {
+		fmt.Println("\nNo vulnerable packages found")
+		return 0
+	}
+
+	found := make(map[string]string)
+	for name, services := range affected {
+		for _, severity := range services {
+			addVulnerability(found, name, severity)
+		}
+	}
+
+	fmt.Println("\nVulnerable packages:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tSEVERITY\tSERVICES")
+	for _, vulnerability := range sortedVulnerabilities(found) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", vulnerability.Name, vulnerability.Severity, strings.Join(sortedKeys(affected[vulnerability.Name]), ", "))
+	}
+	w.Flush()
+	return 1
+}
+
+// printOutdatedSummary prints the outdated packages of every service in one table
+func printOutdatedSummary(reports []ServiceReport) int {
+	latest := make(map[string]string)
+	current := make(map[string][]string)
+	for _, report := range reports {
+		if report.Err != nil {
+			fmt.Printf("%s: error: %v\n", report.Service, report.Err)
+			continue
+		}
+		for _, pkg := range report.Outdated {
+			latest[pkg.Name] = pkg.Latest
+			current[pkg.Name] = append(current[pkg.Name], fmt.Sprintf("%s@%s", report.Service, pkg.Current))
+		}
+	}
+
+	if len(latest) == 0 {
+		fmt.Println("All packages are up to date")
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tLATEST\tSERVICES")
+	for _, name := range sortedKeys(latest) {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, latest[name], strings.Join(current[name], ", "))
+	}
+	w.Flush()
+	return 1
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// runMaintenanceCommand runs audit or outdated in every Node.js service, or only the selected one,
+// and prints a summary across services. It returns the exit code.
+func runMaintenanceCommand(command string, services []NodeService, selected string) int {
+	if selected != "" {
+		var filtered []NodeService
+		for _, svc := range services {
+			if svc.Name == selected {
+				filtered = append(filtered, svc)
+			}
+		}
+		if len(filtered) == 0 {
+			fmt.Fprintf(os.Stderr, "Service '%s' not found or is not a Node.js service\n", selected)
+			return 1
+		}
+		services = filtered
+	}
+
+	var reports []ServiceReport
+	for _, svc := range services {
+		fmt.Printf("Running %s in %s...\n", command, svc.Name)
+		reports = append(reports, checkService(svc, command))
+	}
+	fmt.Println()
+
+	if command == "audit" {
+		return printAuditSummary(reports)
+	}
+	return printOutdatedSummary(reports)
+}
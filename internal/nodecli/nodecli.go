@@ -0,0 +1,523 @@
+// Package nodecli runs the Node.js tooling of the services of a Fleet project. It is both
+// fleet node and the standalone fleet-node binary.
+package nodecli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	
+	"github.com/BurntSushi/toml"
+	"github.com/fleet/fleet/internal/containers"
+	"gopkg.in/yaml.v3"
+)
+
+const version = "1.0.0"
+
+// configFiles are the names of the fleet configuration, in order of preference
+var configFiles = []string{"fleet.toml", "fleet.yaml", "fleet.yml", "fleet.json"}
+
+// Config represents fleet configuration (minimal subset needed)
+type Config struct {
+	Project  string    `toml:"project" yaml:"project" json:"project"`
+	Services []Service `toml:"services" yaml:"services" json:"services"`
+}
+
+// Service represents a service configuration (minimal subset)
+type Service struct {
+	Name           string `toml:"name" yaml:"name" json:"name"`
+	Runtime        string `toml:"runtime" yaml:"runtime" json:"runtime"`
+	Framework      string `toml:"framework" yaml:"framework" json:"framework"`
+	Folder         string `toml:"folder" yaml:"folder" json:"folder"`
+	Image          string `toml:"image" yaml:"image" json:"image"`
+	BuildCommand   string `toml:"build_command" yaml:"build_command" json:"build_command"`
+	PackageManager string `toml:"package_manager" yaml:"package_manager" json:"package_manager"`
+}
+
+// NodeService represents a detected Node.js service
+type NodeService struct {
+	Name           string
+	ComposeService string
+	ContainerName  string
+	Framework      string
+	Folder         string
+	PackageManager string
+	WorkDir        string
+}
+
+// Run runs a command with the arguments that follow it. name is how the user called it,
+// fleet-node or fleet node, so the help shows the same command.
+func Run(name string, arguments []string) {
+	// Parse flags
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	serviceFlag := flags.String("service", "", "Specify which service to use")
+	projectDirFlag := flags.String("project-dir", "", "Directory containing the fleet configuration")
+	versionFlag := flags.Bool("version", false, "Show version")
+	helpFlag := flags.Bool("help", false, "Show help")
+	flags.Parse(arguments)
+
+	if *versionFlag {
+		fmt.Printf("fleet-node v%s\n", version)
+		os.Exit(0)
+	}
+
+	if *helpFlag || flags.NArg() == 0 {
+		printUsage(name)
+		os.Exit(0)
+	}
+
+	// Get command and args
+	command := flags.Arg(0)
+	args := flags.Args()[1:]
+
+	// Find the project like git finds its repository, so commands work from subfolders
+	workDir, _ := os.Getwd()
+	projectDir := *projectDirFlag
+	if projectDir == "" {
+		dir, err := findProjectDir(workDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading fleet configuration: %v\n", err)
+			os.Exit(1)
+		}
+		projectDir = dir
+	}
+	projectDir, _ = filepath.Abs(projectDir)
+	if err := os.Chdir(projectDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening project directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Load configuration
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading fleet configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Find Node.js services
+	nodeServices := detectNodeServices(config)
+	if len(nodeServices) == 0 {
+		fmt.Fprintf(os.Stderr, "No Node.js services found in fleet configuration\n")
+		os.Exit(1)
+	}
+
+	// Maintenance commands run across every service
+	if command == "audit" || command == "outdated" {
+		os.Exit(runMaintenanceCommand(command, nodeServices, *serviceFlag))
+	}
+
+	// Select service
+	var selectedService *NodeService
+	if *serviceFlag != "" {
+		for _, svc := range nodeServices {
+			if svc.Name == *serviceFlag {
+				selectedService = &svc
+				break
+			}
+		}
+		if selectedService == nil {
+			fmt.Fprintf(os.Stderr, "Service '%s' not found or is not a Node.js service\n", *serviceFlag)
+			os.Exit(1)
+		}
+	} else if svc := findServiceForDir(nodeServices, projectDir, workDir); svc != nil {
+		selectedService = svc
+	} else {
+		selectedService = &nodeServices[0]
+		if len(nodeServices) > 1 {
+			fmt.Printf("Multiple Node.js services found. Using '%s'. Use --service flag to specify.\n", selectedService.Name)
+		}
+	}
+	// Package managers find package.json upwards, like they do on the host
+	selectedService.WorkDir = getContainerWorkDir("/app", projectDir, selectedService.Folder, workDir)
+
+	// Scripts are read from the package.json on the host, listing them needs no container
+	if command == "run" {
+		script := ""
+		if !isScriptListing(args) {
+			script = args[0]
+		}
+		packageJSON, err := findPackageJSON(filepath.Join(projectDir, selectedService.Folder), workDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error finding the scripts of %s: %v\n", selectedService.Name, err)
+			os.Exit(1)
+		}
+		scripts, err := findScript(packageJSON, script)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if script == "" {
+			printScripts(selectedService, packageJSON, scripts, len(args) > 0 && args[0] == "--complete")
+			return
+		}
+	}
+
+	selectedService.ContainerName, err = containers.FindServiceContainer(selectedService.ComposeService)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding the container of %s: %v\n", selectedService.Name, err)
+		os.Exit(1)
+	}
+
+	// Execute command
+	switch command {
+	case "npm":
+		executeNPM(selectedService, args)
+	case "yarn":
+		executeYarn(selectedService, args)
+	case "pnpm":
+		executePNPM(selectedService, args)
+	case "node":
+		executeNode(selectedService, args)
+	case "npx":
+		executeNPX(selectedService, args)
+	case "run":
+		runScript(selectedService, args[0], args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
+		printUsage(name)
+		os.Exit(1)
+	}
+}
+
+func printUsage(name string) {
+	fmt.Printf("%s - Node.js CLI tool for Fleet\n", name)
+	fmt.Printf("Version: %s\n\n", version)
+	fmt.Printf("Usage: %s [--service=<name>] <command> [args...]\n", name)
+	fmt.Println("\nCommands:")
+	fmt.Println("  npm [args...]        Run npm commands")
+	fmt.Println("  yarn [args...]       Run yarn commands")
+	fmt.Println("  pnpm [args...]       Run pnpm commands")
+	fmt.Println("  node [args...]       Run Node.js scripts")
+	fmt.Println("  npx [args...]        Run npx commands")
+	fmt.Println("  run <script> [args]  Run a script of package.json with the service's package manager")
+	fmt.Println("  run --list           List the scripts of package.json (--complete: names only)")
+	fmt.Println("  audit                Audit dependencies of every service for vulnerabilities")
+	fmt.Println("  outdated             List outdated dependencies across every service")
+	fmt.Println("\nFlags:")
+	fmt.Println("  --service=<name>     Specify which service to use (for multi-service projects)")
+	fmt.Println("  --project-dir=<dir>  Directory containing fleet.toml (default: closest parent with one)")
+	fmt.Println("  --version            Show version")
+	fmt.Println("  --help               Show this help")
+	fmt.Println("\nExamples:")
+	fmt.Printf("  %s npm install\n", name)
+	fmt.Printf("  %s npm run build\n", name)
+	fmt.Printf("  %s run test --watch\n", name)
+	fmt.Printf("  %s yarn add express\n", name)
+	fmt.Printf("  %s node -v\n", name)
+	fmt.Printf("  %s npx create-react-app my-app\n", name)
+	fmt.Printf("  %s --service=api npm start\n", name)
+	fmt.Printf("  %s --project-dir ~/code/shop npm test\n", name)
+	fmt.Printf("  %s audit\n", name)
+	fmt.Printf("  %s --service=web outdated\n", name)
+}
+
+// findProjectDir returns the closest directory at or above dir with a fleet configuration
+func findProjectDir(dir string) (string, error) {
+	for {
+		for _, file := range configFiles {
+			if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+				return dir, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no fleet configuration file found in this directory or any parent directory")
+		}
+		dir = parent
+	}
+}
+
+// isInsideDir checks if path is dir or one of its subdirectories, and returns path relative to dir
+func isInsideDir(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// findServiceForDir returns the service whose folder holds workDir, when run from inside one
+func findServiceForDir(services []NodeService, projectDir, workDir string) *NodeService {
+	var found *NodeService
+	longest := -1
+	for i := range services {
+		if services[i].Folder == "" {
+			continue
+		}
+		folder := filepath.Join(projectDir, services[i].Folder)
+		// The deepest folder wins when service folders are nested
+		if _, ok := isInsideDir(folder, workDir); ok && len(folder) > longest {
+			found = &services[i]
+			longest = len(folder)
+		}
+	}
+	return found
+}
+
+// getContainerWorkDir returns the directory in the container that matches workDir on
+// the host, or base when workDir is outside the service folder
+func getContainerWorkDir(base, projectDir, folder, workDir string) string {
+	rel, ok := isInsideDir(filepath.Join(projectDir, folder), workDir)
+	if !ok {
+		return base
+	}
+	return path.Join(base, filepath.ToSlash(rel))
+}
+
+func loadConfig() (*Config, error) {
+	// Try different config file formats
+	for _, file := range configFiles {
+		if _, err := os.Stat(file); err == nil {
+			return loadConfigFile(file)
+		}
+	}
+	
+	return nil, fmt.Errorf("no fleet configuration file found")
+}
+
+func loadConfigFile(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	
+	// Handle different config formats
+	switch {
+	case strings.HasSuffix(filename, ".toml"):
+		if err := toml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse TOML: %w", err)
+		}
+	case strings.HasSuffix(filename, ".yaml") || strings.HasSuffix(filename, ".yml"):
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+	case strings.HasSuffix(filename, ".json"):
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", filename)
+	}
+	
+	return config, nil
+}
+
+func detectNodeServices(config *Config) []NodeService {
+	var services []NodeService
+	
+	for _, svc := range config.Services {
+		if strings.HasPrefix(svc.Runtime, "node") {
+			// Services with an nginx image build with Node.js in a -node container
+			composeService := svc.Name
+			if strings.Contains(strings.ToLower(svc.Image), "nginx") {
+				composeService = svc.Name + "-node"
+			}
+			nodeSvc := NodeService{
+				Name:           svc.Name,
+				ComposeService: composeService,
+				Framework:     svc.Framework,
+				Folder:        svc.Folder,
+				PackageManager: svc.PackageManager,
+			}
+			
+			// Auto-detect package manager if not specified
+			if nodeSvc.PackageManager == "" && nodeSvc.Folder != "" {
+				nodeSvc.PackageManager = detectPackageManager(nodeSvc.Folder)
+			}
+			
+			// Auto-detect framework if not specified
+			if nodeSvc.Framework == "" && nodeSvc.Folder != "" {
+				nodeSvc.Framework = detectFramework(nodeSvc.Folder)
+			}
+			
+			services = append(services, nodeSvc)
+		}
+	}
+	
+	return services
+}
+
+func detectPackageManager(folder string) string {
+	// Check for lock files
+	if _, err := os.Stat(filepath.Join(folder, "pnpm-lock.yaml")); err == nil {
+		return "pnpm"
+	}
+	if _, err := os.Stat(filepath.Join(folder, "yarn.lock")); err == nil {
+		return "yarn"
+	}
+	if _, err := os.Stat(filepath.Join(folder, "package-lock.json")); err == nil {
+		return "npm"
+	}
+	return "npm" // Default
+}
+
+func detectFramework(folder string) string {
+	// Read package.json to detect framework
+	packagePath := filepath.Join(folder, "package.json")
+	data, err := os.ReadFile(packagePath)
+	if err != nil {
+		return ""
+	}
+	
+	content := string(data)
+	
+	// Check for common frameworks
+	if strings.Contains(content, "\"next\"") {
+		return "nextjs"
+	}
+	if strings.Contains(content, "\"nuxt\"") {
+		return "nuxt"
+	}
+	if strings.Contains(content, "\"@angular/core\"") {
+		return "angular"
+	}
+	if strings.Contains(content, "\"express\"") {
+		return "express"
+	}
+	if strings.Contains(content, "\"react\"") {
+		return "react"
+	}
+	if strings.Contains(content, "\"vue\"") {
+		return "vue"
+	}
+	
+	return ""
+}
+
+func executeNPM(service *NodeService, args []string) {
+	dockerArgs := []string{
+		"exec",
+		"-w", service.WorkDir,
+	}
+	
+	// Add TTY if available and not just checking version/help
+	if isTerminal() && !isInfoCommand(args) {
+		dockerArgs = append(dockerArgs, "-it")
+	}
+	
+	dockerArgs = append(dockerArgs, service.ContainerName, "npm")
+	dockerArgs = append(dockerArgs, args...)
+	
+	runDockerCommand(dockerArgs)
+}
+
+func executeYarn(service *NodeService, args []string) {
+	dockerArgs := []string{
+		"exec",
+		"-w", service.WorkDir,
+	}
+	
+	// Add TTY if available
+	if isTerminal() && !isInfoCommand(args) {
+		dockerArgs = append(dockerArgs, "-it")
+	}
+	
+	dockerArgs = append(dockerArgs, service.ContainerName, "yarn")
+	dockerArgs = append(dockerArgs, args...)
+	
+	runDockerCommand(dockerArgs)
+}
+
+func executePNPM(service *NodeService, args []string) {
+	dockerArgs := []string{
+		"exec",
+		"-w", service.WorkDir,
+	}
+	
+	// Add TTY if available
+	if isTerminal() && !isInfoCommand(args) {
+		dockerArgs = append(dockerArgs, "-it")
+	}
+	
+	dockerArgs = append(dockerArgs, service.ContainerName, "pnpm")
+	dockerArgs = append(dockerArgs, args...)
+	
+	runDockerCommand(dockerArgs)
+}
+
+func executeNode(service *NodeService, args []string) {
+	dockerArgs := []string{
+		"exec",
+		"-w", service.WorkDir,
+	}
+	
+	// Add TTY if available
+	if isTerminal() && !isInfoCommand(args) {
+		dockerArgs = append(dockerArgs, "-it")
+	}
+	
+	dockerArgs = append(dockerArgs, service.ContainerName, "node")
+	dockerArgs = append(dockerArgs, args...)
+	
+	runDockerCommand(dockerArgs)
+}
+
+func executeNPX(service *NodeService, args []string) {
+	dockerArgs := []string{
+		"exec",
+		"-w", service.WorkDir,
+	}
+	
+	// Add TTY if available
+	if isTerminal() && !isInfoCommand(args) {
+		dockerArgs = append(dockerArgs, "-it")
+	}
+	
+	dockerArgs = append(dockerArgs, service.ContainerName, "npx")
+	dockerArgs = append(dockerArgs, args...)
+	
+	runDockerCommand(dockerArgs)
+}
+
+// isInfoCommand checks if the command is just for information (doesn't need TTY)
+func isInfoCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	infoCommands := []string{"--version", "-V", "-v", "--help", "-h", "list", "about"}
+	for _, cmd := range infoCommands {
+		if args[0] == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+func runDockerCommand(args []string) {
+	// Stop docker along with us instead of leaving it running when interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = 10 * time.Second
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Fprintf(os.Stderr, "Error running docker command: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func isTerminal() bool {
+	fileInfo, _ := os.Stdin.Stat()
+	return (fileInfo.Mode() & os.ModeCharDevice) != 0
+}
\ No newline at end of file
@@ -0,0 +1,114 @@
+package nodecli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// findPackageJSON returns the package.json a package manager run in dir uses: the
+// closest one at or above dir, without leaving the service folder
+func findPackageJSON(serviceDir, dir string) (string, error) {
+	if _, ok := isInsideDir(serviceDir, dir); !ok {
+		dir = serviceDir
+	}
+	for {
+		path := filepath.Join(dir, "package.json")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		if dir == serviceDir || filepath.Dir(dir) == dir {
+			return "", fmt.Errorf("no package.json in %s", serviceDir)
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// readPackageScripts returns the scripts of a package.json
+func readPackageScripts(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return pkg.Scripts, nil
+}
+
+// getRunCommand returns the package manager command running a script. npm passes
+// arguments to the script only after --.
+func getRunCommand(packageManager, script string, args []string) []string {
+	command := []string{packageManager, "run", script}
+	if packageManager != "yarn" && packageManager != "pnpm" {
+		command[0] = "npm"
+		if len(args) > 0 {
+			command = append(command, "--")
+		}
+	}
+	return append(command, args...)
+}
+
+// printScripts prints the scripts of a service with the command each one runs. With
+// namesOnly it prints one name per line, for shell completion.
+func printScripts(service *NodeService, path string, scripts map[string]string, namesOnly bool) {
+	names := sortedKeys(scripts)
+	if namesOnly {
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return
+	}
+
+	if len(names) == 0 {
+		fmt.Printf("%s has no scripts\n", path)
+		return
+	}
+	fmt.Printf("Scripts of %s (%s, run with %s):\n\n", service.Name, path, service.PackageManager)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\t%s\n", name, scripts[name])
+	}
+	w.Flush()
+}
+
+// isScriptListing checks if run was asked for the scripts instead of running one
+func isScriptListing(args []string) bool {
+	return len(args) == 0 || args[0] == "--list" || args[0] == "--complete"
+}
+
+// findScript checks that the package.json of a service has a script, and returns its
+// scripts
+func findScript(path, script string) (map[string]string, error) {
+	scripts, err := readPackageScripts(path)
+	if err != nil {
+		return nil, err
+	}
+	if script != "" {
+		if _, ok := scripts[script]; !ok {
+			return nil, fmt.Errorf("script '%s' not found in %s, available: %s", script, path, strings.Join(sortedKeys(scripts), ", "))
+		}
+	}
+	return scripts, nil
+}
+
+// runScript runs a script of package.json with the package manager of the service
+func runScript(service *NodeService, script string, args []string) {
+	dockerArgs := []string{
+		"exec",
+		"-w", service.WorkDir,
+	}
+	if isTerminal() {
+		dockerArgs = append(dockerArgs, "-it")
+	}
+	dockerArgs = append(dockerArgs, service.ContainerName)
+	dockerArgs = append(dockerArgs, getRunCommand(service.PackageManager, script, args)...)
+
+	runDockerCommand(dockerArgs)
+}
@@ -0,0 +1,241 @@
+package phpcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fleet/fleet/internal/containers"
+	"github.com/fleet/fleet/internal/dbdump"
+)
+
+// DatabaseConnection is the database a PHP service connects to
+type DatabaseConnection struct {
+	Engine   string // mysql, mariadb or postgres
+	Host     string // Compose service of the database, e.g. mysql-80
+	Database string
+	User     string
+	Password string
+}
+
+// readContainerEnv returns the environment of a running container, which holds the
+// variables of the generated compose file and of its env files
+func readContainerEnv(container string) (map[string]string, error) {
+	cmd, stop := newDockerCommand("inspect", "--format", "{{json .Config.Env}}", container)
+	defer stop()
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s: %w", container, err)
+	}
+	var lines []string
+	if err := json.Unmarshal(output, &lines); err != nil {
+		return nil, fmt.Errorf("failed to read the environment of %s: %w", container, err)
+	}
+	env := make(map[string]string, len(lines))
+	for _, line := range lines {
+		if key, value, found := strings.Cut(line, "="); found {
+			env[key] = value
+		}
+	}
+	return env, nil
+}
+
+// getDatabaseEngine returns the engine of a database from its compose service, like
+// mariadb-11, or from the connection name the framework uses
+func getDatabaseEngine(connection, host string) string {
+	for _, engine := range []string{"mariadb", "mysql", "postgres"} {
+		if strings.HasPrefix(host, engine+"-") {
+			return engine
+		}
+	}
+	switch connection {
+	case "mysql", "mysql2":
+		return "mysql"
+	case "mariadb":
+		return "mariadb"
+	case "pgsql", "postgres", "postgresql":
+		return "postgres"
+	}
+	return ""
+}
+
+// getDatabaseConnection returns the database of a service from the environment Fleet
+// generated for it: the DB_ variables, or DATABASE_URL when its env_style renamed them
+func getDatabaseConnection(env map[string]string) (*DatabaseConnection, error) {
+	db := &DatabaseConnection{
+		Host:     env["DB_HOST"],
+		Database: env["DB_DATABASE"],
+		User:     env["DB_USERNAME"],
+		Password: env["DB_PASSWORD"],
+	}
+	connection := env["DB_CONNECTION"]
+	if db.Host == "" && env["DATABASE_URL"] != "" {
+		u, err := url.Parse(env["DATABASE_URL"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid DATABASE_URL: %w", err)
+		}
+		connection = u.Scheme
+		db.Host = u.Hostname()
+		db.Database = strings.TrimPrefix(u.Path, "/")
+		db.User = u.User.Username()
+		db.Password, _ = u.User.Password()
+	}
+	if db.Host == "" {
+		return nil, fmt.Errorf("the service has no database, set database in the fleet configuration")
+	}
+
+	db.Engine = getDatabaseEngine(connection, db.Host)
+	if db.Engine == "" {
+		return nil, fmt.Errorf("unsupported database %s, db commands work with MySQL, MariaDB and PostgreSQL", db.Host)
+	}
+	return db, nil
+}
+
+// getDatabaseExecArgs returns the docker arguments running a client of the database in
+// its container, logged in as the user of the service
+func getDatabaseExecArgs(container string, db *DatabaseConnection, flags []string, command ...string) []string {
+	args := append([]string{"exec"}, flags...)
+	if db.Engine == "postgres" {
+		args = append(args, "-e", "PGPASSWORD="+db.Password)
+	} else {
+		args = append(args, "-e", "MYSQL_PWD="+db.Password)
+	}
+	return append(append(args, container), command...)
+}
+
+// getDatabaseShellCommand returns the interactive client of a database
+func getDatabaseShellCommand(db *DatabaseConnection, args []string) []string {
+	var command []string
+	switch db.Engine {
+	case "postgres":
+		command = []string{"psql", "-U", db.User, "-d", db.Database}
+	case "mariadb":
+		command = []string{"mariadb", "-u", db.User, db.Database}
+	default:
+		command = []string{"mysql", "-u", db.User, db.Database}
+	}
+	return append(command, args...)
+}
+
+// getDatabaseDumpCommand returns the command writing a database as SQL to its output
+func getDatabaseDumpCommand(db *DatabaseConnection) []string {
+	switch db.Engine {
+	case "postgres":
+		return []string{"pg_dump", "-U", db.User, "--no-owner", "--clean", "--if-exists", db.Database}
+	case "mariadb":
+		return []string{"mariadb-dump", "-u", db.User, "--single-transaction", "--routines", "--triggers", db.Database}
+	default:
+		return []string{"mysqldump", "-u", db.User, "--single-transaction", "--routines", "--triggers", "--no-tablespaces", db.Database}
+	}
+}
+
+// getDatabaseRestoreCommand returns the command running the SQL of its input, stopping
+// at the first error
+func getDatabaseRestoreCommand(db *DatabaseConnection) []string {
+	if db.Engine == "postgres" {
+		return []string{"psql", "-U", db.User, "-d", db.Database, "-q", "-v", "ON_ERROR_STOP=1"}
+	}
+	return getDatabaseShellCommand(db, nil)
+}
+
+// getDefaultDumpFile returns the file db:dump writes to when none is given
+func getDefaultDumpFile(db *DatabaseConnection, now time.Time) string {
+	return fmt.Sprintf("%s-%s.sql", db.Database, now.Format("20060102-150405"))
+}
+
+// findServiceDatabase returns the database of a service and the container running it.
+// Services with an nginx image get the database variables on the nginx container, not
+// on their PHP container.
+func findServiceDatabase(service *PHPService) (*DatabaseConnection, string, error) {
+	env, err := readContainerEnv(service.ContainerName)
+	if err != nil {
+		return nil, "", err
+	}
+	if env["DB_HOST"] == "" && env["DATABASE_URL"] == "" && service.ComposeService != service.Name {
+		container, err := containers.FindServiceContainer(service.Name)
+		if err != nil {
+			return nil, "", err
+		}
+		if env, err = readContainerEnv(container); err != nil {
+			return nil, "", err
+		}
+	}
+	db, err := getDatabaseConnection(env)
+	if err != nil {
+		return nil, "", fmt.Errorf("service %s: %w", service.Name, err)
+	}
+	container, err := containers.FindServiceContainer(db.Host)
+	if err != nil {
+		return nil, "", err
+	}
+	return db, container, nil
+}
+
+// getHostFile returns a file given on the command line, relative to the directory
+// fleet-php was called from
+func getHostFile(workDir, file string) string {
+	if file == "-" || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(workDir, file)
+}
+
+// runDatabaseCommand runs db:shell, db:dump or db:restore against the database of a
+// service. Files are relative to workDir, the directory fleet-php was called from.
+func runDatabaseCommand(service *PHPService, command string, args []string, workDir string) {
+	db, container, err := findServiceDatabase(service)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch command {
+	case "db:shell":
+		flags := []string{"-i"}
+		if isTerminal() {
+			flags = []string{"-it"}
+		}
+		runDockerCommand(getDatabaseExecArgs(container, db, flags, getDatabaseShellCommand(db, args)...))
+	case "db:dump":
+		file := getDefaultDumpFile(db, time.Now())
+		if len(args) > 0 {
+			file = args[0]
+		}
+		if err := dumpDatabase(container, db, getHostFile(workDir, file)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error dumping %s: %v\n", db.Database, err)
+			os.Exit(1)
+		}
+		if file != "-" {
+			fmt.Fprintf(os.Stderr, "Dumped %s to %s\n", db.Database, file)
+		}
+	case "db:restore":
+		if len(args) != 1 {
+			fmt.Fprintf(os.Stderr, "Usage: db:restore <file.sql|file.sql.gz>\n")
+			os.Exit(1)
+		}
+		if err := restoreDatabase(container, db, getHostFile(workDir, args[0])); err != nil {
+			fmt.Fprintf(os.Stderr, "Error restoring %s: %v\n", db.Database, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Restored %s from %s\n", db.Database, args[0])
+	}
+}
+
+// dumpDatabase writes a database to a file, gzipped when it ends with .gz, or to the
+// output with -
+func dumpDatabase(container string, db *DatabaseConnection, file string) error {
+	cmd, stop := newDockerCommand(getDatabaseExecArgs(container, db, nil, getDatabaseDumpCommand(db)...)...)
+	defer stop()
+	return dbdump.Dump(cmd, file)
+}
+
+// restoreDatabase runs the SQL of a file, gunzipped when it ends with .gz, in a database
+func restoreDatabase(container string, db *DatabaseConnection, file string) error {
+	cmd, stop := newDockerCommand(getDatabaseExecArgs(container, db, []string{"-i"}, getDatabaseRestoreCommand(db)...)...)
+	defer stop()
+	return dbdump.Restore(cmd, file)
+}
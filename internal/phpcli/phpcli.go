@@ -0,0 +1,465 @@
+// Package phpcli runs the PHP tooling of the services of a Fleet project. It is both
+// fleet php and the standalone fleet-php binary.
+package phpcli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+	
+	"github.com/BurntSushi/toml"
+	"github.com/fleet/fleet/internal/containers"
+	"gopkg.in/yaml.v3"
+)
+
+const version = "1.0.0"
+
+// configFiles are the names of the fleet configuration, in order of preference
+var configFiles = []string{"fleet.toml", "fleet.yaml", "fleet.yml", "fleet.json"}
+
+// Config represents fleet configuration (minimal subset needed)
+type Config struct {
+	Project  string    `toml:"project" yaml:"project" json:"project"`
+	Services []Service `toml:"services" yaml:"services" json:"services"`
+}
+
+// Service represents a service configuration (minimal subset)
+type Service struct {
+	Name      string `toml:"name" yaml:"name" json:"name"`
+	Runtime   string `toml:"runtime" yaml:"runtime" json:"runtime"`
+	Framework string `toml:"framework" yaml:"framework" json:"framework"`
+	Folder    string `toml:"folder" yaml:"folder" json:"folder"`
+	Image     string `toml:"image" yaml:"image" json:"image"`
+}
+
+// PHPService represents a detected PHP service
+type PHPService struct {
+	Name           string
+	ComposeService string
+	ContainerName  string
+	Framework      string
+	Folder         string
+	WorkDir        string
+}
+
+// Run runs a command with the arguments that follow it. name is how the user called it,
+// fleet-php or fleet php, so the help shows the same command.
+func Run(name string, arguments []string) {
+	// Parse flags
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	serviceFlag := flags.String("service", "", "Specify which service to use")
+	projectDirFlag := flags.String("project-dir", "", "Directory containing the fleet configuration")
+	versionFlag := flags.Bool("version", false, "Show version")
+	helpFlag := flags.Bool("help", false, "Show help")
+	flags.Parse(arguments)
+
+	if *versionFlag {
+		fmt.Printf("fleet-php v%s\n", version)
+		os.Exit(0)
+	}
+
+	if *helpFlag || flags.NArg() == 0 {
+		printUsage(name)
+		os.Exit(0)
+	}
+
+	// Get command and args
+	command := flags.Arg(0)
+	args := flags.Args()[1:]
+
+	// Find the project like git finds its repository, so commands work from subfolders
+	workDir, _ := os.Getwd()
+	projectDir := *projectDirFlag
+	if projectDir == "" {
+		dir, err := findProjectDir(workDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading fleet configuration: %v\n", err)
+			os.Exit(1)
+		}
+		projectDir = dir
+	}
+	projectDir, _ = filepath.Abs(projectDir)
+	if err := os.Chdir(projectDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening project directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Load configuration
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading fleet configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Find PHP services
+	phpServices := detectPHPServices(config)
+	if len(phpServices) == 0 {
+		fmt.Fprintf(os.Stderr, "No PHP services found in fleet configuration\n")
+		os.Exit(1)
+	}
+
+	// Select service
+	var selectedService *PHPService
+	if *serviceFlag != "" {
+		for _, svc := range phpServices {
+			if svc.Name == *serviceFlag {
+				selectedService = &svc
+				break
+			}
+		}
+		if selectedService == nil {
+			fmt.Fprintf(os.Stderr, "Service '%s' not found or is not a PHP service\n", *serviceFlag)
+			os.Exit(1)
+		}
+	} else if svc := findServiceForDir(phpServices, projectDir, workDir); svc != nil {
+		selectedService = svc
+	} else {
+		selectedService = &phpServices[0]
+		if len(phpServices) > 1 {
+			fmt.Printf("Multiple PHP services found. Using '%s'. Use --service flag to specify.\n", selectedService.Name)
+		}
+	}
+	selectedService.WorkDir = getContainerWorkDir("/var/www/html", projectDir, selectedService.Folder, workDir)
+	selectedService.ContainerName, err = containers.FindServiceContainer(selectedService.ComposeService)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error finding the container of %s: %v\n", selectedService.Name, err)
+		os.Exit(1)
+	}
+
+	// Execute command
+	switch command {
+	case "composer":
+		executeComposer(selectedService, args)
+	case "php":
+		executePHP(selectedService, args)
+	case "artisan":
+		if !isLaravelService(selectedService) {
+			fmt.Fprintf(os.Stderr, "artisan command is only available for Laravel/Lumen projects\n")
+			os.Exit(1)
+		}
+		executeArtisan(selectedService, args)
+	case "console":
+		if !isSymfonyService(selectedService) {
+			fmt.Fprintf(os.Stderr, "console command is only available for Symfony projects\n")
+			os.Exit(1)
+		}
+		executeConsole(selectedService, args)
+	case "test":
+		executeTests(selectedService, args)
+	case "db:shell", "db:dump", "db:restore":
+		runDatabaseCommand(selectedService, command, args, workDir)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
+		printUsage(name)
+		os.Exit(1)
+	}
+}
+
+func printUsage(name string) {
+	fmt.Printf("%s - PHP CLI tool for Fleet\n", name)
+	fmt.Printf("Version: %s\n\n", version)
+	fmt.Printf("Usage: %s [--service=<name>] <command> [args...]\n", name)
+	fmt.Println("\nCommands:")
+	fmt.Println("  composer [args...]   Run Composer commands")
+	fmt.Println("  php [args...]        Run PHP scripts")
+	fmt.Println("  artisan [args...]    Run Laravel Artisan commands (Laravel/Lumen only)")
+	fmt.Println("  console [args...]    Run Symfony Console commands (Symfony only)")
+	fmt.Println("  test [args...]       Run the tests: artisan test, Pest or PHPUnit")
+	fmt.Println("  db:shell [args...]   Open mysql or psql in the database of the service")
+	fmt.Println("  db:dump [file]       Dump the database to a file, .gz to compress it, - for the output")
+	fmt.Println("  db:restore <file>    Run the SQL of a file, .sql or .sql.gz, in the database")
+	fmt.Println("\nFlags:")
+	fmt.Println("  --service=<name>     Specify which service to use (for multi-service projects)")
+	fmt.Println("  --project-dir=<dir>  Directory containing fleet.toml (default: closest parent with one)")
+	fmt.Println("  --version            Show version")
+	fmt.Println("  --help               Show this help")
+	fmt.Println("\nExamples:")
+	fmt.Printf("  %s composer install\n", name)
+	fmt.Printf("  %s composer require laravel/sanctum\n", name)
+	fmt.Printf("  %s php -v\n", name)
+	fmt.Printf("  %s artisan migrate\n", name)
+	fmt.Printf("  %s test --filter UserTest\n", name)
+	fmt.Printf("  %s --service=api composer update\n", name)
+	fmt.Printf("  %s db:dump backup.sql.gz\n", name)
+	fmt.Printf("  %s --project-dir ~/code/shop artisan migrate\n", name)
+}
+
+// findProjectDir returns the closest directory at or above dir with a fleet configuration
+func findProjectDir(dir string) (string, error) {
+	for {
+		for _, file := range configFiles {
+			if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+				return dir, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no fleet configuration file found in this directory or any parent directory")
+		}
+		dir = parent
+	}
+}
+
+// isInsideDir checks if path is dir or one of its subdirectories, and returns path relative to dir
+func isInsideDir(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// findServiceForDir returns the service whose folder holds workDir, when run from inside one
+func findServiceForDir(services []PHPService, projectDir, workDir string) *PHPService {
+	var found *PHPService
+	longest := -1
+	for i := range services {
+		if services[i].Folder == "" {
+			continue
+		}
+		folder := filepath.Join(projectDir, services[i].Folder)
+		// The deepest folder wins when service folders are nested
+		if _, ok := isInsideDir(folder, workDir); ok && len(folder) > longest {
+			found = &services[i]
+			longest = len(folder)
+		}
+	}
+	return found
+}
+
+// getContainerWorkDir returns the directory in the container that matches workDir on
+// the host, or base when workDir is outside the service folder
+func getContainerWorkDir(base, projectDir, folder, workDir string) string {
+	rel, ok := isInsideDir(filepath.Join(projectDir, folder), workDir)
+	if !ok {
+		return base
+	}
+	return path.Join(base, filepath.ToSlash(rel))
+}
+
+func loadConfig() (*Config, error) {
+	// Try different config file formats
+	for _, file := range configFiles {
+		if _, err := os.Stat(file); err == nil {
+			return loadConfigFile(file)
+		}
+	}
+	
+	return nil, fmt.Errorf("no fleet configuration file found")
+}
+
+func loadConfigFile(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	
+	// Handle different config formats
+	switch {
+	case strings.HasSuffix(filename, ".toml"):
+		if err := toml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse TOML: %w", err)
+		}
+	case strings.HasSuffix(filename, ".yaml") || strings.HasSuffix(filename, ".yml"):
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+	case strings.HasSuffix(filename, ".json"):
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", filename)
+	}
+	
+	return config, nil
+}
+
+func detectPHPServices(config *Config) []PHPService {
+	var services []PHPService
+	
+	for _, svc := range config.Services {
+		if strings.HasPrefix(svc.Runtime, "php") {
+			// Services with an nginx image run PHP-FPM in a -php sidecar
+			composeService := svc.Name
+			if strings.Contains(strings.ToLower(svc.Image), "nginx") {
+				composeService = svc.Name + "-php"
+			}
+			phpSvc := PHPService{
+				Name:           svc.Name,
+				ComposeService: composeService,
+				Framework:      svc.Framework,
+				Folder:         svc.Folder,
+			}
+			
+			// Auto-detect framework if not specified
+			if phpSvc.Framework == "" && phpSvc.Folder != "" {
+				phpSvc.Framework = detectFramework(phpSvc.Folder)
+			}
+			
+			services = append(services, phpSvc)
+		}
+	}
+	
+	return services
+}
+
+func detectFramework(folder string) string {
+	// Check for Laravel/Lumen
+	artisanPath := filepath.Join(folder, "artisan")
+	if _, err := os.Stat(artisanPath); err == nil {
+		composerPath := filepath.Join(folder, "composer.json")
+		if data, err := os.ReadFile(composerPath); err == nil {
+			if strings.Contains(string(data), "laravel/lumen-framework") {
+				return "lumen"
+			}
+			if strings.Contains(string(data), "laravel/framework") {
+				return "laravel"
+			}
+		}
+	}
+	
+	// Check for Symfony
+	consolePath := filepath.Join(folder, "bin", "console")
+	if _, err := os.Stat(consolePath); err == nil {
+		return "symfony"
+	}
+	
+	return ""
+}
+
+func isLaravelService(service *PHPService) bool {
+	return service.Framework == "laravel" || service.Framework == "lumen"
+}
+
+func isSymfonyService(service *PHPService) bool {
+	return service.Framework == "symfony"
+}
+
+func executeComposer(service *PHPService, args []string) {
+	dockerArgs := []string{
+		"exec",
+		"-w", "/var/www/html",
+	}
+	
+	// Add TTY if available and not just checking version/help
+	if isTerminal() && !isInfoCommand(args) {
+		dockerArgs = append(dockerArgs, "-it")
+	}
+	
+	dockerArgs = append(dockerArgs, service.ContainerName, "composer")
+	dockerArgs = append(dockerArgs, args...)
+	
+	runDockerCommand(dockerArgs)
+}
+
+// isInfoCommand checks if the command is just for information (doesn't need TTY)
+func isInfoCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	infoCommands := []string{"--version", "-V", "-v", "--help", "-h", "list", "about", "-i", "--info"}
+	for _, cmd := range infoCommands {
+		if args[0] == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+func executePHP(service *PHPService, args []string) {
+	// Scripts are run relative to the directory fleet-php was called from
+	dockerArgs := []string{
+		"exec",
+		"-w", service.WorkDir,
+	}
+	
+	// Add TTY if available and not just checking version/info
+	if isTerminal() && !isInfoCommand(args) {
+		dockerArgs = append(dockerArgs, "-it")
+	}
+	
+	dockerArgs = append(dockerArgs, service.ContainerName, "php")
+	dockerArgs = append(dockerArgs, args...)
+	
+	runDockerCommand(dockerArgs)
+}
+
+func executeArtisan(service *PHPService, args []string) {
+	dockerArgs := []string{
+		"exec",
+		"-w", "/var/www/html",
+	}
+	
+	// Add TTY if available
+	if isTerminal() {
+		dockerArgs = append(dockerArgs, "-it")
+	}
+	
+	dockerArgs = append(dockerArgs, service.ContainerName, "php", "artisan")
+	dockerArgs = append(dockerArgs, args...)
+	
+	runDockerCommand(dockerArgs)
+}
+
+func executeConsole(service *PHPService, args []string) {
+	dockerArgs := []string{
+		"exec",
+		"-w", "/var/www/html",
+	}
+	
+	// Add TTY if available
+	if isTerminal() {
+		dockerArgs = append(dockerArgs, "-it")
+	}
+	
+	dockerArgs = append(dockerArgs, service.ContainerName, "php", "bin/console")
+	dockerArgs = append(dockerArgs, args...)
+	
+	runDockerCommand(dockerArgs)
+}
+
+// newDockerCommand returns a docker command that is stopped along with us instead of
+// left running when we are interrupted. Call stop once the command ran.
+func newDockerCommand(args ...string) (cmd *exec.Cmd, stop context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	cmd = exec.CommandContext(ctx, "docker", args...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = 10 * time.Second
+	return cmd, stop
+}
+
+func runDockerCommand(args []string) {
+	cmd, stop := newDockerCommand(args...)
+	defer stop()
+
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Fprintf(os.Stderr, "Error running docker command: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func isTerminal() bool {
+	fileInfo, _ := os.Stdin.Stat()
+	return (fileInfo.Mode() & os.ModeCharDevice) != 0
+}
\ No newline at end of file
@@ -0,0 +1,61 @@
+package phpcli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// composerRequires reports whether the composer.json of a service requires a package,
+// in require or require-dev
+func composerRequires(service *PHPService, pkg string) bool {
+	data, err := os.ReadFile(filepath.Join(service.Folder, "composer.json"))
+	if err != nil {
+		return false
+	}
+	var composer struct {
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if err := json.Unmarshal(data, &composer); err != nil {
+		return false
+	}
+	_, required := composer.Require[pkg]
+	_, requiredDev := composer.RequireDev[pkg]
+	return required || requiredDev
+}
+
+// getTestCommand returns the command running the tests of a service from the root of its
+// code. Pest runs from vendor/bin whatever the framework, so its own flags work. Symfony
+// projects with the PHPUnit bridge have bin/phpunit, the others run PHPUnit from vendor/bin.
+func getTestCommand(service *PHPService) []string {
+	switch {
+	case composerRequires(service, "pestphp/pest"):
+		return []string{"php", "vendor/bin/pest"}
+	case service.Framework == "laravel":
+		return []string{"php", "artisan", "test"}
+	}
+	if _, err := os.Stat(filepath.Join(service.Folder, "bin", "phpunit")); err == nil {
+		return []string{"php", "bin/phpunit"}
+	}
+	return []string{"php", "vendor/bin/phpunit"}
+}
+
+// executeTests runs the test runner of a service, passing filters and flags through
+func executeTests(service *PHPService, args []string) {
+	dockerArgs := []string{
+		"exec",
+		"-w", "/var/www/html",
+	}
+
+	// Runners only print colors to a terminal
+	if isTerminal() {
+		dockerArgs = append(dockerArgs, "-it")
+	}
+
+	dockerArgs = append(dockerArgs, service.ContainerName)
+	dockerArgs = append(dockerArgs, getTestCommand(service)...)
+	dockerArgs = append(dockerArgs, args...)
+
+	runDockerCommand(dockerArgs)
+}
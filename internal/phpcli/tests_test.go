@@ -0,0 +1,60 @@
+package phpcli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TestsTestSuite struct {
+	suite.Suite
+	service *PHPService
+}
+
+func (suite *TestsTestSuite) SetupTest() {
+	suite.service = &PHPService{Name: "app", Folder: suite.T().TempDir()}
+}
+
+// writeComposer writes the composer.json of the service
+func (suite *TestsTestSuite) writeComposer(content string) {
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.service.Folder, "composer.json"), []byte(content), 0644))
+}
+
+func (suite *TestsTestSuite) TestComposerRequires() {
+	suite.False(composerRequires(suite.service, "pestphp/pest"), "No composer.json")
+
+	suite.writeComposer(`{
+		"require": {"php": "^8.2", "laravel/framework": "^11.0"},
+		"require-dev": {"pestphp/pest": "^2.0"},
+		"suggest": {"phpunit/phpunit": "Runs the tests"}
+	}`)
+	suite.True(composerRequires(suite.service, "laravel/framework"))
+	suite.True(composerRequires(suite.service, "pestphp/pest"))
+	suite.False(composerRequires(suite.service, "phpunit/phpunit"), "Only require and require-dev count")
+	suite.False(composerRequires(suite.service, "pestphp"))
+
+	suite.writeComposer(`{"require": `)
+	suite.False(composerRequires(suite.service, "laravel/framework"), "Invalid composer.json")
+}
+
+func (suite *TestsTestSuite) TestGetTestCommand() {
+	suite.Equal([]string{"php", "vendor/bin/phpunit"}, getTestCommand(suite.service))
+
+	suite.service.Framework = "laravel"
+	suite.Equal([]string{"php", "artisan", "test"}, getTestCommand(suite.service))
+
+	suite.writeComposer(`{"require-dev": {"pestphp/pest": "^2.0"}}`)
+	suite.Equal([]string{"php", "vendor/bin/pest"}, getTestCommand(suite.service), "Pest wins over artisan test")
+
+	suite.service.Framework = "symfony"
+	suite.writeComposer(`{"require-dev": {"symfony/phpunit-bridge": "^7.0"}}`)
+	suite.Require().NoError(os.MkdirAll(filepath.Join(suite.service.Folder, "bin"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.service.Folder, "bin", "phpunit"), []byte("#!/usr/bin/env php\n"), 0755))
+	suite.Equal([]string{"php", "bin/phpunit"}, getTestCommand(suite.service))
+}
+
+func TestTestsSuite(t *testing.T) {
+	suite.Run(t, new(TestsTestSuite))
+}
@@ -0,0 +1,250 @@
+// Package ui shows the progress of long operations, like image pulls or generating
+// certificates. On a terminal, the running step is redrawn in place with a spinner and
+// its elapsed time. In CI logs, or when output is piped, each step prints a line when it
+// starts and one when it ends. In quiet mode nothing is printed unless a step fails.
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mode is how steps are shown
+type Mode int
+
+const (
+	// Spinner redraws the running step in place
+	Spinner Mode = iota
+	// Plain prints one line when a step starts and one when it ends
+	Plain
+	// Quiet only prints the output of failed steps
+	Quiet
+)
+
+// spinnerFrames are drawn in turn in front of the running step
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinnerInterval is how often the running step is redrawn
+const spinnerInterval = 100 * time.Millisecond
+
+// Progress shows steps on a writer, one at a time
+type Progress struct {
+	out  io.Writer
+	mode Mode
+	// now returns the current time, tests replace it
+	now func() time.Time
+}
+
+// New returns a Progress writing to out
+func New(out io.Writer, mode Mode) *Progress {
+	return &Progress{out: out, mode: mode, now: time.Now}
+}
+
+// Step is a long operation in progress. End it with Done, Fail or Skip.
+type Step struct {
+	progress *Progress
+	title    string
+	started  time.Time
+
+	mu      sync.Mutex
+	details []string     // Lines shown under the title while the step runs
+	output  bytes.Buffer // Output of commands, shown when the step fails
+	drawn   int          // Lines of the last redraw
+	frame   int
+	ended   bool
+
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// Start shows that a step started and returns it
+func (p *Progress) Start(title string) *Step {
+	step := &Step{progress: p, title: title, started: p.now()}
+	switch p.mode {
+	case Plain:
+		fmt.Fprintf(p.out, "⏳ %s...\n", title)
+	case Spinner:
+		step.stop = make(chan struct{})
+		step.stopped = make(chan struct{})
+		step.draw()
+		go step.spin()
+	}
+	return step
+}
+
+// Run runs fn as a step, ending it with the error fn returns
+func (p *Progress) Run(title string, fn func(step *Step) error) error {
+	step := p.Start(title)
+	err := fn(step)
+	if err != nil {
+		step.Fail(err)
+	} else {
+		step.Done()
+	}
+	return err
+}
+
+// spin redraws the step until it ends
+func (s *Step) spin() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.draw()
+		}
+	}
+}
+
+// draw redraws the step and its details in place
+func (s *Step) draw() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drawLocked()
+}
+
+func (s *Step) drawLocked() {
+	if s.ended {
+		return
+	}
+	s.erase()
+	frame := spinnerFrames[s.frame%len(spinnerFrames)]
+	s.frame++
+	lines := append([]string{fmt.Sprintf("%s %s (%s)", frame, s.title, formatElapsed(s.progress.now().Sub(s.started)))}, s.details...)
+	for _, line := range lines {
+		fmt.Fprintf(s.progress.out, "%s\n", line)
+	}
+	s.drawn = len(lines)
+}
+
+// erase clears the lines of the last redraw
+func (s *Step) erase() {
+	if s.drawn > 0 {
+		fmt.Fprintf(s.progress.out, "\033[%dA\r\033[J", s.drawn)
+		s.drawn = 0
+	}
+}
+
+// Update replaces the lines shown under the title while the step runs, like the
+// progress bars of pulls. They are only shown by the spinner.
+func (s *Step) Update(details ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.details = details
+}
+
+// Log prints a line that stays, like an image that finished pulling
+func (s *Step) Log(format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.progress.mode == Quiet || s.ended {
+		return
+	}
+	s.erase()
+	fmt.Fprintf(s.progress.out, format, args...)
+	if s.progress.mode == Spinner {
+		s.drawLocked()
+	}
+}
+
+// Writer returns where commands run by the step write their output. With Plain, it is
+// printed as it comes. Otherwise it is kept, the spinner shows its last line and a
+// failed step prints it all.
+func (s *Step) Writer() io.Writer {
+	return stepWriter{s}
+}
+
+type stepWriter struct {
+	step *Step
+}
+
+func (w stepWriter) Write(p []byte) (int, error) {
+	s := w.step
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.progress.mode == Plain {
+		return s.progress.out.Write(p)
+	}
+	s.output.Write(p)
+	if line := lastLine(s.output.String()); line != "" {
+		s.details = []string{"  " + line}
+	}
+	return len(p), nil
+}
+
+// lastLine returns the last line of output with text, without the carriage returns
+// progress bars redraw themselves with
+func lastLine(output string) string {
+	lines := strings.FieldsFunc(output, func(r rune) bool { return r == '\n' || r == '\r' })
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// Done shows that the step succeeded
+func (s *Step) Done() {
+	s.end("✅", "")
+}
+
+// Fail shows that the step failed, with the output of its commands
+func (s *Step) Fail(err error) {
+	s.end("❌", fmt.Sprintf(": %v", err))
+}
+
+// Skip shows that the step had nothing to do
+func (s *Step) Skip(reason string) {
+	s.end("⏭️ ", fmt.Sprintf(": %s", reason))
+}
+
+// end stops the spinner and prints how the step ended
+func (s *Step) end(mark, suffix string) {
+	if s.stop != nil {
+		s.stopOnce.Do(func() {
+			close(s.stop)
+			<-s.stopped
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.erase()
+	s.ended = true
+
+	failed := mark == "❌"
+	if failed && s.progress.mode != Plain && s.output.Len() > 0 {
+		s.progress.out.Write(s.output.Bytes())
+		if !bytes.HasSuffix(s.output.Bytes(), []byte("\n")) {
+			fmt.Fprintln(s.progress.out)
+		}
+	}
+	if s.progress.mode == Quiet && !failed {
+		return
+	}
+	fmt.Fprintf(s.progress.out, "%s %s (%s)%s\n", mark, s.title, formatElapsed(s.progress.now().Sub(s.started)), suffix)
+}
+
+// formatElapsed formats how long a step took, like 850ms, 4.2s or 1m05s
+func formatElapsed(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
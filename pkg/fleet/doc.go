@@ -0,0 +1,26 @@
+// Package fleet is the API of Fleet, for tools that read fleet.toml or preview its
+// compose config without running the fleet command, like editor plugins.
+//
+// Parse and Load decode a config and Validate checks it. Generate returns the compose
+// config of a validated config and the plan of the files its services mount, like the
+// nginx config and scripts. None of them change the working directory or write files:
+// the fleet command writes the plan with the compose files.
+//
+//	config, err := fleet.Load("shop/fleet.toml")
+//	if err != nil {
+//		return err
+//	}
+//	compose, plan := fleet.Generate(config, "shop")
+//	out, err := yaml.Marshal(compose)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("%s", out)
+//	for _, file := range plan.Files() {
+//		fmt.Println(file.Path)
+//	}
+//
+// Generate reads the folders of services in the project directory it is given, to
+// detect frameworks and package managers, and keeps the certificates and image lock
+// found in its .fleet directory.
+package fleet
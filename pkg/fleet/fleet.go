@@ -0,0 +1,71 @@
+package fleet
+
+import "github.com/fleet/fleet/internal/fleetcli"
+
+// DefaultProject is the project name of configs without one
+const DefaultProject = fleetcli.DefaultProject
+
+// The config model: a fleet.toml and its [[services]]
+type (
+	Config          = fleetcli.Config
+	Service         = fleetcli.Service
+	HealthCheck     = fleetcli.HealthCheck
+	InitContainer   = fleetcli.InitContainer
+	Worker          = fleetcli.Worker
+	CronJob         = fleetcli.CronJob
+	MaintenanceTask = fleetcli.MaintenanceTask
+	HTTPCheck       = fleetcli.HTTPCheck
+	PHPFPMSettings  = fleetcli.PHPFPMSettings
+	AlertThresholds = fleetcli.AlertThresholds
+	WatchRule       = fleetcli.WatchRule
+)
+
+// The generated compose config, which marshals to a compose file with gopkg.in/yaml.v3
+type (
+	DockerCompose           = fleetcli.DockerCompose
+	DockerService           = fleetcli.DockerService
+	HealthCheckYAML         = fleetcli.HealthCheckYAML
+	DockerDeploy            = fleetcli.DockerDeploy
+	DockerResources         = fleetcli.DockerResources
+	DockerReservations      = fleetcli.DockerReservations
+	DockerDevice            = fleetcli.DockerDevice
+	DockerDevelop           = fleetcli.DockerDevelop
+	DockerWatch             = fleetcli.DockerWatch
+	DockerNetwork           = fleetcli.DockerNetwork
+	DockerNetworkIPAM       = fleetcli.DockerNetworkIPAM
+	DockerNetworkIPAMConfig = fleetcli.DockerNetworkIPAMConfig
+	DockerVolume            = fleetcli.DockerVolume
+)
+
+// The files the services of a generated compose config mount
+type (
+	ArtifactPlan = fleetcli.ArtifactPlan
+	Artifact     = fleetcli.Artifact
+)
+
+// Parse decodes a config in the format of a file extension: .toml, .yaml, .yml or
+// .json. It doesn't validate it.
+func Parse(data []byte, ext string) (*Config, error) {
+	return fleetcli.Parse(data, ext)
+}
+
+// Load reads a config file and validates it. Unlike the fleet command, it doesn't fetch
+// remote configs.
+func Load(filename string) (*Config, error) {
+	return fleetcli.Load(filename)
+}
+
+// Validate checks a config: the services it needs and the settings of each feature,
+// against the runtime and service versions Fleet supports. A config without a project
+// gets DefaultProject.
+func Validate(config *Config) error {
+	return fleetcli.Validate(config)
+}
+
+// Generate returns the compose config of a validated config and the plan of the files
+// its services mount. The folders of services are read in projectDir, usually the
+// directory of the config, and the paths of planned files are relative to it. The
+// config isn't changed.
+func Generate(config *Config, projectDir string) (*DockerCompose, *ArtifactPlan) {
+	return fleetcli.Generate(config, projectDir)
+}
@@ -0,0 +1,83 @@
+package fleet
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// GenerateTestSuite tests generating the compose config of a project other than the
+// working directory
+type GenerateTestSuite struct {
+	suite.Suite
+	projectDir string
+}
+
+func (suite *GenerateTestSuite) SetupTest() {
+	suite.projectDir = suite.T().TempDir()
+	suite.Require().NoError(os.MkdirAll(filepath.Join(suite.projectDir, "web"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.projectDir, "web", "package.json"),
+		[]byte(`{"dependencies": {"next": "14.0.0"}, "scripts": {"dev": "next dev"}}`), 0644))
+}
+
+func (suite *GenerateTestSuite) config() *Config {
+	return &Config{Project: "shop", Services: []Service{
+		{Name: "web", Runtime: "node:20", Folder: "web", Port: 3000, FrameworkConfig: true},
+		{Name: "worker", Image: "node:20", Command: "node worker.js", WaitFor: []string{"web:port:3000"}},
+	}}
+}
+
+func (suite *GenerateTestSuite) TestGenerate() {
+	config := suite.config()
+	compose, plan := Generate(config, suite.projectDir)
+	suite.Contains(compose.Services, "nginx-proxy")
+	suite.Same(compose.Artifacts, plan)
+
+	var paths []string
+	for _, artifact := range plan.Files() {
+		paths = append(paths, artifact.Path)
+	}
+	suite.Equal([]string{
+		filepath.Join(".fleet", "nginx.conf"),
+		filepath.Join(".fleet", "wait-for.sh"),
+		filepath.Join("web", ".env.local"),
+	}, paths, "The framework of web is detected in the project directory")
+	suite.Contains(compose.Services["nginx-proxy"].Volumes,
+		filepath.Join(suite.projectDir, ".fleet", "nginx.conf")+":/etc/nginx/nginx.conf:ro")
+
+	suite.Equal(suite.config(), config, "Generating leaves the config alone")
+	suite.NoDirExists(filepath.Join(suite.projectDir, ".fleet"), "Generating only plans the files")
+}
+
+func (suite *GenerateTestSuite) TestGenerateUsesImageLock() {
+	lock := `{"images": {"node:20": "sha256:0123"}}`
+	suite.Require().NoError(os.MkdirAll(filepath.Join(suite.projectDir, ".fleet"), 0755))
+	suite.Require().NoError(os.WriteFile(filepath.Join(suite.projectDir, ".fleet", "images.lock"), []byte(lock), 0644))
+
+	compose, _ := Generate(suite.config(), suite.projectDir)
+	suite.Equal("node:20@sha256:0123", compose.Services["worker"].Image)
+
+	compose, _ = Generate(suite.config(), suite.T().TempDir())
+	suite.Equal("node:20", compose.Services["worker"].Image, "Another project has no lock")
+}
+
+func (suite *GenerateTestSuite) TestGenerateIsSafeToRunInParallel() {
+	done := make(chan *DockerCompose)
+	for i := 0; i < 4; i++ {
+		go func() {
+			compose, _ := Generate(suite.config(), suite.projectDir)
+			done <- compose
+		}()
+	}
+	first := <-done
+	for i := 1; i < 4; i++ {
+		suite.True(reflect.DeepEqual(first, <-done))
+	}
+}
+
+func TestGenerateSuite(t *testing.T) {
+	suite.Run(t, new(GenerateTestSuite))
+}
@@ -0,0 +1,78 @@
+package fleet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ParseTestSuite tests the API other tools read configs with
+type ParseTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ParseTestSuite) TestParseFormats() {
+	testCases := map[string]string{
+		".toml": "project = \"shop\"\n\n[[services]]\nname = \"web\"\nimage = \"nginx:alpine\"\n",
+		".yaml": "project: shop\nservices:\n  - name: web\n    image: nginx:alpine\n",
+		".yml":  "project: shop\nservices:\n  - name: web\n    image: nginx:alpine\n",
+		".json": `{"project": "shop", "services": [{"name": "web", "image": "nginx:alpine"}]}`,
+	}
+	for ext, data := range testCases {
+		config, err := Parse([]byte(data), ext)
+		suite.Require().NoError(err, ext)
+		suite.Equal("shop", config.Project, ext)
+		suite.Require().Len(config.Services, 1, ext)
+		suite.Equal("nginx:alpine", config.Services[0].Image, ext)
+	}
+
+	_, err := Parse([]byte("project = \"shop\""), ".ini")
+	suite.ErrorContains(err, "unsupported config format")
+	_, err = Parse([]byte("project = "), ".toml")
+	suite.ErrorContains(err, "failed to parse config")
+}
+
+func (suite *ParseTestSuite) TestParseDoesNotValidate() {
+	config, err := Parse([]byte("[[services]]\nname = \"web\"\n"), ".toml")
+	suite.Require().NoError(err)
+	suite.Empty(config.Project)
+}
+
+func (suite *ParseTestSuite) TestLoad() {
+	dir := suite.T().TempDir()
+	file := filepath.Join(dir, "fleet.staging.toml")
+	suite.Require().NoError(os.WriteFile(file, []byte("[[services]]\nname = \"web\"\nimage = \"nginx:alpine\"\n"), 0644))
+
+	config, err := Load(file)
+	suite.Require().NoError(err)
+	suite.Equal(DefaultProject, config.Project)
+	suite.Regexp(`^staging-[0-9a-f]{8}$`, config.ArtifactSet, "Generated files of the config go to their own directory")
+
+	_, err = Load(filepath.Join(dir, "missing.toml"))
+	suite.ErrorContains(err, "failed to read config file")
+}
+
+func (suite *ParseTestSuite) TestValidate() {
+	testCases := map[string]struct {
+		config *Config
+		err    string
+	}{
+		"no services":      {&Config{Project: "shop"}, "no services defined"},
+		"no name":          {&Config{Services: []Service{{Image: "nginx:alpine"}}}, "service #1: name is required"},
+		"no image":         {&Config{Services: []Service{{Name: "web"}}}, "either 'image' or 'build' is required"},
+		"feature settings": {&Config{Services: []Service{{Name: "web", Image: "nginx:alpine", Replicas: -1}}}, "replicas must be positive"},
+	}
+	for name, tc := range testCases {
+		suite.ErrorContains(Validate(tc.config), tc.err, name)
+	}
+
+	config := &Config{Services: []Service{{Name: "db", Database: "postgres:16"}}}
+	suite.Require().NoError(Validate(config), "Addons provide the container")
+	suite.Equal(DefaultProject, config.Project)
+}
+
+func TestParseSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
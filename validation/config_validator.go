@@ -149,6 +149,14 @@ func (cv *ConfigValidator) validateRuntimeFramework(runtime, framework string) e
 			return fmt.Errorf("framework '%s' requires Node.js runtime", framework)
 		}
 	}
+
+	// Ruby frameworks
+	rubyFrameworks := []string{"rails", "sinatra"}
+	for _, rubyFramework := range rubyFrameworks {
+		if framework == rubyFramework && !strings.HasPrefix(runtime, "ruby") {
+			return fmt.Errorf("framework '%s' requires Ruby runtime", framework)
+		}
+	}
 	
 	return nil
 }